@@ -0,0 +1,222 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/gorilla/mux"
+)
+
+// Maximum size of an access control policy in PUT requests.
+const maxAccessControlPolicySize = 64 * humanize.KiByte
+
+// parseCannedACL - returns the canned ACL requested by a PUT of the acl
+// subresource, in the x-amz-acl header or as an access control policy
+// in the body. Policies other than those of canned ACLs are not
+// implemented.
+func parseCannedACL(r *http.Request) (string, APIErrorCode) {
+	acl, s3Error := getCannedACL(r.Header)
+	if s3Error != ErrNone || acl != "" {
+		return acl, s3Error
+	}
+
+	if r.ContentLength == 0 {
+		return "", ErrMissingRequestBodyError
+	}
+	if r.ContentLength > maxAccessControlPolicySize {
+		return "", ErrEntityTooLarge
+	}
+	var policy AccessControlPolicy
+	if err := xmlDecoder(io.LimitReader(r.Body, maxAccessControlPolicySize), &policy, r.ContentLength); err != nil {
+		errorIf(err, "Unable to parse access control policy.")
+		return "", ErrMalformedXML
+	}
+	acl, ok := policy.cannedACL()
+	if !ok {
+		return "", ErrNotImplemented
+	}
+	return acl, ErrNone
+}
+
+// GetBucketACLHandler - GET Bucket ACL
+// -----------------
+// This operation returns the canned ACL of a bucket as an access
+// control policy.
+func (api objectAPIHandlers) GetBucketACLHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, "", "", globalServerConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	// Layers without ACL support have private buckets.
+	acl := cannedACLPrivate
+	if objectAPI.IsACLSupported() {
+		var err error
+		if acl, err = readBucketACL(bucket, objectAPI); err != nil {
+			writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+			return
+		}
+	}
+
+	writeSuccessResponseXML(w, encodeResponse(generateAccessControlPolicy(acl)))
+}
+
+// PutBucketACLHandler - PUT Bucket ACL
+// -----------------
+// This operation sets the canned ACL of a bucket.
+func (api objectAPIHandlers) PutBucketACLHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, "", "", globalServerConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	acl, s3Error := parseCannedACL(r)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	if !objectAPI.IsACLSupported() {
+		if acl != cannedACLPrivate {
+			writeErrorResponse(w, ErrNotImplemented, r.URL)
+			return
+		}
+		writeSuccessResponseHeadersOnly(w)
+		return
+	}
+
+	if err := persistAndNotifyBucketACLChange(bucket, acl, objectAPI); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
+
+// GetObjectACLHandler - GET Object ACL
+// -----------------
+// This operation returns the canned ACL of an object as an access
+// control policy.
+func (api objectAPIHandlers) GetObjectACLHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, "", "", globalServerConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	writeSuccessResponseXML(w, encodeResponse(generateAccessControlPolicy(getObjectACL(objInfo))))
+}
+
+// PutObjectACLHandler - PUT Object ACL
+// -----------------
+// This operation sets the canned ACL of an object.
+func (api objectAPIHandlers) PutObjectACLHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, "", "", globalServerConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	acl, s3Error := parseCannedACL(r)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	if acl == getObjectACL(objInfo) {
+		writeSuccessResponseHeadersOnly(w)
+		return
+	}
+
+	// The ACL is object metadata, update it in place.
+	metadata := make(map[string]string, len(objInfo.UserDefined))
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	delete(metadata, "etag")
+	if s3Error = setObjectACL(objectAPI, acl, metadata); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+	if _, err = objectAPI.CopyObject(bucket, object, bucket, object, metadata, objInfo.ETag); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
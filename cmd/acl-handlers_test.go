@@ -0,0 +1,296 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	router "github.com/gorilla/mux"
+	"github.com/minio/minio-go/pkg/policy"
+	"github.com/minio/minio-go/pkg/set"
+)
+
+func TestAccessControlPolicyCannedACL(t *testing.T) {
+	for _, acl := range []string{cannedACLPrivate, cannedACLPublicRead, cannedACLPublicReadWrite, cannedACLAuthenticatedRead} {
+		data, err := xml.Marshal(generateAccessControlPolicy(acl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var policy AccessControlPolicy
+		if err = xml.Unmarshal(data, &policy); err != nil {
+			t.Fatal(err)
+		}
+		if got, ok := policy.cannedACL(); !ok || got != acl {
+			t.Errorf("%s: got %q, %v from %s", acl, got, ok, data)
+		}
+	}
+
+	// Grants to other users do not match a canned ACL.
+	policy := generateAccessControlPolicy(cannedACLPublicRead)
+	policy.AccessControlList.Grants = append(policy.AccessControlList.Grants,
+		groupGrant(aclGroupAllUsers, aclPermissionFullControl))
+	if _, ok := policy.cannedACL(); ok {
+		t.Error("Expected no canned ACL to match")
+	}
+}
+
+func TestCannedACLHandlers(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fsDir)
+	globalObjLayerMutex.Lock()
+	globalObjectAPI = obj
+	globalObjLayerMutex.Unlock()
+	initGlobalS3Peers(mustGetNewEndpointList(fsDir))
+
+	mux := router.NewRouter().SkipClean(true)
+	registerAPIRouter(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cred := globalServerConfig.GetCredential()
+	bucket := getRandomBucketName()
+
+	signedRequest := func(method, urlStr, acl string, body []byte) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if acl != "" {
+			req.Header.Set(amzACL, acl)
+		}
+		if err = signRequestV4(req, cred.AccessKey, cred.SecretKey); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	anonymousRequest := func(method, urlStr string, body []byte) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	aclBody, err := xml.Marshal(generateAccessControlPolicy(cannedACLPublicRead))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		req            *http.Request
+		expectedStatus int
+	}{
+		{signedRequest(http.MethodPut, getMakeBucketURL(server.URL, bucket), "public-read", nil), http.StatusOK},
+		{signedRequest(http.MethodPut, getPutObjectURL(server.URL, bucket, "public"), "public-read", []byte("hello")), http.StatusOK},
+		{signedRequest(http.MethodPut, getPutObjectURL(server.URL, bucket, "private"), "", []byte("hello")), http.StatusOK},
+		{signedRequest(http.MethodPut, getPutObjectURL(server.URL, bucket, "invalid"), "public", []byte("hello")), http.StatusBadRequest},
+		// Public objects and listing of public-read buckets are
+		// allowed to anonymous requests, writing is not.
+		{anonymousRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "public"), nil), http.StatusOK},
+		{anonymousRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "private"), nil), http.StatusForbidden},
+		{anonymousRequest(http.MethodGet, getListObjectsV1URL(server.URL, bucket, ""), nil), http.StatusOK},
+		{anonymousRequest(http.MethodPut, getPutObjectURL(server.URL, bucket, "anonymous"), []byte("hello")), http.StatusForbidden},
+		{anonymousRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "public")+"?acl", nil), http.StatusForbidden},
+		// ACLs are changed by header or access control policy.
+		{signedRequest(http.MethodPut, getMakeBucketURL(server.URL, bucket)+"?acl", "public-read-write", nil), http.StatusOK},
+		{anonymousRequest(http.MethodPut, getPutObjectURL(server.URL, bucket, "anonymous"), []byte("hello")), http.StatusOK},
+		{signedRequest(http.MethodPut, getPutObjectURL(server.URL, bucket, "private")+"?acl", "", aclBody), http.StatusOK},
+		{anonymousRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "private"), nil), http.StatusOK},
+		{signedRequest(http.MethodPut, getPutObjectURL(server.URL, bucket, "public")+"?acl", "private", nil), http.StatusOK},
+		{anonymousRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "public"), nil), http.StatusForbidden},
+		{signedRequest(http.MethodPut, getMakeBucketURL(server.URL, bucket)+"?acl", "private", nil), http.StatusOK},
+		{anonymousRequest(http.MethodGet, getListObjectsV1URL(server.URL, bucket, ""), nil), http.StatusForbidden},
+		{signedRequest(http.MethodPut, getMakeBucketURL(server.URL, bucket)+"?acl", "", nil), http.StatusLengthRequired},
+	}
+	for i, testCase := range testCases {
+		resp, err := http.DefaultClient.Do(testCase.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != testCase.expectedStatus {
+			t.Errorf("Test %d: %s %s: expected %d, got %d", i+1, testCase.req.Method,
+				testCase.req.URL, testCase.expectedStatus, resp.StatusCode)
+		}
+	}
+
+	// The ACL is returned as an access control policy.
+	resp, err := http.DefaultClient.Do(signedRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "private")+"?acl", "", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var policy AccessControlPolicy
+	if err = xml.NewDecoder(resp.Body).Decode(&policy); err != nil {
+		t.Fatal(err)
+	}
+	if acl, _ := policy.cannedACL(); acl != cannedACLPublicRead {
+		t.Errorf("Expected %s, got %s", cannedACLPublicRead, acl)
+	}
+
+	// The ACL is not returned as object metadata.
+	objInfo, err := obj.GetObjectInfo(bucket, "private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	setObjectHeaders(w, objInfo, nil)
+	if w.Header().Get(objectACLKey) != "" {
+		t.Errorf("Unexpected %s header", objectACLKey)
+	}
+}
+
+// Tests that an explicit deny of the bucket policy is not overridden
+// by the canned ACLs of a public bucket.
+func TestCannedACLBucketPolicyDeny(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fsDir)
+	globalObjLayerMutex.Lock()
+	globalObjectAPI = obj
+	globalObjLayerMutex.Unlock()
+	initGlobalS3Peers(mustGetNewEndpointList(fsDir))
+
+	bucket := getRandomBucketName()
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = persistAndNotifyBucketACLChange(bucket, cannedACLPublicRead, obj); err != nil {
+		t.Fatal(err)
+	}
+	for _, object := range []string{"public", "secret"} {
+		metadata := map[string]string{objectACLKey: cannedACLPublicRead}
+		if _, err = obj.PutObject(bucket, object, mustGetHashReader(t, bytes.NewReader([]byte("hello")), 5, "", ""), metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bucketPolicy := policy.BucketAccessPolicy{
+		Version: "2012-10-17",
+		Statements: []policy.Statement{{
+			Effect:    "Deny",
+			Principal: policy.User{AWS: set.CreateStringSet("*")},
+			Actions:   set.CreateStringSet("s3:GetObject"),
+			Resources: set.CreateStringSet(bucketARNPrefix + bucket + "/secret"),
+		}},
+	}
+	if err = obj.SetBucketPolicy(bucket, bucketPolicy); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		action, resource string
+		expectedErr      APIErrorCode
+	}{
+		// Canned ACLs apply when no statement matches.
+		{"s3:GetObject", "/" + bucket + "/public", ErrNone},
+		{"s3:ListBucket", "/" + bucket, ErrNone},
+		{"s3:PutObject", "/" + bucket + "/public", ErrAccessDenied},
+		// Explicitly denied.
+		{"s3:GetObject", "/" + bucket + "/secret", ErrAccessDenied},
+	}
+	for i, testCase := range testCases {
+		if s3Error := enforceBucketPolicy(bucket, testCase.action, testCase.resource, "", "127.0.0.1", nil); s3Error != testCase.expectedErr {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expectedErr, s3Error)
+		}
+	}
+}
+
+// Tests that bucket ACLs are enforced from memory and reloaded when
+// peers are notified of a change.
+func TestBucketACLCache(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fsDir)
+	globalObjLayerMutex.Lock()
+	globalObjectAPI = obj
+	globalObjLayerMutex.Unlock()
+	initGlobalS3Peers(mustGetNewEndpointList(fsDir))
+
+	bucket := getRandomBucketName()
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	resource := "/" + bucket
+	if s3Error := enforceCannedACL(obj, "s3:ListBucket", resource); s3Error != ErrAccessDenied {
+		t.Fatalf("Expected %v, got %v", ErrAccessDenied, s3Error)
+	}
+
+	// A change saved by another node is not seen until peers are
+	// notified.
+	if err = writeBucketACL(bucket, obj, cannedACLPublicRead); err != nil {
+		t.Fatal(err)
+	}
+	if s3Error := enforceCannedACL(obj, "s3:ListBucket", resource); s3Error != ErrAccessDenied {
+		t.Fatalf("Expected %v, got %v", ErrAccessDenied, s3Error)
+	}
+	S3PeersUpdateBucketACL(bucket)
+	if s3Error := enforceCannedACL(obj, "s3:ListBucket", resource); s3Error != ErrNone {
+		t.Fatalf("Expected %v, got %v", ErrNone, s3Error)
+	}
+
+	// The ACL is dropped with the bucket.
+	if err = obj.DeleteBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	if acl := getBucketACLs(obj).GetBucketACL(bucket); acl != cannedACLPrivate {
+		t.Fatalf("Expected %s, got %s", cannedACLPrivate, acl)
+	}
+
+	// ACLs are loaded on startup.
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = writeBucketACL(bucket, obj, cannedACLPublicReadWrite); err != nil {
+		t.Fatal(err)
+	}
+	if err = initBucketACLs(obj); err != nil {
+		t.Fatal(err)
+	}
+	if s3Error := enforceCannedACL(obj, "s3:PutObject", resource+"/object"); s3Error != ErrNone {
+		t.Fatalf("Expected %v, got %v", ErrNone, s3Error)
+	}
+}
@@ -0,0 +1,392 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"sync"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Canned ACLs supported on buckets and objects, other ACLs are not
+// implemented.
+// http://docs.aws.amazon.com/AmazonS3/latest/dev/acl-overview.html#canned-acl
+const (
+	cannedACLPrivate           = "private"
+	cannedACLPublicRead        = "public-read"
+	cannedACLPublicReadWrite   = "public-read-write"
+	cannedACLAuthenticatedRead = "authenticated-read"
+)
+
+const (
+	// Header selecting the canned ACL of a new bucket or object.
+	amzACL = "X-Amz-Acl"
+
+	// Object metadata key of the canned ACL of an object, not set
+	// for private objects.
+	objectACLKey = ReservedMetadataPrefix + "Acl"
+
+	// Bucket ACL config name, not present for private buckets.
+	bucketACLConfig = "acl.json"
+)
+
+// Headers granting permissions to explicit grantees, which are not
+// supported.
+var amzGrantHeaders = []string{
+	"X-Amz-Grant-Read",
+	"X-Amz-Grant-Write",
+	"X-Amz-Grant-Read-Acp",
+	"X-Amz-Grant-Write-Acp",
+	"X-Amz-Grant-Full-Control",
+}
+
+// Grantee URIs of the predefined groups, and permissions.
+const (
+	aclGroupAllUsers           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	aclGroupAuthenticatedUsers = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+
+	aclPermissionRead        = "READ"
+	aclPermissionWrite       = "WRITE"
+	aclPermissionFullControl = "FULL_CONTROL"
+)
+
+// isValidCannedACL - returns whether acl is a supported canned ACL.
+func isValidCannedACL(acl string) bool {
+	switch acl {
+	case cannedACLPrivate, cannedACLPublicRead, cannedACLPublicReadWrite, cannedACLAuthenticatedRead:
+		return true
+	}
+	return false
+}
+
+// getCannedACL - returns the canned ACL requested by the x-amz-acl
+// header, empty if not set.
+func getCannedACL(header http.Header) (string, APIErrorCode) {
+	for _, grantHeader := range amzGrantHeaders {
+		if _, ok := header[grantHeader]; ok {
+			return "", ErrNotImplemented
+		}
+	}
+	acl := header.Get(amzACL)
+	if acl != "" && !isValidCannedACL(acl) {
+		return "", ErrInvalidCannedACL
+	}
+	return acl, ErrNone
+}
+
+// setObjectACL - saves the canned ACL of a new object in its metadata.
+func setObjectACL(objAPI ObjectLayer, acl string, metadata map[string]string) APIErrorCode {
+	if acl == "" || acl == cannedACLPrivate {
+		delete(metadata, objectACLKey)
+		return ErrNone
+	}
+	if !objAPI.IsACLSupported() {
+		return ErrNotImplemented
+	}
+	metadata[objectACLKey] = acl
+	return ErrNone
+}
+
+// setObjectACLFromHeader - saves the canned ACL requested by the
+// x-amz-acl header in the metadata of a new object.
+func setObjectACLFromHeader(objAPI ObjectLayer, header http.Header, metadata map[string]string) APIErrorCode {
+	acl, s3Error := getCannedACL(header)
+	if s3Error != ErrNone {
+		return s3Error
+	}
+	return setObjectACL(objAPI, acl, metadata)
+}
+
+// getObjectACL - returns the canned ACL of an object.
+func getObjectACL(objInfo ObjectInfo) string {
+	if acl, ok := objInfo.UserDefined[objectACLKey]; ok {
+		return acl
+	}
+	return cannedACLPrivate
+}
+
+// bucketACL - format of the bucket ACL config.
+type bucketACL struct {
+	ACL string `json:"acl"`
+}
+
+// readBucketACL - returns the canned ACL of a bucket, private if not set.
+func readBucketACL(bucket string, objAPI ObjectLayer) (string, error) {
+	aclPath := pathJoin(bucketConfigPrefix, bucket, bucketACLConfig)
+
+	var buffer bytes.Buffer
+	err := objAPI.GetObject(minioMetaBucket, aclPath, 0, -1, &buffer, "")
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrIncompleteBody(err) {
+			return cannedACLPrivate, nil
+		}
+		errorIf(err, "Unable to load ACL for the bucket %s.", bucket)
+		return "", errors.Cause(err)
+	}
+
+	var config bucketACL
+	if err = json.Unmarshal(buffer.Bytes(), &config); err != nil {
+		errorIf(err, "Unable to parse ACL for the bucket %s.", bucket)
+		return "", err
+	}
+	if !isValidCannedACL(config.ACL) {
+		return cannedACLPrivate, nil
+	}
+	return config.ACL, nil
+}
+
+// writeBucketACL - saves the canned ACL of a bucket, private buckets
+// have no ACL config.
+func writeBucketACL(bucket string, objAPI ObjectLayer, acl string) error {
+	if acl == cannedACLPrivate {
+		if err := removeBucketACL(bucket, objAPI); err != nil && !isErrObjectNotFound(err) {
+			return errors.Cause(err)
+		}
+		return nil
+	}
+
+	buf, err := json.Marshal(bucketACL{ACL: acl})
+	if err != nil {
+		return err
+	}
+	aclPath := pathJoin(bucketConfigPrefix, bucket, bucketACLConfig)
+	hashReader, err := hash.NewReader(bytes.NewReader(buf), int64(len(buf)), "", getSHA256Hash(buf))
+	if err != nil {
+		errorIf(err, "Unable to set ACL for the bucket %s", bucket)
+		return errors.Cause(err)
+	}
+
+	if _, err = objAPI.PutObject(minioMetaBucket, aclPath, hashReader, nil); err != nil {
+		errorIf(err, "Unable to set ACL for the bucket %s", bucket)
+		return errors.Cause(err)
+	}
+	return nil
+}
+
+// removeBucketACL - removes the ACL config of a bucket, only used
+// during DeleteBucket and to make a bucket private.
+func removeBucketACL(bucket string, objAPI ObjectLayer) error {
+	aclPath := pathJoin(bucketConfigPrefix, bucket, bucketACLConfig)
+	return objAPI.DeleteObject(minioMetaBucket, aclPath)
+}
+
+// bucketACLs - canned ACLs of all buckets cached in memory, consulted
+// when enforcing ACLs on anonymous requests.
+type bucketACLs struct {
+	rwMutex *sync.RWMutex
+
+	// Canned ACLs of buckets which are not private.
+	bucketACLConfigs map[string]string
+}
+
+// GetBucketACL - returns the cached canned ACL of a bucket, private if
+// not set.
+func (ba bucketACLs) GetBucketACL(bucket string) string {
+	ba.rwMutex.RLock()
+	defer ba.rwMutex.RUnlock()
+	if acl, ok := ba.bucketACLConfigs[bucket]; ok {
+		return acl
+	}
+	return cannedACLPrivate
+}
+
+// SetBucketACL - caches the canned ACL of a bucket.
+func (ba *bucketACLs) SetBucketACL(bucket, acl string) {
+	ba.rwMutex.Lock()
+	defer ba.rwMutex.Unlock()
+	if acl == cannedACLPrivate {
+		delete(ba.bucketACLConfigs, bucket)
+		return
+	}
+	ba.bucketACLConfigs[bucket] = acl
+}
+
+// initBucketACLs - loads the canned ACLs of all buckets.
+func initBucketACLs(objAPI ObjectLayer) error {
+	if objAPI == nil {
+		return errInvalidArgument
+	}
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return errors.Cause(err)
+	}
+
+	acls := &bucketACLs{
+		rwMutex:          &sync.RWMutex{},
+		bucketACLConfigs: make(map[string]string),
+	}
+	for _, bucket := range buckets {
+		acl, err := readBucketACL(bucket.Name, objAPI)
+		if err != nil {
+			return err
+		}
+		acls.SetBucketACL(bucket.Name, acl)
+	}
+	switch objAPI.(type) {
+	case *fsObjects:
+		objAPI.(*fsObjects).bucketACLs = acls
+	case *xlObjects:
+		objAPI.(*xlObjects).bucketACLs = acls
+	}
+	return nil
+}
+
+// getBucketACLs - returns the cached bucket ACLs of an object layer,
+// nil for layers without ACLs.
+func getBucketACLs(objAPI ObjectLayer) *bucketACLs {
+	switch objAPI := objAPI.(type) {
+	case *fsObjects:
+		return objAPI.bucketACLs
+	case *xlObjects:
+		return objAPI.bucketACLs
+	}
+	return nil
+}
+
+// refreshBucketACL - reloads the cached canned ACL of a bucket.
+func refreshBucketACL(bucket string, objAPI ObjectLayer) error {
+	acls := getBucketACLs(objAPI)
+	if acls == nil {
+		return nil
+	}
+	acl, err := readBucketACL(bucket, objAPI)
+	if err != nil {
+		return err
+	}
+	acls.SetBucketACL(bucket, acl)
+	return nil
+}
+
+// persistAndNotifyBucketACLChange - saves the canned ACL of a bucket
+// and notifies all nodes of the change.
+func persistAndNotifyBucketACLChange(bucket, acl string, objAPI ObjectLayer) error {
+	if err := writeBucketACL(bucket, objAPI, acl); err != nil {
+		return err
+	}
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketACL(bucket)
+	return nil
+}
+
+// Grantee - a user or group granted a permission.
+type Grantee struct {
+	XMLNS       string `xml:"xmlns:xsi,attr"`
+	Type        string `xml:"xsi:type,attr"`
+	ID          string `xml:"ID,omitempty"`
+	DisplayName string `xml:"DisplayName,omitempty"`
+	URI         string `xml:"URI,omitempty"`
+}
+
+// Grant - a permission granted to a grantee.
+type Grant struct {
+	Grantee    Grantee
+	Permission string
+}
+
+// AccessControlPolicy - the ACL of a bucket or object, for GET and PUT
+// of the acl subresource.
+type AccessControlPolicy struct {
+	XMLName           xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ AccessControlPolicy" json:"-"`
+	Owner             Owner
+	AccessControlList struct {
+		Grants []Grant `xml:"Grant"`
+	}
+}
+
+// canonicalUserGrant - grants a permission to the owner.
+func canonicalUserGrant(permission string) Grant {
+	return Grant{
+		Grantee: Grantee{
+			XMLNS: "http://www.w3.org/2001/XMLSchema-instance",
+			Type:  "CanonicalUser",
+			ID:    globalMinioDefaultOwnerID,
+		},
+		Permission: permission,
+	}
+}
+
+// groupGrant - grants a permission to a predefined group.
+func groupGrant(uri, permission string) Grant {
+	return Grant{
+		Grantee: Grantee{
+			XMLNS: "http://www.w3.org/2001/XMLSchema-instance",
+			Type:  "Group",
+			URI:   uri,
+		},
+		Permission: permission,
+	}
+}
+
+// generateAccessControlPolicy - returns the grants of a canned ACL.
+func generateAccessControlPolicy(acl string) AccessControlPolicy {
+	policy := AccessControlPolicy{}
+	policy.Owner.ID = globalMinioDefaultOwnerID
+	grants := []Grant{canonicalUserGrant(aclPermissionFullControl)}
+	switch acl {
+	case cannedACLPublicRead:
+		grants = append(grants, groupGrant(aclGroupAllUsers, aclPermissionRead))
+	case cannedACLPublicReadWrite:
+		grants = append(grants, groupGrant(aclGroupAllUsers, aclPermissionRead),
+			groupGrant(aclGroupAllUsers, aclPermissionWrite))
+	case cannedACLAuthenticatedRead:
+		grants = append(grants, groupGrant(aclGroupAuthenticatedUsers, aclPermissionRead))
+	}
+	policy.AccessControlList.Grants = grants
+	return policy
+}
+
+// cannedACL - returns the canned ACL with the grants of the policy,
+// false if the grants do not match any canned ACL.
+func (acp AccessControlPolicy) cannedACL() (string, bool) {
+	grantKeys := func(grants []Grant) map[string]bool {
+		keys := make(map[string]bool)
+		for _, grant := range grants {
+			// The owner is the only canonical user.
+			grantee := grant.Grantee.URI
+			if grantee == "" {
+				grantee = "owner"
+			}
+			keys[grantee+" "+grant.Permission] = true
+		}
+		return keys
+	}
+
+	keys := grantKeys(acp.AccessControlList.Grants)
+	for _, acl := range []string{cannedACLPrivate, cannedACLPublicRead, cannedACLPublicReadWrite, cannedACLAuthenticatedRead} {
+		aclKeys := grantKeys(generateAccessControlPolicy(acl).AccessControlList.Grants)
+		if len(keys) != len(aclKeys) {
+			continue
+		}
+		match := true
+		for key := range aclKeys {
+			if !keys[key] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return acl, true
+		}
+	}
+	return "", false
+}
@@ -196,13 +196,49 @@ type ServerHTTPStats struct {
 	SuccessDELETEStats ServerHTTPMethodStats `json:"successDELETEs"`
 }
 
+// ServerDurationBucket holds the number of observations less than or
+// equal to the upper bound.
+type ServerDurationBucket struct {
+	UpperBound string `json:"le"`
+	Count      uint64 `json:"count"`
+}
+
+// ServerDurationHistogram holds a histogram of durations with
+// cumulative bucket counts, total count and sum of all observations.
+type ServerDurationHistogram struct {
+	Buckets []ServerDurationBucket `json:"buckets"`
+	Count   uint64                 `json:"count"`
+	Sum     string                 `json:"sum"`
+}
+
+// ServerLockStats holds the time namespace locks were waited for and
+// held, and the number of stale distributed locks dropped.
+type ServerLockStats struct {
+	Wait       ServerDurationHistogram `json:"wait"`
+	Hold       ServerDurationHistogram `json:"hold"`
+	StaleLocks uint64                  `json:"staleLocks"`
+}
+
+// ServerAdmissionStats holds the limit of API requests served at
+// once, the requests in flight and queued, and the number of requests
+// admitted and rejected since startup.
+type ServerAdmissionStats struct {
+	MaxRequests int    `json:"maxRequests"`
+	InFlight    int    `json:"inFlight"`
+	Queued      int    `json:"queued"`
+	Admitted    uint64 `json:"admitted"`
+	Rejected    uint64 `json:"rejected"`
+}
+
 // ServerInfoData holds storage, connections and other
 // information of a given server.
 type ServerInfoData struct {
-	StorageInfo StorageInfo      `json:"storage"`
-	ConnStats   ServerConnStats  `json:"network"`
-	HTTPStats   ServerHTTPStats  `json:"http"`
-	Properties  ServerProperties `json:"server"`
+	StorageInfo StorageInfo          `json:"storage"`
+	ConnStats   ServerConnStats      `json:"network"`
+	HTTPStats   ServerHTTPStats      `json:"http"`
+	LockStats   ServerLockStats      `json:"locks"`
+	Admission   ServerAdmissionStats `json:"admission"`
+	Properties  ServerProperties     `json:"server"`
 }
 
 // ServerInfo holds server information result of one node
@@ -265,6 +301,44 @@ func (a adminAPIHandlers) ServerInfoHandler(w http.ResponseWriter, r *http.Reque
 	writeSuccessResponseJSON(w, jsonBytes)
 }
 
+// WriteBackStatusHandler - GET /minio/admin/v1/writeback?bucket=mybucket&prefix=myprefix
+// - bucket and prefix are optional query parameters
+// ----------
+// Get uploads pending on a gateway in write-back mode, lists the
+// oldest pending objects in the given bucket and prefix.
+func (a adminAPIHandlers) WriteBackStatusHandler(w http.ResponseWriter, r *http.Request) {
+	// Authenticate request
+	adminAPIErr := checkAdminRequestAuthType(r, globalServerConfig.GetRegion())
+	if adminAPIErr != ErrNone {
+		writeErrorResponseJSON(w, adminAPIErr, r.URL)
+		return
+	}
+
+	objectAPI := newObjectLayerFn()
+	if objectAPI == nil {
+		writeErrorResponseJSON(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+	wb, ok := unwrapGatewayLayer(objectAPI).(*writeBackObjects)
+	if !ok {
+		writeErrorResponseJSON(w, ErrAdminWriteBackNotEnabled, r.URL)
+		return
+	}
+
+	vars := r.URL.Query()
+	status := wb.Status(vars.Get(string(mgmtBucket)), vars.Get(string(mgmtPrefix)))
+
+	// Marshal API response
+	jsonBytes, err := json.Marshal(status)
+	if err != nil {
+		writeErrorResponseJSON(w, ErrInternalError, r.URL)
+		errorIf(err, "Failed to marshal write-back status into json.")
+		return
+	}
+
+	writeSuccessResponseJSON(w, jsonBytes)
+}
+
 // validateLockQueryParams - Validates query params for list/clear
 // locks management APIs.
 func validateLockQueryParams(vars url.Values) (string, string, time.Duration,
@@ -603,6 +677,8 @@ func toAdminAPIErrCode(err error) APIErrorCode {
 	switch err {
 	case errXLWriteQuorum:
 		return ErrAdminConfigNoQuorum
+	case errConfigSubsysUnknown:
+		return ErrAdminConfigUnknownSubsys
 	}
 	return toAPIErrorCode(err)
 }
@@ -618,12 +694,15 @@ type nodeSummary struct {
 type setConfigResult struct {
 	NodeResults []nodeSummary `json:"nodeResults"`
 	Status      bool          `json:"status"`
+	// Difference with the previous configuration, only set for
+	// updates of configuration subsystems.
+	Diff string `json:"diff,omitempty"`
 }
 
 // writeSetConfigResponse - writes setConfigResult value as json
 // depending on the status.
 func writeSetConfigResponse(w http.ResponseWriter, peers adminPeers,
-	errs []error, status bool, reqURL *url.URL) {
+	errs []error, status bool, diff string, reqURL *url.URL) {
 
 	var nodeResults []nodeSummary
 	// Build nodeResults based on error values received during
@@ -640,6 +719,7 @@ func writeSetConfigResponse(w http.ResponseWriter, peers adminPeers,
 	result := setConfigResult{
 		Status:      status,
 		NodeResults: nodeResults,
+		Diff:        diff,
 	}
 
 	// The following elaborate json encoding is to avoid escaping
@@ -726,7 +806,7 @@ func (a adminAPIHandlers) SetConfigHandler(w http.ResponseWriter, r *http.Reques
 	// Check if the operation succeeded in quorum or more nodes.
 	rErr := reduceWriteQuorumErrs(errs, nil, len(globalAdminPeers)/2+1)
 	if rErr != nil {
-		writeSetConfigResponse(w, globalAdminPeers, errs, false, r.URL)
+		writeSetConfigResponse(w, globalAdminPeers, errs, false, "", r.URL)
 		return
 	}
 
@@ -744,7 +824,7 @@ func (a adminAPIHandlers) SetConfigHandler(w http.ResponseWriter, r *http.Reques
 	errs = commitConfigPeers(globalAdminPeers, tmpFileName)
 	rErr = reduceWriteQuorumErrs(errs, nil, len(globalAdminPeers)/2+1)
 	if rErr != nil {
-		writeSetConfigResponse(w, globalAdminPeers, errs, false, r.URL)
+		writeSetConfigResponse(w, globalAdminPeers, errs, false, "", r.URL)
 		return
 	}
 
@@ -752,12 +832,195 @@ func (a adminAPIHandlers) SetConfigHandler(w http.ResponseWriter, r *http.Reques
 	// where all listeners are closed and process restart/shutdown
 	// happens after 5s or completion of all ongoing http
 	// requests, whichever is earlier.
-	writeSetConfigResponse(w, globalAdminPeers, errs, true, r.URL)
+	writeSetConfigResponse(w, globalAdminPeers, errs, true, "", r.URL)
 
 	// Restart all node for the modified config to take effect.
 	sendServiceCmd(globalAdminPeers, serviceRestart)
 }
 
+// GetConfigSubsysHandler - GET /minio/admin/v1/config/{subsys}
+// Get the configuration of a subsystem, e.g. region or notify/webhook/1.
+func (a adminAPIHandlers) GetConfigSubsysHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Validate request signature.
+	adminAPIErr := checkAdminRequestAuthType(r, globalServerConfig.GetRegion())
+	if adminAPIErr != ErrNone {
+		writeErrorResponseJSON(w, adminAPIErr, r.URL)
+		return
+	}
+
+	// check if objectLayer is initialized, if not return.
+	if newObjectLayerFn() == nil {
+		writeErrorResponseJSON(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	configLock := globalNSMutex.NewNSLock(minioReservedBucket, minioConfigFile)
+	if configLock.GetRLock(globalObjectTimeout) != nil {
+		writeErrorResponseJSON(w, ErrOperationTimedOut, r.URL)
+		return
+	}
+	defer configLock.RUnlock()
+
+	config, err := getPeerServerConfig(globalAdminPeers)
+	if err != nil {
+		errorIf(err, "Failed to get config from peers")
+		writeErrorResponseJSON(w, toAdminAPIErrCode(err), r.URL)
+		return
+	}
+
+	subsysBytes, err := getConfigSubsys(config, mux.Vars(r)["subsys"])
+	if err != nil {
+		writeErrorResponseJSON(w, toAdminAPIErrCode(err), r.URL)
+		return
+	}
+
+	writeSuccessResponseJSON(w, subsysBytes)
+}
+
+// getPeerServerConfig - returns the configuration occurring on a
+// quorum of the servers.
+func getPeerServerConfig(peers adminPeers) (*serverConfig, error) {
+	configBytes, err := getPeerConfig(peers)
+	if err != nil {
+		return nil, err
+	}
+	config := &serverConfig{}
+	if err = json.Unmarshal(configBytes, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// SetConfigSubsysHandler - PUT /minio/admin/v1/config/{subsys}
+// ----------
+// Update the configuration of a subsystem on all servers. Changes are
+// applied without a restart unless the subsystem requires one.
+func (a adminAPIHandlers) SetConfigSubsysHandler(w http.ResponseWriter, r *http.Request) {
+
+	// Get current object layer instance.
+	objectAPI := newObjectLayerFn()
+	if objectAPI == nil {
+		writeErrorResponseJSON(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	// Validate request signature.
+	adminAPIErr := checkAdminRequestAuthType(r, globalServerConfig.GetRegion())
+	if adminAPIErr != ErrNone {
+		writeErrorResponseJSON(w, adminAPIErr, r.URL)
+		return
+	}
+
+	// Changes would not take effect when the subsystem is set via
+	// the environment.
+	subsys := mux.Vars(r)["subsys"]
+	if isConfigSubsysEnvSet(subsys) {
+		writeErrorResponseJSON(w, ErrAdminConfigEnvOverride, r.URL)
+		return
+	}
+
+	// Read configuration bytes from request body.
+	subsysBuf := make([]byte, maxConfigJSONSize+1)
+	n, err := io.ReadFull(r.Body, subsysBuf)
+	if err == nil {
+		// More than maxConfigSize bytes were available
+		writeErrorResponseJSON(w, ErrAdminConfigTooLarge, r.URL)
+		return
+	}
+	if err != io.ErrUnexpectedEOF {
+		errorIf(err, "Failed to read config from request body.")
+		writeErrorResponseJSON(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+	subsysBytes := subsysBuf[:n]
+
+	if err = checkDupJSONKeys(string(subsysBytes)); err != nil {
+		errorIf(err, "config contains duplicate JSON entries.")
+		writeErrorResponseJSON(w, ErrAdminConfigBadJSON, r.URL)
+		return
+	}
+
+	// Take a lock on minio/config.json for the whole update, the
+	// subsystem is updated in the current configuration.
+	configLock := globalNSMutex.NewNSLock(minioReservedBucket, minioConfigFile)
+	if configLock.GetLock(globalObjectTimeout) != nil {
+		writeErrorResponseJSON(w, ErrOperationTimedOut, r.URL)
+		return
+	}
+	defer configLock.Unlock()
+
+	// Fetch the configuration once, config is updated in place and
+	// compared with prevConfig below.
+	currentBytes, err := getPeerConfig(globalAdminPeers)
+	if err != nil {
+		errorIf(err, "Failed to get config from peers")
+		writeErrorResponseJSON(w, toAdminAPIErrCode(err), r.URL)
+		return
+	}
+	prevConfig, config := &serverConfig{}, &serverConfig{}
+	if err = json.Unmarshal(currentBytes, prevConfig); err == nil {
+		err = json.Unmarshal(currentBytes, config)
+	}
+	if err != nil {
+		errorIf(err, "Failed to parse config from peers")
+		writeErrorResponseJSON(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	if err = setConfigSubsys(config, subsys, subsysBytes); err != nil {
+		if err == errConfigSubsysUnknown {
+			writeErrorResponseJSON(w, toAdminAPIErrCode(err), r.URL)
+			return
+		}
+		errorIf(err, "Invalid configuration of %s", subsys)
+		writeErrorResponseJSON(w, ErrAdminConfigInvalid, r.URL)
+		return
+	}
+
+	// Nothing to do if the configuration did not change.
+	diff := prevConfig.ConfigDiff(config)
+	if diff == "" {
+		writeSetConfigResponse(w, globalAdminPeers, nil, true, diff, r.URL)
+		return
+	}
+
+	configBytes, err := json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		writeErrorResponseJSON(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	// Write the updated config onto a temporary file on all nodes
+	// and rename it to config.json.
+	tmpFileName := fmt.Sprintf(minioConfigTmpFormat, mustGetUUID())
+	errs := writeTmpConfigPeers(globalAdminPeers, tmpFileName, configBytes)
+	rErr := reduceWriteQuorumErrs(errs, nil, len(globalAdminPeers)/2+1)
+	if rErr != nil {
+		writeSetConfigResponse(w, globalAdminPeers, errs, false, diff, r.URL)
+		return
+	}
+	errs = commitConfigPeers(globalAdminPeers, tmpFileName)
+	rErr = reduceWriteQuorumErrs(errs, nil, len(globalAdminPeers)/2+1)
+	if rErr != nil {
+		writeSetConfigResponse(w, globalAdminPeers, errs, false, diff, r.URL)
+		return
+	}
+
+	if configSubsysNeedsRestart(subsys) {
+		writeSetConfigResponse(w, globalAdminPeers, errs, true, diff, r.URL)
+
+		// Restart all node for the modified config to take effect.
+		sendServiceCmd(globalAdminPeers, serviceRestart)
+		return
+	}
+
+	// Apply the committed config on all nodes.
+	errs = reloadConfigPeers(globalAdminPeers)
+	rErr = reduceWriteQuorumErrs(errs, nil, len(globalAdminPeers)/2+1)
+	writeSetConfigResponse(w, globalAdminPeers, errs, rErr == nil, diff, r.URL)
+}
+
 // ConfigCredsHandler - POST /minio/admin/v1/config/credential
 // ----------
 // Update credentials in a minio server. In a distributed setup,
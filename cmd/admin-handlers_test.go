@@ -866,6 +866,108 @@ func TestSetConfigHandler(t *testing.T) {
 	}
 }
 
+// TestConfigSubsysHandlers - test for GetConfigSubsysHandler and
+// SetConfigSubsysHandler.
+func TestConfigSubsysHandlers(t *testing.T) {
+	adminTestBed, err := prepareAdminXLTestBed()
+	if err != nil {
+		t.Fatal("Failed to initialize a single node XL backend for admin handler tests.")
+	}
+	defer adminTestBed.TearDown()
+
+	// Initialize admin peers to make admin RPC calls.
+	globalMinioAddr = "127.0.0.1:9000"
+	initGlobalAdminPeers(mustGetNewEndpointList("http://127.0.0.1:9000/d1"))
+
+	testCases := []struct {
+		method       string
+		subsys       string
+		body         string
+		expectedCode int
+		expectedBody string
+		expectedDiff string
+	}{
+		// 1. Update region.
+		{http.MethodPut, "region", `"us-west-2"`, http.StatusOK, "", "Region configuration differs"},
+		// 2. Get updated region.
+		{http.MethodGet, "region", "", http.StatusOK, `"us-west-2"`, ""},
+		// 3. Update region to the same value.
+		{http.MethodPut, "region", `"us-west-2"`, http.StatusOK, "", ""},
+		// 4. Add a webhook target.
+		{http.MethodPut, "notify/webhook/2", `{"enable":false,"endpoint":"http://localhost:8080"}`, http.StatusOK, "", "Webhook Notification configuration differs for target 2"},
+		// 5. Get added webhook target.
+		{http.MethodGet, "notify/webhook/2", "", http.StatusOK, `{"enable":false,"endpoint":"http://localhost:8080"}`, ""},
+		// 6. Get unknown webhook target.
+		{http.MethodGet, "notify/webhook/3", "", http.StatusNotFound, "", ""},
+		// 7. Update unknown notification target type.
+		{http.MethodPut, "notify/unknown/1", `{}`, http.StatusNotFound, "", ""},
+		// 8. Update unknown subsystem.
+		{http.MethodPut, "unknown", `{}`, http.StatusNotFound, "", ""},
+		// 9. Invalid storage class parity.
+		{http.MethodPut, "storageclass", `{"standard":"EC:1","rrs":""}`, http.StatusBadRequest, "", ""},
+		// 10. Invalid region.
+		{http.MethodPut, "region", `""`, http.StatusBadRequest, "", ""},
+		// 11. Limit the uploads of a bucket.
+		{http.MethodPut, "bandwidth", `{"buckets":{"backups":{"upload":1048576,"download":0}}}`, http.StatusOK, "", "Bandwidth configuration differs"},
+		// 12. Get updated bandwidth limits.
+		{http.MethodGet, "bandwidth", "", http.StatusOK, `{"buckets":{"backups":{"upload":1048576,"download":0}}}`, ""},
+		// 13. Negative bandwidth limit.
+		{http.MethodPut, "bandwidth", `{"accessKeys":{"minio":{"upload":-1,"download":0}}}`, http.StatusBadRequest, "", ""},
+		// 14. Map an access key to a POSIX owner.
+		{http.MethodPut, "posix", `{"umask":"027","enforce":true,"users":{"alice":{"uid":1001,"gid":100}}}`, http.StatusOK, "", "POSIX configuration differs"},
+		// 15. Get updated POSIX configuration.
+		{http.MethodGet, "posix", "", http.StatusOK, `{"umask":"027","enforce":true,"users":{"alice":{"uid":1001,"gid":100}}}`, ""},
+		// 16. Invalid umask.
+		{http.MethodPut, "posix", `{"umask":"0999","enforce":false}`, http.StatusBadRequest, "", ""},
+	}
+
+	for i, testCase := range testCases {
+		req, err := buildAdminRequest(url.Values{}, testCase.method, "/config/"+testCase.subsys,
+			int64(len(testCase.body)), bytes.NewReader([]byte(testCase.body)))
+		if err != nil {
+			t.Fatalf("Test %d: Failed to construct config request - %v", i+1, err)
+		}
+
+		rec := httptest.NewRecorder()
+		adminTestBed.mux.ServeHTTP(rec, req)
+		if rec.Code != testCase.expectedCode {
+			t.Fatalf("Test %d: Expected status code %d, got %d - %s", i+1, testCase.expectedCode, rec.Code, rec.Body.String())
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+
+		if testCase.method == http.MethodGet {
+			if rec.Body.String() != testCase.expectedBody {
+				t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expectedBody, rec.Body.String())
+			}
+			continue
+		}
+
+		result := setConfigResult{}
+		if err = json.NewDecoder(rec.Body).Decode(&result); err != nil {
+			t.Fatalf("Test %d: Failed to decode set config result json %v", i+1, err)
+		}
+		if !result.Status {
+			t.Errorf("Test %d: Expected set-config to succeed, but failed", i+1)
+		}
+		if result.Diff != testCase.expectedDiff {
+			t.Errorf("Test %d: Expected diff %q, got %q", i+1, testCase.expectedDiff, result.Diff)
+		}
+	}
+
+	// Updated configuration is applied without a restart.
+	if region := globalServerConfig.GetRegion(); region != "us-west-2" {
+		t.Errorf("Expected region us-west-2 to be applied, got %s", region)
+	}
+	if _, ok := globalServerConfig.Notify.Webhook["2"]; !ok {
+		t.Errorf("Expected webhook target 2 to be applied")
+	}
+	if upload, _ := globalBandwidthThrottler.Limiters("backups", httptest.NewRequest(http.MethodPut, "/backups/object", nil)); len(upload) != 1 {
+		t.Errorf("Expected the bandwidth limit of bucket backups to be applied")
+	}
+}
+
 func TestAdminServerInfo(t *testing.T) {
 	adminTestBed, err := prepareAdminXLTestBed()
 	if err != nil {
@@ -919,6 +1021,54 @@ func TestAdminServerInfo(t *testing.T) {
 }
 
 // TestToAdminAPIErr - test for toAdminAPIErr helper function.
+func TestWriteBackStatusHandler(t *testing.T) {
+	adminTestBed, err := prepareAdminXLTestBed()
+	if err != nil {
+		t.Fatal("Failed to initialize a single node XL backend for admin handler tests.")
+	}
+	defer adminTestBed.TearDown()
+
+	req, err := buildAdminRequest(url.Values{}, http.MethodGet, "/writeback", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to construct write-back status request - %v", err)
+	}
+	rec := httptest.NewRecorder()
+	adminTestBed.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected write-back status not to be enabled, got %d", rec.Code)
+	}
+
+	stagingDirs, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(stagingDirs)
+	// The write-back layer is found below the other gateway features.
+	objAPI := wrapGatewayTestLayer(t, adminTestBed.objLayer, stagingDirs[0])
+	defer objAPI.Shutdown()
+	globalObjLayerMutex.Lock()
+	globalObjectAPI = objAPI
+	globalObjLayerMutex.Unlock()
+
+	queryVal := url.Values{}
+	queryVal.Set("bucket", "mybucket")
+	if req, err = buildAdminRequest(queryVal, http.MethodGet, "/writeback", 0, nil); err != nil {
+		t.Fatalf("Failed to construct write-back status request - %v", err)
+	}
+	rec = httptest.NewRecorder()
+	adminTestBed.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected to succeed but failed with %d", rec.Code)
+	}
+	var status madmin.WriteBackStatus
+	if err = json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Pending != 0 || status.Objects == nil {
+		t.Errorf("Unexpected write-back status %+v", status)
+	}
+}
+
 func TestToAdminAPIErr(t *testing.T) {
 	testCases := []struct {
 		err            error
@@ -995,7 +1145,7 @@ func TestWriteSetConfigResponse(t *testing.T) {
 	var actualResult setConfigResult
 	for i, test := range testCases {
 		rec := httptest.NewRecorder()
-		writeSetConfigResponse(rec, testPeers, test.errs, test.status, testURL)
+		writeSetConfigResponse(rec, testPeers, test.errs, test.status, "", testURL)
 		resp := rec.Result()
 		jsonBytes, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
@@ -309,6 +309,10 @@ type healSequence struct {
 
 	// the last result index sent to client
 	lastSentResultIndex int64
+
+	// background heal state, set only for heal sequences
+	// started by the server to heal freshly formatted disks.
+	bgHeal *bgHealState
 }
 
 // NewHealSequence - creates healSettings, assumes bucket and
@@ -373,6 +377,14 @@ func (h *healSequence) stop() {
 // sequence automatically resumes. The return value indicates if the
 // operation succeeded.
 func (h *healSequence) pushHealResultItem(r madmin.HealResultItem) error {
+	// Results of background heal sequences are not consumed by
+	// any client, so they are not accumulated.
+	if h.bgHeal != nil {
+		if h.isQuitting() {
+			return errHealStopSignalled
+		}
+		return nil
+	}
 
 	// start a timer to keep an upper time limit to find an empty
 	// slot to add the given heal result - if no slot is found it
@@ -506,12 +518,19 @@ func (h *healSequence) traverseAndHeal() {
 		err = f()
 	}
 
-	// Start with format healing
-	checkErr(h.healDiskFormat)
+	// Start with format healing, background heal sequences are
+	// only started once format.json is healed.
+	if h.bgHeal == nil {
+		checkErr(h.healDiskFormat)
+	}
 
 	// Heal buckets and objects
 	checkErr(h.healBuckets)
 
+	if h.bgHeal != nil {
+		h.bgHeal.finish(err)
+	}
+
 	if err != nil {
 		h.traverseAndHealDoneCh <- err
 	}
@@ -522,23 +541,35 @@ func (h *healSequence) traverseAndHeal() {
 // healDiskFormat - heals format.json, return value indicates if a
 // failure error occurred.
 func (h *healSequence) healDiskFormat() error {
+	hres, err := healDiskFormat(h.settings.DryRun)
+	if err != nil {
+		return err
+	}
+
+	// Push format heal result
+	return h.pushHealResultItem(hres)
+}
+
+// healDiskFormat - heals format.json on all disks and reloads the
+// object layer if some disk was healed.
+func healDiskFormat(dryRun bool) (hres madmin.HealResultItem, err error) {
 	// Get current object layer instance.
 	objectAPI := newObjectLayerFn()
 	if objectAPI == nil {
-		return errServerNotInitialized
+		return hres, errServerNotInitialized
 	}
 
 	// Acquire lock on format.json
 	formatLock := globalNSMutex.NewNSLock(minioMetaBucket, formatConfigFile)
-	if err := formatLock.GetLock(globalHealingTimeout); err != nil {
-		return errFnHealFromAPIErr(err)
+	if err = formatLock.GetLock(globalHealingTimeout); err != nil {
+		return hres, errFnHealFromAPIErr(err)
 	}
 	defer formatLock.Unlock()
 
 	// Create a new set of storage instances to heal format.json.
 	bootstrapDisks, err := initStorageDisks(globalEndpoints)
 	if err != nil {
-		return errFnHealFromAPIErr(err)
+		return hres, errFnHealFromAPIErr(err)
 	}
 
 	// Wrap into retrying disks
@@ -547,9 +578,9 @@ func (h *healSequence) healDiskFormat() error {
 		globalStorageHealthCheckInterval, globalStorageRetryThreshold)
 
 	// Heal format.json on available storage.
-	hres, err := healFormatXL(retryingDisks, h.settings.DryRun)
+	hres, err = healFormatXL(retryingDisks, dryRun)
 	if err != nil {
-		return errFnHealFromAPIErr(err)
+		return hres, errFnHealFromAPIErr(err)
 	}
 
 	// reload object layer global only if we healed some disk
@@ -560,7 +591,7 @@ func (h *healSequence) healDiskFormat() error {
 		// storage.
 		newObjectAPI, err := newXLObjects(retryingDisks)
 		if err != nil {
-			return errFnHealFromAPIErr(err)
+			return hres, errFnHealFromAPIErr(err)
 		}
 
 		// Set object layer with newly formatted storage to
@@ -578,8 +609,7 @@ func (h *healSequence) healDiskFormat() error {
 		reInitPeerDisks(globalAdminPeers)
 	}
 
-	// Push format heal result
-	return h.pushHealResultItem(hres)
+	return hres, nil
 }
 
 // healBuckets - check for all buckets heal or just particular bucket.
@@ -601,6 +631,9 @@ func (h *healSequence) healBuckets() error {
 	}
 
 	for _, bucket := range buckets {
+		if h.bgHeal != nil && h.bgHeal.skipBucket(bucket.Name) {
+			continue
+		}
 		err = h.healBucket(bucket.Name)
 		if err != nil {
 			return err
@@ -650,6 +683,9 @@ func (h *healSequence) healBucket(bucket string) error {
 	}
 
 	marker := ""
+	if h.bgHeal != nil {
+		marker = h.bgHeal.resumeMarker(bucket)
+	}
 	isTruncated := true
 	for isTruncated {
 		objectInfos, err := objectAPI.ListObjectsHeal(bucket,
@@ -686,5 +722,8 @@ func (h *healSequence) healObject(bucket, object string) error {
 	if err != nil {
 		hri.Detail = err.Error()
 	}
+	if h.bgHeal != nil {
+		h.bgHeal.objectHealed(bucket, object, err)
+	}
 	return h.pushHealResultItem(hri)
 }
@@ -52,6 +52,8 @@ func registerAdminRouter(mux *router.Router) {
 
 	// Info operations
 	adminV1Router.Methods(http.MethodGet).Path("/info").HandlerFunc(adminAPI.ServerInfoHandler)
+	// Write-back status
+	adminV1Router.Methods(http.MethodGet).Path("/writeback").HandlerFunc(adminAPI.WriteBackStatusHandler)
 
 	/// Lock operations
 
@@ -75,4 +77,25 @@ func registerAdminRouter(mux *router.Router) {
 	adminV1Router.Methods(http.MethodGet).Path("/config").HandlerFunc(adminAPI.GetConfigHandler)
 	// Set config
 	adminV1Router.Methods(http.MethodPut).Path("/config").HandlerFunc(adminAPI.SetConfigHandler)
+	// Get config of a subsystem
+	adminV1Router.Methods(http.MethodGet).Path("/config/{subsys:.+}").HandlerFunc(adminAPI.GetConfigSubsysHandler)
+	// Set config of a subsystem
+	adminV1Router.Methods(http.MethodPut).Path("/config/{subsys:.+}").HandlerFunc(adminAPI.SetConfigSubsysHandler)
+}
+
+// registerGatewayAdminRouter - Add handler functions for the admin API
+// routes served by gateways.
+func registerGatewayAdminRouter(mux *router.Router) {
+
+	adminAPI := adminAPIHandlers{}
+	// Admin router
+	adminRouter := mux.NewRoute().PathPrefix(adminAPIPathPrefix).Subrouter()
+
+	// Version handler
+	adminRouter.Methods(http.MethodGet).Path("/version").HandlerFunc(adminAPI.VersionHandler)
+
+	adminV1Router := adminRouter.PathPrefix("/v1").Subrouter()
+
+	// Write-back status
+	adminV1Router.Methods(http.MethodGet).Path("/writeback").HandlerFunc(adminAPI.WriteBackStatusHandler)
 }
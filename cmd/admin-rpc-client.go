@@ -40,6 +40,7 @@ const (
 	getConfigRPC      = "Admin.GetConfig"
 	writeTmpConfigRPC = "Admin.WriteTmpConfig"
 	commitConfigRPC   = "Admin.CommitConfig"
+	reloadConfigRPC   = "Admin.ReloadConfig"
 )
 
 // localAdminClient - represents admin operation to be executed locally.
@@ -62,6 +63,7 @@ type adminCmdRunner interface {
 	GetConfig() ([]byte, error)
 	WriteTmpConfig(tmpFileName string, configBytes []byte) error
 	CommitConfig(tmpFileName string) error
+	ReloadConfig() error
 }
 
 var errUnsupportedSignal = fmt.Errorf("unsupported signal: only restart and stop signals are supported")
@@ -145,6 +147,8 @@ func (lc localAdminClient) ServerInfoData() (sid ServerInfoData, e error) {
 		StorageInfo: storage,
 		ConnStats:   globalConnStats.toServerConnStats(),
 		HTTPStats:   globalHTTPStats.toServerHTTPStats(),
+		LockStats:   globalLockMetrics.toServerLockStats(),
+		Admission:   getAdmissionStats(),
 		Properties: ServerProperties{
 			Uptime:   UTCNow().Sub(globalBootTime),
 			Version:  Version,
@@ -236,6 +240,27 @@ func (rc remoteAdminClient) CommitConfig(tmpFileName string) error {
 	return nil
 }
 
+// ReloadConfig - Applies the committed config.json on the local node.
+func (lc localAdminClient) ReloadConfig() error {
+	err := reloadServerConfig()
+	errorIf(err, "Failed to reload config.")
+	return err
+}
+
+// ReloadConfig - Signals a remote node to apply the committed
+// config.json.
+func (rc remoteAdminClient) ReloadConfig() error {
+	args := AuthRPCArgs{}
+	reply := AuthRPCReply{}
+	err := rc.Call(reloadConfigRPC, &args, &reply)
+	if err != nil {
+		errorIf(err, "Failed to reload config.")
+		return err
+	}
+
+	return nil
+}
+
 // adminPeer - represents an entity that implements admin API RPCs.
 type adminPeer struct {
 	addr      string
@@ -634,3 +659,26 @@ func commitConfigPeers(peers adminPeers, tmpFileName string) []error {
 	// Return errors (if any) received during rename.
 	return errs
 }
+
+// Apply the committed config.json on all nodes.
+func reloadConfigPeers(peers adminPeers) []error {
+	// For a single-node minio server setup.
+	if !globalIsDistXL {
+		return []error{peers[0].cmdRunner.ReloadConfig()}
+	}
+
+	errs := make([]error, len(peers))
+
+	wg := sync.WaitGroup{}
+	for i, peer := range peers {
+		wg.Add(1)
+		go func(idx int, peer adminPeer) {
+			defer wg.Done()
+			errs[idx] = peer.cmdRunner.ReloadConfig()
+		}(i, peer)
+	}
+	wg.Wait()
+
+	// Return errors (if any) received during reload.
+	return errs
+}
@@ -164,6 +164,8 @@ func (s *adminCmd) ServerInfoData(args *AuthRPCArgs, reply *ServerInfoDataReply)
 		StorageInfo: storageInfo,
 		ConnStats:   globalConnStats.toServerConnStats(),
 		HTTPStats:   globalHTTPStats.toServerHTTPStats(),
+		LockStats:   globalLockMetrics.toServerLockStats(),
+		Admission:   getAdmissionStats(),
 	}
 
 	return nil
@@ -241,6 +243,15 @@ func (s *adminCmd) CommitConfig(cArgs *CommitConfigArgs, cReply *CommitConfigRep
 	return err
 }
 
+// ReloadConfig - Applies the committed config.json on this node.
+func (s *adminCmd) ReloadConfig(args *AuthRPCArgs, reply *AuthRPCReply) error {
+	if err := args.IsAuthenticated(); err != nil {
+		return err
+	}
+
+	return reloadServerConfig()
+}
+
 // registerAdminRPCRouter - registers RPC methods for service status,
 // stop and restart commands.
 func registerAdminRPCRouter(mux *router.Router) error {
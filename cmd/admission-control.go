@@ -0,0 +1,312 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/sys"
+	"go.uber.org/atomic"
+)
+
+const (
+	// Environment variables configuring the admission controller.
+	apiRequestsMaxEnv      = "MINIO_API_REQUESTS_MAX"
+	apiRequestsDeadlineEnv = "MINIO_API_REQUESTS_DEADLINE"
+	apiRequestsFairnessEnv = "MINIO_API_REQUESTS_FAIRNESS"
+	apiRequestsWeightsEnv  = "MINIO_API_REQUESTS_WEIGHTS"
+
+	// Default time a request waits to be admitted before it is
+	// rejected with SlowDown.
+	defaultAPIRequestsDeadline = 10 * time.Second
+
+	// Clients queued requests are shared fairly between.
+	fairnessAccessKey = "accesskey"
+	fairnessSourceIP  = "ip"
+)
+
+// parseAdmissionWeights - parses weights of clients in the format
+// `client=weight,...`, clients are access keys or source IPs.
+func parseAdmissionWeights(s string) (map[string]int, error) {
+	weights := make(map[string]int)
+	if s == "" {
+		return weights, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid weight ‘%s’, expected client=weight", entry)
+		}
+		weight, err := strconv.Atoi(entry[i+1:])
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid weight ‘%s’, expected a positive integer", entry[i+1:])
+		}
+		weights[entry[:i]] = weight
+	}
+	return weights, nil
+}
+
+// admissionWaiter - a request waiting to be admitted.
+type admissionWaiter struct {
+	client string
+	// Virtual finish time of the request, requests are admitted in
+	// the order of their finish times.
+	finish float64
+	seq    uint64
+	ready  chan struct{}
+	// Index in the queue, -1 once admitted.
+	index int
+}
+
+// admissionQueue - heap of waiting requests ordered by finish time.
+type admissionQueue []*admissionWaiter
+
+func (q admissionQueue) Len() int { return len(q) }
+
+func (q admissionQueue) Less(i, j int) bool {
+	if q[i].finish != q[j].finish {
+		return q[i].finish < q[j].finish
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q admissionQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *admissionQueue) Push(x interface{}) {
+	w := x.(*admissionWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *admissionQueue) Pop() interface{} {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*q = old[:len(old)-1]
+	return w
+}
+
+// admissionController - limits the number of requests served at once.
+// Requests above the limit wait in a queue for up to a deadline and
+// are admitted by weighted fair queuing between clients, so that one
+// client sending many requests cannot starve the others.
+type admissionController struct {
+	// 64 bit counters come first to be aligned for atomic access
+	// on 32 bit platforms.
+	admitted atomic.Uint64
+	rejected atomic.Uint64
+
+	maxRequests int
+	deadline    time.Duration
+	fairness    string
+	weights     map[string]int
+
+	mu       sync.Mutex
+	inFlight int
+	queue    admissionQueue
+	seq      uint64
+	// Virtual time, the finish time of the last admitted request.
+	virtualTime float64
+	// Finish time of the last queued request of each client.
+	lastFinish map[string]float64
+}
+
+func newAdmissionController(maxRequests int, deadline time.Duration, fairness string, weights map[string]int) *admissionController {
+	return &admissionController{
+		maxRequests: maxRequests,
+		deadline:    deadline,
+		fairness:    fairness,
+		weights:     weights,
+		lastFinish:  make(map[string]float64),
+	}
+}
+
+// client - returns the client r is queued as.
+func (a *admissionController) client(r *http.Request) string {
+	if a.fairness == fairnessAccessKey {
+		// Clients cannot queue as other access keys without
+		// their secret key.
+		if accessKey := getVerifiedAccessKey(r); accessKey != "" {
+			return accessKey
+		}
+	}
+	// Anonymous requests, and requests with invalid signatures are
+	// queued by source IP.
+	return getSourceIPAddress(r)
+}
+
+// Acquire - waits until a request of client is admitted, returns
+// false if the deadline expires or ctx is cancelled first. Admitted
+// requests must call Release.
+func (a *admissionController) Acquire(ctx context.Context, client string) bool {
+	a.mu.Lock()
+	if a.inFlight < a.maxRequests && a.queue.Len() == 0 {
+		a.inFlight++
+		a.mu.Unlock()
+		a.admitted.Inc()
+		return true
+	}
+
+	weight := a.weights[client]
+	if weight == 0 {
+		weight = 1
+	}
+	start := a.virtualTime
+	if lastFinish := a.lastFinish[client]; lastFinish > start {
+		start = lastFinish
+	}
+	a.seq++
+	w := &admissionWaiter{
+		client: client,
+		finish: start + 1/float64(weight),
+		seq:    a.seq,
+		ready:  make(chan struct{}),
+	}
+	a.lastFinish[client] = w.finish
+	heap.Push(&a.queue, w)
+	a.mu.Unlock()
+
+	timer := time.NewTimer(a.deadline)
+	defer timer.Stop()
+	select {
+	case <-w.ready:
+		a.admitted.Inc()
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Admitted while timing out.
+	if w.index < 0 {
+		a.admitted.Inc()
+		return true
+	}
+	heap.Remove(&a.queue, w.index)
+	a.rejected.Inc()
+	return false
+}
+
+// Release - admits the next queued request in place of a finished one.
+func (a *admissionController) Release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.queue.Len() == 0 {
+		a.inFlight--
+		return
+	}
+	w := heap.Pop(&a.queue).(*admissionWaiter)
+	a.virtualTime = w.finish
+	// Clients without later queued requests start over at the
+	// virtual time.
+	for client, lastFinish := range a.lastFinish {
+		if lastFinish <= a.virtualTime {
+			delete(a.lastFinish, client)
+		}
+	}
+	close(w.ready)
+}
+
+// Converts admission statistics into struct to be sent back to the
+// client.
+func (a *admissionController) toServerAdmissionStats() ServerAdmissionStats {
+	a.mu.Lock()
+	inFlight, queued := a.inFlight, a.queue.Len()
+	a.mu.Unlock()
+	return ServerAdmissionStats{
+		MaxRequests: a.maxRequests,
+		InFlight:    inFlight,
+		Queued:      queued,
+		Admitted:    a.admitted.Load(),
+		Rejected:    a.rejected.Load(),
+	}
+}
+
+type admissionControlHandler struct {
+	*admissionController
+	handler http.Handler
+}
+
+// setAdmissionControlHandler middleware limits the number of requests
+// served at once to globalAPIRequestsMax, or the open file limit if
+// not set. Requests wait for up to globalAPIRequestsDeadline to be
+// admitted, the client receives a SlowDown error if the deadline
+// expires first.
+func setAdmissionControlHandler(h http.Handler) http.Handler {
+	maxRequests := globalAPIRequestsMax
+	if maxRequests <= 0 {
+		// Every request uses at least one open file.
+		_, maxLimit, err := sys.GetMaxOpenFileLimit()
+		if err != nil {
+			panic(err)
+		}
+		maxRequests = math.MaxInt32
+		if maxLimit < math.MaxInt32 {
+			maxRequests = int(maxLimit)
+		}
+	}
+
+	globalAdmissionControllerMu.Lock()
+	globalAdmissionController = newAdmissionController(maxRequests,
+		globalAPIRequestsDeadline, globalAPIRequestsFairness, globalAPIRequestsWeights)
+	a := globalAdmissionController
+	globalAdmissionControllerMu.Unlock()
+	return admissionControlHandler{a, h}
+}
+
+func (h admissionControlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Inter-node RPC, admin and browser requests are never queued,
+	// so that distributed operations cannot deadlock on busy servers.
+	if bucket, _ := urlPath2BucketObjectName(r.URL); bucket == minioReservedBucket {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if !h.Acquire(r.Context(), h.client(r)) {
+		// Send an S3 compatible error, SlowDown.
+		writeErrorResponse(w, ErrSlowDown, r.URL)
+		return
+	}
+	defer h.Release()
+
+	h.handler.ServeHTTP(w, r)
+}
+
+// getAdmissionStats - returns the admission statistics of this server.
+func getAdmissionStats() ServerAdmissionStats {
+	globalAdmissionControllerMu.RLock()
+	a := globalAdmissionController
+	globalAdmissionControllerMu.RUnlock()
+	if a == nil {
+		return ServerAdmissionStats{}
+	}
+	return a.toServerAdmissionStats()
+}
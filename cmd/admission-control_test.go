@@ -0,0 +1,197 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseAdmissionWeights(t *testing.T) {
+	testCases := []struct {
+		weights   string
+		expected  map[string]int
+		shouldErr bool
+	}{
+		{"", map[string]int{}, false},
+		{"minio=2", map[string]int{"minio": 2}, false},
+		{"minio=2,192.0.2.3=4", map[string]int{"minio": 2, "192.0.2.3": 4}, false},
+		{"minio", nil, true},
+		{"=2", nil, true},
+		{"minio=0", nil, true},
+		{"minio=two", nil, true},
+	}
+	for i, testCase := range testCases {
+		weights, err := parseAdmissionWeights(testCase.weights)
+		if (err != nil) != testCase.shouldErr {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.shouldErr, err)
+		}
+		if err == nil && !reflect.DeepEqual(weights, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, weights)
+		}
+	}
+}
+
+// waitQueued - waits until n requests are queued in a.
+func waitQueued(t *testing.T, a *admissionController, n int) {
+	for i := 0; i < 1000; i++ {
+		if a.toServerAdmissionStats().Queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected %d queued requests", n)
+}
+
+func TestAdmissionControllerFairness(t *testing.T) {
+	testCases := []struct {
+		weights  map[string]int
+		expected []string
+	}{
+		// The request of client b is admitted before the
+		// later requests of client a.
+		{map[string]int{}, []string{"a", "b", "a", "a"}},
+		// Client b is admitted first with a higher weight.
+		{map[string]int{"b": 4}, []string{"b", "a", "a", "a"}},
+	}
+	for i, testCase := range testCases {
+		a := newAdmissionController(1, time.Minute, fairnessAccessKey, testCase.weights)
+		if !a.Acquire(context.Background(), "a") {
+			t.Fatalf("Test %d: expected first request to be admitted", i+1)
+		}
+
+		admitted := make(chan string)
+		for n, client := range []string{"a", "a", "a", "b"} {
+			go func(client string) {
+				if a.Acquire(context.Background(), client) {
+					admitted <- client
+				}
+			}(client)
+			waitQueued(t, a, n+1)
+		}
+
+		var order []string
+		for range testCase.expected {
+			a.Release()
+			order = append(order, <-admitted)
+		}
+		a.Release()
+		if !reflect.DeepEqual(order, testCase.expected) {
+			t.Errorf("Test %d: expected admission order %v, got %v", i+1, testCase.expected, order)
+		}
+
+		stats := a.toServerAdmissionStats()
+		if stats.InFlight != 0 || stats.Queued != 0 || stats.Admitted != 5 || stats.Rejected != 0 {
+			t.Errorf("Test %d: unexpected stats %+v", i+1, stats)
+		}
+	}
+}
+
+func TestAdmissionControllerClient(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	cred := globalServerConfig.GetCredential()
+	newRequest := func(signed, forged bool) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost:9000/bucket/object", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if signed {
+			req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+			if err := signRequestV4(req, cred.AccessKey, cred.SecretKey); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if forged {
+			req.Header.Set("Authorization", signV4Algorithm+" Credential="+cred.AccessKey+"/20180420/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=0000")
+		}
+		return req
+	}
+
+	testCases := []struct {
+		fairness       string
+		signed, forged bool
+		expected       string
+	}{
+		{fairnessAccessKey, true, false, cred.AccessKey},
+		// Requests are queued as their access key only with a
+		// valid signature.
+		{fairnessAccessKey, false, true, "10.0.0.1"},
+		{fairnessAccessKey, false, false, "10.0.0.1"},
+		{fairnessSourceIP, true, false, "10.0.0.1"},
+	}
+	for i, testCase := range testCases {
+		a := newAdmissionController(1, time.Minute, testCase.fairness, nil)
+		if client := a.client(newRequest(testCase.signed, testCase.forged)); client != testCase.expected {
+			t.Errorf("Test %d: expected client %s, got %s", i+1, testCase.expected, client)
+		}
+	}
+}
+
+func TestAdmissionControlHandler(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	a := newAdmissionController(1, 100*time.Millisecond, fairnessSourceIP, nil)
+	release := make(chan struct{})
+	handler := admissionControlHandler{a, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bucket/object" {
+			<-release
+		}
+	})}
+
+	// Holds the only slot until released.
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bucket/object", nil))
+		close(done)
+	}()
+	for a.toServerAdmissionStats().InFlight != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Requests queued beyond the deadline are rejected.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bucket/object", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+
+	// Admin requests are not queued.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/minio/admin/v1/info", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	close(release)
+	<-done
+	stats := a.toServerAdmissionStats()
+	if stats.InFlight != 0 || stats.Admitted != 1 || stats.Rejected != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
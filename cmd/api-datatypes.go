@@ -44,3 +44,10 @@ type DeleteObjectsRequest struct {
 	// List of objects to be deleted
 	Objects []ObjectIdentifier `xml:"Object"`
 }
+
+// restoreObjectRequest - xml carrying the number of days a transitioned
+// object is restored for.
+type restoreObjectRequest struct {
+	XMLName xml.Name `xml:"RestoreRequest" json:"-"`
+	Days    int      `xml:"Days"`
+}
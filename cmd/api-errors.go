@@ -73,6 +73,7 @@ const (
 	ErrInvalidCopyDest
 	ErrInvalidPolicyDocument
 	ErrInvalidObjectState
+	ErrRestoreAlreadyInProgress
 	ErrMalformedXML
 	ErrMissingContentLength
 	ErrMissingContentMD5
@@ -151,6 +152,8 @@ const (
 
 	// S3 extended errors.
 	ErrContentSHA256Mismatch
+	ErrInvalidCannedACL
+	ErrInvalidTargetBucketForLogging
 
 	// Add new extended error codes here.
 
@@ -182,6 +185,10 @@ const (
 	ErrAdminConfigTooLarge
 	ErrAdminConfigBadJSON
 	ErrAdminCredentialsMismatch
+	ErrAdminConfigUnknownSubsys
+	ErrAdminConfigInvalid
+	ErrAdminConfigEnvOverride
+	ErrAdminWriteBackNotEnabled
 	ErrInsecureClientRequest
 	ErrObjectTampered
 	ErrHealNotImplemented
@@ -190,6 +197,10 @@ const (
 	ErrHealMissingBucket
 	ErrHealAlreadyRunning
 	ErrHealOverlappingPaths
+
+	// Temporary credentials errors
+	ErrInvalidToken
+	ErrExpiredToken
 )
 
 // error code to APIError structure, these fields carry respective
@@ -375,6 +386,11 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "The operation is not valid for the current state of the object.",
 		HTTPStatusCode: http.StatusForbidden,
 	},
+	ErrRestoreAlreadyInProgress: {
+		Code:           "RestoreAlreadyInProgress",
+		Description:    "Object restore is already in progress.",
+		HTTPStatusCode: http.StatusConflict,
+	},
 	ErrAuthorizationHeaderMalformed: {
 		Code:           "AuthorizationHeaderMalformed",
 		Description:    "The authorization header is malformed; the region is wrong; expecting 'us-east-1'.",
@@ -673,6 +689,16 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "The provided 'x-amz-content-sha256' header does not match what was computed.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrInvalidCannedACL: {
+		Code:           "InvalidArgument",
+		Description:    "The canned ACL you provided is not valid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidTargetBucketForLogging: {
+		Code:           "InvalidTargetBucketForLogging",
+		Description:    "The target bucket for logging does not exist.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 
 	/// Minio extensions.
 	ErrStorageFull: {
@@ -756,6 +782,26 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "Credentials in config mismatch with server environment variables",
 		HTTPStatusCode: http.StatusServiceUnavailable,
 	},
+	ErrAdminConfigUnknownSubsys: {
+		Code:           "XMinioAdminConfigUnknownSubsys",
+		Description:    "The specified configuration subsystem does not exist",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrAdminConfigInvalid: {
+		Code:           "XMinioAdminConfigInvalid",
+		Description:    "Configuration provided for the subsystem is invalid",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminConfigEnvOverride: {
+		Code:           "XMinioAdminConfigEnvOverride",
+		Description:    "Configuration of the subsystem is set by server environment variables",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrAdminWriteBackNotEnabled: {
+		Code:           "XMinioAdminWriteBackNotEnabled",
+		Description:    "Write-back mode is not enabled on this gateway",
+		HTTPStatusCode: http.StatusNotImplemented,
+	},
 	ErrInsecureClientRequest: {
 		Code:           "XMinioInsecureClientRequest",
 		Description:    "Cannot respond to plain-text request from TLS-encrypted server",
@@ -824,6 +870,16 @@ var errorCodeResponse = map[APIErrorCode]APIError{
 		Description:    "",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrInvalidToken: {
+		Code:           "InvalidToken",
+		Description:    "The provided token is malformed or otherwise invalid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrExpiredToken: {
+		Code:           "ExpiredToken",
+		Description:    "The provided token has expired.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 
 	// Add your error structure here.
 }
@@ -910,6 +966,8 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrNoSuchUpload
 	case InvalidPart:
 		apiErr = ErrInvalidPart
+	case InvalidPartOrder:
+		apiErr = ErrInvalidPartOrder
 	case InsufficientWriteQuorum:
 		apiErr = ErrWriteQuorum
 	case InsufficientReadQuorum:
@@ -944,6 +1002,10 @@ func toAPIErrorCode(err error) (apiErr APIErrorCode) {
 		apiErr = ErrPartsSizeUnequal
 	case BucketPolicyNotFound:
 		apiErr = ErrNoSuchBucketPolicy
+	case InvalidObjectState:
+		apiErr = ErrInvalidObjectState
+	case RestoreAlreadyInProgress:
+		apiErr = ErrRestoreAlreadyInProgress
 	default:
 		apiErr = ErrInternalError
 	}
@@ -37,6 +37,7 @@ var toAPIErrorCodeTests = []struct {
 	{err: ObjectNameInvalid{}, errCode: ErrInvalidObjectName},
 	{err: InvalidUploadID{}, errCode: ErrNoSuchUpload},
 	{err: InvalidPart{}, errCode: ErrInvalidPart},
+	{err: InvalidPartOrder{}, errCode: ErrInvalidPartOrder},
 	{err: InsufficientReadQuorum{}, errCode: ErrReadQuorum},
 	{err: InsufficientWriteQuorum{}, errCode: ErrWriteQuorum},
 	{err: UnsupportedDelimiter{}, errCode: ErrNotImplemented},
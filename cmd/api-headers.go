@@ -89,6 +89,10 @@ func setObjectHeaders(w http.ResponseWriter, objInfo ObjectInfo, contentRange *h
 
 	// Set all other user defined metadata.
 	for k, v := range objInfo.UserDefined {
+		if k == objectACLKey {
+			// ACLs are only returned by the acl subresource.
+			continue
+		}
 		w.Header().Set(k, v)
 	}
 
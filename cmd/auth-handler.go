@@ -106,7 +106,8 @@ func getRequestAuthType(r *http.Request) authType {
 // It does not accept presigned or JWT or anonymous requests.
 func checkAdminRequestAuthType(r *http.Request, region string) APIErrorCode {
 	s3Err := ErrAccessDenied
-	if getRequestAuthType(r) == authTypeSigned { // we only support V4 (no presign)
+	// Temporary credentials are never granted admin operations.
+	if getRequestAuthType(r) == authTypeSigned && getSessionToken(r) == "" { // we only support V4 (no presign)
 		s3Err = isReqAuthenticated(r, region)
 	}
 	if s3Err != ErrNone {
@@ -130,8 +131,13 @@ func checkRequestAuthType(r *http.Request, bucket, policyAction, region string)
 		s3Error := isReqAuthenticated(r, region)
 		if s3Error != ErrNone {
 			errorIf(errSignatureMismatch, "%s", dumpRequest(r))
+			return s3Error
 		}
-		return s3Error
+		resource, err := getResource(r.URL.Path, r.Host, globalDomainName)
+		if err != nil {
+			return ErrInternalError
+		}
+		return enforceSessionPolicy(r, policyAction, resource)
 	}
 
 	if reqAuthType == authTypeAnonymous && policyAction != "" {
@@ -209,6 +215,27 @@ func isReqAuthenticated(r *http.Request, region string) (s3Error APIErrorCode) {
 	return ErrNone
 }
 
+// getVerifiedAccessKey - returns the access key of a request with a
+// valid signature, empty for anonymous requests and invalid signatures.
+// Only the signature is verified, the request body is not read.
+func getVerifiedAccessKey(r *http.Request) string {
+	var s3Error APIErrorCode
+	switch getRequestAuthType(r) {
+	case authTypeSignedV2, authTypePresignedV2:
+		s3Error = isReqAuthenticatedV2(r)
+	case authTypeSigned, authTypePresigned:
+		s3Error = reqSignatureV4Verify(r, "")
+	case authTypeStreamingSigned:
+		_, _, _, _, s3Error = calculateSeedSignature(r)
+	default:
+		return ""
+	}
+	if s3Error != ErrNone {
+		return ""
+	}
+	return accessLogRequester(r)
+}
+
 // authHandler - handles all the incoming authorization headers and validates them if possible.
 type authHandler struct {
 	handler http.Handler
@@ -245,7 +272,7 @@ func (a authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	} else if aType == authTypeJWT {
 		// Validate Authorization header if its valid for JWT request.
-		if !isHTTPRequestValid(r) {
+		if _, err := webSessionAuthenticate(r); err != nil && !isNodeRequest(r) {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
@@ -110,7 +110,7 @@ func (authClient *AuthRPCClient) Login() (err error) {
 	// Attempt to login if not logged in already.
 	if authClient.authToken == "" {
 		var authToken string
-		authToken, err = authenticateNode(authClient.config.accessKey, authClient.config.secretKey)
+		authToken, err = getNodeAuthToken(authClient.config.accessKey, authClient.config.secretKey)
 		if err != nil {
 			return err
 		}
@@ -252,10 +252,7 @@ func rpcDial(serverAddr, serviceEndpoint string, secureConn bool) (netRPCClient
 			}
 		}
 		// ServerName in tls.Config needs to be specified to support SNI certificates.
-		conn, err = tls.DialWithDialer(d, "tcp", serverAddr, &tls.Config{
-			ServerName: hostname,
-			RootCAs:    globalRootCAs,
-		})
+		conn, err = tls.DialWithDialer(d, "tcp", serverAddr, newNodeTLSConfig(hostname))
 	} else {
 		conn, err = d.Dial("tcp", serverAddr)
 	}
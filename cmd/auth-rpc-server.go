@@ -30,7 +30,7 @@ func (b AuthRPCServer) Login(args *LoginRPCArgs, reply *LoginRPCReply) error {
 	}
 
 	// Return an error if token is not valid.
-	if !isAuthTokenValid(args.AuthToken) {
+	if !args.nodeAuthenticated && !isAuthTokenValid(args.AuthToken) {
 		return errAuthentication
 	}
 
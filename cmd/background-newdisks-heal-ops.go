@@ -0,0 +1,329 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/madmin"
+)
+
+const (
+	// Interval at which local disks are inspected for freshly
+	// replaced drives.
+	defaultMonitorNewDiskInterval = time.Minute
+
+	// Healing tracker file saved under `.minio.sys` on a freshly
+	// formatted disk until all data is healed onto it.
+	healingTrackerFile = "healing.json"
+
+	// Minimum time between two saves of the healing progress.
+	healingTrackerSaveInterval = 30 * time.Second
+
+	// Client address reported for heal sequences started by the
+	// server itself.
+	bgHealingClientAddr = "minio-background-heal"
+
+	// Heal path of background heal sequences, they always heal
+	// the entire namespace.
+	bgHealingPath = "/"
+)
+
+// healingTracker - persisted progress of healing a freshly formatted
+// disk, allows the background heal to resume after a restart.
+type healingTracker struct {
+	// Disk UUID assigned when the disk was formatted.
+	ID string `json:"id"`
+
+	// Time at which the disk was formatted.
+	Started time.Time `json:"started"`
+
+	// Time at which the progress was last saved.
+	LastUpdate time.Time `json:"lastUpdate"`
+
+	// Last bucket and object healed, healing resumes from here.
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+
+	// Number of objects healed so far.
+	ItemsHealed uint64 `json:"itemsHealed"`
+	ItemsFailed uint64 `json:"itemsFailed"`
+}
+
+// newHealingTracker - returns a new healing tracker for disk id.
+func newHealingTracker(id string) *healingTracker {
+	return &healingTracker{
+		ID:      id,
+		Started: UTCNow(),
+	}
+}
+
+// save - writes the healing tracker to `.minio.sys` on the disk.
+func (t *healingTracker) save(disk StorageAPI) error {
+	t.LastUpdate = UTCNow()
+	trackerBytes, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename, so that a partially
+	// written tracker is never seen.
+	tmpFile := mustGetUUID()
+	if err = disk.AppendFile(minioMetaTmpBucket, tmpFile, trackerBytes); err != nil {
+		return err
+	}
+	return disk.RenameFile(minioMetaTmpBucket, tmpFile, minioMetaBucket, healingTrackerFile)
+}
+
+// loadHealingTracker - loads the healing tracker from the disk,
+// returns errFileNotFound if the disk is not being healed.
+func loadHealingTracker(disk StorageAPI) (*healingTracker, error) {
+	trackerBytes, err := disk.ReadAll(minioMetaBucket, healingTrackerFile)
+	if err != nil {
+		return nil, err
+	}
+	t := &healingTracker{}
+	if err = json.Unmarshal(trackerBytes, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// saveHealingTrackers - marks freshly formatted disks for healing,
+// ids carry the disk UUIDs in the same order as disks. Servers pick
+// up the tracker on their local disks and heal data onto them.
+func saveHealingTrackers(disks []StorageAPI, ids []string) {
+	var wg = &sync.WaitGroup{}
+	for index, disk := range disks {
+		if disk == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(disk StorageAPI, id string) {
+			defer wg.Done()
+			err := newHealingTracker(id).save(disk)
+			errorIf(err, "Unable to save healing tracker on %s", disk)
+		}(disk, ids[index])
+	}
+	wg.Wait()
+}
+
+// bgHealState - state of a heal sequence started by the server to
+// heal freshly formatted local disks.
+type bgHealState struct {
+	sync.Mutex
+
+	// local disks being healed and their healing trackers.
+	disks    []StorageAPI
+	trackers []*healingTracker
+
+	// bucket and object after which healing resumes.
+	resumeBucket, resumeObject string
+
+	// last time the trackers were saved.
+	lastSave time.Time
+}
+
+// newBgHealState - initializes background heal state for disks, the
+// heal resumes from the least progressed healing tracker.
+func newBgHealState(disks []StorageAPI, trackers []*healingTracker) *bgHealState {
+	s := &bgHealState{
+		disks:    disks,
+		trackers: trackers,
+		lastSave: UTCNow(),
+	}
+	for i, t := range trackers {
+		if i == 0 || t.Bucket < s.resumeBucket ||
+			(t.Bucket == s.resumeBucket && t.Object < s.resumeObject) {
+			s.resumeBucket, s.resumeObject = t.Bucket, t.Object
+		}
+	}
+	return s
+}
+
+// skipBucket - returns true if bucket was already healed before the
+// heal sequence was resumed.
+func (s *bgHealState) skipBucket(bucket string) bool {
+	return bucket < s.resumeBucket
+}
+
+// resumeMarker - returns the listing marker to start healing objects
+// in the bucket from.
+func (s *bgHealState) resumeMarker(bucket string) string {
+	if bucket == s.resumeBucket {
+		return s.resumeObject
+	}
+	return ""
+}
+
+// objectHealed - records the outcome of healing an object, progress
+// is saved on the disks once every healingTrackerSaveInterval.
+func (s *bgHealState) objectHealed(bucket, object string, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, t := range s.trackers {
+		t.Bucket, t.Object = bucket, object
+		if err != nil {
+			t.ItemsFailed++
+		} else {
+			t.ItemsHealed++
+		}
+	}
+
+	if UTCNow().Sub(s.lastSave) >= healingTrackerSaveInterval {
+		s.save()
+	}
+}
+
+// save - saves healing trackers on all disks, caller holds the lock.
+func (s *bgHealState) save() {
+	for i, t := range s.trackers {
+		errorIf(t.save(s.disks[i]), "Unable to save healing tracker on %s", s.disks[i])
+	}
+	s.lastSave = UTCNow()
+}
+
+// finish - called once the heal sequence ends. On success the disks
+// are fully healed and their trackers are removed, otherwise the
+// progress is saved for the next attempt. Disks on which objects
+// failed to heal keep their tracker, reset to heal all buckets and
+// objects again in the next pass.
+func (s *bgHealState) finish(err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if err != nil {
+		s.save()
+		return
+	}
+	for i, t := range s.trackers {
+		if t.ItemsFailed > 0 {
+			errorIf(fmt.Errorf("%d object(s) failed to heal", t.ItemsFailed),
+				"Unable to fully heal %s, healing it again", s.disks[i])
+			t.Bucket, t.Object = "", ""
+			t.ItemsHealed, t.ItemsFailed = 0, 0
+			errorIf(t.save(s.disks[i]), "Unable to save healing tracker on %s", s.disks[i])
+			continue
+		}
+		derr := s.disks[i].DeleteFile(minioMetaBucket, healingTrackerFile)
+		errorIf(derr, "Unable to remove healing tracker on %s", s.disks[i])
+	}
+}
+
+// newBgHealSequence - creates a heal sequence healing all buckets and
+// objects onto the given freshly formatted disks.
+func newBgHealSequence(disks []StorageAPI, trackers []*healingTracker) *healSequence {
+	hs := madmin.HealOpts{
+		Recursive: true,
+	}
+	h := newHealSequence("", "", bgHealingClientAddr, len(globalEndpoints), hs, false)
+	h.bgHeal = newBgHealState(disks, trackers)
+	return h
+}
+
+// initLocalDisksAutoHeal - starts a routine which watches the local
+// disks of this server and heals freshly replaced drives.
+func initLocalDisksAutoHeal() {
+	go monitorLocalDisksAndHeal()
+}
+
+// monitorLocalDisksAndHeal - checks local disks once at startup, to
+// resume interrupted heals, and then every
+// defaultMonitorNewDiskInterval.
+func monitorLocalDisksAndHeal() {
+	ticker := time.NewTicker(defaultMonitorNewDiskInterval)
+	defer ticker.Stop()
+
+	healLocalDisks(globalEndpoints)
+	for {
+		select {
+		case <-ticker.C:
+			healLocalDisks(globalEndpoints)
+		case <-globalServiceDoneCh:
+			return
+		}
+	}
+}
+
+// healLocalDisks - formats unformatted local disks and launches a
+// background heal sequence for local disks carrying a healing
+// tracker.
+func healLocalDisks(endpoints EndpointList) {
+	// Wait until the object layer is initialized.
+	if newObjectLayerFn() == nil {
+		return
+	}
+
+	// Only one background heal sequence at a time.
+	if h, exists := globalAllHealState.getHealSequence(bgHealingPath); exists && !h.hasEnded() {
+		return
+	}
+
+	var localDisks []StorageAPI
+	for _, endpoint := range endpoints {
+		if !endpoint.IsLocal {
+			continue
+		}
+		disk, err := newPosix(endpoint.Path)
+		if err != nil {
+			continue
+		}
+		localDisks = append(localDisks, disk)
+	}
+
+	// Heal `format.json` if a local disk was replaced, this also
+	// saves healing trackers on the freshly formatted disks.
+	for _, disk := range localDisks {
+		if _, err := loadFormat(disk); errors.Cause(err) == errUnformattedDisk {
+			if _, err = healDiskFormat(false); err != nil {
+				errorIf(err, "Unable to heal format of fresh disk %s", disk)
+				return
+			}
+			break
+		}
+	}
+
+	var healDisks []StorageAPI
+	var trackers []*healingTracker
+	for _, disk := range localDisks {
+		tracker, err := loadHealingTracker(disk)
+		if err != nil {
+			if errors.Cause(err) != errFileNotFound && errors.Cause(err) != errVolumeNotFound {
+				errorIf(err, "Unable to load healing tracker on %s", disk)
+			}
+			continue
+		}
+		healDisks = append(healDisks, disk)
+		trackers = append(trackers, tracker)
+	}
+	if len(healDisks) == 0 {
+		return
+	}
+
+	_, errCode, errMsg := globalAllHealState.LaunchNewHealSequence(newBgHealSequence(healDisks, trackers))
+	if errCode != ErrNone {
+		if errMsg == "" {
+			errMsg = getAPIError(errCode).Description
+		}
+		errorIf(fmt.Errorf("%s", errMsg), "Unable to start background heal of %d fresh disk(s)", len(healDisks))
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// Tests saving and loading of healing trackers.
+func TestHealingTracker(t *testing.T) {
+	disk, diskPath, err := newPosixTestSetup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(diskPath)
+
+	if err = initMetaVolume([]StorageAPI{disk}); err != nil {
+		t.Fatal(err)
+	}
+
+	// No tracker on a disk which is not being healed.
+	if _, err = loadHealingTracker(disk); err != errFileNotFound {
+		t.Fatalf("Expected %s, got %v", errFileNotFound, err)
+	}
+
+	tracker := newHealingTracker("disk-uuid")
+	tracker.Bucket, tracker.Object = "bucket", "object"
+	tracker.ItemsHealed = 10
+	if err = tracker.save(disk); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadHealingTracker(disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ID != tracker.ID || loaded.Bucket != tracker.Bucket ||
+		loaded.Object != tracker.Object || loaded.ItemsHealed != tracker.ItemsHealed {
+		t.Fatalf("Expected %#v, got %#v", tracker, loaded)
+	}
+
+	// A successful heal removes the tracker.
+	s := newBgHealState([]StorageAPI{disk}, []*healingTracker{loaded})
+	s.objectHealed("bucket", "object2", nil)
+	s.finish(nil)
+	if _, err = loadHealingTracker(disk); err != errFileNotFound {
+		t.Fatalf("Expected %s, got %v", errFileNotFound, err)
+	}
+
+	// A heal which failed to heal an object keeps the tracker and
+	// heals everything again in the next pass.
+	s = newBgHealState([]StorageAPI{disk}, []*healingTracker{newHealingTracker("disk-uuid")})
+	s.objectHealed("bucket", "object1", errors.New("heal failed"))
+	s.objectHealed("bucket", "object2", nil)
+	s.finish(nil)
+	if loaded, err = loadHealingTracker(disk); err != nil {
+		t.Fatalf("Expected the tracker to survive failed objects, got %v", err)
+	}
+	if loaded.Bucket != "" || loaded.Object != "" || loaded.ItemsFailed != 0 {
+		t.Fatalf("Expected the healing progress to be reset, got %#v", loaded)
+	}
+	if s = newBgHealState([]StorageAPI{disk}, []*healingTracker{loaded}); s.skipBucket("bucket") || s.resumeMarker("bucket") != "" {
+		t.Fatal("Expected the next pass to heal all buckets and objects")
+	}
+
+	// A failed heal saves the progress made.
+	s = newBgHealState([]StorageAPI{disk}, []*healingTracker{newHealingTracker("disk-uuid")})
+	s.objectHealed("bucket", "object3", errors.New("heal failed"))
+	s.finish(errHealStopSignalled)
+	if loaded, err = loadHealingTracker(disk); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Object != "object3" || loaded.ItemsFailed != 1 {
+		t.Fatalf("Unexpected healing progress %#v", loaded)
+	}
+}
+
+// Tests that background heal resumes from the least progressed disk.
+func TestBgHealStateResume(t *testing.T) {
+	testCases := []struct {
+		trackers     []*healingTracker
+		resumeBucket string
+		resumeObject string
+	}{
+		{[]*healingTracker{{}}, "", ""},
+		{[]*healingTracker{{Bucket: "b", Object: "o"}}, "b", "o"},
+		{[]*healingTracker{{Bucket: "b", Object: "o"}, {Bucket: "a", Object: "z"}}, "a", "z"},
+		{[]*healingTracker{{Bucket: "b", Object: "o"}, {Bucket: "b", Object: "a"}}, "b", "a"},
+		{[]*healingTracker{{Bucket: "b", Object: "o"}, {}}, "", ""},
+	}
+
+	for i, testCase := range testCases {
+		s := newBgHealState(make([]StorageAPI, len(testCase.trackers)), testCase.trackers)
+		if s.resumeBucket != testCase.resumeBucket || s.resumeObject != testCase.resumeObject {
+			t.Errorf("Test %d: expected to resume at %s/%s, got %s/%s", i+1,
+				testCase.resumeBucket, testCase.resumeObject, s.resumeBucket, s.resumeObject)
+		}
+		if testCase.resumeBucket != "" && !s.skipBucket("0") {
+			t.Errorf("Test %d: expected bucket to be skipped", i+1)
+		}
+		if s.skipBucket(testCase.resumeBucket) {
+			t.Errorf("Test %d: expected resume bucket not to be skipped", i+1)
+		}
+		if marker := s.resumeMarker(testCase.resumeBucket); marker != testCase.resumeObject {
+			t.Errorf("Test %d: expected marker %s, got %s", i+1, testCase.resumeObject, marker)
+		}
+	}
+}
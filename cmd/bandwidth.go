@@ -0,0 +1,266 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	humanize "github.com/dustin/go-humanize"
+	"golang.org/x/time/rate"
+)
+
+// Maximum burst of a bandwidth limit, limits allow bursts of a second
+// worth of data up to this size.
+const maxBandwidthBurst = 64 * humanize.MiByte
+
+// bandwidthLimit - upload and download limits in bytes per second,
+// zero means unlimited.
+type bandwidthLimit struct {
+	Upload   int64 `json:"upload"`
+	Download int64 `json:"download"`
+}
+
+// bandwidthConfig - bandwidth limits of S3 API requests by bucket and
+// by access key. A request is throttled by the limits of its bucket
+// and of its access key.
+type bandwidthConfig struct {
+	Buckets    map[string]bandwidthLimit `json:"buckets,omitempty"`
+	AccessKeys map[string]bandwidthLimit `json:"accessKeys,omitempty"`
+}
+
+// Validate - validates the bandwidth configuration.
+func (b bandwidthConfig) Validate() error {
+	for bucket, limit := range b.Buckets {
+		if !IsValidBucketName(bucket) {
+			return fmt.Errorf("bandwidth: invalid bucket name ‘%s’", bucket)
+		}
+		if limit.Upload < 0 || limit.Download < 0 {
+			return fmt.Errorf("bandwidth: negative limit for bucket ‘%s’", bucket)
+		}
+	}
+	for accessKey, limit := range b.AccessKeys {
+		if accessKey == "" {
+			return errors.New("bandwidth: access key cannot be empty")
+		}
+		if limit.Upload < 0 || limit.Download < 0 {
+			return fmt.Errorf("bandwidth: negative limit for access key ‘%s’", accessKey)
+		}
+	}
+	return nil
+}
+
+// bandwidthLimiterKey - identifies the token bucket shared by all
+// requests of a bucket or access key in one direction.
+type bandwidthLimiterKey struct {
+	bucket    string
+	accessKey string
+	upload    bool
+}
+
+// bandwidthThrottler - token buckets of the configured limits, limits
+// are read from the server config on every request so that changes
+// through the admin API apply to new requests immediately.
+type bandwidthThrottler struct {
+	sync.Mutex
+	limiters map[bandwidthLimiterKey]*rate.Limiter
+}
+
+func newBandwidthThrottler() *bandwidthThrottler {
+	return &bandwidthThrottler{
+		limiters: make(map[bandwidthLimiterKey]*rate.Limiter),
+	}
+}
+
+// Global bandwidth throttler of S3 API requests.
+var globalBandwidthThrottler = newBandwidthThrottler()
+
+// limiter - returns the token bucket of key for limit bytes per second,
+// nil if unlimited. The token bucket is replaced when limit changes.
+func (t *bandwidthThrottler) limiter(key bandwidthLimiterKey, limit int64) *rate.Limiter {
+	if limit <= 0 {
+		delete(t.limiters, key)
+		return nil
+	}
+	l, ok := t.limiters[key]
+	if !ok || l.Limit() != rate.Limit(limit) {
+		burst := limit
+		if burst > maxBandwidthBurst {
+			burst = maxBandwidthBurst
+		}
+		l = rate.NewLimiter(rate.Limit(limit), int(burst))
+		t.limiters[key] = l
+	}
+	return l
+}
+
+// Limiters - returns the token buckets throttling the uploads and
+// downloads of request r to bucket. Requests are throttled as their
+// access key only if their signature is valid, so that clients cannot
+// use up the bandwidth of other access keys.
+func (t *bandwidthThrottler) Limiters(bucket string, r *http.Request) (upload, download []*rate.Limiter) {
+	var bucketLimit, accessKeyLimit bandwidthLimit
+	var hasAccessKeyLimits bool
+	globalServerConfigMu.RLock()
+	if globalServerConfig != nil {
+		bucketLimit = globalServerConfig.Bandwidth.Buckets[bucket]
+		hasAccessKeyLimits = len(globalServerConfig.Bandwidth.AccessKeys) > 0
+	}
+	globalServerConfigMu.RUnlock()
+
+	var accessKey string
+	if hasAccessKeyLimits {
+		if accessKey = getVerifiedAccessKey(r); accessKey != "" {
+			globalServerConfigMu.RLock()
+			if globalServerConfig != nil {
+				accessKeyLimit = globalServerConfig.Bandwidth.AccessKeys[accessKey]
+			}
+			globalServerConfigMu.RUnlock()
+		}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	for _, l := range []*rate.Limiter{
+		t.limiter(bandwidthLimiterKey{bucket: bucket, upload: true}, bucketLimit.Upload),
+		t.limiter(bandwidthLimiterKey{accessKey: accessKey, upload: true}, accessKeyLimit.Upload),
+	} {
+		if l != nil {
+			upload = append(upload, l)
+		}
+	}
+	for _, l := range []*rate.Limiter{
+		t.limiter(bandwidthLimiterKey{bucket: bucket}, bucketLimit.Download),
+		t.limiter(bandwidthLimiterKey{accessKey: accessKey}, accessKeyLimit.Download),
+	} {
+		if l != nil {
+			download = append(download, l)
+		}
+	}
+	return upload, download
+}
+
+// waitBandwidth - waits until n bytes are available in all limiters,
+// n must not exceed the smallest burst.
+func waitBandwidth(ctx context.Context, limiters []*rate.Limiter, n int) error {
+	for _, l := range limiters {
+		if err := l.WaitN(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// minBurst - returns the largest number of bytes which can be waited
+// for at once in all limiters.
+func minBurst(limiters []*rate.Limiter) int {
+	burst := limiters[0].Burst()
+	for _, l := range limiters[1:] {
+		if l.Burst() < burst {
+			burst = l.Burst()
+		}
+	}
+	return burst
+}
+
+// throttledReader - throttles reads of a request body.
+type throttledReader struct {
+	io.ReadCloser
+	ctx      context.Context
+	limiters []*rate.Limiter
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if burst := minBurst(t.limiters); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := waitBandwidth(t.ctx, t.limiters, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// throttledResponseWriter - throttles writes of a response body.
+type throttledResponseWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	limiters []*rate.Limiter
+}
+
+func (t *throttledResponseWriter) Write(p []byte) (n int, err error) {
+	burst := minBurst(t.limiters)
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > burst {
+			chunk = chunk[:burst]
+		}
+		if err = waitBandwidth(t.ctx, t.limiters, len(chunk)); err != nil {
+			return n, err
+		}
+		var m int
+		m, err = t.ResponseWriter.Write(chunk)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		p = p[m:]
+	}
+	return n, nil
+}
+
+// Flush - sends buffered data to the client.
+func (t *throttledResponseWriter) Flush() {
+	if f, ok := t.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+type bandwidthThrottleHandler struct {
+	handler http.Handler
+}
+
+// setBandwidthThrottleHandler middleware throttles request and response
+// bodies of S3 API requests to the bandwidth limits of their bucket
+// and access key.
+func setBandwidthThrottleHandler(h http.Handler) http.Handler {
+	return bandwidthThrottleHandler{h}
+}
+
+func (h bandwidthThrottleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := urlPath2BucketObjectName(r.URL)
+	// Admin, browser and RPC requests are never throttled.
+	if bucket == minioReservedBucket {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	upload, download := globalBandwidthThrottler.Limiters(bucket, r)
+	if len(upload) > 0 && r.Body != nil {
+		r.Body = &throttledReader{r.Body, r.Context(), upload}
+	}
+	if len(download) > 0 {
+		w = &throttledResponseWriter{w, r.Context(), download}
+	}
+	h.handler.ServeHTTP(w, r)
+}
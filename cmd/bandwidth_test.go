@@ -0,0 +1,116 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBandwidthConfigValidate(t *testing.T) {
+	testCases := []struct {
+		config    bandwidthConfig
+		shouldErr bool
+	}{
+		{bandwidthConfig{}, false},
+		{bandwidthConfig{Buckets: map[string]bandwidthLimit{"backups": {Upload: 1024}}}, false},
+		{bandwidthConfig{AccessKeys: map[string]bandwidthLimit{"minio": {Download: 1024}}}, false},
+		{bandwidthConfig{Buckets: map[string]bandwidthLimit{"Invalid_Bucket": {Upload: 1024}}}, true},
+		{bandwidthConfig{Buckets: map[string]bandwidthLimit{"backups": {Upload: -1}}}, true},
+		{bandwidthConfig{AccessKeys: map[string]bandwidthLimit{"": {Upload: 1024}}}, true},
+		{bandwidthConfig{AccessKeys: map[string]bandwidthLimit{"minio": {Download: -1}}}, true},
+	}
+	for i, testCase := range testCases {
+		if err := testCase.config.Validate(); (err != nil) != testCase.shouldErr {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, testCase.shouldErr, err)
+		}
+	}
+}
+
+func TestBandwidthThrottleHandler(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	const limit = 64 * 1024
+	cred := globalServerConfig.GetCredential()
+	globalServerConfigMu.Lock()
+	globalServerConfig.Bandwidth = bandwidthConfig{
+		Buckets:    map[string]bandwidthLimit{"throttled": {Upload: limit}},
+		AccessKeys: map[string]bandwidthLimit{cred.AccessKey: {Download: limit}},
+	}
+	globalServerConfigMu.Unlock()
+
+	payload := bytes.Repeat([]byte("a"), 2*limit)
+	handler := setBandwidthThrottleHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.Copy(ioutil.Discard, r.Body); err != nil {
+			t.Error(err)
+		}
+		w.Write(payload)
+	}))
+
+	testCases := []struct {
+		url       string
+		signed    bool
+		forged    bool
+		body      []byte
+		throttled bool
+	}{
+		// Uploads to the bucket are throttled.
+		{"/throttled/object", false, false, payload, true},
+		// Downloads of other buckets are not.
+		{"/other/object", false, false, nil, false},
+		// Downloads with the access key are throttled.
+		{"/other/object", true, false, nil, true},
+		// Requests claiming the access key without a valid
+		// signature are not.
+		{"/other/object", false, true, nil, false},
+		// Admin requests are never throttled.
+		{"/minio/admin/v1/info", true, false, nil, false},
+	}
+	for i, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodPut, "http://localhost:9000"+testCase.url, bytes.NewReader(testCase.body))
+		if testCase.signed {
+			req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+			if err = signRequestV4(req, cred.AccessKey, cred.SecretKey); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if testCase.forged {
+			req.Header.Set("Authorization", signV4Algorithm+" Credential="+cred.AccessKey+"/20180420/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=0000")
+		}
+		rec := httptest.NewRecorder()
+		start := time.Now()
+		handler.ServeHTTP(rec, req)
+		// A burst of a second is allowed, the second half of the
+		// payload takes a second.
+		if throttled := time.Since(start) >= 500*time.Millisecond; throttled != testCase.throttled {
+			t.Errorf("Test %d: expected throttled %v, took %v", i+1, testCase.throttled, time.Since(start))
+		}
+		if !bytes.Equal(rec.Body.Bytes(), payload) {
+			t.Errorf("Test %d: unexpected response body", i+1)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Environment variables configuring the bucket registry shared by
+	// federated clusters, either etcd endpoints separated by commas
+	// or a registry file.
+	etcdEndpointsEnv      = "MINIO_ETCD_ENDPOINTS"
+	bucketRegistryFileEnv = "MINIO_BUCKET_REGISTRY_FILE"
+
+	// Environment variable with the endpoint of this cluster, e.g.
+	// https://minio1.example.com:9000, registered for its buckets.
+	publicEndpointEnv = "MINIO_PUBLIC_ENDPOINT"
+
+	// Environment variable selecting how requests for buckets of
+	// other clusters are served, either proxy (default) or redirect.
+	bucketFederationEnv = "MINIO_BUCKET_FEDERATION"
+
+	bucketFederationProxy    = "proxy"
+	bucketFederationRedirect = "redirect"
+
+	// Header set on proxied requests, they are served locally by the
+	// cluster they are proxied to.
+	bucketFederationProxiedHeader = "X-Minio-Federation-Proxied"
+
+	// Time the owners of buckets looked up in the registry are
+	// cached for.
+	bucketRegistryCacheTTL = 10 * time.Second
+
+	// Time the existence of buckets in this cluster is cached for.
+	localBucketCacheTTL = 10 * time.Second
+)
+
+// initBucketFederation - initializes the bucket registry and the
+// endpoint of this cluster from the environment.
+func initBucketFederation() error {
+	endpoints, file := os.Getenv(etcdEndpointsEnv), os.Getenv(bucketRegistryFileEnv)
+	if endpoints == "" && file == "" {
+		return nil
+	}
+	if endpoints != "" && file != "" {
+		return errors.New("only one of " + etcdEndpointsEnv + " and " + bucketRegistryFileEnv + " can be set")
+	}
+
+	endpoint, err := parsePublicEndpoint(os.Getenv(publicEndpointEnv))
+	if err != nil {
+		return err
+	}
+
+	mode := os.Getenv(bucketFederationEnv)
+	switch mode {
+	case "":
+		mode = bucketFederationProxy
+	case bucketFederationProxy, bucketFederationRedirect:
+	default:
+		return errors.New("unknown value ‘" + mode + "’ in " + bucketFederationEnv + " environment variable")
+	}
+
+	var registry bucketRegistry
+	if endpoints != "" {
+		registry, err = newEtcdBucketRegistry(strings.Split(endpoints, ","))
+	} else {
+		registry, err = newFileBucketRegistry(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	globalBucketRegistry = newCachedBucketRegistry(registry, bucketRegistryCacheTTL)
+	globalPublicEndpoint = endpoint
+	globalBucketFederationMode = mode
+	return nil
+}
+
+// parsePublicEndpoint - returns the endpoint of this cluster as
+// scheme://host.
+func parsePublicEndpoint(endpoint string) (string, error) {
+	if endpoint == "" {
+		return "", errors.New(publicEndpointEnv + " is not set")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.New("invalid endpoint ‘" + endpoint + "’ in " + publicEndpointEnv)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// registerBucket - registers bucket for this cluster, buckets of other
+// clusters can not be created. Returns whether the bucket was newly
+// registered.
+func registerBucket(bucket string) (bool, error) {
+	if globalBucketRegistry == nil {
+		return false, nil
+	}
+	owner, err := globalBucketRegistry.Register(bucket, bucketRegistryEntry{
+		Endpoint: globalPublicEndpoint,
+		Created:  UTCNow(),
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case err == errBucketRegistered && owner.Endpoint == globalPublicEndpoint:
+		return false, nil
+	case err == errBucketRegistered:
+		return false, BucketAlreadyExists{Bucket: bucket}
+	}
+	return false, err
+}
+
+// unregisterBucket - removes a bucket of this cluster from the
+// registry.
+func unregisterBucket(bucket string) {
+	if globalBucketRegistry == nil {
+		return
+	}
+	errorIf(globalBucketRegistry.Unregister(bucket), "Unable to unregister bucket %s", bucket)
+}
+
+// makeFederatedBucket - registers bucket for this cluster and creates
+// it, the registration is undone if the bucket can not be created.
+func makeFederatedBucket(objAPI ObjectLayer, bucket, location string) error {
+	// Bucket names are unique across federated clusters.
+	registered, err := registerBucket(bucket)
+	if err != nil {
+		return err
+	}
+	err = objAPI.MakeBucketWithLocation(bucket, location)
+	globalLocalBuckets.invalidate(bucket)
+	if err != nil {
+		if registered {
+			unregisterBucket(bucket)
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteFederatedBucket - deletes bucket and removes it from the
+// registry.
+func deleteFederatedBucket(objAPI ObjectLayer, bucket string) error {
+	err := objAPI.DeleteBucket(bucket)
+	globalLocalBuckets.invalidate(bucket)
+	if err != nil {
+		return err
+	}
+	unregisterBucket(bucket)
+	return nil
+}
+
+// registerLocalBuckets - registers the existing buckets of this
+// cluster, e.g. buckets created before it joined the federation. Fails
+// if a bucket is registered by another cluster.
+func registerLocalBuckets(objAPI ObjectLayer) error {
+	if globalBucketRegistry == nil {
+		return nil
+	}
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return err
+	}
+	for _, bucket := range buckets {
+		owner, err := globalBucketRegistry.Register(bucket.Name, bucketRegistryEntry{
+			Endpoint: globalPublicEndpoint,
+			Created:  bucket.Created,
+		})
+		if err == errBucketRegistered && owner.Endpoint != globalPublicEndpoint {
+			return fmt.Errorf("bucket ‘%s’ is registered by %s", bucket.Name, owner.Endpoint)
+		}
+		if err != nil && err != errBucketRegistered {
+			return err
+		}
+	}
+	return nil
+}
+
+// localBucketCache - caches whether buckets exist in this cluster for
+// ttl, buckets created or deleted through this server are seen right
+// away, those of other servers of the cluster once the cached entries
+// expired.
+type localBucketCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]localBucketCacheEntry
+}
+
+// localBucketCacheEntry - result of a lookup of a bucket.
+type localBucketCacheEntry struct {
+	exists  bool
+	expires time.Time
+}
+
+// newLocalBucketCache - returns a cache keeping the existence of
+// buckets for ttl.
+func newLocalBucketCache(ttl time.Duration) *localBucketCache {
+	return &localBucketCache{
+		ttl:     ttl,
+		entries: make(map[string]localBucketCacheEntry),
+	}
+}
+
+// exists - returns whether bucket exists in objAPI, looks it up if it
+// is not cached or expired. Lookups failing for other reasons than a
+// missing bucket are not cached.
+func (c *localBucketCache) exists(objAPI ObjectLayer, bucket string) bool {
+	now := UTCNow()
+	c.mu.Lock()
+	cached, ok := c.entries[bucket]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.exists
+	}
+
+	_, err := objAPI.GetBucketInfo(bucket)
+	if err != nil && !isErrBucketNotFound(err) {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedBucketRegistryEntries {
+		for b, cached := range c.entries {
+			if !now.Before(cached.expires) {
+				delete(c.entries, b)
+			}
+		}
+		if len(c.entries) >= maxCachedBucketRegistryEntries {
+			c.entries = make(map[string]localBucketCacheEntry)
+		}
+	}
+	c.entries[bucket] = localBucketCacheEntry{err == nil, now.Add(c.ttl)}
+	return err == nil
+}
+
+// invalidate - removes the cached entry of bucket.
+func (c *localBucketCache) invalidate(bucket string) {
+	c.mu.Lock()
+	delete(c.entries, bucket)
+	c.mu.Unlock()
+}
+
+// listFederatedBuckets - returns the buckets of this cluster along with
+// the registered buckets of other clusters.
+func listFederatedBuckets(buckets []BucketInfo) ([]BucketInfo, error) {
+	if globalBucketRegistry == nil {
+		return buckets, nil
+	}
+	entries, err := globalBucketRegistry.List()
+	if err != nil {
+		return nil, err
+	}
+	for bucket, entry := range entries {
+		if entry.Endpoint != globalPublicEndpoint {
+			buckets = append(buckets, BucketInfo{Name: bucket, Created: entry.Created})
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Name < buckets[j].Name
+	})
+	return buckets, nil
+}
+
+// bucketFederationHandler - serves requests for buckets registered by
+// other clusters by proxying them to, or redirecting them to, the
+// cluster owning the bucket.
+type bucketFederationHandler struct {
+	handler http.Handler
+	proxy   *httputil.ReverseProxy
+}
+
+// setBucketFederationHandler - forwards requests for buckets of other
+// clusters.
+func setBucketFederationHandler(h http.Handler) http.Handler {
+	return bucketFederationHandler{
+		handler: h,
+		proxy: &httputil.ReverseProxy{
+			// Requests are sent unchanged, the owner verifies their
+			// signature for the original host.
+			Director:  func(r *http.Request) {},
+			Transport: NewCustomHTTPTransport(),
+		},
+	}
+}
+
+func (h bucketFederationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if globalBucketRegistry == nil || r.Header.Get(bucketFederationProxiedHeader) != "" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	if guessIsRPCReq(r) || isAdminReq(r) || isStorageRESTReq(r) {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	resource, err := getResource(r.URL.Path, r.Host, globalDomainName)
+	if err != nil {
+		writeErrorResponse(w, ErrInvalidRequest, r.URL)
+		return
+	}
+	bucket, _ := path2BucketAndObject(resource)
+	if bucket == "" || isMinioReservedBucket(bucket) || isMinioMetaBucket(bucket) {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	// Buckets of this cluster are served locally without looking
+	// them up in the registry.
+	if objAPI := newObjectLayerFn(); objAPI != nil && globalLocalBuckets.exists(objAPI, bucket) {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	owner, err := globalBucketRegistry.Get(bucket)
+	if err == errBucketNotRegistered || (err == nil && owner.Endpoint == globalPublicEndpoint) {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	if err != nil {
+		errorIf(err, "Unable to look up bucket %s in the bucket registry", bucket)
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		return
+	}
+
+	target, err := url.Parse(owner.Endpoint)
+	if err != nil {
+		errorIf(err, "Invalid endpoint registered for bucket %s", bucket)
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		return
+	}
+	if globalBucketFederationMode == bucketFederationRedirect {
+		// Clients are redirected to path-style requests of the owner.
+		location := *r.URL
+		location.Scheme, location.Host, location.Path, location.RawPath = target.Scheme, target.Host, resource, ""
+		http.Redirect(w, r, location.String(), http.StatusTemporaryRedirect)
+		return
+	}
+
+	outreq := r.WithContext(r.Context())
+	outreq.URL = &url.URL{
+		Scheme:   target.Scheme,
+		Host:     target.Host,
+		Path:     r.URL.Path,
+		RawPath:  r.URL.RawPath,
+		RawQuery: r.URL.RawQuery,
+	}
+	outreq.Header = cloneHeader(r.Header)
+	outreq.Header.Set(bucketFederationProxiedHeader, globalPublicEndpoint)
+	h.proxy.ServeHTTP(w, outreq)
+}
@@ -0,0 +1,303 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setBucketFederationTest - federates this cluster, served at
+// http://minio1:9000, with an in-memory registry.
+func setBucketFederationTest(t *testing.T, mode string) func() {
+	registry, err := newFileBucketRegistry("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalBucketRegistry = registry
+	globalPublicEndpoint = "http://minio1:9000"
+	globalBucketFederationMode = mode
+	globalLocalBuckets = newLocalBucketCache(localBucketCacheTTL)
+	return func() {
+		globalBucketRegistry = nil
+		globalPublicEndpoint = ""
+		globalBucketFederationMode = ""
+	}
+}
+
+func TestInitBucketFederation(t *testing.T) {
+	defer setBucketFederationTest(t, "")()
+	globalBucketRegistry = nil
+	defer func() {
+		for _, env := range []string{etcdEndpointsEnv, bucketRegistryFileEnv, publicEndpointEnv, bucketFederationEnv} {
+			os.Unsetenv(env)
+		}
+	}()
+
+	file := filepath.Join(globalTestTmpDir, mustGetUUID()+".json")
+	testCases := []struct {
+		etcd, file, endpoint, mode string
+		success                    bool
+	}{
+		{"", "", "", "", true},
+		{"http://etcd1:2379,http://etcd2:2379", "", "https://minio1.example.com:9000/", "", true},
+		{"", file, "http://minio1:9000", "redirect", true},
+		{"http://etcd1:2379", file, "http://minio1:9000", "", false},
+		{"http://etcd1:2379", "", "", "", false},
+		{"http://etcd1:2379", "", "minio1:9000", "", false},
+		{"http://etcd1:2379", "", "http://minio1:9000", "forward", false},
+		{"etcd1:2379", "", "http://minio1:9000", "", false},
+	}
+	for i, testCase := range testCases {
+		os.Setenv(etcdEndpointsEnv, testCase.etcd)
+		os.Setenv(bucketRegistryFileEnv, testCase.file)
+		os.Setenv(publicEndpointEnv, testCase.endpoint)
+		os.Setenv(bucketFederationEnv, testCase.mode)
+		if err := initBucketFederation(); (err == nil) != testCase.success {
+			t.Errorf("Test %d: Unexpected result %v", i+1, err)
+		}
+	}
+	if globalPublicEndpoint != "http://minio1:9000" || globalBucketFederationMode != bucketFederationRedirect {
+		t.Errorf("Unexpected federation %s %s", globalPublicEndpoint, globalBucketFederationMode)
+	}
+}
+
+func TestRegisterBucket(t *testing.T) {
+	defer setBucketFederationTest(t, bucketFederationProxy)()
+
+	if registered, err := registerBucket("bucket"); err != nil || !registered {
+		t.Fatalf("Expected bucket to be registered, got %v", err)
+	}
+	// Buckets of this cluster may be created again, e.g. after a
+	// failed registration.
+	if registered, err := registerBucket("bucket"); err != nil || registered {
+		t.Fatalf("Expected bucket to be registered already, got %v", err)
+	}
+	if _, err := globalBucketRegistry.Register("remote", bucketRegistryEntry{Endpoint: "http://minio2:9000"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registerBucket("remote"); toAPIErrorCode(err) != ErrBucketAlreadyExists {
+		t.Fatalf("Expected duplicate bucket to be rejected, got %v", err)
+	}
+
+	buckets, err := listFederatedBuckets([]BucketInfo{{Name: "bucket"}, {Name: "unregistered"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 3 || buckets[0].Name != "bucket" || buckets[1].Name != "remote" || buckets[2].Name != "unregistered" {
+		t.Errorf("Unexpected buckets %v", buckets)
+	}
+
+	unregisterBucket("bucket")
+	if _, err = globalBucketRegistry.Get("bucket"); err != errBucketNotRegistered {
+		t.Errorf("Expected bucket to be unregistered, got %v", err)
+	}
+}
+
+func TestFederatedBuckets(t *testing.T) {
+	obj, _, cleanup := prepareGatewayTest(t, 0)
+	defer cleanup()
+	defer setBucketFederationTest(t, bucketFederationProxy)()
+
+	for _, bucket := range []string{"existing", "conflict"} {
+		if err := obj.MakeBucketWithLocation(bucket, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := globalBucketRegistry.Register("conflict", bucketRegistryEntry{Endpoint: "http://minio2:9000"}); err != nil {
+		t.Fatal(err)
+	}
+	// Existing buckets are registered, buckets of other clusters fail.
+	if err := registerLocalBuckets(obj); err == nil {
+		t.Fatal("Expected bucket registered by another cluster to fail")
+	}
+	if err := globalBucketRegistry.Unregister("conflict"); err != nil {
+		t.Fatal(err)
+	}
+	if err := registerLocalBuckets(obj); err != nil {
+		t.Fatal(err)
+	}
+	for _, bucket := range []string{"existing", "conflict"} {
+		if owner, err := globalBucketRegistry.Get(bucket); err != nil || owner.Endpoint != globalPublicEndpoint {
+			t.Errorf("Expected %s to be registered, got %v, %v", bucket, owner, err)
+		}
+	}
+
+	if _, err := globalBucketRegistry.Register("remote", bucketRegistryEntry{Endpoint: "http://minio2:9000"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := makeFederatedBucket(obj, "remote", ""); toAPIErrorCode(err) != ErrBucketAlreadyExists {
+		t.Errorf("Expected bucket of another cluster to be rejected, got %v", err)
+	}
+	if _, err := obj.GetBucketInfo("remote"); !isBucketNotFound(err) {
+		t.Errorf("Expected rejected bucket not to be created, got %v", err)
+	}
+	// Buckets created or deleted are seen by the local bucket cache.
+	if globalLocalBuckets.exists(obj, "bucket") {
+		t.Fatal("Expected bucket not to exist")
+	}
+	if err := makeFederatedBucket(obj, "bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	if !globalLocalBuckets.exists(obj, "bucket") {
+		t.Error("Expected created bucket to exist")
+	}
+	if owner, err := globalBucketRegistry.Get("bucket"); err != nil || owner.Endpoint != globalPublicEndpoint {
+		t.Errorf("Expected bucket to be registered, got %v, %v", owner, err)
+	}
+	// Failing to create the bucket undoes its registration.
+	if err := obj.MakeBucketWithLocation("unregistered", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := makeFederatedBucket(obj, "unregistered", ""); err == nil {
+		t.Fatal("Expected existing bucket to fail")
+	}
+	if _, err := globalBucketRegistry.Get("unregistered"); err != errBucketNotRegistered {
+		t.Errorf("Expected bucket to be unregistered, got %v", err)
+	}
+	if !globalLocalBuckets.exists(obj, "existing") {
+		t.Fatal("Expected bucket to exist")
+	}
+	if err := deleteFederatedBucket(obj, "existing"); err != nil {
+		t.Fatal(err)
+	}
+	if globalLocalBuckets.exists(obj, "existing") {
+		t.Error("Expected deleted bucket not to exist")
+	}
+	if _, err := globalBucketRegistry.Get("existing"); err != errBucketNotRegistered {
+		t.Errorf("Expected deleted bucket to be unregistered, got %v", err)
+	}
+}
+
+func TestLocalBucketCache(t *testing.T) {
+	obj, _, cleanup := prepareGatewayTest(t, 0)
+	defer cleanup()
+
+	c := newLocalBucketCache(time.Hour)
+	if c.exists(obj, "bucket") {
+		t.Fatal("Expected bucket not to exist")
+	}
+	// Changes made directly in the object layer are seen once the
+	// cached entry is invalidated or expired.
+	if err := obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	if c.exists(obj, "bucket") {
+		t.Error("Expected missing bucket to be cached")
+	}
+	c.invalidate("bucket")
+	if !c.exists(obj, "bucket") {
+		t.Error("Expected bucket to exist")
+	}
+	if err := obj.DeleteBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if !c.exists(obj, "bucket") {
+		t.Error("Expected existing bucket to be cached")
+	}
+	c.ttl = 0
+	c.invalidate("bucket")
+	if c.exists(obj, "bucket") {
+		t.Error("Expected deleted bucket not to exist")
+	}
+	if err := obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	if !c.exists(obj, "bucket") {
+		t.Error("Expected expired entry to be looked up again")
+	}
+}
+
+func TestBucketFederationHandler(t *testing.T) {
+	obj, _, cleanup := prepareGatewayTest(t, 0)
+	defer cleanup()
+	defer setBucketFederationTest(t, bucketFederationProxy)()
+
+	// Cluster owning the remote bucket.
+	var proxied *http.Request
+	owner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer owner.Close()
+
+	for bucket, endpoint := range map[string]string{"local": globalPublicEndpoint, "remote": owner.URL, "moved": owner.URL} {
+		if _, err := globalBucketRegistry.Register(bucket, bucketRegistryEntry{Endpoint: endpoint}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Buckets of this cluster are served locally, whatever the
+	// registry says.
+	if err := obj.MakeBucketWithLocation("moved", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := setBucketFederationHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	testCases := []struct {
+		path   string
+		header string
+		status int
+	}{
+		{"/", "", http.StatusOK},
+		{"/local/object", "", http.StatusOK},
+		{"/unregistered/object", "", http.StatusOK},
+		{"/moved/object", "", http.StatusOK},
+		{"/remote/object?uploads", "", http.StatusAccepted},
+		// Proxied requests are not proxied again.
+		{"/remote/object", "http://minio2:9000", http.StatusOK},
+	}
+	for i, testCase := range testCases {
+		proxied = nil
+		req, err := http.NewRequest(http.MethodGet, "http://minio1:9000"+testCase.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if testCase.header != "" {
+			req.Header.Set(bucketFederationProxiedHeader, testCase.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != testCase.status {
+			t.Errorf("Test %d: Expected status %d, got %d", i+1, testCase.status, rec.Code)
+		}
+		if testCase.status != http.StatusAccepted {
+			continue
+		}
+		// The signed request reaches the owner unchanged.
+		if proxied == nil || proxied.Host != "minio1:9000" || proxied.URL.RequestURI() != testCase.path || proxied.Header.Get(bucketFederationProxiedHeader) != globalPublicEndpoint {
+			t.Errorf("Test %d: Unexpected proxied request %v", i+1, proxied)
+		}
+	}
+
+	// Clients are redirected to the owner in redirect mode.
+	globalBucketFederationMode = bucketFederationRedirect
+	req, err := http.NewRequest(http.MethodGet, "http://minio1:9000/remote/object?versionId=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if location := rec.Header().Get("Location"); rec.Code != http.StatusTemporaryRedirect || location != owner.URL+"/remote/object?versionId=1" {
+		t.Errorf("Unexpected redirect %d %s", rec.Code, location)
+	}
+}
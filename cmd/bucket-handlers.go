@@ -56,13 +56,10 @@ func enforceBucketPolicy(bucket, action, resource, referer, sourceIP string, que
 		return ErrInternalError
 	}
 
-	// Fetch bucket policy, if policy is not set return access denied.
+	// Fetch bucket policy, if policy is not set fall back to canned ACLs.
 	p, err := objAPI.GetBucketPolicy(bucket)
-	if err != nil {
-		return ErrAccessDenied
-	}
-	if reflect.DeepEqual(p, emptyBucketPolicy) {
-		return ErrAccessDenied
+	if err != nil || reflect.DeepEqual(p, emptyBucketPolicy) {
+		return enforceCannedACL(objAPI, action, resource)
 	}
 
 	// Construct resource in 'arn:aws:s3:::examplebucket/object' format.
@@ -81,11 +78,15 @@ func enforceBucketPolicy(bucket, action, resource, referer, sourceIP string, que
 	// Add request source Ip to conditionKeyMap.
 	conditionKeyMap["ip"] = set.CreateStringSet(sourceIP)
 
-	// Validate action, resource and conditions with current policy statements.
-	if !bucketPolicyEvalStatements(action, arn, conditionKeyMap, p.Statements) {
+	// Validate action, resource and conditions with current policy
+	// statements, canned ACLs apply only if no statement matches.
+	switch bucketPolicyEvalEffect(action, arn, conditionKeyMap, p.Statements) {
+	case policyEffectAllow:
+		return ErrNone
+	case policyEffectDeny:
 		return ErrAccessDenied
 	}
-	return ErrNone
+	return enforceCannedACL(objAPI, action, resource)
 }
 
 // Check if the action is allowed on the bucket/prefix.
@@ -223,6 +224,13 @@ func (api objectAPIHandlers) ListBucketsHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	// List the buckets of federated clusters too.
+	bucketsInfo, err = listFederatedBuckets(bucketsInfo)
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
 	// Generate response.
 	response := generateListBucketsResponse(bucketsInfo)
 	encodedSuccessResponse := encodeResponse(response)
@@ -397,13 +405,30 @@ func (api objectAPIHandlers) PutBucketHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	acl, s3Error := getCannedACL(r.Header)
+	if s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+	if acl != "" && acl != cannedACLPrivate && !objectAPI.IsACLSupported() {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
 	// Proceed to creating a bucket.
-	err := objectAPI.MakeBucketWithLocation(bucket, "")
+	err := makeFederatedBucket(objectAPI, bucket, "")
 	if err != nil {
 		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
 		return
 	}
 
+	if acl != "" && acl != cannedACLPrivate {
+		if err = persistAndNotifyBucketACLChange(bucket, acl, objectAPI); err != nil {
+			writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+			return
+		}
+	}
+
 	// Make sure to add Location information here only for bucket
 	w.Header().Set("Location", getLocation(r))
 
@@ -537,6 +562,16 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 		}
 	}
 
+	// The signing access key of the policy, signature V2 or V4.
+	accessKey := formValues.Get("AWSAccessKeyId")
+	if credHeader, s3Error := parseCredentialHeader("Credential=" + formValues.Get("X-Amz-Credential")); s3Error == ErrNone {
+		accessKey = credHeader.accessKey
+	}
+	if s3Error := checkPOSIXPermission(objectAPI, accessKey, bucket, object, true); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
 	// Extract metadata to be saved from received Form.
 	metadata, err := extractMetadataFromHeader(formValues)
 	if err != nil {
@@ -545,6 +580,18 @@ func (api objectAPIHandlers) PostPolicyBucketHandler(w http.ResponseWriter, r *h
 		return
 	}
 
+	// Save the canned ACL of the object, if any.
+	acl := formValues.Get("Acl")
+	if acl != "" && !isValidCannedACL(acl) {
+		writeErrorResponse(w, ErrInvalidCannedACL, r.URL)
+		return
+	}
+	if s3Error := setObjectACL(objectAPI, acl, metadata); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+	setPOSIXOwner(objectAPI, accessKey, metadata)
+
 	hashReader, err := hash.NewReader(fileBody, fileSize, "", "")
 	if err != nil {
 		errorIf(err, "Unable to initialize hashReader.")
@@ -651,7 +698,7 @@ func (api objectAPIHandlers) DeleteBucketHandler(w http.ResponseWriter, r *http.
 	bucket := vars["bucket"]
 
 	// Attempt to delete bucket.
-	if err := objectAPI.DeleteBucket(bucket); err != nil {
+	if err := deleteFederatedBucket(objectAPI, bucket); err != nil {
 		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
 		return
 	}
@@ -0,0 +1,142 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Namespace of bucket logging status documents.
+	bucketLoggingXMLNS = "http://doc.s3.amazonaws.com/2006-03-01"
+
+	// Maximum size of a bucket logging status in PUT requests.
+	maxBucketLoggingStatusSize = 64 * humanize.KiByte
+)
+
+// GetBucketLoggingHandler - GET Bucket logging
+// -----------------
+// This operation returns the access logging configuration of a bucket,
+// an empty BucketLoggingStatus if logging is disabled.
+func (api objectAPIHandlers) GetBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	if !objectAPI.IsLoggingSupported() {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+	if s3Error := checkRequestAuthType(r, "", "", globalServerConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	config, err := readBucketLoggingConfig(bucket, objectAPI)
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	writeSuccessResponseXML(w, encodeResponse(BucketLoggingStatus{
+		XMLNS:          bucketLoggingXMLNS,
+		LoggingEnabled: config,
+	}))
+}
+
+// PutBucketLoggingHandler - PUT Bucket logging
+// -----------------
+// This operation enables access logging of a bucket into a target
+// bucket, or disables it with an empty BucketLoggingStatus.
+func (api objectAPIHandlers) PutBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	if !objectAPI.IsLoggingSupported() {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+	if s3Error := checkRequestAuthType(r, "", "", globalServerConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+
+	if _, err := objectAPI.GetBucketInfo(bucket); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	// If Content-Length is unknown or zero, deny the request.
+	if r.ContentLength == -1 || r.ContentLength == 0 {
+		writeErrorResponse(w, ErrMissingContentLength, r.URL)
+		return
+	}
+	if r.ContentLength > maxBucketLoggingStatusSize {
+		writeErrorResponse(w, ErrEntityTooLarge, r.URL)
+		return
+	}
+
+	var status BucketLoggingStatus
+	if err := xmlDecoder(io.LimitReader(r.Body, maxBucketLoggingStatusSize), &status, r.ContentLength); err != nil {
+		errorIf(err, "Unable to parse bucket logging status XML.")
+		writeErrorResponse(w, ErrMalformedXML, r.URL)
+		return
+	}
+
+	config := status.LoggingEnabled
+	if config != nil {
+		if config.TargetGrants != nil {
+			writeErrorResponse(w, ErrNotImplemented, r.URL)
+			return
+		}
+		if !IsValidObjectPrefix(config.TargetPrefix) {
+			writeErrorResponse(w, ErrInvalidObjectName, r.URL)
+			return
+		}
+		if _, err := objectAPI.GetBucketInfo(config.TargetBucket); err != nil {
+			writeErrorResponse(w, ErrInvalidTargetBucketForLogging, r.URL)
+			return
+		}
+	}
+
+	if err := persistAndNotifyBucketLoggingChange(bucket, config, objectAPI); err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	writeSuccessResponseHeadersOnly(w)
+}
@@ -0,0 +1,484 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+const (
+	// Bucket logging config name.
+	bucketLoggingConfig = "logging.xml"
+
+	// Access logs are delivered at this interval, or earlier when the
+	// records buffered for a bucket exceed accessLogMaxBufferSize.
+	accessLogFlushInterval = 5 * time.Minute
+	accessLogMaxBufferSize = 4 * humanize.MiByte
+
+	// Time format of access log records.
+	accessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+)
+
+// BucketLoggingStatus - access logging configuration of a bucket,
+// logging is disabled if LoggingEnabled is not set.
+// http://docs.aws.amazon.com/AmazonS3/latest/API/RESTBucketPUTlogging.html
+type BucketLoggingStatus struct {
+	XMLName        xml.Name        `xml:"BucketLoggingStatus"`
+	XMLNS          string          `xml:"xmlns,attr,omitempty"`
+	LoggingEnabled *LoggingEnabled `xml:"LoggingEnabled,omitempty"`
+}
+
+// LoggingEnabled - bucket and key prefix access logs are delivered to.
+type LoggingEnabled struct {
+	TargetBucket string
+	TargetPrefix string
+	// Grants on the log objects are not supported.
+	TargetGrants *struct {
+		Grants []Grant `xml:"Grant"`
+	} `xml:"TargetGrants,omitempty"`
+}
+
+// readBucketLoggingConfig - returns the logging configuration of a
+// bucket, nil if logging is disabled.
+func readBucketLoggingConfig(bucket string, objAPI ObjectLayer) (*LoggingEnabled, error) {
+	configPath := pathJoin(bucketConfigPrefix, bucket, bucketLoggingConfig)
+
+	var buffer bytes.Buffer
+	err := objAPI.GetObject(minioMetaBucket, configPath, 0, -1, &buffer, "")
+	if err != nil {
+		if isErrObjectNotFound(err) || isErrIncompleteBody(err) {
+			return nil, nil
+		}
+		errorIf(err, "Unable to load logging configuration for the bucket %s.", bucket)
+		return nil, errors.Cause(err)
+	}
+
+	var status BucketLoggingStatus
+	if err = xml.Unmarshal(buffer.Bytes(), &status); err != nil {
+		errorIf(err, "Unable to parse logging configuration for the bucket %s.", bucket)
+		return nil, err
+	}
+	return status.LoggingEnabled, nil
+}
+
+// writeBucketLoggingConfig - saves the logging configuration of a
+// bucket, buckets without access logging have no config.
+func writeBucketLoggingConfig(bucket string, objAPI ObjectLayer, config *LoggingEnabled) error {
+	if config == nil {
+		if err := removeBucketLoggingConfig(bucket, objAPI); err != nil && !isErrObjectNotFound(err) {
+			return errors.Cause(err)
+		}
+		return nil
+	}
+
+	buf, err := xml.Marshal(BucketLoggingStatus{LoggingEnabled: config})
+	if err != nil {
+		return err
+	}
+	configPath := pathJoin(bucketConfigPrefix, bucket, bucketLoggingConfig)
+	hashReader, err := hash.NewReader(bytes.NewReader(buf), int64(len(buf)), "", getSHA256Hash(buf))
+	if err != nil {
+		errorIf(err, "Unable to set logging configuration for the bucket %s", bucket)
+		return errors.Cause(err)
+	}
+
+	if _, err = objAPI.PutObject(minioMetaBucket, configPath, hashReader, nil); err != nil {
+		errorIf(err, "Unable to set logging configuration for the bucket %s", bucket)
+		return errors.Cause(err)
+	}
+	return nil
+}
+
+// removeBucketLoggingConfig - removes the logging configuration of a
+// bucket, only used during DeleteBucket and to disable logging.
+func removeBucketLoggingConfig(bucket string, objAPI ObjectLayer) error {
+	configPath := pathJoin(bucketConfigPrefix, bucket, bucketLoggingConfig)
+	return objAPI.DeleteObject(minioMetaBucket, configPath)
+}
+
+// accessLogRecord - a request to a bucket, in the format of AWS server
+// access logs.
+// http://docs.aws.amazon.com/AmazonS3/latest/dev/LogFormat.html
+type accessLogRecord struct {
+	Bucket     string
+	Time       time.Time
+	RemoteIP   string
+	Requester  string
+	RequestID  string
+	Operation  string
+	Key        string
+	RequestURI string
+	Status     int
+	ErrorCode  string
+	BytesSent  int64
+	TotalTime  time.Duration
+	Referer    string
+	UserAgent  string
+}
+
+// String - formats the record as a line of an access log.
+func (rec accessLogRecord) String() string {
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	quote := func(s string) string {
+		return `"` + strings.Replace(orDash(s), `"`, `\"`, -1) + `"`
+	}
+	bytesSent := "-"
+	if rec.BytesSent > 0 {
+		bytesSent = fmt.Sprint(rec.BytesSent)
+	}
+	key := "-"
+	if rec.Key != "" {
+		key = url.QueryEscape(rec.Key)
+	}
+	return fmt.Sprintf("%s %s [%s] %s %s %s %s %s %s %d %s %s - %d - %s %s -\n",
+		globalMinioDefaultOwnerID, rec.Bucket, rec.Time.Format(accessLogTimeFormat),
+		orDash(rec.RemoteIP), orDash(rec.Requester), orDash(rec.RequestID), rec.Operation,
+		key, quote(rec.RequestURI), rec.Status, orDash(rec.ErrorCode), bytesSent,
+		rec.TotalTime/time.Millisecond, quote(rec.Referer), quote(rec.UserAgent))
+}
+
+// accessLogOperation - returns the operation of a request as named in
+// AWS access logs, for example REST.GET.OBJECT.
+func accessLogOperation(r *http.Request, object string) string {
+	query := r.URL.Query()
+	method := r.Method
+	if r.Header.Get("X-Amz-Copy-Source") != "" {
+		method = "COPY"
+	}
+
+	resource := "BUCKET"
+	if object != "" {
+		resource = "OBJECT"
+	}
+	switch {
+	case object != "" && query.Get("uploadId") != "" && query.Get("partNumber") != "":
+		resource = "PART"
+	case object != "" && query.Get("uploadId") != "":
+		resource = "UPLOAD"
+	case hasQuery(query, "uploads"):
+		resource = "UPLOADS"
+	case hasQuery(query, "acl"):
+		if object != "" {
+			resource = "ACL_OBJECT"
+		} else {
+			resource = "ACL"
+		}
+	case hasQuery(query, "policy"):
+		resource = "BUCKETPOLICY"
+	case hasQuery(query, "logging"):
+		resource = "LOGGING_STATUS"
+	case hasQuery(query, "location"):
+		resource = "LOCATION"
+	case hasQuery(query, "notification"):
+		resource = "NOTIFICATION"
+	case hasQuery(query, "delete"):
+		resource = "MULTI_OBJECT_DELETE"
+	}
+	return "REST." + method + "." + resource
+}
+
+func hasQuery(query url.Values, key string) bool {
+	_, ok := query[key]
+	return ok
+}
+
+// accessLogRequester - returns the access key a request is signed with,
+// empty for anonymous requests.
+func accessLogRequester(r *http.Request) string {
+	// Credentials are "<access key>/<scope>" in signature V4, and
+	// "<access key>:<signature>" in signature V2 headers.
+	credential := r.URL.Query().Get("X-Amz-Credential")
+	if credential == "" {
+		credential = r.URL.Query().Get("AWSAccessKeyId")
+	}
+	if auth := r.Header.Get("Authorization"); credential == "" && auth != "" {
+		if i := strings.Index(auth, "Credential="); i >= 0 {
+			credential = auth[i+len("Credential="):]
+		} else if strings.HasPrefix(auth, signV2Algorithm+" ") {
+			credential = strings.TrimPrefix(auth, signV2Algorithm+" ")
+		}
+	}
+	if i := strings.IndexAny(credential, "/:"); i >= 0 {
+		credential = credential[:i]
+	}
+	return credential
+}
+
+// accessLogDestination - source bucket and target of buffered records.
+type accessLogDestination struct {
+	bucket       string
+	targetBucket string
+	targetPrefix string
+}
+
+// bucketAccessLogger - buffers the access log records of the buckets
+// with logging enabled, and delivers them periodically as objects into
+// the target buckets.
+//
+// Log objects are written directly to the object layer, their writes
+// never produce records themselves. A bucket may thus be its own
+// target without logging recursively.
+type bucketAccessLogger struct {
+	mu      sync.RWMutex
+	configs map[string]LoggingEnabled
+	pending map[accessLogDestination]*bytes.Buffer
+	flushCh chan struct{}
+}
+
+func newBucketAccessLogger() *bucketAccessLogger {
+	return &bucketAccessLogger{
+		configs: make(map[string]LoggingEnabled),
+		pending: make(map[accessLogDestination]*bytes.Buffer),
+		flushCh: make(chan struct{}, 1),
+	}
+}
+
+// SetBucketLoggingConfig - sets the logging configuration of a bucket,
+// nil disables logging. Records already buffered are still delivered.
+func (l *bucketAccessLogger) SetBucketLoggingConfig(bucket string, config *LoggingEnabled) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if config == nil {
+		delete(l.configs, bucket)
+		return
+	}
+	l.configs[bucket] = *config
+}
+
+// IsEnabled - returns whether access logging is enabled for the bucket.
+func (l *bucketAccessLogger) IsEnabled(bucket string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.configs[bucket]
+	return ok
+}
+
+// Log - buffers a record if logging is enabled for its bucket.
+func (l *bucketAccessLogger) Log(rec accessLogRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	config, ok := l.configs[rec.Bucket]
+	if !ok {
+		return
+	}
+	dest := accessLogDestination{rec.Bucket, config.TargetBucket, config.TargetPrefix}
+	buffer, ok := l.pending[dest]
+	if !ok {
+		buffer = &bytes.Buffer{}
+		l.pending[dest] = buffer
+	}
+	buffer.WriteString(rec.String())
+	if buffer.Len() >= accessLogMaxBufferSize {
+		select {
+		case l.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush - delivers all buffered records, failed deliveries are logged
+// and their records are dropped.
+func (l *bucketAccessLogger) Flush(objAPI ObjectLayer) {
+	l.mu.Lock()
+	pending := l.pending
+	l.pending = make(map[accessLogDestination]*bytes.Buffer)
+	l.mu.Unlock()
+
+	for dest, buffer := range pending {
+		// Keys are the prefix followed by the time of delivery and
+		// a unique string, as in AWS.
+		object := dest.targetPrefix + UTCNow().Format("2006-01-02-15-04-05-") +
+			strings.ToUpper(strings.Replace(mustGetUUID(), "-", "", -1)[:16])
+		hashReader, err := hash.NewReader(buffer, int64(buffer.Len()), "", "")
+		if err != nil {
+			errorIf(err, "Unable to deliver access log to %s/%s", dest.targetBucket, object)
+			continue
+		}
+		_, err = objAPI.PutObject(dest.targetBucket, object, hashReader, map[string]string{
+			"content-type": "text/plain",
+		})
+		errorIf(err, "Unable to deliver access log of %s to %s/%s", dest.bucket, dest.targetBucket, object)
+	}
+}
+
+// deliver - flushes buffered records periodically, and when buffers are
+// full, until doneCh is closed.
+func (l *bucketAccessLogger) deliver(objAPI ObjectLayer, interval time.Duration, doneCh chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-l.flushCh:
+		case <-doneCh:
+			l.Flush(objAPI)
+			return
+		}
+		l.Flush(objAPI)
+	}
+}
+
+// Global access logger, nil for layers without access logging.
+var globalBucketAccessLogger *bucketAccessLogger
+
+// initBucketLogging - loads the logging configuration of all buckets
+// and starts the delivery of access logs.
+func initBucketLogging(objAPI ObjectLayer) error {
+	buckets, err := objAPI.ListBuckets()
+	if err != nil {
+		return err
+	}
+
+	logger := newBucketAccessLogger()
+	for _, bucket := range buckets {
+		config, err := readBucketLoggingConfig(bucket.Name, objAPI)
+		if err != nil {
+			return err
+		}
+		logger.SetBucketLoggingConfig(bucket.Name, config)
+	}
+
+	globalBucketAccessLogger = logger
+	go logger.deliver(objAPI, accessLogFlushInterval, globalServiceDoneCh)
+	return nil
+}
+
+// persistAndNotifyBucketLoggingChange - saves the logging configuration
+// of a bucket and notifies all nodes of the change.
+func persistAndNotifyBucketLoggingChange(bucket string, config *LoggingEnabled, objAPI ObjectLayer) error {
+	if err := writeBucketLoggingConfig(bucket, objAPI, config); err != nil {
+		return err
+	}
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketLogging(bucket)
+	return nil
+}
+
+// accessLogResponseWriter - records the status, size and error code of
+// responses.
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	bytesSent int64
+	// Beginning of error responses, holding their error code.
+	errorBody bytes.Buffer
+}
+
+func (w *accessLogResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.status >= http.StatusBadRequest && w.errorBody.Len() < 512 {
+		w.errorBody.Write(b)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytesSent += int64(n)
+	return n, err
+}
+
+func (w *accessLogResponseWriter) Flush() {
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *accessLogResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// errorCode - returns the code of an S3 error response.
+func (w *accessLogResponseWriter) errorCode() string {
+	body := w.errorBody.String()
+	start := strings.Index(body, "<Code>")
+	end := strings.Index(body, "</Code>")
+	if start < 0 || end < start {
+		return ""
+	}
+	return body[start+len("<Code>") : end]
+}
+
+// bucketAccessLogHandler - records requests to buckets with access
+// logging enabled.
+type bucketAccessLogHandler struct {
+	handler http.Handler
+}
+
+func setBucketAccessLogHandler(h http.Handler) http.Handler {
+	return bucketAccessLogHandler{handler: h}
+}
+
+func (h bucketAccessLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resource, err := getResource(r.URL.Path, r.Host, globalDomainName)
+	if err != nil {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	bucket, object := path2BucketAndObject(resource)
+	logger := globalBucketAccessLogger
+	if logger == nil || bucket == "" || !logger.IsEnabled(bucket) {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	start := UTCNow()
+	lw := &accessLogResponseWriter{ResponseWriter: w}
+	h.handler.ServeHTTP(lw, r)
+	if lw.status == 0 {
+		lw.status = http.StatusOK
+	}
+
+	logger.Log(accessLogRecord{
+		Bucket:     bucket,
+		Time:       start,
+		RemoteIP:   getSourceIPAddress(r),
+		Requester:  accessLogRequester(r),
+		RequestID:  w.Header().Get(responseRequestIDKey),
+		Operation:  accessLogOperation(r, object),
+		Key:        object,
+		RequestURI: r.Method + " " + r.URL.RequestURI() + " " + r.Proto,
+		Status:     lw.status,
+		ErrorCode:  lw.errorCode(),
+		BytesSent:  lw.bytesSent,
+		TotalTime:  UTCNow().Sub(start),
+		Referer:    r.Referer(),
+		UserAgent:  r.UserAgent(),
+	})
+}
@@ -0,0 +1,238 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	router "github.com/gorilla/mux"
+)
+
+func TestAccessLogRecordString(t *testing.T) {
+	rec := accessLogRecord{
+		Bucket:     "mybucket",
+		Time:       time.Date(2018, 4, 20, 10, 30, 0, 0, time.UTC),
+		RemoteIP:   "192.0.2.3",
+		Requester:  "minio",
+		RequestID:  "1527D9D8AA9BBB55",
+		Operation:  "REST.GET.OBJECT",
+		Key:        "photos/2018 april.jpg",
+		RequestURI: "GET /mybucket/photos/2018%20april.jpg HTTP/1.1",
+		Status:     http.StatusOK,
+		BytesSent:  2662992,
+		TotalTime:  70 * time.Millisecond,
+		UserAgent:  `curl/7.58 "test"`,
+	}
+	expected := globalMinioDefaultOwnerID + ` mybucket [20/Apr/2018:10:30:00 +0000] 192.0.2.3 minio 1527D9D8AA9BBB55 ` +
+		`REST.GET.OBJECT photos%2F2018+april.jpg "GET /mybucket/photos/2018%20april.jpg HTTP/1.1" 200 - 2662992 - 70 - ` +
+		`"-" "curl/7.58 \"test\"" -` + "\n"
+	if got := rec.String(); got != expected {
+		t.Errorf("Expected\n%s, got\n%s", expected, got)
+	}
+}
+
+func TestAccessLogOperation(t *testing.T) {
+	testCases := []struct {
+		method, url string
+		copySource  string
+		expected    string
+	}{
+		{http.MethodGet, "/bucket/object", "", "REST.GET.OBJECT"},
+		{http.MethodHead, "/bucket", "", "REST.HEAD.BUCKET"},
+		{http.MethodPut, "/bucket/object", "/bucket/source", "REST.COPY.OBJECT"},
+		{http.MethodPut, "/bucket/object?partNumber=1&uploadId=abc", "", "REST.PUT.PART"},
+		{http.MethodPost, "/bucket/object?uploads", "", "REST.POST.UPLOADS"},
+		{http.MethodDelete, "/bucket/object?uploadId=abc", "", "REST.DELETE.UPLOAD"},
+		{http.MethodGet, "/bucket?acl", "", "REST.GET.ACL"},
+		{http.MethodPut, "/bucket/object?acl", "", "REST.PUT.ACL_OBJECT"},
+		{http.MethodPut, "/bucket?logging", "", "REST.PUT.LOGGING_STATUS"},
+		{http.MethodPost, "/bucket?delete", "", "REST.POST.MULTI_OBJECT_DELETE"},
+	}
+	for i, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, testCase.url, nil)
+		if testCase.copySource != "" {
+			req.Header.Set("X-Amz-Copy-Source", testCase.copySource)
+		}
+		_, object := urlPath2BucketObjectName(req.URL)
+		if got := accessLogOperation(req, object); got != testCase.expected {
+			t.Errorf("Test %d: expected %s, got %s", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestBucketLogging(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fsDir)
+	globalObjLayerMutex.Lock()
+	globalObjectAPI = obj
+	globalObjLayerMutex.Unlock()
+	initGlobalS3Peers(mustGetNewEndpointList(fsDir))
+
+	mux := router.NewRouter().SkipClean(true)
+	registerAPIRouter(mux)
+	server := httptest.NewServer(setBucketAccessLogHandler(mux))
+	defer server.Close()
+
+	cred := globalServerConfig.GetCredential()
+	bucket, logBucket := getRandomBucketName(), getRandomBucketName()
+	for _, b := range []string{bucket, logBucket} {
+		if err = obj.MakeBucketWithLocation(b, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	do := func(method, urlStr string, body []byte) *http.Response {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = signRequestV4(req, cred.AccessKey, cred.SecretKey); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	putLogging := func(status BucketLoggingStatus) int {
+		body, err := xml.Marshal(status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp := do(http.MethodPut, getMakeBucketURL(server.URL, bucket)+"?logging", body)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// The target bucket must exist.
+	if status := putLogging(BucketLoggingStatus{LoggingEnabled: &LoggingEnabled{TargetBucket: "nonexistent"}}); status != http.StatusBadRequest {
+		t.Fatalf("Expected %d, got %d", http.StatusBadRequest, status)
+	}
+	config := &LoggingEnabled{TargetBucket: logBucket, TargetPrefix: "access/"}
+	if status := putLogging(BucketLoggingStatus{LoggingEnabled: config}); status != http.StatusOK {
+		t.Fatalf("Expected %d, got %d", http.StatusOK, status)
+	}
+
+	resp := do(http.MethodGet, getMakeBucketURL(server.URL, bucket)+"?logging", nil)
+	var status BucketLoggingStatus
+	err = xml.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.LoggingEnabled == nil || *status.LoggingEnabled != *config {
+		t.Fatalf("Expected %#v, got %#v", config, status.LoggingEnabled)
+	}
+
+	resp = do(http.MethodPut, getPutObjectURL(server.URL, bucket, "object"), []byte("hello"))
+	resp.Body.Close()
+	resp = do(http.MethodGet, getGetObjectURL(server.URL, bucket, "missing"), nil)
+	resp.Body.Close()
+
+	// Deliveries write directly to the target bucket and are not
+	// logged themselves.
+	globalBucketAccessLogger.Flush(obj)
+	globalBucketAccessLogger.Flush(obj)
+	result, err := obj.ListObjects(logBucket, "access/", "", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Objects) != 1 {
+		t.Fatalf("Expected one log object, got %d", len(result.Objects))
+	}
+
+	var buffer bytes.Buffer
+	if err = obj.GetObject(logBucket, result.Objects[0].Name, 0, -1, &buffer, ""); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 records, got %q", lines)
+	}
+	for i, expected := range []string{
+		"REST.GET.LOGGING_STATUS - \"GET /" + bucket + "/?logging= HTTP/1.1\" 200 - ",
+		"REST.PUT.OBJECT object \"PUT /" + bucket + "/object HTTP/1.1\" 200 - - - ",
+		"REST.GET.OBJECT missing \"GET /" + bucket + "/missing HTTP/1.1\" 404 NoSuchKey ",
+	} {
+		if !strings.Contains(lines[i], expected) || !strings.Contains(lines[i], " "+cred.AccessKey+" ") {
+			t.Errorf("Record %d: expected %q in %q", i+1, expected, lines[i])
+		}
+	}
+
+	// Disable logging.
+	if status := putLogging(BucketLoggingStatus{}); status != http.StatusOK {
+		t.Fatalf("Expected %d, got %d", http.StatusOK, status)
+	}
+	if globalBucketAccessLogger.IsEnabled(bucket) {
+		t.Fatal("Expected logging to be disabled")
+	}
+}
+
+// Tests that requests in virtual-host style are logged for the bucket
+// named by the host.
+func TestBucketAccessLogHandlerVirtualHost(t *testing.T) {
+	prevLogger, prevDomain := globalBucketAccessLogger, globalDomainName
+	defer func() {
+		globalBucketAccessLogger, globalDomainName = prevLogger, prevDomain
+	}()
+	logger := newBucketAccessLogger()
+	logger.SetBucketLoggingConfig("bucket", &LoggingEnabled{TargetBucket: "logs"})
+	globalBucketAccessLogger, globalDomainName = logger, "minio.test"
+
+	handler := setBucketAccessLogHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, host := range []string{"bucket.minio.test", "bucket.minio.test:9000", "other.minio.test"} {
+		req, err := http.NewRequest(http.MethodGet, "http://"+host+"/object", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	buffer := logger.pending[accessLogDestination{"bucket", "logs", ""}]
+	if buffer == nil {
+		t.Fatal("Expected records for the bucket")
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %q", lines)
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, "REST.GET.OBJECT object ") {
+			t.Errorf("Record %d: expected the object key in %q", i+1, line)
+		}
+	}
+	if len(logger.pending) != 1 {
+		t.Fatalf("Expected records of a single bucket, got %d", len(logger.pending))
+	}
+}
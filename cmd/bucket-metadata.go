@@ -28,6 +28,12 @@ type BucketMetaState interface {
 	// Updates bucket policy
 	UpdateBucketPolicy(args *SetBucketPolicyPeerArgs) error
 
+	// Updates bucket logging
+	UpdateBucketLogging(args *SetBucketLoggingPeerArgs) error
+
+	// Updates bucket ACL
+	UpdateBucketACL(args *SetBucketACLPeerArgs) error
+
 	// Sends event
 	SendEvent(args *EventArgs) error
 }
@@ -80,6 +86,36 @@ func (lc *localBucketMetaState) UpdateBucketPolicy(args *SetBucketPolicyPeerArgs
 	return objAPI.RefreshBucketPolicy(args.Bucket)
 }
 
+// localBucketMetaState.UpdateBucketLogging - reloads the in-memory
+// logging config of a bucket.
+func (lc *localBucketMetaState) UpdateBucketLogging(args *SetBucketLoggingPeerArgs) error {
+	// check if object layer is available.
+	objAPI := lc.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	if globalBucketAccessLogger == nil {
+		return nil
+	}
+	config, err := readBucketLoggingConfig(args.Bucket, objAPI)
+	if err != nil {
+		return err
+	}
+	globalBucketAccessLogger.SetBucketLoggingConfig(args.Bucket, config)
+	return nil
+}
+
+// localBucketMetaState.UpdateBucketACL - reloads the in-memory canned
+// ACL of a bucket.
+func (lc *localBucketMetaState) UpdateBucketACL(args *SetBucketACLPeerArgs) error {
+	// check if object layer is available.
+	objAPI := lc.ObjectAPI()
+	if objAPI == nil {
+		return errServerNotInitialized
+	}
+	return refreshBucketACL(args.Bucket, objAPI)
+}
+
 // localBucketMetaState.SendEvent - sends event to local event notifier via
 // `globalEventNotifier`
 func (lc *localBucketMetaState) SendEvent(args *EventArgs) error {
@@ -118,6 +154,20 @@ func (rc *remoteBucketMetaState) UpdateBucketPolicy(args *SetBucketPolicyPeerArg
 	return rc.Call("S3.SetBucketPolicyPeer", args, &reply)
 }
 
+// remoteBucketMetaState.UpdateBucketLogging - sends bucket logging change
+// to remote peer via RPC call.
+func (rc *remoteBucketMetaState) UpdateBucketLogging(args *SetBucketLoggingPeerArgs) error {
+	reply := AuthRPCReply{}
+	return rc.Call("S3.SetBucketLoggingPeer", args, &reply)
+}
+
+// remoteBucketMetaState.UpdateBucketACL - sends bucket ACL change to
+// remote peer via RPC call.
+func (rc *remoteBucketMetaState) UpdateBucketACL(args *SetBucketACLPeerArgs) error {
+	reply := AuthRPCReply{}
+	return rc.Call("S3.SetBucketACLPeer", args, &reply)
+}
+
 // remoteBucketMetaState.SendEvent - sends event for bucket listener to remote
 // peer via RPC call.
 func (rc *remoteBucketMetaState) SendEvent(args *EventArgs) error {
@@ -39,18 +39,35 @@ const maxAccessPolicySize = 20 * humanize.KiByte
 // existing bucket access policy.
 func bucketPolicyEvalStatements(action string, resource string, conditions policy.ConditionKeyMap,
 	statements []policy.Statement) bool {
+	return bucketPolicyEvalEffect(action, resource, conditions, statements) == policyEffectAllow
+}
+
+// Outcomes of evaluating bucket policy statements.
+type policyEffect int
+
+const (
+	// No statement matches the request.
+	policyEffectNone policyEffect = iota
+	// The first matching statement allows the request.
+	policyEffectAllow
+	// The first matching statement denies the request.
+	policyEffectDeny
+)
+
+// bucketPolicyEvalEffect - returns the effect of the first statement
+// matching action, resource and conditions, an explicit deny is told
+// apart from no match so that it is not overridden by canned ACLs.
+func bucketPolicyEvalEffect(action string, resource string, conditions policy.ConditionKeyMap,
+	statements []policy.Statement) policyEffect {
 	for _, statement := range statements {
 		if bucketPolicyMatchStatement(action, resource, conditions, statement) {
 			if statement.Effect == "Allow" {
-				return true
+				return policyEffectAllow
 			}
-			// Do not uncomment kept here for readability.
-			// else statement.Effect == "Deny"
-			return false
+			return policyEffectDeny
 		}
 	}
-	// None match so deny.
-	return false
+	return policyEffectNone
 }
 
 // Verify if action, resource and conditions match input policy statement.
@@ -24,6 +24,7 @@ import (
 	"sync"
 
 	"github.com/minio/minio-go/pkg/policy"
+	"github.com/minio/minio-go/pkg/set"
 	"github.com/minio/minio/pkg/errors"
 	"github.com/minio/minio/pkg/hash"
 )
@@ -213,3 +214,48 @@ func persistAndNotifyBucketPolicyChange(bucket string, isRemove bool, bktPolicy
 	S3PeersUpdateBucketPolicy(bucket)
 	return nil
 }
+
+// Actions granted to anonymous requests by the canned ACL of a bucket.
+var (
+	bucketACLReadActions  = set.CreateStringSet("s3:ListBucket", "s3:ListBucketMultipartUploads")
+	bucketACLWriteActions = set.CreateStringSet("s3:PutObject", "s3:DeleteObject",
+		"s3:AbortMultipartUpload", "s3:ListMultipartUploadParts")
+)
+
+// enforceCannedACL - verifies an anonymous request against the canned
+// ACLs of the bucket and object, consulted when the bucket policy does
+// not allow the request. Only public ACLs grant anonymous access.
+func enforceCannedACL(objAPI ObjectLayer, action, resource string) APIErrorCode {
+	if !objAPI.IsACLSupported() {
+		return ErrAccessDenied
+	}
+	bucket, object := path2BucketAndObject(resource)
+
+	var acl string
+	switch {
+	case action == "s3:GetObject" && object != "":
+		objInfo, err := objAPI.GetObjectInfo(bucket, object)
+		if err != nil {
+			return ErrAccessDenied
+		}
+		acl = getObjectACL(objInfo)
+	case bucketACLReadActions.Contains(action), bucketACLWriteActions.Contains(action):
+		acls := getBucketACLs(objAPI)
+		if acls == nil {
+			return ErrAccessDenied
+		}
+		acl = acls.GetBucketACL(bucket)
+	default:
+		return ErrAccessDenied
+	}
+
+	switch acl {
+	case cannedACLPublicReadWrite:
+		return ErrNone
+	case cannedACLPublicRead:
+		if !bucketACLWriteActions.Contains(action) {
+			return ErrNone
+		}
+	}
+	return ErrAccessDenied
+}
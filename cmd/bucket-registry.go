@@ -0,0 +1,449 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// errBucketNotRegistered - bucket is not in the registry.
+	errBucketNotRegistered = errors.New("bucket not registered")
+
+	// errBucketRegistered - bucket is registered already.
+	errBucketRegistered = errors.New("bucket already registered")
+)
+
+// bucketRegistryEntry - cluster owning a bucket.
+type bucketRegistryEntry struct {
+	// Endpoint of the cluster, e.g. https://minio1.example.com:9000.
+	Endpoint string    `json:"endpoint"`
+	Created  time.Time `json:"created"`
+}
+
+// bucketRegistry - registry of the buckets of federated clusters,
+// shared by all clusters.
+type bucketRegistry interface {
+	// Get - returns the entry of bucket, errBucketNotRegistered if
+	// it is not registered.
+	Get(bucket string) (bucketRegistryEntry, error)
+
+	// Register - registers bucket unless it is registered already,
+	// the existing entry is returned with errBucketRegistered then.
+	Register(bucket string, entry bucketRegistryEntry) (bucketRegistryEntry, error)
+
+	// Unregister - removes bucket from the registry.
+	Unregister(bucket string) error
+
+	// List - returns the entries of all buckets.
+	List() (map[string]bucketRegistryEntry, error)
+}
+
+// fileBucketRegistry - registry kept in memory and saved to a file,
+// if any. It is shared by the clusters of a single host only, e.g.
+// in tests.
+type fileBucketRegistry struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]bucketRegistryEntry
+}
+
+// newFileBucketRegistry - returns a registry saved to path, an
+// in-memory registry if path is empty.
+func newFileBucketRegistry(path string) (*fileBucketRegistry, error) {
+	r := &fileBucketRegistry{
+		path:    path,
+		entries: make(map[string]bucketRegistryEntry),
+	}
+	if path == "" {
+		return r, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load - reads the entries saved by all clusters.
+func (r *fileBucketRegistry) load() error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		r.entries = make(map[string]bucketRegistryEntry)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	entries := make(map[string]bucketRegistryEntry)
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	r.entries = entries
+	return nil
+}
+
+// save - replaces the registry file atomically.
+func (r *fileBucketRegistry) save() error {
+	if r.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(r.entries)
+	if err != nil {
+		return err
+	}
+	tmpPath := r.path + "." + mustGetUUID()
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, r.path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// Get - returns the entry of bucket.
+func (r *fileBucketRegistry) Get(bucket string) (bucketRegistryEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return bucketRegistryEntry{}, err
+	}
+	entry, ok := r.entries[bucket]
+	if !ok {
+		return entry, errBucketNotRegistered
+	}
+	return entry, nil
+}
+
+// Register - registers bucket unless it is registered already.
+func (r *fileBucketRegistry) Register(bucket string, entry bucketRegistryEntry) (bucketRegistryEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return bucketRegistryEntry{}, err
+	}
+	if existing, ok := r.entries[bucket]; ok {
+		return existing, errBucketRegistered
+	}
+	r.entries[bucket] = entry
+	return entry, r.save()
+}
+
+// Unregister - removes bucket from the registry.
+func (r *fileBucketRegistry) Unregister(bucket string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return err
+	}
+	if _, ok := r.entries[bucket]; !ok {
+		return nil
+	}
+	delete(r.entries, bucket)
+	return r.save()
+}
+
+// List - returns the entries of all buckets.
+func (r *fileBucketRegistry) List() (map[string]bucketRegistryEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	entries := make(map[string]bucketRegistryEntry, len(r.entries))
+	for bucket, entry := range r.entries {
+		entries[bucket] = entry
+	}
+	return entries, nil
+}
+
+// Key prefix of the buckets in etcd.
+const etcdBucketPrefix = "/minio/buckets/"
+
+// etcdBucketRegistry - registry kept in etcd, accessed through the
+// JSON gateway of the etcd v3 API.
+type etcdBucketRegistry struct {
+	endpoints []string
+	client    *http.Client
+}
+
+// newEtcdBucketRegistry - returns a registry kept in the etcd cluster
+// of endpoints, e.g. http://etcd1:2379.
+func newEtcdBucketRegistry(endpoints []string) (*etcdBucketRegistry, error) {
+	for i, endpoint := range endpoints {
+		endpoint = strings.TrimSuffix(strings.TrimSpace(endpoint), "/")
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			return nil, fmt.Errorf("invalid etcd endpoint ‘%s’", endpoint)
+		}
+		endpoints[i] = endpoint
+	}
+	return &etcdBucketRegistry{
+		endpoints: endpoints,
+		client: &http.Client{
+			Transport: NewCustomHTTPTransport(),
+			Timeout:   10 * time.Second,
+		},
+	}, nil
+}
+
+// etcdKeyValue - key value pair of the etcd v3 API, keys and values
+// are base64 encoded.
+type etcdKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// etcdRangeResponse - response of a range request.
+type etcdRangeResponse struct {
+	Kvs []etcdKeyValue `json:"kvs"`
+}
+
+// call - sends a request of the etcd v3 API to the first endpoint
+// responding successfully, the error of the last endpoint is returned
+// if none does.
+func (r *etcdBucketRegistry) call(method string, req, resp interface{}) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	for _, endpoint := range r.endpoints {
+		if err = r.callEndpoint(endpoint, method, data, resp); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// callEndpoint - sends a request of the etcd v3 API to endpoint.
+func (r *etcdBucketRegistry) callEndpoint(endpoint, method string, data []byte, resp interface{}) error {
+	httpResp, err := r.client.Post(endpoint+"/v3/"+method, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(httpResp.Body)
+		return fmt.Errorf("etcd %s failed on %s: %s %s", method, endpoint, httpResp.Status, bytes.TrimSpace(body))
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+// etcdEncode - returns the base64 encoding of s.
+func etcdEncode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// etcdDecodeEntry - returns the entry of a key value pair.
+func etcdDecodeEntry(kv etcdKeyValue) (bucket string, entry bucketRegistryEntry, err error) {
+	key, err := base64.StdEncoding.DecodeString(kv.Key)
+	if err != nil {
+		return "", entry, err
+	}
+	value, err := base64.StdEncoding.DecodeString(kv.Value)
+	if err != nil {
+		return "", entry, err
+	}
+	err = json.Unmarshal(value, &entry)
+	return strings.TrimPrefix(string(key), etcdBucketPrefix), entry, err
+}
+
+// Get - returns the entry of bucket.
+func (r *etcdBucketRegistry) Get(bucket string) (bucketRegistryEntry, error) {
+	var resp etcdRangeResponse
+	if err := r.call("kv/range", map[string]string{"key": etcdEncode(etcdBucketPrefix + bucket)}, &resp); err != nil {
+		return bucketRegistryEntry{}, err
+	}
+	if len(resp.Kvs) == 0 {
+		return bucketRegistryEntry{}, errBucketNotRegistered
+	}
+	_, entry, err := etcdDecodeEntry(resp.Kvs[0])
+	return entry, err
+}
+
+// Register - registers bucket unless it is registered already, in a
+// transaction putting the key only if it was never created.
+func (r *etcdBucketRegistry) Register(bucket string, entry bucketRegistryEntry) (bucketRegistryEntry, error) {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return bucketRegistryEntry{}, err
+	}
+	key := etcdEncode(etcdBucketPrefix + bucket)
+	req := map[string]interface{}{
+		"compare": []map[string]string{
+			{"key": key, "result": "EQUAL", "target": "CREATE", "create_revision": "0"},
+		},
+		"success": []map[string]interface{}{
+			{"request_put": map[string]string{"key": key, "value": base64.StdEncoding.EncodeToString(value)}},
+		},
+		"failure": []map[string]interface{}{
+			{"request_range": map[string]string{"key": key}},
+		},
+	}
+	var resp struct {
+		Succeeded bool `json:"succeeded"`
+		Responses []struct {
+			ResponseRange etcdRangeResponse `json:"response_range"`
+		} `json:"responses"`
+	}
+	if err = r.call("kv/txn", req, &resp); err != nil {
+		return bucketRegistryEntry{}, err
+	}
+	if resp.Succeeded {
+		return entry, nil
+	}
+	if len(resp.Responses) == 0 || len(resp.Responses[0].ResponseRange.Kvs) == 0 {
+		// Unregistered meanwhile.
+		return r.Register(bucket, entry)
+	}
+	_, existing, err := etcdDecodeEntry(resp.Responses[0].ResponseRange.Kvs[0])
+	if err != nil {
+		return bucketRegistryEntry{}, err
+	}
+	return existing, errBucketRegistered
+}
+
+// Unregister - removes bucket from the registry.
+func (r *etcdBucketRegistry) Unregister(bucket string) error {
+	var resp struct{}
+	return r.call("kv/deleterange", map[string]string{"key": etcdEncode(etcdBucketPrefix + bucket)}, &resp)
+}
+
+// List - returns the entries of all buckets, the keys below the
+// bucket prefix.
+func (r *etcdBucketRegistry) List() (map[string]bucketRegistryEntry, error) {
+	// The range end of a prefix is the prefix with its last byte
+	// incremented.
+	rangeEnd := []byte(etcdBucketPrefix)
+	rangeEnd[len(rangeEnd)-1]++
+	var resp etcdRangeResponse
+	req := map[string]string{
+		"key":       etcdEncode(etcdBucketPrefix),
+		"range_end": etcdEncode(string(rangeEnd)),
+	}
+	if err := r.call("kv/range", req, &resp); err != nil {
+		return nil, err
+	}
+	entries := make(map[string]bucketRegistryEntry, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		bucket, entry, err := etcdDecodeEntry(kv)
+		if err != nil {
+			return nil, err
+		}
+		entries[bucket] = entry
+	}
+	return entries, nil
+}
+
+// cachedBucketRegistry - registry caching the entries looked up with
+// Get for ttl, changes made through the cache are visible right away,
+// changes of other clusters once the cached entries expired.
+type cachedBucketRegistry struct {
+	bucketRegistry
+
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cachedBucketRegistryEntry
+}
+
+// cachedBucketRegistryEntry - result of a lookup of a bucket.
+type cachedBucketRegistryEntry struct {
+	entry   bucketRegistryEntry
+	err     error
+	expires time.Time
+}
+
+// Maximum number of cached entries, expired entries are dropped when
+// it is reached.
+const maxCachedBucketRegistryEntries = 10000
+
+// newCachedBucketRegistry - returns a registry caching the entries of
+// registry for ttl.
+func newCachedBucketRegistry(registry bucketRegistry, ttl time.Duration) *cachedBucketRegistry {
+	return &cachedBucketRegistry{
+		bucketRegistry: registry,
+		ttl:            ttl,
+		entries:        make(map[string]cachedBucketRegistryEntry),
+	}
+}
+
+// Get - returns the cached entry of bucket, looks it up if it is not
+// cached or expired. Unregistered buckets are cached as well.
+func (r *cachedBucketRegistry) Get(bucket string) (bucketRegistryEntry, error) {
+	now := UTCNow()
+	r.mu.Lock()
+	cached, ok := r.entries[bucket]
+	r.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.entry, cached.err
+	}
+
+	entry, err := r.bucketRegistry.Get(bucket)
+	if err != nil && err != errBucketNotRegistered {
+		return entry, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) >= maxCachedBucketRegistryEntries {
+		for b, cached := range r.entries {
+			if !now.Before(cached.expires) {
+				delete(r.entries, b)
+			}
+		}
+		if len(r.entries) >= maxCachedBucketRegistryEntries {
+			r.entries = make(map[string]cachedBucketRegistryEntry)
+		}
+	}
+	r.entries[bucket] = cachedBucketRegistryEntry{entry, err, now.Add(r.ttl)}
+	return entry, err
+}
+
+// invalidate - removes the cached entry of bucket.
+func (r *cachedBucketRegistry) invalidate(bucket string) {
+	r.mu.Lock()
+	delete(r.entries, bucket)
+	r.mu.Unlock()
+}
+
+// Register - registers bucket unless it is registered already.
+func (r *cachedBucketRegistry) Register(bucket string, entry bucketRegistryEntry) (bucketRegistryEntry, error) {
+	defer r.invalidate(bucket)
+	return r.bucketRegistry.Register(bucket, entry)
+}
+
+// Unregister - removes bucket from the registry.
+func (r *cachedBucketRegistry) Unregister(bucket string) error {
+	defer r.invalidate(bucket)
+	return r.bucketRegistry.Unregister(bucket)
+}
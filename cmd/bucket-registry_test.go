@@ -0,0 +1,229 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testBucketRegistry - tests the registry r, which must be empty.
+func testBucketRegistry(t *testing.T, r bucketRegistry) {
+	created := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := bucketRegistryEntry{Endpoint: "http://minio1:9000", Created: created}
+
+	if _, err := r.Get("bucket"); err != errBucketNotRegistered {
+		t.Fatalf("Expected unregistered bucket, got %v", err)
+	}
+	if _, err := r.Register("bucket", entry); err != nil {
+		t.Fatal(err)
+	}
+	owner, err := r.Register("bucket", bucketRegistryEntry{Endpoint: "http://minio2:9000"})
+	if err != errBucketRegistered || owner.Endpoint != entry.Endpoint {
+		t.Fatalf("Expected bucket to be registered by %s, got %v, %v", entry.Endpoint, owner, err)
+	}
+	if owner, err = r.Get("bucket"); err != nil || owner.Endpoint != entry.Endpoint || !owner.Created.Equal(created) {
+		t.Fatalf("Unexpected entry %v, %v", owner, err)
+	}
+	if _, err = r.Register("other", bucketRegistryEntry{Endpoint: "http://minio2:9000"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries["bucket"].Endpoint != "http://minio1:9000" || entries["other"].Endpoint != "http://minio2:9000" {
+		t.Fatalf("Unexpected entries %v", entries)
+	}
+
+	if err = r.Unregister("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Get("bucket"); err != errBucketNotRegistered {
+		t.Fatalf("Expected unregistered bucket, got %v", err)
+	}
+	if _, err = r.Register("bucket", bucketRegistryEntry{Endpoint: "http://minio2:9000"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileBucketRegistry(t *testing.T) {
+	r, err := newFileBucketRegistry("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBucketRegistry(t, r)
+
+	dir, err := ioutil.TempDir(globalTestTmpDir, "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "registry", "buckets.json")
+	if r, err = newFileBucketRegistry(path); err != nil {
+		t.Fatal(err)
+	}
+	testBucketRegistry(t, r)
+
+	// Entries are shared by the registries of all clusters.
+	other, err := newFileBucketRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = other.Register("new", bucketRegistryEntry{Endpoint: "http://minio3:9000"}); err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := r.Get("new"); err != nil || owner.Endpoint != "http://minio3:9000" {
+		t.Errorf("Unexpected entry %v, %v", owner, err)
+	}
+}
+
+func TestCachedBucketRegistry(t *testing.T) {
+	r, err := newFileBucketRegistry("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBucketRegistry(t, newCachedBucketRegistry(r, time.Hour))
+
+	// Changes of other clusters are seen once the entries expired.
+	cached := newCachedBucketRegistry(r, time.Hour)
+	if _, err = cached.Get("new"); err != errBucketNotRegistered {
+		t.Fatalf("Expected unregistered bucket, got %v", err)
+	}
+	if _, err = r.Register("new", bucketRegistryEntry{Endpoint: "http://minio3:9000"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cached.Get("new"); err != errBucketNotRegistered {
+		t.Fatalf("Expected cached unregistered bucket, got %v", err)
+	}
+	cached.mu.Lock()
+	entry := cached.entries["new"]
+	entry.expires = UTCNow()
+	cached.entries["new"] = entry
+	cached.mu.Unlock()
+	if owner, err := cached.Get("new"); err != nil || owner.Endpoint != "http://minio3:9000" {
+		t.Errorf("Unexpected entry %v, %v", owner, err)
+	}
+}
+
+// etcdTestServer - in-memory server of the etcd v3 JSON API subset
+// used by the bucket registry.
+type etcdTestServer struct {
+	mu  sync.Mutex
+	kvs map[string]string
+}
+
+func (s *etcdTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var req map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	decode := func(field string) string {
+		var s string
+		json.Unmarshal(req[field], &s)
+		b, _ := base64.StdEncoding.DecodeString(s)
+		return string(b)
+	}
+	rangeResponse := func(key, rangeEnd string) etcdRangeResponse {
+		var resp etcdRangeResponse
+		for k, v := range s.kvs {
+			if k == key || (rangeEnd != "" && k >= key && k < rangeEnd) {
+				resp.Kvs = append(resp.Kvs, etcdKeyValue{Key: etcdEncode(k), Value: etcdEncode(v)})
+			}
+		}
+		return resp
+	}
+
+	var resp interface{}
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		resp = rangeResponse(decode("key"), decode("range_end"))
+	case "/v3/kv/deleterange":
+		delete(s.kvs, decode("key"))
+		resp = struct{}{}
+	case "/v3/kv/txn":
+		// Only the transaction of Register is supported.
+		var txn struct {
+			Compare []struct {
+				Key string `json:"key"`
+			} `json:"compare"`
+			Success []struct {
+				RequestPut etcdKeyValue `json:"request_put"`
+			} `json:"success"`
+		}
+		b, _ := json.Marshal(req)
+		json.Unmarshal(b, &txn)
+		key, _ := base64.StdEncoding.DecodeString(txn.Compare[0].Key)
+		if _, ok := s.kvs[string(key)]; ok {
+			resp = map[string]interface{}{
+				"responses": []interface{}{
+					map[string]interface{}{"response_range": rangeResponse(string(key), "")},
+				},
+			}
+			break
+		}
+		value, _ := base64.StdEncoding.DecodeString(txn.Success[0].RequestPut.Value)
+		s.kvs[string(key)] = string(value)
+		resp = map[string]interface{}{"succeeded": true}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestEtcdBucketRegistry(t *testing.T) {
+	server := httptest.NewServer(&etcdTestServer{kvs: make(map[string]string)})
+	defer server.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "etcdserver: not capable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	// Unreachable and failing endpoints are skipped.
+	r, err := newEtcdBucketRegistry([]string{"http://127.0.0.1:1", failing.URL, server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBucketRegistry(t, r)
+
+	// The error of the last endpoint is returned if all fail.
+	if r, err = newEtcdBucketRegistry([]string{failing.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Get("bucket"); err == nil || !strings.Contains(err.Error(), "not capable") {
+		t.Errorf("Expected the error of the failing endpoint, got %v", err)
+	}
+
+	if _, err = newEtcdBucketRegistry([]string{"etcd:2379"}); err == nil {
+		t.Error("Expected endpoint without scheme to fail")
+	}
+}
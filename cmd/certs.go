@@ -23,7 +23,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio/pkg/certs"
 )
 
 // TLSPrivateKeyPassword is the environment variable which contains the password used
@@ -126,7 +131,7 @@ func loadX509KeyPair(certFile, keyFile string) (tls.Certificate, error) {
 	return tls.X509KeyPair(certPEMBlock, keyPEMBlock)
 }
 
-func getSSLConfig() (x509Certs []*x509.Certificate, rootCAs *x509.CertPool, tlsCert *tls.Certificate, secureConn bool, err error) {
+func getSSLConfig() (x509Certs []*x509.Certificate, rootCAs *x509.CertPool, tlsCerts *certs.Manager, secureConn bool, err error) {
 	if !(isFile(getPublicCertFile()) && isFile(getPrivateKeyFile())) {
 		return nil, nil, nil, false, nil
 	}
@@ -135,17 +140,157 @@ func getSSLConfig() (x509Certs []*x509.Certificate, rootCAs *x509.CertPool, tlsC
 		return nil, nil, nil, false, err
 	}
 
-	var cert tls.Certificate
-	if cert, err = loadX509KeyPair(getPublicCertFile(), getPrivateKeyFile()); err != nil {
+	if rootCAs, err = getRootCAs(getCADir()); err != nil {
 		return nil, nil, nil, false, err
 	}
 
-	tlsCert = &cert
-
-	if rootCAs, err = getRootCAs(getCADir()); err != nil {
+	// The default certificate has to be added first.
+	tlsCerts = certs.NewManager(loadX509KeyPair)
+	if err = tlsCerts.AddCertificate(getPublicCertFile(), getPrivateKeyFile()); err != nil {
+		return nil, nil, nil, false, err
+	}
+	if err = addDomainCertificates(tlsCerts); err != nil {
 		return nil, nil, nil, false, err
 	}
 
 	secureConn = true
-	return x509Certs, rootCAs, tlsCert, secureConn, nil
+	return x509Certs, rootCAs, tlsCerts, secureConn, nil
+}
+
+// addDomainCertificates - adds the certificates of every sub directory
+// of the certs directory, other than the CAs and node directories, to
+// tlsCerts. They are presented to clients requesting one of the names
+// they are valid for.
+func addDomainCertificates(tlsCerts *certs.Manager) error {
+	fis, err := ioutil.ReadDir(getCertsDir())
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		if !fi.IsDir() || fi.Name() == certsCADir || fi.Name() == certsNodeDir {
+			continue
+		}
+		certFile := filepath.Join(getCertsDir(), fi.Name(), publicCertFile)
+		keyFile := filepath.Join(getCertsDir(), fi.Name(), privateKeyFile)
+		if !(isFile(certFile) && isFile(keyFile)) {
+			continue
+		}
+		if err = tlsCerts.AddCertificate(certFile, keyFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reloadCertificates - picks up new domain certificates and reloads
+// changed ones, all of them if force is set, along with the node
+// certificate and the CAs. Invalid certificates are logged and the
+// ones in use are kept.
+func reloadCertificates(tlsCerts *certs.Manager, force bool) {
+	errorIf(addDomainCertificates(tlsCerts), "Unable to add TLS certificate")
+	for _, err := range tlsCerts.Reload(force) {
+		errorIf(err, "Unable to reload TLS certificate")
+	}
+	if globalNodeCerts != nil {
+		for _, err := range globalNodeCerts.Reload(force) {
+			errorIf(err, "Unable to reload node certificate")
+		}
+	}
+	reloadCAs()
+}
+
+// reloadCAs - rebuilds the pools of root CAs and node CAs from the CAs
+// directories, the pools in use are kept if this fails.
+func reloadCAs() {
+	rootCAs, err := getRootCAs(getCADir())
+	if err != nil {
+		errorIf(err, "Unable to reload CA certificates")
+		return
+	}
+	var nodeCAs *x509.CertPool
+	if globalNodeCerts != nil {
+		if nodeCAs, err = getNodeCAs(getNodeCADir()); err != nil {
+			errorIf(err, "Unable to reload node CA certificates")
+			return
+		}
+	}
+
+	globalCAsMu.Lock()
+	globalRootCAs = rootCAs
+	if nodeCAs != nil {
+		globalNodeCAs = nodeCAs
+	}
+	globalCAsMu.Unlock()
+}
+
+// startCertificatesWatch - starts reloading the server certificates in
+// the background, returns the certificate lookup for TLS handshakes or
+// nil if TLS is not configured.
+func startCertificatesWatch() certs.GetCertificateFunc {
+	if globalTLSCerts == nil {
+		return nil
+	}
+	go watchCertificates(globalTLSCerts, globalCertsReloadInterval, nil)
+	return globalTLSCerts.GetCertificate
+}
+
+// watchCertificates - reloads changed certificates every interval and
+// all certificates on SIGHUP, returns when doneCh is closed.
+func watchCertificates(tlsCerts *certs.Manager, interval time.Duration, doneCh <-chan struct{}) {
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-doneCh:
+			return
+		case <-ticker.C:
+			reloadCertificates(tlsCerts, false)
+		case <-hupCh:
+			log.Println("Reloading TLS certificates on SIGHUP")
+			reloadCertificates(tlsCerts, true)
+		}
+	}
+}
+
+// getNodeCertificate - returns the manager of the certificate presented
+// to other nodes for mutual TLS, nil if no node certificate is
+// configured.
+func getNodeCertificate() (*certs.Manager, error) {
+	if !(isFile(getNodeCertFile()) && isFile(getNodeKeyFile())) {
+		return nil, nil
+	}
+	nodeCerts := certs.NewManager(loadX509KeyPair)
+	if err := nodeCerts.AddCertificate(getNodeCertFile(), getNodeKeyFile()); err != nil {
+		return nil, err
+	}
+	return nodeCerts, nil
+}
+
+// getNodeCAs - returns the pool of the CAs in certsNodeCAsDir, node
+// certificates are only verified against these CAs.
+func getNodeCAs(certsNodeCAsDir string) (*x509.CertPool, error) {
+	fis, err := ioutil.ReadDir(certsNodeCAsDir)
+	if err != nil {
+		return nil, err
+	}
+	nodeCAs := x509.NewCertPool()
+	found := false
+	for _, fi := range fis {
+		caCert, err := ioutil.ReadFile(filepath.Join(certsNodeCAsDir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if !nodeCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("No certificate found in %s", filepath.Join(certsNodeCAsDir, fi.Name()))
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("No node CA certificate found in %s", certsNodeCAsDir)
+	}
+	return nodeCAs, nil
 }
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -258,6 +259,42 @@ func TestGetRootCAs(t *testing.T) {
 	}
 }
 
+func TestGetNodeCAs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-get-node-cas")
+	if err != nil {
+		t.Fatalf("Unable create temp directory. %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err = getNodeCAs(filepath.Join(dir, "nonexistent-dir")); err == nil {
+		t.Fatal("Expected error for a missing node CAs directory")
+	}
+	if _, err = getNodeCAs(dir); err == nil {
+		t.Fatal("Expected error for an empty node CAs directory")
+	}
+
+	ca := newTestNodeCertificate(t, "", nil)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate[0]})
+	if err = ioutil.WriteFile(filepath.Join(dir, "ca.crt"), caPEM, 0644); err != nil {
+		t.Fatalf("Unable create test file. %v", err)
+	}
+	nodeCAs, err := getNodeCAs(dir)
+	if err != nil {
+		t.Fatalf("error: expected = <nil>, got = %v", err)
+	}
+	// Only the node CA, no system CAs.
+	if n := len(nodeCAs.Subjects()); n != 1 {
+		t.Fatalf("Expected 1 CA, got %d", n)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "invalid.crt"), []byte("invalid"), 0644); err != nil {
+		t.Fatalf("Unable create test file. %v", err)
+	}
+	if _, err = getNodeCAs(dir); err == nil {
+		t.Fatal("Expected error for an invalid CA certificate")
+	}
+}
+
 func TestLoadX509KeyPair(t *testing.T) {
 	for i, testCase := range loadX509KeyPairTests {
 		privateKey, err := createTempFile("private.key", testCase.privateKey)
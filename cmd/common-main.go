@@ -20,6 +20,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -111,6 +112,37 @@ func handleCommonEnvVars() {
 		globalIsEnvDomainName = true
 	}
 
+	if maxRequests := os.Getenv(apiRequestsMaxEnv); maxRequests != "" {
+		var err error
+		globalAPIRequestsMax, err = strconv.Atoi(maxRequests)
+		if err == nil && globalAPIRequestsMax <= 0 {
+			err = errors.New("must be positive")
+		}
+		fatalIf(err, "Invalid value set in environment variable %s.", apiRequestsMaxEnv)
+	}
+
+	if deadline := os.Getenv(apiRequestsDeadlineEnv); deadline != "" {
+		var err error
+		globalAPIRequestsDeadline, err = time.ParseDuration(deadline)
+		if err == nil && globalAPIRequestsDeadline <= 0 {
+			err = errors.New("must be positive")
+		}
+		fatalIf(err, "Invalid value set in environment variable %s.", apiRequestsDeadlineEnv)
+	}
+
+	if fairness := os.Getenv(apiRequestsFairnessEnv); fairness != "" {
+		if fairness != fairnessAccessKey && fairness != fairnessSourceIP {
+			fatalIf(errors.New("invalid value"), "Unknown value ‘%s’ in %s environment variable.", fairness, apiRequestsFairnessEnv)
+		}
+		globalAPIRequestsFairness = fairness
+	}
+
+	if weights := os.Getenv(apiRequestsWeightsEnv); weights != "" {
+		var err error
+		globalAPIRequestsWeights, err = parseAdmissionWeights(weights)
+		fatalIf(err, "Invalid value set in environment variable %s.", apiRequestsWeightsEnv)
+	}
+
 	// In place update is true by default if the MINIO_UPDATE is not set
 	// or is not set to 'off', if MINIO_UPDATE is set to 'off' then
 	// in-place update is off.
@@ -23,6 +23,8 @@ import (
 	"reflect"
 	"sync"
 
+	"github.com/minio/minio-go/pkg/policy"
+	"github.com/minio/minio-go/pkg/set"
 	"github.com/minio/minio/pkg/auth"
 	"github.com/minio/minio/pkg/quick"
 	"github.com/tidwall/gjson"
@@ -37,9 +39,9 @@ import (
 // 6. Make changes in config-current_test.go for any test change
 
 // Config version
-const serverConfigVersion = "22"
+const serverConfigVersion = "28"
 
-type serverConfig = serverConfigV22
+type serverConfig = serverConfigV28
 
 var (
 	// globalServerConfig server config.
@@ -125,24 +127,36 @@ func (s *serverConfig) ConfigDiff(t *serverConfig) string {
 		return "Domain configuration differs"
 	case s.StorageClass != t.StorageClass:
 		return "StorageClass configuration differs"
-	case !reflect.DeepEqual(s.Notify.AMQP, t.Notify.AMQP):
-		return "AMQP Notification configuration differs"
-	case !reflect.DeepEqual(s.Notify.NATS, t.Notify.NATS):
-		return "NATS Notification configuration differs"
-	case !reflect.DeepEqual(s.Notify.ElasticSearch, t.Notify.ElasticSearch):
-		return "ElasticSearch Notification configuration differs"
-	case !reflect.DeepEqual(s.Notify.Redis, t.Notify.Redis):
-		return "Redis Notification configuration differs"
-	case !reflect.DeepEqual(s.Notify.PostgreSQL, t.Notify.PostgreSQL):
-		return "PostgreSQL Notification configuration differs"
-	case !reflect.DeepEqual(s.Notify.Kafka, t.Notify.Kafka):
-		return "Kafka Notification configuration differs"
-	case !reflect.DeepEqual(s.Notify.Webhook, t.Notify.Webhook):
-		return "Webhook Notification configuration differs"
-	case !reflect.DeepEqual(s.Notify.MySQL, t.Notify.MySQL):
-		return "MySQL Notification configuration differs"
-	case !reflect.DeepEqual(s.Notify.MQTT, t.Notify.MQTT):
-		return "MQTT Notification configuration differs"
+	case !reflect.DeepEqual(s.Tiering, t.Tiering):
+		return "Tiering configuration differs"
+	case !reflect.DeepEqual(s.OpenID, t.OpenID):
+		return "OpenID configuration differs"
+	case !reflect.DeepEqual(s.LDAP, t.LDAP):
+		return "LDAP configuration differs"
+	case !reflect.DeepEqual(s.Bandwidth, t.Bandwidth):
+		return "Bandwidth configuration differs"
+	case !reflect.DeepEqual(s.POSIX, t.POSIX):
+		return "POSIX configuration differs"
+	case !reflect.DeepEqual(s.Federation, t.Federation):
+		return "Federation configuration differs"
+	case notifyTargetsDiff(s.Notify.AMQP, t.Notify.AMQP) != "":
+		return "AMQP Notification configuration differs for target " + notifyTargetsDiff(s.Notify.AMQP, t.Notify.AMQP)
+	case notifyTargetsDiff(s.Notify.NATS, t.Notify.NATS) != "":
+		return "NATS Notification configuration differs for target " + notifyTargetsDiff(s.Notify.NATS, t.Notify.NATS)
+	case notifyTargetsDiff(s.Notify.ElasticSearch, t.Notify.ElasticSearch) != "":
+		return "ElasticSearch Notification configuration differs for target " + notifyTargetsDiff(s.Notify.ElasticSearch, t.Notify.ElasticSearch)
+	case notifyTargetsDiff(s.Notify.Redis, t.Notify.Redis) != "":
+		return "Redis Notification configuration differs for target " + notifyTargetsDiff(s.Notify.Redis, t.Notify.Redis)
+	case notifyTargetsDiff(s.Notify.PostgreSQL, t.Notify.PostgreSQL) != "":
+		return "PostgreSQL Notification configuration differs for target " + notifyTargetsDiff(s.Notify.PostgreSQL, t.Notify.PostgreSQL)
+	case notifyTargetsDiff(s.Notify.Kafka, t.Notify.Kafka) != "":
+		return "Kafka Notification configuration differs for target " + notifyTargetsDiff(s.Notify.Kafka, t.Notify.Kafka)
+	case notifyTargetsDiff(s.Notify.Webhook, t.Notify.Webhook) != "":
+		return "Webhook Notification configuration differs for target " + notifyTargetsDiff(s.Notify.Webhook, t.Notify.Webhook)
+	case notifyTargetsDiff(s.Notify.MySQL, t.Notify.MySQL) != "":
+		return "MySQL Notification configuration differs for target " + notifyTargetsDiff(s.Notify.MySQL, t.Notify.MySQL)
+	case notifyTargetsDiff(s.Notify.MQTT, t.Notify.MQTT) != "":
+		return "MQTT Notification configuration differs for target " + notifyTargetsDiff(s.Notify.MQTT, t.Notify.MQTT)
 	case reflect.DeepEqual(s, t):
 		return ""
 	default:
@@ -152,6 +166,25 @@ func (s *serverConfig) ConfigDiff(t *serverConfig) string {
 	}
 }
 
+// notifyTargetsDiff - returns the id of the first notification target
+// which differs between the maps of targets s and t, empty if none.
+func notifyTargetsDiff(s, t interface{}) string {
+	sTargets, tTargets := reflect.ValueOf(s), reflect.ValueOf(t)
+	ids := set.NewStringSet()
+	for _, id := range append(sTargets.MapKeys(), tTargets.MapKeys()...) {
+		ids.Add(id.String())
+	}
+	for _, id := range ids.ToSlice() {
+		sTarget := sTargets.MapIndex(reflect.ValueOf(id))
+		tTarget := tTargets.MapIndex(reflect.ValueOf(id))
+		if sTarget.IsValid() != tTarget.IsValid() ||
+			sTarget.IsValid() && !reflect.DeepEqual(sTarget.Interface(), tTarget.Interface()) {
+			return id
+		}
+	}
+	return ""
+}
+
 func newServerConfig() *serverConfig {
 	srvCfg := &serverConfig{
 		Version:    serverConfigVersion,
@@ -162,6 +195,18 @@ func newServerConfig() *serverConfig {
 			Standard: storageClass{},
 			RRS:      storageClass{},
 		},
+		Tiering: tieringConfig{
+			Tiers: make(map[string]tierConfig),
+		},
+		OpenID: openIDConfig{
+			Policies: make(map[string]policy.BucketAccessPolicy),
+		},
+		LDAP: ldapConfig{
+			Policies: make(map[string]policy.BucketAccessPolicy),
+		},
+		Federation: federationConfig{
+			Backends: make(map[string]federationBackendConfig),
+		},
 		Notify: notifier{},
 	}
 
@@ -310,6 +355,36 @@ func getValidConfig() (*serverConfig, error) {
 		return nil, errors.New("invalid credential in config file " + configFile)
 	}
 
+	// Validate tiering field
+	if err = srvCfg.Tiering.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Validate openid field
+	if err = srvCfg.OpenID.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Validate ldap field
+	if err = srvCfg.LDAP.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Validate bandwidth field
+	if err = srvCfg.Bandwidth.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Validate posix field
+	if err = srvCfg.POSIX.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Validate federation field
+	if err = srvCfg.Federation.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Validate notify field
 	if err = srvCfg.Notify.Validate(); err != nil {
 		return nil, err
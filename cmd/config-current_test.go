@@ -347,55 +347,67 @@ func TestConfigDiff(t *testing.T) {
 		{
 			&serverConfig{Notify: notifier{AMQP: map[string]amqpNotify{"1": {Enable: true}}}},
 			&serverConfig{Notify: notifier{AMQP: map[string]amqpNotify{"1": {Enable: false}}}},
-			"AMQP Notification configuration differs",
+			"AMQP Notification configuration differs for target 1",
 		},
 		// 8
 		{
 			&serverConfig{Notify: notifier{NATS: map[string]natsNotify{"1": {Enable: true}}}},
 			&serverConfig{Notify: notifier{NATS: map[string]natsNotify{"1": {Enable: false}}}},
-			"NATS Notification configuration differs",
+			"NATS Notification configuration differs for target 1",
 		},
 		// 9
 		{
 			&serverConfig{Notify: notifier{ElasticSearch: map[string]elasticSearchNotify{"1": {Enable: true}}}},
 			&serverConfig{Notify: notifier{ElasticSearch: map[string]elasticSearchNotify{"1": {Enable: false}}}},
-			"ElasticSearch Notification configuration differs",
+			"ElasticSearch Notification configuration differs for target 1",
 		},
 		// 10
 		{
 			&serverConfig{Notify: notifier{Redis: map[string]redisNotify{"1": {Enable: true}}}},
 			&serverConfig{Notify: notifier{Redis: map[string]redisNotify{"1": {Enable: false}}}},
-			"Redis Notification configuration differs",
+			"Redis Notification configuration differs for target 1",
 		},
 		// 11
 		{
 			&serverConfig{Notify: notifier{PostgreSQL: map[string]postgreSQLNotify{"1": {Enable: true}}}},
 			&serverConfig{Notify: notifier{PostgreSQL: map[string]postgreSQLNotify{"1": {Enable: false}}}},
-			"PostgreSQL Notification configuration differs",
+			"PostgreSQL Notification configuration differs for target 1",
 		},
 		// 12
 		{
 			&serverConfig{Notify: notifier{Kafka: map[string]kafkaNotify{"1": {Enable: true}}}},
 			&serverConfig{Notify: notifier{Kafka: map[string]kafkaNotify{"1": {Enable: false}}}},
-			"Kafka Notification configuration differs",
+			"Kafka Notification configuration differs for target 1",
 		},
 		// 13
 		{
 			&serverConfig{Notify: notifier{Webhook: map[string]webhookNotify{"1": {Enable: true}}}},
 			&serverConfig{Notify: notifier{Webhook: map[string]webhookNotify{"1": {Enable: false}}}},
-			"Webhook Notification configuration differs",
+			"Webhook Notification configuration differs for target 1",
 		},
 		// 14
 		{
 			&serverConfig{Notify: notifier{MySQL: map[string]mySQLNotify{"1": {Enable: true}}}},
 			&serverConfig{Notify: notifier{MySQL: map[string]mySQLNotify{"1": {Enable: false}}}},
-			"MySQL Notification configuration differs",
+			"MySQL Notification configuration differs for target 1",
 		},
 		// 15
 		{
 			&serverConfig{Notify: notifier{MQTT: map[string]mqttNotify{"1": {Enable: true}}}},
 			&serverConfig{Notify: notifier{MQTT: map[string]mqttNotify{"1": {Enable: false}}}},
-			"MQTT Notification configuration differs",
+			"MQTT Notification configuration differs for target 1",
+		},
+		// 16
+		{
+			&serverConfig{Notify: notifier{Webhook: map[string]webhookNotify{"1": {Enable: true}}}},
+			&serverConfig{Notify: notifier{Webhook: map[string]webhookNotify{"1": {Enable: true}, "2": {Enable: true}}}},
+			"Webhook Notification configuration differs for target 2",
+		},
+		// 17
+		{
+			&serverConfig{Notify: notifier{Webhook: map[string]webhookNotify{"1": {Enable: true}}}},
+			&serverConfig{Notify: notifier{Webhook: map[string]webhookNotify{"1": {Enable: true}}}},
+			"",
 		},
 	}
 
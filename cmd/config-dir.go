@@ -42,6 +42,16 @@ const (
 
 	// Private key file for HTTPS.
 	privateKeyFile = "private.key"
+
+	// Directory contains the certificate and private key presented to
+	// other nodes of a distributed setup.
+	certsNodeDir = "node"
+
+	// Private host key of the SFTP server.
+	sshHostKeyFile = "ssh_host_rsa_key"
+
+	// Directory contains the bucket configs of a gateway.
+	gatewayConfigDir = "gateway"
 )
 
 // ConfigDir - configuration directory with locking.
@@ -95,6 +105,27 @@ func (config *ConfigDir) GetPrivateKeyFile() string {
 	return filepath.Join(config.getCertsDir(), privateKeyFile)
 }
 
+// GetNodeCADir - returns the directory of the CAs issuing node
+// certificates.
+func (config *ConfigDir) GetNodeCADir() string {
+	return filepath.Join(config.getCertsDir(), certsNodeDir, certsCADir)
+}
+
+// GetNodeCertFile - returns absolute path of the node public.crt file.
+func (config *ConfigDir) GetNodeCertFile() string {
+	return filepath.Join(config.getCertsDir(), certsNodeDir, publicCertFile)
+}
+
+// GetNodeKeyFile - returns absolute path of the node private.key file.
+func (config *ConfigDir) GetNodeKeyFile() string {
+	return filepath.Join(config.getCertsDir(), certsNodeDir, privateKeyFile)
+}
+
+// GetSSHHostKeyFile - returns absolute path of the SSH host key file.
+func (config *ConfigDir) GetSSHHostKeyFile() string {
+	return filepath.Join(config.Get(), sshHostKeyFile)
+}
+
 func mustGetDefaultConfigDir() string {
 	homeDir, err := homedir.Dir()
 	fatalIf(err, "Unable to get home directory.")
@@ -112,6 +143,10 @@ func getConfigDir() string {
 	return configDir.Get()
 }
 
+func getCertsDir() string {
+	return configDir.getCertsDir()
+}
+
 func getCADir() string {
 	return configDir.GetCADir()
 }
@@ -131,3 +166,19 @@ func getPublicCertFile() string {
 func getPrivateKeyFile() string {
 	return configDir.GetPrivateKeyFile()
 }
+
+func getNodeCADir() string {
+	return configDir.GetNodeCADir()
+}
+
+func getNodeCertFile() string {
+	return configDir.GetNodeCertFile()
+}
+
+func getNodeKeyFile() string {
+	return configDir.GetNodeKeyFile()
+}
+
+func getSSHHostKeyFile() string {
+	return configDir.GetSSHHostKeyFile()
+}
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/minio/minio-go/pkg/policy"
 	"github.com/minio/minio/pkg/auth"
 	"github.com/minio/minio/pkg/quick"
 )
@@ -162,6 +163,36 @@ func migrateConfig() error {
 		if err = migrateV21ToV22(); err != nil {
 			return err
 		}
+		fallthrough
+	case "22":
+		if err = migrateV22ToV23(); err != nil {
+			return err
+		}
+		fallthrough
+	case "23":
+		if err = migrateV23ToV24(); err != nil {
+			return err
+		}
+		fallthrough
+	case "24":
+		if err = migrateV24ToV25(); err != nil {
+			return err
+		}
+		fallthrough
+	case "25":
+		if err = migrateV25ToV26(); err != nil {
+			return err
+		}
+		fallthrough
+	case "26":
+		if err = migrateV26ToV27(); err != nil {
+			return err
+		}
+		fallthrough
+	case "27":
+		if err = migrateV27ToV28(); err != nil {
+			return err
+		}
 	case serverConfigVersion:
 		// No migration needed. this always points to current version.
 		err = nil
@@ -1719,7 +1750,7 @@ func migrateV21ToV22() error {
 	srvConfig := &serverConfigV22{
 		Notify: notifier{},
 	}
-	srvConfig.Version = serverConfigVersion
+	srvConfig.Version = "22"
 	srvConfig.Credential = cv21.Credential
 	srvConfig.Region = cv21.Region
 	if srvConfig.Region == "" {
@@ -1807,3 +1838,332 @@ func migrateV21ToV22() error {
 	log.Printf(configMigrateMSGTemplate, configFile, cv21.Version, srvConfig.Version)
 	return nil
 }
+
+func migrateV22ToV23() error {
+	configFile := getConfigFile()
+
+	cv22 := &serverConfigV22{}
+	_, err := quick.Load(configFile, cv22)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Unable to load config version ‘22’. %v", err)
+	}
+	if cv22.Version != "22" {
+		return nil
+	}
+
+	// Copy over fields from V22 into V23 config struct
+	srvConfig := &serverConfigV23{
+		Notify: cv22.Notify,
+	}
+	srvConfig.Version = "23"
+	srvConfig.Credential = cv22.Credential
+	srvConfig.Region = cv22.Region
+	if srvConfig.Region == "" {
+		// Region needs to be set for AWS Signature Version 4.
+		srvConfig.Region = globalMinioDefaultRegion
+	}
+
+	// Load browser config from existing config in the file.
+	srvConfig.Browser = cv22.Browser
+
+	// Load domain config from existing config in the file.
+	srvConfig.Domain = cv22.Domain
+
+	// Load storage class config from existing config in the file.
+	srvConfig.StorageClass = cv22.StorageClass
+
+	// New tiering config, no tiers and rules by default.
+	srvConfig.Tiering = tieringConfig{
+		Tiers: make(map[string]tierConfig),
+	}
+
+	if err = quick.Save(configFile, srvConfig); err != nil {
+		return fmt.Errorf("Failed to migrate config from ‘%s’ to ‘%s’. %v", cv22.Version, srvConfig.Version, err)
+	}
+
+	log.Printf(configMigrateMSGTemplate, configFile, cv22.Version, srvConfig.Version)
+	return nil
+}
+
+func migrateV23ToV24() error {
+	configFile := getConfigFile()
+
+	cv23 := &serverConfigV23{}
+	_, err := quick.Load(configFile, cv23)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Unable to load config version ‘23’. %v", err)
+	}
+	if cv23.Version != "23" {
+		return nil
+	}
+
+	// Copy over fields from V23 into V24 config struct
+	srvConfig := &serverConfigV24{
+		Notify: cv23.Notify,
+	}
+	srvConfig.Version = "24"
+	srvConfig.Credential = cv23.Credential
+	srvConfig.Region = cv23.Region
+	if srvConfig.Region == "" {
+		// Region needs to be set for AWS Signature Version 4.
+		srvConfig.Region = globalMinioDefaultRegion
+	}
+
+	// Load browser config from existing config in the file.
+	srvConfig.Browser = cv23.Browser
+
+	// Load domain config from existing config in the file.
+	srvConfig.Domain = cv23.Domain
+
+	// Load storage class config from existing config in the file.
+	srvConfig.StorageClass = cv23.StorageClass
+
+	// Load tiering config from existing config in the file.
+	srvConfig.Tiering = cv23.Tiering
+
+	// New OpenID config, disabled by default.
+	srvConfig.OpenID = openIDConfig{
+		Policies: make(map[string]policy.BucketAccessPolicy),
+	}
+
+	if err = quick.Save(configFile, srvConfig); err != nil {
+		return fmt.Errorf("Failed to migrate config from ‘%s’ to ‘%s’. %v", cv23.Version, srvConfig.Version, err)
+	}
+
+	log.Printf(configMigrateMSGTemplate, configFile, cv23.Version, srvConfig.Version)
+	return nil
+}
+
+func migrateV24ToV25() error {
+	configFile := getConfigFile()
+
+	cv24 := &serverConfigV24{}
+	_, err := quick.Load(configFile, cv24)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Unable to load config version ‘24’. %v", err)
+	}
+	if cv24.Version != "24" {
+		return nil
+	}
+
+	// Copy over fields from V24 into V25 config struct
+	srvConfig := &serverConfigV25{
+		Notify: cv24.Notify,
+	}
+	srvConfig.Version = "25"
+	srvConfig.Credential = cv24.Credential
+	srvConfig.Region = cv24.Region
+	if srvConfig.Region == "" {
+		// Region needs to be set for AWS Signature Version 4.
+		srvConfig.Region = globalMinioDefaultRegion
+	}
+
+	// Load browser config from existing config in the file.
+	srvConfig.Browser = cv24.Browser
+
+	// Load domain config from existing config in the file.
+	srvConfig.Domain = cv24.Domain
+
+	// Load storage class config from existing config in the file.
+	srvConfig.StorageClass = cv24.StorageClass
+
+	// Load tiering config from existing config in the file.
+	srvConfig.Tiering = cv24.Tiering
+
+	// Load OpenID config from existing config in the file.
+	srvConfig.OpenID = cv24.OpenID
+
+	// New LDAP config, disabled by default.
+	srvConfig.LDAP = ldapConfig{
+		Policies: make(map[string]policy.BucketAccessPolicy),
+	}
+
+	if err = quick.Save(configFile, srvConfig); err != nil {
+		return fmt.Errorf("Failed to migrate config from ‘%s’ to ‘%s’. %v", cv24.Version, srvConfig.Version, err)
+	}
+
+	log.Printf(configMigrateMSGTemplate, configFile, cv24.Version, srvConfig.Version)
+	return nil
+}
+
+func migrateV25ToV26() error {
+	configFile := getConfigFile()
+
+	cv25 := &serverConfigV25{}
+	_, err := quick.Load(configFile, cv25)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Unable to load config version ‘25’. %v", err)
+	}
+	if cv25.Version != "25" {
+		return nil
+	}
+
+	// Copy over fields from V25 into V26 config struct
+	srvConfig := &serverConfigV26{
+		Notify: cv25.Notify,
+	}
+	srvConfig.Version = "26"
+	srvConfig.Credential = cv25.Credential
+	srvConfig.Region = cv25.Region
+	if srvConfig.Region == "" {
+		// Region needs to be set for AWS Signature Version 4.
+		srvConfig.Region = globalMinioDefaultRegion
+	}
+
+	// Load browser config from existing config in the file.
+	srvConfig.Browser = cv25.Browser
+
+	// Load domain config from existing config in the file.
+	srvConfig.Domain = cv25.Domain
+
+	// Load storage class config from existing config in the file.
+	srvConfig.StorageClass = cv25.StorageClass
+
+	// Load tiering config from existing config in the file.
+	srvConfig.Tiering = cv25.Tiering
+
+	// Load OpenID config from existing config in the file.
+	srvConfig.OpenID = cv25.OpenID
+
+	// Load LDAP config from existing config in the file.
+	srvConfig.LDAP = cv25.LDAP
+
+	// New bandwidth config, unlimited by default.
+	srvConfig.Bandwidth = bandwidthConfig{}
+
+	if err = quick.Save(configFile, srvConfig); err != nil {
+		return fmt.Errorf("Failed to migrate config from ‘%s’ to ‘%s’. %v", cv25.Version, srvConfig.Version, err)
+	}
+
+	log.Printf(configMigrateMSGTemplate, configFile, cv25.Version, srvConfig.Version)
+	return nil
+}
+
+func migrateV26ToV27() error {
+	configFile := getConfigFile()
+
+	cv26 := &serverConfigV26{}
+	_, err := quick.Load(configFile, cv26)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Unable to load config version ‘26’. %v", err)
+	}
+	if cv26.Version != "26" {
+		return nil
+	}
+
+	// Copy over fields from V26 into V27 config struct
+	srvConfig := &serverConfigV27{
+		Notify: cv26.Notify,
+	}
+	srvConfig.Version = "27"
+	srvConfig.Credential = cv26.Credential
+	srvConfig.Region = cv26.Region
+	if srvConfig.Region == "" {
+		// Region needs to be set for AWS Signature Version 4.
+		srvConfig.Region = globalMinioDefaultRegion
+	}
+
+	// Load browser config from existing config in the file.
+	srvConfig.Browser = cv26.Browser
+
+	// Load domain config from existing config in the file.
+	srvConfig.Domain = cv26.Domain
+
+	// Load storage class config from existing config in the file.
+	srvConfig.StorageClass = cv26.StorageClass
+
+	// Load tiering config from existing config in the file.
+	srvConfig.Tiering = cv26.Tiering
+
+	// Load OpenID config from existing config in the file.
+	srvConfig.OpenID = cv26.OpenID
+
+	// Load LDAP config from existing config in the file.
+	srvConfig.LDAP = cv26.LDAP
+
+	// Load bandwidth config from existing config in the file.
+	srvConfig.Bandwidth = cv26.Bandwidth
+
+	// New POSIX config, objects are owned by the server by default.
+	srvConfig.POSIX = posixConfig{}
+
+	if err = quick.Save(configFile, srvConfig); err != nil {
+		return fmt.Errorf("Failed to migrate config from ‘%s’ to ‘%s’. %v", cv26.Version, srvConfig.Version, err)
+	}
+
+	log.Printf(configMigrateMSGTemplate, configFile, cv26.Version, srvConfig.Version)
+	return nil
+}
+
+func migrateV27ToV28() error {
+	configFile := getConfigFile()
+
+	cv27 := &serverConfigV27{}
+	_, err := quick.Load(configFile, cv27)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Unable to load config version ‘27’. %v", err)
+	}
+	if cv27.Version != "27" {
+		return nil
+	}
+
+	// Copy over fields from V27 into V28 config struct
+	srvConfig := &serverConfigV28{
+		Notify: cv27.Notify,
+	}
+	srvConfig.Version = serverConfigVersion
+	srvConfig.Credential = cv27.Credential
+	srvConfig.Region = cv27.Region
+	if srvConfig.Region == "" {
+		// Region needs to be set for AWS Signature Version 4.
+		srvConfig.Region = globalMinioDefaultRegion
+	}
+
+	// Load browser config from existing config in the file.
+	srvConfig.Browser = cv27.Browser
+
+	// Load domain config from existing config in the file.
+	srvConfig.Domain = cv27.Domain
+
+	// Load storage class config from existing config in the file.
+	srvConfig.StorageClass = cv27.StorageClass
+
+	// Load tiering config from existing config in the file.
+	srvConfig.Tiering = cv27.Tiering
+
+	// Load OpenID config from existing config in the file.
+	srvConfig.OpenID = cv27.OpenID
+
+	// Load LDAP config from existing config in the file.
+	srvConfig.LDAP = cv27.LDAP
+
+	// Load bandwidth config from existing config in the file.
+	srvConfig.Bandwidth = cv27.Bandwidth
+
+	// Load POSIX config from existing config in the file.
+	srvConfig.POSIX = cv27.POSIX
+
+	// New federation config, no backends are federated by default.
+	srvConfig.Federation = federationConfig{
+		Backends: make(map[string]federationBackendConfig),
+	}
+
+	if err = quick.Save(configFile, srvConfig); err != nil {
+		return fmt.Errorf("Failed to migrate config from ‘%s’ to ‘%s’. %v", cv27.Version, srvConfig.Version, err)
+	}
+
+	log.Printf(configMigrateMSGTemplate, configFile, cv27.Version, srvConfig.Version)
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Subsystems of the server configuration which can be read and
+// updated individually through the admin API. Notification targets
+// are addressed as `notify/<type>/<id>`, e.g. `notify/webhook/1`.
+const (
+	configSubsysRegion       = "region"
+	configSubsysBrowser      = "browser"
+	configSubsysStorageClass = "storageclass"
+	configSubsysBandwidth    = "bandwidth"
+	configSubsysPOSIX        = "posix"
+	configSubsysNotify       = "notify"
+)
+
+var errConfigSubsysUnknown = errors.New("unknown configuration subsystem")
+
+// isConfigSubsysEnvSet - returns true if subsys is set by environment
+// variables, which take precedence over config.json.
+func isConfigSubsysEnvSet(subsys string) bool {
+	switch subsys {
+	case configSubsysRegion:
+		return globalIsEnvRegion
+	case configSubsysBrowser:
+		return globalIsEnvBrowser
+	case configSubsysStorageClass:
+		return globalIsStorageClass
+	}
+	return false
+}
+
+// configSubsysNeedsRestart - returns true if changes of subsys only
+// take effect after a restart. Browser routes are registered at
+// startup, all other subsystems are reloaded by reloadServerConfig.
+func configSubsysNeedsRestart(subsys string) bool {
+	return subsys == configSubsysBrowser
+}
+
+// notifyTargets - returns the map of notification targets and the
+// target id addressed by subsys `notify/<type>/<id>` in config.
+func notifyTargets(config *serverConfig, subsys string) (targets reflect.Value, id string, err error) {
+	parts := strings.Split(subsys, "/")
+	if len(parts) != 3 || parts[0] != configSubsysNotify || parts[2] == "" {
+		return targets, id, errConfigSubsysUnknown
+	}
+
+	notify := reflect.ValueOf(&config.Notify).Elem()
+	for i := 0; i < notify.NumField(); i++ {
+		if notify.Type().Field(i).Tag.Get("json") == parts[1] {
+			return notify.Field(i), parts[2], nil
+		}
+	}
+	return targets, id, errConfigSubsysUnknown
+}
+
+// getConfigSubsys - returns the JSON configuration of subsys in config.
+func getConfigSubsys(config *serverConfig, subsys string) ([]byte, error) {
+	switch subsys {
+	case configSubsysRegion:
+		return json.Marshal(config.Region)
+	case configSubsysBrowser:
+		return json.Marshal(config.Browser)
+	case configSubsysStorageClass:
+		return json.Marshal(&config.StorageClass)
+	case configSubsysBandwidth:
+		return json.Marshal(config.Bandwidth)
+	case configSubsysPOSIX:
+		return json.Marshal(config.POSIX)
+	}
+
+	targets, id, err := notifyTargets(config, subsys)
+	if err != nil {
+		return nil, err
+	}
+	target := targets.MapIndex(reflect.ValueOf(id))
+	if !target.IsValid() {
+		return nil, errConfigSubsysUnknown
+	}
+	return json.Marshal(target.Interface())
+}
+
+// setConfigSubsys - replaces the configuration of subsys in config by
+// the JSON configuration in data and validates it.
+func setConfigSubsys(config *serverConfig, subsys string, data []byte) error {
+	switch subsys {
+	case configSubsysRegion:
+		var region string
+		if err := json.Unmarshal(data, &region); err != nil {
+			return err
+		}
+		if region == "" {
+			return errors.New("region cannot be empty")
+		}
+		config.SetRegion(region)
+		return nil
+	case configSubsysBrowser:
+		return json.Unmarshal(data, &config.Browser)
+	case configSubsysStorageClass:
+		// Parities are validated while unmarshalling.
+		var sCfg storageClassConfig
+		if err := json.Unmarshal(data, &sCfg); err != nil {
+			return err
+		}
+		config.SetStorageClass(sCfg.Standard, sCfg.RRS)
+		return nil
+	case configSubsysBandwidth:
+		var bCfg bandwidthConfig
+		if err := json.Unmarshal(data, &bCfg); err != nil {
+			return err
+		}
+		if err := bCfg.Validate(); err != nil {
+			return err
+		}
+		config.Bandwidth = bCfg
+		return nil
+	case configSubsysPOSIX:
+		var pCfg posixConfig
+		if err := json.Unmarshal(data, &pCfg); err != nil {
+			return err
+		}
+		if err := pCfg.Validate(); err != nil {
+			return err
+		}
+		config.POSIX = pCfg
+		return nil
+	}
+
+	targets, id, err := notifyTargets(config, subsys)
+	if err != nil {
+		return err
+	}
+	target := reflect.New(targets.Type().Elem())
+	if err = json.Unmarshal(data, target.Interface()); err != nil {
+		return err
+	}
+	if targets.IsNil() {
+		targets.Set(reflect.MakeMap(targets.Type()))
+	}
+	targets.SetMapIndex(reflect.ValueOf(id), target.Elem())
+	return config.Notify.Validate()
+}
+
+// reloadServerConfig - loads config.json committed through the admin
+// API and applies it to the running server without a restart.
+func reloadServerConfig() error {
+	globalServerConfigMu.RLock()
+	prevConfig := globalServerConfig
+	globalServerConfigMu.RUnlock()
+
+	if err := loadConfig(); err != nil {
+		return err
+	}
+
+	globalServerConfigMu.RLock()
+	config := globalServerConfig
+	globalServerConfigMu.RUnlock()
+
+	// ARNs of notification targets contain the region.
+	if globalEventNotifier != nil && (prevConfig == nil ||
+		prevConfig.Region != config.Region ||
+		!reflect.DeepEqual(prevConfig.Notify, config.Notify)) {
+		return globalEventNotifier.ReloadExternalTargets()
+	}
+	return nil
+}
@@ -566,3 +566,192 @@ type serverConfigV22 struct {
 	// Notification queue configuration.
 	Notify notifier `json:"notify"`
 }
+
+// serverConfigV23 is just like version '22' with added support
+// for tiering cold objects to remote storage.
+//
+// IMPORTANT NOTE: When updating this struct make sure that
+// serverConfig.ConfigDiff() is updated as necessary.
+type serverConfigV23 struct {
+	Version string `json:"version"`
+
+	// S3 API configuration.
+	Credential auth.Credentials `json:"credential"`
+	Region     string           `json:"region"`
+	Browser    BrowserFlag      `json:"browser"`
+	Domain     string           `json:"domain"`
+
+	// Storage class configuration
+	StorageClass storageClassConfig `json:"storageclass"`
+
+	// Tiering configuration
+	Tiering tieringConfig `json:"tiering"`
+
+	// Notification queue configuration.
+	Notify notifier `json:"notify"`
+}
+
+// serverConfigV24 is just like version '23' with added support
+// for logging in to the browser with an OpenID Connect provider.
+//
+// IMPORTANT NOTE: When updating this struct make sure that
+// serverConfig.ConfigDiff() is updated as necessary.
+type serverConfigV24 struct {
+	Version string `json:"version"`
+
+	// S3 API configuration.
+	Credential auth.Credentials `json:"credential"`
+	Region     string           `json:"region"`
+	Browser    BrowserFlag      `json:"browser"`
+	Domain     string           `json:"domain"`
+
+	// Storage class configuration
+	StorageClass storageClassConfig `json:"storageclass"`
+
+	// Tiering configuration
+	Tiering tieringConfig `json:"tiering"`
+
+	// OpenID Connect browser login configuration
+	OpenID openIDConfig `json:"openid"`
+
+	// Notification queue configuration.
+	Notify notifier `json:"notify"`
+}
+
+// serverConfigV25 is just like version '24' with added support
+// for issuing temporary credentials to LDAP users.
+//
+// IMPORTANT NOTE: When updating this struct make sure that
+// serverConfig.ConfigDiff() is updated as necessary.
+type serverConfigV25 struct {
+	Version string `json:"version"`
+
+	// S3 API configuration.
+	Credential auth.Credentials `json:"credential"`
+	Region     string           `json:"region"`
+	Browser    BrowserFlag      `json:"browser"`
+	Domain     string           `json:"domain"`
+
+	// Storage class configuration
+	StorageClass storageClassConfig `json:"storageclass"`
+
+	// Tiering configuration
+	Tiering tieringConfig `json:"tiering"`
+
+	// OpenID Connect browser login configuration
+	OpenID openIDConfig `json:"openid"`
+
+	// LDAP authentication configuration
+	LDAP ldapConfig `json:"ldap"`
+
+	// Notification queue configuration.
+	Notify notifier `json:"notify"`
+}
+
+// serverConfigV26 is just like version '25' with added support
+// for bandwidth limits per bucket and access key.
+//
+// IMPORTANT NOTE: When updating this struct make sure that
+// serverConfig.ConfigDiff() is updated as necessary.
+type serverConfigV26 struct {
+	Version string `json:"version"`
+
+	// S3 API configuration.
+	Credential auth.Credentials `json:"credential"`
+	Region     string           `json:"region"`
+	Browser    BrowserFlag      `json:"browser"`
+	Domain     string           `json:"domain"`
+
+	// Storage class configuration
+	StorageClass storageClassConfig `json:"storageclass"`
+
+	// Tiering configuration
+	Tiering tieringConfig `json:"tiering"`
+
+	// OpenID Connect browser login configuration
+	OpenID openIDConfig `json:"openid"`
+
+	// LDAP authentication configuration
+	LDAP ldapConfig `json:"ldap"`
+
+	// Bandwidth limits of uploads and downloads
+	Bandwidth bandwidthConfig `json:"bandwidth"`
+
+	// Notification queue configuration.
+	Notify notifier `json:"notify"`
+}
+
+// serverConfigV27 is just like version '26' with added support
+// for POSIX ownership and permissions of objects in FS mode.
+//
+// IMPORTANT NOTE: When updating this struct make sure that
+// serverConfig.ConfigDiff() is updated as necessary.
+type serverConfigV27 struct {
+	Version string `json:"version"`
+
+	// S3 API configuration.
+	Credential auth.Credentials `json:"credential"`
+	Region     string           `json:"region"`
+	Browser    BrowserFlag      `json:"browser"`
+	Domain     string           `json:"domain"`
+
+	// Storage class configuration
+	StorageClass storageClassConfig `json:"storageclass"`
+
+	// Tiering configuration
+	Tiering tieringConfig `json:"tiering"`
+
+	// OpenID Connect browser login configuration
+	OpenID openIDConfig `json:"openid"`
+
+	// LDAP authentication configuration
+	LDAP ldapConfig `json:"ldap"`
+
+	// Bandwidth limits of uploads and downloads
+	Bandwidth bandwidthConfig `json:"bandwidth"`
+
+	// POSIX ownership and permissions in FS mode
+	POSIX posixConfig `json:"posix"`
+
+	// Notification queue configuration.
+	Notify notifier `json:"notify"`
+}
+
+// serverConfigV28 is just like version '27' with added support
+// for federated gateways routing buckets to several backends.
+//
+// IMPORTANT NOTE: When updating this struct make sure that
+// serverConfig.ConfigDiff() is updated as necessary.
+type serverConfigV28 struct {
+	Version string `json:"version"`
+
+	// S3 API configuration.
+	Credential auth.Credentials `json:"credential"`
+	Region     string           `json:"region"`
+	Browser    BrowserFlag      `json:"browser"`
+	Domain     string           `json:"domain"`
+
+	// Storage class configuration
+	StorageClass storageClassConfig `json:"storageclass"`
+
+	// Tiering configuration
+	Tiering tieringConfig `json:"tiering"`
+
+	// OpenID Connect browser login configuration
+	OpenID openIDConfig `json:"openid"`
+
+	// LDAP authentication configuration
+	LDAP ldapConfig `json:"ldap"`
+
+	// Bandwidth limits of uploads and downloads
+	Bandwidth bandwidthConfig `json:"bandwidth"`
+
+	// POSIX ownership and permissions in FS mode
+	POSIX posixConfig `json:"posix"`
+
+	// Backends of the federated gateway and their buckets
+	Federation federationConfig `json:"federation"`
+
+	// Notification queue configuration.
+	Notify notifier `json:"notify"`
+}
@@ -0,0 +1,197 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/klauspost/reedsolomon"
+	"github.com/minio/minio/pkg/errors"
+)
+
+// Erasure coding algorithms, the algorithm of an object is saved in
+// its `xl.json`.
+const (
+	// Reed-Solomon code with a Vandermonde matrix, default for all
+	// objects.
+	erasureAlgorithmKlauspost = "klauspost/reedsolomon/vandermonde"
+
+	// Reed-Solomon code with a Cauchy matrix.
+	erasureAlgorithmCauchy = "klauspost/reedsolomon/cauchy"
+
+	// Locally repairable code, a single lost block is rebuilt from
+	// the blocks of its local group only.
+	erasureAlgorithmLRC = "minio/lrc/cauchy"
+)
+
+// ErasureCode is implemented by erasure codes protecting object data.
+// Blocks are always ordered data blocks first, followed by parity
+// blocks. A missing block is represented by a zero-length block.
+type ErasureCode interface {
+	// Encode splits data into data blocks and computes the
+	// parity blocks.
+	Encode(data []byte) ([][]byte, error)
+
+	// DecodeDataBlocks reconstructs the missing data blocks,
+	// missing parity blocks may be left missing.
+	DecodeDataBlocks(blocks [][]byte) error
+
+	// DecodeDataAndParityBlocks reconstructs all missing blocks.
+	DecodeDataAndParityBlocks(blocks [][]byte) error
+
+	// HealReadSet returns the indices of the blocks to read to
+	// reconstruct the stale blocks, choosing the cheapest repair
+	// the code offers. Unavailable blocks, which include stale
+	// blocks, are never returned. It returns nil if the stale
+	// blocks cannot be reconstructed.
+	HealReadSet(stale, unavailable []bool) []int
+
+	// HealBlocks reconstructs the stale blocks from the blocks
+	// returned by HealReadSet.
+	HealBlocks(blocks [][]byte, stale []bool) error
+}
+
+// NewErasureCode returns the erasure code implementing algorithm. An
+// empty algorithm selects erasureAlgorithmKlauspost.
+func NewErasureCode(algorithm string, dataBlocks, parityBlocks int, blockSize int64) (ErasureCode, error) {
+	switch algorithm {
+	case "", erasureAlgorithmKlauspost:
+		if !globalErasureSIMD {
+			return newVandermondeErasure(dataBlocks, parityBlocks)
+		}
+		return newReedSolomonErasure(dataBlocks, parityBlocks, blockSize)
+	case erasureAlgorithmCauchy:
+		if !globalErasureSIMD {
+			return newCauchyErasure(dataBlocks, parityBlocks)
+		}
+		return newReedSolomonErasure(dataBlocks, parityBlocks, blockSize, reedsolomon.WithCauchyMatrix())
+	case erasureAlgorithmLRC:
+		return newLRCErasure(dataBlocks, parityBlocks)
+	}
+	return nil, errors.Trace(errErasureAlgorithmUnknown)
+}
+
+// isValidErasureAlgorithm - returns true if algorithm is supported.
+func isValidErasureAlgorithm(algorithm string) bool {
+	switch algorithm {
+	case erasureAlgorithmKlauspost, erasureAlgorithmCauchy, erasureAlgorithmLRC:
+		return true
+	}
+	return false
+}
+
+// erasureAlgorithmFor - returns the configured erasure algorithm for
+// new objects, or the default one if the configured algorithm cannot
+// be used with the given number of data and parity blocks.
+func erasureAlgorithmFor(dataBlocks, parityBlocks int) string {
+	if globalErasureAlgorithm == erasureAlgorithmLRC && lrcLocalGroups(dataBlocks, parityBlocks) == 0 {
+		return erasureAlgorithmKlauspost
+	}
+	return globalErasureAlgorithm
+}
+
+// erasureReadQuorum - returns the number of disks an object has to be
+// read from, such that its data can always be decoded.
+func erasureReadQuorum(algorithm string, dataBlocks, parityBlocks int) int {
+	if algorithm == erasureAlgorithmLRC {
+		// Local parity blocks do not add to the number of lost
+		// blocks the code always tolerates.
+		return dataBlocks + lrcLocalGroups(dataBlocks, parityBlocks)
+	}
+	return dataBlocks
+}
+
+// erasureWriteQuorum - returns the number of disks an object has to
+// be written to, such that it survives the loss of one more disk.
+func erasureWriteQuorum(algorithm string, dataBlocks, parityBlocks int) int {
+	return erasureReadQuorum(algorithm, dataBlocks, parityBlocks) + 1
+}
+
+// reedSolomonErasure - Reed-Solomon code implemented by
+// klauspost/reedsolomon, which uses SIMD instructions when available.
+type reedSolomonErasure struct {
+	encoder                  reedsolomon.Encoder
+	dataBlocks, parityBlocks int
+}
+
+func newReedSolomonErasure(dataBlocks, parityBlocks int, blockSize int64, opts ...reedsolomon.Option) (ErasureCode, error) {
+	if err := checkErasureGeometry(dataBlocks, parityBlocks); err != nil {
+		return nil, err
+	}
+	shardsize := (int(blockSize) + dataBlocks - 1) / dataBlocks
+	opts = append(opts, reedsolomon.WithAutoGoroutines(shardsize))
+	encoder, err := reedsolomon.New(dataBlocks, parityBlocks, opts...)
+	if err != nil {
+		return nil, errors.Tracef("failed to create erasure coding: %v", err)
+	}
+	return &reedSolomonErasure{
+		encoder:      encoder,
+		dataBlocks:   dataBlocks,
+		parityBlocks: parityBlocks,
+	}, nil
+}
+
+func (e *reedSolomonErasure) Encode(data []byte) ([][]byte, error) {
+	encoded, err := e.encoder.Split(data)
+	if err != nil {
+		return nil, errors.Tracef("failed to split data: %v", err)
+	}
+	if err = e.encoder.Encode(encoded); err != nil {
+		return nil, errors.Tracef("failed to encode data: %v", err)
+	}
+	return encoded, nil
+}
+
+func (e *reedSolomonErasure) DecodeDataBlocks(blocks [][]byte) error {
+	if err := e.encoder.ReconstructData(blocks); err != nil {
+		return errors.Tracef("failed to reconstruct data: %v", err)
+	}
+	return nil
+}
+
+func (e *reedSolomonErasure) DecodeDataAndParityBlocks(blocks [][]byte) error {
+	if err := e.encoder.Reconstruct(blocks); err != nil {
+		return errors.Tracef("failed to reconstruct data: %v", err)
+	}
+	return nil
+}
+
+// HealReadSet - any dataBlocks blocks reconstruct all others, data
+// blocks are preferred as they are read first during GET too.
+func (e *reedSolomonErasure) HealReadSet(stale, unavailable []bool) []int {
+	return firstAvailableBlocks(unavailable, e.dataBlocks)
+}
+
+func (e *reedSolomonErasure) HealBlocks(blocks [][]byte, stale []bool) error {
+	return e.DecodeDataAndParityBlocks(blocks)
+}
+
+// firstAvailableBlocks - returns the indices of the first n available
+// blocks, nil if there are fewer than n.
+func firstAvailableBlocks(unavailable []bool, n int) []int {
+	var indices []int
+	for i := range unavailable {
+		if len(indices) == n {
+			break
+		}
+		if !unavailable[i] {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) < n {
+		return nil
+	}
+	return indices
+}
@@ -0,0 +1,158 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	mrand "math/rand"
+	"testing"
+)
+
+// Tests that the plain Go erasure codes produce the same blocks as
+// klauspost/reedsolomon, such that objects written with and without
+// SIMD support are interchangeable.
+func TestErasureCodeCompatibility(t *testing.T) {
+	data := make([]byte, 1000)
+	if _, err := io.ReadFull(rand.Reader, data); err != nil {
+		t.Fatalf("Failed to read random data: %v", err)
+	}
+	testCases := []struct {
+		algorithm                string
+		dataBlocks, parityBlocks int
+	}{
+		{erasureAlgorithmKlauspost, 2, 2},
+		{erasureAlgorithmKlauspost, 5, 3},
+		{erasureAlgorithmKlauspost, 8, 8},
+		{erasureAlgorithmKlauspost, 12, 4},
+		{erasureAlgorithmCauchy, 2, 2},
+		{erasureAlgorithmCauchy, 7, 3},
+		{erasureAlgorithmCauchy, 8, 8},
+	}
+	for i, testCase := range testCases {
+		simd, err := NewErasureCode(testCase.algorithm, testCase.dataBlocks, testCase.parityBlocks, blockSizeV1)
+		if err != nil {
+			t.Fatalf("Test %d: failed to create erasure code: %v", i+1, err)
+		}
+		globalErasureSIMD = false
+		plain, err := NewErasureCode(testCase.algorithm, testCase.dataBlocks, testCase.parityBlocks, blockSizeV1)
+		globalErasureSIMD = true
+		if err != nil {
+			t.Fatalf("Test %d: failed to create erasure code: %v", i+1, err)
+		}
+		if _, ok := plain.(*matrixErasure); !ok {
+			t.Fatalf("Test %d: expected plain Go erasure code, got %T", i+1, plain)
+		}
+
+		want, err := simd.Encode(append([]byte{}, data...))
+		if err != nil {
+			t.Fatalf("Test %d: failed to encode data: %v", i+1, err)
+		}
+		got, err := plain.Encode(append([]byte{}, data...))
+		if err != nil {
+			t.Fatalf("Test %d: failed to encode data: %v", i+1, err)
+		}
+		for j := range want {
+			if !bytes.Equal(got[j], want[j]) {
+				t.Errorf("Test %d: block %d differs from klauspost/reedsolomon", i+1, j)
+			}
+		}
+	}
+}
+
+// Tests that all erasure codes reconstruct all blocks after losing as
+// many blocks as they always tolerate.
+func TestErasureCodeDecode(t *testing.T) {
+	data := make([]byte, 1000)
+	if _, err := io.ReadFull(rand.Reader, data); err != nil {
+		t.Fatalf("Failed to read random data: %v", err)
+	}
+	testCases := []struct {
+		algorithm                string
+		simd                     bool
+		dataBlocks, parityBlocks int
+	}{
+		{erasureAlgorithmKlauspost, true, 4, 4},
+		{erasureAlgorithmKlauspost, false, 4, 4},
+		{erasureAlgorithmKlauspost, false, 10, 6},
+		{erasureAlgorithmCauchy, true, 6, 2},
+		{erasureAlgorithmCauchy, false, 6, 2},
+		{erasureAlgorithmCauchy, false, 8, 8},
+		{erasureAlgorithmLRC, true, 4, 4},
+		{erasureAlgorithmLRC, true, 8, 4},
+		{erasureAlgorithmLRC, true, 9, 7},
+	}
+	for i, testCase := range testCases {
+		globalErasureSIMD = testCase.simd
+		erasure, err := NewErasureCode(testCase.algorithm, testCase.dataBlocks, testCase.parityBlocks, blockSizeV1)
+		globalErasureSIMD = true
+		if err != nil {
+			t.Fatalf("Test %d: failed to create erasure code: %v", i+1, err)
+		}
+		n := testCase.dataBlocks + testCase.parityBlocks
+		tolerated := n - erasureWriteQuorum(testCase.algorithm, testCase.dataBlocks, testCase.parityBlocks) + 1
+
+		for run := 0; run < 20; run++ {
+			encoded, err := erasure.Encode(append([]byte{}, data...))
+			if err != nil {
+				t.Fatalf("Test %d: failed to encode data: %v", i+1, err)
+			}
+			want := make([][]byte, n)
+			for j := range encoded {
+				want[j] = append([]byte{}, encoded[j]...)
+			}
+			for _, j := range mrand.Perm(n)[:tolerated] {
+				encoded[j] = nil
+			}
+			if err = erasure.DecodeDataAndParityBlocks(encoded); err != nil {
+				t.Fatalf("Test %d: failed to decode blocks: %v", i+1, err)
+			}
+			for j := range want {
+				if !bytes.Equal(encoded[j], want[j]) {
+					t.Fatalf("Test %d: block %d not reconstructed", i+1, j)
+				}
+			}
+		}
+	}
+}
+
+func TestNewErasure(t *testing.T) {
+	testCases := []struct {
+		algorithm                string
+		dataBlocks, parityBlocks int
+		shouldFail               bool
+	}{
+		{"", 8, 8, false},
+		{erasureAlgorithmKlauspost, 8, 8, false},
+		{erasureAlgorithmCauchy, 2, 2, false},
+		{erasureAlgorithmLRC, 8, 4, false},
+		{erasureAlgorithmLRC, 3, 3, true},
+		{erasureAlgorithmLRC, 6, 2, true},
+		{erasureAlgorithmKlauspost, 0, 2, true},
+		{"unknown/algorithm", 8, 8, true},
+	}
+	for i, testCase := range testCases {
+		_, err := NewErasureCode(testCase.algorithm, testCase.dataBlocks, testCase.parityBlocks, blockSizeV1)
+		if err != nil && !testCase.shouldFail {
+			t.Errorf("Test %d: should pass but it failed with: %v", i+1, err)
+		}
+		if err == nil && testCase.shouldFail {
+			t.Errorf("Test %d: should fail but it passed", i+1)
+		}
+	}
+}
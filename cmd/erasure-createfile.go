@@ -40,6 +40,21 @@ func (s *ErasureStorage) CreateFile(src io.Reader, volume, path string, buffer [
 		errChans[i] = make(chan error, 1) // create buffered channel to let finished go-routines die early
 	}
 
+	// Every disk receives its shards over a single stream.
+	writers := make([]*erasureShardWriter, len(s.disks))
+	for i, disk := range s.disks {
+		if disk != OfflineDisk {
+			writers[i] = newErasureShardWriter(disk, volume, path)
+		}
+	}
+	defer func() {
+		for _, w := range writers {
+			if w != nil { // abort all streams on early return
+				w.CloseWithError(errUnexpected)
+			}
+		}
+	}()
+
 	var blocks [][]byte
 	var n = len(buffer)
 	for n == len(buffer) {
@@ -59,18 +74,31 @@ func (s *ErasureStorage) CreateFile(src io.Reader, volume, path string, buffer [
 		}
 
 		for i := range errChans { // span workers
-			go erasureAppendFile(s.disks[i], volume, path, hashers[i], blocks[i], errChans[i])
+			go erasureAppendFile(writers[i], hashers[i], blocks[i], errChans[i])
 		}
 		for i := range errChans { // what until all workers are finished
 			errs[i] = <-errChans[i]
 		}
-		if err = reduceWriteQuorumErrs(errs, objectOpIgnoredErrs, writeQuorum); err != nil {
+		if err = s.evalShardWriters(writers, errs, writeQuorum); err != nil {
 			return f, err
 		}
-		s.disks = evalDisks(s.disks, errs)
 		f.Size += int64(n)
 	}
 
+	// Data is only persisted once the streams are closed.
+	for i, w := range writers {
+		if w == nil {
+			errs[i] = errors.Trace(errDiskNotFound)
+			continue
+		}
+		errs[i] = w.Close()
+	}
+	writers = nil
+	if err = reduceWriteQuorumErrs(errs, objectOpIgnoredErrs, writeQuorum); err != nil {
+		return f, err
+	}
+	s.disks = evalDisks(s.disks, errs)
+
 	f.Algorithm = algorithm
 	for i, disk := range s.disks {
 		if disk == OfflineDisk {
@@ -81,18 +109,71 @@ func (s *ErasureStorage) CreateFile(src io.Reader, volume, path string, buffer [
 	return f, nil
 }
 
-// erasureAppendFile appends the content of buf to the file on the given disk and updates computes
-// the hash of the written data. It sends the write error (or nil) over the error channel.
-func erasureAppendFile(disk StorageAPI, volume, path string, hash hash.Hash, buf []byte, errChan chan<- error) {
-	if disk == OfflineDisk {
+// evalShardWriters checks whether enough shards have been written and
+// stops streaming to all disks which failed.
+func (s *ErasureStorage) evalShardWriters(writers []*erasureShardWriter, errs []error, writeQuorum int) error {
+	if err := reduceWriteQuorumErrs(errs, objectOpIgnoredErrs, writeQuorum); err != nil {
+		return err
+	}
+	s.disks = evalDisks(s.disks, errs)
+	for i, err := range errs {
+		if err != nil && writers[i] != nil {
+			writers[i].CloseWithError(err)
+			writers[i] = nil
+		}
+	}
+	return nil
+}
+
+// erasureShardWriter streams all shards of a file to a disk, a remote
+// disk receives the whole file in a single request.
+type erasureShardWriter struct {
+	pw    *io.PipeWriter
+	errCh chan error
+}
+
+// newErasureShardWriter starts creating the file on the given disk.
+func newErasureShardWriter(disk StorageAPI, volume, path string) *erasureShardWriter {
+	pr, pw := io.Pipe()
+	w := &erasureShardWriter{pw: pw, errCh: make(chan error, 1)}
+	go func() {
+		err := disk.CreateFile(volume, path, -1, pr)
+		// Unblock the writer if the disk failed before reading all data.
+		pr.CloseWithError(err)
+		w.errCh <- err
+	}()
+	return w
+}
+
+// Write sends buf to the disk.
+func (w *erasureShardWriter) Write(buf []byte) (int, error) {
+	return w.pw.Write(buf)
+}
+
+// Close ends the stream and returns the result of creating the file.
+func (w *erasureShardWriter) Close() error {
+	w.pw.Close()
+	return <-w.errCh
+}
+
+// CloseWithError aborts the stream, the disk fails to create the file.
+func (w *erasureShardWriter) CloseWithError(err error) {
+	w.pw.CloseWithError(err)
+}
+
+// erasureAppendFile appends the content of buf to the shard stream and updates computes the hash
+// of the written data. It sends the write error (or nil) over the error channel.
+func erasureAppendFile(w *erasureShardWriter, hash hash.Hash, buf []byte, errChan chan<- error) {
+	if w == nil {
 		errChan <- errors.Trace(errDiskNotFound)
 		return
 	}
-	err := disk.AppendFile(volume, path, buf)
-	if err != nil {
-		errChan <- err
-		return
+	if len(buf) > 0 {
+		if _, err := w.Write(buf); err != nil {
+			errChan <- err
+			return
+		}
 	}
 	hash.Write(buf)
-	errChan <- err
+	errChan <- nil
 }
@@ -57,19 +57,26 @@ func (s ErasureStorage) HealFile(staleDisks []StorageAPI, volume, path string, b
 	f.Checksums = make([][]byte, len(s.disks))
 	hashers := make([]hash.Hash, len(s.disks))
 	verifiers := make([]*BitrotVerifier, len(s.disks))
+	stale := make([]bool, len(s.disks))
+	unavailable := make([]bool, len(s.disks))
 	for i, disk := range s.disks {
 		switch {
 		case staleDisks[i] != nil:
 			hashers[i] = alg.New()
+			stale[i], unavailable[i] = true, true
 		case disk == nil:
 			// disregard unavailable disk
-			continue
+			unavailable[i] = true
 		default:
 			verifiers[i] = NewBitrotVerifier(alg, checksums[i])
 		}
 	}
 	writeErrors := make([]error, len(s.disks))
 
+	// Let the erasure code choose the cheapest set of disks to
+	// read from to reconstruct the stale disks.
+	readSet := s.erasure.HealReadSet(stale, unavailable)
+
 	// Scan part files on disk, block-by-block reconstruct it and
 	// write to stale disks.
 	chunksize := getChunkSize(blocksize, s.dataBlocks)
@@ -86,36 +93,36 @@ func (s ErasureStorage) HealFile(staleDisks []StorageAPI, volume, path string, b
 		// left, so chunksize needs to be recomputed.
 		if size < blockOffset+blocksize {
 			chunksize = getChunkSize(size-blockOffset, s.dataBlocks)
-			for i := range blocks {
-				blocks[i] = blocks[i][:chunksize]
-			}
 		}
-		// read a chunk from each disk, until we have
-		// `s.dataBlocks` number of chunks set to non-nil in
-		// `blocks`
-		numReads := 0
-		for i, disk := range s.disks {
-			// skip reading from unavailable or stale disks
-			if disk == nil || staleDisks[i] != nil {
-				blocks[i] = blocks[i][:0] // mark shard as missing
-				continue
-			}
-			_, err = disk.ReadFile(volume, path, chunkOffset, blocks[i], verifiers[i])
-			if err != nil {
-				// LOG FIXME: add a conditional log
-				// for read failures, once per-disk
-				// per-function-invocation.
-				blocks[i] = blocks[i][:0] // mark shard as missing
-				continue
+		for i := range blocks {
+			blocks[i] = blocks[i][:0] // mark shard as missing
+		}
+
+		// read a chunk from each disk of the read set, a disk
+		// failing to read is excluded from the read set for
+		// this and all following chunks.
+		for done := false; !done; {
+			if readSet == nil {
+				return f, errors.Trace(errXLReadQuorum)
 			}
-			numReads++
-			if numReads == s.dataBlocks {
-				// we have enough data to reconstruct
-				// mark all other blocks as missing
-				for j := i + 1; j < len(blocks); j++ {
-					blocks[j] = blocks[j][:0] // mark shard as missing
+			done = true
+			for _, i := range readSet {
+				if len(blocks[i]) > 0 {
+					continue
 				}
-				break
+				blocks[i] = blocks[i][:chunksize]
+				_, err = s.disks[i].ReadFile(volume, path, chunkOffset, blocks[i], verifiers[i])
+				if err != nil {
+					// LOG FIXME: add a conditional log
+					// for read failures, once per-disk
+					// per-function-invocation.
+					blocks[i] = blocks[i][:0] // mark shard as missing
+					unavailable[i] = true
+					done = false
+				}
+			}
+			if !done {
+				readSet = s.erasure.HealReadSet(stale, unavailable)
 			}
 		}
 
@@ -123,11 +130,10 @@ func (s ErasureStorage) HealFile(staleDisks []StorageAPI, volume, path string, b
 		// iteration
 		chunkOffset += chunksize
 
-		// reconstruct data - this computes all data and
-		// parity shards - but we skip this step if we are
-		// reconstructing an empty file.
+		// reconstruct the stale shards - but we skip this
+		// step if we are reconstructing an empty file.
 		if chunksize > 0 {
-			if err = s.ErasureDecodeDataAndParityBlocks(blocks); err != nil {
+			if err = s.erasure.HealBlocks(blocks, stale); err != nil {
 				return f, err
 			}
 		}
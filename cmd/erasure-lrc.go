@@ -0,0 +1,169 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/klauspost/reedsolomon"
+	"github.com/minio/minio/pkg/errors"
+)
+
+// lrcErasure - locally repairable code. Data blocks are split into
+// local groups, each protected by a local parity block which is the
+// XOR of the data blocks of its group. The remaining parity blocks
+// are global Cauchy Reed-Solomon parities over all data blocks.
+//
+// Blocks are laid out as
+//
+//	data blocks | one local parity per group | global parities
+//
+// A single lost block of a group is rebuilt by reading the other
+// blocks of its group only, while any parityBlocks - groups lost
+// blocks can be rebuilt by reading dataBlocks blocks.
+type lrcErasure struct {
+	*matrixErasure
+
+	// Indices of the data blocks of each local group.
+	groups [][]int
+}
+
+// lrcLocalGroups - returns the number of local groups of an LRC for
+// the given number of data and parity blocks, 0 if the LRC cannot be
+// used. At least one global parity block is kept on top of the local
+// parities and groups have at least two data blocks.
+func lrcLocalGroups(dataBlocks, parityBlocks int) int {
+	const groups = 2
+	if parityBlocks < groups+1 || dataBlocks < 2*groups {
+		return 0
+	}
+	return groups
+}
+
+// newLRCErasure - returns an erasureAlgorithmLRC erasure code.
+func newLRCErasure(dataBlocks, parityBlocks int) (ErasureCode, error) {
+	numGroups := lrcLocalGroups(dataBlocks, parityBlocks)
+	if numGroups == 0 || dataBlocks+parityBlocks > 256 {
+		return nil, errors.Tracef("failed to create erasure coding: %v", reedsolomon.ErrInvShardNum)
+	}
+
+	groups := make([][]int, numGroups)
+	for i := 0; i < dataBlocks; i++ {
+		g := i * numGroups / dataBlocks
+		groups[g] = append(groups[g], i)
+	}
+
+	parity := newGFMatrix(parityBlocks, dataBlocks)
+	for g, group := range groups {
+		for _, i := range group {
+			parity[g][i] = 1
+		}
+	}
+	for r := numGroups; r < parityBlocks; r++ {
+		for c := range parity[r] {
+			parity[r][c] = gfInv(byte((dataBlocks + r) ^ c))
+		}
+	}
+	return &lrcErasure{
+		matrixErasure: newMatrixErasure(dataBlocks, parityBlocks, parity),
+		groups:        groups,
+	}, nil
+}
+
+// localGroup - returns the indices of all blocks of the local group
+// of block i, including its local parity block, nil if block i is a
+// global parity block.
+func (e *lrcErasure) localGroup(i int) []int {
+	for g, group := range e.groups {
+		localParity := e.dataBlocks + g
+		if i == localParity || (i >= group[0] && i <= group[len(group)-1]) {
+			return append(append([]int{}, group...), localParity)
+		}
+	}
+	return nil
+}
+
+// localRepair - returns the blocks to read to rebuild all stale blocks
+// from their local groups, nil if some stale block cannot be rebuilt
+// locally. Blocks are rebuilt locally if a group has exactly one stale
+// block and all other blocks of the group are available.
+func (e *lrcErasure) localRepair(stale, unavailable []bool) []int {
+	var readSet []int
+	repaired := make([]bool, len(stale))
+	for i := range stale {
+		if !stale[i] || repaired[i] {
+			continue
+		}
+		group := e.localGroup(i)
+		if group == nil {
+			return nil
+		}
+		for _, j := range group {
+			if j == i {
+				continue
+			}
+			if unavailable[j] || stale[j] {
+				return nil
+			}
+			readSet = append(readSet, j)
+		}
+		repaired[i] = true
+	}
+	return readSet
+}
+
+// HealReadSet - prefers rebuilding stale blocks from their local
+// groups if that reads fewer than dataBlocks blocks.
+func (e *lrcErasure) HealReadSet(stale, unavailable []bool) []int {
+	if readSet := e.localRepair(stale, unavailable); readSet != nil && len(readSet) < e.dataBlocks {
+		return readSet
+	}
+	return e.matrixErasure.HealReadSet(stale, unavailable)
+}
+
+// HealBlocks - XORs the blocks of the local group of each stale block
+// if HealReadSet chose the local repair.
+func (e *lrcErasure) HealBlocks(blocks [][]byte, stale []bool) error {
+	missing := make([]bool, len(blocks))
+	for i := range blocks {
+		missing[i] = len(blocks[i]) == 0 && !stale[i]
+	}
+	if e.localRepair(stale, missing) == nil {
+		return e.DecodeDataAndParityBlocks(blocks)
+	}
+
+	size := 0
+	for i := range blocks {
+		if !stale[i] && len(blocks[i]) > 0 {
+			size = len(blocks[i])
+			break
+		}
+	}
+	for i := range stale {
+		if !stale[i] {
+			continue
+		}
+		blocks[i] = allocBlock(blocks[i], size)
+		for j := range blocks[i] {
+			blocks[i][j] = 0
+		}
+		for _, j := range e.localGroup(i) {
+			if j != i {
+				gfMulAdd(1, blocks[j], blocks[i])
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLRCHealReadSet(t *testing.T) {
+	// 8 data blocks in groups [0, 4) and [4, 8), local parities
+	// 8 and 9, global parities 10 and 11.
+	erasure, err := NewErasureCode(erasureAlgorithmLRC, 8, 4, blockSizeV1)
+	if err != nil {
+		t.Fatalf("Failed to create erasure code: %v", err)
+	}
+	testCases := []struct {
+		stale, unavailable []int
+		readSet            []int
+	}{
+		// Single lost data block is rebuilt from its group.
+		{[]int{1}, nil, []int{0, 2, 3, 8}},
+		// Lost local parity is rebuilt from its group.
+		{[]int{9}, nil, []int{4, 5, 6, 7}},
+		// One lost block in each group reads as many blocks as
+		// a global repair.
+		{[]int{0, 5}, nil, []int{1, 2, 3, 4, 6, 7, 8, 9}},
+		// Two lost blocks in a group need a global repair.
+		{[]int{0, 1}, nil, []int{2, 3, 4, 5, 6, 7, 8, 10}},
+		// Unavailable group member needs a global repair.
+		{[]int{0}, []int{2}, []int{1, 3, 4, 5, 6, 7, 8, 10}},
+		// Lost global parity needs a global repair.
+		{[]int{11}, nil, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+		// Too many lost blocks.
+		{[]int{0, 1, 4}, []int{10, 11}, nil},
+	}
+	for i, testCase := range testCases {
+		stale := make([]bool, 12)
+		unavailable := make([]bool, 12)
+		for _, j := range testCase.stale {
+			stale[j], unavailable[j] = true, true
+		}
+		for _, j := range testCase.unavailable {
+			unavailable[j] = true
+		}
+		readSet := erasure.HealReadSet(stale, unavailable)
+		if !reflect.DeepEqual(readSet, testCase.readSet) {
+			t.Errorf("Test %d: expected read set %v, got %v", i+1, testCase.readSet, readSet)
+		}
+	}
+}
+
+// countingDisk - counts calls to ReadFile.
+type countingDisk struct {
+	StorageAPI
+	reads *int32
+}
+
+func (d countingDisk) ReadFile(volume string, path string, offset int64, buffer []byte, verifier *BitrotVerifier) (int64, error) {
+	atomic.AddInt32(d.reads, 1)
+	return d.StorageAPI.ReadFile(volume, path, offset, buffer, verifier)
+}
+
+// Tests that healing a single disk of an LRC object reads from the
+// disks of its local group only.
+func TestLRCHealFile(t *testing.T) {
+	const dataBlocks, parityBlocks = 8, 4
+	setup, err := newErasureTestSetup(dataBlocks, parityBlocks, blockSizeV1)
+	if err != nil {
+		t.Fatalf("Failed to setup XL environment: %v", err)
+	}
+	defer setup.Remove()
+
+	storage, err := NewErasureStorage(setup.disks, erasureAlgorithmLRC, dataBlocks, parityBlocks, blockSizeV1)
+	if err != nil {
+		t.Fatalf("Failed to create ErasureStorage: %v", err)
+	}
+	data := make([]byte, 3*blockSizeV1+100)
+	if _, err = io.ReadFull(rand.Reader, data); err != nil {
+		t.Fatalf("Failed to create random test data: %v", err)
+	}
+	buffer := make([]byte, blockSizeV1, 2*blockSizeV1)
+	writeQuorum := erasureWriteQuorum(erasureAlgorithmLRC, dataBlocks, parityBlocks)
+	file, err := storage.CreateFile(bytes.NewReader(data), "testbucket", "testobject", buffer, DefaultBitrotAlgorithm, writeQuorum)
+	if err != nil {
+		t.Fatalf("Failed to create test object: %v", err)
+	}
+
+	reads := make([]int32, len(setup.disks))
+	staleDisks := make([]StorageAPI, len(setup.disks))
+	for i := range storage.disks {
+		storage.disks[i] = countingDisk{setup.disks[i], &reads[i]}
+	}
+	staleDisks[5], storage.disks[5] = setup.disks[5], OfflineDisk
+
+	info, err := storage.HealFile(staleDisks, "testbucket", "testobject", blockSizeV1, "testbucket", "healedobject", int64(len(data)), DefaultBitrotAlgorithm, file.Checksums)
+	if err != nil {
+		t.Fatalf("Failed to heal object: %v", err)
+	}
+	if !bytes.Equal(info.Checksums[5], file.Checksums[5]) {
+		t.Errorf("Healed block has a different bitrot checksum")
+	}
+	for i := range reads {
+		local := i == 4 || i == 6 || i == 7 || i == 9
+		if local && reads[i] == 0 {
+			t.Errorf("Expected reads from disk %d of the local group", i)
+		}
+		if !local && reads[i] != 0 {
+			t.Errorf("Expected no reads from disk %d outside of the local group, got %d", i, reads[i])
+		}
+	}
+
+	// A data block is rebuilt from the global parities once the
+	// local parity of its group is lost too.
+	storage.disks[9] = OfflineDisk
+	storage.disks[5] = setup.disks[5]
+	file.Checksums[5] = info.Checksums[5]
+	if err = setup.disks[5].RenameFile("testbucket", "healedobject", "testbucket", "testobject"); err != nil {
+		t.Fatalf("Failed to rename healed object: %v", err)
+	}
+	storage.disks[4] = OfflineDisk
+	content := new(bytes.Buffer)
+	if _, err = storage.ReadFile(content, "testbucket", "testobject", 0, int64(len(data)), int64(len(data)), file.Checksums, DefaultBitrotAlgorithm, blockSizeV1); err != nil {
+		t.Fatalf("Failed to read healed object: %v", err)
+	}
+	if !bytes.Equal(content.Bytes(), data) {
+		t.Errorf("Healed object does not match the original data")
+	}
+}
+
+// Tests that the read quorum of LRC objects includes the local parity
+// blocks.
+func TestLRCObjectQuorum(t *testing.T) {
+	const dataBlocks, parityBlocks = 8, 4
+	xlMeta := newXLMetaV1("object", dataBlocks, parityBlocks)
+	xlMeta.Erasure.Algorithm = erasureAlgorithmLRC
+	xlMeta.Stat.ModTime = UTCNow()
+
+	testCases := []struct {
+		offline     int
+		readQuorum  int
+		writeQuorum int
+		err         error
+	}{
+		{0, 10, 11, nil},
+		{2, 10, 11, nil},
+		{3, 0, 0, errXLReadQuorum},
+	}
+	for i, testCase := range testCases {
+		parts := make([]xlMetaV1, dataBlocks+parityBlocks)
+		errs := make([]error, dataBlocks+parityBlocks)
+		for j := range parts {
+			if j < testCase.offline {
+				errs[j] = errDiskNotFound
+				continue
+			}
+			parts[j] = xlMeta
+		}
+		readQuorum, writeQuorum, err := objectQuorumFromMeta(xlObjects{}, parts, errs)
+		if err != testCase.err {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if readQuorum != testCase.readQuorum || writeQuorum != testCase.writeQuorum {
+			t.Errorf("Test %d: expected quorum %d/%d, got %d/%d", i+1, testCase.readQuorum, testCase.writeQuorum, readQuorum, writeQuorum)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/klauspost/reedsolomon"
+	"github.com/minio/minio/pkg/errors"
+)
+
+// Arithmetic in GF(2^8) using the same field as klauspost/reedsolomon,
+// such that blocks encoded by either implementation are identical.
+const gfPolynomial = 0x11d
+
+var gfExpTable, gfLogTable = gfTables()
+
+func gfTables() (exp [510]byte, log [256]byte) {
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i], exp[i+255] = byte(x), byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPolynomial
+		}
+	}
+	return exp, log
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExpTable[int(gfLogTable[a])+int(gfLogTable[b])]
+}
+
+func gfInv(a byte) byte {
+	return gfExpTable[255-int(gfLogTable[a])]
+}
+
+func gfExp(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+	return gfExpTable[(int(gfLogTable[a])*n)%255]
+}
+
+// gfMulAdd - computes out ^= c * in.
+func gfMulAdd(c byte, in, out []byte) {
+	switch c {
+	case 0:
+		return
+	case 1:
+		for i := range in {
+			out[i] ^= in[i]
+		}
+		return
+	}
+	var table [256]byte
+	for i := range table {
+		table[i] = gfMul(c, byte(i))
+	}
+	for i := range in {
+		out[i] ^= table[in[i]]
+	}
+}
+
+// gfMatrix - matrix over GF(2^8).
+type gfMatrix [][]byte
+
+func newGFMatrix(rows, cols int) gfMatrix {
+	m := make(gfMatrix, rows)
+	for r := range m {
+		m[r] = make([]byte, cols)
+	}
+	return m
+}
+
+func (m gfMatrix) multiply(right gfMatrix) gfMatrix {
+	result := newGFMatrix(len(m), len(right[0]))
+	for r := range result {
+		for c := range result[r] {
+			var v byte
+			for i := range right {
+				v ^= gfMul(m[r][i], right[i][c])
+			}
+			result[r][c] = v
+		}
+	}
+	return result
+}
+
+// invert - returns the inverse of a square matrix using Gauss-Jordan
+// elimination, errSingularMatrix if it has none.
+func (m gfMatrix) invert() (gfMatrix, error) {
+	size := len(m)
+	work := newGFMatrix(size, 2*size)
+	for r := range m {
+		copy(work[r], m[r])
+		work[r][size+r] = 1
+	}
+	for c := 0; c < size; c++ {
+		if work[c][c] == 0 {
+			for r := c + 1; r < size; r++ {
+				if work[r][c] != 0 {
+					work[c], work[r] = work[r], work[c]
+					break
+				}
+			}
+		}
+		if work[c][c] == 0 {
+			return nil, errors.Trace(errSingularMatrix)
+		}
+		if v := work[c][c]; v != 1 {
+			scale := gfInv(v)
+			for i := range work[c] {
+				work[c][i] = gfMul(work[c][i], scale)
+			}
+		}
+		for r := 0; r < size; r++ {
+			if v := work[r][c]; r != c && v != 0 {
+				for i := range work[r] {
+					work[r][i] ^= gfMul(v, work[c][i])
+				}
+			}
+		}
+	}
+	result := make(gfMatrix, size)
+	for r := range work {
+		result[r] = work[r][size:]
+	}
+	return result, nil
+}
+
+// matrixErasure - erasure code defined by an encoding matrix whose
+// first dataBlocks rows form the identity matrix. It is written in
+// plain Go and used where SIMD instructions are not wanted, and as
+// the base of codes which klauspost/reedsolomon does not implement.
+type matrixErasure struct {
+	dataBlocks, parityBlocks int
+	matrix                   gfMatrix
+}
+
+func newMatrixErasure(dataBlocks, parityBlocks int, parity gfMatrix) *matrixErasure {
+	matrix := newGFMatrix(dataBlocks, dataBlocks)
+	for i := range matrix {
+		matrix[i][i] = 1
+	}
+	return &matrixErasure{
+		dataBlocks:   dataBlocks,
+		parityBlocks: parityBlocks,
+		matrix:       append(matrix, parity...),
+	}
+}
+
+func checkErasureGeometry(dataBlocks, parityBlocks int) error {
+	if dataBlocks <= 0 || parityBlocks < 0 || dataBlocks+parityBlocks > 256 {
+		return errors.Tracef("failed to create erasure coding: %v", reedsolomon.ErrInvShardNum)
+	}
+	return nil
+}
+
+// newVandermondeErasure - returns the plain Go implementation of
+// erasureAlgorithmKlauspost.
+func newVandermondeErasure(dataBlocks, parityBlocks int) (ErasureCode, error) {
+	if err := checkErasureGeometry(dataBlocks, parityBlocks); err != nil {
+		return nil, err
+	}
+	vm := newGFMatrix(dataBlocks+parityBlocks, dataBlocks)
+	for r := range vm {
+		for c := range vm[r] {
+			vm[r][c] = gfExp(byte(r), c)
+		}
+	}
+	topInv, err := vm[:dataBlocks].invert()
+	if err != nil {
+		return nil, err
+	}
+	return newMatrixErasure(dataBlocks, parityBlocks, vm[dataBlocks:].multiply(topInv)), nil
+}
+
+// newCauchyErasure - returns the plain Go implementation of
+// erasureAlgorithmCauchy.
+func newCauchyErasure(dataBlocks, parityBlocks int) (ErasureCode, error) {
+	if err := checkErasureGeometry(dataBlocks, parityBlocks); err != nil {
+		return nil, err
+	}
+	parity := newGFMatrix(parityBlocks, dataBlocks)
+	for r := range parity {
+		for c := range parity[r] {
+			parity[r][c] = gfInv(byte((dataBlocks + r) ^ c))
+		}
+	}
+	return newMatrixErasure(dataBlocks, parityBlocks, parity), nil
+}
+
+func (e *matrixErasure) Encode(data []byte) ([][]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.Tracef("failed to split data: %v", reedsolomon.ErrShortData)
+	}
+	perBlock := (len(data) + e.dataBlocks - 1) / e.dataBlocks
+	buffer := make([]byte, len(e.matrix)*perBlock)
+	copy(buffer, data)
+
+	blocks := make([][]byte, len(e.matrix))
+	for i := range blocks {
+		blocks[i] = buffer[i*perBlock : (i+1)*perBlock]
+	}
+	for i := e.dataBlocks; i < len(blocks); i++ {
+		e.codeBlock(e.matrix[i], blocks[:e.dataBlocks], blocks[i])
+	}
+	return blocks, nil
+}
+
+func (e *matrixErasure) DecodeDataBlocks(blocks [][]byte) error {
+	if err := e.decode(blocks, true); err != nil {
+		return errors.Tracef("failed to reconstruct data: %v", err)
+	}
+	return nil
+}
+
+func (e *matrixErasure) DecodeDataAndParityBlocks(blocks [][]byte) error {
+	if err := e.decode(blocks, false); err != nil {
+		return errors.Tracef("failed to reconstruct data: %v", err)
+	}
+	return nil
+}
+
+func (e *matrixErasure) HealReadSet(stale, unavailable []bool) []int {
+	return e.independentBlocks(unavailable)
+}
+
+func (e *matrixErasure) HealBlocks(blocks [][]byte, stale []bool) error {
+	return e.DecodeDataAndParityBlocks(blocks)
+}
+
+// codeBlock - computes out as the linear combination of in given by
+// the coefficients in row.
+func (e *matrixErasure) codeBlock(row []byte, in [][]byte, out []byte) {
+	for i := range out {
+		out[i] = 0
+	}
+	for i, c := range row {
+		gfMulAdd(c, in[i], out)
+	}
+}
+
+// independentBlocks - returns the indices of the first dataBlocks
+// available blocks whose rows in the encoding matrix are linearly
+// independent, nil if there are not enough of them.
+func (e *matrixErasure) independentBlocks(unavailable []bool) []int {
+	var indices []int
+	// Rows selected so far in reduced form, pivots[i] is the
+	// column of the leading coefficient of basis[i].
+	var basis [][]byte
+	var pivots []int
+	for i := range e.matrix {
+		if len(indices) == e.dataBlocks {
+			break
+		}
+		if unavailable[i] {
+			continue
+		}
+		row := append([]byte{}, e.matrix[i]...)
+		for j, b := range basis {
+			if v := row[pivots[j]]; v != 0 {
+				for c := range row {
+					row[c] ^= gfMul(v, b[c])
+				}
+			}
+		}
+		pivot := -1
+		for c, v := range row {
+			if v != 0 {
+				pivot = c
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		scale := gfInv(row[pivot])
+		for c := range row {
+			row[c] = gfMul(row[c], scale)
+		}
+		// Keep the basis fully reduced.
+		for _, b := range basis {
+			if v := b[pivot]; v != 0 {
+				for c := range b {
+					b[c] ^= gfMul(v, row[c])
+				}
+			}
+		}
+		basis = append(basis, row)
+		pivots = append(pivots, pivot)
+		indices = append(indices, i)
+	}
+	if len(indices) < e.dataBlocks {
+		return nil
+	}
+	return indices
+}
+
+// decode - reconstructs missing data blocks and, unless dataOnly is
+// set, missing parity blocks. Buffers of missing blocks are reused if
+// they are large enough.
+func (e *matrixErasure) decode(blocks [][]byte, dataOnly bool) error {
+	if len(blocks) != len(e.matrix) {
+		return reedsolomon.ErrTooFewShards
+	}
+	size := 0
+	missing := make([]bool, len(blocks))
+	numMissing := 0
+	for i, block := range blocks {
+		if len(block) == 0 {
+			missing[i] = true
+			numMissing++
+			continue
+		}
+		if size == 0 {
+			size = len(block)
+		} else if len(block) != size {
+			return reedsolomon.ErrShardSize
+		}
+	}
+	if size == 0 {
+		return reedsolomon.ErrShardNoData
+	}
+	if numMissing == 0 {
+		return nil
+	}
+
+	dataMissing := false
+	for i := 0; i < e.dataBlocks; i++ {
+		dataMissing = dataMissing || missing[i]
+	}
+	if dataMissing {
+		indices := e.independentBlocks(missing)
+		if indices == nil {
+			return reedsolomon.ErrTooFewShards
+		}
+		sub := make(gfMatrix, len(indices))
+		in := make([][]byte, len(indices))
+		for i, index := range indices {
+			sub[i] = e.matrix[index]
+			in[i] = blocks[index]
+		}
+		decodeMatrix, err := sub.invert()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < e.dataBlocks; i++ {
+			if missing[i] {
+				blocks[i] = allocBlock(blocks[i], size)
+				e.codeBlock(decodeMatrix[i], in, blocks[i])
+			}
+		}
+	}
+	if dataOnly {
+		return nil
+	}
+	for i := e.dataBlocks; i < len(blocks); i++ {
+		if missing[i] {
+			blocks[i] = allocBlock(blocks[i], size)
+			e.codeBlock(e.matrix[i], blocks[:e.dataBlocks], blocks[i])
+		}
+	}
+	return nil
+}
+
+// allocBlock - returns a block of size bytes, reusing the buffer of
+// block if possible.
+func allocBlock(block []byte, size int) []byte {
+	if cap(block) >= size {
+		return block[:size]
+	}
+	return make([]byte, size)
+}
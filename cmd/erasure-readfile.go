@@ -36,21 +36,47 @@ func (s ErasureStorage) ReadFile(writer io.Writer, volume, path string, offset,
 		return f, errors.Trace(errBitrotHashAlgoInvalid)
 	}
 
+	lastBlock := totalLength / blocksize
+	startOffset := offset % blocksize
+	chunksize := getChunkSize(blocksize, s.dataBlocks)
+
+	// Shards are streamed up to the end of the last requested block.
+	var till int64
+	if length > 0 {
+		endBlock := (offset + length - 1) / blocksize
+		till = endBlock*chunksize + chunksize
+		if endBlock == lastBlock {
+			till = endBlock*chunksize + getChunkSize(totalLength%blocksize, s.dataBlocks)
+		}
+	}
+
 	f.Checksums = make([][]byte, len(s.disks))
 	verifiers := make([]*BitrotVerifier, len(s.disks))
+	readers := make([]*erasureShardReader, len(s.disks))
 	for i, disk := range s.disks {
 		if disk == OfflineDisk {
 			continue
 		}
 		verifiers[i] = NewBitrotVerifier(algorithm, checksums[i])
+		readers[i] = &erasureShardReader{
+			disk:     disk,
+			volume:   volume,
+			path:     path,
+			verifier: verifiers[i],
+			till:     till,
+		}
 	}
+	defer func() {
+		for _, r := range readers {
+			if r != nil {
+				r.Close()
+			}
+		}
+	}()
 	errChans := make([]chan error, len(s.disks))
 	for i := range errChans {
 		errChans[i] = make(chan error, 1)
 	}
-	lastBlock := totalLength / blocksize
-	startOffset := offset % blocksize
-	chunksize := getChunkSize(blocksize, s.dataBlocks)
 
 	blocks := make([][]byte, len(s.disks))
 	for i := range blocks {
@@ -66,7 +92,7 @@ func (s ErasureStorage) ReadFile(writer io.Writer, volume, path string, offset,
 				blocks[i] = blocks[i][:chunksize]
 			}
 		}
-		err = s.readConcurrent(volume, path, blockOffset, blocks, verifiers, errChans)
+		err = s.readConcurrent(readers, blockOffset, blocks, errChans)
 		if err != nil {
 			return f, errors.Trace(errXLReadQuorum)
 		}
@@ -106,27 +132,37 @@ func erasureCountMissingBlocks(blocks [][]byte, limit int) int {
 
 // readConcurrent reads all requested data concurrently from the disks into blocks. It returns an error if
 // too many disks failed while reading.
-func (s *ErasureStorage) readConcurrent(volume, path string, offset int64, blocks [][]byte, verifiers []*BitrotVerifier, errChans []chan error) (err error) {
+func (s *ErasureStorage) readConcurrent(readers []*erasureShardReader, offset int64, blocks [][]byte, errChans []chan error) (err error) {
 	errs := make([]error, len(s.disks))
 
-	erasureReadBlocksConcurrent(s.disks[:s.dataBlocks], volume, path, offset, blocks[:s.dataBlocks], verifiers[:s.dataBlocks], errs[:s.dataBlocks], errChans[:s.dataBlocks])
+	erasureReadBlocksConcurrent(s.disks[:s.dataBlocks], readers[:s.dataBlocks], offset, blocks[:s.dataBlocks], errs[:s.dataBlocks], errChans[:s.dataBlocks])
 	missingDataBlocks := erasureCountMissingBlocks(blocks, s.dataBlocks)
 	mustReconstruct := missingDataBlocks > 0
+	requiredReads := s.dataBlocks
 	if mustReconstruct {
-		requiredReads := s.dataBlocks + missingDataBlocks
+		requiredReads += missingDataBlocks
 		if requiredReads > s.dataBlocks+s.parityBlocks {
 			return errXLReadQuorum
 		}
-		erasureReadBlocksConcurrent(s.disks[s.dataBlocks:requiredReads], volume, path, offset, blocks[s.dataBlocks:requiredReads], verifiers[s.dataBlocks:requiredReads], errs[s.dataBlocks:requiredReads], errChans[s.dataBlocks:requiredReads])
+		erasureReadBlocksConcurrent(s.disks[s.dataBlocks:requiredReads], readers[s.dataBlocks:requiredReads], offset, blocks[s.dataBlocks:requiredReads], errs[s.dataBlocks:requiredReads], errChans[s.dataBlocks:requiredReads])
 		if erasureCountMissingBlocks(blocks, requiredReads) > 0 {
-			erasureReadBlocksConcurrent(s.disks[requiredReads:], volume, path, offset, blocks[requiredReads:], verifiers[requiredReads:], errs[requiredReads:], errChans[requiredReads:])
+			erasureReadBlocksConcurrent(s.disks[requiredReads:], readers[requiredReads:], offset, blocks[requiredReads:], errs[requiredReads:], errChans[requiredReads:])
+			requiredReads = len(s.disks)
 		}
 	}
 	if err = reduceReadQuorumErrs(errs, []error{}, s.dataBlocks); err != nil {
 		return err
 	}
 	if mustReconstruct {
-		if err = s.ErasureDecodeDataBlocks(blocks); err != nil {
+		err = s.ErasureDecodeDataBlocks(blocks)
+		if err != nil && requiredReads < len(s.disks) {
+			// Not every set of dataBlocks blocks reconstructs the
+			// data for all erasure codes, e.g. local parities of an
+			// LRC, so read the remaining blocks and try again.
+			erasureReadBlocksConcurrent(s.disks[requiredReads:], readers[requiredReads:], offset, blocks[requiredReads:], errs[requiredReads:], errChans[requiredReads:])
+			err = s.ErasureDecodeDataBlocks(blocks)
+		}
+		if err != nil {
 			return err
 		}
 	}
@@ -134,10 +170,10 @@ func (s *ErasureStorage) readConcurrent(volume, path string, offset int64, block
 }
 
 // erasureReadBlocksConcurrent reads all data from each disk to each data block in parallel.
-// Therefore disks, blocks, verifiers errors and locks must have the same length.
-func erasureReadBlocksConcurrent(disks []StorageAPI, volume, path string, offset int64, blocks [][]byte, verifiers []*BitrotVerifier, errors []error, errChans []chan error) {
+// Therefore disks, readers, blocks, errors and locks must have the same length.
+func erasureReadBlocksConcurrent(disks []StorageAPI, readers []*erasureShardReader, offset int64, blocks [][]byte, errors []error, errChans []chan error) {
 	for i := range errChans {
-		go erasureReadFromFile(disks[i], volume, path, offset, blocks[i], verifiers[i], errChans[i])
+		go erasureReadFromFile(disks[i], readers[i], offset, blocks[i], errChans[i])
 	}
 	for i := range errChans {
 		errors[i] = <-errChans[i] // blocks until the go routine 'i' is done - no data race
@@ -150,11 +186,58 @@ func erasureReadBlocksConcurrent(disks []StorageAPI, volume, path string, offset
 
 // erasureReadFromFile reads data from the disk to buffer in parallel.
 // It sends the returned error through the error channel.
-func erasureReadFromFile(disk StorageAPI, volume, path string, offset int64, buffer []byte, verifier *BitrotVerifier, errChan chan<- error) {
+func erasureReadFromFile(disk StorageAPI, reader *erasureShardReader, offset int64, buffer []byte, errChan chan<- error) {
 	if disk == OfflineDisk {
 		errChan <- errors.Trace(errDiskNotFound)
 		return
 	}
-	_, err := disk.ReadFile(volume, path, offset, buffer, verifier)
-	errChan <- err
+	errChan <- reader.ReadAt(buffer, offset)
+}
+
+// erasureShardReader reads the blocks of a shard from a disk over a
+// single stream. The disk verifies the whole shard against bitrot while
+// streaming, a mismatch is reported with the last block of the stream.
+type erasureShardReader struct {
+	disk         StorageAPI
+	volume, path string
+	verifier     *BitrotVerifier
+	till         int64 // the stream ends at this offset
+
+	stream io.ReadCloser
+	offset int64 // offset of the next byte read from stream
+}
+
+// ReadAt fills buffer with the shard data at offset.
+func (r *erasureShardReader) ReadAt(buffer []byte, offset int64) error {
+	if r.stream == nil || r.offset != offset {
+		r.Close()
+		stream, err := r.disk.ReadFileStream(r.volume, r.path, offset, r.till-offset, r.verifier)
+		if err != nil {
+			return err
+		}
+		r.stream, r.offset = stream, offset
+	}
+	n, err := io.ReadFull(r.stream, buffer)
+	r.offset += int64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err == nil && r.offset == r.till {
+		// Read the end of the stream for the result of the bitrot
+		// verification, before the last block is used.
+		if _, err = io.ReadFull(r.stream, make([]byte, 1)); err == io.EOF {
+			err = nil
+		} else if err == nil {
+			err = errUnexpected
+		}
+	}
+	return err
+}
+
+// Close closes the open stream, if any.
+func (r *erasureShardReader) Close() {
+	if r.stream != nil {
+		r.stream.Close()
+		r.stream = nil
+	}
 }
@@ -18,10 +18,9 @@ package cmd
 
 import (
 	"crypto/subtle"
+	"encoding/hex"
 	"hash"
-
-	"github.com/klauspost/reedsolomon"
-	"github.com/minio/minio/pkg/errors"
+	"io"
 )
 
 // OfflineDisk represents an unavailable disk.
@@ -38,17 +37,16 @@ type ErasureFileInfo struct {
 // The disks contain erasure coded and bitrot-protected data.
 type ErasureStorage struct {
 	disks                    []StorageAPI
-	erasure                  reedsolomon.Encoder
+	erasure                  ErasureCode
 	dataBlocks, parityBlocks int
 }
 
 // NewErasureStorage creates a new ErasureStorage. The storage erasure codes and protects all data written to
-// the disks.
-func NewErasureStorage(disks []StorageAPI, dataBlocks, parityBlocks int, blockSize int64) (s ErasureStorage, err error) {
-	shardsize := (int(blockSize) + dataBlocks - 1) / dataBlocks
-	erasure, err := reedsolomon.New(dataBlocks, parityBlocks, reedsolomon.WithAutoGoroutines(shardsize))
+// the disks using the given erasure coding algorithm.
+func NewErasureStorage(disks []StorageAPI, algorithm string, dataBlocks, parityBlocks int, blockSize int64) (s ErasureStorage, err error) {
+	erasure, err := NewErasureCode(algorithm, dataBlocks, parityBlocks, blockSize)
 	if err != nil {
-		return s, errors.Tracef("failed to create erasure coding: %v", err)
+		return s, err
 	}
 	s = ErasureStorage{
 		disks:        make([]StorageAPI, len(disks)),
@@ -63,33 +61,20 @@ func NewErasureStorage(disks []StorageAPI, dataBlocks, parityBlocks int, blockSi
 // ErasureEncode encodes the given data and returns the erasure-coded data.
 // It returns an error if the erasure coding failed.
 func (s *ErasureStorage) ErasureEncode(data []byte) ([][]byte, error) {
-	encoded, err := s.erasure.Split(data)
-	if err != nil {
-		return nil, errors.Tracef("failed to split data: %v", err)
-	}
-	if err = s.erasure.Encode(encoded); err != nil {
-		return nil, errors.Tracef("failed to encode data: %v", err)
-	}
-	return encoded, nil
+	return s.erasure.Encode(data)
 }
 
 // ErasureDecodeDataBlocks decodes the given erasure-coded data.
 // It only decodes the data blocks but does not verify them.
 // It returns an error if the decoding failed.
 func (s *ErasureStorage) ErasureDecodeDataBlocks(data [][]byte) error {
-	if err := s.erasure.ReconstructData(data); err != nil {
-		return errors.Tracef("failed to reconstruct data: %v", err)
-	}
-	return nil
+	return s.erasure.DecodeDataBlocks(data)
 }
 
 // ErasureDecodeDataAndParityBlocks decodes the given erasure-coded data and verifies it.
 // It returns an error if the decoding failed.
 func (s *ErasureStorage) ErasureDecodeDataAndParityBlocks(data [][]byte) error {
-	if err := s.erasure.Reconstruct(data); err != nil {
-		return errors.Tracef("failed to reconstruct data: %v", err)
-	}
-	return nil
+	return s.erasure.DecodeDataAndParityBlocks(data)
 }
 
 // NewBitrotVerifier returns a new BitrotVerifier implementing the given algorithm.
@@ -115,3 +100,77 @@ func (v *BitrotVerifier) Verify() bool {
 
 // IsVerified returns true iff Verify was called at least once.
 func (v *BitrotVerifier) IsVerified() bool { return v.verified }
+
+// bitrotVerifyingReader streams a byte range of a file and verifies the
+// whole file against bitrot on the way. The bytes before the range are
+// hashed on the first read, the bytes after the range once the range
+// was read. The stream ends with a hashMismatchError instead of io.EOF
+// if the file is corrupted.
+type bitrotVerifyingReader struct {
+	file      io.Reader // positioned at the start of the file
+	verifier  *BitrotVerifier
+	offset    int64
+	remaining int64 // bytes left in the range, negative up to EOF
+
+	started bool
+	err     error // sticky error, io.EOF at the end
+}
+
+// newBitrotVerifyingReader - returns a reader of length bytes of file
+// starting at offset, which verifies the whole file using verifier.
+func newBitrotVerifyingReader(file io.Reader, offset, length int64, verifier *BitrotVerifier) *bitrotVerifyingReader {
+	return &bitrotVerifyingReader{
+		file:      file,
+		verifier:  verifier,
+		offset:    offset,
+		remaining: length,
+	}
+}
+
+func (r *bitrotVerifyingReader) Read(p []byte) (n int, err error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if !r.started {
+		r.started = true
+		// The verifier may have seen part of an earlier stream.
+		r.verifier.Reset()
+		if _, err = io.CopyN(r.verifier, r.file, r.offset); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			r.err = err
+			return 0, err
+		}
+	}
+
+	if r.remaining != 0 {
+		if r.remaining > 0 && int64(len(p)) > r.remaining {
+			p = p[:r.remaining]
+		}
+		n, err = r.file.Read(p)
+		r.verifier.Write(p[:n])
+		if r.remaining > 0 {
+			r.remaining -= int64(n)
+		}
+		if err != io.EOF {
+			if err != nil {
+				r.err = err
+			}
+			return n, err
+		}
+		// Like io.LimitReader a range beyond the end of the file
+		// ends early.
+		r.remaining = 0
+	}
+
+	if _, err = io.Copy(r.verifier, r.file); err != nil {
+		r.err = err
+		return n, err
+	}
+	r.err = io.EOF
+	if !r.verifier.Verify() {
+		r.err = hashMismatchError{hex.EncodeToString(r.verifier.sum), hex.EncodeToString(r.verifier.Sum(nil))}
+	}
+	return n, r.err
+}
@@ -181,6 +181,45 @@ func newNotificationEvent(event eventData) NotificationEvent {
 	return nEvent
 }
 
+// Reloads all external targets from the server config. The map of
+// targets is updated in place as it is shared by all copies of the
+// notifier.
+func (en eventNotifier) ReloadExternalTargets() error {
+	queueTargets, err := loadAllQueueTargets()
+	if err != nil {
+		return err
+	}
+	en.external.rwMutex.Lock()
+	defer en.external.rwMutex.Unlock()
+	for queueARN, target := range en.external.targets {
+		closeExternalTarget(target)
+		delete(en.external.targets, queueARN)
+	}
+	for queueARN, target := range queueTargets {
+		en.external.targets[queueARN] = target
+	}
+	return nil
+}
+
+// closeExternalTarget - closes the connections held by the hooks of a
+// replaced external target.
+func closeExternalTarget(target *logrus.Logger) {
+	for level, hooks := range target.Hooks {
+		for _, hook := range hooks {
+			// A hook is added for each of its levels, close it once.
+			if hook.Levels()[0] != level {
+				continue
+			}
+			switch c := hook.(type) {
+			case interface{ Close() }:
+				c.Close()
+			case interface{ Close() error }:
+				_ = c.Close()
+			}
+		}
+	}
+}
+
 // Fetch all external targets. This returns a copy of the current map of
 // external notification targets.
 func (en eventNotifier) GetAllExternalTargets() map[string]*logrus.Logger {
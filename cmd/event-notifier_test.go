@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/minio/minio/pkg/errors"
 )
 
@@ -583,3 +584,27 @@ func TestAddRemoveBucketListenerConfig(t *testing.T) {
 			lcSlice)
 	}
 }
+
+// closingHook - hook counting how often it was closed.
+type closingHook struct {
+	closed *int
+}
+
+func (h closingHook) Fire(entry *logrus.Entry) error { return nil }
+
+func (h closingHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.InfoLevel, logrus.WarnLevel}
+}
+
+func (h closingHook) Close() { *h.closed++ }
+
+// Tests that the hooks of a replaced external target are closed once.
+func TestCloseExternalTarget(t *testing.T) {
+	var closed int
+	target := logrus.New()
+	target.Hooks.Add(closingHook{&closed})
+	closeExternalTarget(target)
+	if closed != 1 {
+		t.Fatalf("Expected hook to be closed once, closed %d times", closed)
+	}
+}
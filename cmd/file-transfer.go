@@ -0,0 +1,691 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/auth"
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+	"golang.org/x/crypto/ssh"
+)
+
+// Errors returned to SFTP and FTP clients, object layer errors are
+// translated by toFileTransferError.
+var (
+	errFileTransferNotDir      = fmt.Errorf("Not a directory")
+	errFileTransferIsDir       = fmt.Errorf("Is a directory")
+	errFileTransferDirNotEmpty = fmt.Errorf("Directory not empty")
+	errFileTransferUnsupported = fmt.Errorf("Operation not supported")
+)
+
+// toFileTransferError - translates object layer errors to the errors
+// of the os package which SFTP and FTP report to clients.
+func toFileTransferError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch errors.Cause(err).(type) {
+	case BucketNotFound, ObjectNotFound:
+		return os.ErrNotExist
+	case BucketExists, BucketAlreadyExists, BucketAlreadyOwnedByYou:
+		return os.ErrExist
+	case BucketNotEmpty:
+		return errFileTransferDirNotEmpty
+	case BucketNameInvalid, ObjectNameInvalid, PrefixAccessDenied:
+		return os.ErrPermission
+	}
+	return errors.Cause(err)
+}
+
+// authenticateFileTransfer - verifies the credentials of SFTP and FTP
+// logins, returns the session token of temporary credentials. The
+// password is the secret key of the server credentials, or the secret
+// key and session token of temporary credentials separated by a colon.
+func authenticateFileTransfer(accessKey, password string) (sessionToken string, err error) {
+	secretKey := password
+	if i := strings.IndexByte(password, ':'); i >= 0 {
+		secretKey, sessionToken = password[:i], password[i+1:]
+	}
+	if sessionToken == "" {
+		cred, err := auth.CreateCredentials(accessKey, secretKey)
+		if err != nil || !globalServerConfig.GetCredential().Equal(cred) {
+			return "", errAuthentication
+		}
+		return "", nil
+	}
+
+	claims, errCode := parseSessionToken(sessionToken)
+	if errCode != ErrNone || claims.Subject != accessKey {
+		return "", errAuthentication
+	}
+	if subtle.ConstantTimeCompare([]byte(secretKey), []byte(stsSecretKey(sessionToken))) != 1 {
+		return "", errAuthentication
+	}
+	return sessionToken, nil
+}
+
+// fileTransferInfo - os.FileInfo of buckets, objects and common
+// prefixes.
+type fileTransferInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+func (fi fileTransferInfo) Name() string       { return fi.name }
+func (fi fileTransferInfo) Size() int64        { return fi.size }
+func (fi fileTransferInfo) ModTime() time.Time { return fi.modTime }
+func (fi fileTransferInfo) IsDir() bool        { return fi.isDir }
+func (fi fileTransferInfo) Sys() interface{}   { return nil }
+
+func (fi fileTransferInfo) Mode() os.FileMode {
+	if fi.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+// fileTransferListLine - returns the `ls -l` line of a file, as listed
+// by SFTP and FTP.
+func fileTransferListLine(fi os.FileInfo) string {
+	timeFormat := "Jan _2 15:04"
+	if fi.ModTime().Before(UTCNow().AddDate(0, -6, 0)) {
+		timeFormat = "Jan _2  2006"
+	}
+	return fmt.Sprintf("%s 1 minio minio %12d %s %s", fi.Mode(), fi.Size(),
+		fi.ModTime().Format(timeFormat), fi.Name())
+}
+
+// splitFileTransferPath - splits an absolute path of a session into
+// bucket and object.
+func splitFileTransferPath(p string) (bucket, object string) {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if i := strings.Index(p, "/"); i >= 0 {
+		return p[:i], p[i+1:]
+	}
+	return p, ""
+}
+
+// fileTransferSession - object layer operations of an authenticated
+// SFTP or FTP session. The first component of paths is the bucket,
+// directories are the common prefixes of objects.
+type fileTransferSession struct {
+	accessKey string
+	// Session token of temporary credentials, whose policies are
+	// enforced on all operations.
+	sessionToken string
+	remoteAddr   string
+	// Protocol of the session, reported as user agent of events.
+	protocol string
+}
+
+func (s *fileTransferSession) objectAPI() (ObjectLayer, error) {
+	objAPI := newObjectLayerFn()
+	if objAPI == nil {
+		return nil, errServerNotInitialized
+	}
+	return objAPI, nil
+}
+
+// checkPermission - checks the POSIX permissions of the access key of
+// the session in FS mode.
+func (s *fileTransferSession) checkPermission(objAPI ObjectLayer, bucket, object string, write bool) error {
+	if checkPOSIXPermission(objAPI, s.accessKey, bucket, object, write) != ErrNone {
+		return os.ErrPermission
+	}
+	return nil
+}
+
+// authorize - checks that the policies of temporary credentials grant
+// action on a bucket or object, sessions of the server credentials are
+// allowed all actions.
+func (s *fileTransferSession) authorize(action, bucket, object string) error {
+	if s.sessionToken == "" {
+		return nil
+	}
+	if enforceSessionTokenPolicy(s.sessionToken, action, pathJoin(slashSeparator, bucket, object)) != ErrNone {
+		return os.ErrPermission
+	}
+	return nil
+}
+
+// notify - notifies events like requests of the S3 API.
+func (s *fileTransferSession) notify(eventType EventName, bucket string, objInfo ObjectInfo) {
+	host, port, err := net.SplitHostPort(s.remoteAddr)
+	if err != nil {
+		host, port = "", ""
+	}
+	eventNotify(eventData{
+		Type:      eventType,
+		Bucket:    bucket,
+		ObjInfo:   objInfo,
+		ReqParams: map[string]string{"sourceIPAddress": s.remoteAddr},
+		UserAgent: s.protocol,
+		Host:      host,
+		Port:      port,
+	})
+}
+
+// stat - returns the info of a file or directory.
+func (s *fileTransferSession) stat(p string) (os.FileInfo, error) {
+	bucket, object := splitFileTransferPath(p)
+	if bucket == "" {
+		return fileTransferInfo{name: "/", isDir: true}, nil
+	}
+	// Files are listed with their directory, or read.
+	if err := s.authorize("s3:ListBucket", bucket, ""); err != nil {
+		if object == "" || s.authorize("s3:GetObject", bucket, object) != nil {
+			return nil, err
+		}
+	}
+	objAPI, err := s.objectAPI()
+	if err != nil {
+		return nil, err
+	}
+	if object == "" {
+		bucketInfo, err := objAPI.GetBucketInfo(bucket)
+		if err != nil {
+			return nil, toFileTransferError(err)
+		}
+		return fileTransferInfo{name: bucket, modTime: bucketInfo.Created, isDir: true}, nil
+	}
+
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err == nil && !objInfo.IsDir {
+		return fileTransferInfo{name: path.Base(object), size: objInfo.Size, modTime: objInfo.ModTime}, nil
+	}
+	if err != nil && !isErrObjectNotFound(err) {
+		return nil, toFileTransferError(err)
+	}
+
+	// Directories are marked by an empty object, or are the common
+	// prefix of at least one object.
+	if dirInfo, err := objAPI.GetObjectInfo(bucket, object+slashSeparator); err == nil {
+		return fileTransferInfo{name: path.Base(object), modTime: dirInfo.ModTime, isDir: true}, nil
+	}
+	result, err := objAPI.ListObjects(bucket, object+slashSeparator, "", slashSeparator, 1)
+	if err != nil {
+		return nil, toFileTransferError(err)
+	}
+	if len(result.Objects) == 0 && len(result.Prefixes) == 0 {
+		return nil, os.ErrNotExist
+	}
+	return fileTransferInfo{name: path.Base(object), modTime: objInfo.ModTime, isDir: true}, nil
+}
+
+// readDir - lists the buckets, or the objects and common prefixes of
+// a directory.
+func (s *fileTransferSession) readDir(p string) ([]os.FileInfo, error) {
+	objAPI, err := s.objectAPI()
+	if err != nil {
+		return nil, err
+	}
+	bucket, object := splitFileTransferPath(p)
+	if bucket == "" {
+		buckets, err := objAPI.ListBuckets()
+		if err != nil {
+			return nil, toFileTransferError(err)
+		}
+		infos := make([]os.FileInfo, 0, len(buckets))
+		for _, bucketInfo := range buckets {
+			// Only the buckets which may be listed are shown.
+			if s.authorize("s3:ListBucket", bucketInfo.Name, "") != nil {
+				continue
+			}
+			infos = append(infos, fileTransferInfo{name: bucketInfo.Name, modTime: bucketInfo.Created, isDir: true})
+		}
+		return infos, nil
+	}
+	if err = s.authorize("s3:ListBucket", bucket, ""); err != nil {
+		return nil, err
+	}
+
+	var prefix string
+	if object != "" {
+		prefix = object + slashSeparator
+	}
+	var infos []os.FileInfo
+	marker := ""
+	for {
+		result, err := objAPI.ListObjects(bucket, prefix, marker, slashSeparator, maxObjectList)
+		if err != nil {
+			return nil, toFileTransferError(err)
+		}
+		for _, objInfo := range result.Objects {
+			// Skip the object which marks the directory.
+			if objInfo.Name == prefix {
+				continue
+			}
+			infos = append(infos, fileTransferInfo{
+				name:    strings.TrimSuffix(strings.TrimPrefix(objInfo.Name, prefix), slashSeparator),
+				size:    objInfo.Size,
+				modTime: objInfo.ModTime,
+				isDir:   objInfo.IsDir,
+			})
+		}
+		for _, commonPrefix := range result.Prefixes {
+			infos = append(infos, fileTransferInfo{
+				name:  strings.TrimSuffix(strings.TrimPrefix(commonPrefix, prefix), slashSeparator),
+				isDir: true,
+			})
+		}
+		if !result.IsTruncated {
+			return infos, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// open - opens a file for reading from offset.
+func (s *fileTransferSession) open(p string, offset int64) (io.ReadCloser, error) {
+	objAPI, err := s.objectAPI()
+	if err != nil {
+		return nil, err
+	}
+	bucket, object := splitFileTransferPath(p)
+	if object == "" {
+		return nil, errFileTransferIsDir
+	}
+	if err = s.authorize("s3:GetObject", bucket, object); err != nil {
+		return nil, err
+	}
+	if err = s.checkPermission(objAPI, bucket, object, false); err != nil {
+		return nil, err
+	}
+	objInfo, err := objAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		return nil, toFileTransferError(err)
+	}
+	if offset < 0 || offset > objInfo.Size {
+		return nil, toFileTransferError(errors.Trace(InvalidRange{offset, objInfo.Size, objInfo.Size}))
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(objAPI.GetObject(bucket, object, offset, objInfo.Size-offset, pw, objInfo.ETag))
+	}()
+	s.notify(ObjectAccessedGet, bucket, objInfo)
+	return pr, nil
+}
+
+// fileTransferWriter - uploads the data written to it, the upload is
+// completed by Close.
+type fileTransferWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func (w *fileTransferWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Close - completes the upload, returns its error.
+func (w *fileTransferWriter) Close() error {
+	w.pw.Close()
+	return <-w.done
+}
+
+// Abort - cancels the upload.
+func (w *fileTransferWriter) Abort() {
+	w.pw.CloseWithError(errFileTransferUnsupported)
+	<-w.done
+}
+
+// create - returns a writer uploading a file, replacing any existing
+// file once closed.
+func (s *fileTransferSession) create(p string) (*fileTransferWriter, error) {
+	objAPI, err := s.objectAPI()
+	if err != nil {
+		return nil, err
+	}
+	bucket, object := splitFileTransferPath(p)
+	if object == "" {
+		return nil, os.ErrPermission
+	}
+	if err = s.authorize("s3:PutObject", bucket, object); err != nil {
+		return nil, err
+	}
+	if err = s.checkPermission(objAPI, bucket, object, true); err != nil {
+		return nil, err
+	}
+	if _, err = objAPI.GetBucketInfo(bucket); err != nil {
+		return nil, toFileTransferError(err)
+	}
+
+	pr, pw := io.Pipe()
+	w := &fileTransferWriter{pw: pw, done: make(chan error, 1)}
+	go func() {
+		metadata := make(map[string]string)
+		setPOSIXOwner(objAPI, s.accessKey, metadata)
+		objInfo, eventType, err := uploadFileTransfer(objAPI, bucket, object, pr, metadata)
+		pr.CloseWithError(err)
+		if err == nil {
+			s.notify(eventType, bucket, objInfo)
+		}
+		w.done <- toFileTransferError(err)
+	}()
+	return w, nil
+}
+
+// uploadFileTransfer - uploads data of unknown size, data larger than
+// one part is uploaded by multipart upload. Object layers need the size
+// of the data they store, data is thus spooled one part at a time to a
+// temporary file instead of memory.
+func uploadFileTransfer(objAPI ObjectLayer, bucket, object string, data io.Reader, metadata map[string]string) (objInfo ObjectInfo, eventType EventName, err error) {
+	spool, err := ioutil.TempFile("", "minio-file-transfer-")
+	if err != nil {
+		return objInfo, eventType, errors.Trace(err)
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	// nextPart - spools the next part of data, the part is smaller
+	// than globalPutPartSize only at the end of data.
+	nextPart := func() (*hash.Reader, int64, error) {
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return nil, 0, errors.Trace(err)
+		}
+		if err := spool.Truncate(0); err != nil {
+			return nil, 0, errors.Trace(err)
+		}
+		n, err := io.CopyN(spool, data, globalPutPartSize)
+		if err != nil && err != io.EOF {
+			return nil, 0, errors.Trace(err)
+		}
+		if _, err = spool.Seek(0, io.SeekStart); err != nil {
+			return nil, 0, errors.Trace(err)
+		}
+		hashReader, err := hash.NewReader(spool, n, "", "")
+		return hashReader, n, err
+	}
+
+	hashReader, n, err := nextPart()
+	if err != nil {
+		return objInfo, eventType, err
+	}
+	if n < globalPutPartSize {
+		objInfo, err = objAPI.PutObject(bucket, object, hashReader, metadata)
+		return objInfo, ObjectCreatedPut, err
+	}
+
+	uploadID, err := objAPI.NewMultipartUpload(bucket, object, metadata)
+	if err != nil {
+		return objInfo, eventType, err
+	}
+	var parts []CompletePart
+	for partID := 1; n > 0; partID++ {
+		var partInfo PartInfo
+		if partInfo, err = objAPI.PutObjectPart(bucket, object, uploadID, partID, hashReader); err != nil {
+			break
+		}
+		parts = append(parts, CompletePart{PartNumber: partID, ETag: partInfo.ETag})
+		if hashReader, n, err = nextPart(); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		errorIf(objAPI.AbortMultipartUpload(bucket, object, uploadID), "Unable to abort upload %s of %s/%s", uploadID, bucket, object)
+		return objInfo, eventType, err
+	}
+	objInfo, err = objAPI.CompleteMultipartUpload(bucket, object, uploadID, parts)
+	return objInfo, ObjectCreatedCompleteMultipartUpload, err
+}
+
+// remove - deletes a file.
+func (s *fileTransferSession) remove(p string) error {
+	objAPI, err := s.objectAPI()
+	if err != nil {
+		return err
+	}
+	bucket, object := splitFileTransferPath(p)
+	if object == "" {
+		return os.ErrPermission
+	}
+	if err = s.authorize("s3:DeleteObject", bucket, object); err != nil {
+		return err
+	}
+	if err = s.checkPermission(objAPI, bucket, object, true); err != nil {
+		return err
+	}
+	if _, err = objAPI.GetObjectInfo(bucket, object); err != nil {
+		return toFileTransferError(err)
+	}
+	if err = objAPI.DeleteObject(bucket, object); err != nil {
+		return toFileTransferError(err)
+	}
+	s.notify(ObjectRemovedDelete, bucket, ObjectInfo{Name: object})
+	return nil
+}
+
+// rename - renames a file by copying it and deleting the original,
+// directories can't be renamed.
+func (s *fileTransferSession) rename(oldPath, newPath string) error {
+	objAPI, err := s.objectAPI()
+	if err != nil {
+		return err
+	}
+	srcBucket, srcObject := splitFileTransferPath(oldPath)
+	dstBucket, dstObject := splitFileTransferPath(newPath)
+	if srcObject == "" || dstObject == "" {
+		return errFileTransferUnsupported
+	}
+	if srcBucket == dstBucket && srcObject == dstObject {
+		return nil
+	}
+	for _, check := range []struct{ action, bucket, object string }{
+		{"s3:GetObject", srcBucket, srcObject},
+		{"s3:DeleteObject", srcBucket, srcObject},
+		{"s3:PutObject", dstBucket, dstObject},
+	} {
+		if err = s.authorize(check.action, check.bucket, check.object); err != nil {
+			return err
+		}
+	}
+	if err = s.checkPermission(objAPI, srcBucket, srcObject, true); err != nil {
+		return err
+	}
+	if err = s.checkPermission(objAPI, dstBucket, dstObject, true); err != nil {
+		return err
+	}
+
+	srcInfo, err := objAPI.GetObjectInfo(srcBucket, srcObject)
+	if err != nil {
+		if fi, serr := s.stat(oldPath); serr == nil && fi.IsDir() {
+			return errFileTransferUnsupported
+		}
+		return toFileTransferError(err)
+	}
+	metadata := make(map[string]string)
+	for k, v := range srcInfo.UserDefined {
+		metadata[k] = v
+	}
+	delete(metadata, posixOwnerKey)
+	setPOSIXOwner(objAPI, s.accessKey, metadata)
+
+	objInfo, err := objAPI.CopyObject(srcBucket, srcObject, dstBucket, dstObject, metadata, srcInfo.ETag)
+	if err != nil {
+		return toFileTransferError(err)
+	}
+	s.notify(ObjectCreatedCopy, dstBucket, objInfo)
+	if err = objAPI.DeleteObject(srcBucket, srcObject); err != nil {
+		return toFileTransferError(err)
+	}
+	s.notify(ObjectRemovedDelete, srcBucket, ObjectInfo{Name: srcObject})
+	return nil
+}
+
+// mkdir - creates a bucket, or an empty object marking a directory.
+func (s *fileTransferSession) mkdir(p string) error {
+	objAPI, err := s.objectAPI()
+	if err != nil {
+		return err
+	}
+	bucket, object := splitFileTransferPath(p)
+	if bucket == "" {
+		return os.ErrExist
+	}
+	if object == "" {
+		if err = s.authorize("s3:CreateBucket", bucket, ""); err != nil {
+			return err
+		}
+		return toFileTransferError(makeFederatedBucket(objAPI, bucket, globalServerConfig.GetRegion()))
+	}
+	if err = s.authorize("s3:PutObject", bucket, object+slashSeparator); err != nil {
+		return err
+	}
+	if _, err = s.stat(p); err == nil {
+		return os.ErrExist
+	}
+	if err = s.checkPermission(objAPI, bucket, object, true); err != nil {
+		return err
+	}
+
+	hashReader, err := hash.NewReader(bytes.NewReader(nil), 0, "", "")
+	if err != nil {
+		return err
+	}
+	metadata := make(map[string]string)
+	setPOSIXOwner(objAPI, s.accessKey, metadata)
+	_, err = objAPI.PutObject(bucket, object+slashSeparator, hashReader, metadata)
+	return toFileTransferError(err)
+}
+
+// rmdir - deletes an empty bucket or directory.
+func (s *fileTransferSession) rmdir(p string) error {
+	objAPI, err := s.objectAPI()
+	if err != nil {
+		return err
+	}
+	bucket, object := splitFileTransferPath(p)
+	if bucket == "" {
+		return os.ErrPermission
+	}
+	if object == "" {
+		if err = s.authorize("s3:DeleteBucket", bucket, ""); err != nil {
+			return err
+		}
+		return toFileTransferError(deleteFederatedBucket(objAPI, bucket))
+	}
+	if err = s.authorize("s3:DeleteObject", bucket, object+slashSeparator); err != nil {
+		return err
+	}
+	if err = s.checkPermission(objAPI, bucket, object, true); err != nil {
+		return err
+	}
+
+	prefix := object + slashSeparator
+	result, err := objAPI.ListObjects(bucket, prefix, "", slashSeparator, 2)
+	if err != nil {
+		return toFileTransferError(err)
+	}
+	for _, objInfo := range result.Objects {
+		if objInfo.Name != prefix {
+			return errFileTransferDirNotEmpty
+		}
+	}
+	if len(result.Prefixes) > 0 {
+		return errFileTransferDirNotEmpty
+	}
+	fi, err := s.stat(p)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return errFileTransferNotDir
+	}
+	return toFileTransferError(objAPI.DeleteObject(bucket, prefix))
+}
+
+// loadSSHHostKey - loads the host key of the SFTP server, a new key is
+// generated on first start.
+func loadSSHHostKey(keyFile string) (ssh.Signer, error) {
+	data, err := ioutil.ReadFile(keyFile)
+	if os.IsNotExist(err) {
+		var key *rsa.PrivateKey
+		if key, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			return nil, err
+		}
+		data = pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})
+		err = ioutil.WriteFile(keyFile, data, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(data)
+}
+
+// startFileTransferServers - starts the SFTP and FTP listeners set by
+// the --sftp and --ftp flags.
+func startFileTransferServers(ctx *cli.Context) {
+	flagValue := func(name string) string {
+		if ctx.IsSet(name) {
+			return ctx.String(name)
+		}
+		return ctx.GlobalString(name)
+	}
+
+	if addr := flagValue("sftp"); addr != "" {
+		fatalIf(CheckLocalServerAddr(addr), "Invalid SFTP address ‘%s’ in command line argument.", addr)
+		hostKey, err := loadSSHHostKey(getSSHHostKeyFile())
+		fatalIf(err, "Unable to load SSH host key %s", getSSHHostKeyFile())
+		l, err := net.Listen("tcp", addr)
+		fatalIf(err, "Unable to listen for SFTP on %s", addr)
+		go func() {
+			errorIf(newSFTPServer(hostKey).Serve(l), "SFTP server exited")
+		}()
+	}
+
+	if addr := flagValue("ftp"); addr != "" {
+		fatalIf(CheckLocalServerAddr(addr), "Invalid FTP address ‘%s’ in command line argument.", addr)
+		// Credentials are never sent in clear text.
+		if !globalIsSSL {
+			fatalIf(errInvalidArgument, "FTP requires TLS, please configure the certificates of the server.")
+		}
+		l, err := net.Listen("tcp", addr)
+		fatalIf(err, "Unable to listen for FTP on %s", addr)
+		tlsConfig := &tls.Config{
+			GetCertificate: globalTLSCerts.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		}
+		go func() {
+			errorIf(newFTPServer(tlsConfig).Serve(l), "FTP server exited")
+		}()
+	}
+}
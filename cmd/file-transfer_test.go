@@ -0,0 +1,354 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSplitFileTransferPath(t *testing.T) {
+	testCases := []struct {
+		path, bucket, object string
+	}{
+		{"/", "", ""},
+		{"", "", ""},
+		{"/bucket", "bucket", ""},
+		{"bucket/", "bucket", ""},
+		{"/bucket/dir/object", "bucket", "dir/object"},
+		{"/bucket/dir/../object", "bucket", "object"},
+		{"/../bucket//object/", "bucket", "object"},
+	}
+	for i, testCase := range testCases {
+		bucket, object := splitFileTransferPath(testCase.path)
+		if bucket != testCase.bucket || object != testCase.object {
+			t.Errorf("Test %d: expected %s, %s, got %s, %s", i+1, testCase.bucket, testCase.object, bucket, object)
+		}
+	}
+}
+
+func TestAuthenticateFileTransfer(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	globalServerConfig.LDAP = newTestLDAPConfig("localhost:389", "bucket")
+	cred := globalServerConfig.GetCredential()
+	stsCred, sessionToken, err := newSTSCredentials("alice", []string{"developers"}, UTCNow().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiredCred, expiredToken, err := newSTSCredentials("alice", []string{"developers"}, UTCNow().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		accessKey, password  string
+		expectedSessionToken string
+		expectedErr          error
+	}{
+		{cred.AccessKey, cred.SecretKey, "", nil},
+		{cred.AccessKey, "invalid-secret-key", "", errAuthentication},
+		{"", "", "", errAuthentication},
+		// Temporary credentials log in with the session token
+		// following the secret key.
+		{stsCred.AccessKey, stsCred.SecretKey + ":" + sessionToken, sessionToken, nil},
+		{stsCred.AccessKey, stsCred.SecretKey, "", errAuthentication},
+		{stsCred.AccessKey, cred.SecretKey + ":" + sessionToken, "", errAuthentication},
+		{cred.AccessKey, stsCred.SecretKey + ":" + sessionToken, "", errAuthentication},
+		{expiredCred.AccessKey, expiredCred.SecretKey + ":" + expiredToken, "", errAuthentication},
+	}
+	for i, testCase := range testCases {
+		sessionToken, err := authenticateFileTransfer(testCase.accessKey, testCase.password)
+		if err != testCase.expectedErr || sessionToken != testCase.expectedSessionToken {
+			t.Errorf("Test %d: expected %q, %v, got %q, %v", i+1, testCase.expectedSessionToken, testCase.expectedErr, sessionToken, err)
+		}
+	}
+}
+
+// Tests that the policies of temporary credentials are enforced on the
+// operations of their sessions.
+func TestFileTransferSessionPolicy(t *testing.T) {
+	obj, _, cleanup := prepareGatewayTest(t, 0)
+	defer cleanup()
+
+	globalServerConfig.LDAP = newTestLDAPConfig("localhost:389", "bucket")
+	stsCred, sessionToken, err := newSTSCredentials("alice", []string{"developers"}, UTCNow().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bucket := range []string{"bucket", "other"} {
+		if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Developers may read and write the objects of bucket only.
+	s := &fileTransferSession{accessKey: stsCred.AccessKey, sessionToken: sessionToken, remoteAddr: "127.0.0.1:1234", protocol: "test"}
+	w, err := s.create("/bucket/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := s.open("/bucket/file", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	if _, err = s.stat("/bucket/file"); err != nil {
+		t.Errorf("Expected readable file to be found, got %v", err)
+	}
+
+	for i, err := range []error{
+		func() error { _, err := s.create("/other/file"); return err }(),
+		func() error { _, err := s.open("/other/file", 0); return err }(),
+		func() error { _, err := s.readDir("/bucket"); return err }(),
+		func() error { _, err := s.stat("/bucket"); return err }(),
+		s.remove("/bucket/file"),
+		s.rename("/bucket/file", "/bucket/renamed"),
+		s.mkdir("/new"),
+		s.rmdir("/other"),
+	} {
+		if !os.IsPermission(err) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, os.ErrPermission, err)
+		}
+	}
+	infos, err := s.readDir("/")
+	if err != nil || len(infos) != 0 {
+		t.Errorf("Expected no listable buckets, got %v, %v", infos, err)
+	}
+
+	// Sessions end with LDAP authentication.
+	globalServerConfig.LDAP.Enable = false
+	if _, err = s.open("/bucket/file", 0); !os.IsPermission(err) {
+		t.Errorf("Expected %v, got %v", os.ErrPermission, err)
+	}
+}
+
+// Tests the file operations of sessions on buckets and objects.
+func TestFileTransferSession(t *testing.T) {
+	_, _, cleanup := prepareGatewayTest(t, 0)
+	defer cleanup()
+
+	s := &fileTransferSession{accessKey: "minio", remoteAddr: "127.0.0.1:1234", protocol: "test"}
+	put := func(p string, data []byte) {
+		w, err := s.create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.mkdir("/bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.mkdir("/bucket"); !os.IsExist(err) {
+		t.Fatalf("Expected bucket to exist, got %v", err)
+	}
+	if err := s.mkdir("/bucket/empty"); err != nil {
+		t.Fatal(err)
+	}
+	put("/bucket/file", []byte("hello world"))
+	put("/bucket/dir/file", nil)
+	if _, err := s.create("/missing/file"); !os.IsNotExist(err) {
+		t.Fatalf("Expected missing bucket, got %v", err)
+	}
+
+	statCases := []struct {
+		path  string
+		isDir bool
+		size  int64
+		err   error
+	}{
+		{"/", true, 0, nil},
+		{"/bucket", true, 0, nil},
+		{"/bucket/file", false, 11, nil},
+		{"/bucket/dir", true, 0, nil},
+		{"/bucket/empty", true, 0, nil},
+		{"/bucket/dir/file", false, 0, nil},
+		{"/bucket/missing", false, 0, os.ErrNotExist},
+		{"/missing", false, 0, os.ErrNotExist},
+	}
+	for i, testCase := range statCases {
+		fi, err := s.stat(testCase.path)
+		if err != testCase.err {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if err == nil && (fi.IsDir() != testCase.isDir || fi.Size() != testCase.size) {
+			t.Errorf("Test %d: unexpected info dir %v, size %d", i+1, fi.IsDir(), fi.Size())
+		}
+	}
+
+	infos, err := s.readDir("/bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, fi := range infos {
+		names = append(names, fi.Name())
+	}
+	if expected := []string{"file", "dir", "empty"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+	if infos, err = s.readDir("/bucket/empty"); err != nil || len(infos) != 0 {
+		t.Errorf("Expected empty directory, got %v, %v", infos, err)
+	}
+
+	r, err := s.open("/bucket/file", 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil || string(data) != "world" {
+		t.Errorf("Expected `world`, got `%s`, %v", data, err)
+	}
+	if _, err = s.open("/bucket/dir", 0); !os.IsNotExist(err) {
+		t.Errorf("Expected directories not to be opened, got %v", err)
+	}
+
+	if err = s.rename("/bucket/file", "/bucket/empty/renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.stat("/bucket/file"); !os.IsNotExist(err) {
+		t.Errorf("Expected renamed file to be removed, got %v", err)
+	}
+	if fi, serr := s.stat("/bucket/empty/renamed"); serr != nil || fi.Size() != 11 {
+		t.Errorf("Expected renamed file, got %v", serr)
+	}
+	if err = s.rename("/bucket/dir", "/bucket/other"); err != errFileTransferUnsupported {
+		t.Errorf("Expected directories not to be renamed, got %v", err)
+	}
+
+	if err = s.rmdir("/bucket/empty"); err != errFileTransferDirNotEmpty {
+		t.Errorf("Expected %v, got %v", errFileTransferDirNotEmpty, err)
+	}
+	for _, p := range []string{"/bucket/empty/renamed", "/bucket/dir/file"} {
+		if err = s.remove(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.remove("/bucket/dir/file"); !os.IsNotExist(err) {
+		t.Errorf("Expected removed file not to exist, got %v", err)
+	}
+	if err = s.mkdir("/bucket/new"); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.rmdir("/bucket/new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.stat("/bucket/new"); !os.IsNotExist(err) {
+		t.Errorf("Expected removed directory not to exist, got %v", err)
+	}
+	if err = s.rmdir("/bucket"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests uploads larger than one part.
+func TestUploadFileTransfer(t *testing.T) {
+	obj, _, cleanup := prepareGatewayTest(t, 0)
+	defer cleanup()
+
+	defer func(partSize int64) { globalPutPartSize = partSize }(globalPutPartSize)
+	globalPutPartSize = globalMinPartSize
+
+	if err := obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		size      int64
+		eventType EventName
+	}{
+		{0, ObjectCreatedPut},
+		{globalMinPartSize - 1, ObjectCreatedPut},
+		{globalMinPartSize, ObjectCreatedCompleteMultipartUpload},
+		{2*globalMinPartSize + 1, ObjectCreatedCompleteMultipartUpload},
+	}
+	for i, testCase := range testCases {
+		data := bytes.Repeat([]byte("a"), int(testCase.size))
+		objInfo, eventType, err := uploadFileTransfer(obj, "bucket", "object", bytes.NewReader(data), make(map[string]string))
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if objInfo.Size != testCase.size || eventType != testCase.eventType {
+			t.Errorf("Test %d: expected size %d and event %v, got %d and %v", i+1, testCase.size, testCase.eventType, objInfo.Size, eventType)
+		}
+	}
+
+	// Failed uploads leave no object behind.
+	r := io.MultiReader(bytes.NewReader(make([]byte, globalMinPartSize)), iotestErrReader{})
+	if _, _, err := uploadFileTransfer(obj, "bucket", "failed", r, make(map[string]string)); err == nil {
+		t.Fatal("Expected upload to fail")
+	}
+	if _, err := obj.GetObjectInfo("bucket", "failed"); !isErrObjectNotFound(err) {
+		t.Errorf("Expected failed upload not to be saved, got %v", err)
+	}
+	result, err := obj.ListMultipartUploads("bucket", "failed", "", "", "", 10)
+	if err != nil || len(result.Uploads) != 0 {
+		t.Errorf("Expected failed upload to be aborted, got %v, %v", result.Uploads, err)
+	}
+}
+
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read([]byte) (int, error) { return 0, errUnexpected }
+
+func TestLoadSSHHostKey(t *testing.T) {
+	dir, err := ioutil.TempDir(globalTestTmpDir, "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyFile := filepath.Join(dir, sshHostKeyFile)
+	signer, err := loadSSHHostKey(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The generated key is kept for later starts.
+	reloaded, err := loadSSHHostKey(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signer.PublicKey().Marshal(), reloaded.PublicKey().Marshal()) {
+		t.Error("Expected the same host key to be loaded")
+	}
+	if fi, err := os.Stat(keyFile); err != nil || (runtime.GOOS != "windows" && fi.Mode().Perm()&0077 != 0) {
+		t.Errorf("Expected private host key file, got %v", err)
+	}
+}
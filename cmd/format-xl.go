@@ -438,6 +438,8 @@ func healFormatXLFreshDisks(storageDisks []StorageAPI,
 	// We need to make sure we have kept the previous order
 	// and allowed fresh disks to be arranged anywhere.
 	// Following block facilitates to put fresh disks.
+	var freshDisks []StorageAPI
+	var freshDiskIDs []string
 	for index, format := range formats {
 		if format != nil {
 			continue
@@ -449,14 +451,24 @@ func healFormatXLFreshDisks(storageDisks []StorageAPI,
 		for oIndex, disk := range orderedDisks {
 			if disk == nil {
 				orderedDisks[oIndex] = storageDisks[index]
+				freshDisks = append(freshDisks, storageDisks[index])
+				freshDiskIDs = append(freshDiskIDs, referenceConfig.XL.JBOD[oIndex])
 				break
 			}
 		}
 	}
 
 	// apply new format config and save to all disks
-	return collectNSaveNewFormatConfigs(referenceConfig, orderedDisks,
+	err = collectNSaveNewFormatConfigs(referenceConfig, orderedDisks,
 		dryRun)
+	if err != nil || dryRun {
+		return err
+	}
+
+	// Mark fresh disks so that their data is healed in the
+	// background.
+	saveHealingTrackers(freshDisks, freshDiskIDs)
+	return nil
 }
 
 // collectUnAssignedDisks - collect disks unassigned to orderedDisks
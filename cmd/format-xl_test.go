@@ -282,6 +282,19 @@ func TestFormatXLHealFreshDisks(t *testing.T) {
 		t.Fatal("loading healed disk failed: ", err)
 	}
 
+	// Only the fresh disks are marked for background healing.
+	xl := obj.(*xlObjects)
+	for i, disk := range xl.storageDisks {
+		_, err = loadHealingTracker(disk)
+		if i >= 3 && i <= 5 {
+			if err != nil {
+				t.Fatalf("Disk %d: expected a healing tracker, got %s", i, err)
+			}
+		} else if err != errFileNotFound {
+			t.Fatalf("Disk %d: expected %s, got %v", i, errFileNotFound, err)
+		}
+	}
+
 	// Clean all
 	removeRoots(fsDirs)
 }
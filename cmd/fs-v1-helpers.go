@@ -123,6 +123,30 @@ func fsMkdir(dirPath string) (err error) {
 	return nil
 }
 
+// fsMkdirAll creates a directory along with its missing parents,
+// returns the directories created, parents first.
+func fsMkdirAll(dirPath string) ([]string, error) {
+	if dirPath == "" {
+		return nil, errors.Trace(errInvalidArgument)
+	}
+
+	var created []string
+	for dir := dirPath; ; dir = pathutil.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil || !os.IsNotExist(err) {
+			break
+		}
+		created = append([]string{dir}, created...)
+		if dir == pathutil.Dir(dir) {
+			break
+		}
+	}
+
+	if err := mkdirAll(dirPath, 0777); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return created, nil
+}
+
 // fsStat is a low level call which validates input arguments
 // and checks input length upto supported maximum. Does
 // not perform any higher layer interpretation of files v/s
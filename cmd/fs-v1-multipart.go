@@ -65,6 +65,12 @@ func (fs *fsObjects) decodePartFile(name string) (partNumber int, etag string, e
 
 // Appends parts to an appendFile sequentially.
 func (fs *fsObjects) backgroundAppend(bucket, object, uploadID string) {
+	// Parts of uploads to a shared backend may arrive at other
+	// servers, they are appended when the upload completes.
+	if fs.shared {
+		return
+	}
+
 	fs.appendFileMapMu.Lock()
 	file := fs.appendFileMap[uploadID]
 	if file == nil {
@@ -333,6 +339,23 @@ func (fs *fsObjects) PutObjectPart(bucket, object, uploadID string, partID int,
 	}
 	partPath := pathJoin(uploadIDDir, fs.encodePartFile(partID, etag))
 
+	// Hold read lock on the upload while adding the part, so that it
+	// is not completed or aborted meanwhile.
+	uploadIDLock := fs.nsMutex.NewNSLock(minioMetaMultipartBucket, pathJoin(bucket, object, uploadID))
+	if err = uploadIDLock.GetRLock(globalObjectTimeout); err != nil {
+		return pi, err
+	}
+	defer uploadIDLock.RUnlock()
+
+	// The upload may have completed or aborted while the part was
+	// written.
+	if _, err = fsStatFile(pathJoin(uploadIDDir, fsMetaJSONFile)); err != nil {
+		if errors.Cause(err) == errFileNotFound || errors.Cause(err) == errFileAccessDenied {
+			return pi, errors.Trace(InvalidUploadID{UploadID: uploadID})
+		}
+		return pi, toObjectErr(err, bucket, object)
+	}
+
 	if err = fsRenameFile(tmpPartPath, partPath); err != nil {
 		return pi, toObjectErr(err, minioMetaMultipartBucket, partPath)
 	}
@@ -474,6 +497,14 @@ func (fs *fsObjects) CompleteMultipartUpload(bucket string, object string, uploa
 		return oi, toObjectErr(err, bucket)
 	}
 
+	// Hold write lock on the upload, so that it is completed only
+	// once, even by several servers of a shared backend.
+	uploadIDLock := fs.nsMutex.NewNSLock(minioMetaMultipartBucket, pathJoin(bucket, object, uploadID))
+	if err := uploadIDLock.GetLock(globalObjectTimeout); err != nil {
+		return oi, err
+	}
+	defer uploadIDLock.Unlock()
+
 	uploadIDDir := fs.getUploadIDDir(bucket, object, uploadID)
 	// Just check if the uploadID exists to avoid copy if it doesn't.
 	_, err := fsStatFile(pathJoin(uploadIDDir, fsMetaJSONFile))
@@ -567,7 +598,9 @@ func (fs *fsObjects) CompleteMultipartUpload(bucket string, object string, uploa
 	}
 
 	if appendFallback {
-		fsRemoveFile(file.filePath)
+		if file != nil {
+			fsRemoveFile(file.filePath)
+		}
 		for _, part := range parts {
 			partPath := pathJoin(uploadIDDir, fs.encodePartFile(part.PartNumber, part.ETag))
 			err = mioutil.AppendFile(appendFilePath, partPath)
@@ -605,11 +638,18 @@ func (fs *fsObjects) CompleteMultipartUpload(bucket string, object string, uploa
 		fsMeta.Meta = make(map[string]string)
 	}
 	fsMeta.Meta["etag"] = s3MD5
+	// The POSIX owner is applied to the object, not saved with it.
+	owner := fsMeta.Meta[posixOwnerKey]
+	delete(fsMeta.Meta, posixOwnerKey)
 	if _, err = fsMeta.WriteTo(metaFile); err != nil {
 		return oi, toObjectErr(errors.Trace(err), bucket, object)
 	}
 
-	err = fsRenameFile(appendFilePath, pathJoin(fs.fsPath, bucket, object))
+	objectPath := pathJoin(fs.fsPath, bucket, object)
+	if err = setPOSIXOwnership(pathutil.Dir(objectPath), appendFilePath, owner); err != nil {
+		return oi, toObjectErr(errors.Trace(err), bucket, object)
+	}
+	err = fsRenameFile(appendFilePath, objectPath)
 	if err != nil {
 		return oi, toObjectErr(errors.Trace(err), bucket, object)
 	}
@@ -643,6 +683,12 @@ func (fs *fsObjects) AbortMultipartUpload(bucket, object, uploadID string) error
 		return toObjectErr(errors.Trace(err), bucket)
 	}
 
+	uploadIDLock := fs.nsMutex.NewNSLock(minioMetaMultipartBucket, pathJoin(bucket, object, uploadID))
+	if err := uploadIDLock.GetLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	defer uploadIDLock.Unlock()
+
 	fs.appendFileMapMu.Lock()
 	delete(fs.appendFileMap, uploadID)
 	fs.appendFileMapMu.Unlock()
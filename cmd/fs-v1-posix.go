@@ -0,0 +1,233 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	pathutil "path"
+	"strconv"
+)
+
+// Reserved metadata of the access key creating an object, consumed by
+// the fs object layer and never saved.
+const posixOwnerKey = ReservedMetadataPrefix + "Posix-Owner"
+
+// Permission bits checked by enforced POSIX permissions.
+const (
+	posixRead  os.FileMode = 04
+	posixWrite os.FileMode = 02
+	posixExec  os.FileMode = 01
+)
+
+// posixUser - POSIX owner of the objects created with an access key.
+type posixUser struct {
+	UID int `json:"uid"`
+	GID int `json:"gid"`
+}
+
+// posixConfig - POSIX ownership and permissions of objects in FS mode.
+type posixConfig struct {
+	// Octal umask of created files and directories, the umask of the
+	// server process applies if empty.
+	Umask string `json:"umask,omitempty"`
+
+	// Enforce permission bits on requests of mapped access keys.
+	Enforce bool `json:"enforce"`
+
+	// POSIX users by access key.
+	Users map[string]posixUser `json:"users,omitempty"`
+}
+
+// parseUmask - parses an octal umask.
+func parseUmask(s string) (os.FileMode, error) {
+	umask, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || umask > 0777 {
+		return 0, fmt.Errorf("posix: invalid umask ‘%s’", s)
+	}
+	return os.FileMode(umask), nil
+}
+
+// Validate - validates the POSIX configuration.
+func (c posixConfig) Validate() error {
+	if c.Umask != "" {
+		if _, err := parseUmask(c.Umask); err != nil {
+			return err
+		}
+	}
+	for accessKey, user := range c.Users {
+		if accessKey == "" {
+			return errors.New("posix: access key cannot be empty")
+		}
+		if user.UID < 0 || user.GID < 0 {
+			return fmt.Errorf("posix: negative uid or gid for access key ‘%s’", accessKey)
+		}
+	}
+	return nil
+}
+
+// getPOSIXConfig - returns the POSIX configuration of the server.
+func getPOSIXConfig() posixConfig {
+	globalServerConfigMu.RLock()
+	defer globalServerConfigMu.RUnlock()
+	if globalServerConfig == nil {
+		return posixConfig{}
+	}
+	return globalServerConfig.POSIX
+}
+
+// apply - applies the umask, and the owner of accessKey if mapped, to
+// the created file or directory at path.
+func (c posixConfig) apply(path string, isDir bool, accessKey string) error {
+	if c.Umask != "" {
+		umask, err := parseUmask(c.Umask)
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(0666)
+		if isDir {
+			mode = 0777
+		}
+		if err = os.Chmod(path, mode&^umask); err != nil {
+			return err
+		}
+	}
+	if user, ok := c.Users[accessKey]; ok {
+		return os.Chown(path, user.UID, user.GID)
+	}
+	return nil
+}
+
+// posixPermits - returns whether user is granted perm on fi by its
+// permission bits. Root, and platforms without POSIX owners, are
+// granted everything.
+func posixPermits(fi os.FileInfo, user posixUser, perm os.FileMode) bool {
+	uid, gid, ok := posixFileOwner(fi)
+	if !ok || user.UID == 0 {
+		return true
+	}
+	mode := fi.Mode().Perm()
+	switch {
+	case uid == user.UID:
+		mode >>= 6
+	case gid == user.GID:
+		mode >>= 3
+	}
+	return mode&perm == perm
+}
+
+// setPOSIXOwnership - creates the missing directories of dirPath and
+// applies the POSIX umask and owner of accessKey to them, and to the
+// file at filePath if set. Objects are renamed into place afterwards,
+// they never appear with the ownership of the server.
+func setPOSIXOwnership(dirPath, filePath, accessKey string) error {
+	c := getPOSIXConfig()
+	if _, ok := c.Users[accessKey]; !ok && c.Umask == "" {
+		return mkdirAll(dirPath, 0777)
+	}
+
+	created, err := fsMkdirAll(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, dir := range created {
+		if err = c.apply(dir, true, accessKey); err != nil {
+			return err
+		}
+	}
+	if filePath != "" {
+		return c.apply(filePath, false, accessKey)
+	}
+	return nil
+}
+
+// checkPOSIXAccess - verifies the permission bits of object, and of
+// the directories leading to it, grant user to read object, or to
+// write it if write is set.
+func (fs *fsObjects) checkPOSIXAccess(bucket, object string, user posixUser, write bool) error {
+	objectPath := pathJoin(fs.fsPath, bucket, object)
+	bucketDir := pathJoin(fs.fsPath, bucket)
+
+	// Existing directories leading to object must be searchable, the
+	// nearest one, where object or its directories are created, must
+	// be writable for writes.
+	var parent os.FileInfo
+	var dirs []string
+	for dir := pathutil.Dir(objectPath); len(dir) >= len(bucketDir); dir = pathutil.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	for _, dir := range dirs {
+		fi, err := os.Stat(dir)
+		if err != nil {
+			break
+		}
+		if !posixPermits(fi, user, posixExec) {
+			return errFileAccessDenied
+		}
+		parent = fi
+	}
+	if parent == nil {
+		// Missing buckets are reported by the request.
+		return nil
+	}
+	if write && !posixPermits(parent, user, posixWrite) {
+		return errFileAccessDenied
+	}
+
+	fi, err := os.Stat(objectPath)
+	if err != nil {
+		// Missing objects are reported by the request, or created.
+		return nil
+	}
+	perm := posixRead
+	if write {
+		perm = posixWrite
+	}
+	if !posixPermits(fi, user, perm) {
+		return errFileAccessDenied
+	}
+	return nil
+}
+
+// setPOSIXOwner - records the access key creating an object in its
+// metadata, if the object layer applies POSIX ownership.
+func setPOSIXOwner(objAPI ObjectLayer, accessKey string, metadata map[string]string) {
+	if _, ok := objAPI.(*fsObjects); !ok || accessKey == "" {
+		return
+	}
+	metadata[posixOwnerKey] = accessKey
+}
+
+// checkPOSIXPermission - verifies the POSIX permissions of an object
+// grant the user of accessKey to read it, or to write it if write is
+// set. Only enforced if configured, and for mapped access keys.
+func checkPOSIXPermission(objAPI ObjectLayer, accessKey, bucket, object string, write bool) APIErrorCode {
+	fs, ok := objAPI.(*fsObjects)
+	if !ok {
+		return ErrNone
+	}
+	c := getPOSIXConfig()
+	user, ok := c.Users[accessKey]
+	if !c.Enforce || !ok {
+		return ErrNone
+	}
+	if err := fs.checkPOSIXAccess(bucket, object, user, write); err != nil {
+		return ErrAccessDenied
+	}
+	return ErrNone
+}
@@ -0,0 +1,188 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestPOSIXConfigValidate(t *testing.T) {
+	testCases := []struct {
+		config    posixConfig
+		shouldErr bool
+	}{
+		{posixConfig{}, false},
+		{posixConfig{Umask: "022"}, false},
+		{posixConfig{Umask: "0777", Enforce: true}, false},
+		{posixConfig{Users: map[string]posixUser{"alice": {UID: 1001, GID: 100}}}, false},
+		{posixConfig{Umask: "0999"}, true},
+		{posixConfig{Umask: "1000"}, true},
+		{posixConfig{Umask: "u=rwx"}, true},
+		{posixConfig{Users: map[string]posixUser{"": {UID: 1001, GID: 100}}}, true},
+		{posixConfig{Users: map[string]posixUser{"alice": {UID: -1, GID: 100}}}, true},
+	}
+	for i, testCase := range testCases {
+		if err := testCase.config.Validate(); (err != nil) != testCase.shouldErr {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, testCase.shouldErr, err)
+		}
+	}
+}
+
+// Tests the umask and owner applied to objects created in FS mode.
+func TestFSPOSIXOwnership(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX permissions are not supported on Windows")
+	}
+
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	disk, err := ioutil.TempDir(globalTestTmpDir, "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(disk)
+
+	obj := initFSObjects(disk, t)
+	fs := obj.(*fsObjects)
+
+	// Map the access key to the owner of the test process, which
+	// chown() is always permitted to.
+	user := posixUser{UID: os.Getuid(), GID: os.Getgid()}
+	globalServerConfig.POSIX = posixConfig{
+		Umask: "027",
+		Users: map[string]posixUser{"alice": user},
+	}
+	defer func() { globalServerConfig.POSIX = posixConfig{} }()
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := make(map[string]string)
+	setPOSIXOwner(obj, "alice", metadata)
+	if _, err = obj.PutObject(bucket, "dir/object", mustGetHashReader(t, bytes.NewReader([]byte("abcd")), 4, "", ""), metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		path string
+		mode os.FileMode
+	}{
+		{pathJoin(disk, bucket, "dir"), 0750},
+		{pathJoin(disk, bucket, "dir", "object"), 0640},
+	}
+	for i, testCase := range testCases {
+		fi, err := os.Stat(testCase.path)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if fi.Mode().Perm() != testCase.mode {
+			t.Errorf("Test %d: expected mode %v, got %v", i+1, testCase.mode, fi.Mode().Perm())
+		}
+		if uid, gid, ok := posixFileOwner(fi); ok && (uid != user.UID || gid != user.GID) {
+			t.Errorf("Test %d: expected owner %d:%d, got %d:%d", i+1, user.UID, user.GID, uid, gid)
+		}
+	}
+
+	// The owner is not saved in the metadata of the object.
+	objInfo, err := fs.GetObjectInfo(bucket, "dir/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objInfo.UserDefined[posixOwnerKey]; ok {
+		t.Errorf("Expected %s not to be saved", posixOwnerKey)
+	}
+}
+
+// Tests the permission bits enforced on requests of mapped users.
+func TestFSCheckPOSIXAccess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX permissions are not supported on Windows")
+	}
+
+	disk, err := ioutil.TempDir(globalTestTmpDir, "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(disk)
+
+	obj := initFSObjects(disk, t)
+	fs := obj.(*fsObjects)
+
+	bucket := "bucket"
+	if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = obj.PutObject(bucket, "dir/object", mustGetHashReader(t, bytes.NewReader([]byte("abcd")), 4, "", ""), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Objects are owned by the test process, users of its group are
+	// granted the group permission bits.
+	group := posixUser{UID: os.Getuid() + 1, GID: os.Getgid()}
+	other := posixUser{UID: os.Getuid() + 1, GID: os.Getgid() + 1}
+	dirPath := pathJoin(disk, bucket, "dir")
+	objectPath := pathJoin(dirPath, "object")
+
+	testCases := []struct {
+		dirMode, objectMode os.FileMode
+		user                posixUser
+		object              string
+		write               bool
+		expected            bool
+	}{
+		{0755, 0644, other, "dir/object", false, true},
+		{0755, 0644, other, "dir/object", true, false},
+		{0755, 0640, other, "dir/object", false, false},
+		{0750, 0640, group, "dir/object", false, true},
+		{0750, 0640, group, "dir/object", true, false},
+		{0770, 0660, group, "dir/object", true, true},
+		// Directories must be searchable.
+		{0754, 0644, other, "dir/object", false, false},
+		// New objects require a writable parent directory.
+		{0755, 0644, other, "dir/new", true, false},
+		{0757, 0644, other, "dir/new/object", true, true},
+		// Missing objects are reported by the request.
+		{0755, 0644, other, "dir/missing", false, true},
+	}
+	for i, testCase := range testCases {
+		if err = os.Chmod(dirPath, testCase.dirMode); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.Chmod(objectPath, testCase.objectMode); err != nil {
+			t.Fatal(err)
+		}
+		err = fs.checkPOSIXAccess(bucket, testCase.object, testCase.user, testCase.write)
+		if (err == nil) != testCase.expected {
+			t.Errorf("Test %d: expected access %v, got %v", i+1, testCase.expected, err)
+		}
+	}
+
+	// The root user is granted everything.
+	if err = fs.checkPOSIXAccess(bucket, "dir/object", posixUser{}, true); err != nil {
+		t.Errorf("Expected root to be granted access, got %v", err)
+	}
+}
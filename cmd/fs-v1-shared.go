@@ -0,0 +1,319 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/minio/lsync"
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/lock"
+)
+
+const (
+	// Directory of lock files in `.minio.sys` of a shared backend.
+	fsLocksDir = "locks"
+
+	// Interval between attempts to lock a lock file held by another
+	// server.
+	fsLockRetryInterval = 10 * time.Millisecond
+
+	// Interval between checks of bucket configs changed by other
+	// servers of a shared backend.
+	sharedFSRefreshInterval = 10 * time.Second
+)
+
+// NewNASObjectLayer - initializes an fs object layer on a filesystem
+// shared by several servers, such as an NFS mount.
+func NewNASObjectLayer(fsPath string) (ObjectLayer, error) {
+	return newFSObjects(fsPath, true)
+}
+
+// newSharedNSLock - returns a namespace lock map whose locks are held
+// on lock files in lockDir, shared by all servers using lockDir.
+func newSharedNSLock(lockDir string) *nsLockMap {
+	nsMutex := newNSLock(false)
+	nsMutex.lockDir = lockDir
+	return nsMutex
+}
+
+// fsRWMutex - lock of a namespace resource held on a lock file. The
+// in-process lock queues goroutines of this server, so that at most
+// one descriptor of the lock file is open at once. Locks thereby also
+// work where flock() is emulated by per-process fcntl() locks, as on
+// NFS.
+type fsRWMutex struct {
+	lrw  lsync.LRWMutex
+	path string
+
+	mu      sync.Mutex
+	readers int
+	file    *lock.LockedFile
+}
+
+func newFSRWMutex(lockDir, volume, path string) *fsRWMutex {
+	// Lock files are named by hash, as objects and their parent
+	// directories are locked independently.
+	return &fsRWMutex{
+		path: pathJoin(lockDir, getSHA256Hash([]byte(pathJoin(volume, path)))),
+	}
+}
+
+// tryLockFile - tries to lock the lock file at path once.
+func tryLockFile(path string, readLock bool) (*lock.LockedFile, error) {
+	flag := os.O_RDWR | os.O_CREATE
+	if readLock {
+		// Read locks are only held on existing files.
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0666)
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+		flag = os.O_RDONLY
+	}
+
+	lk, err := lock.TryLockedOpenFile(path, flag, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	// The previous holder removes the lock file before unlocking it,
+	// the lock is only valid if it is held on the current file.
+	fi, err := lk.Stat()
+	if err != nil {
+		lk.Close()
+		return nil, err
+	}
+	st, err := os.Stat(path)
+	if err != nil || !os.SameFile(fi, st) {
+		lk.Close()
+		return nil, lock.ErrAlreadyLocked
+	}
+	return lk, nil
+}
+
+// lockFile - polls for a lock on the lock file at path until timeout.
+func lockFile(path string, readLock bool, timeout time.Duration) *lock.LockedFile {
+	deadline := time.Now().Add(timeout)
+	for {
+		lk, err := tryLockFile(path, readLock)
+		if err == nil {
+			return lk
+		}
+		if time.Now().After(deadline) {
+			if err != lock.ErrAlreadyLocked && !os.IsNotExist(err) {
+				errorIf(err, "Unable to lock %s", path)
+			}
+			return nil
+		}
+		time.Sleep(fsLockRetryInterval)
+	}
+}
+
+// removeLockFile - removes the lock file at path unless another server
+// holds it.
+func removeLockFile(path string) {
+	lk, err := tryLockFile(path, false)
+	if err != nil {
+		return
+	}
+	os.Remove(path)
+	lk.Close()
+}
+
+// GetLock - tries to get a write lock until timeout.
+func (m *fsRWMutex) GetLock(timeout time.Duration) bool {
+	start := time.Now()
+	if !m.lrw.GetLock(timeout) {
+		return false
+	}
+
+	lk := lockFile(m.path, false, timeout-time.Since(start))
+	if lk == nil {
+		m.lrw.Unlock()
+		return false
+	}
+	m.file = lk
+	return true
+}
+
+// Unlock - releases a write lock.
+func (m *fsRWMutex) Unlock() {
+	// Lock files are removed while locked, so that they do not pile
+	// up for every object ever written.
+	os.Remove(m.path)
+	m.file.Close()
+	m.file = nil
+	m.lrw.Unlock()
+}
+
+// GetRLock - tries to get a read lock until timeout, the lock file is
+// locked by the first reader of this server.
+func (m *fsRWMutex) GetRLock(timeout time.Duration) bool {
+	start := time.Now()
+	if !m.lrw.GetRLock(timeout) {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.readers == 0 {
+		lk := lockFile(m.path, true, timeout-time.Since(start))
+		if lk == nil {
+			m.lrw.RUnlock()
+			return false
+		}
+		m.file = lk
+	}
+	m.readers++
+	return true
+}
+
+// RUnlock - releases a read lock, the lock file is unlocked by the last
+// reader of this server.
+func (m *fsRWMutex) RUnlock() {
+	m.mu.Lock()
+	m.readers--
+	if m.readers == 0 {
+		m.file.Close()
+		m.file = nil
+		removeLockFile(m.path)
+	}
+	m.mu.Unlock()
+	m.lrw.RUnlock()
+}
+
+// Bucket configs cached in memory by servers, re-read from a shared
+// backend when changed by other servers.
+var sharedBucketConfigs = []string{
+	bucketPolicyConfig,
+	bucketACLConfig,
+	bucketNotificationConfig,
+	bucketLoggingConfig,
+}
+
+// refreshBucketConfig - reloads a bucket config into memory.
+func (fs *fsObjects) refreshBucketConfig(bucket, config string) error {
+	switch config {
+	case bucketPolicyConfig:
+		return fs.RefreshBucketPolicy(bucket)
+	case bucketACLConfig:
+		return refreshBucketACL(bucket, fs)
+	case bucketNotificationConfig:
+		ncfg, err := loadNotificationConfig(bucket, fs)
+		if err != nil && errors.Cause(err) != errNoSuchNotifications {
+			return err
+		}
+		globalEventNotifier.SetBucketNotificationConfig(bucket, ncfg)
+	case bucketLoggingConfig:
+		if globalBucketAccessLogger == nil {
+			return nil
+		}
+		lcfg, err := readBucketLoggingConfig(bucket, fs)
+		if err != nil {
+			return err
+		}
+		globalBucketAccessLogger.SetBucketLoggingConfig(bucket, lcfg)
+	}
+	return nil
+}
+
+// bucketConfigKey - identifies a config of a bucket.
+type bucketConfigKey struct {
+	bucket, config string
+}
+
+// refreshBucketConfigs - reloads bucket configs which differ from
+// their previous contents in seen, and updates seen.
+func (fs *fsObjects) refreshBucketConfigs(seen map[bucketConfigKey][]byte) {
+	buckets, err := fs.ListBuckets()
+	if err != nil {
+		errorIf(err, "Unable to list buckets to refresh their configs.")
+		return
+	}
+
+	current := make(map[bucketConfigKey][]byte)
+	for _, bucket := range buckets {
+		for _, config := range sharedBucketConfigs {
+			// Configs are replaced by rename, they are never read
+			// half written.
+			data, err := ioutil.ReadFile(pathJoin(fs.fsPath, minioMetaBucket, bucketConfigPrefix, bucket.Name, config))
+			if err != nil {
+				continue
+			}
+			current[bucketConfigKey{bucket.Name, config}] = data
+		}
+	}
+
+	// Configs which were removed, along with their bucket, are
+	// reloaded as well.
+	changed := make(map[bucketConfigKey]struct{})
+	for key, data := range current {
+		if old, ok := seen[key]; !ok || !bytes.Equal(data, old) {
+			changed[key] = struct{}{}
+		}
+	}
+	for key := range seen {
+		if _, ok := current[key]; !ok {
+			changed[key] = struct{}{}
+		}
+	}
+
+	for key := range changed {
+		if err = fs.refreshBucketConfig(key.bucket, key.config); err != nil {
+			errorIf(err, "Unable to refresh %s of bucket %s", key.config, key.bucket)
+			// Retried on the next refresh.
+			if old, ok := seen[key]; ok {
+				current[key] = old
+			} else {
+				delete(current, key)
+			}
+		}
+	}
+
+	for key := range seen {
+		delete(seen, key)
+	}
+	for key, data := range current {
+		seen[key] = data
+	}
+}
+
+// watchBucketConfigs - reloads bucket configs changed by other servers
+// every interval, this function is blocking and should be run in a
+// go-routine.
+func (fs *fsObjects) watchBucketConfigs(interval time.Duration, doneCh chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// The first refresh reloads all configs, including those changed
+	// since initialization.
+	seen := make(map[bucketConfigKey][]byte)
+	fs.refreshBucketConfigs(seen)
+	for {
+		select {
+		case <-doneCh:
+			return
+		case <-ticker.C:
+			fs.refreshBucketConfigs(seen)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/pkg/policy"
+)
+
+// Tests locks of different servers sharing a lock directory.
+func TestFSRWMutex(t *testing.T) {
+	lockDir, err := ioutil.TempDir(globalTestTmpDir, "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(lockDir)
+
+	// Each mutex stands in for the lock of another server.
+	m1 := newFSRWMutex(lockDir, "bucket", "object")
+	m2 := newFSRWMutex(lockDir, "bucket", "object")
+	m3 := newFSRWMutex(lockDir, "bucket", "object")
+	timeout := 50 * time.Millisecond
+
+	if !m1.GetLock(timeout) {
+		t.Fatal("Expected write lock to succeed")
+	}
+	if m2.GetLock(timeout) || m2.GetRLock(timeout) {
+		t.Fatal("Expected locks to fail while write locked")
+	}
+	m1.Unlock()
+
+	if !m2.GetRLock(timeout) || !m2.GetRLock(timeout) || !m3.GetRLock(timeout) {
+		t.Fatal("Expected read locks to succeed")
+	}
+	if m1.GetLock(timeout) {
+		t.Fatal("Expected write lock to fail while read locked")
+	}
+	m2.RUnlock()
+	m2.RUnlock()
+	if m1.GetLock(timeout) {
+		t.Fatal("Expected write lock to fail while read locked")
+	}
+	m3.RUnlock()
+
+	// Lock files of unlocked resources are removed.
+	if _, err = os.Stat(m1.path); !os.IsNotExist(err) {
+		t.Fatalf("Expected lock file to be removed, got %v", err)
+	}
+	if !m1.GetLock(timeout) {
+		t.Fatal("Expected write lock to succeed")
+	}
+	m1.Unlock()
+}
+
+// Tests reloading bucket configs changed by other servers.
+func TestFSRefreshBucketConfigs(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	disk, err := ioutil.TempDir(globalTestTmpDir, "minio-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(disk)
+
+	obj, err := newFSObjects(disk, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := obj.(*fsObjects)
+
+	bucket := "bucket"
+	if err = fs.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[bucketConfigKey][]byte)
+	fs.refreshBucketConfigs(seen)
+
+	// Policies written without notifying this server are loaded by
+	// the next refresh.
+	bucketPolicy := policy.BucketAccessPolicy{
+		Version:    "2012-10-17",
+		Statements: policy.SetPolicy(nil, policy.BucketPolicyReadOnly, bucket, ""),
+	}
+	if err = writeBucketPolicy(bucket, fs, bucketPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fs.bucketPolicies.GetBucketPolicy(bucket), emptyBucketPolicy) {
+		t.Fatal("Expected policy not to be cached before refresh")
+	}
+	fs.refreshBucketConfigs(seen)
+	if !reflect.DeepEqual(fs.bucketPolicies.GetBucketPolicy(bucket), bucketPolicy) {
+		t.Fatal("Expected policy to be cached after refresh")
+	}
+
+	if err = removeBucketPolicy(bucket, fs); err != nil {
+		t.Fatal(err)
+	}
+	fs.refreshBucketConfigs(seen)
+	if !reflect.DeepEqual(fs.bucketPolicies.GetBucketPolicy(bucket), emptyBucketPolicy) {
+		t.Fatal("Expected policy to be removed after refresh")
+	}
+}
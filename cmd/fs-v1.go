@@ -63,6 +63,14 @@ type fsObjects struct {
 
 	// Variable represents bucket policies in memory.
 	bucketPolicies *bucketPolicies
+
+	// Variable represents bucket ACLs in memory.
+	bucketACLs *bucketACLs
+
+	// Set if fsPath is shared with other servers, namespace locks
+	// are then held on lock files and bucket configs changed by
+	// other servers are re-read periodically.
+	shared bool
 }
 
 // Represents the background append file.
@@ -95,6 +103,35 @@ func initMetaVolumeFS(fsPath, fsUUID string) error {
 
 // newFSObjectLayer - initialize new fs object layer.
 func newFSObjectLayer(fsPath string) (ObjectLayer, error) {
+	fs, err := newFSObjects(fsPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if err = initFSNotifications(fs); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// initFSNotifications - initializes the event notifier and the bucket
+// access logger of the server on an fs object layer.
+func initFSNotifications(fs ObjectLayer) error {
+	// Initialize a new event notifier.
+	if err := initEventNotifier(fs); err != nil {
+		return fmt.Errorf("Unable to initialize event notification. %s", err)
+	}
+
+	// Initialize and load bucket access logging.
+	if err := initBucketLogging(fs); err != nil {
+		return fmt.Errorf("Unable to initialize bucket logging. %s", err)
+	}
+	return nil
+}
+
+// newFSObjects - initialize new fs object layer, shared with other
+// servers if shared is set. The event notifier and the bucket access
+// logger of the server are left alone.
+func newFSObjects(fsPath string, shared bool) (ObjectLayer, error) {
 	if fsPath == "" {
 		return nil, errInvalidArgument
 	}
@@ -145,6 +182,15 @@ func newFSObjectLayer(fsPath string) (ObjectLayer, error) {
 		return nil, err
 	}
 
+	nsMutex := newNSLock(false)
+	if shared {
+		lockDir := pathJoin(fsPath, minioMetaBucket, fsLocksDir)
+		if err = os.MkdirAll(lockDir, 0777); err != nil {
+			return nil, fmt.Errorf("Unable to initialize '.minio.sys' locks, %s", err)
+		}
+		nsMutex = newSharedNSLock(lockDir)
+	}
+
 	// Initialize fs objects.
 	fs := &fsObjects{
 		fsPath: fsPath,
@@ -152,9 +198,10 @@ func newFSObjectLayer(fsPath string) (ObjectLayer, error) {
 		rwPool: &fsIOPool{
 			readersMap: make(map[string]*lock.RLockedFile),
 		},
-		nsMutex:       newNSLock(false),
+		nsMutex:       nsMutex,
 		listPool:      newTreeWalkPool(globalLookupTimeout),
 		appendFileMap: make(map[string]*fsAppendFile),
+		shared:        shared,
 	}
 
 	// Once the filesystem has initialized hold the read lock for
@@ -168,12 +215,15 @@ func newFSObjectLayer(fsPath string) (ObjectLayer, error) {
 		return nil, fmt.Errorf("Unable to load all bucket policies. %s", err)
 	}
 
-	// Initialize a new event notifier.
-	if err = initEventNotifier(fs); err != nil {
-		return nil, fmt.Errorf("Unable to initialize event notification. %s", err)
+	// Initialize and load bucket ACLs.
+	if err = initBucketACLs(fs); err != nil {
+		return nil, fmt.Errorf("Unable to load all bucket ACLs. %s", err)
 	}
 
 	go fs.cleanupStaleMultipartUploads(multipartCleanupInterval, multipartExpiry, globalServiceDoneCh)
+	if shared {
+		go fs.watchBucketConfigs(sharedFSRefreshInterval, globalServiceDoneCh)
+	}
 	// Return successfully initialized object layer.
 	return fs, nil
 }
@@ -352,6 +402,18 @@ func (fs *fsObjects) DeleteBucket(bucket string) error {
 	// Notify all peers (including self) to update in-memory state
 	S3PeersUpdateBucketPolicy(bucket)
 
+	// Delete bucket ACL, if present - ignore any errors.
+	_ = removeBucketACL(bucket, fs)
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketACL(bucket)
+
+	// Delete bucket logging config, if present - ignore any errors.
+	_ = removeBucketLoggingConfig(bucket, fs)
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketLogging(bucket)
+
 	// Delete notification config, if present - ignore any errors.
 	_ = removeNotificationConfig(bucket, fs)
 
@@ -424,6 +486,9 @@ func (fs *fsObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject strin
 		// This close will allow for locks to be synchronized on `fs.json`.
 		defer wlk.Close()
 
+		// Metadata updates keep the POSIX owner of the object.
+		delete(metadata, posixOwnerKey)
+
 		// Save objects' metadata in `fs.json`.
 		fsMeta := newFSMetaV1()
 		fsMeta.Meta = metadata
@@ -671,6 +736,10 @@ func (fs *fsObjects) putObject(bucket string, object string, data *hash.Reader,
 	}
 	var err error
 
+	// The POSIX owner is applied to the object, not saved with it.
+	owner := metadata[posixOwnerKey]
+	delete(metadata, posixOwnerKey)
+
 	// Validate if bucket name is valid and exists.
 	if _, err = fs.statBucketDir(bucket); err != nil {
 		return ObjectInfo{}, toObjectErr(err, bucket)
@@ -687,7 +756,7 @@ func (fs *fsObjects) putObject(bucket string, object string, data *hash.Reader,
 		if fs.parentDirIsObject(bucket, path.Dir(object)) {
 			return ObjectInfo{}, toObjectErr(errors.Trace(errFileAccessDenied), bucket, object)
 		}
-		if err = mkdirAll(pathJoin(fs.fsPath, bucket, object), 0777); err != nil {
+		if err = setPOSIXOwnership(pathJoin(fs.fsPath, bucket, object), "", owner); err != nil {
 			return ObjectInfo{}, toObjectErr(err, bucket, object)
 		}
 		var fi os.FileInfo
@@ -765,6 +834,11 @@ func (fs *fsObjects) putObject(bucket string, object string, data *hash.Reader,
 
 	// Entire object was written to the temp location, now it's safe to rename it to the actual location.
 	fsNSObjPath := pathJoin(fs.fsPath, bucket, object)
+	if bucket != minioMetaBucket {
+		if err = setPOSIXOwnership(path.Dir(fsNSObjPath), fsTmpObjPath, owner); err != nil {
+			return ObjectInfo{}, toObjectErr(err, bucket, object)
+		}
+	}
 	if err = fsRenameFile(fsTmpObjPath, fsNSObjPath); err != nil {
 		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
@@ -1060,6 +1134,12 @@ func (fs *fsObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKe
 	return result, nil
 }
 
+// RestoreObject - no-op for fs, objects are never transitioned. Valid
+// only for XL.
+func (fs *fsObjects) RestoreObject(bucket, object string, days int) error {
+	return errors.Trace(NotImplemented{})
+}
+
 // HealObject - no-op for fs. Valid only for XL.
 func (fs *fsObjects) HealObject(bucket, object string, dryRun bool) (
 	res madmin.HealResultItem, err error) {
@@ -1140,3 +1220,13 @@ func (fs *fsObjects) IsNotificationSupported() bool {
 func (fs *fsObjects) IsEncryptionSupported() bool {
 	return true
 }
+
+// IsACLSupported returns whether canned ACLs are applicable for this layer.
+func (fs *fsObjects) IsACLSupported() bool {
+	return true
+}
+
+// IsLoggingSupported returns whether bucket access logging is applicable for this layer.
+func (fs *fsObjects) IsLoggingSupported() bool {
+	return true
+}
@@ -0,0 +1,534 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Time to wait for the data connection of a transfer.
+	ftpDataConnTimeout = 30 * time.Second
+	// Time to wait for TLS handshakes.
+	ftpTLSHandshakeTimeout = 30 * time.Second
+	// Commands are limited to a path and their name.
+	ftpMaxCommandSize = 4096
+)
+
+// ftpServer - FTPS server of sessions logged in with the server
+// credentials or temporary credentials, data connections are passive
+// or active. Control connections are secured by AUTH TLS before login,
+// and data connections must be protected by PROT P, as described in
+// https://tools.ietf.org/html/rfc4217
+type ftpServer struct {
+	tlsConfig *tls.Config
+}
+
+func newFTPServer(tlsConfig *tls.Config) *ftpServer {
+	return &ftpServer{tlsConfig: tlsConfig}
+}
+
+// Serve - accepts connections of l until it is closed.
+func (s *ftpServer) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go newFTPConn(conn, s.tlsConfig).serve()
+	}
+}
+
+// ftpConn - control connection of an FTP session.
+type ftpConn struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	tlsConfig *tls.Config
+	// Set once the control connection is secured by AUTH TLS, and
+	// data connections are protected by PROT P.
+	secure    bool
+	protected bool
+
+	user    string
+	session *fileTransferSession
+	cwd     string
+	// Offset of the next download, set by REST.
+	restOffset int64
+	// Path of the file to rename, set by RNFR.
+	renameFrom string
+	// Listener of passive mode, or address of active mode.
+	pasvListener net.Listener
+	activeAddr   string
+}
+
+func newFTPConn(conn net.Conn, tlsConfig *tls.Config) *ftpConn {
+	return &ftpConn{
+		conn:      conn,
+		reader:    bufio.NewReaderSize(conn, ftpMaxCommandSize),
+		tlsConfig: tlsConfig,
+		cwd:       "/",
+	}
+}
+
+func (c *ftpConn) reply(code int, format string, args ...interface{}) error {
+	_, err := fmt.Fprintf(c.conn, "%d %s\r\n", code, fmt.Sprintf(format, args...))
+	return err
+}
+
+// replyError - replies the error of a file operation.
+func (c *ftpConn) replyError(err error) error {
+	switch {
+	case os.IsNotExist(err):
+		return c.reply(550, "No such file or directory.")
+	case os.IsPermission(err):
+		return c.reply(550, "Permission denied.")
+	case os.IsExist(err):
+		return c.reply(550, "File exists.")
+	case err == errFileTransferUnsupported:
+		return c.reply(504, "Operation not supported.")
+	}
+	return c.reply(550, "%s.", strings.TrimSuffix(err.Error(), "."))
+}
+
+// absPath - returns the absolute path of an argument.
+func (c *ftpConn) absPath(p string) string {
+	if path.IsAbs(p) {
+		return path.Clean(p)
+	}
+	return path.Join(c.cwd, p)
+}
+
+func (c *ftpConn) closeDataListener() {
+	if c.pasvListener != nil {
+		c.pasvListener.Close()
+		c.pasvListener = nil
+	}
+}
+
+func (c *ftpConn) serve() {
+	defer c.conn.Close()
+	defer c.closeDataListener()
+
+	if c.reply(220, "Minio FTP server ready.") != nil {
+		return
+	}
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command, arg := line, ""
+		if i := strings.IndexByte(line, ' '); i >= 0 {
+			command, arg = line[:i], line[i+1:]
+		}
+		command = strings.ToUpper(command)
+		if command == "QUIT" {
+			c.reply(221, "Goodbye.")
+			return
+		}
+		if err = c.handle(command, arg); err != nil {
+			return
+		}
+	}
+}
+
+// handle - serves a command, errors are those of the control
+// connection.
+func (c *ftpConn) handle(command, arg string) error {
+	switch command {
+	case "AUTH":
+		if c.secure {
+			return c.reply(503, "Already secured.")
+		}
+		if arg = strings.ToUpper(arg); arg != "TLS" && arg != "TLS-C" && arg != "SSL" {
+			return c.reply(504, "Only AUTH TLS is supported.")
+		}
+		if err := c.reply(234, "Proceed with TLS negotiation."); err != nil {
+			return err
+		}
+		tlsConn := tls.Server(c.conn, c.tlsConfig)
+		tlsConn.SetDeadline(time.Now().Add(ftpTLSHandshakeTimeout))
+		if err := tlsConn.Handshake(); err != nil {
+			return err
+		}
+		tlsConn.SetDeadline(time.Time{})
+		c.conn, c.reader = tlsConn, bufio.NewReaderSize(tlsConn, ftpMaxCommandSize)
+		c.secure, c.user, c.session = true, "", nil
+		return nil
+	case "PBSZ":
+		if !c.secure {
+			return c.reply(503, "Secure the connection with AUTH TLS first.")
+		}
+		return c.reply(200, "PBSZ=0")
+	case "PROT":
+		if !c.secure {
+			return c.reply(503, "Secure the connection with AUTH TLS first.")
+		}
+		switch strings.ToUpper(arg) {
+		case "P":
+			c.protected = true
+			return c.reply(200, "Data connections are protected.")
+		case "C":
+			return c.reply(536, "Only protected data connections are supported.")
+		}
+		return c.reply(504, "Protection level not supported.")
+	case "USER":
+		if !c.secure {
+			return c.reply(530, "Secure the connection with AUTH TLS before login.")
+		}
+		c.user, c.session = arg, nil
+		return c.reply(331, "Password required for %s.", arg)
+	case "PASS":
+		if c.user == "" {
+			return c.reply(503, "Login with USER first.")
+		}
+		sessionToken, err := authenticateFileTransfer(c.user, arg)
+		if err != nil {
+			c.user = ""
+			return c.reply(530, "Login incorrect.")
+		}
+		c.session = &fileTransferSession{
+			accessKey:    c.user,
+			sessionToken: sessionToken,
+			remoteAddr:   c.conn.RemoteAddr().String(),
+			protocol:     "ftp",
+		}
+		return c.reply(230, "User %s logged in.", c.user)
+	case "SYST":
+		return c.reply(215, "UNIX Type: L8")
+	case "FEAT":
+		_, err := fmt.Fprint(c.conn, "211-Features:\r\n AUTH TLS\r\n EPSV\r\n MDTM\r\n PASV\r\n PBSZ\r\n PROT\r\n REST STREAM\r\n SIZE\r\n UTF8\r\n211 End\r\n")
+		return err
+	case "NOOP":
+		return c.reply(200, "OK.")
+	case "OPTS":
+		if strings.ToUpper(arg) == "UTF8 ON" {
+			return c.reply(200, "UTF8 mode enabled.")
+		}
+		return c.reply(501, "Option not supported.")
+	}
+
+	if c.session == nil {
+		return c.reply(530, "Please login with USER and PASS.")
+	}
+
+	switch command {
+	case "PWD", "XPWD":
+		return c.reply(257, "\"%s\" is the current directory.", strings.Replace(c.cwd, "\"", "\"\"", -1))
+	case "CWD", "XCWD":
+		return c.changeDir(c.absPath(arg))
+	case "CDUP", "XCUP":
+		return c.changeDir(path.Dir(c.cwd))
+	case "TYPE":
+		// Files are always transferred as they are stored.
+		return c.reply(200, "Type set to %s.", arg)
+	case "MODE", "STRU":
+		if arg == "S" || arg == "F" {
+			return c.reply(200, "OK.")
+		}
+		return c.reply(504, "Only stream mode and file structure are supported.")
+	case "PASV", "EPSV":
+		return c.passive(command)
+	case "PORT", "EPRT":
+		return c.active(command, arg)
+	case "REST":
+		offset, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || offset < 0 {
+			return c.reply(501, "Invalid offset.")
+		}
+		c.restOffset = offset
+		return c.reply(350, "Restarting at %d.", offset)
+	case "LIST", "NLST":
+		return c.list(command, arg)
+	case "RETR":
+		return c.retrieve(c.absPath(arg))
+	case "STOR":
+		return c.store(c.absPath(arg))
+	case "DELE":
+		if err := c.session.remove(c.absPath(arg)); err != nil {
+			return c.replyError(err)
+		}
+		return c.reply(250, "File deleted.")
+	case "RNFR":
+		p := c.absPath(arg)
+		if _, err := c.session.stat(p); err != nil {
+			return c.replyError(err)
+		}
+		c.renameFrom = p
+		return c.reply(350, "Ready for destination name.")
+	case "RNTO":
+		if c.renameFrom == "" {
+			return c.reply(503, "Send RNFR first.")
+		}
+		from := c.renameFrom
+		c.renameFrom = ""
+		if err := c.session.rename(from, c.absPath(arg)); err != nil {
+			return c.replyError(err)
+		}
+		return c.reply(250, "File renamed.")
+	case "MKD", "XMKD":
+		p := c.absPath(arg)
+		if err := c.session.mkdir(p); err != nil {
+			return c.replyError(err)
+		}
+		return c.reply(257, "\"%s\" created.", strings.Replace(p, "\"", "\"\"", -1))
+	case "RMD", "XRMD":
+		if err := c.session.rmdir(c.absPath(arg)); err != nil {
+			return c.replyError(err)
+		}
+		return c.reply(250, "Directory removed.")
+	case "SIZE", "MDTM":
+		fi, err := c.session.stat(c.absPath(arg))
+		if err != nil {
+			return c.replyError(err)
+		}
+		if fi.IsDir() {
+			return c.replyError(errFileTransferIsDir)
+		}
+		if command == "SIZE" {
+			return c.reply(213, "%d", fi.Size())
+		}
+		return c.reply(213, "%s", fi.ModTime().UTC().Format("20060102150405"))
+	}
+	return c.reply(502, "Command not implemented.")
+}
+
+func (c *ftpConn) changeDir(p string) error {
+	fi, err := c.session.stat(p)
+	if err != nil {
+		return c.replyError(err)
+	}
+	if !fi.IsDir() {
+		return c.replyError(errFileTransferNotDir)
+	}
+	c.cwd = p
+	return c.reply(250, "Directory changed to %s.", p)
+}
+
+// passive - listens for the data connection of the next transfer on
+// the address the client connected to.
+func (c *ftpConn) passive(command string) error {
+	c.closeDataListener()
+	c.activeAddr = ""
+	host, _, err := net.SplitHostPort(c.conn.LocalAddr().String())
+	if err != nil {
+		return c.reply(425, "Unable to open data connection.")
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return c.reply(425, "Unable to open data connection.")
+	}
+	c.pasvListener = l
+	port := l.Addr().(*net.TCPAddr).Port
+
+	if command == "EPSV" {
+		return c.reply(229, "Entering Extended Passive Mode (|||%d|).", port)
+	}
+	ip := net.ParseIP(host).To4()
+	if ip == nil {
+		c.closeDataListener()
+		return c.reply(425, "Use EPSV for IPv6 connections.")
+	}
+	return c.reply(227, "Entering Passive Mode (%d,%d,%d,%d,%d,%d).", ip[0], ip[1], ip[2], ip[3], port>>8, port&0xff)
+}
+
+// active - saves the address to connect to for the data connection of
+// the next transfer, only addresses of the client are accepted.
+func (c *ftpConn) active(command, arg string) error {
+	c.closeDataListener()
+	var host, port string
+	if command == "PORT" {
+		fields := strings.Split(arg, ",")
+		if len(fields) != 6 {
+			return c.reply(501, "Invalid PORT argument.")
+		}
+		p1, err1 := strconv.Atoi(fields[4])
+		p2, err2 := strconv.Atoi(fields[5])
+		if err1 != nil || err2 != nil {
+			return c.reply(501, "Invalid PORT argument.")
+		}
+		host, port = strings.Join(fields[:4], "."), strconv.Itoa(p1<<8+p2)
+	} else {
+		// EPRT arguments are of the form |protocol|address|port|.
+		if arg == "" {
+			return c.reply(501, "Invalid EPRT argument.")
+		}
+		fields := strings.Split(arg, arg[:1])
+		if len(fields) != 5 {
+			return c.reply(501, "Invalid EPRT argument.")
+		}
+		host, port = fields[2], fields[3]
+	}
+
+	clientHost, _, _ := net.SplitHostPort(c.conn.RemoteAddr().String())
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.Equal(net.ParseIP(clientHost)) {
+		return c.reply(501, "Data connections are only made to the client.")
+	}
+	c.activeAddr = net.JoinHostPort(host, port)
+	return c.reply(200, "%s command successful.", command)
+}
+
+// dataConn - opens the data connection set up by the last PASV, EPSV,
+// PORT or EPRT command, secured by TLS. The server is the TLS server
+// of data connections in both modes.
+func (c *ftpConn) dataConn() (net.Conn, error) {
+	conn, err := c.openDataConn()
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Server(conn, c.tlsConfig)
+	tlsConn.SetDeadline(time.Now().Add(ftpTLSHandshakeTimeout))
+	if err = tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+func (c *ftpConn) openDataConn() (net.Conn, error) {
+	if c.activeAddr != "" {
+		addr := c.activeAddr
+		c.activeAddr = ""
+		return net.DialTimeout("tcp", addr, ftpDataConnTimeout)
+	}
+	if c.pasvListener == nil {
+		return nil, errFileTransferUnsupported
+	}
+	defer c.closeDataListener()
+	if tcpListener, ok := c.pasvListener.(*net.TCPListener); ok {
+		tcpListener.SetDeadline(time.Now().Add(ftpDataConnTimeout))
+	}
+	conn, err := c.pasvListener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	// Only the client may connect.
+	clientHost, _, _ := net.SplitHostPort(c.conn.RemoteAddr().String())
+	dataHost, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	if clientHost != dataHost {
+		conn.Close()
+		return nil, os.ErrPermission
+	}
+	return conn, nil
+}
+
+// transfer - runs fn with the data connection, replies the result of
+// the transfer.
+func (c *ftpConn) transfer(fn func(conn net.Conn) error) error {
+	if !c.protected {
+		c.closeDataListener()
+		c.activeAddr = ""
+		return c.reply(521, "Protect data connections with PROT P first.")
+	}
+	if err := c.reply(150, "Opening data connection."); err != nil {
+		return err
+	}
+	conn, err := c.dataConn()
+	if err != nil {
+		return c.reply(425, "Unable to open data connection.")
+	}
+	err = fn(conn)
+	conn.Close()
+	if err != nil {
+		return c.reply(426, "Transfer aborted: %s.", strings.TrimSuffix(err.Error(), "."))
+	}
+	return c.reply(226, "Transfer complete.")
+}
+
+func (c *ftpConn) list(command, arg string) error {
+	// Options of `ls` sent by clients are ignored.
+	fields := strings.Fields(arg)
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+		fields = fields[1:]
+	}
+	p := c.cwd
+	if len(fields) > 0 {
+		p = c.absPath(strings.Join(fields, " "))
+	}
+
+	fi, err := c.session.stat(p)
+	if err != nil {
+		return c.replyError(err)
+	}
+	infos := []os.FileInfo{fi}
+	if fi.IsDir() {
+		if infos, err = c.session.readDir(p); err != nil {
+			return c.replyError(err)
+		}
+	}
+	return c.transfer(func(conn net.Conn) error {
+		w := bufio.NewWriter(conn)
+		for _, fi := range infos {
+			if command == "NLST" {
+				fmt.Fprintf(w, "%s\r\n", fi.Name())
+			} else {
+				fmt.Fprintf(w, "%s\r\n", fileTransferListLine(fi))
+			}
+		}
+		return w.Flush()
+	})
+}
+
+func (c *ftpConn) retrieve(p string) error {
+	offset := c.restOffset
+	c.restOffset = 0
+	reader, err := c.session.open(p, offset)
+	if err != nil {
+		return c.replyError(err)
+	}
+	defer reader.Close()
+	return c.transfer(func(conn net.Conn) error {
+		_, err := io.Copy(conn, reader)
+		return err
+	})
+}
+
+func (c *ftpConn) store(p string) error {
+	if c.restOffset != 0 {
+		c.restOffset = 0
+		return c.reply(504, "Uploads can't be restarted.")
+	}
+	writer, err := c.session.create(p)
+	if err != nil {
+		return c.replyError(err)
+	}
+	started := false
+	err = c.transfer(func(conn net.Conn) error {
+		started = true
+		if _, err := io.Copy(writer, conn); err != nil {
+			writer.Abort()
+			return err
+		}
+		return writer.Close()
+	})
+	if !started {
+		writer.Abort()
+	}
+	return err
+}
@@ -0,0 +1,189 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// ftpTestClient - sends FTP commands, data is transferred in extended
+// passive mode over TLS.
+type ftpTestClient struct {
+	t    *testing.T
+	conn *textproto.Conn
+}
+
+// ftpTestTLSConfig - client TLS configuration trusting any server.
+var ftpTestTLSConfig = &tls.Config{InsecureSkipVerify: true}
+
+// cmd - sends a command, returns the reply code and message.
+func (c *ftpTestClient) cmd(format string, args ...interface{}) (int, string) {
+	if err := c.conn.PrintfLine(format, args...); err != nil {
+		c.t.Fatal(err)
+	}
+	code, msg, err := c.conn.ReadResponse(0)
+	if err != nil && code == 0 {
+		c.t.Fatal(err)
+	}
+	return code, msg
+}
+
+// expect - sends a command, fails if the reply code is not code.
+func (c *ftpTestClient) expect(code int, format string, args ...interface{}) string {
+	replyCode, msg := c.cmd(format, args...)
+	if replyCode != code {
+		c.t.Fatalf("%s: expected reply %d, got %d %s", fmt.Sprintf(format, args...), code, replyCode, msg)
+	}
+	return msg
+}
+
+// transfer - sends a transfer command, writes data to or reads data
+// from the data connection, returns the reply code of the transfer.
+func (c *ftpTestClient) transfer(data string, format string, args ...interface{}) (int, string) {
+	msg := c.expect(229, "EPSV")
+	port := strings.TrimSuffix(msg[strings.Index(msg, "|||")+3:], "|).")
+	if _, err := strconv.Atoi(port); err != nil {
+		c.t.Fatalf("Invalid EPSV reply %s", msg)
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", port))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if code, msg := c.cmd(format, args...); code != 150 {
+		return code, msg
+	}
+	dataConn := tls.Client(conn, ftpTestTLSConfig)
+	var received []byte
+	if data != "" {
+		io.WriteString(dataConn, data)
+		dataConn.Close()
+	} else if received, err = ioutil.ReadAll(dataConn); err != nil {
+		c.t.Fatal(err)
+	}
+	code, _, err := c.conn.ReadResponse(0)
+	if err != nil && code == 0 {
+		c.t.Fatal(err)
+	}
+	return code, string(received)
+}
+
+func TestFTPServer(t *testing.T) {
+	_, _, cleanup := prepareGatewayTest(t, 0)
+	defer cleanup()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	certPEM, keyPEM, err := generateTLSCertKey("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go newFTPServer(&tls.Config{Certificates: []tls.Certificate{cert}}).Serve(l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := &ftpTestClient{t: t, conn: textproto.NewConn(conn)}
+	if _, _, err = c.conn.ReadResponse(220); err != nil {
+		t.Fatal(err)
+	}
+
+	// Logins are only accepted over TLS.
+	cred := globalServerConfig.GetCredential()
+	c.expect(530, "MKD /bucket")
+	c.expect(530, "USER %s", cred.AccessKey)
+	c.expect(503, "PROT P")
+	c.expect(504, "AUTH KERBEROS")
+	c.expect(234, "AUTH TLS")
+	c.conn = textproto.NewConn(tls.Client(conn, ftpTestTLSConfig))
+
+	c.expect(331, "USER %s", cred.AccessKey)
+	c.expect(530, "PASS invalid-secret-key")
+	c.expect(331, "USER %s", cred.AccessKey)
+	c.expect(230, "PASS %s", cred.SecretKey)
+
+	// Data connections must be protected.
+	if code, _ := c.transfer("", "NLST"); code != 521 {
+		t.Fatalf("Expected unprotected transfer to be refused, got %d", code)
+	}
+	c.expect(200, "PBSZ 0")
+	c.expect(536, "PROT C")
+	c.expect(200, "PROT P")
+
+	c.expect(257, "MKD /bucket")
+	c.expect(550, "MKD /bucket")
+	c.expect(250, "CWD bucket")
+	c.expect(257, "MKD dir")
+	c.expect(250, "CWD dir")
+	if msg := c.expect(257, "PWD"); !strings.HasPrefix(msg, `"/bucket/dir"`) {
+		t.Errorf("Unexpected current directory %s", msg)
+	}
+
+	if code, _ := c.transfer("hello world", "STOR file"); code != 226 {
+		t.Fatalf("Expected upload to succeed, got %d", code)
+	}
+	if msg := c.expect(213, "SIZE /bucket/dir/file"); msg != "11" {
+		t.Errorf("Expected size 11, got %s", msg)
+	}
+	c.expect(550, "SIZE /bucket/missing")
+
+	c.expect(250, "CDUP")
+	if code, data := c.transfer("", "NLST"); code != 226 || data != "dir\r\n" {
+		t.Errorf("Expected listing of `dir`, got %d %q", code, data)
+	}
+	if code, data := c.transfer("", "LIST -la dir"); code != 226 || !strings.HasPrefix(data, "-rw-r--r-- 1 minio minio") || !strings.HasSuffix(data, " file\r\n") {
+		t.Errorf("Unexpected long listing %d %q", code, data)
+	}
+
+	c.expect(350, "REST 6")
+	if code, data := c.transfer("", "RETR dir/file"); code != 226 || data != "world" {
+		t.Errorf("Expected `world`, got %d %q", code, data)
+	}
+	if code, data := c.transfer("", "RETR /bucket/dir/file"); code != 226 || data != "hello world" {
+		t.Errorf("Expected `hello world`, got %d %q", code, data)
+	}
+	if code, _ := c.transfer("", "RETR missing"); code != 550 {
+		t.Errorf("Expected missing file, got %d", code)
+	}
+
+	c.expect(350, "RNFR dir/file")
+	c.expect(250, "RNTO renamed")
+	c.expect(550, "DELE dir/file")
+	c.expect(550, "RMD /bucket")
+	c.expect(250, "DELE renamed")
+	c.expect(250, "RMD /bucket")
+	c.expect(502, "SITE CHMOD 777 file")
+	c.expect(221, "QUIT")
+}
@@ -0,0 +1,237 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+const (
+	// Environment variable selecting how a gateway serves SSE-C
+	// requests, either passthrough (default) or gateway.
+	gatewaySSEEnv = "MINIO_GATEWAY_SSE"
+
+	// SSE-C requests are passed through to backends encrypting
+	// objects with customer provided keys themselves.
+	gatewaySSEPassthrough = "passthrough"
+
+	// Objects of SSE-C requests are encrypted by the gateway before
+	// they are written to the backend.
+	gatewaySSEGateway = "gateway"
+)
+
+// GatewaySSECustomer is implemented by gateways whose backend encrypts
+// objects with customer provided keys (SSE-C) itself, the key is the
+// decoded 32 byte key of the request.
+type GatewaySSECustomer interface {
+	GetObjectWithCustomerKey(bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, key []byte) error
+	GetObjectInfoWithCustomerKey(bucket, object string, key []byte) (ObjectInfo, error)
+	PutObjectWithCustomerKey(bucket, object string, data *hash.Reader, metadata map[string]string, key []byte) (ObjectInfo, error)
+}
+
+// gatewaySSECustomerObjects - gateway object layer passing the key of
+// a SSE-C request through to the backend.
+type gatewaySSECustomerObjects struct {
+	ObjectLayer
+
+	backend GatewaySSECustomer
+	key     []byte
+}
+
+// newGatewaySSECustomerObjects - returns the object layer serving the
+// SSE-C request r with the gateway backend, if it supports SSE-C.
+func newGatewaySSECustomerObjects(objectAPI ObjectLayer, r *http.Request) (ObjectLayer, APIErrorCode) {
+	// Staged objects, e.g. in write-back mode, would be saved in
+	// plain text so only gateways implementing SSE-C are accepted.
+	sseBackend, ok := unwrapGatewayLayer(objectAPI).(GatewaySSECustomer)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	key, err := ParseSSECustomerRequest(r)
+	if err != nil {
+		return nil, toAPIErrorCode(err)
+	}
+	return &gatewaySSECustomerObjects{
+		ObjectLayer: objectAPI,
+		backend:     sseBackend,
+		key:         key,
+	}, ErrNone
+}
+
+// GetObject - reads an object encrypted with the customer key.
+func (s *gatewaySSECustomerObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer, etag string) error {
+	return s.backend.GetObjectWithCustomerKey(bucket, object, startOffset, length, writer, etag, s.key)
+}
+
+// GetObjectInfo - returns the info of an object encrypted with the
+// customer key.
+func (s *gatewaySSECustomerObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	return s.backend.GetObjectInfoWithCustomerKey(bucket, object, s.key)
+}
+
+// PutObject - creates an object encrypted with the customer key.
+func (s *gatewaySSECustomerObjects) PutObject(bucket, object string, data *hash.Reader, metadata map[string]string) (ObjectInfo, error) {
+	return s.backend.PutObjectWithCustomerKey(bucket, object, data, metadata, s.key)
+}
+
+// Backends only keep user defined metadata, so the sealed key of
+// objects encrypted by the gateway is saved under these keys.
+var gatewaySSEMetadataKeys = map[string]string{
+	ServerSideEncryptionIV:            "X-Amz-Meta-Minio-Sse-Iv",
+	ServerSideEncryptionSealAlgorithm: "X-Amz-Meta-Minio-Sse-Seal-Algorithm",
+	ServerSideEncryptionSealedKey:     "X-Amz-Meta-Minio-Sse-Sealed-Key",
+}
+
+// toGatewaySSEMetadata - returns the metadata saved on the backend,
+// clients cannot set the keys holding the sealed key.
+func toGatewaySSEMetadata(metadata map[string]string) map[string]string {
+	backendKeys := make(map[string]bool, len(gatewaySSEMetadataKeys))
+	for _, k := range gatewaySSEMetadataKeys {
+		backendKeys[k] = true
+	}
+
+	m := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if backendKeys[http.CanonicalHeaderKey(k)] {
+			continue
+		}
+		if backendKey, ok := gatewaySSEMetadataKeys[k]; ok {
+			k = backendKey
+		}
+		m[k] = v
+	}
+	return m
+}
+
+// fromGatewaySSEMetadata - returns the metadata of a backend object
+// with the sealed key under its reserved keys.
+func fromGatewaySSEMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return metadata
+	}
+	reservedKeys := make(map[string]string, len(gatewaySSEMetadataKeys))
+	for k, backendKey := range gatewaySSEMetadataKeys {
+		reservedKeys[backendKey] = k
+	}
+
+	m := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		// Backends may change the case of metadata keys.
+		if reservedKey, ok := reservedKeys[http.CanonicalHeaderKey(k)]; ok {
+			k = reservedKey
+		}
+		m[k] = v
+	}
+	return m
+}
+
+// gatewayEncryptionObjects - gateway object layer supporting SSE-C
+// requests. Objects are encrypted by the object handlers, their sealed
+// keys are saved in the metadata of the backend objects.
+type gatewayEncryptionObjects struct {
+	// Gateway backend.
+	ObjectLayer
+}
+
+// newGatewayEncryptionObjects - returns the backend with objects of
+// SSE-C requests encrypted by the gateway.
+func newGatewayEncryptionObjects(backend ObjectLayer) *gatewayEncryptionObjects {
+	return &gatewayEncryptionObjects{ObjectLayer: backend}
+}
+
+// ListObjects - lists objects of the backend.
+func (e *gatewayEncryptionObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	result, err := e.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	for i := range result.Objects {
+		result.Objects[i].UserDefined = fromGatewaySSEMetadata(result.Objects[i].UserDefined)
+	}
+	return result, err
+}
+
+// ListObjectsV2 - lists objects of the backend.
+func (e *gatewayEncryptionObjects) ListObjectsV2(bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (ListObjectsV2Info, error) {
+	result, err := e.ObjectLayer.ListObjectsV2(bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+	for i := range result.Objects {
+		result.Objects[i].UserDefined = fromGatewaySSEMetadata(result.Objects[i].UserDefined)
+	}
+	return result, err
+}
+
+// GetObjectInfo - returns the info of a backend object.
+func (e *gatewayEncryptionObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	objInfo, err := e.ObjectLayer.GetObjectInfo(bucket, object)
+	objInfo.UserDefined = fromGatewaySSEMetadata(objInfo.UserDefined)
+	return objInfo, err
+}
+
+// PutObject - creates an object on the backend. Objects which were
+// encrypted are removed again if the backend did not keep their
+// sealed key, they could not be decrypted otherwise.
+func (e *gatewayEncryptionObjects) PutObject(bucket, object string, data *hash.Reader, metadata map[string]string) (ObjectInfo, error) {
+	objInfo, err := e.ObjectLayer.PutObject(bucket, object, data, toGatewaySSEMetadata(metadata))
+	if err != nil {
+		return objInfo, err
+	}
+	objInfo.UserDefined = fromGatewaySSEMetadata(objInfo.UserDefined)
+
+	if _, ok := metadata[ServerSideEncryptionSealedKey]; !ok || objInfo.IsEncrypted() {
+		return objInfo, nil
+	}
+	// Backends may not return the metadata of created objects.
+	if objInfo, err = e.GetObjectInfo(bucket, object); err != nil {
+		return objInfo, err
+	}
+	if !objInfo.IsEncrypted() {
+		errorIf(e.ObjectLayer.DeleteObject(bucket, object), "Unable to remove %s/%s without its sealed key", bucket, object)
+		return ObjectInfo{}, errors.Trace(NotImplemented{})
+	}
+	return objInfo, nil
+}
+
+// CopyObject - copies an object on the backend.
+func (e *gatewayEncryptionObjects) CopyObject(srcBucket, srcObject, destBucket, destObject string, metadata map[string]string, srcETag string) (ObjectInfo, error) {
+	objInfo, err := e.ObjectLayer.CopyObject(srcBucket, srcObject, destBucket, destObject, toGatewaySSEMetadata(metadata), srcETag)
+	objInfo.UserDefined = fromGatewaySSEMetadata(objInfo.UserDefined)
+	return objInfo, err
+}
+
+// NewMultipartUpload - initiates a multipart upload on the backend.
+// Encrypted objects are uploaded in one piece only, whether the backend
+// kept their sealed key is checked when they are created.
+func (e *gatewayEncryptionObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	if _, ok := metadata[ServerSideEncryptionSealedKey]; ok {
+		return "", errors.Trace(NotImplemented{})
+	}
+	return e.ObjectLayer.NewMultipartUpload(bucket, object, toGatewaySSEMetadata(metadata))
+}
+
+// CompleteMultipartUpload - completes a multipart upload on the
+// backend.
+func (e *gatewayEncryptionObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []CompletePart) (ObjectInfo, error) {
+	objInfo, err := e.ObjectLayer.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+	objInfo.UserDefined = fromGatewaySSEMetadata(objInfo.UserDefined)
+	return objInfo, err
+}
+
+// IsEncryptionSupported returns whether server side encryption is applicable for this layer.
+func (e *gatewayEncryptionObjects) IsEncryptionSupported() bool {
+	return true
+}
@@ -0,0 +1,285 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// newSSECustomerTestRequest - returns a SSE-C request.
+func newSSECustomerTestRequest() *http.Request {
+	r := &http.Request{Header: http.Header{}}
+	r.Header.Set(SSECustomerAlgorithm, SSECustomerAlgorithmAES256)
+	r.Header.Set(SSECustomerKey, "MzJieXRlc2xvbmdzZWNyZXRrZXltdXN0cHJvdmlkZWQ=")
+	r.Header.Set(SSECustomerKeyMD5, "7PpPLAK26ONlVUGOWlusfg==")
+	return r
+}
+
+// prepareGatewayEncryptionTest - returns an FS backend with a bucket.
+func prepareGatewayEncryptionTest(t *testing.T) (ObjectLayer, func()) {
+	obj, _, cleanup := prepareGatewayTest(t, 0)
+	if err := obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return obj, cleanup
+}
+
+func TestGatewayEncryptionObjects(t *testing.T) {
+	defer func(flag bool) { globalIsSSL = flag }(globalIsSSL)
+	globalIsSSL = true
+
+	backend, cleanup := prepareGatewayEncryptionTest(t)
+	defer cleanup()
+	e := newGatewayEncryptionObjects(backend)
+	if !e.IsEncryptionSupported() {
+		t.Fatal("Expected encryption to be supported")
+	}
+
+	data := []byte("hello world")
+	metadata := map[string]string{
+		"X-Amz-Meta-Minio-Sse-Iv": "forged",
+		"X-Amz-Meta-Color":        "blue",
+	}
+	reader, err := EncryptRequest(bytes.NewReader(data), newSSECustomerTestRequest(), metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := (&ObjectInfo{Size: int64(len(data))}).EncryptedSize()
+	hashReader, err := hash.NewReader(reader, size, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.PutObject("bucket", "object", hashReader, metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sealed key is saved as user defined metadata.
+	backendInfo, err := backend.GetObjectInfo("bucket", "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backendInfo.UserDefined[ServerSideEncryptionSealedKey] != "" || backendInfo.UserDefined["X-Amz-Meta-Minio-Sse-Sealed-Key"] != metadata[ServerSideEncryptionSealedKey] {
+		t.Errorf("Unexpected backend metadata %v", backendInfo.UserDefined)
+	}
+	if backendInfo.UserDefined["X-Amz-Meta-Minio-Sse-Iv"] != metadata[ServerSideEncryptionIV] {
+		t.Errorf("Expected the IV set by clients to be ignored, got %v", backendInfo.UserDefined)
+	}
+
+	objInfo, err := e.GetObjectInfo("bucket", "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !objInfo.IsEncrypted() || objInfo.UserDefined["X-Amz-Meta-Color"] != "blue" {
+		t.Fatalf("Expected encrypted object, got %v", objInfo.UserDefined)
+	}
+	if apiErr, _ := DecryptObjectInfo(&objInfo, newSSECustomerTestRequest().Header); apiErr != ErrNone || objInfo.Size != int64(len(data)) {
+		t.Fatalf("Unexpected decrypted size %d, %v", objInfo.Size, apiErr)
+	}
+	var buf bytes.Buffer
+	writer, err := DecryptRequest(&buf, newSSECustomerTestRequest(), objInfo.UserDefined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = e.GetObject("bucket", "object", 0, objInfo.EncryptedSize(), writer, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Close(); err != nil || buf.String() != "hello world" {
+		t.Errorf("Expected `hello world`, got `%s`, %v", buf.String(), err)
+	}
+}
+
+func isNotImplemented(err error) bool {
+	_, ok := errors.Cause(err).(NotImplemented)
+	return ok
+}
+
+// gatewayEncryptionTestBackend - backend dropping the metadata of
+// objects.
+type gatewayEncryptionTestBackend struct {
+	ObjectLayer
+}
+
+func (b gatewayEncryptionTestBackend) PutObject(bucket, object string, data *hash.Reader, metadata map[string]string) (ObjectInfo, error) {
+	return b.ObjectLayer.PutObject(bucket, object, data, nil)
+}
+
+// Tests that encrypted objects are not kept by backends dropping
+// their sealed key.
+func TestGatewayEncryptionObjectsMetadataDropped(t *testing.T) {
+	backend, cleanup := prepareGatewayEncryptionTest(t)
+	defer cleanup()
+	e := newGatewayEncryptionObjects(gatewayEncryptionTestBackend{backend})
+
+	metadata := map[string]string{ServerSideEncryptionSealedKey: "sealed"}
+	_, err := e.PutObject("bucket", "object", mustGetHashReader(t, bytes.NewReader([]byte("data")), 4, "", ""), metadata)
+	if !isNotImplemented(err) {
+		t.Fatalf("Expected not implemented, got %v", err)
+	}
+	if _, err = backend.GetObjectInfo("bucket", "object"); !isErrObjectNotFound(err) {
+		t.Errorf("Expected object to be removed, got %v", err)
+	}
+
+	// Objects without sealed key are kept.
+	if _, err = e.PutObject("bucket", "object", mustGetHashReader(t, bytes.NewReader([]byte("data")), 4, "", ""), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests that multipart uploads don't save sealed keys.
+func TestGatewayEncryptionObjectsMultipart(t *testing.T) {
+	backend, cleanup := prepareGatewayEncryptionTest(t)
+	defer cleanup()
+	e := newGatewayEncryptionObjects(backend)
+
+	metadata := map[string]string{ServerSideEncryptionSealedKey: "sealed"}
+	if _, err := e.NewMultipartUpload("bucket", "object", metadata); !isNotImplemented(err) {
+		t.Fatalf("Expected not implemented, got %v", err)
+	}
+
+	// Clients cannot set the sealed key of multipart uploads.
+	uploadID, err := e.NewMultipartUpload("bucket", "object", map[string]string{"X-Amz-Meta-Minio-Sse-Sealed-Key": "forged"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pi, err := e.PutObjectPart("bucket", "object", uploadID, 1, mustGetHashReader(t, bytes.NewReader([]byte("data")), 4, "", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	objInfo, err := e.CompleteMultipartUpload("bucket", "object", uploadID, []CompletePart{{PartNumber: 1, ETag: pi.ETag}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.IsEncrypted() {
+		t.Errorf("Expected object not to be encrypted, got %v", objInfo.UserDefined)
+	}
+	if objInfo, err = e.GetObjectInfo("bucket", "object"); err != nil || objInfo.IsEncrypted() {
+		t.Errorf("Expected object not to be encrypted, got %v, %v", objInfo.UserDefined, err)
+	}
+}
+
+// Tests gateway-side encryption of objects staged in write-back mode.
+func TestGatewayEncryptionObjectsWriteBack(t *testing.T) {
+	defer func(flag bool) { globalIsSSL = flag }(globalIsSSL)
+	globalIsSSL = true
+
+	backend, stagingDirs, cleanup := prepareGatewayTest(t, 1)
+	defer cleanup()
+	if err := backend.MakeBucketWithLocation("bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	objAPI := wrapGatewayTestLayer(t, backend, stagingDirs[0])
+	defer objAPI.Shutdown()
+
+	data := []byte("hello world")
+	metadata := map[string]string{}
+	reader, err := EncryptRequest(bytes.NewReader(data), newSSECustomerTestRequest(), metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := (&ObjectInfo{Size: int64(len(data))}).EncryptedSize()
+	if _, err = objAPI.PutObject("bucket", "object", mustGetHashReader(t, reader, size, "", ""), metadata); err != nil {
+		t.Fatal(err)
+	}
+	waitForWriteBack(t, unwrapGatewayLayer(objAPI).(*writeBackObjects))
+
+	// The uploaded object keeps its sealed key on the backend.
+	backendInfo, err := backend.GetObjectInfo("bucket", "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backendInfo.UserDefined["X-Amz-Meta-Minio-Sse-Sealed-Key"] != metadata[ServerSideEncryptionSealedKey] {
+		t.Errorf("Unexpected backend metadata %v", backendInfo.UserDefined)
+	}
+	objInfo, err := objAPI.GetObjectInfo("bucket", "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apiErr, _ := DecryptObjectInfo(&objInfo, newSSECustomerTestRequest().Header); apiErr != ErrNone || objInfo.Size != int64(len(data)) {
+		t.Fatalf("Unexpected decrypted size %d, %v", objInfo.Size, apiErr)
+	}
+	var buf bytes.Buffer
+	writer, err := DecryptRequest(&buf, newSSECustomerTestRequest(), objInfo.UserDefined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = objAPI.GetObject("bucket", "object", 0, objInfo.EncryptedSize(), writer, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Close(); err != nil || buf.String() != "hello world" {
+		t.Errorf("Expected `hello world`, got `%s`, %v", buf.String(), err)
+	}
+}
+
+// gatewaySSECustomerTestBackend - backend supporting SSE-C, which
+// records the key of requests.
+type gatewaySSECustomerTestBackend struct {
+	ObjectLayer
+	key []byte
+}
+
+func (b *gatewaySSECustomerTestBackend) GetObjectWithCustomerKey(bucket, object string, startOffset int64, length int64, writer io.Writer, etag string, key []byte) error {
+	b.key = key
+	return b.GetObject(bucket, object, startOffset, length, writer, etag)
+}
+
+func (b *gatewaySSECustomerTestBackend) GetObjectInfoWithCustomerKey(bucket, object string, key []byte) (ObjectInfo, error) {
+	b.key = key
+	return b.GetObjectInfo(bucket, object)
+}
+
+func (b *gatewaySSECustomerTestBackend) PutObjectWithCustomerKey(bucket, object string, data *hash.Reader, metadata map[string]string, key []byte) (ObjectInfo, error) {
+	b.key = key
+	return b.PutObject(bucket, object, data, metadata)
+}
+
+func TestNewGatewaySSECustomerObjects(t *testing.T) {
+	defer func(flag bool) { globalIsSSL = flag }(globalIsSSL)
+	globalIsSSL = true
+
+	backend, cleanup := prepareGatewayEncryptionTest(t)
+	defer cleanup()
+
+	if _, apiErr := newGatewaySSECustomerObjects(backend, newSSECustomerTestRequest()); apiErr != ErrNotImplemented {
+		t.Errorf("Expected backend without SSE-C support to fail, got %v", apiErr)
+	}
+
+	sseBackend := &gatewaySSECustomerTestBackend{ObjectLayer: backend}
+	r := newSSECustomerTestRequest()
+	r.Header.Set(SSECustomerKeyMD5, "AAAAAAAAAAAAAAAAAAAAAA==")
+	if _, apiErr := newGatewaySSECustomerObjects(sseBackend, r); apiErr != ErrSSECustomerKeyMD5Mismatch {
+		t.Errorf("Expected invalid key to fail, got %v", apiErr)
+	}
+
+	// Gateways with notifications pass SSE-C requests through too.
+	objectAPI, apiErr := newGatewaySSECustomerObjects(&gatewayNotificationObjects{ObjectLayer: sseBackend}, newSSECustomerTestRequest())
+	if apiErr != ErrNone {
+		t.Fatalf("Expected SSE-C request to be passed through, got %v", apiErr)
+	}
+	if _, err := objectAPI.PutObject("bucket", "object", mustGetHashReader(t, bytes.NewReader([]byte("data")), 4, "", ""), nil); err != nil {
+		t.Fatal(err)
+	}
+	if string(sseBackend.key) != "32byteslongsecretkeymustprovided" {
+		t.Errorf("Unexpected key %q", sseBackend.key)
+	}
+}
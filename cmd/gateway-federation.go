@@ -0,0 +1,369 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/policy"
+	"github.com/minio/minio/pkg/auth"
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Types of federated backends in local directories, all other types
+// are gateways registered with RegisterGatewayCommand.
+const (
+	federationBackendFS = "fs"
+	federationBackendXL = "xl"
+)
+
+// federationBackendConfig - backend of a federated gateway.
+type federationBackendConfig struct {
+	// Backend type, "fs", "xl" or the name of a gateway, e.g. "s3".
+	Type string `json:"type"`
+
+	// Local directories of "fs" and "xl" backends.
+	Paths []string `json:"paths,omitempty"`
+
+	// Endpoint and credentials of gateway backends, Endpoint is the
+	// argument of the gateway command, e.g. the path of "nas".
+	Endpoint  string `json:"endpoint,omitempty"`
+	AccessKey string `json:"accessKey,omitempty"`
+	SecretKey string `json:"secretKey,omitempty"`
+}
+
+// federationRoute - routes buckets to a backend, Bucket is a bucket
+// name or a prefix of bucket names ending with '*', e.g. "logs-*".
+type federationRoute struct {
+	Bucket  string `json:"bucket"`
+	Backend string `json:"backend"`
+}
+
+// federationConfig - backends of the federated gateway by name and
+// the routes of buckets to them. Buckets without route are served by
+// the default backend.
+type federationConfig struct {
+	Backends map[string]federationBackendConfig `json:"backends"`
+	Routes   []federationRoute                  `json:"routes"`
+	Default  string                             `json:"default"`
+}
+
+// Validate - validates the federation configuration.
+func (f federationConfig) Validate() error {
+	for name, backend := range f.Backends {
+		switch backend.Type {
+		case "":
+			return fmt.Errorf("federated backend ‘%s’ has no type", name)
+		case federationBackendFS:
+			if len(backend.Paths) != 1 {
+				return fmt.Errorf("federated backend ‘%s’ needs one path", name)
+			}
+		case federationBackendXL:
+			if len(backend.Paths) < 2 {
+				return fmt.Errorf("federated backend ‘%s’ needs several paths", name)
+			}
+		}
+	}
+	if len(f.Backends) == 0 {
+		return nil
+	}
+	if _, ok := f.Backends[f.Default]; !ok {
+		return fmt.Errorf("unknown default federated backend ‘%s’", f.Default)
+	}
+	for i, route := range f.Routes {
+		if route.Bucket == "" || route.Bucket == "*" {
+			return fmt.Errorf("federation route %d has no bucket", i+1)
+		}
+		if _, ok := f.Backends[route.Backend]; !ok {
+			return fmt.Errorf("federation route %d refers to unknown backend ‘%s’", i+1, route.Backend)
+		}
+	}
+	return nil
+}
+
+// route - returns the name of the backend of bucket, exact routes take
+// precedence over the longest matching prefix.
+func (f federationConfig) route(bucket string) string {
+	backend, matched := f.Default, -1
+	for _, route := range f.Routes {
+		if route.Bucket == bucket {
+			return route.Backend
+		}
+		prefix := strings.TrimSuffix(route.Bucket, "*")
+		if prefix != route.Bucket && strings.HasPrefix(bucket, prefix) && len(prefix) > matched {
+			backend, matched = route.Backend, len(prefix)
+		}
+	}
+	return backend
+}
+
+// federatedObjects - gateway object layer serving the buckets of
+// several backends, each bucket is served by the backend it is routed
+// to.
+type federatedObjects struct {
+	GatewayUnsupported
+
+	config   federationConfig
+	backends map[string]ObjectLayer
+}
+
+// NewFederationLayer - returns the federated gateway object layer of
+// the backends in the federation configuration.
+func NewFederationLayer() (ObjectLayer, error) {
+	globalServerConfigMu.RLock()
+	config := globalServerConfig.Federation
+	globalServerConfigMu.RUnlock()
+	if len(config.Backends) == 0 {
+		return nil, fmt.Errorf("no federated backends configured")
+	}
+
+	f := &federatedObjects{
+		config:   config,
+		backends: make(map[string]ObjectLayer),
+	}
+	for name, backend := range config.Backends {
+		objLayer, err := newFederatedBackend(backend)
+		if err != nil {
+			f.Shutdown()
+			return nil, fmt.Errorf("Unable to initialize federated backend ‘%s’. %v", name, err)
+		}
+		f.backends[name] = objLayer
+	}
+	return f, nil
+}
+
+// newFederatedBackend - returns the ObjectLayer of a backend.
+func newFederatedBackend(backend federationBackendConfig) (ObjectLayer, error) {
+	switch backend.Type {
+	case federationBackendFS, federationBackendXL:
+		return newLocalObjectLayer(backend.Paths)
+	}
+	return newGatewayBackend(backend.Type, backend.Endpoint, auth.Credentials{
+		AccessKey: backend.AccessKey,
+		SecretKey: backend.SecretKey,
+	})
+}
+
+// backend - returns the backend serving bucket.
+func (f *federatedObjects) backend(bucket string) ObjectLayer {
+	return f.backends[f.config.route(bucket)]
+}
+
+// Shutdown - shuts down all backends.
+func (f *federatedObjects) Shutdown() error {
+	var err error
+	for name, objLayer := range f.backends {
+		if serr := objLayer.Shutdown(); serr != nil {
+			errorIf(serr, "Unable to shutdown federated backend %s", name)
+			err = serr
+		}
+	}
+	return err
+}
+
+// StorageInfo - returns the sum of the storage of all backends.
+func (f *federatedObjects) StorageInfo() (si StorageInfo) {
+	for _, objLayer := range f.backends {
+		info := objLayer.StorageInfo()
+		si.Total += info.Total
+		si.Free += info.Free
+	}
+	return si
+}
+
+// MakeBucketWithLocation - creates a bucket on its backend.
+func (f *federatedObjects) MakeBucketWithLocation(bucket, location string) error {
+	return f.backend(bucket).MakeBucketWithLocation(bucket, location)
+}
+
+// GetBucketInfo - returns the info of a bucket from its backend.
+func (f *federatedObjects) GetBucketInfo(bucket string) (BucketInfo, error) {
+	return f.backend(bucket).GetBucketInfo(bucket)
+}
+
+// ListBuckets - lists the buckets of all backends, buckets of a backend
+// which are routed to another backend are not listed.
+func (f *federatedObjects) ListBuckets() ([]BucketInfo, error) {
+	var buckets []BucketInfo
+	for name, objLayer := range f.backends {
+		backendBuckets, err := objLayer.ListBuckets()
+		if err != nil {
+			return nil, err
+		}
+		for _, bucket := range backendBuckets {
+			if f.config.route(bucket.Name) == name {
+				buckets = append(buckets, bucket)
+			}
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Name < buckets[j].Name
+	})
+	return buckets, nil
+}
+
+// DeleteBucket - deletes a bucket on its backend.
+func (f *federatedObjects) DeleteBucket(bucket string) error {
+	return f.backend(bucket).DeleteBucket(bucket)
+}
+
+// ListObjects - lists the objects of a bucket.
+func (f *federatedObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (ListObjectsInfo, error) {
+	return f.backend(bucket).ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+}
+
+// ListObjectsV2 - lists the objects of a bucket.
+func (f *federatedObjects) ListObjectsV2(bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (ListObjectsV2Info, error) {
+	return f.backend(bucket).ListObjectsV2(bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+}
+
+// GetObject - reads an object.
+func (f *federatedObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer, etag string) error {
+	return f.backend(bucket).GetObject(bucket, object, startOffset, length, writer, etag)
+}
+
+// GetObjectInfo - returns the info of an object.
+func (f *federatedObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	return f.backend(bucket).GetObjectInfo(bucket, object)
+}
+
+// PutObject - creates an object.
+func (f *federatedObjects) PutObject(bucket, object string, data *hash.Reader, metadata map[string]string) (ObjectInfo, error) {
+	return f.backend(bucket).PutObject(bucket, object, data, metadata)
+}
+
+// readObject - returns a reader streaming length bytes of an object
+// from startOffset on.
+func readObject(objLayer ObjectLayer, bucket, object string, startOffset, length int64, etag string) *io.PipeReader {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(objLayer.GetObject(bucket, object, startOffset, length, pw, etag))
+	}()
+	return pr
+}
+
+// CopyObject - copies an object, objects are streamed from the source
+// backend to the destination backend if they differ.
+func (f *federatedObjects) CopyObject(srcBucket, srcObject, destBucket, destObject string, metadata map[string]string, srcETag string) (ObjectInfo, error) {
+	src, dest := f.backend(srcBucket), f.backend(destBucket)
+	if src == dest {
+		return src.CopyObject(srcBucket, srcObject, destBucket, destObject, metadata, srcETag)
+	}
+
+	srcInfo, err := src.GetObjectInfo(srcBucket, srcObject)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	pr := readObject(src, srcBucket, srcObject, 0, srcInfo.Size, srcETag)
+	defer pr.Close()
+	hashReader, err := hash.NewReader(pr, srcInfo.Size, "", "")
+	if err != nil {
+		return ObjectInfo{}, errors.Trace(err)
+	}
+	return dest.PutObject(destBucket, destObject, hashReader, metadata)
+}
+
+// DeleteObject - deletes an object.
+func (f *federatedObjects) DeleteObject(bucket, object string) error {
+	return f.backend(bucket).DeleteObject(bucket, object)
+}
+
+// RestoreObject - restores a transitioned object.
+func (f *federatedObjects) RestoreObject(bucket, object string, days int) error {
+	return f.backend(bucket).RestoreObject(bucket, object, days)
+}
+
+// ListMultipartUploads - lists the multipart uploads of a bucket.
+func (f *federatedObjects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (ListMultipartsInfo, error) {
+	return f.backend(bucket).ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
+}
+
+// NewMultipartUpload - starts a multipart upload.
+func (f *federatedObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (string, error) {
+	return f.backend(bucket).NewMultipartUpload(bucket, object, metadata)
+}
+
+// CopyObjectPart - copies a part of an object, parts are streamed from
+// the source backend to the destination backend if they differ.
+func (f *federatedObjects) CopyObjectPart(srcBucket, srcObject, destBucket, destObject string, uploadID string, partID int, startOffset int64, length int64, metadata map[string]string, srcETag string) (PartInfo, error) {
+	src, dest := f.backend(srcBucket), f.backend(destBucket)
+	if src == dest {
+		return src.CopyObjectPart(srcBucket, srcObject, destBucket, destObject, uploadID, partID, startOffset, length, metadata, srcETag)
+	}
+
+	pr := readObject(src, srcBucket, srcObject, startOffset, length, srcETag)
+	defer pr.Close()
+	hashReader, err := hash.NewReader(pr, length, "", "")
+	if err != nil {
+		return PartInfo{}, errors.Trace(err)
+	}
+	return dest.PutObjectPart(destBucket, destObject, uploadID, partID, hashReader)
+}
+
+// PutObjectPart - uploads a part of a multipart upload.
+func (f *federatedObjects) PutObjectPart(bucket, object, uploadID string, partID int, data *hash.Reader) (PartInfo, error) {
+	return f.backend(bucket).PutObjectPart(bucket, object, uploadID, partID, data)
+}
+
+// ListObjectParts - lists the parts of a multipart upload.
+func (f *federatedObjects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (ListPartsInfo, error) {
+	return f.backend(bucket).ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+}
+
+// AbortMultipartUpload - aborts a multipart upload.
+func (f *federatedObjects) AbortMultipartUpload(bucket, object, uploadID string) error {
+	return f.backend(bucket).AbortMultipartUpload(bucket, object, uploadID)
+}
+
+// CompleteMultipartUpload - completes a multipart upload.
+func (f *federatedObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []CompletePart) (ObjectInfo, error) {
+	return f.backend(bucket).CompleteMultipartUpload(bucket, object, uploadID, uploadedParts)
+}
+
+// SetBucketPolicy - sets the policy of a bucket on its backend.
+func (f *federatedObjects) SetBucketPolicy(bucket string, policyInfo policy.BucketAccessPolicy) error {
+	return f.backend(bucket).SetBucketPolicy(bucket, policyInfo)
+}
+
+// GetBucketPolicy - returns the policy of a bucket from its backend.
+func (f *federatedObjects) GetBucketPolicy(bucket string) (policy.BucketAccessPolicy, error) {
+	return f.backend(bucket).GetBucketPolicy(bucket)
+}
+
+// RefreshBucketPolicy - refreshes the policy of a bucket.
+func (f *federatedObjects) RefreshBucketPolicy(bucket string) error {
+	return f.backend(bucket).RefreshBucketPolicy(bucket)
+}
+
+// DeleteBucketPolicy - deletes the policy of a bucket on its backend.
+func (f *federatedObjects) DeleteBucketPolicy(bucket string) error {
+	return f.backend(bucket).DeleteBucketPolicy(bucket)
+}
+
+// IsEncryptionSupported returns whether server side encryption is applicable for this layer.
+func (f *federatedObjects) IsEncryptionSupported() bool {
+	for _, objLayer := range f.backends {
+		if !objLayer.IsEncryptionSupported() {
+			return false
+		}
+	}
+	return true
+}
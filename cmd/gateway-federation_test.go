@@ -0,0 +1,231 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/auth"
+)
+
+func TestFederationConfigValidate(t *testing.T) {
+	backends := map[string]federationBackendConfig{
+		"local":  {Type: "fs", Paths: []string{"/data"}},
+		"remote": {Type: "s3", Endpoint: "https://s3.amazonaws.com"},
+	}
+	testCases := []struct {
+		config  federationConfig
+		success bool
+	}{
+		{federationConfig{}, true},
+		{federationConfig{Backends: backends, Default: "local"}, true},
+		{federationConfig{Backends: backends, Default: "local", Routes: []federationRoute{{"logs-*", "remote"}}}, true},
+		{federationConfig{Backends: backends}, false},
+		{federationConfig{Backends: backends, Default: "unknown"}, false},
+		{federationConfig{Backends: backends, Default: "local", Routes: []federationRoute{{"", "remote"}}}, false},
+		{federationConfig{Backends: backends, Default: "local", Routes: []federationRoute{{"logs", "unknown"}}}, false},
+		{federationConfig{Backends: map[string]federationBackendConfig{"local": {Paths: []string{"/data"}}}, Default: "local"}, false},
+		{federationConfig{Backends: map[string]federationBackendConfig{"local": {Type: "fs"}}, Default: "local"}, false},
+		{federationConfig{Backends: map[string]federationBackendConfig{"local": {Type: "xl", Paths: []string{"/data"}}}, Default: "local"}, false},
+	}
+	for i, testCase := range testCases {
+		if err := testCase.config.Validate(); (err == nil) != testCase.success {
+			t.Errorf("Test %d: Unexpected validation result %v", i+1, err)
+		}
+	}
+}
+
+func TestFederationConfigRoute(t *testing.T) {
+	config := federationConfig{
+		Default: "default",
+		Routes: []federationRoute{
+			{"logs-*", "logs"},
+			{"logs-archive-*", "archive"},
+			{"logs-current", "current"},
+		},
+	}
+	testCases := []struct {
+		bucket, backend string
+	}{
+		{"photos", "default"},
+		{"logs", "default"},
+		{"logs-2018", "logs"},
+		{"logs-archive-2017", "archive"},
+		{"logs-current", "current"},
+	}
+	for i, testCase := range testCases {
+		if backend := config.route(testCase.bucket); backend != testCase.backend {
+			t.Errorf("Test %d: Expected backend %s, got %s", i+1, testCase.backend, backend)
+		}
+	}
+}
+
+// prepareFederationTest - returns a federation of two FS backends,
+// buckets starting with remote- are routed to the remote backend.
+func prepareFederationTest(t *testing.T) (*federatedObjects, func()) {
+	local, dirs, cleanup := prepareGatewayTest(t, 1)
+	remote, err := newFSObjects(dirs[0], false)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	f := &federatedObjects{
+		config: federationConfig{
+			Default: "local",
+			Routes:  []federationRoute{{"remote-*", "remote"}},
+		},
+		backends: map[string]ObjectLayer{"local": local, "remote": remote},
+	}
+	return f, func() {
+		remote.Shutdown()
+		cleanup()
+	}
+}
+
+func TestFederatedObjects(t *testing.T) {
+	f, cleanup := prepareFederationTest(t)
+	defer cleanup()
+
+	for _, bucket := range []string{"remote-bucket", "bucket"} {
+		if err := f.MakeBucketWithLocation(bucket, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := f.backends["local"].GetBucketInfo("remote-bucket"); !isBucketNotFound(err) {
+		t.Errorf("Expected remote-bucket to be routed to the remote backend, got %v", err)
+	}
+
+	// Buckets of a backend routed elsewhere are not listed.
+	if err := f.backends["remote"].MakeBucketWithLocation("hidden", ""); err != nil {
+		t.Fatal(err)
+	}
+	buckets, err := f.ListBuckets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 2 || buckets[0].Name != "bucket" || buckets[1].Name != "remote-bucket" {
+		t.Errorf("Unexpected buckets %v", buckets)
+	}
+
+	data := []byte("hello world")
+	if _, err = f.PutObject("bucket", "object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Objects are streamed between backends.
+	metadata := map[string]string{"X-Amz-Meta-Color": "blue"}
+	if _, err = f.CopyObject("bucket", "object", "remote-bucket", "copy", metadata, ""); err != nil {
+		t.Fatal(err)
+	}
+	objInfo, err := f.backends["remote"].GetObjectInfo("remote-bucket", "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.Size != int64(len(data)) || objInfo.UserDefined["X-Amz-Meta-Color"] != "blue" {
+		t.Errorf("Unexpected copy %v", objInfo)
+	}
+	var buf bytes.Buffer
+	if err = f.GetObject("remote-bucket", "copy", 0, objInfo.Size, &buf, ""); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hello world" {
+		t.Errorf("Expected `hello world`, got `%s`", buf.String())
+	}
+
+	// Copies of missing objects fail.
+	if _, err = f.CopyObject("bucket", "missing", "remote-bucket", "copy", nil, ""); !isErrObjectNotFound(err) {
+		t.Errorf("Expected object not found, got %v", err)
+	}
+
+	// Parts are streamed between backends too.
+	uploadID, err := f.NewMultipartUpload("remote-bucket", "multipart", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partInfo, err := f.CopyObjectPart("bucket", "object", "remote-bucket", "multipart", uploadID, 1, 6, 5, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.CompleteMultipartUpload("remote-bucket", "multipart", uploadID, []CompletePart{{PartNumber: 1, ETag: partInfo.ETag}}); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err = f.GetObject("remote-bucket", "multipart", 0, 5, &buf, ""); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "world" {
+		t.Errorf("Expected `world`, got `%s`", buf.String())
+	}
+
+	if si := f.StorageInfo(); si.Total == 0 {
+		t.Errorf("Expected the storage of the backends, got %v", si)
+	}
+}
+
+// nasTestGateway - gateway command "nas" of the gateway packages.
+type nasTestGateway struct {
+	path string
+}
+
+func (g *nasTestGateway) Name() string { return "nastest" }
+
+func (g *nasTestGateway) NewGatewayLayer(creds auth.Credentials) (ObjectLayer, error) {
+	return NewNASObjectLayer(g.path)
+}
+
+func (g *nasTestGateway) Production() bool { return false }
+
+func TestNewFederatedBackend(t *testing.T) {
+	_, dirs, cleanup := prepareGatewayTest(t, 2)
+	defer cleanup()
+	err := RegisterGatewayCommand(cli.Command{Name: "nastest"}, func(arg string) Gateway {
+		return &nasTestGateway{arg}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		gatewaysMu.Lock()
+		delete(gateways, "nastest")
+		gatewaysMu.Unlock()
+	}()
+
+	if _, err = newFederatedBackend(federationBackendConfig{Type: "unknown"}); err == nil {
+		t.Error("Expected backend of unknown type to fail")
+	}
+
+	notifier, logger := globalEventNotifier, globalBucketAccessLogger
+	testCases := []federationBackendConfig{
+		{Type: federationBackendFS, Paths: dirs[:1]},
+		{Type: "nastest", Endpoint: dirs[1]},
+	}
+	for i, backend := range testCases {
+		objLayer, err := newFederatedBackend(backend)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if err = objLayer.MakeBucketWithLocation("bucket", ""); err != nil {
+			t.Errorf("Test %d: %v", i+1, err)
+		}
+		objLayer.Shutdown()
+		if globalEventNotifier != notifier || globalBucketAccessLogger != logger {
+			t.Errorf("Test %d: Expected the backend to leave the notifier and the logger of the server alone", i+1)
+		}
+	}
+}
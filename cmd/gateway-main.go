@@ -23,10 +23,12 @@ import (
 	"os/signal"
 	"runtime"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/gorilla/mux"
 	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/auth"
 	"github.com/minio/minio/pkg/errors"
 	miniohttp "github.com/minio/minio/pkg/http"
 )
@@ -38,15 +40,45 @@ var (
 		Flags:           append(serverFlags, globalFlags...),
 		HideHelpCommand: true,
 	}
+
+	// Gateways of the registered commands by name, used as backends
+	// of remote tiers and federated gateways.
+	gatewaysMu sync.RWMutex
+	gateways   = make(map[string]GatewayFn)
 )
 
-// RegisterGatewayCommand registers a new command for gateway.
-func RegisterGatewayCommand(cmd cli.Command) error {
+// GatewayFn - returns the gateway of a backend for the argument of its
+// command, e.g. the endpoint of an S3 backend or the path of a NAS
+// backend.
+type GatewayFn func(arg string) Gateway
+
+// RegisterGatewayCommand registers a new command for gateway, newGateway
+// returns the gateway for the argument of the command, it is nil for
+// gateways which can't be the backend of another gateway.
+func RegisterGatewayCommand(cmd cli.Command, newGateway GatewayFn) error {
 	cmd.Flags = append(append(cmd.Flags, append(cmd.Flags, serverFlags...)...), globalFlags...)
 	gatewayCmd.Subcommands = append(gatewayCmd.Subcommands, cmd)
+	if newGateway != nil {
+		gatewaysMu.Lock()
+		gateways[cmd.Name] = newGateway
+		gatewaysMu.Unlock()
+	}
 	return nil
 }
 
+// newGatewayBackend - returns the object layer of the registered
+// gateway name for the argument of its command and the credentials of
+// the backend.
+func newGatewayBackend(name, arg string, creds auth.Credentials) (ObjectLayer, error) {
+	gatewaysMu.RLock()
+	newGateway, ok := gateways[name]
+	gatewaysMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported type ‘%s’", name)
+	}
+	return newGateway(arg).NewGatewayLayer(creds)
+}
+
 // ParseGatewayEndpoint - Return endpoint.
 func ParseGatewayEndpoint(arg string) (endPoint string, secure bool, err error) {
 	schemeSpecified := len(strings.Split(arg, "://")) > 1
@@ -100,6 +132,70 @@ func ValidateGatewayArguments(serverAddr, endpointAddr string) error {
 	return nil
 }
 
+// gatewayLayerWrapper - implemented by gateway object layers adding a
+// feature on top of another gateway object layer and passing all its
+// other features through.
+type gatewayLayerWrapper interface {
+	unwrap() ObjectLayer
+}
+
+// unwrapGatewayLayer - returns the gateway object layer below the
+// wrappers passing its features through.
+func unwrapGatewayLayer(objAPI ObjectLayer) ObjectLayer {
+	for {
+		w, ok := objAPI.(gatewayLayerWrapper)
+		if !ok {
+			return objAPI
+		}
+		objAPI = w.unwrap()
+	}
+}
+
+// wrapGatewayLayer - adds the features enabled by environment
+// variables to a gateway object layer, gateway-side encryption first,
+// then write-back mode and bucket notifications last.
+func wrapGatewayLayer(newObject ObjectLayer) (ObjectLayer, error) {
+	// Encrypt the objects of SSE-C requests in the gateway when
+	// gateway-side encryption is enabled, SSE-C requests are passed
+	// through to the backend otherwise.
+	switch sse := os.Getenv(gatewaySSEEnv); sse {
+	case "", gatewaySSEPassthrough:
+	case gatewaySSEGateway:
+		if !newObject.IsEncryptionSupported() {
+			newObject = newGatewayEncryptionObjects(newObject)
+		}
+	default:
+		return nil, fmt.Errorf("Unknown value ‘%s’ in %s environment variable", sse, gatewaySSEEnv)
+	}
+
+	// Stage writes locally and upload them in the background when
+	// write-back mode is enabled.
+	if stagingDirs := os.Getenv(gatewayWriteBackEnv); stagingDirs != "" {
+		wb, err := newWriteBackObjects(newObject, strings.Split(stagingDirs, ","))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to initialize write-back staging area %s. %s", stagingDirs, err)
+		}
+		newObject = wb
+	}
+
+	// Initialize S3 peers, the gateway is its only peer.
+	initGlobalS3Peers(nil)
+
+	// Save bucket notification configs locally or in a bucket of
+	// the backend, unless the backend keeps them itself.
+	if newObject.IsNotificationSupported() {
+		if err := initFSNotifications(newObject); err != nil {
+			return nil, err
+		}
+		return newObject, nil
+	}
+	n, err := newGatewayNotificationObjects(newObject, os.Getenv(gatewayConfigBucketEnv))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to initialize bucket notifications. %s", err)
+	}
+	return n, nil
+}
+
 // StartGateway - handler for 'minio gateway <name>'.
 func StartGateway(ctx *cli.Context, gw Gateway) {
 	if gw == nil {
@@ -154,7 +250,7 @@ func StartGateway(ctx *cli.Context, gw Gateway) {
 
 	// Check and load SSL certificates.
 	var err error
-	globalPublicCerts, globalRootCAs, globalTLSCertificate, globalIsSSL, err = getSSLConfig()
+	globalPublicCerts, globalRootCAs, globalTLSCerts, globalIsSSL, err = getSSLConfig()
 	fatalIf(err, "Invalid SSL certificate file")
 
 	// Set system resources to maximum.
@@ -165,8 +261,14 @@ func StartGateway(ctx *cli.Context, gw Gateway) {
 	newObject, err := gw.NewGatewayLayer(globalServerConfig.GetCredential())
 	fatalIf(err, "Unable to initialize gateway layer")
 
+	newObject, err = wrapGatewayLayer(newObject)
+	fatalIf(err, "Unable to initialize gateway layer")
+
 	router := mux.NewRouter().SkipClean(true)
 
+	// Add Admin router.
+	registerGatewayAdminRouter(router)
+
 	// Register web router when its enabled.
 	if globalIsBrowserEnabled {
 		fatalIf(registerWebRouter(router), "Unable to configure web browser")
@@ -201,7 +303,7 @@ func StartGateway(ctx *cli.Context, gw Gateway) {
 		// Add new handlers here.
 	}
 
-	globalHTTPServer = miniohttp.NewServer([]string{gatewayAddr}, registerHandlers(router, handlerFns...), globalTLSCertificate)
+	globalHTTPServer = miniohttp.NewServer([]string{gatewayAddr}, registerHandlers(router, handlerFns...), startCertificatesWatch())
 
 	// Start server, automatically configures TLS if certs are available.
 	go func() {
@@ -215,6 +317,9 @@ func StartGateway(ctx *cli.Context, gw Gateway) {
 	globalObjectAPI = newObject
 	globalObjLayerMutex.Unlock()
 
+	// Start SFTP and FTP listeners once object layer is initialized.
+	startFileTransferServers(ctx)
+
 	// Prints the formatted startup message once object layer is initialized.
 	if !quietFlag {
 		mode := globalMinioModeGatewayPrefix + gatewayName
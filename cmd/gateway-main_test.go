@@ -17,21 +17,60 @@
 package cmd
 
 import (
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/auth"
 )
 
+// testGateway - gateway serving an existing ObjectLayer.
+type testGateway struct {
+	name     string
+	objLayer ObjectLayer
+}
+
+func (g *testGateway) Name() string { return g.name }
+
+func (g *testGateway) NewGatewayLayer(creds auth.Credentials) (ObjectLayer, error) {
+	return g.objLayer, nil
+}
+
+func (g *testGateway) Production() bool { return false }
+
 // Test RegisterGatewayCommand
 func TestRegisterGatewayCommand(t *testing.T) {
 	var err error
 
 	cmd := cli.Command{Name: "test"}
-	err = RegisterGatewayCommand(cmd)
+	err = RegisterGatewayCommand(cmd, nil)
 	if err != nil {
 		t.Errorf("RegisterGatewayCommand got unexpected error: %s", err)
 	}
+	if _, err = newGatewayBackend("test", "", auth.Credentials{}); err == nil {
+		t.Error("Expected gateway without backend to be unsupported")
+	}
+
+	var arg string
+	err = RegisterGatewayCommand(cli.Command{Name: "test-backend"}, func(a string) Gateway {
+		arg = a
+		return &testGateway{"test-backend", nil}
+	})
+	if err != nil {
+		t.Errorf("RegisterGatewayCommand got unexpected error: %s", err)
+	}
+	defer func() {
+		gatewaysMu.Lock()
+		delete(gateways, "test-backend")
+		gatewaysMu.Unlock()
+	}()
+	if _, err = newGatewayBackend("test-backend", "endpoint", auth.Credentials{}); err != nil {
+		t.Errorf("newGatewayBackend got unexpected error: %s", err)
+	}
+	if arg != "endpoint" {
+		t.Errorf("Expected gateway argument endpoint, got %s", arg)
+	}
 }
 
 // Test parseGatewayEndpoint
@@ -96,3 +135,55 @@ func TestValidateGatewayArguments(t *testing.T) {
 		}
 	}
 }
+
+// gatewayTestBackend - gateway backend without notification and
+// encryption support, left to the cleanup of the test on shutdown.
+type gatewayTestBackend struct {
+	ObjectLayer
+}
+
+func (b gatewayTestBackend) IsNotificationSupported() bool { return false }
+func (b gatewayTestBackend) IsEncryptionSupported() bool   { return false }
+func (b gatewayTestBackend) Shutdown() error               { return nil }
+
+// wrapGatewayTestLayer - wraps backend in the gateway features, with
+// gateway-side encryption and write-back mode staging in stagingDir.
+func wrapGatewayTestLayer(t *testing.T, backend ObjectLayer, stagingDir string) ObjectLayer {
+	os.Setenv(gatewaySSEEnv, gatewaySSEGateway)
+	os.Setenv(gatewayWriteBackEnv, stagingDir)
+	defer os.Unsetenv(gatewaySSEEnv)
+	defer os.Unsetenv(gatewayWriteBackEnv)
+
+	objAPI, err := wrapGatewayLayer(gatewayTestBackend{backend})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return objAPI
+}
+
+// Tests the order in which features are added to gateways.
+func TestWrapGatewayLayer(t *testing.T) {
+	obj, stagingDirs, cleanup := prepareGatewayTest(t, 1)
+	defer cleanup()
+
+	objAPI := wrapGatewayTestLayer(t, obj, stagingDirs[0])
+	defer objAPI.Shutdown()
+
+	n, ok := objAPI.(*gatewayNotificationObjects)
+	if !ok {
+		t.Fatalf("Expected notifications to be added last, got %T", objAPI)
+	}
+	wb, ok := unwrapGatewayLayer(objAPI).(*writeBackObjects)
+	if !ok || wb != n.ObjectLayer {
+		t.Fatalf("Expected write-back mode below notifications, got %T", n.ObjectLayer)
+	}
+	if _, ok = wb.ObjectLayer.(*gatewayEncryptionObjects); !ok {
+		t.Fatalf("Expected gateway-side encryption below write-back mode, got %T", wb.ObjectLayer)
+	}
+
+	os.Setenv(gatewaySSEEnv, "invalid")
+	defer os.Unsetenv(gatewaySSEEnv)
+	if _, err := wrapGatewayLayer(gatewayTestBackend{obj}); err == nil {
+		t.Error("Expected unknown encryption mode to fail")
+	}
+}
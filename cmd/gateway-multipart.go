@@ -0,0 +1,554 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// GatewayMultipartETag is the metadata key under which the S3 ETag of
+// an object completed by GatewayMultipart is passed to the backend.
+// Backends keeping metadata with their objects save it and report it
+// as the ETag of the object.
+const GatewayMultipartETag = ReservedMetadataPrefix + "Multipart-Etag"
+
+// GatewayMultipart implements multipart uploads for gateways whose
+// backend has no multipart API. Parts are staged in a local directory
+// and uploaded to the backend as a single object when the upload
+// completes. Gateways embed it instead of GatewayUnsupported.
+//
+// The staging directory is laid out like the multipart area of FS mode,
+// DIR/multipart/SHA256/UPLOADID/ holds fs.json with the metadata of the
+// upload and a file per part named PARTNUMBER.ETAG.
+type GatewayMultipart struct {
+	GatewayUnsupported
+
+	dir     string
+	backend ObjectLayer
+	nsMutex *nsLockMap
+}
+
+// NewGatewayMultipart - returns multipart uploads staged in dir and
+// uploaded to backend, stale uploads are removed in the background.
+func NewGatewayMultipart(dir string, backend ObjectLayer) (*GatewayMultipart, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	m := &GatewayMultipart{
+		dir:     filepath.ToSlash(dir),
+		backend: backend,
+		nsMutex: newNSLock(false),
+	}
+	for _, subDir := range []string{m.multipartDir(), m.tmpDir()} {
+		if err = mkdirAll(subDir, 0700); err != nil {
+			return nil, err
+		}
+	}
+	go m.cleanupStaleMultipartUploads(multipartCleanupInterval, multipartExpiry, globalServiceDoneCh)
+	return m, nil
+}
+
+// Returns DIR/multipart
+func (m *GatewayMultipart) multipartDir() string {
+	return pathJoin(m.dir, "multipart")
+}
+
+// Returns DIR/tmp
+func (m *GatewayMultipart) tmpDir() string {
+	return pathJoin(m.dir, "tmp")
+}
+
+// Returns DIR/multipart/SHA256
+func (m *GatewayMultipart) getMultipartSHADir(bucket, object string) string {
+	return pathJoin(m.multipartDir(), getSHA256Hash([]byte(pathJoin(bucket, object))))
+}
+
+// Returns DIR/multipart/SHA256/UPLOADID
+func (m *GatewayMultipart) getUploadIDDir(bucket, object, uploadID string) string {
+	return pathJoin(m.getMultipartSHADir(bucket, object), uploadID)
+}
+
+// Returns partNumber.etag
+func (m *GatewayMultipart) encodePartFile(partNumber int, etag string) string {
+	return fmt.Sprintf("%.5d.%s", partNumber, etag)
+}
+
+// Returns partNumber and etag
+func (m *GatewayMultipart) decodePartFile(name string) (partNumber int, etag string, err error) {
+	result := strings.Split(name, ".")
+	if len(result) != 2 {
+		return 0, "", errUnexpected
+	}
+	partNumber, err = strconv.Atoi(result[0])
+	if err != nil {
+		return 0, "", errUnexpected
+	}
+	return partNumber, result[1], nil
+}
+
+// Returns InvalidUploadID if the upload does not exist.
+func (m *GatewayMultipart) checkUploadIDExists(bucket, object, uploadID string) error {
+	_, err := fsStatFile(pathJoin(m.getUploadIDDir(bucket, object, uploadID), fsMetaJSONFile))
+	if err != nil {
+		if errors.Cause(err) == errFileNotFound || errors.Cause(err) == errFileAccessDenied {
+			return errors.Trace(InvalidUploadID{UploadID: uploadID})
+		}
+		return toObjectErr(err, bucket, object)
+	}
+	return nil
+}
+
+// ListMultipartUploads - lists all the uploadIDs for the specified object.
+// Prefix based listing is not supported.
+func (m *GatewayMultipart) ListMultipartUploads(bucket, object, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result ListMultipartsInfo, e error) {
+	if err := checkListMultipartArgs(bucket, object, keyMarker, uploadIDMarker, delimiter, m.backend); err != nil {
+		return result, toObjectErr(errors.Trace(err))
+	}
+
+	result.MaxUploads = maxUploads
+	result.KeyMarker = keyMarker
+	result.Prefix = object
+	result.Delimiter = delimiter
+	result.UploadIDMarker = uploadIDMarker
+
+	uploadIDs, err := readDir(m.getMultipartSHADir(bucket, object))
+	if err != nil {
+		if err == errFileNotFound {
+			return result, nil
+		}
+		return result, toObjectErr(errors.Trace(err))
+	}
+
+	var uploads []MultipartInfo
+	for _, uploadID := range uploadIDs {
+		uploadID = strings.TrimSuffix(uploadID, slashSeparator)
+		fi, err := fsStatFile(pathJoin(m.getUploadIDDir(bucket, object, uploadID), fsMetaJSONFile))
+		if err != nil {
+			// Completed or aborted meanwhile.
+			continue
+		}
+		uploads = append(uploads, MultipartInfo{
+			Object:    object,
+			UploadID:  uploadID,
+			Initiated: fi.ModTime(),
+		})
+	}
+	sort.Slice(uploads, func(i int, j int) bool {
+		return uploads[i].Initiated.Before(uploads[j].Initiated)
+	})
+
+	uploadIndex := 0
+	if uploadIDMarker != "" {
+		for uploadIndex < len(uploads) {
+			uploadIndex++
+			if uploads[uploadIndex-1].UploadID == uploadIDMarker {
+				break
+			}
+		}
+	}
+	for uploadIndex < len(uploads) && len(result.Uploads) < maxUploads {
+		result.Uploads = append(result.Uploads, uploads[uploadIndex])
+		uploadIndex++
+	}
+
+	result.IsTruncated = uploadIndex < len(uploads)
+	if result.IsTruncated {
+		result.NextKeyMarker = object
+		result.NextUploadIDMarker = result.Uploads[len(result.Uploads)-1].UploadID
+	}
+	return result, nil
+}
+
+// NewMultipartUpload - initializes a new multipart upload in the
+// staging directory, returns a unique id.
+func (m *GatewayMultipart) NewMultipartUpload(bucket, object string, meta map[string]string) (string, error) {
+	if err := checkNewMultipartArgs(bucket, object, m.backend); err != nil {
+		return "", toObjectErr(err, bucket)
+	}
+
+	uploadID := mustGetUUID()
+	uploadIDDir := m.getUploadIDDir(bucket, object, uploadID)
+	if err := mkdirAll(uploadIDDir, 0700); err != nil {
+		return "", errors.Trace(err)
+	}
+
+	fsMeta := newFSMetaV1()
+	fsMeta.Meta = meta
+	fsMetaBytes, err := json.Marshal(fsMeta)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if err = ioutil.WriteFile(pathJoin(uploadIDDir, fsMetaJSONFile), fsMetaBytes, 0600); err != nil {
+		return "", errors.Trace(err)
+	}
+	return uploadID, nil
+}
+
+// CopyObjectPart - similar to PutObjectPart but reads data from an
+// existing object on the backend.
+func (m *GatewayMultipart) CopyObjectPart(srcBucket, srcObject, dstBucket, dstObject, uploadID string, partID int,
+	startOffset int64, length int64, metadata map[string]string, srcEtag string) (pi PartInfo, e error) {
+
+	if err := checkBucketAndObjectNames(srcBucket, srcObject); err != nil {
+		return pi, toObjectErr(err)
+	}
+
+	// Initialize pipe.
+	pipeReader, pipeWriter := io.Pipe()
+
+	go func() {
+		if gerr := m.backend.GetObject(srcBucket, srcObject, startOffset, length, pipeWriter, srcEtag); gerr != nil {
+			errorIf(gerr, "Unable to read %s/%s.", srcBucket, srcObject)
+			pipeWriter.CloseWithError(gerr)
+			return
+		}
+		pipeWriter.Close() // Close writer explicitly signalling we wrote all data.
+	}()
+
+	hashReader, err := hash.NewReader(pipeReader, length, "", "")
+	if err != nil {
+		return pi, toObjectErr(err, dstBucket, dstObject)
+	}
+
+	partInfo, err := m.PutObjectPart(dstBucket, dstObject, uploadID, partID, hashReader)
+	if err != nil {
+		return pi, toObjectErr(err, dstBucket, dstObject)
+	}
+
+	// Explicitly close the reader.
+	pipeReader.Close()
+
+	return partInfo, nil
+}
+
+// PutObjectPart - writes the part to the staging directory, the part
+// is written to a temporary file first and renamed into the upload.
+func (m *GatewayMultipart) PutObjectPart(bucket, object, uploadID string, partID int, data *hash.Reader) (pi PartInfo, e error) {
+	// The bucket is verified when the upload is initiated, parts
+	// do not reach the backend.
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return pi, toObjectErr(err, bucket)
+	}
+
+	// Validate input data size and it can never be less than zero.
+	if data.Size() < 0 {
+		return pi, toObjectErr(errors.Trace(errInvalidArgument))
+	}
+
+	// Just check if the uploadID exists to avoid copy if it doesn't.
+	if err := m.checkUploadIDExists(bucket, object, uploadID); err != nil {
+		return pi, err
+	}
+
+	bufSize := int64(readSizeV1)
+	if size := data.Size(); size > 0 && bufSize > size {
+		bufSize = size
+	}
+	buf := make([]byte, bufSize)
+
+	tmpPartPath := pathJoin(m.tmpDir(), uploadID+"."+mustGetUUID()+"."+strconv.Itoa(partID))
+	bytesWritten, err := fsCreateFile(tmpPartPath, data, buf, data.Size())
+	if err != nil {
+		fsRemoveFile(tmpPartPath)
+		return pi, toObjectErr(err, bucket, object)
+	}
+	// Delete temporary part in case of failure, nothing is left to
+	// delete once it is renamed.
+	defer fsRemoveFile(tmpPartPath)
+
+	// Should return IncompleteBody{} error when reader has fewer
+	// bytes than specified in request header.
+	if bytesWritten < data.Size() {
+		return pi, errors.Trace(IncompleteBody{})
+	}
+
+	etag := hex.EncodeToString(data.MD5Current())
+	if etag == "" {
+		etag = GenETag()
+	}
+	uploadIDDir := m.getUploadIDDir(bucket, object, uploadID)
+	partPath := pathJoin(uploadIDDir, m.encodePartFile(partID, etag))
+
+	// Hold read lock on the upload while adding the part, so that it
+	// is not completed or aborted meanwhile.
+	uploadIDLock := m.nsMutex.NewNSLock(bucket, pathJoin(object, uploadID))
+	if err = uploadIDLock.GetRLock(globalObjectTimeout); err != nil {
+		return pi, err
+	}
+	defer uploadIDLock.RUnlock()
+
+	// The upload may have completed or aborted while the part was
+	// written.
+	if err = m.checkUploadIDExists(bucket, object, uploadID); err != nil {
+		return pi, err
+	}
+	if err = fsRenameFile(tmpPartPath, partPath); err != nil {
+		return pi, toObjectErr(err, bucket, object)
+	}
+
+	fi, err := fsStatFile(partPath)
+	if err != nil {
+		return pi, toObjectErr(err, bucket, object)
+	}
+	return PartInfo{
+		PartNumber:   partID,
+		LastModified: fi.ModTime(),
+		ETag:         etag,
+		Size:         fi.Size(),
+	}, nil
+}
+
+// ListObjectParts - lists the parts staged for the upload, starting
+// after partNumberMarker.
+func (m *GatewayMultipart) ListObjectParts(bucket, object, uploadID string, partNumberMarker, maxParts int) (result ListPartsInfo, e error) {
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return result, toObjectErr(err, bucket)
+	}
+	result.Bucket = bucket
+	result.Object = object
+	result.UploadID = uploadID
+	result.MaxParts = maxParts
+	result.PartNumberMarker = partNumberMarker
+
+	if err := m.checkUploadIDExists(bucket, object, uploadID); err != nil {
+		return result, err
+	}
+
+	uploadIDDir := m.getUploadIDDir(bucket, object, uploadID)
+	entries, err := readDir(uploadIDDir)
+	if err != nil {
+		return result, toObjectErr(errors.Trace(err), bucket)
+	}
+
+	// A part uploaded several times is listed with its latest
+	// contents.
+	partsMap := make(map[int]PartInfo)
+	for _, entry := range entries {
+		if entry == fsMetaJSONFile {
+			continue
+		}
+		partNumber, etag, err := m.decodePartFile(entry)
+		if err != nil {
+			return result, toObjectErr(errors.Trace(err))
+		}
+		fi, err := fsStatFile(pathJoin(uploadIDDir, entry))
+		if err != nil {
+			return result, toObjectErr(err)
+		}
+		if part, ok := partsMap[partNumber]; ok && part.LastModified.After(fi.ModTime()) {
+			continue
+		}
+		partsMap[partNumber] = PartInfo{
+			PartNumber:   partNumber,
+			LastModified: fi.ModTime(),
+			ETag:         etag,
+			Size:         fi.Size(),
+		}
+	}
+	var parts []PartInfo
+	for _, part := range partsMap {
+		if part.PartNumber > partNumberMarker {
+			parts = append(parts, part)
+		}
+	}
+	sort.Slice(parts, func(i int, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+
+	if len(parts) > maxParts {
+		parts = parts[:maxParts]
+		result.IsTruncated = true
+		if maxParts > 0 {
+			result.NextPartNumberMarker = parts[maxParts-1].PartNumber
+		}
+	}
+	result.Parts = parts
+	return result, nil
+}
+
+// CompleteMultipartUpload - uploads the parts to the backend as a
+// single object and removes the upload. The returned ETag is the S3
+// multipart ETag of the parts, it is saved with the object by backends
+// keeping metadata, others report their own ETag for the object
+// afterwards.
+func (m *GatewayMultipart) CompleteMultipartUpload(bucket string, object string, uploadID string, parts []CompletePart) (oi ObjectInfo, e error) {
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return oi, toObjectErr(err, bucket)
+	}
+
+	// Hold write lock on the upload, so that it is completed only
+	// once.
+	uploadIDLock := m.nsMutex.NewNSLock(bucket, pathJoin(object, uploadID))
+	if err := uploadIDLock.GetLock(globalObjectTimeout); err != nil {
+		return oi, err
+	}
+	defer uploadIDLock.Unlock()
+
+	if err := m.checkUploadIDExists(bucket, object, uploadID); err != nil {
+		return oi, err
+	}
+
+	// Calculate s3 compatible md5sum for complete multipart.
+	s3MD5, err := getCompleteMultipartMD5(parts)
+	if err != nil {
+		return oi, err
+	}
+
+	// Parts must be listed in ascending order.
+	for i := 1; i < len(parts); i++ {
+		if parts[i].PartNumber <= parts[i-1].PartNumber {
+			return oi, errors.Trace(InvalidPartOrder{})
+		}
+	}
+
+	// Validate all parts before uploading.
+	uploadIDDir := m.getUploadIDDir(bucket, object, uploadID)
+	var size int64
+	for i, part := range parts {
+		fi, err := fsStatFile(pathJoin(uploadIDDir, m.encodePartFile(part.PartNumber, part.ETag)))
+		if err != nil {
+			if errors.Cause(err) == errFileNotFound || errors.Cause(err) == errFileAccessDenied {
+				return oi, errors.Trace(InvalidPart{})
+			}
+			return oi, errors.Trace(err)
+		}
+		size += fi.Size()
+
+		// All parts except the last part has to be atleast 5MB.
+		if i < len(parts)-1 && !isMinAllowedPartSize(fi.Size()) {
+			return oi, errors.Trace(PartTooSmall{
+				PartNumber: part.PartNumber,
+				PartSize:   fi.Size(),
+				PartETag:   part.ETag,
+			})
+		}
+	}
+
+	fsMeta := fsMetaV1{}
+	fsMetaBuf, err := ioutil.ReadFile(pathJoin(uploadIDDir, fsMetaJSONFile))
+	if err != nil {
+		return oi, toObjectErr(errors.Trace(err), bucket, object)
+	}
+	if err = json.Unmarshal(fsMetaBuf, &fsMeta); err != nil {
+		return oi, toObjectErr(errors.Trace(err), bucket, object)
+	}
+
+	// Stream the parts in order to the backend.
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeReader.Close()
+
+	go func() {
+		for _, part := range parts {
+			reader, _, perr := fsOpenFile(pathJoin(uploadIDDir, m.encodePartFile(part.PartNumber, part.ETag)), 0)
+			if perr != nil {
+				pipeWriter.CloseWithError(perr)
+				return
+			}
+			_, perr = io.Copy(pipeWriter, reader)
+			reader.Close()
+			if perr != nil {
+				pipeWriter.CloseWithError(perr)
+				return
+			}
+		}
+		pipeWriter.Close() // Close writer explicitly signalling we wrote all data.
+	}()
+
+	hashReader, err := hash.NewReader(pipeReader, size, "", "")
+	if err != nil {
+		return oi, toObjectErr(err, bucket, object)
+	}
+	metadata := make(map[string]string, len(fsMeta.Meta)+1)
+	for k, v := range fsMeta.Meta {
+		metadata[k] = v
+	}
+	metadata[GatewayMultipartETag] = s3MD5
+	if oi, err = m.backend.PutObject(bucket, object, hashReader, metadata); err != nil {
+		return oi, toObjectErr(err, bucket, object)
+	}
+
+	fsRemoveAll(uploadIDDir)
+	oi.ETag = s3MD5
+	return oi, nil
+}
+
+// AbortMultipartUpload - removes the upload and its staged parts.
+func (m *GatewayMultipart) AbortMultipartUpload(bucket, object, uploadID string) error {
+	if err := checkBucketAndObjectNames(bucket, object); err != nil {
+		return toObjectErr(err, bucket)
+	}
+
+	uploadIDLock := m.nsMutex.NewNSLock(bucket, pathJoin(object, uploadID))
+	if err := uploadIDLock.GetLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	defer uploadIDLock.Unlock()
+
+	if err := m.checkUploadIDExists(bucket, object, uploadID); err != nil {
+		return err
+	}
+	return fsRemoveAll(m.getUploadIDDir(bucket, object, uploadID))
+}
+
+// Removes multipart uploads older than `expiry` duration every
+// `cleanupInterval`, this function is blocking and should be run in a
+// go-routine.
+func (m *GatewayMultipart) cleanupStaleMultipartUploads(cleanupInterval, expiry time.Duration, doneCh chan struct{}) {
+	ticker := time.NewTicker(cleanupInterval)
+	for {
+		select {
+		case <-doneCh:
+			// Stop the timer.
+			ticker.Stop()
+			return
+		case <-ticker.C:
+			now := time.Now()
+			entries, err := readDir(m.multipartDir())
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				uploadIDs, err := readDir(pathJoin(m.multipartDir(), entry))
+				if err != nil {
+					continue
+				}
+				for _, uploadID := range uploadIDs {
+					fi, err := fsStatDir(pathJoin(m.multipartDir(), entry, uploadID))
+					if err != nil {
+						continue
+					}
+					if now.Sub(fi.ModTime()) > expiry {
+						fsRemoveAll(pathJoin(m.multipartDir(), entry, uploadID))
+					}
+				}
+			}
+		}
+	}
+}
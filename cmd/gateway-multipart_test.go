@@ -0,0 +1,174 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/errors"
+)
+
+func isInvalidPartOrder(err error) bool {
+	_, ok := errors.Cause(err).(InvalidPartOrder)
+	return ok
+}
+
+// newGatewayMultipartTest - returns multipart uploads staged in the
+// given directory for an FS backend with a bucket.
+func newGatewayMultipartTest(t *testing.T, obj ObjectLayer, stagingDir string) *GatewayMultipart {
+	if err := obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewGatewayMultipart(stagingDir, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestGatewayMultipart(t *testing.T) {
+	obj, stagingDirs, cleanup := prepareGatewayTest(t, 1)
+	defer cleanup()
+	m := newGatewayMultipartTest(t, obj, stagingDirs[0])
+
+	if _, err := m.NewMultipartUpload("missing", "object", nil); !isBucketNotFound(err) {
+		t.Fatalf("Expected missing bucket, got %v", err)
+	}
+	uploadID, err := m.NewMultipartUpload("bucket", "object", map[string]string{"content-type": "text/plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	part1 := bytes.Repeat([]byte("a"), globalMinPartSize)
+	part2 := []byte("hello world")
+	var parts []CompletePart
+	for i, part := range []struct {
+		partID int
+		data   []byte
+	}{{1, part1}, {2, []byte("replaced")}, {2, part2}} {
+		pi, perr := m.PutObjectPart("bucket", "object", uploadID, part.partID, mustGetHashReader(t, bytes.NewReader(part.data), int64(len(part.data)), "", ""))
+		if perr != nil {
+			t.Fatal(perr)
+		}
+		if pi.PartNumber != part.partID || pi.Size != int64(len(part.data)) || pi.ETag != getMD5Hash(part.data) {
+			t.Errorf("Unexpected part %+v", pi)
+		}
+		if i != 1 {
+			parts = append(parts, CompletePart{PartNumber: pi.PartNumber, ETag: pi.ETag})
+		}
+		// Parts uploaded again are listed with their latest contents.
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err = m.PutObjectPart("bucket", "object", "invalid", 1, mustGetHashReader(t, bytes.NewReader(part2), int64(len(part2)), "", "")); err == nil {
+		t.Error("Expected invalid upload ID to fail")
+	}
+
+	listParts, err := m.ListObjectParts("bucket", "object", uploadID, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listParts.Parts) != 1 || !listParts.IsTruncated || listParts.NextPartNumberMarker != 1 {
+		t.Errorf("Unexpected parts %+v", listParts)
+	}
+	if listParts, err = m.ListObjectParts("bucket", "object", uploadID, 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	if len(listParts.Parts) != 1 || listParts.IsTruncated || listParts.Parts[0].ETag != parts[1].ETag || listParts.Parts[0].Size != int64(len(part2)) {
+		t.Errorf("Unexpected parts %+v", listParts)
+	}
+
+	uploads, err := m.ListMultipartUploads("bucket", "object", "", "", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads.Uploads) != 1 || uploads.Uploads[0].UploadID != uploadID {
+		t.Errorf("Unexpected uploads %+v", uploads)
+	}
+
+	if _, err = m.CompleteMultipartUpload("bucket", "object", uploadID, []CompletePart{{PartNumber: 3, ETag: parts[1].ETag}}); err == nil {
+		t.Error("Expected invalid part to fail")
+	}
+	for _, unordered := range [][]CompletePart{{parts[1], parts[0]}, {parts[0], parts[0], parts[1]}} {
+		if _, err = m.CompleteMultipartUpload("bucket", "object", uploadID, unordered); !isInvalidPartOrder(err) {
+			t.Errorf("Expected InvalidPartOrder, got %v", err)
+		}
+	}
+	pi, err := m.PutObjectPart("bucket", "object", uploadID, 3, mustGetHashReader(t, bytes.NewReader(part2), int64(len(part2)), "", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.CompleteMultipartUpload("bucket", "object", uploadID, []CompletePart{parts[1], {PartNumber: 3, ETag: pi.ETag}}); err == nil {
+		t.Error("Expected part smaller than 5MiB to fail")
+	}
+
+	objInfo, err := m.CompleteMultipartUpload("bucket", "object", uploadID, parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3MD5, err := getCompleteMultipartMD5(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.ETag != s3MD5 || objInfo.Size != int64(len(part1)+len(part2)) || objInfo.ContentType != "text/plain" {
+		t.Errorf("Unexpected object %+v", objInfo)
+	}
+	// The multipart ETag is passed on to the backend.
+	if objInfo, err = obj.GetObjectInfo("bucket", "object"); err != nil || objInfo.UserDefined[GatewayMultipartETag] != s3MD5 {
+		t.Errorf("Expected the multipart ETag to be saved with the object, got %v, %v", objInfo.UserDefined, err)
+	}
+	var buf bytes.Buffer
+	if err = obj.GetObject("bucket", "object", int64(len(part1)), int64(len(part2)), &buf, ""); err != nil || buf.String() != "hello world" {
+		t.Errorf("Expected `hello world`, got `%s`, %v", buf.String(), err)
+	}
+	if _, err = m.ListObjectParts("bucket", "object", uploadID, 0, 10); err == nil {
+		t.Error("Expected completed upload to be removed")
+	}
+
+	if uploadID, err = m.NewMultipartUpload("bucket", "object", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.AbortMultipartUpload("bucket", "object", uploadID); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.AbortMultipartUpload("bucket", "object", uploadID); err == nil {
+		t.Error("Expected aborted upload to be removed")
+	}
+}
+
+// Tests cleanup of stale multipart uploads staged by gateways.
+func TestGatewayMultipartCleanup(t *testing.T) {
+	obj, stagingDirs, cleanup := prepareGatewayTest(t, 1)
+	defer cleanup()
+	m := newGatewayMultipartTest(t, obj, stagingDirs[0])
+
+	uploadID, err := m.NewMultipartUpload("bucket", "object", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doneCh := make(chan struct{})
+	go m.cleanupStaleMultipartUploads(20*time.Millisecond, 0, doneCh)
+	time.Sleep(40 * time.Millisecond)
+	close(doneCh)
+
+	err = m.AbortMultipartUpload("bucket", "object", uploadID)
+	if _, ok := errors.Cause(err).(InvalidUploadID); !ok {
+		t.Fatalf("Expected upload to be removed, got %v", err)
+	}
+}
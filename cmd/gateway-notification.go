@@ -0,0 +1,207 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Environment variable naming a bucket on the gateway backend which
+// holds the bucket notification configs, they are kept in the local
+// config directory otherwise.
+const gatewayConfigBucketEnv = "MINIO_GATEWAY_CONFIG_BUCKET"
+
+// gatewayNotificationObjects - gateway object layer with bucket
+// notifications. Gateway backends cannot store the configs saved in
+// the '.minio.sys' meta bucket, such objects are kept in the local
+// config directory or in a bucket of the backend instead. All other
+// operations are passed on to the backend.
+type gatewayNotificationObjects struct {
+	// Gateway backend.
+	ObjectLayer
+
+	// Directory holding the meta bucket, unless configBucket is set.
+	configDir    string
+	configBucket string
+}
+
+// newGatewayNotificationObjects - returns the backend with bucket
+// notifications, configs are saved in configBucket on the backend if
+// set and in the local config directory otherwise.
+func newGatewayNotificationObjects(backend ObjectLayer, configBucket string) (*gatewayNotificationObjects, error) {
+	n := &gatewayNotificationObjects{
+		ObjectLayer:  backend,
+		configBucket: configBucket,
+	}
+	if configBucket != "" {
+		err := backend.MakeBucketWithLocation(configBucket, globalServerConfig.GetRegion())
+		switch errors.Cause(err).(type) {
+		case nil, BucketAlreadyOwnedByYou, BucketExists:
+		default:
+			return nil, err
+		}
+	} else {
+		n.configDir = filepath.ToSlash(filepath.Join(getConfigDir(), gatewayConfigDir))
+		if err := mkdirAll(n.configDir, 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize a new event notifier.
+	if err := initEventNotifier(n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// unwrap - returns the backend, whose other features are passed
+// through.
+func (n *gatewayNotificationObjects) unwrap() ObjectLayer {
+	return n.ObjectLayer
+}
+
+// ListBuckets - lists the buckets of the backend, without the bucket
+// holding the configs.
+func (n *gatewayNotificationObjects) ListBuckets() ([]BucketInfo, error) {
+	buckets, err := n.ObjectLayer.ListBuckets()
+	if err != nil || n.configBucket == "" {
+		return buckets, err
+	}
+	var result []BucketInfo
+	for _, bucket := range buckets {
+		if bucket.Name != n.configBucket {
+			result = append(result, bucket)
+		}
+	}
+	return result, nil
+}
+
+// DeleteBucket - deletes the bucket on the backend and its
+// notification config.
+func (n *gatewayNotificationObjects) DeleteBucket(bucket string) error {
+	if err := n.ObjectLayer.DeleteBucket(bucket); err != nil {
+		return err
+	}
+
+	// Delete notification config, if present - ignore any errors.
+	_ = removeNotificationConfig(bucket, n)
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketNotification(bucket, nil)
+	return nil
+}
+
+// GetObject - reads objects of the meta bucket from the configs,
+// other objects from the backend.
+func (n *gatewayNotificationObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer, etag string) error {
+	if bucket != minioMetaBucket {
+		return n.ObjectLayer.GetObject(bucket, object, startOffset, length, writer, etag)
+	}
+	if n.configBucket != "" {
+		return n.ObjectLayer.GetObject(n.configBucket, object, startOffset, length, writer, etag)
+	}
+
+	data, err := ioutil.ReadFile(pathJoin(n.configDir, object))
+	if err != nil {
+		return toObjectErr(osErrToFSFileErr(err), bucket, object)
+	}
+	if length < 0 {
+		length = int64(len(data)) - startOffset
+	}
+	if startOffset < 0 || length < 0 || startOffset+length > int64(len(data)) {
+		return errors.Trace(InvalidRange{startOffset, length, int64(len(data))})
+	}
+	_, err = writer.Write(data[startOffset : startOffset+length])
+	return errors.Trace(err)
+}
+
+// GetObjectInfo - returns the info of objects of the meta bucket from
+// the configs, of other objects from the backend.
+func (n *gatewayNotificationObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	if bucket != minioMetaBucket {
+		return n.ObjectLayer.GetObjectInfo(bucket, object)
+	}
+	if n.configBucket != "" {
+		return n.ObjectLayer.GetObjectInfo(n.configBucket, object)
+	}
+
+	fi, err := fsStatFile(pathJoin(n.configDir, object))
+	if err != nil {
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	return ObjectInfo{
+		Bucket:  bucket,
+		Name:    object,
+		ModTime: fi.ModTime(),
+		Size:    fi.Size(),
+	}, nil
+}
+
+// PutObject - saves objects of the meta bucket with the configs, other
+// objects on the backend.
+func (n *gatewayNotificationObjects) PutObject(bucket, object string, data *hash.Reader, metadata map[string]string) (ObjectInfo, error) {
+	if bucket != minioMetaBucket {
+		return n.ObjectLayer.PutObject(bucket, object, data, metadata)
+	}
+	if n.configBucket != "" {
+		return n.ObjectLayer.PutObject(n.configBucket, object, data, metadata)
+	}
+
+	// Write to a temporary file first, so that readers never see a
+	// partial config.
+	filePath := pathJoin(n.configDir, object)
+	tmpPath := filePath + "." + mustGetUUID()
+	if _, err := fsCreateFile(tmpPath, data, nil, data.Size()); err != nil {
+		fsRemoveFile(tmpPath)
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	if err := fsRenameFile(tmpPath, filePath); err != nil {
+		fsRemoveFile(tmpPath)
+		return ObjectInfo{}, toObjectErr(err, bucket, object)
+	}
+	return n.GetObjectInfo(bucket, object)
+}
+
+// DeleteObject - deletes objects of the meta bucket from the configs,
+// other objects from the backend.
+func (n *gatewayNotificationObjects) DeleteObject(bucket, object string) error {
+	if bucket != minioMetaBucket {
+		return n.ObjectLayer.DeleteObject(bucket, object)
+	}
+	if n.configBucket != "" {
+		return n.ObjectLayer.DeleteObject(n.configBucket, object)
+	}
+
+	err := fsRemoveFile(pathJoin(n.configDir, object))
+	if err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	// Remove the bucket directory once its last config is gone.
+	os.Remove(filepath.Dir(pathJoin(n.configDir, object)))
+	return nil
+}
+
+// IsNotificationSupported returns whether bucket notification is applicable for this layer.
+func (n *gatewayNotificationObjects) IsNotificationSupported() bool {
+	return true
+}
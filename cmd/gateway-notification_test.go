@@ -0,0 +1,117 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+// prepareGatewayNotificationTest - returns an FS backend with a bucket,
+// the local gateway configs are saved in the config directory.
+func prepareGatewayNotificationTest(t *testing.T) (ObjectLayer, func()) {
+	obj, _, cleanup := prepareGatewayTest(t, 0)
+	if err := obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	initNSLock(false)
+	initGlobalS3Peers(nil)
+	return obj, cleanup
+}
+
+func TestGatewayNotificationObjects(t *testing.T) {
+	backend, cleanup := prepareGatewayNotificationTest(t)
+	defer cleanup()
+
+	n, err := newGatewayNotificationObjects(backend, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !n.IsNotificationSupported() {
+		t.Fatal("Expected notifications to be supported")
+	}
+
+	ncfg := &notificationConfig{
+		TopicConfigs: []topicConfig{{
+			ServiceConfig: ServiceConfig{Events: []string{"s3:ObjectCreated:*"}, ID: "1"},
+			TopicARN:      "arn:minio:sns:us-east-1:1:listen",
+		}},
+	}
+	if err = PutBucketNotificationConfig("bucket", ncfg, n); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(getConfigDir(), gatewayConfigDir, bucketConfigPrefix, "bucket", bucketNotificationConfig)
+	if _, err = os.Stat(configFile); err != nil {
+		t.Fatalf("Expected config to be saved locally, got %v", err)
+	}
+	if _, err = backend.GetObjectInfo(minioMetaBucket, path.Join(bucketConfigPrefix, "bucket", bucketNotificationConfig)); !isErrObjectNotFound(err) {
+		t.Errorf("Expected config not to be saved on the backend, got %v", err)
+	}
+
+	// Configs are loaded when the gateway restarts.
+	globalEventNotifier = nil
+	if n, err = newGatewayNotificationObjects(backend, ""); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := globalEventNotifier.GetBucketNotificationConfig("bucket"); cfg == nil || len(cfg.TopicConfigs) != 1 {
+		t.Fatalf("Expected config to be loaded, got %v", cfg)
+	}
+
+	if err = n.DeleteBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(configFile); !os.IsNotExist(err) {
+		t.Errorf("Expected config to be removed, got %v", err)
+	}
+	if cfg := globalEventNotifier.GetBucketNotificationConfig("bucket"); cfg != nil {
+		t.Errorf("Expected config to be removed, got %v", cfg)
+	}
+}
+
+// Tests configs saved in a bucket of the backend.
+func TestGatewayNotificationObjectsConfigBucket(t *testing.T) {
+	backend, cleanup := prepareGatewayNotificationTest(t)
+	defer cleanup()
+
+	n, err := newGatewayNotificationObjects(backend, "minio-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newGatewayNotificationObjects(backend, "minio-config"); err != nil {
+		t.Fatalf("Expected existing config bucket to be reused, got %v", err)
+	}
+	buckets, err := n.ListBuckets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 1 || buckets[0].Name != "bucket" {
+		t.Errorf("Expected config bucket to be hidden, got %v", buckets)
+	}
+
+	if err = PutBucketNotificationConfig("bucket", &notificationConfig{}, n); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = backend.GetObjectInfo("minio-config", path.Join(bucketConfigPrefix, "bucket", bucketNotificationConfig)); err != nil {
+		t.Errorf("Expected config to be saved in the config bucket, got %v", err)
+	}
+	if _, err = loadNotificationConfig("bucket", n); err != nil {
+		t.Errorf("Expected config to be loaded, got %v", err)
+	}
+}
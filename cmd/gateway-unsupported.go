@@ -116,6 +116,11 @@ func (a GatewayUnsupported) ListLocks(bucket, prefix string, duration time.Durat
 	return []VolumeLockInfo{}, errors.Trace(NotImplemented{})
 }
 
+// RestoreObject restores a transitioned object
+func (a GatewayUnsupported) RestoreObject(bucket, object string, days int) error {
+	return errors.Trace(NotImplemented{})
+}
+
 // ClearLocks clears namespace locks held in object layer
 func (a GatewayUnsupported) ClearLocks([]VolumeLockInfo) error {
 	return errors.Trace(NotImplemented{})
@@ -135,3 +140,13 @@ func (a GatewayUnsupported) IsNotificationSupported() bool {
 func (a GatewayUnsupported) IsEncryptionSupported() bool {
 	return false
 }
+
+// IsACLSupported returns whether canned ACLs are applicable for this layer.
+func (a GatewayUnsupported) IsACLSupported() bool {
+	return false
+}
+
+// IsLoggingSupported returns whether bucket access logging is applicable for this layer.
+func (a GatewayUnsupported) IsLoggingSupported() bool {
+	return false
+}
@@ -0,0 +1,811 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+	"github.com/minio/minio/pkg/madmin"
+)
+
+const (
+	// Environment variable listing the local directories staging the
+	// writes of a gateway in write-back mode, separated by commas.
+	gatewayWriteBackEnv = "MINIO_GATEWAY_WRITEBACK"
+
+	// Number of objects uploaded to the gateway backend at once.
+	writeBackUploaders = 4
+
+	// Failed uploads are retried with an exponential backoff, up to
+	// the maximum delay.
+	writeBackRetryDelay    = time.Second
+	writeBackMaxRetryDelay = 5 * time.Minute
+)
+
+// writeBackEntry - an object staged locally and not yet uploaded
+// to the gateway backend.
+type writeBackEntry struct {
+	bucket, object string
+	etag           string
+	size           int64
+	queued         time.Time
+
+	attempts int
+	lastErr  error
+
+	// Schedules the next upload attempt after a failure.
+	retryTimer *time.Timer
+
+	// Set while the entry waits in the ready queue and while
+	// the entry is being uploaded.
+	ready     bool
+	uploading bool
+
+	// Set when the object is deleted while being uploaded, the
+	// uploader deletes the uploaded copy from the backend.
+	deleted bool
+}
+
+// writeBackObjects - gateway object layer in write-back mode. Objects
+// are written to a local FS or XL staging area, acknowledged and
+// uploaded to the gateway backend in the background. Staged objects
+// are served locally until their upload completes, every object left
+// in the staging area is pending, which keeps the queue across
+// restarts. All other operations are passed on to the backend.
+type writeBackObjects struct {
+	// Gateway backend.
+	ObjectLayer
+
+	staging ObjectLayer
+
+	// Serializes writes of staged objects against the end of their
+	// uploads.
+	nsMutex *nsLockMap
+
+	mu       sync.Mutex
+	entries  map[string]*writeBackEntry
+	queue    []*writeBackEntry
+	uploaded int64
+
+	readyCh chan struct{}
+	doneCh  chan struct{}
+
+	// Tracks the running uploaders, waited for on shutdown.
+	uploaders sync.WaitGroup
+}
+
+// newWriteBackObjects - returns the backend in write-back mode with
+// objects staged in the given directories, uploads of objects left
+// in the staging area are resumed.
+func newWriteBackObjects(backend ObjectLayer, dirs []string) (*writeBackObjects, error) {
+	staging, err := newLocalObjectLayer(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	wb := &writeBackObjects{
+		ObjectLayer: backend,
+		staging:     staging,
+		nsMutex:     newNSLock(false),
+		entries:     make(map[string]*writeBackEntry),
+		readyCh:     make(chan struct{}, 1),
+		doneCh:      make(chan struct{}),
+	}
+	if err = wb.loadStagedObjects(); err != nil {
+		return nil, err
+	}
+	wb.uploaders.Add(writeBackUploaders)
+	for i := 0; i < writeBackUploaders; i++ {
+		go wb.uploader()
+	}
+	return wb, nil
+}
+
+// loadStagedObjects - queues the upload of all staged objects.
+func (wb *writeBackObjects) loadStagedObjects() error {
+	buckets, err := wb.staging.ListBuckets()
+	if err != nil {
+		return err
+	}
+	for _, bucket := range buckets {
+		marker := ""
+		for {
+			result, err := wb.staging.ListObjects(bucket.Name, "", marker, "", maxObjectList)
+			if err != nil {
+				return err
+			}
+			for _, objInfo := range result.Objects {
+				wb.enqueue(objInfo)
+			}
+			if !result.IsTruncated {
+				break
+			}
+			marker = result.NextMarker
+		}
+	}
+	return nil
+}
+
+func writeBackKey(bucket, object string) string {
+	return pathJoin(bucket, object)
+}
+
+// enqueue - queues the upload of a staged object, replacing the
+// pending upload of an earlier version.
+func (wb *writeBackObjects) enqueue(objInfo ObjectInfo) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	key := writeBackKey(objInfo.Bucket, objInfo.Name)
+	e, ok := wb.entries[key]
+	if !ok {
+		e = &writeBackEntry{bucket: objInfo.Bucket, object: objInfo.Name}
+		wb.entries[key] = e
+	}
+	e.etag = objInfo.ETag
+	e.size = objInfo.Size
+	e.queued = objInfo.ModTime
+	e.attempts = 0
+	e.lastErr = nil
+	e.deleted = false
+	e.stopRetry()
+	wb.push(e)
+}
+
+// stopRetry - cancels the scheduled upload attempt of an entry, must
+// be called with the mutex held.
+func (e *writeBackEntry) stopRetry() {
+	if e.retryTimer != nil {
+		e.retryTimer.Stop()
+		e.retryTimer = nil
+	}
+}
+
+// push - adds an entry to the ready queue unless queued or being
+// uploaded, must be called with the mutex held.
+func (wb *writeBackObjects) push(e *writeBackEntry) {
+	if e.ready || e.uploading {
+		return
+	}
+	e.ready = true
+	wb.queue = append(wb.queue, e)
+	select {
+	case wb.readyCh <- struct{}{}:
+	default:
+	}
+}
+
+// next - returns the next entry to upload, nil if none is ready.
+func (wb *writeBackObjects) next() *writeBackEntry {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	for len(wb.queue) > 0 {
+		e := wb.queue[0]
+		wb.queue[0] = nil
+		wb.queue = wb.queue[1:]
+		if !e.ready || wb.entries[writeBackKey(e.bucket, e.object)] != e {
+			// Deleted while queued.
+			continue
+		}
+		e.ready = false
+		e.uploading = true
+		if len(wb.queue) > 0 {
+			// Wake up another uploader for the rest.
+			select {
+			case wb.readyCh <- struct{}{}:
+			default:
+			}
+		}
+		return e
+	}
+	return nil
+}
+
+// pending - returns the entry of a staged object.
+func (wb *writeBackObjects) pending(bucket, object string) (e writeBackEntry, ok bool) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	if entry, found := wb.entries[writeBackKey(bucket, object)]; found {
+		return *entry, true
+	}
+	return e, false
+}
+
+// uploader - uploads ready entries until shutdown.
+func (wb *writeBackObjects) uploader() {
+	defer wb.uploaders.Done()
+	for {
+		select {
+		case <-wb.doneCh:
+			return
+		default:
+		}
+
+		e := wb.next()
+		if e == nil {
+			select {
+			case <-wb.readyCh:
+				continue
+			case <-wb.doneCh:
+				return
+			}
+		}
+
+		err := wb.uploadEntry(e)
+		if err != nil {
+			wb.retry(e, err)
+		}
+	}
+}
+
+// uploadEntry - uploads the staged object of an entry to the backend,
+// the staged copy is removed if it was not replaced meanwhile.
+func (wb *writeBackObjects) uploadEntry(e *writeBackEntry) error {
+	objInfo, err := wb.staging.GetObjectInfo(e.bucket, e.object)
+	if err != nil && !isErrObjectNotFound(err) {
+		return err
+	}
+	uploaded := err == nil
+	if uploaded {
+		if err = wb.upload(objInfo); err != nil {
+			return err
+		}
+	}
+
+	objectLock := wb.nsMutex.NewNSLock(e.bucket, e.object)
+	if err = objectLock.GetLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	defer objectLock.Unlock()
+
+	wb.mu.Lock()
+	deleted := e.deleted
+	done := deleted || !uploaded || e.etag == objInfo.ETag
+	wb.mu.Unlock()
+
+	switch {
+	case deleted:
+		if err = wb.ObjectLayer.DeleteObject(e.bucket, e.object); err != nil && !isErrObjectNotFound(err) {
+			return err
+		}
+	case done && uploaded:
+		if err = wb.staging.DeleteObject(e.bucket, e.object); err != nil && !isErrObjectNotFound(err) {
+			return err
+		}
+	}
+
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	e.uploading = false
+	if !done {
+		// Replaced while uploading, upload the new version.
+		wb.push(e)
+		return nil
+	}
+	if uploaded {
+		wb.uploaded++
+	}
+	if wb.entries[writeBackKey(e.bucket, e.object)] == e {
+		delete(wb.entries, writeBackKey(e.bucket, e.object))
+	}
+	return nil
+}
+
+// upload - uploads a staged object, objects larger than one part are
+// uploaded by multipart upload if the backend supports it.
+func (wb *writeBackObjects) upload(objInfo ObjectInfo) error {
+	metadata := make(map[string]string)
+	for k, v := range objInfo.UserDefined {
+		metadata[k] = v
+	}
+	if objInfo.ContentType != "" {
+		metadata["content-type"] = objInfo.ContentType
+	}
+	read := func() *stagedObjectReader {
+		pr, pw := io.Pipe()
+		return &stagedObjectReader{PipeReader: pr, start: func() {
+			go func() {
+				pw.CloseWithError(wb.staging.GetObject(objInfo.Bucket, objInfo.Name, 0, objInfo.Size, pw, objInfo.ETag))
+			}()
+		}}
+	}
+
+	if objInfo.Size > globalPutPartSize {
+		pr := read()
+		_, _, err := uploadFileTransfer(wb.ObjectLayer, objInfo.Bucket, objInfo.Name, pr, metadata)
+		pr.Close()
+		if _, ok := errors.Cause(err).(NotImplemented); !ok {
+			return err
+		}
+	}
+
+	// Verify the content against the MD5 sum unless it was
+	// uploaded in parts.
+	md5Hex := objInfo.ETag
+	if strings.Contains(md5Hex, "-") {
+		md5Hex = ""
+	}
+	pr := read()
+	defer pr.Close()
+	hashReader, err := hash.NewReader(pr, objInfo.Size, md5Hex, "")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = wb.ObjectLayer.PutObject(objInfo.Bucket, objInfo.Name, hashReader, metadata)
+	return err
+}
+
+// stagedObjectReader - reads a staged object once the backend starts
+// reading, so that it is not locked while the backend waits.
+type stagedObjectReader struct {
+	*io.PipeReader
+	once  sync.Once
+	start func()
+}
+
+func (r *stagedObjectReader) Read(p []byte) (int, error) {
+	r.once.Do(r.start)
+	return r.PipeReader.Read(p)
+}
+
+// retry - schedules the next upload attempt of an entry.
+func (wb *writeBackObjects) retry(e *writeBackEntry, err error) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	e.uploading = false
+	if e.deleted {
+		// Nothing was uploaded, the backend has no copy left.
+		if wb.entries[writeBackKey(e.bucket, e.object)] == e {
+			delete(wb.entries, writeBackKey(e.bucket, e.object))
+		}
+		return
+	}
+	errorIf(err, "Unable to upload %s/%s to the gateway backend", e.bucket, e.object)
+	e.lastErr = err
+	e.attempts++
+
+	delay := writeBackMaxRetryDelay
+	if e.attempts < 16 && writeBackRetryDelay<<uint(e.attempts-1) < delay {
+		delay = writeBackRetryDelay << uint(e.attempts-1)
+	}
+	select {
+	case <-wb.doneCh:
+		// Shutting down, retried on the next start.
+		return
+	default:
+	}
+	attempts := e.attempts
+	e.retryTimer = time.AfterFunc(delay, func() {
+		wb.mu.Lock()
+		defer wb.mu.Unlock()
+		// Skip if replaced or deleted meanwhile.
+		if e.attempts == attempts && wb.entries[writeBackKey(e.bucket, e.object)] == e {
+			e.retryTimer = nil
+			wb.push(e)
+		}
+	})
+}
+
+// Status - returns the pending uploads, lists pending objects with
+// the given bucket and prefix.
+func (wb *writeBackObjects) Status(bucket, prefix string) madmin.WriteBackStatus {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	status := madmin.WriteBackStatus{
+		Uploaded: wb.uploaded,
+		Objects:  []madmin.WriteBackObject{},
+	}
+	for _, e := range wb.entries {
+		if e.deleted {
+			continue
+		}
+		status.Pending++
+		status.PendingSize += e.size
+		if e.uploading {
+			status.Uploading++
+		}
+		if e.lastErr != nil {
+			status.Failed++
+		}
+		if (bucket != "" && e.bucket != bucket) || !hasPrefix(e.object, prefix) {
+			continue
+		}
+		object := madmin.WriteBackObject{
+			Bucket:    e.bucket,
+			Object:    e.object,
+			Size:      e.size,
+			Queued:    e.queued,
+			Uploading: e.uploading,
+			Attempts:  e.attempts,
+		}
+		if e.lastErr != nil {
+			object.LastError = e.lastErr.Error()
+		}
+		status.Objects = append(status.Objects, object)
+	}
+
+	sort.Slice(status.Objects, func(i, j int) bool {
+		return status.Objects[i].Queued.Before(status.Objects[j].Queued)
+	})
+	if len(status.Objects) > maxObjectList {
+		status.Objects = status.Objects[:maxObjectList]
+	}
+	return status
+}
+
+// Shutdown - stops the uploads and waits for the uploads in progress,
+// pending objects are uploaded on the next start.
+func (wb *writeBackObjects) Shutdown() error {
+	wb.mu.Lock()
+	close(wb.doneCh)
+	for _, e := range wb.entries {
+		e.stopRetry()
+	}
+	wb.mu.Unlock()
+	wb.uploaders.Wait()
+
+	errorIf(wb.staging.Shutdown(), "Unable to shutdown the write-back staging area")
+	return wb.ObjectLayer.Shutdown()
+}
+
+// makeStagingBucket - creates a bucket of the backend in the staging
+// area.
+func (wb *writeBackObjects) makeStagingBucket(bucket string) error {
+	if _, err := wb.staging.GetBucketInfo(bucket); err == nil {
+		return nil
+	}
+	if _, err := wb.ObjectLayer.GetBucketInfo(bucket); err != nil {
+		return err
+	}
+	err := wb.staging.MakeBucketWithLocation(bucket, "")
+	if _, ok := errors.Cause(err).(BucketExists); ok {
+		return nil
+	}
+	return err
+}
+
+// DeleteBucket - deletes a bucket without pending uploads.
+func (wb *writeBackObjects) DeleteBucket(bucket string) error {
+	wb.mu.Lock()
+	for _, e := range wb.entries {
+		if e.bucket == bucket {
+			wb.mu.Unlock()
+			return errors.Trace(BucketNotEmpty{Bucket: bucket})
+		}
+	}
+	wb.mu.Unlock()
+
+	if err := wb.ObjectLayer.DeleteBucket(bucket); err != nil {
+		return err
+	}
+	if err := wb.staging.DeleteBucket(bucket); err != nil {
+		if _, ok := errors.Cause(err).(BucketNotFound); !ok {
+			errorIf(err, "Unable to delete staged bucket %s", bucket)
+		}
+	}
+	return nil
+}
+
+// ListObjects - lists objects of the backend merged with staged
+// objects.
+func (wb *writeBackObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (result ListObjectsInfo, err error) {
+	result, err = wb.ObjectLayer.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil || maxKeys <= 0 {
+		return result, err
+	}
+	staged, err := wb.staging.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		if _, ok := errors.Cause(err).(BucketNotFound); !ok {
+			return result, err
+		}
+		staged = ListObjectsInfo{}
+	}
+	return wb.mergeListObjects(bucket, result, staged, maxKeys), nil
+}
+
+// mergeListObjects - merges listings of the backend and the staging
+// area with the same marker, staged objects replace objects of the
+// backend and objects deleted while being uploaded are left out.
+func (wb *writeBackObjects) mergeListObjects(bucket string, backend, staged ListObjectsInfo, maxKeys int) ListObjectsInfo {
+	objects := make(map[string]ObjectInfo)
+	prefixes := make(map[string]bool)
+	var names []string
+
+	wb.mu.Lock()
+	for _, objInfo := range backend.Objects {
+		if e, ok := wb.entries[writeBackKey(bucket, objInfo.Name)]; ok && e.deleted {
+			continue
+		}
+		objects[objInfo.Name] = objInfo
+		names = append(names, objInfo.Name)
+	}
+	wb.mu.Unlock()
+	for _, objInfo := range staged.Objects {
+		if _, ok := objects[objInfo.Name]; !ok {
+			names = append(names, objInfo.Name)
+		}
+		objects[objInfo.Name] = objInfo
+	}
+	for _, prefix := range append(backend.Prefixes, staged.Prefixes...) {
+		if _, ok := objects[prefix]; !ok && !prefixes[prefix] {
+			prefixes[prefix] = true
+			names = append(names, prefix)
+		}
+	}
+	sort.Strings(names)
+
+	// Both listings hold every entry up to their last one, so the
+	// first maxKeys entries of the merge are complete.
+	var result ListObjectsInfo
+	for i, name := range names {
+		if i == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+		if objInfo, ok := objects[name]; ok {
+			result.Objects = append(result.Objects, objInfo)
+		} else {
+			result.Prefixes = append(result.Prefixes, name)
+		}
+		result.NextMarker = name
+	}
+	result.IsTruncated = result.IsTruncated || backend.IsTruncated || staged.IsTruncated
+	if !result.IsTruncated {
+		result.NextMarker = ""
+	}
+	return result
+}
+
+// ListObjectsV2 - lists objects of the backend merged with staged
+// objects.
+func (wb *writeBackObjects) ListObjectsV2(bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (result ListObjectsV2Info, err error) {
+	marker := continuationToken
+	if marker == "" {
+		marker = startAfter
+	}
+	loi, err := wb.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return result, err
+	}
+	return ListObjectsV2Info{
+		IsTruncated:           loi.IsTruncated,
+		ContinuationToken:     continuationToken,
+		NextContinuationToken: loi.NextMarker,
+		Objects:               loi.Objects,
+		Prefixes:              loi.Prefixes,
+	}, nil
+}
+
+// GetObject - reads a staged object locally, other objects from the
+// backend.
+func (wb *writeBackObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer, etag string) error {
+	if e, ok := wb.pending(bucket, object); ok {
+		if e.deleted {
+			return errors.Trace(ObjectNotFound{Bucket: bucket, Object: object})
+		}
+		// Fall back to the backend if uploaded meanwhile.
+		err := wb.staging.GetObject(bucket, object, startOffset, length, writer, etag)
+		if !isErrObjectNotFound(err) {
+			return err
+		}
+	}
+	return wb.ObjectLayer.GetObject(bucket, object, startOffset, length, writer, etag)
+}
+
+// GetObjectInfo - returns the info of a staged object, of other
+// objects from the backend.
+func (wb *writeBackObjects) GetObjectInfo(bucket, object string) (ObjectInfo, error) {
+	if e, ok := wb.pending(bucket, object); ok {
+		if e.deleted {
+			return ObjectInfo{}, errors.Trace(ObjectNotFound{Bucket: bucket, Object: object})
+		}
+		objInfo, err := wb.staging.GetObjectInfo(bucket, object)
+		if !isErrObjectNotFound(err) {
+			return objInfo, err
+		}
+	}
+	return wb.ObjectLayer.GetObjectInfo(bucket, object)
+}
+
+// PutObject - stages an object and queues its upload.
+func (wb *writeBackObjects) PutObject(bucket, object string, data *hash.Reader, metadata map[string]string) (objInfo ObjectInfo, err error) {
+	if err = wb.makeStagingBucket(bucket); err != nil {
+		return objInfo, err
+	}
+
+	objectLock := wb.nsMutex.NewNSLock(bucket, object)
+	if err = objectLock.GetLock(globalObjectTimeout); err != nil {
+		return objInfo, err
+	}
+	defer objectLock.Unlock()
+
+	if objInfo, err = wb.staging.PutObject(bucket, object, data, metadata); err != nil {
+		return objInfo, err
+	}
+	wb.enqueue(objInfo)
+	return objInfo, nil
+}
+
+// CopyObject - copies staged objects and copies to staged objects in
+// the staging area, all other copies are done by the backend.
+func (wb *writeBackObjects) CopyObject(srcBucket, srcObject, destBucket, destObject string, metadata map[string]string, srcETag string) (objInfo ObjectInfo, err error) {
+	srcInfo, err := wb.GetObjectInfo(srcBucket, srcObject)
+	if err != nil {
+		return objInfo, err
+	}
+	_, srcStaged := wb.pending(srcBucket, srcObject)
+
+	objectLock := wb.nsMutex.NewNSLock(destBucket, destObject)
+	if err = objectLock.GetLock(globalObjectTimeout); err != nil {
+		return objInfo, err
+	}
+	defer objectLock.Unlock()
+
+	if srcStaged {
+		if err = wb.makeStagingBucket(destBucket); err != nil {
+			return objInfo, err
+		}
+		objInfo, err = wb.staging.CopyObject(srcBucket, srcObject, destBucket, destObject, metadata, srcETag)
+		if err == nil {
+			wb.enqueue(objInfo)
+			return objInfo, nil
+		}
+		if !isErrObjectNotFound(err) {
+			return objInfo, err
+		}
+	}
+
+	// A pending upload would overwrite a copy done by the backend,
+	// the source is staged instead.
+	if _, destStaged := wb.pending(destBucket, destObject); !destStaged {
+		return wb.ObjectLayer.CopyObject(srcBucket, srcObject, destBucket, destObject, metadata, srcETag)
+	}
+	if err = wb.makeStagingBucket(destBucket); err != nil {
+		return objInfo, err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(wb.ObjectLayer.GetObject(srcBucket, srcObject, 0, srcInfo.Size, pw, srcETag))
+	}()
+	defer pr.Close()
+	hashReader, err := hash.NewReader(pr, srcInfo.Size, "", "")
+	if err != nil {
+		return objInfo, errors.Trace(err)
+	}
+	if objInfo, err = wb.staging.PutObject(destBucket, destObject, hashReader, metadata); err != nil {
+		return objInfo, err
+	}
+	wb.enqueue(objInfo)
+	return objInfo, nil
+}
+
+// DeleteObject - deletes an object from the staging area and the
+// backend.
+func (wb *writeBackObjects) DeleteObject(bucket, object string) error {
+	objectLock := wb.nsMutex.NewNSLock(bucket, object)
+	if err := objectLock.GetLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	defer objectLock.Unlock()
+
+	staged := false
+	wb.mu.Lock()
+	if e, ok := wb.entries[writeBackKey(bucket, object)]; ok && !e.deleted {
+		staged = true
+		if e.uploading {
+			e.deleted = true
+		} else {
+			e.stopRetry()
+			delete(wb.entries, writeBackKey(bucket, object))
+		}
+	}
+	wb.mu.Unlock()
+
+	if staged {
+		if err := wb.staging.DeleteObject(bucket, object); err != nil && !isErrObjectNotFound(err) {
+			return err
+		}
+	}
+	err := wb.ObjectLayer.DeleteObject(bucket, object)
+	if staged && isErrObjectNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// ListMultipartUploads - lists multipart uploads of the staging area.
+func (wb *writeBackObjects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result ListMultipartsInfo, err error) {
+	result, err = wb.staging.ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
+	if _, ok := errors.Cause(err).(BucketNotFound); ok {
+		// No upload was started in the bucket.
+		if _, err = wb.ObjectLayer.GetBucketInfo(bucket); err != nil {
+			return result, err
+		}
+		return ListMultipartsInfo{
+			KeyMarker:      keyMarker,
+			UploadIDMarker: uploadIDMarker,
+			MaxUploads:     maxUploads,
+			Prefix:         prefix,
+			Delimiter:      delimiter,
+		}, nil
+	}
+	return result, err
+}
+
+// NewMultipartUpload - starts a multipart upload in the staging area.
+func (wb *writeBackObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (uploadID string, err error) {
+	if err = wb.makeStagingBucket(bucket); err != nil {
+		return "", err
+	}
+	return wb.staging.NewMultipartUpload(bucket, object, metadata)
+}
+
+// CopyObjectPart - copies a part of an object to a multipart upload in
+// the staging area.
+func (wb *writeBackObjects) CopyObjectPart(srcBucket, srcObject, destBucket, destObject string, uploadID string, partID int, startOffset int64, length int64, metadata map[string]string, srcETag string) (info PartInfo, err error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(wb.GetObject(srcBucket, srcObject, startOffset, length, pw, srcETag))
+	}()
+	defer pr.Close()
+	hashReader, err := hash.NewReader(pr, length, "", "")
+	if err != nil {
+		return info, errors.Trace(err)
+	}
+	return wb.staging.PutObjectPart(destBucket, destObject, uploadID, partID, hashReader)
+}
+
+// PutObjectPart - uploads a part to the staging area.
+func (wb *writeBackObjects) PutObjectPart(bucket, object, uploadID string, partID int, data *hash.Reader) (info PartInfo, err error) {
+	return wb.staging.PutObjectPart(bucket, object, uploadID, partID, data)
+}
+
+// ListObjectParts - lists parts uploaded to the staging area.
+func (wb *writeBackObjects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (result ListPartsInfo, err error) {
+	return wb.staging.ListObjectParts(bucket, object, uploadID, partNumberMarker, maxParts)
+}
+
+// AbortMultipartUpload - aborts a multipart upload in the staging area.
+func (wb *writeBackObjects) AbortMultipartUpload(bucket, object, uploadID string) error {
+	return wb.staging.AbortMultipartUpload(bucket, object, uploadID)
+}
+
+// CompleteMultipartUpload - completes a multipart upload in the
+// staging area and queues the upload of the object.
+func (wb *writeBackObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []CompletePart) (objInfo ObjectInfo, err error) {
+	objectLock := wb.nsMutex.NewNSLock(bucket, object)
+	if err = objectLock.GetLock(globalObjectTimeout); err != nil {
+		return objInfo, err
+	}
+	defer objectLock.Unlock()
+
+	if objInfo, err = wb.staging.CompleteMultipartUpload(bucket, object, uploadID, uploadedParts); err != nil {
+		return objInfo, err
+	}
+	wb.enqueue(objInfo)
+	return objInfo, nil
+}
@@ -0,0 +1,301 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// writeBackTestBackend - backend whose uploads wait until released
+// and fail while failing is set.
+type writeBackTestBackend struct {
+	ObjectLayer
+	releaseCh   chan struct{}
+	releaseOnce sync.Once
+	failing     bool
+}
+
+func (b *writeBackTestBackend) PutObject(bucket, object string, data *hash.Reader, metadata map[string]string) (ObjectInfo, error) {
+	<-b.releaseCh
+	if b.failing {
+		return ObjectInfo{}, errUnexpected
+	}
+	return b.ObjectLayer.PutObject(bucket, object, data, metadata)
+}
+
+// Shutdown - leaves the backend to the cleanup of the test.
+func (b *writeBackTestBackend) Shutdown() error {
+	return nil
+}
+
+// release - lets waiting and future uploads proceed.
+func (b *writeBackTestBackend) release() {
+	b.releaseOnce.Do(func() { close(b.releaseCh) })
+}
+
+// newWriteBackTestBackend - returns an FS backend with a bucket,
+// wrapped to control its uploads.
+func newWriteBackTestBackend(t *testing.T, obj ObjectLayer) *writeBackTestBackend {
+	if err := obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	return &writeBackTestBackend{ObjectLayer: obj, releaseCh: make(chan struct{})}
+}
+
+func isBucketNotFound(err error) bool {
+	_, ok := errors.Cause(err).(BucketNotFound)
+	return ok
+}
+
+func putWriteBackTestObject(t *testing.T, obj ObjectLayer, object, data string) ObjectInfo {
+	hashReader, err := hash.NewReader(bytes.NewReader([]byte(data)), int64(len(data)), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	objInfo, err := obj.PutObject("bucket", object, hashReader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return objInfo
+}
+
+// waitForWriteBack - waits until no upload is pending.
+func waitForWriteBack(t *testing.T, wb *writeBackObjects) {
+	for i := 0; i < 1000; i++ {
+		if wb.Status("", "").Pending == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for pending uploads")
+}
+
+func TestWriteBackObjects(t *testing.T) {
+	obj, stagingDirs, cleanup := prepareGatewayTest(t, 1)
+	defer cleanup()
+	backend := newWriteBackTestBackend(t, obj)
+
+	notifier, logger := globalEventNotifier, globalBucketAccessLogger
+	wb, err := newWriteBackObjects(backend, stagingDirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wb.Shutdown()
+	defer backend.release()
+	if globalEventNotifier != notifier || globalBucketAccessLogger != logger {
+		t.Fatal("Expected the staging area to leave the notifier and the logger of the server alone")
+	}
+
+	if _, err = wb.PutObject("missing", "object", nil, nil); !isBucketNotFound(err) {
+		t.Fatalf("Expected missing bucket, got %v", err)
+	}
+	putWriteBackTestObject(t, wb, "dir/object", "hello world")
+	putWriteBackTestObject(t, wb, "deleted", "deleted")
+
+	// Staged objects are served locally until uploaded.
+	if _, err = backend.ObjectLayer.GetObjectInfo("bucket", "dir/object"); !isErrObjectNotFound(err) {
+		t.Fatalf("Expected object not to be uploaded, got %v", err)
+	}
+	objInfo, err := wb.GetObjectInfo("bucket", "dir/object")
+	if err != nil || objInfo.Size != 11 {
+		t.Fatalf("Expected staged object, got %v", err)
+	}
+	var buf bytes.Buffer
+	if err = wb.GetObject("bucket", "dir/object", 6, 5, &buf, ""); err != nil || buf.String() != "world" {
+		t.Errorf("Expected `world`, got `%s`, %v", buf.String(), err)
+	}
+	result, err := wb.ListObjects("bucket", "", "", "/", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Name != "deleted" || !reflect.DeepEqual(result.Prefixes, []string{"dir/"}) {
+		t.Errorf("Unexpected listing %v, %v", result.Objects, result.Prefixes)
+	}
+
+	status := wb.Status("bucket", "dir/")
+	if status.Pending != 2 || status.PendingSize != 18 || len(status.Objects) != 1 || status.Objects[0].Object != "dir/object" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	if _, ok := errors.Cause(wb.DeleteBucket("bucket")).(BucketNotEmpty); !ok {
+		t.Error("Expected bucket with pending uploads not to be deleted")
+	}
+
+	// Deleted objects are not uploaded or removed once uploaded.
+	if err = wb.DeleteObject("bucket", "deleted"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = wb.GetObjectInfo("bucket", "deleted"); !isErrObjectNotFound(err) {
+		t.Errorf("Expected deleted object not to exist, got %v", err)
+	}
+
+	backend.release()
+	waitForWriteBack(t, wb)
+	if objInfo, err = backend.ObjectLayer.GetObjectInfo("bucket", "dir/object"); err != nil || objInfo.Size != 11 {
+		t.Fatalf("Expected object to be uploaded, got %v", err)
+	}
+	if _, err = backend.ObjectLayer.GetObjectInfo("bucket", "deleted"); !isErrObjectNotFound(err) {
+		t.Errorf("Expected deleted object not to be uploaded, got %v", err)
+	}
+	if _, err = wb.staging.GetObjectInfo("bucket", "dir/object"); !isErrObjectNotFound(err) {
+		t.Errorf("Expected uploaded object to be removed from staging, got %v", err)
+	}
+	if status = wb.Status("", ""); status.Uploaded != 1 || len(status.Objects) != 0 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	buf.Reset()
+	if err = wb.GetObject("bucket", "dir/object", 0, 11, &buf, ""); err != nil || buf.String() != "hello world" {
+		t.Errorf("Expected `hello world`, got `%s`, %v", buf.String(), err)
+	}
+}
+
+// Tests that objects left in the staging area are uploaded after a
+// restart.
+func TestWriteBackObjectsResume(t *testing.T) {
+	obj, stagingDirs, cleanup := prepareGatewayTest(t, 1)
+	defer cleanup()
+	backend := newWriteBackTestBackend(t, obj)
+
+	backend.failing = true
+	backend.release()
+	wb, err := newWriteBackObjects(backend, stagingDirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	putWriteBackTestObject(t, wb, "object", "hello world")
+
+	for i := 0; i < 1000 && wb.Status("", "").Failed == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	status := wb.Status("", "")
+	if status.Failed != 1 || status.Objects[0].Attempts == 0 || status.Objects[0].LastError == "" {
+		t.Fatalf("Expected upload to fail, got %+v", status)
+	}
+	if err = wb.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range wb.entries {
+		if e.retryTimer != nil {
+			t.Fatalf("Expected retries of %s to be stopped on shutdown", e.object)
+		}
+	}
+
+	backend.failing = false
+	if wb, err = newWriteBackObjects(backend, stagingDirs); err != nil {
+		t.Fatal(err)
+	}
+	defer wb.Shutdown()
+	waitForWriteBack(t, wb)
+	if _, err = backend.ObjectLayer.GetObjectInfo("bucket", "object"); err != nil {
+		t.Fatalf("Expected object to be uploaded, got %v", err)
+	}
+}
+
+// Tests that shutdown waits for the uploads in progress.
+func TestWriteBackObjectsShutdown(t *testing.T) {
+	obj, stagingDirs, cleanup := prepareGatewayTest(t, 1)
+	defer cleanup()
+	backend := newWriteBackTestBackend(t, obj)
+	defer backend.release()
+
+	backend.failing = true
+	wb, err := newWriteBackObjects(backend, stagingDirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	putWriteBackTestObject(t, wb, "object", "hello world")
+	for i := 0; i < 1000 && wb.Status("", "").Uploading == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	doneCh := make(chan error)
+	go func() { doneCh <- wb.Shutdown() }()
+	select {
+	case <-doneCh:
+		t.Fatal("Expected shutdown to wait for the upload in progress")
+	case <-time.After(100 * time.Millisecond):
+	}
+	backend.release()
+	select {
+	case err = <-doneCh:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Timed out waiting for shutdown")
+	}
+
+	// The failed upload is retried on the next start only.
+	status := wb.Status("", "")
+	if status.Failed != 1 || status.Uploading != 0 {
+		t.Fatalf("Unexpected status %+v", status)
+	}
+	if e := wb.entries[writeBackKey("bucket", "object")]; e.retryTimer != nil {
+		t.Fatal("Expected no retry to be scheduled after shutdown")
+	}
+}
+
+func TestMergeListObjects(t *testing.T) {
+	wb := &writeBackObjects{entries: map[string]*writeBackEntry{
+		"bucket/c": {bucket: "bucket", object: "c", deleted: true},
+	}}
+	objects := func(names ...string) (objInfos []ObjectInfo) {
+		for _, name := range names {
+			objInfos = append(objInfos, ObjectInfo{Name: name})
+		}
+		return objInfos
+	}
+
+	testCases := []struct {
+		backend, staged  ListObjectsInfo
+		maxKeys          int
+		expectedObjects  []ObjectInfo
+		expectedPrefixes []string
+		nextMarker       string
+	}{
+		{
+			ListObjectsInfo{Objects: objects("a", "c", "e")},
+			ListObjectsInfo{Objects: objects("b", "e"), Prefixes: []string{"d/"}},
+			10, objects("a", "b", "e"), []string{"d/"}, "",
+		},
+		{
+			ListObjectsInfo{Objects: objects("a", "b"), Prefixes: []string{"d/"}},
+			ListObjectsInfo{Objects: objects("c"), Prefixes: []string{"d/"}},
+			2, objects("a", "b"), nil, "b",
+		},
+		{
+			ListObjectsInfo{Objects: objects("a"), IsTruncated: true, NextMarker: "a"},
+			ListObjectsInfo{},
+			1, objects("a"), nil, "a",
+		},
+	}
+	for i, testCase := range testCases {
+		result := wb.mergeListObjects("bucket", testCase.backend, testCase.staged, testCase.maxKeys)
+		if !reflect.DeepEqual(result.Objects, testCase.expectedObjects) || !reflect.DeepEqual(result.Prefixes, testCase.expectedPrefixes) {
+			t.Errorf("Test %d: unexpected listing %v, %v", i+1, result.Objects, result.Prefixes)
+		}
+		if result.NextMarker != testCase.nextMarker || result.IsTruncated != (testCase.nextMarker != "") {
+			t.Errorf("Test %d: unexpected marker %s, truncated %v", i+1, result.NextMarker, result.IsTruncated)
+		}
+	}
+}
@@ -93,6 +93,8 @@ EXAMPLES:
 		Action:             azureGatewayMain,
 		CustomHelpTemplate: azureGatewayTemplate,
 		HideHelpCommand:    true,
+	}, func(arg string) minio.Gateway {
+		return &Azure{arg}
 	})
 }
 
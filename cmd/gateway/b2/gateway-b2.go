@@ -79,6 +79,8 @@ EXAMPLES:
 		Action:             b2GatewayMain,
 		CustomHelpTemplate: b2GatewayTemplate,
 		HideHelpCommand:    true,
+	}, func(_ string) minio.Gateway {
+		return &B2{}
 	})
 }
 
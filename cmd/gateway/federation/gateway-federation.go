@@ -0,0 +1,94 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package federation
+
+import (
+	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/auth"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+const (
+	federationBackend = "federation"
+)
+
+func init() {
+	const federationGatewayTemplate = `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} {{if .VisibleFlags}}[FLAGS]{{end}}
+{{if .VisibleFlags}}
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}{{end}}
+CONFIGURATION:
+  Backends and the routes of buckets to them are configured in the "federation"
+  section of the server configuration.
+
+ENVIRONMENT VARIABLES:
+  ACCESS:
+     MINIO_ACCESS_KEY: Custom username or access key of minimum 3 characters in length.
+     MINIO_SECRET_KEY: Custom password or secret key of minimum 8 characters in length.
+
+  BROWSER:
+     MINIO_BROWSER: To disable web browser access, set this value to "off".
+
+  UPDATE:
+     MINIO_UPDATE: To turn off in-place upgrades, set this value to "off".
+
+EXAMPLES:
+  1. Start minio gateway server for the federated backends.
+      $ export MINIO_ACCESS_KEY=accesskey
+      $ export MINIO_SECRET_KEY=secretkey
+      $ {{.HelpName}}
+
+`
+
+	minio.RegisterGatewayCommand(cli.Command{
+		Name:               federationBackend,
+		Usage:              "Federation of several backends.",
+		Action:             federationGatewayMain,
+		CustomHelpTemplate: federationGatewayTemplate,
+		HideHelpCommand:    true,
+	}, nil)
+}
+
+// Handler for 'minio gateway federation' command line.
+func federationGatewayMain(ctx *cli.Context) {
+	minio.StartGateway(ctx, &Federation{})
+}
+
+// Federation implements Gateway.
+type Federation struct{}
+
+// Name implements Gateway interface.
+func (g *Federation) Name() string {
+	return federationBackend
+}
+
+// NewGatewayLayer returns federation gateway layer, routing buckets to
+// the configured backends.
+func (g *Federation) NewGatewayLayer(creds auth.Credentials) (minio.ObjectLayer, error) {
+	return minio.NewFederationLayer()
+}
+
+// Production - federation gateway is not yet production ready.
+func (g *Federation) Production() bool {
+	return false
+}
@@ -20,10 +20,13 @@ import (
 	// Import all gateways.
 	_ "github.com/minio/minio/cmd/gateway/azure"
 	_ "github.com/minio/minio/cmd/gateway/b2"
+	_ "github.com/minio/minio/cmd/gateway/federation"
 	_ "github.com/minio/minio/cmd/gateway/gcs"
 	_ "github.com/minio/minio/cmd/gateway/manta"
+	_ "github.com/minio/minio/cmd/gateway/nas"
 	_ "github.com/minio/minio/cmd/gateway/oss"
 	_ "github.com/minio/minio/cmd/gateway/s3"
 	_ "github.com/minio/minio/cmd/gateway/sia"
+	_ "github.com/minio/minio/cmd/gateway/swift"
 	// Add your gateway here.
 )
@@ -131,6 +131,8 @@ EXAMPLES:
 		Action:             gcsGatewayMain,
 		CustomHelpTemplate: gcsGatewayTemplate,
 		HideHelpCommand:    true,
+	}, func(arg string) minio.Gateway {
+		return &GCS{arg}
 	})
 }
 
@@ -709,6 +711,16 @@ func (l *gcsGateway) ListObjectsV2(bucket, prefix, continuationToken, delimiter
 	}, nil
 }
 
+// objectHandle - returns the handle of an object, encrypted with the
+// customer supplied key if set.
+func (l *gcsGateway) objectHandle(bucket, key string, encryptionKey []byte) *storage.ObjectHandle {
+	object := l.client.Bucket(bucket).Object(key)
+	if encryptionKey != nil {
+		object = object.Key(encryptionKey)
+	}
+	return object
+}
+
 // GetObject - reads an object from GCS. Supports additional
 // parameters like offset and length which are synonymous with
 // HTTP Range requests.
@@ -716,13 +728,23 @@ func (l *gcsGateway) ListObjectsV2(bucket, prefix, continuationToken, delimiter
 // startOffset indicates the starting read location of the object.
 // length indicates the total length of the object.
 func (l *gcsGateway) GetObject(bucket string, key string, startOffset int64, length int64, writer io.Writer, etag string) error {
+	return l.getObject(bucket, key, startOffset, length, writer, nil)
+}
+
+// GetObjectWithCustomerKey - reads an object encrypted by GCS with the
+// customer supplied key.
+func (l *gcsGateway) GetObjectWithCustomerKey(bucket string, key string, startOffset int64, length int64, writer io.Writer, etag string, encryptionKey []byte) error {
+	return l.getObject(bucket, key, startOffset, length, writer, encryptionKey)
+}
+
+func (l *gcsGateway) getObject(bucket string, key string, startOffset int64, length int64, writer io.Writer, encryptionKey []byte) error {
 	// if we want to mimic S3 behavior exactly, we need to verify if bucket exists first,
 	// otherwise gcs will just return object not exist in case of non-existing bucket
 	if _, err := l.client.Bucket(bucket).Attrs(l.ctx); err != nil {
 		return gcsToObjectError(errors.Trace(err), bucket)
 	}
 
-	object := l.client.Bucket(bucket).Object(key)
+	object := l.objectHandle(bucket, key, encryptionKey)
 	r, err := object.NewRangeReader(l.ctx, startOffset, length)
 	if err != nil {
 		return gcsToObjectError(errors.Trace(err), bucket, key)
@@ -754,13 +776,23 @@ func fromGCSAttrsToObjectInfo(attrs *storage.ObjectAttrs) minio.ObjectInfo {
 
 // GetObjectInfo - reads object info and replies back ObjectInfo
 func (l *gcsGateway) GetObjectInfo(bucket string, object string) (minio.ObjectInfo, error) {
+	return l.getObjectInfo(bucket, object, nil)
+}
+
+// GetObjectInfoWithCustomerKey - reads the info of an object encrypted
+// by GCS with the customer supplied key.
+func (l *gcsGateway) GetObjectInfoWithCustomerKey(bucket string, object string, encryptionKey []byte) (minio.ObjectInfo, error) {
+	return l.getObjectInfo(bucket, object, encryptionKey)
+}
+
+func (l *gcsGateway) getObjectInfo(bucket string, object string, encryptionKey []byte) (minio.ObjectInfo, error) {
 	// if we want to mimic S3 behavior exactly, we need to verify if bucket exists first,
 	// otherwise gcs will just return object not exist in case of non-existing bucket
 	if _, err := l.client.Bucket(bucket).Attrs(l.ctx); err != nil {
 		return minio.ObjectInfo{}, gcsToObjectError(errors.Trace(err), bucket)
 	}
 
-	attrs, err := l.client.Bucket(bucket).Object(object).Attrs(l.ctx)
+	attrs, err := l.objectHandle(bucket, object, encryptionKey).Attrs(l.ctx)
 	if err != nil {
 		return minio.ObjectInfo{}, gcsToObjectError(errors.Trace(err), bucket, object)
 	}
@@ -770,13 +802,23 @@ func (l *gcsGateway) GetObjectInfo(bucket string, object string) (minio.ObjectIn
 
 // PutObject - Create a new object with the incoming data,
 func (l *gcsGateway) PutObject(bucket string, key string, data *hash.Reader, metadata map[string]string) (minio.ObjectInfo, error) {
+	return l.putObject(bucket, key, data, metadata, nil)
+}
+
+// PutObjectWithCustomerKey - Create a new object encrypted by GCS with
+// the customer supplied key.
+func (l *gcsGateway) PutObjectWithCustomerKey(bucket string, key string, data *hash.Reader, metadata map[string]string, encryptionKey []byte) (minio.ObjectInfo, error) {
+	return l.putObject(bucket, key, data, metadata, encryptionKey)
+}
+
+func (l *gcsGateway) putObject(bucket string, key string, data *hash.Reader, metadata map[string]string, encryptionKey []byte) (minio.ObjectInfo, error) {
 	// if we want to mimic S3 behavior exactly, we need to verify if bucket exists first,
 	// otherwise gcs will just return object not exist in case of non-existing bucket
 	if _, err := l.client.Bucket(bucket).Attrs(l.ctx); err != nil {
 		return minio.ObjectInfo{}, gcsToObjectError(errors.Trace(err), bucket)
 	}
 
-	object := l.client.Bucket(bucket).Object(key)
+	object := l.objectHandle(bucket, key, encryptionKey)
 
 	w := object.NewWriter(l.ctx)
 
@@ -25,10 +25,13 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
+	"time"
 
 	triton "github.com/joyent/triton-go"
 	"github.com/joyent/triton-go/authentication"
+	"github.com/joyent/triton-go/client"
 	terrors "github.com/joyent/triton-go/errors"
 	"github.com/joyent/triton-go/storage"
 	"github.com/minio/cli"
@@ -45,6 +48,10 @@ const (
 	mantaBackend     = "manta"
 	defaultMantaRoot = "/stor"
 	defaultMantaURL  = "https://us-east.manta.joyent.com"
+
+	// Metadata header saving the S3 ETag of objects uploaded by
+	// multipart upload.
+	mantaMultipartETagHeader = "m-minio-multipart-etag"
 )
 
 var mantaRoot = defaultMantaRoot
@@ -68,6 +75,8 @@ ENVIRONMENT VARIABLES:
      MANTA_KEY_MATERIAL: The path to the SSH Key associated with the Manta account if the MINIO_SECRET_KEY is not in SSH Agent.
 	 MANTA_SUBUSER: The username of a user who has limited access to your account.
 
+  MANTA_TEMP_DIR: The local directory where parts of multipart uploads are staged until the upload completes. (.manta_temp)
+
   BROWSER:
      MINIO_BROWSER: To disable web browser access, set this value to "off".
 
@@ -96,6 +105,8 @@ EXAMPLES:
 		Action:             mantaGatewayMain,
 		CustomHelpTemplate: mantaGatewayTemplate,
 		HideHelpCommand:    true,
+	}, func(arg string) minio.Gateway {
+		return &Manta{arg}
 	})
 }
 
@@ -202,9 +213,20 @@ func (g *Manta) NewGatewayLayer(creds auth.Credentials) (minio.ObjectLayer, erro
 		Transport: minio.NewCustomHTTPTransport(),
 	}
 
-	return &tritonObjects{
+	t := &tritonObjects{
 		client: tc,
-	}, nil
+	}
+
+	// Manta has no multipart API, parts are staged locally and
+	// uploaded as one object.
+	tempDir := os.Getenv("MANTA_TEMP_DIR")
+	if tempDir == "" {
+		tempDir = ".manta_temp"
+	}
+	if t.GatewayMultipart, err = minio.NewGatewayMultipart(tempDir, t); err != nil {
+		return nil, err
+	}
+	return t, nil
 }
 
 // Production - Manta is not production ready.
@@ -214,7 +236,7 @@ func (g *Manta) Production() bool {
 
 // tritonObjects - Implements Object layer for Triton Manta storage
 type tritonObjects struct {
-	minio.GatewayUnsupported
+	*minio.GatewayMultipart
 	client *storage.StorageClient
 }
 
@@ -501,9 +523,7 @@ func (t *tritonObjects) GetObject(bucket, object string, startOffset int64, leng
 // https://apidocs.joyent.com/manta/api.html#GetObject
 func (t *tritonObjects) GetObjectInfo(bucket, object string) (objInfo minio.ObjectInfo, err error) {
 	ctx := context.Background()
-	info, err := t.client.Objects().GetInfo(ctx, &storage.GetInfoInput{
-		ObjectPath: path.Join(mantaRoot, bucket, object),
-	})
+	info, err := t.getInfo(ctx, path.Join(mantaRoot, bucket, object))
 	if err != nil {
 		if terrors.IsStatusNotFoundCode(err) {
 			return objInfo, minio.ObjectNotFound{
@@ -515,17 +535,59 @@ func (t *tritonObjects) GetObjectInfo(bucket, object string) (objInfo minio.Obje
 		return objInfo, err
 	}
 
+	// Objects uploaded by multipart upload report their S3 ETag.
+	etag := info.ETag
+	if multipartETag, ok := info.Metadata[mantaMultipartETagHeader]; ok {
+		etag = multipartETag
+		delete(info.Metadata, mantaMultipartETagHeader)
+	}
+
 	return minio.ObjectInfo{
 		Bucket:      bucket,
 		ContentType: info.ContentType,
 		Size:        int64(info.ContentLength),
-		ETag:        info.ETag,
+		ETag:        etag,
 		ModTime:     info.LastModified,
 		UserDefined: info.Metadata,
 		IsDir:       strings.HasSuffix(info.ContentType, "type=directory"),
 	}, nil
 }
 
+// getInfo - returns the properties of an object like GetInfo of the
+// Manta client, whose metadata lookup misses the m- headers of the
+// response since their names are canonicalized.
+func (t *tritonObjects) getInfo(ctx context.Context, objectPath string) (*storage.GetInfoOutput, error) {
+	accountRoot := path.Join("/", t.client.Client.AccountName)
+	if !strings.HasPrefix(objectPath, accountRoot+"/") {
+		objectPath = path.Join(accountRoot, objectPath)
+	}
+	body, headers, err := t.client.Client.ExecuteRequestStorage(ctx, client.RequestInput{
+		Method: http.MethodHead,
+		Path:   objectPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		body.Close()
+	}
+
+	info := &storage.GetInfoOutput{
+		ContentType: headers.Get("Content-Type"),
+		ContentMD5:  headers.Get("Content-MD5"),
+		ETag:        headers.Get("Etag"),
+		Metadata:    make(map[string]string),
+	}
+	info.LastModified, _ = time.Parse(time.RFC1123, headers.Get("Last-Modified"))
+	info.ContentLength, _ = strconv.ParseUint(headers.Get("Content-Length"), 10, 64)
+	for key, values := range headers {
+		if key = strings.ToLower(key); strings.HasPrefix(key, "m-") {
+			info.Metadata[key] = strings.Join(values, ", ")
+		}
+	}
+	return info, nil
+}
+
 type dummySeeker struct {
 	io.Reader
 }
@@ -540,6 +602,10 @@ func (d dummySeeker) Seek(offset int64, whence int) (int64, error) {
 // https://apidocs.joyent.com/manta/api.html#PutObject
 func (t *tritonObjects) PutObject(bucket, object string, data *hash.Reader, metadata map[string]string) (objInfo minio.ObjectInfo, err error) {
 	ctx := context.Background()
+	var headers map[string]string
+	if etag, ok := metadata[minio.GatewayMultipartETag]; ok {
+		headers = map[string]string{mantaMultipartETagHeader: etag}
+	}
 	if err = t.client.Objects().Put(ctx, &storage.PutObjectInput{
 		ContentLength: uint64(data.Size()),
 		ObjectPath:    path.Join(mantaRoot, bucket, object),
@@ -547,6 +613,7 @@ func (t *tritonObjects) PutObject(bucket, object string, data *hash.Reader, meta
 		// TODO: Change to `string(data.md5sum)` if/when that becomes an exported field
 		ContentMD5:   metadata["content-md5"],
 		ObjectReader: dummySeeker{data},
+		Headers:      headers,
 		ForceInsert:  true,
 	}); err != nil {
 		return objInfo, errors.Trace(err)
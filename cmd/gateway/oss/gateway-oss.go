@@ -83,6 +83,8 @@ EXAMPLES:
 		Action:             ossGatewayMain,
 		CustomHelpTemplate: ossGatewayTemplate,
 		HideHelpCommand:    true,
+	}, func(arg string) minio.Gateway {
+		return &OSS{arg}
 	})
 }
 
@@ -17,6 +17,8 @@
 package s3
 
 import (
+	"crypto/md5"
+	"encoding/base64"
 	"io"
 
 	"github.com/minio/cli"
@@ -76,6 +78,8 @@ EXAMPLES:
 		Action:             s3GatewayMain,
 		CustomHelpTemplate: s3GatewayTemplate,
 		HideHelpCommand:    true,
+	}, func(arg string) minio.Gateway {
+		return &S3{arg}
 	})
 }
 
@@ -240,6 +244,20 @@ func (l *s3Objects) ListObjectsV2(bucket, prefix, continuationToken, delimiter s
 	return minio.FromMinioClientListBucketV2Result(bucket, result), nil
 }
 
+// sseCustomerHeaders returns the SSE-C headers of requests with the
+// customer provided key, none if the key is not set.
+func sseCustomerHeaders(encryptionKey []byte) map[string]string {
+	if encryptionKey == nil {
+		return nil
+	}
+	keyMD5 := md5.Sum(encryptionKey)
+	return map[string]string{
+		minio.SSECustomerAlgorithm: minio.SSECustomerAlgorithmAES256,
+		minio.SSECustomerKey:       base64.StdEncoding.EncodeToString(encryptionKey),
+		minio.SSECustomerKeyMD5:    base64.StdEncoding.EncodeToString(keyMD5[:]),
+	}
+}
+
 // GetObject reads an object from S3. Supports additional
 // parameters like offset and length which are synonymous with
 // HTTP Range requests.
@@ -247,11 +265,24 @@ func (l *s3Objects) ListObjectsV2(bucket, prefix, continuationToken, delimiter s
 // startOffset indicates the starting read location of the object.
 // length indicates the total length of the object.
 func (l *s3Objects) GetObject(bucket string, key string, startOffset int64, length int64, writer io.Writer, etag string) error {
+	return l.getObject(bucket, key, startOffset, length, writer, nil)
+}
+
+// GetObjectWithCustomerKey reads an object encrypted by S3 with the
+// customer provided key.
+func (l *s3Objects) GetObjectWithCustomerKey(bucket string, key string, startOffset int64, length int64, writer io.Writer, etag string, encryptionKey []byte) error {
+	return l.getObject(bucket, key, startOffset, length, writer, encryptionKey)
+}
+
+func (l *s3Objects) getObject(bucket string, key string, startOffset int64, length int64, writer io.Writer, encryptionKey []byte) error {
 	if length < 0 && length != -1 {
 		return minio.ErrorRespToObjectError(errors.Trace(minio.InvalidRange{}), bucket, key)
 	}
 
 	opts := miniogo.GetObjectOptions{}
+	for k, v := range sseCustomerHeaders(encryptionKey) {
+		opts.Set(k, v)
+	}
 	if startOffset >= 0 && length >= 0 {
 		if err := opts.SetRange(startOffset, startOffset+length-1); err != nil {
 			return minio.ErrorRespToObjectError(errors.Trace(err), bucket, key)
@@ -271,7 +302,21 @@ func (l *s3Objects) GetObject(bucket string, key string, startOffset int64, leng
 
 // GetObjectInfo reads object info and replies back ObjectInfo
 func (l *s3Objects) GetObjectInfo(bucket string, object string) (objInfo minio.ObjectInfo, err error) {
-	oi, err := l.Client.StatObject(bucket, object, miniogo.StatObjectOptions{})
+	return l.getObjectInfo(bucket, object, nil)
+}
+
+// GetObjectInfoWithCustomerKey reads the info of an object encrypted
+// by S3 with the customer provided key.
+func (l *s3Objects) GetObjectInfoWithCustomerKey(bucket string, object string, encryptionKey []byte) (objInfo minio.ObjectInfo, err error) {
+	return l.getObjectInfo(bucket, object, encryptionKey)
+}
+
+func (l *s3Objects) getObjectInfo(bucket string, object string, encryptionKey []byte) (objInfo minio.ObjectInfo, err error) {
+	opts := miniogo.StatObjectOptions{}
+	for k, v := range sseCustomerHeaders(encryptionKey) {
+		opts.Set(k, v)
+	}
+	oi, err := l.Client.StatObject(bucket, object, opts)
 	if err != nil {
 		return minio.ObjectInfo{}, minio.ErrorRespToObjectError(errors.Trace(err), bucket, object)
 	}
@@ -289,6 +334,21 @@ func (l *s3Objects) PutObject(bucket string, object string, data *hash.Reader, m
 	return minio.FromMinioClientObjectInfo(bucket, oi), nil
 }
 
+// PutObjectWithCustomerKey creates a new object encrypted by S3 with
+// the customer provided key.
+func (l *s3Objects) PutObjectWithCustomerKey(bucket string, object string, data *hash.Reader, metadata map[string]string, encryptionKey []byte) (objInfo minio.ObjectInfo, err error) {
+	m := minio.ToMinioClientMetadata(metadata)
+	for k, v := range sseCustomerHeaders(encryptionKey) {
+		m[k] = v
+	}
+	oi, err := l.Client.PutObject(bucket, object, data, data.Size(), data.MD5Base64String(), data.SHA256HexString(), m)
+	if err != nil {
+		return objInfo, minio.ErrorRespToObjectError(errors.Trace(err), bucket, object)
+	}
+
+	return minio.FromMinioClientObjectInfo(bucket, oi), nil
+}
+
 // CopyObject copies an object from source bucket to a destination bucket.
 func (l *s3Objects) CopyObject(srcBucket string, srcObject string, dstBucket string, dstObject string, metadata map[string]string, srcEtag string) (objInfo minio.ObjectInfo, err error) {
 	// Set this header such that following CopyObject() always sets the right metadata on the destination.
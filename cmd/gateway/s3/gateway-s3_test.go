@@ -18,6 +18,7 @@ package s3
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	miniogo "github.com/minio/minio-go"
@@ -125,3 +126,20 @@ func TestS3ToObjectError(t *testing.T) {
 		}
 	}
 }
+
+func TestSSECustomerHeaders(t *testing.T) {
+	var _ minio.GatewaySSECustomer = &s3Objects{}
+
+	if headers := sseCustomerHeaders(nil); headers != nil {
+		t.Errorf("Expected no headers without key, got %v", headers)
+	}
+	headers := sseCustomerHeaders([]byte("32byteslongsecretkeymustprovided"))
+	expected := map[string]string{
+		minio.SSECustomerAlgorithm: "AES256",
+		minio.SSECustomerKey:       "MzJieXRlc2xvbmdzZWNyZXRrZXltdXN0cHJvdmlkZWQ=",
+		minio.SSECustomerKeyMD5:    "7PpPLAK26ONlVUGOWlusfg==",
+	}
+	if !reflect.DeepEqual(headers, expected) {
+		t.Errorf("Expected %v, got %v", expected, headers)
+	}
+}
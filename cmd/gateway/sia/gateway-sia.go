@@ -47,7 +47,7 @@ const (
 )
 
 type siaObjects struct {
-	minio.GatewayUnsupported
+	*minio.GatewayMultipart
 	Address  string // Address and port of Sia Daemon.
 	TempDir  string // Temporary storage location for file transfers.
 	RootDir  string // Root directory to store files on Sia.
@@ -75,7 +75,8 @@ ENVIRONMENT VARIABLES: (Default values in parenthesis)
   UPDATE:
      MINIO_UPDATE: To turn off in-place upgrades, set this value to "off".
 
-  SIA_TEMP_DIR:        The name of the local Sia temporary storage directory. (.sia_temp)
+  SIA_TEMP_DIR:        The name of the local Sia temporary storage directory, parts of
+                       multipart uploads are staged here until the upload completes. (.sia_temp)
   SIA_API_PASSWORD:    API password for Sia daemon. (default is empty)
 
 EXAMPLES:
@@ -90,6 +91,8 @@ EXAMPLES:
 		Action:             siaGatewayMain,
 		CustomHelpTemplate: siaGatewayTemplate,
 		HideHelpCommand:    true,
+	}, func(arg string) minio.Gateway {
+		return &Sia{arg}
 	})
 }
 
@@ -147,6 +150,12 @@ func (g *Sia) NewGatewayLayer(creds auth.Credentials) (minio.ObjectLayer, error)
 		return nil, err
 	}
 
+	// Sia has no multipart API, parts are staged in the temp
+	// directory and uploaded as one file.
+	if sia.GatewayMultipart, err = minio.NewGatewayMultipart(sia.TempDir, sia); err != nil {
+		return nil, err
+	}
+
 	colorBlue := color.New(color.FgBlue).SprintfFunc()
 	colorBold := color.New(color.Bold).SprintFunc()
 
@@ -0,0 +1,368 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package swift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio/pkg/errors"
+)
+
+// Authentication versions of Swift.
+const (
+	swiftTempAuth   = 1
+	swiftKeystoneV2 = 2
+	swiftKeystoneV3 = 3
+)
+
+// swiftError - error response of the Swift API.
+type swiftError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e swiftError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("swift: %s", http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("swift: %s: %s", http.StatusText(e.StatusCode), e.Message)
+}
+
+// newSwiftError - returns the error of a non-2xx response.
+func newSwiftError(resp *http.Response) error {
+	// Only the beginning of error pages is kept.
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return swiftError{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(msg)),
+	}
+}
+
+// non2xx returns true for non-success HTTP status codes.
+func non2xx(code int) bool {
+	return code < 200 || code > 299
+}
+
+// swiftAuthVersion - returns the authentication version of authURL,
+// Keystone URLs end with their API version.
+func swiftAuthVersion(authURL string) int {
+	u := strings.TrimSuffix(authURL, "/")
+	switch {
+	case strings.HasSuffix(u, "/v3"):
+		return swiftKeystoneV3
+	case strings.HasSuffix(u, "/v2.0"), strings.HasSuffix(u, "/v2"):
+		return swiftKeystoneV2
+	}
+	return swiftTempAuth
+}
+
+// swiftClient - client of the Swift API. Tokens are requested on first
+// use and renewed once they expire.
+type swiftClient struct {
+	authURL     string
+	authVersion int
+	user        string
+	key         string
+	// Tenant, or project, of Keystone tokens.
+	tenant string
+	// Domain of the user and project of Keystone v3 tokens.
+	domain string
+	// Region of the storage URL in the Keystone service catalog, the
+	// first one is used if empty.
+	region     string
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	storageURL string
+	token      string
+}
+
+// authenticate - requests a token and the storage URL of the account.
+func (c *swiftClient) authenticate() (storageURL, token string, err error) {
+	switch c.authVersion {
+	case swiftTempAuth:
+		return c.authenticateTempAuth()
+	case swiftKeystoneV2:
+		return c.authenticateKeystoneV2()
+	case swiftKeystoneV3:
+		return c.authenticateKeystoneV3()
+	}
+	return "", "", errors.Trace(fmt.Errorf("swift: unsupported auth version %d", c.authVersion))
+}
+
+func (c *swiftClient) authenticateTempAuth() (storageURL, token string, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.authURL, nil)
+	if err != nil {
+		return "", "", errors.Trace(err)
+	}
+	req.Header.Set("X-Auth-User", c.user)
+	req.Header.Set("X-Auth-Key", c.key)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", "", errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if non2xx(resp.StatusCode) {
+		return "", "", errors.Trace(newSwiftError(resp))
+	}
+
+	storageURL = resp.Header.Get("X-Storage-Url")
+	token = resp.Header.Get("X-Auth-Token")
+	if storageURL == "" || token == "" {
+		return "", "", errors.Trace(fmt.Errorf("swift: missing storage URL or token in auth response"))
+	}
+	return storageURL, token, nil
+}
+
+// postKeystone - posts a Keystone auth request, the response is
+// decoded into v.
+func (c *swiftClient) postKeystone(path string, body, v interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	resp, err := c.httpClient.Post(strings.TrimSuffix(c.authURL, "/")+path, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if non2xx(resp.StatusCode) {
+		return nil, errors.Trace(newSwiftError(resp))
+	}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return resp, nil
+}
+
+// keystoneEndpoint - endpoint of a service in a Keystone catalog.
+type keystoneEndpoint struct {
+	Region string `json:"region"`
+	// Keystone v2 URL.
+	PublicURL string `json:"publicURL"`
+	// Keystone v3 URL and interface.
+	Interface string `json:"interface"`
+	URL       string `json:"url"`
+}
+
+// keystoneService - service of a Keystone catalog.
+type keystoneService struct {
+	Type      string             `json:"type"`
+	Endpoints []keystoneEndpoint `json:"endpoints"`
+}
+
+// objectStoreURL - returns the public object-store URL of region in
+// catalog.
+func objectStoreURL(catalog []keystoneService, region string) (string, error) {
+	for _, service := range catalog {
+		if service.Type != "object-store" {
+			continue
+		}
+		for _, endpoint := range service.Endpoints {
+			if region != "" && endpoint.Region != region {
+				continue
+			}
+			if endpoint.PublicURL != "" {
+				return endpoint.PublicURL, nil
+			}
+			if endpoint.Interface == "public" && endpoint.URL != "" {
+				return endpoint.URL, nil
+			}
+		}
+	}
+	return "", errors.Trace(fmt.Errorf("swift: no public object-store endpoint in service catalog"))
+}
+
+func (c *swiftClient) authenticateKeystoneV2() (storageURL, token string, err error) {
+	var auth struct {
+		Access struct {
+			Token struct {
+				ID string `json:"id"`
+			} `json:"token"`
+			ServiceCatalog []keystoneService `json:"serviceCatalog"`
+		} `json:"access"`
+	}
+	body := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"passwordCredentials": map[string]string{
+				"username": c.user,
+				"password": c.key,
+			},
+			"tenantName": c.tenant,
+		},
+	}
+	if _, err = c.postKeystone("/tokens", body, &auth); err != nil {
+		return "", "", err
+	}
+
+	if storageURL, err = objectStoreURL(auth.Access.ServiceCatalog, c.region); err != nil {
+		return "", "", err
+	}
+	return storageURL, auth.Access.Token.ID, nil
+}
+
+func (c *swiftClient) authenticateKeystoneV3() (storageURL, token string, err error) {
+	var auth struct {
+		Token struct {
+			Catalog []keystoneService `json:"catalog"`
+		} `json:"token"`
+	}
+	domain := map[string]string{"name": c.domain}
+	body := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"password"},
+				"password": map[string]interface{}{
+					"user": map[string]interface{}{
+						"name":     c.user,
+						"password": c.key,
+						"domain":   domain,
+					},
+				},
+			},
+			"scope": map[string]interface{}{
+				"project": map[string]interface{}{
+					"name":   c.tenant,
+					"domain": domain,
+				},
+			},
+		},
+	}
+	resp, err := c.postKeystone("/auth/tokens", body, &auth)
+	if err != nil {
+		return "", "", err
+	}
+
+	if storageURL, err = objectStoreURL(auth.Token.Catalog, c.region); err != nil {
+		return "", "", err
+	}
+	return storageURL, resp.Header.Get("X-Subject-Token"), nil
+}
+
+// getToken - returns the storage URL and a token, authenticating if
+// there is no token yet or if expired is the token which expired.
+func (c *swiftClient) getToken(expired string) (storageURL, token string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == "" || c.token == expired {
+		if c.storageURL, c.token, err = c.authenticate(); err != nil {
+			c.token = ""
+			return "", "", err
+		}
+	}
+	return c.storageURL, c.token, nil
+}
+
+// swiftPath - returns the escaped path of an object, or of a container
+// if object is empty, or of the account if both are empty.
+func swiftPath(container, object string) string {
+	if container == "" {
+		return ""
+	}
+	p := "/" + url.PathEscape(container)
+	if object != "" {
+		segments := strings.Split(object, "/")
+		for i := range segments {
+			segments[i] = url.PathEscape(segments[i])
+		}
+		p += "/" + strings.Join(segments, "/")
+	}
+	return p
+}
+
+// do - sends a request for an object, container or account, the
+// response body must be closed. Requests without body are retried
+// with a new token if the token expired.
+func (c *swiftClient) do(method, container, object string, query url.Values, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	var expired string
+	for {
+		storageURL, token, err := c.getToken(expired)
+		if err != nil {
+			return nil, err
+		}
+
+		u := strings.TrimSuffix(storageURL, "/") + swiftPath(container, object)
+		if len(query) > 0 {
+			u += "?" + query.Encode()
+		}
+		req, err := http.NewRequest(method, u, body)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("X-Auth-Token", token)
+		if body != nil {
+			req.ContentLength = size
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if resp.StatusCode == http.StatusUnauthorized && body == nil && expired == "" {
+			resp.Body.Close()
+			expired = token
+			continue
+		}
+		if non2xx(resp.StatusCode) {
+			err = newSwiftError(resp)
+			resp.Body.Close()
+			return nil, errors.Trace(err)
+		}
+		return resp, nil
+	}
+}
+
+// call - sends a request and discards the response body, returns the
+// response headers.
+func (c *swiftClient) call(method, container, object string, query url.Values, header http.Header, body io.Reader, size int64) (http.Header, error) {
+	resp, err := c.do(method, container, object, query, header, body, size)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.Header, nil
+}
+
+// getJSON - lists an account or container in JSON format into v.
+func (c *swiftClient) getJSON(container, object string, query url.Values, v interface{}) error {
+	if query == nil {
+		query = make(url.Values)
+	}
+	query.Set("format", "json")
+	resp, err := c.do(http.MethodGet, container, object, query, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Empty listings are returned with 204 No Content.
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return errors.Trace(json.NewDecoder(resp.Body).Decode(v))
+}
@@ -0,0 +1,929 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package swift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/auth"
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+	sha256 "github.com/minio/sha256-simd"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+const (
+	swiftBackend = "swift"
+
+	// Segments and metadata of multipart uploads, segments of
+	// completed uploads are kept as segments of Static Large Objects.
+	swiftMultipartTemplate = minio.GatewayMinioSysTmp + "multipart/v1/%s.%x/"
+	swiftMultipartMetaFile = "swift.json"
+	swiftS3MinPartSize     = 5 * humanize.MiByte
+
+	// Time format of last modified times in Swift listings.
+	swiftTimeFormat = "2006-01-02T15:04:05.999999"
+)
+
+func init() {
+	const swiftGatewayTemplate = `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} {{if .VisibleFlags}}[FLAGS]{{end}} AUTH-URL
+{{if .VisibleFlags}}
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}{{end}}
+AUTH-URL:
+  Swift authentication URL. Keystone URLs end with their API version,
+  e.g. https://keystone.example.com:5000/v3, other URLs use TempAuth.
+
+ENVIRONMENT VARIABLES:
+  ACCESS:
+     MINIO_ACCESS_KEY: Swift user, e.g. account:user for TempAuth.
+     MINIO_SECRET_KEY: Swift key or Keystone password of the user.
+
+  BROWSER:
+     MINIO_BROWSER: To disable web browser access, set this value to "off".
+
+  UPDATE:
+     MINIO_UPDATE: To turn off in-place upgrades, set this value to "off".
+
+  SWIFT_AUTH_VERSION:  Authentication version, 1 for TempAuth, 2 or 3 for Keystone. (detected from AUTH-URL)
+  SWIFT_TENANT:        Keystone tenant, or project, of the user.
+  SWIFT_DOMAIN:        Keystone v3 domain of the user and project. (Default)
+  SWIFT_REGION:        Region of the object-store endpoint. (first region of the catalog)
+
+EXAMPLES:
+  1. Start minio gateway server for Swift with TempAuth.
+      $ export MINIO_ACCESS_KEY=account:user
+      $ export MINIO_SECRET_KEY=swiftkey
+      $ {{.HelpName}} http://swift.example.com:8080/auth/v1.0
+
+  2. Start minio gateway server for Swift with Keystone v3.
+      $ export MINIO_ACCESS_KEY=user
+      $ export MINIO_SECRET_KEY=password
+      $ export SWIFT_TENANT=project
+      $ {{.HelpName}} https://keystone.example.com:5000/v3
+
+`
+
+	minio.RegisterGatewayCommand(cli.Command{
+		Name:               swiftBackend,
+		Usage:              "OpenStack Swift.",
+		Action:             swiftGatewayMain,
+		CustomHelpTemplate: swiftGatewayTemplate,
+		HideHelpCommand:    true,
+	}, func(arg string) minio.Gateway {
+		return &Swift{arg}
+	})
+}
+
+// Handler for 'minio gateway swift' command line.
+func swiftGatewayMain(ctx *cli.Context) {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, swiftBackend, 1)
+	}
+
+	authURL := ctx.Args().First()
+	// Validate gateway arguments.
+	minio.FatalIf(minio.ValidateGatewayArguments(ctx.GlobalString("address"), authURL), "Invalid argument")
+
+	minio.StartGateway(ctx, &Swift{authURL})
+}
+
+// Swift implements Gateway.
+type Swift struct {
+	authURL string
+}
+
+// Name implements Gateway interface.
+func (g *Swift) Name() string {
+	return swiftBackend
+}
+
+// NewGatewayLayer returns swift gateway layer, implements ObjectLayer
+// interface to talk to Swift.
+func (g *Swift) NewGatewayLayer(creds auth.Credentials) (minio.ObjectLayer, error) {
+	authVersion := swiftAuthVersion(g.authURL)
+	if v := os.Getenv("SWIFT_AUTH_VERSION"); v != "" {
+		var err error
+		if authVersion, err = strconv.Atoi(v); err != nil || authVersion < swiftTempAuth || authVersion > swiftKeystoneV3 {
+			return nil, fmt.Errorf("invalid SWIFT_AUTH_VERSION ‘%s’, expected 1, 2 or 3", v)
+		}
+	}
+
+	domain := os.Getenv("SWIFT_DOMAIN")
+	if domain == "" {
+		domain = "Default"
+	}
+
+	client := &swiftClient{
+		authURL:     g.authURL,
+		authVersion: authVersion,
+		user:        creds.AccessKey,
+		key:         creds.SecretKey,
+		tenant:      os.Getenv("SWIFT_TENANT"),
+		domain:      domain,
+		region:      os.Getenv("SWIFT_REGION"),
+		httpClient:  &http.Client{Transport: minio.NewCustomHTTPTransport()},
+	}
+
+	// Fail early on invalid credentials.
+	if _, _, err := client.getToken(""); err != nil {
+		return nil, err
+	}
+	return &swiftObjects{client: client}, nil
+}
+
+// Production - swift gateway is not yet production ready.
+func (g *Swift) Production() bool {
+	return false
+}
+
+// swiftObjects - Implements Object layer for Swift.
+type swiftObjects struct {
+	minio.GatewayUnsupported
+	client *swiftClient
+}
+
+// Convert swift errors to minio object layer errors.
+func swiftToObjectError(err error, params ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	e, ok := err.(*errors.Error)
+	if !ok {
+		// Code should be fixed if this function is called without doing errors.Trace()
+		// Else handling different situations in this function makes this function complicated.
+		minio.ErrorIf(err, "Expected type *Error")
+		return err
+	}
+
+	err = e.Cause
+	bucket := ""
+	object := ""
+	if len(params) >= 1 {
+		bucket = params[0]
+	}
+	if len(params) == 2 {
+		object = params[1]
+	}
+
+	swiftErr, ok := err.(swiftError)
+	if !ok {
+		// We don't interpret non Swift errors. As swift errors will
+		// have StatusCode to help to convert to object errors.
+		return e
+	}
+
+	switch swiftErr.StatusCode {
+	case http.StatusNotFound:
+		if object != "" {
+			err = minio.ObjectNotFound{
+				Bucket: bucket,
+				Object: object,
+			}
+		} else {
+			err = minio.BucketNotFound{Bucket: bucket}
+		}
+	case http.StatusConflict:
+		// Containers are only deleted when empty.
+		err = minio.BucketNotEmpty{Bucket: bucket}
+	case http.StatusUnprocessableEntity:
+		// The MD5 of uploaded data differs from its ETag.
+		err = hash.BadDigest{}
+	case http.StatusPreconditionFailed:
+		err = minio.InvalidETag{}
+	case http.StatusRequestEntityTooLarge:
+		err = minio.ObjectTooLarge{
+			Bucket: bucket,
+			Object: object,
+		}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		err = minio.PrefixAccessDenied{
+			Bucket: bucket,
+			Object: object,
+		}
+	case http.StatusBadRequest:
+		if object != "" {
+			err = minio.ObjectNameInvalid{
+				Bucket: bucket,
+				Object: object,
+			}
+		} else {
+			err = minio.BucketNameInvalid{Bucket: bucket}
+		}
+	}
+	e.Cause = err
+	return e
+}
+
+// Headers of common S3 metadata which Swift stores with objects.
+var swiftObjectHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Type",
+	"Expires",
+}
+
+// s3MetaToSwiftHeaders converts metadata meant for S3 PUT/COPY object
+// into Swift object headers. S3 user-metadata is translated to Swift
+// metadata by replacing the `X-Amz-Meta-` prefix by `X-Object-Meta-`.
+func s3MetaToSwiftHeaders(s3Metadata map[string]string) http.Header {
+	header := make(http.Header)
+	for k, v := range s3Metadata {
+		k = http.CanonicalHeaderKey(k)
+		if strings.HasPrefix(k, "X-Amz-Meta-") {
+			header.Set("X-Object-Meta-"+strings.TrimPrefix(k, "X-Amz-Meta-"), v)
+			continue
+		}
+		for _, h := range swiftObjectHeaders {
+			if k == h {
+				header.Set(k, v)
+			}
+		}
+	}
+	return header
+}
+
+// swiftHeadersToS3Meta converts Swift object headers to S3 metadata. It
+// is the reverse of s3MetaToSwiftHeaders.
+func swiftHeadersToS3Meta(header http.Header) map[string]string {
+	s3Metadata := make(map[string]string)
+	for k := range header {
+		k = http.CanonicalHeaderKey(k)
+		if strings.HasPrefix(k, "X-Object-Meta-") {
+			s3Metadata["X-Amz-Meta-"+strings.TrimPrefix(k, "X-Object-Meta-")] = header.Get(k)
+		}
+	}
+	for _, h := range swiftObjectHeaders {
+		if v := header.Get(h); v != "" {
+			s3Metadata[h] = v
+		}
+	}
+	return s3Metadata
+}
+
+// swiftHeadersToObjectInfo - returns the info of an object from the
+// headers of a HEAD or GET response.
+func swiftHeadersToObjectInfo(bucket, object string, header http.Header) minio.ObjectInfo {
+	size, _ := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	modTime, _ := http.ParseTime(header.Get("Last-Modified"))
+	return minio.ObjectInfo{
+		Bucket:          bucket,
+		Name:            object,
+		ModTime:         modTime,
+		Size:            size,
+		ETag:            minio.ToS3ETag(strings.Trim(header.Get("Etag"), `"`)),
+		ContentType:     header.Get("Content-Type"),
+		ContentEncoding: header.Get("Content-Encoding"),
+		UserDefined:     swiftHeadersToS3Meta(header),
+	}
+}
+
+// parseSwiftTime - parses last modified times of Swift listings, which
+// are in UTC.
+func parseSwiftTime(s string) time.Time {
+	t, err := time.Parse(swiftTimeFormat, s)
+	if err != nil {
+		return time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+// Shutdown - save any gateway metadata to disk
+// if necessary and reload upon next restart.
+func (s *swiftObjects) Shutdown() error {
+	return nil
+}
+
+// StorageInfo - Not relevant to Swift backend.
+func (s *swiftObjects) StorageInfo() (si minio.StorageInfo) {
+	return si
+}
+
+// MakeBucketWithLocation - Create a new container on swift backend.
+func (s *swiftObjects) MakeBucketWithLocation(bucket, location string) error {
+	// Container names are less restricted than bucket names.
+	if !minio.IsValidBucketName(bucket) {
+		return errors.Trace(minio.BucketNameInvalid{Bucket: bucket})
+	}
+
+	resp, err := s.client.do(http.MethodPut, bucket, "", nil, nil, nil, 0)
+	if err != nil {
+		return swiftToObjectError(err, bucket)
+	}
+	resp.Body.Close()
+	// Existing containers are accepted without being created.
+	if resp.StatusCode == http.StatusAccepted {
+		return errors.Trace(minio.BucketExists{Bucket: bucket})
+	}
+	return nil
+}
+
+// GetBucketInfo - Get bucket metadata.
+func (s *swiftObjects) GetBucketInfo(bucket string) (bi minio.BucketInfo, err error) {
+	header, err := s.client.call(http.MethodHead, bucket, "", nil, nil, nil, 0)
+	if err != nil {
+		return bi, swiftToObjectError(err, bucket)
+	}
+
+	created := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	if ts, perr := strconv.ParseFloat(header.Get("X-Timestamp"), 64); perr == nil {
+		created = time.Unix(int64(ts), 0).UTC()
+	}
+	return minio.BucketInfo{
+		Name:    bucket,
+		Created: created,
+	}, nil
+}
+
+// swiftContainer - container of a Swift account listing.
+type swiftContainer struct {
+	Name         string `json:"name"`
+	LastModified string `json:"last_modified"`
+}
+
+// ListBuckets - Lists all swift containers.
+func (s *swiftObjects) ListBuckets() (buckets []minio.BucketInfo, err error) {
+	var marker string
+	for {
+		var containers []swiftContainer
+		query := url.Values{}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		if err = s.client.getJSON("", "", query, &containers); err != nil {
+			return nil, swiftToObjectError(err)
+		}
+		if len(containers) == 0 {
+			return buckets, nil
+		}
+
+		for _, container := range containers {
+			marker = container.Name
+			// Containers of other applications may not be valid
+			// bucket names.
+			if !minio.IsValidBucketName(container.Name) {
+				continue
+			}
+			buckets = append(buckets, minio.BucketInfo{
+				Name:    container.Name,
+				Created: parseSwiftTime(container.LastModified),
+			})
+		}
+	}
+}
+
+// DeleteBucket - delete a container on swift.
+func (s *swiftObjects) DeleteBucket(bucket string) error {
+	_, err := s.client.call(http.MethodDelete, bucket, "", nil, nil, nil, 0)
+	return swiftToObjectError(err, bucket)
+}
+
+// swiftObject - object, or common prefix, of a Swift container listing.
+type swiftObject struct {
+	Name         string `json:"name"`
+	Subdir       string `json:"subdir"`
+	Hash         string `json:"hash"`
+	Bytes        int64  `json:"bytes"`
+	ContentType  string `json:"content_type"`
+	LastModified string `json:"last_modified"`
+}
+
+// listObjects - lists a page of objects of a container.
+func (s *swiftObjects) listObjects(bucket, prefix, marker, delimiter string, limit int) (objects []swiftObject, err error) {
+	query := url.Values{}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if marker != "" {
+		query.Set("marker", marker)
+	}
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if err = s.client.getJSON(bucket, "", query, &objects); err != nil {
+		return nil, swiftToObjectError(err, bucket)
+	}
+	return objects, nil
+}
+
+// ListObjects - lists all objects in a container filtered by prefix and
+// marker.
+func (s *swiftObjects) ListObjects(bucket, prefix, marker, delimiter string, maxKeys int) (result minio.ListObjectsInfo, err error) {
+	if maxKeys == 0 {
+		return result, nil
+	}
+
+	for {
+		var entries []swiftObject
+		if entries, err = s.listObjects(bucket, prefix, marker, delimiter, maxKeys); err != nil {
+			return result, err
+		}
+
+		for _, entry := range entries {
+			if entry.Subdir != "" {
+				// Objects under a common prefix used as marker
+				// are listed as the prefix again.
+				if entry.Subdir != marker && entry.Subdir != minio.GatewayMinioSysTmp {
+					result.Prefixes = append(result.Prefixes, entry.Subdir)
+				}
+				continue
+			}
+			if strings.HasPrefix(entry.Name, minio.GatewayMinioSysTmp) {
+				continue
+			}
+			result.Objects = append(result.Objects, minio.ObjectInfo{
+				Bucket:      bucket,
+				Name:        entry.Name,
+				ModTime:     parseSwiftTime(entry.LastModified),
+				Size:        entry.Bytes,
+				ETag:        minio.ToS3ETag(entry.Hash),
+				ContentType: entry.ContentType,
+			})
+		}
+
+		result.IsTruncated = maxKeys > 0 && len(entries) == maxKeys
+		if len(entries) > 0 {
+			marker = entries[len(entries)-1].Name
+			if marker == "" {
+				marker = entries[len(entries)-1].Subdir
+			}
+		}
+		if !result.IsTruncated || len(result.Objects) > 0 || len(result.Prefixes) > 0 {
+			break
+		}
+	}
+
+	if result.IsTruncated {
+		result.NextMarker = marker
+	}
+	return result, nil
+}
+
+// ListObjectsV2 - list all objects in swift container filtered by prefix
+func (s *swiftObjects) ListObjectsV2(bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (result minio.ListObjectsV2Info, err error) {
+	marker := continuationToken
+	if marker == "" {
+		marker = startAfter
+	}
+
+	var resultV1 minio.ListObjectsInfo
+	resultV1, err = s.ListObjects(bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return result, err
+	}
+
+	result.Objects = resultV1.Objects
+	result.Prefixes = resultV1.Prefixes
+	result.ContinuationToken = continuationToken
+	result.NextContinuationToken = resultV1.NextMarker
+	result.IsTruncated = resultV1.IsTruncated
+	return result, nil
+}
+
+// GetObject - reads an object from swift. Supports additional
+// parameters like offset and length which are synonymous with
+// HTTP Range requests.
+//
+// startOffset indicates the starting read location of the object.
+// length indicates the total length of the object.
+func (s *swiftObjects) GetObject(bucket, object string, startOffset int64, length int64, writer io.Writer, etag string) error {
+	// startOffset cannot be negative.
+	if startOffset < 0 {
+		return swiftToObjectError(errors.Trace(minio.InvalidRange{}), bucket, object)
+	}
+
+	header := make(http.Header)
+	if length > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", startOffset, startOffset+length-1))
+	} else if startOffset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", startOffset))
+	}
+	if etag != "" {
+		header.Set("If-Match", swiftETag(etag))
+	}
+
+	resp, err := s.client.do(http.MethodGet, bucket, object, nil, header, nil, 0)
+	if err != nil {
+		return swiftToObjectError(err, bucket, object)
+	}
+	_, err = io.Copy(writer, resp.Body)
+	resp.Body.Close()
+	return errors.Trace(err)
+}
+
+// swiftETag - returns the Swift ETag of an ETag returned by the
+// gateway, which marks ETags as not being checksums.
+func swiftETag(etag string) string {
+	return strings.TrimSuffix(strings.Trim(etag, `"`), "-1")
+}
+
+// GetObjectInfo - reads object headers and replies back minio.ObjectInfo.
+func (s *swiftObjects) GetObjectInfo(bucket, object string) (objInfo minio.ObjectInfo, err error) {
+	header, err := s.client.call(http.MethodHead, bucket, object, nil, nil, nil, 0)
+	if err != nil {
+		return objInfo, swiftToObjectError(err, bucket, object)
+	}
+	return swiftHeadersToObjectInfo(bucket, object, header), nil
+}
+
+// swiftSegment - segment of a Static Large Object manifest.
+type swiftSegment struct {
+	// Path of the segment, `/container/object`.
+	Path string `json:"path"`
+	ETag string `json:"etag"`
+	Size int64  `json:"size_bytes"`
+}
+
+// swiftSegmentPath - returns the path of a segment in manifests, which
+// is not escaped.
+func swiftSegmentPath(container, object string) string {
+	return "/" + container + "/" + object
+}
+
+// manifestSegments - returns the paths of the segments of object if it
+// is a Static Large Object, which are deleted along with the object.
+func (s *swiftObjects) manifestSegments(bucket, object string) ([]string, error) {
+	header, err := s.client.call(http.MethodHead, bucket, object, nil, nil, nil, 0)
+	if err != nil {
+		if e, ok := errors.Cause(err).(swiftError); ok && e.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, swiftToObjectError(err, bucket, object)
+	}
+	if !strings.EqualFold(header.Get("X-Static-Large-Object"), "true") {
+		return nil, nil
+	}
+
+	// Manifests are returned with the names of their segments.
+	var manifest []struct {
+		Name string `json:"name"`
+	}
+	query := url.Values{"multipart-manifest": {"get"}}
+	if err = s.client.getJSON(bucket, object, query, &manifest); err != nil {
+		return nil, swiftToObjectError(err, bucket, object)
+	}
+	var segments []string
+	for _, segment := range manifest {
+		segments = append(segments, segment.Name)
+	}
+	return segments, nil
+}
+
+// deleteSegments - deletes segments of Static Large Objects, errors
+// are logged as segments are only left behind.
+func (s *swiftObjects) deleteSegments(segments []string) {
+	for _, segment := range segments {
+		parts := strings.SplitN(strings.TrimPrefix(segment, "/"), "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		_, err := s.client.call(http.MethodDelete, parts[0], parts[1], nil, nil, nil, 0)
+		if e, ok := errors.Cause(err).(swiftError); ok && e.StatusCode == http.StatusNotFound {
+			continue
+		}
+		minio.ErrorIf(err, "Unable to delete segment %s", segment)
+	}
+}
+
+// PutObject - Create a new object with the incoming data.
+func (s *swiftObjects) PutObject(bucket, object string, data *hash.Reader, metadata map[string]string) (objInfo minio.ObjectInfo, err error) {
+	segments, err := s.manifestSegments(bucket, object)
+	if err != nil {
+		return objInfo, err
+	}
+
+	header := s3MetaToSwiftHeaders(metadata)
+	// Swift verifies uploaded data against the ETag.
+	if md5Hex := data.MD5HexString(); md5Hex != "" {
+		header.Set("Etag", md5Hex)
+	}
+	if _, err = s.client.call(http.MethodPut, bucket, object, nil, header, data, data.Size()); err != nil {
+		return objInfo, swiftToObjectError(err, bucket, object)
+	}
+	s.deleteSegments(segments)
+	return s.GetObjectInfo(bucket, object)
+}
+
+// CopyObject - Copies an object from source container to destination
+// container, with the metadata replaced by metadata.
+func (s *swiftObjects) CopyObject(srcBucket, srcObject, destBucket, destObject string, metadata map[string]string, srcEtag string) (objInfo minio.ObjectInfo, err error) {
+	header := s3MetaToSwiftHeaders(metadata)
+	if srcBucket == destBucket && srcObject == destObject {
+		// Metadata is updated in place, which keeps the segments of
+		// Static Large Objects.
+		if _, err = s.client.call(http.MethodPost, destBucket, destObject, nil, header, nil, 0); err != nil {
+			return objInfo, swiftToObjectError(err, destBucket, destObject)
+		}
+		return s.GetObjectInfo(destBucket, destObject)
+	}
+
+	segments, err := s.manifestSegments(destBucket, destObject)
+	if err != nil {
+		return objInfo, err
+	}
+	header.Set("X-Copy-From", swiftPath(srcBucket, srcObject))
+	header.Set("X-Fresh-Metadata", "true")
+	if srcEtag != "" {
+		header.Set("If-Match", swiftETag(srcEtag))
+	}
+	if _, err = s.client.call(http.MethodPut, destBucket, destObject, nil, header, nil, 0); err != nil {
+		return objInfo, swiftToObjectError(err, srcBucket, srcObject)
+	}
+	s.deleteSegments(segments)
+	return s.GetObjectInfo(destBucket, destObject)
+}
+
+// DeleteObject - Deletes an object, along with its segments if it is a
+// Static Large Object.
+func (s *swiftObjects) DeleteObject(bucket, object string) error {
+	segments, err := s.manifestSegments(bucket, object)
+	if err != nil {
+		return err
+	}
+	if _, err = s.client.call(http.MethodDelete, bucket, object, nil, nil, nil, 0); err != nil {
+		return swiftToObjectError(err, bucket, object)
+	}
+	s.deleteSegments(segments)
+	return nil
+}
+
+// ListMultipartUploads - It's decided not to support List Multipart Uploads, hence returning empty result.
+func (s *swiftObjects) ListMultipartUploads(bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result minio.ListMultipartsInfo, err error) {
+	// It's decided not to support List Multipart Uploads, hence returning empty result.
+	return result, nil
+}
+
+type swiftMultipartMetadata struct {
+	Name     string            `json:"name"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// getSwiftMultipartPrefix - returns the prefix of the segments and the
+// metadata of a multipart upload.
+func getSwiftMultipartPrefix(objectName, uploadID string) string {
+	return fmt.Sprintf(swiftMultipartTemplate, uploadID, sha256.Sum256([]byte(objectName)))
+}
+
+// getSwiftPartName - returns the name of the segment of a part.
+func getSwiftPartName(objectName, uploadID string, partID int) string {
+	return fmt.Sprintf("%s%05d", getSwiftMultipartPrefix(objectName, uploadID), partID)
+}
+
+func (s *swiftObjects) checkUploadIDExists(bucket, object, uploadID string) error {
+	metadataObject := getSwiftMultipartPrefix(object, uploadID) + swiftMultipartMetaFile
+	_, err := s.client.call(http.MethodHead, bucket, metadataObject, nil, nil, nil, 0)
+	err = swiftToObjectError(err, bucket, object)
+	oerr := minio.ObjectNotFound{
+		Bucket: bucket,
+		Object: object,
+	}
+	if errors.Cause(err) == oerr {
+		err = errors.Trace(minio.InvalidUploadID{
+			UploadID: uploadID,
+		})
+	}
+	return err
+}
+
+// NewMultipartUpload - saves the metadata of the upload, parts are
+// uploaded as segments of a Static Large Object.
+func (s *swiftObjects) NewMultipartUpload(bucket, object string, metadata map[string]string) (uploadID string, err error) {
+	uploadID = minio.MustGetUUID()
+	metadataObject := getSwiftMultipartPrefix(object, uploadID) + swiftMultipartMetaFile
+
+	var jsonData []byte
+	if jsonData, err = json.Marshal(swiftMultipartMetadata{Name: object, Metadata: metadata}); err != nil {
+		return "", errors.Trace(err)
+	}
+	if _, err = s.client.call(http.MethodPut, bucket, metadataObject, nil, nil, bytes.NewReader(jsonData), int64(len(jsonData))); err != nil {
+		return "", swiftToObjectError(err, bucket, object)
+	}
+	return uploadID, nil
+}
+
+// PutObjectPart - uploads a part as a segment.
+func (s *swiftObjects) PutObjectPart(bucket, object, uploadID string, partID int, data *hash.Reader) (info minio.PartInfo, err error) {
+	if err = s.checkUploadIDExists(bucket, object, uploadID); err != nil {
+		return info, err
+	}
+
+	header := make(http.Header)
+	if md5Hex := data.MD5HexString(); md5Hex != "" {
+		header.Set("Etag", md5Hex)
+	}
+	respHeader, err := s.client.call(http.MethodPut, bucket, getSwiftPartName(object, uploadID, partID), nil, header, data, data.Size())
+	if err != nil {
+		return info, swiftToObjectError(err, bucket, object)
+	}
+
+	info.PartNumber = partID
+	info.ETag = strings.Trim(respHeader.Get("Etag"), `"`)
+	info.LastModified = minio.UTCNow()
+	info.Size = data.Size()
+	return info, nil
+}
+
+// listParts - returns the uploaded parts of an upload, sorted by part
+// number.
+func (s *swiftObjects) listParts(bucket, object, uploadID string) (parts []minio.PartInfo, err error) {
+	prefix := getSwiftMultipartPrefix(object, uploadID)
+	var marker string
+	for {
+		var entries []swiftObject
+		if entries, err = s.listObjects(bucket, prefix, marker, "", 0); err != nil {
+			return nil, err
+		}
+		if len(entries) == 0 {
+			break
+		}
+		for _, entry := range entries {
+			marker = entry.Name
+			partNumber, perr := strconv.Atoi(strings.TrimPrefix(entry.Name, prefix))
+			if perr != nil {
+				// Metadata of the upload.
+				continue
+			}
+			parts = append(parts, minio.PartInfo{
+				PartNumber:   partNumber,
+				LastModified: parseSwiftTime(entry.LastModified),
+				ETag:         entry.Hash,
+				Size:         entry.Bytes,
+			})
+		}
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	return parts, nil
+}
+
+// ListObjectParts - lists the segments of an upload.
+func (s *swiftObjects) ListObjectParts(bucket, object, uploadID string, partNumberMarker int, maxParts int) (result minio.ListPartsInfo, err error) {
+	if err = s.checkUploadIDExists(bucket, object, uploadID); err != nil {
+		return result, err
+	}
+
+	result.Bucket = bucket
+	result.Object = object
+	result.UploadID = uploadID
+	result.MaxParts = maxParts
+	result.PartNumberMarker = partNumberMarker
+
+	parts, err := s.listParts(bucket, object, uploadID)
+	if err != nil {
+		return result, err
+	}
+	i := sort.Search(len(parts), func(i int) bool {
+		return parts[i].PartNumber > partNumberMarker
+	})
+	for ; i < len(parts) && len(result.Parts) < maxParts; i++ {
+		result.Parts = append(result.Parts, parts[i])
+	}
+	if i < len(parts) {
+		result.IsTruncated = true
+		if len(result.Parts) != 0 {
+			result.NextPartNumberMarker = result.Parts[len(result.Parts)-1].PartNumber
+		}
+	}
+	return result, nil
+}
+
+// deleteUpload - deletes the metadata and the segments of parts of an
+// upload, except for the segments in keep.
+func (s *swiftObjects) deleteUpload(bucket, object, uploadID string, parts []minio.PartInfo, keep map[int]bool) {
+	var segments []string
+	for _, part := range parts {
+		if !keep[part.PartNumber] {
+			segments = append(segments, swiftSegmentPath(bucket, getSwiftPartName(object, uploadID, part.PartNumber)))
+		}
+	}
+	metadataObject := getSwiftMultipartPrefix(object, uploadID) + swiftMultipartMetaFile
+	segments = append(segments, swiftSegmentPath(bucket, metadataObject))
+	s.deleteSegments(segments)
+}
+
+// AbortMultipartUpload - deletes the segments and the metadata of an
+// upload.
+func (s *swiftObjects) AbortMultipartUpload(bucket, object, uploadID string) (err error) {
+	if err = s.checkUploadIDExists(bucket, object, uploadID); err != nil {
+		return err
+	}
+
+	parts, err := s.listParts(bucket, object, uploadID)
+	if err != nil {
+		return err
+	}
+	s.deleteUpload(bucket, object, uploadID, parts, nil)
+	return nil
+}
+
+// CompleteMultipartUpload - creates the object as a Static Large Object
+// of the segments of the uploaded parts.
+func (s *swiftObjects) CompleteMultipartUpload(bucket, object, uploadID string, uploadedParts []minio.CompletePart) (objInfo minio.ObjectInfo, err error) {
+	if err = s.checkUploadIDExists(bucket, object, uploadID); err != nil {
+		return objInfo, err
+	}
+
+	metadataObject := getSwiftMultipartPrefix(object, uploadID) + swiftMultipartMetaFile
+	resp, err := s.client.do(http.MethodGet, bucket, metadataObject, nil, nil, nil, 0)
+	if err != nil {
+		return objInfo, swiftToObjectError(err, bucket, metadataObject)
+	}
+	var metadata swiftMultipartMetadata
+	err = json.NewDecoder(resp.Body).Decode(&metadata)
+	resp.Body.Close()
+	if err != nil {
+		return objInfo, swiftToObjectError(errors.Trace(err), bucket, metadataObject)
+	}
+
+	parts, err := s.listParts(bucket, object, uploadID)
+	if err != nil {
+		return objInfo, err
+	}
+	partsMap := make(map[int]minio.PartInfo)
+	for _, part := range parts {
+		partsMap[part.PartNumber] = part
+	}
+
+	manifest := make([]swiftSegment, len(uploadedParts))
+	keep := make(map[int]bool)
+	for i, uploadedPart := range uploadedParts {
+		part, ok := partsMap[uploadedPart.PartNumber]
+		if !ok || part.ETag != strings.Trim(uploadedPart.ETag, `"`) {
+			return objInfo, errors.Trace(minio.InvalidPart{})
+		}
+		// Error out if parts except last part sizing < 5MiB.
+		if i < len(uploadedParts)-1 && part.Size < swiftS3MinPartSize {
+			return objInfo, errors.Trace(minio.PartTooSmall{
+				PartNumber: part.PartNumber,
+				PartSize:   part.Size,
+				PartETag:   uploadedPart.ETag,
+			})
+		}
+		manifest[i] = swiftSegment{
+			Path: swiftSegmentPath(bucket, getSwiftPartName(object, uploadID, part.PartNumber)),
+			ETag: part.ETag,
+			Size: part.Size,
+		}
+		keep[part.PartNumber] = true
+	}
+
+	segments, err := s.manifestSegments(bucket, object)
+	if err != nil {
+		return objInfo, err
+	}
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return objInfo, errors.Trace(err)
+	}
+	query := url.Values{"multipart-manifest": {"put"}}
+	header := s3MetaToSwiftHeaders(metadata.Metadata)
+	if _, err = s.client.call(http.MethodPut, bucket, object, query, header, bytes.NewReader(manifestData), int64(len(manifestData))); err != nil {
+		return objInfo, swiftToObjectError(err, bucket, object)
+	}
+
+	// Segments of parts which are not part of the object are deleted
+	// along with the upload.
+	s.deleteUpload(bucket, object, uploadID, parts, keep)
+	s.deleteSegments(segments)
+	return s.GetObjectInfo(bucket, object)
+}
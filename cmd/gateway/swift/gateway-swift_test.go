@@ -0,0 +1,771 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package swift
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/auth"
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+
+	minio "github.com/minio/minio/cmd"
+)
+
+const (
+	stubUser    = "test:tester"
+	stubKey     = "testing"
+	stubAccount = "/v1/AUTH_test"
+)
+
+type stubObject struct {
+	data    []byte
+	header  http.Header
+	modTime time.Time
+	// Segment paths of Static Large Objects.
+	segments []string
+}
+
+// swiftStub - in-process Swift server with TempAuth and Keystone v2
+// and v3 authentication.
+type swiftStub struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	token      int
+	containers map[string]map[string]*stubObject
+}
+
+func newSwiftStub() *swiftStub {
+	s := &swiftStub{containers: make(map[string]map[string]*stubObject)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// expireToken - invalidates the current token.
+func (s *swiftStub) expireToken() {
+	s.mu.Lock()
+	s.token++
+	s.mu.Unlock()
+}
+
+func (s *swiftStub) currentToken() string {
+	return fmt.Sprintf("token-%d", s.token)
+}
+
+func (s *swiftStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/auth/v1.0":
+		if r.Header.Get("X-Auth-User") != stubUser || r.Header.Get("X-Auth-Key") != stubKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Storage-Url", s.URL+stubAccount)
+		w.Header().Set("X-Auth-Token", s.currentToken())
+	case r.URL.Path == "/v2.0/tokens", r.URL.Path == "/v3/auth/tokens":
+		s.serveKeystone(w, r)
+	case strings.HasPrefix(r.URL.Path, stubAccount):
+		if r.Header.Get("X-Auth-Token") != s.currentToken() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, stubAccount+"/"), "/", 2)
+		switch {
+		case r.URL.Path == stubAccount:
+			s.serveAccount(w, r)
+		case len(parts) == 1:
+			s.serveContainer(w, r, parts[0])
+		default:
+			s.serveObject(w, r, parts[0], parts[1])
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *swiftStub) serveKeystone(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Auth struct {
+			PasswordCredentials struct {
+				Username string `json:"username"`
+				Password string `json:"password"`
+			} `json:"passwordCredentials"`
+			TenantName string `json:"tenantName"`
+			Identity   struct {
+				Password struct {
+					User struct {
+						Name     string `json:"name"`
+						Password string `json:"password"`
+						Domain   struct {
+							Name string `json:"name"`
+						} `json:"domain"`
+					} `json:"user"`
+				} `json:"password"`
+			} `json:"identity"`
+			Scope struct {
+				Project struct {
+					Name string `json:"name"`
+				} `json:"project"`
+			} `json:"scope"`
+		} `json:"auth"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Path == "/v2.0/tokens" {
+		creds := body.Auth.PasswordCredentials
+		if creds.Username != stubUser || creds.Password != stubKey || body.Auth.TenantName != "tenant" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"access":{"token":{"id":%q},"serviceCatalog":[`+
+			`{"type":"identity","endpoints":[{"region":"east","publicURL":"http://keystone"}]},`+
+			`{"type":"object-store","endpoints":[{"region":"west","publicURL":"http://west"},{"region":"east","publicURL":%q}]}]}}`,
+			s.currentToken(), s.URL+stubAccount)
+		return
+	}
+
+	user := body.Auth.Identity.Password.User
+	if user.Name != stubUser || user.Password != stubKey || user.Domain.Name != "Default" || body.Auth.Scope.Project.Name != "tenant" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("X-Subject-Token", s.currentToken())
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `{"token":{"catalog":[{"type":"object-store","endpoints":[`+
+		`{"interface":"internal","region":"east","url":"http://internal"},{"interface":"public","region":"east","url":%q}]}]}}`,
+		s.URL+stubAccount)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *swiftStub) serveAccount(w http.ResponseWriter, r *http.Request) {
+	var names []string
+	for name := range s.containers {
+		if name > r.URL.Query().Get("marker") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	containers := []swiftContainer{}
+	for _, name := range names {
+		containers = append(containers, swiftContainer{Name: name, LastModified: "2018-03-01T10:00:00.000000"})
+	}
+	writeJSON(w, containers)
+}
+
+func (s *swiftStub) serveContainer(w http.ResponseWriter, r *http.Request, container string) {
+	objects, ok := s.containers[container]
+	switch r.Method {
+	case http.MethodPut:
+		if ok {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		s.containers[container] = make(map[string]*stubObject)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		w.Header().Set("X-Timestamp", "1519898400.00000")
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if len(objects) > 0 {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		delete(s.containers, container)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		query := r.URL.Query()
+		prefix, marker, delimiter := query.Get("prefix"), query.Get("marker"), query.Get("delimiter")
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			limit = 10000
+		}
+
+		var names []string
+		for name := range objects {
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		entries := []swiftObject{}
+		for _, name := range names {
+			if len(entries) == limit {
+				break
+			}
+			if delimiter != "" {
+				if i := strings.Index(name[len(prefix):], delimiter); i >= 0 {
+					subdir := name[:len(prefix)+i+len(delimiter)]
+					if subdir > marker && (len(entries) == 0 || entries[len(entries)-1].Subdir != subdir) {
+						entries = append(entries, swiftObject{Subdir: subdir})
+					}
+					continue
+				}
+			}
+			if name <= marker {
+				continue
+			}
+			object := objects[name]
+			entries = append(entries, swiftObject{
+				Name:         name,
+				Hash:         strings.Trim(object.header.Get("Etag"), `"`),
+				Bytes:        int64(len(s.objectData(object))),
+				ContentType:  object.header.Get("Content-Type"),
+				LastModified: object.modTime.Format(swiftTimeFormat),
+			})
+		}
+		writeJSON(w, entries)
+	}
+}
+
+// objectData - returns the data of an object, or the concatenated
+// segments of a Static Large Object.
+func (s *swiftStub) objectData(object *stubObject) []byte {
+	if object.segments == nil {
+		return object.data
+	}
+	var data []byte
+	for _, segment := range object.segments {
+		if seg := s.lookup(segment); seg != nil {
+			data = append(data, seg.data...)
+		}
+	}
+	return data
+}
+
+// lookup - returns the object at `/container/object`.
+func (s *swiftStub) lookup(path string) *stubObject {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	return s.containers[parts[0]][parts[1]]
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *swiftStub) serveObject(w http.ResponseWriter, r *http.Request, container, name string) {
+	objects, ok := s.containers[container]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	object := objects[name]
+
+	// Metadata headers kept with objects.
+	metaHeader := make(http.Header)
+	for k, v := range r.Header {
+		if strings.HasPrefix(k, "X-Object-Meta-") || k == "Content-Type" || k == "Content-Encoding" {
+			metaHeader[k] = v
+		}
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		newObject := &stubObject{header: metaHeader, modTime: time.Now().UTC()}
+		switch {
+		case r.URL.Query().Get("multipart-manifest") == "put":
+			var manifest []swiftSegment
+			if err := json.NewDecoder(r.Body).Decode(&manifest); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var etags string
+			for _, segment := range manifest {
+				seg := s.lookup(segment.Path)
+				if seg == nil || strings.Trim(seg.header.Get("Etag"), `"`) != segment.ETag || int64(len(seg.data)) != segment.Size {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				newObject.segments = append(newObject.segments, segment.Path)
+				etags += segment.ETag
+			}
+			newObject.header.Set("Etag", `"`+md5Hex([]byte(etags))+`"`)
+		case r.Header.Get("X-Copy-From") != "":
+			src, err := url.PathUnescape(r.Header.Get("X-Copy-From"))
+			srcObject := s.lookup(src)
+			if err != nil || srcObject == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if etag := r.Header.Get("If-Match"); etag != "" && etag != strings.Trim(srcObject.header.Get("Etag"), `"`) {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			newObject.data = s.objectData(srcObject)
+			newObject.header.Set("Etag", md5Hex(newObject.data))
+		default:
+			data, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if etag := r.Header.Get("Etag"); etag != "" && etag != md5Hex(data) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			newObject.data = data
+			newObject.header.Set("Etag", md5Hex(data))
+		}
+		objects[name] = newObject
+		w.Header().Set("Etag", newObject.header.Get("Etag"))
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	if object == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		metaHeader.Set("Etag", object.header.Get("Etag"))
+		object.header = metaHeader
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodDelete:
+		delete(objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodHead, http.MethodGet:
+		if etag := r.Header.Get("If-Match"); etag != "" && etag != strings.Trim(object.header.Get("Etag"), `"`) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if object.segments != nil && r.URL.Query().Get("multipart-manifest") == "get" {
+			var manifest []map[string]interface{}
+			for _, segment := range object.segments {
+				manifest = append(manifest, map[string]interface{}{"name": segment})
+			}
+			writeJSON(w, manifest)
+			return
+		}
+
+		data := s.objectData(object)
+		for k, v := range object.header {
+			w.Header()[k] = v
+		}
+		if object.segments != nil {
+			w.Header().Set("X-Static-Large-Object", "True")
+		}
+		w.Header().Set("Last-Modified", object.modTime.Format(http.TimeFormat))
+		status := http.StatusOK
+		if rng := r.Header.Get("Range"); rng != "" {
+			var start, end int
+			if n, _ := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); n == 1 {
+				end = len(data) - 1
+			}
+			data = data[start : end+1]
+			status = http.StatusPartialContent
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	}
+}
+
+// newTestSwiftObjects - returns a gateway layer of a new stub
+// authenticated with TempAuth.
+func newTestSwiftObjects(t *testing.T) (*swiftStub, *swiftObjects) {
+	stub := newSwiftStub()
+	layer, err := (&Swift{stub.URL + "/auth/v1.0"}).NewGatewayLayer(auth.Credentials{AccessKey: stubUser, SecretKey: stubKey})
+	if err != nil {
+		stub.Close()
+		t.Fatal(err)
+	}
+	return stub, layer.(*swiftObjects)
+}
+
+func mustGetHashReader(t *testing.T, data []byte) *hash.Reader {
+	r, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), md5Hex(data), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestSwiftAuthVersion(t *testing.T) {
+	testCases := []struct {
+		authURL  string
+		expected int
+	}{
+		{"http://swift.example.com:8080/auth/v1.0", swiftTempAuth},
+		{"https://keystone.example.com:5000/v2.0", swiftKeystoneV2},
+		{"https://keystone.example.com:5000/v2.0/", swiftKeystoneV2},
+		{"https://keystone.example.com:5000/v3", swiftKeystoneV3},
+	}
+	for i, testCase := range testCases {
+		if v := swiftAuthVersion(testCase.authURL); v != testCase.expected {
+			t.Errorf("Test %d: expected version %d, got %d", i+1, testCase.expected, v)
+		}
+	}
+}
+
+// Tests authentication with TempAuth and Keystone, and the renewal of
+// expired tokens.
+func TestSwiftAuthenticate(t *testing.T) {
+	stub := newSwiftStub()
+	defer stub.Close()
+
+	os.Setenv("SWIFT_TENANT", "tenant")
+	os.Setenv("SWIFT_REGION", "east")
+	defer os.Unsetenv("SWIFT_TENANT")
+	defer os.Unsetenv("SWIFT_REGION")
+
+	testCases := []struct {
+		authURL   string
+		key       string
+		shouldErr bool
+	}{
+		{stub.URL + "/auth/v1.0", stubKey, false},
+		{stub.URL + "/v2.0", stubKey, false},
+		{stub.URL + "/v3", stubKey, false},
+		{stub.URL + "/auth/v1.0", "invalid", true},
+		{stub.URL + "/v3", "invalid", true},
+	}
+	for i, testCase := range testCases {
+		layer, err := (&Swift{testCase.authURL}).NewGatewayLayer(auth.Credentials{AccessKey: stubUser, SecretKey: testCase.key})
+		if (err != nil) != testCase.shouldErr {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.shouldErr, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		bucket := fmt.Sprintf("bucket%d", i+1)
+		if err = layer.MakeBucketWithLocation(bucket, ""); err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		// Expired tokens are renewed.
+		stub.expireToken()
+		if _, err = layer.GetBucketInfo(bucket); err != nil {
+			t.Errorf("Test %d: expected token to be renewed, got %v", i+1, err)
+		}
+	}
+}
+
+func TestSwiftToObjectError(t *testing.T) {
+	testCases := []struct {
+		params      []string
+		swiftErr    error
+		expectedErr error
+	}{
+		{[]string{}, nil, nil},
+		{[]string{}, fmt.Errorf("Not *Error"), fmt.Errorf("Not *Error")},
+		{[]string{}, errors.Trace(fmt.Errorf("Non Swift Error")), fmt.Errorf("Non Swift Error")},
+		{
+			[]string{"bucket"}, errors.Trace(swiftError{StatusCode: http.StatusNotFound}),
+			minio.BucketNotFound{Bucket: "bucket"},
+		},
+		{
+			[]string{"bucket", "object"}, errors.Trace(swiftError{StatusCode: http.StatusNotFound}),
+			minio.ObjectNotFound{Bucket: "bucket", Object: "object"},
+		},
+		{
+			[]string{"bucket"}, errors.Trace(swiftError{StatusCode: http.StatusConflict}),
+			minio.BucketNotEmpty{Bucket: "bucket"},
+		},
+		{
+			[]string{"bucket", "object"}, errors.Trace(swiftError{StatusCode: http.StatusUnprocessableEntity}),
+			hash.BadDigest{},
+		},
+		{
+			[]string{"bucket", "object"}, errors.Trace(swiftError{StatusCode: http.StatusPreconditionFailed}),
+			minio.InvalidETag{},
+		},
+		{
+			[]string{"bucket", "object"}, errors.Trace(swiftError{StatusCode: http.StatusForbidden}),
+			minio.PrefixAccessDenied{Bucket: "bucket", Object: "object"},
+		},
+		{
+			[]string{"bucket"}, errors.Trace(swiftError{StatusCode: http.StatusBadRequest}),
+			minio.BucketNameInvalid{Bucket: "bucket"},
+		},
+	}
+
+	for i, testCase := range testCases {
+		actualErr := swiftToObjectError(testCase.swiftErr, testCase.params...)
+		if actualErr != nil {
+			if actualErr.Error() != testCase.expectedErr.Error() {
+				t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expectedErr, actualErr)
+			}
+		}
+	}
+}
+
+// Tests the translation of S3 metadata to Swift headers and back.
+func TestSwiftMetadata(t *testing.T) {
+	s3Metadata := map[string]string{
+		"x-amz-meta-color": "blue",
+		"X-Amz-Meta-Test":  "value",
+		"content-type":     "text/plain",
+		"Content-Encoding": "gzip",
+		"X-Amz-Acl":        "private",
+	}
+	expectedHeader := http.Header{
+		"X-Object-Meta-Color": {"blue"},
+		"X-Object-Meta-Test":  {"value"},
+		"Content-Type":        {"text/plain"},
+		"Content-Encoding":    {"gzip"},
+	}
+	header := s3MetaToSwiftHeaders(s3Metadata)
+	if !reflect.DeepEqual(header, expectedHeader) {
+		t.Fatalf("Expected %v, got %v", expectedHeader, header)
+	}
+
+	expectedMetadata := map[string]string{
+		"X-Amz-Meta-Color": "blue",
+		"X-Amz-Meta-Test":  "value",
+		"Content-Type":     "text/plain",
+		"Content-Encoding": "gzip",
+	}
+	if metadata := swiftHeadersToS3Meta(header); !reflect.DeepEqual(metadata, expectedMetadata) {
+		t.Errorf("Expected %v, got %v", expectedMetadata, metadata)
+	}
+}
+
+func TestSwiftBucketsAndObjects(t *testing.T) {
+	stub, s := newTestSwiftObjects(t)
+	defer stub.Close()
+
+	bucket := "bucket"
+	if err := s.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MakeBucketWithLocation(bucket, ""); errors.Cause(err) != (minio.BucketExists{Bucket: bucket}) {
+		t.Fatalf("Expected BucketExists, got %v", err)
+	}
+	if err := s.MakeBucketWithLocation("Invalid_Bucket", ""); errors.Cause(err) != (minio.BucketNameInvalid{Bucket: "Invalid_Bucket"}) {
+		t.Fatalf("Expected BucketNameInvalid, got %v", err)
+	}
+	if _, err := s.GetBucketInfo("missing"); errors.Cause(err) != (minio.BucketNotFound{Bucket: "missing"}) {
+		t.Fatalf("Expected BucketNotFound, got %v", err)
+	}
+	buckets, err := s.ListBuckets()
+	if err != nil || len(buckets) != 1 || buckets[0].Name != bucket {
+		t.Fatalf("Unexpected buckets %v, %v", buckets, err)
+	}
+
+	metadata := map[string]string{"X-Amz-Meta-Color": "blue", "Content-Type": "text/plain"}
+	for _, object := range []string{"a", "dir/b", "dir/c", "dir/sub/d", "e"} {
+		if _, err = s.PutObject(bucket, object, mustGetHashReader(t, []byte("data of "+object)), metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	objInfo, err := s.GetObjectInfo(bucket, "dir/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.Size != int64(len("data of dir/b")) || objInfo.ETag != minio.ToS3ETag(md5Hex([]byte("data of dir/b"))) ||
+		objInfo.UserDefined["X-Amz-Meta-Color"] != "blue" || objInfo.ContentType != "text/plain" {
+		t.Errorf("Unexpected object info %+v", objInfo)
+	}
+
+	var buf bytes.Buffer
+	if err = s.GetObject(bucket, "dir/b", 8, 3, &buf, objInfo.ETag); err != nil || buf.String() != "dir" {
+		t.Errorf("Expected range `dir`, got `%s`, %v", buf.String(), err)
+	}
+	if err = s.GetObject(bucket, "dir/b", 0, 0, ioutil.Discard, "0123"); errors.Cause(err) != (minio.InvalidETag{}) {
+		t.Errorf("Expected InvalidETag, got %v", err)
+	}
+	if _, err = s.GetObjectInfo(bucket, "missing"); errors.Cause(err) != (minio.ObjectNotFound{Bucket: bucket, Object: "missing"}) {
+		t.Errorf("Expected ObjectNotFound, got %v", err)
+	}
+
+	// Listings are paginated, objects under a common prefix are
+	// listed once.
+	testCases := []struct {
+		prefix, marker, delimiter string
+		maxKeys                   int
+		objects, prefixes         []string
+		nextMarker                string
+	}{
+		{"", "", "", 1000, []string{"a", "dir/b", "dir/c", "dir/sub/d", "e"}, nil, ""},
+		{"", "", "/", 1000, []string{"a", "e"}, []string{"dir/"}, ""},
+		{"", "", "/", 2, []string{"a"}, []string{"dir/"}, "dir/"},
+		{"", "dir/", "/", 2, []string{"e"}, nil, ""},
+		{"dir/", "", "/", 1000, []string{"dir/b", "dir/c"}, []string{"dir/sub/"}, ""},
+		{"dir/", "dir/b", "", 1, []string{"dir/c"}, nil, "dir/c"},
+	}
+	for i, testCase := range testCases {
+		result, err := s.ListObjects(bucket, testCase.prefix, testCase.marker, testCase.delimiter, testCase.maxKeys)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		var objects []string
+		for _, object := range result.Objects {
+			objects = append(objects, object.Name)
+		}
+		if !reflect.DeepEqual(objects, testCase.objects) || !reflect.DeepEqual(result.Prefixes, testCase.prefixes) ||
+			result.NextMarker != testCase.nextMarker {
+			t.Errorf("Test %d: unexpected listing %v %v %q", i+1, objects, result.Prefixes, result.NextMarker)
+		}
+	}
+
+	// Copies replace metadata.
+	if _, err = s.CopyObject(bucket, "a", bucket, "copy", map[string]string{"X-Amz-Meta-Shape": "round"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if objInfo, err = s.GetObjectInfo(bucket, "copy"); err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.UserDefined["X-Amz-Meta-Shape"] != "round" || objInfo.UserDefined["X-Amz-Meta-Color"] != "" {
+		t.Errorf("Unexpected metadata %v", objInfo.UserDefined)
+	}
+	if _, err = s.CopyObject(bucket, "a", bucket, "a", map[string]string{"X-Amz-Meta-Shape": "square"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if objInfo, err = s.GetObjectInfo(bucket, "a"); err != nil || objInfo.UserDefined["X-Amz-Meta-Shape"] != "square" {
+		t.Errorf("Unexpected metadata %v, %v", objInfo.UserDefined, err)
+	}
+
+	if err = s.DeleteBucket(bucket); errors.Cause(err) != (minio.BucketNotEmpty{Bucket: bucket}) {
+		t.Fatalf("Expected BucketNotEmpty, got %v", err)
+	}
+	for _, object := range []string{"a", "copy", "dir/b", "dir/c", "dir/sub/d", "e"} {
+		if err = s.DeleteObject(bucket, object); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.DeleteBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSwiftMultipartUpload(t *testing.T) {
+	stub, s := newTestSwiftObjects(t)
+	defer stub.Close()
+
+	bucket, object := "bucket", "object"
+	if err := s.MakeBucketWithLocation(bucket, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	uploadID, err := s.NewMultipartUpload(bucket, object, map[string]string{"X-Amz-Meta-Color": "blue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.PutObjectPart(bucket, object, "missing", 1, mustGetHashReader(t, []byte("a"))); errors.Cause(err) != (minio.InvalidUploadID{UploadID: "missing"}) {
+		t.Fatalf("Expected InvalidUploadID, got %v", err)
+	}
+
+	data := [][]byte{bytes.Repeat([]byte("a"), swiftS3MinPartSize), []byte("bc"), []byte("unused")}
+	var parts []minio.CompletePart
+	for i, partData := range data {
+		info, perr := s.PutObjectPart(bucket, object, uploadID, i+1, mustGetHashReader(t, partData))
+		if perr != nil {
+			t.Fatal(perr)
+		}
+		parts = append(parts, minio.CompletePart{PartNumber: info.PartNumber, ETag: info.ETag})
+	}
+
+	result, err := s.ListObjectParts(bucket, object, uploadID, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Parts) != 1 || result.Parts[0].PartNumber != 2 || result.Parts[0].Size != 2 || !result.IsTruncated || result.NextPartNumberMarker != 2 {
+		t.Errorf("Unexpected parts %+v", result)
+	}
+
+	// Uploads are hidden from listings.
+	loi, err := s.ListObjects(bucket, "", "", "", 1000)
+	if err != nil || len(loi.Objects) != 0 {
+		t.Errorf("Expected no objects, got %v, %v", loi.Objects, err)
+	}
+
+	if _, err = s.CompleteMultipartUpload(bucket, object, uploadID, []minio.CompletePart{{PartNumber: 1, ETag: "0123"}}); errors.Cause(err) != (minio.InvalidPart{}) {
+		t.Errorf("Expected InvalidPart, got %v", err)
+	}
+	if _, err = s.CompleteMultipartUpload(bucket, object, uploadID, []minio.CompletePart{parts[1], parts[0]}); err == nil {
+		t.Errorf("Expected PartTooSmall, got %v", err)
+	}
+
+	objInfo, err := s.CompleteMultipartUpload(bucket, object, uploadID, parts[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.Size != int64(len(data[0])+len(data[1])) || objInfo.UserDefined["X-Amz-Meta-Color"] != "blue" {
+		t.Errorf("Unexpected object info %+v", objInfo)
+	}
+	var buf bytes.Buffer
+	if err = s.GetObject(bucket, object, int64(len(data[0])-1), 3, &buf, ""); err != nil || buf.String() != "abc" {
+		t.Errorf("Expected `abc`, got `%s`, %v", buf.String(), err)
+	}
+	if _, err = s.ListObjectParts(bucket, object, uploadID, 0, 1000); errors.Cause(err) != (minio.InvalidUploadID{UploadID: uploadID}) {
+		t.Errorf("Expected completed upload to be removed, got %v", err)
+	}
+
+	// Overwriting the object deletes its segments, the bucket is then
+	// left empty by deleting it.
+	if _, err = s.PutObject(bucket, object, mustGetHashReader(t, []byte("small")), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.DeleteObject(bucket, object); err != nil {
+		t.Fatal(err)
+	}
+
+	// Aborted uploads are removed.
+	if uploadID, err = s.NewMultipartUpload(bucket, object, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.PutObjectPart(bucket, object, uploadID, 1, mustGetHashReader(t, []byte("a"))); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.AbortMultipartUpload(bucket, object, uploadID); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.DeleteBucket(bucket); err != nil {
+		t.Errorf("Expected bucket to be empty, got %v", err)
+	}
+}
@@ -17,15 +17,16 @@
 package cmd
 
 import (
-	"crypto/tls"
 	"crypto/x509"
 	"os"
 	"runtime"
+	"sync"
 	"time"
 
 	humanize "github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/minio/pkg/auth"
+	"github.com/minio/minio/pkg/certs"
 	miniohttp "github.com/minio/minio/pkg/http"
 )
 
@@ -68,6 +69,9 @@ const (
 	// date and server date during signature verification.
 	globalMaxSkewTime = 15 * time.Minute // 15 minutes skew allowed.
 
+	// Interval to check certificates for changes.
+	globalCertsReloadInterval = time.Minute
+
 	// Default Read/Write timeouts for each connection.
 	globalConnReadTimeout  = 15 * time.Minute // Timeout after 15 minutes of no data sent by the client.
 	globalConnWriteTimeout = 15 * time.Minute // Timeout after 15 minutes if no data received by the client.
@@ -111,13 +115,27 @@ var (
 	// Peer communication struct
 	globalS3Peers = s3Peers{}
 
-	// CA root certificates, a nil value means system certs pool will be used
+	// CA root certificates, a nil value means system certs pool will be used.
+	// Rebuilt when the certificates are reloaded, see getGlobalRootCAs.
 	globalRootCAs *x509.CertPool
 
 	// IsSSL indicates if the server is configured with SSL.
 	globalIsSSL bool
 
-	globalTLSCertificate *tls.Certificate
+	// Certificates presented to clients, reloaded when they change.
+	globalTLSCerts *certs.Manager
+
+	// Certificate presented to other nodes, if set inter-node RPC is
+	// authenticated by mutual TLS instead of the root credentials.
+	globalNodeCerts *certs.Manager
+
+	// CAs issuing the certificates of other nodes, never includes the
+	// system certs pool. Rebuilt when the certificates are reloaded,
+	// see getGlobalNodeCAs.
+	globalNodeCAs *x509.CertPool
+
+	// Guards globalRootCAs and globalNodeCAs.
+	globalCAsMu sync.RWMutex
 
 	globalHTTPServer        *miniohttp.Server
 	globalHTTPServerErrorCh = make(chan error)
@@ -140,6 +158,23 @@ var (
 	// Global HTTP request statisitics
 	globalHTTPStats = newHTTPStats()
 
+	// Lock wait and hold time statistics
+	globalLockMetrics = newLockMetrics()
+
+	// Maximum number of API requests served at once, derived from
+	// the open file limit if not set.
+	globalAPIRequestsMax int
+	// Time API requests wait to be admitted.
+	globalAPIRequestsDeadline = defaultAPIRequestsDeadline
+	// Clients queued API requests are shared fairly between, and
+	// their weights.
+	globalAPIRequestsFairness = fairnessSourceIP
+	globalAPIRequestsWeights  = make(map[string]int)
+
+	// Admission controller of API requests.
+	globalAdmissionController   *admissionController
+	globalAdmissionControllerMu sync.RWMutex
+
 	// Time when object layer was initialized on start up.
 	globalBootTime time.Time
 
@@ -149,6 +184,14 @@ var (
 	globalIsEnvDomainName bool
 	globalDomainName      string // Root domain for virtual host style requests
 
+	// Registry of the buckets of federated clusters, the endpoint of
+	// this cluster and how requests for other clusters are served.
+	globalBucketRegistry       bucketRegistry
+	globalPublicEndpoint       string
+	globalBucketFederationMode string
+	// Buckets of this cluster, served without looking them up.
+	globalLocalBuckets = newLocalBucketCache(localBucketCacheTTL)
+
 	globalListingTimeout   = newDynamicTimeout( /*30*/ 600*time.Second /*5*/, 600*time.Second) // timeout for listing related ops
 	globalObjectTimeout    = newDynamicTimeout( /*1*/ 10*time.Minute /*10*/, 600*time.Second)  // timeout for Object API related ops
 	globalOperationTimeout = newDynamicTimeout(10*time.Minute /*30*/, 600*time.Second)         // default timeout for general ops
@@ -165,8 +208,14 @@ var (
 	// Set to store standard storage class
 	globalStandardStorageClass storageClass
 
-	// RPC version.
-	globalRPCAPIVersion = semVersion{1, 0, 0}
+	// Erasure code algorithm of new objects
+	globalErasureAlgorithm = erasureAlgorithmKlauspost
+	// Set to false to erasure code without SIMD instructions
+	globalErasureSIMD = true
+
+	// RPC version. 2.0.0 keeps locks alive by refreshing their lease,
+	// which peers of 1.0.0 do not support.
+	globalRPCAPIVersion = semVersion{2, 0, 0}
 
 	// Add new variable global values here.
 )
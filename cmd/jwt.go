@@ -90,47 +90,104 @@ func keyFuncCallback(jwtToken *jwtgo.Token) (interface{}, error) {
 	return []byte(globalServerConfig.GetCredential().SecretKey), nil
 }
 
-func isAuthTokenValid(tokenString string) bool {
+// webClaims - claims of browser session and URL tokens. Tokens for the
+// server credentials have the access key as subject, tokens of users
+// logged in with OpenID Connect are issued by the provider and carry
+// the names of the policies granted to the user.
+type webClaims struct {
+	jwtgo.StandardClaims
+	Policies []string `json:"policies,omitempty"`
+}
+
+// isOwner - returns true for tokens of the server credentials.
+func (c *webClaims) isOwner() bool {
+	return c != nil && c.Issuer == "" && c.Subject == globalServerConfig.GetCredential().AccessKey
+}
+
+// newWebToken - signs claims with the server credentials.
+func newWebToken(claims webClaims) (string, error) {
+	jwt := jwtgo.NewWithClaims(jwtgo.SigningMethodHS512, claims)
+	return jwt.SignedString([]byte(globalServerConfig.GetCredential().SecretKey))
+}
+
+// parseWebToken - returns the claims of a valid token of the server
+// credentials or of an OpenID Connect session.
+func parseWebToken(tokenString string) (*webClaims, error) {
 	if tokenString == "" {
-		return false
+		return nil, errNoAuthToken
 	}
-	var claims jwtgo.StandardClaims
-	jwtToken, err := jwtgo.ParseWithClaims(tokenString, &claims, keyFuncCallback)
+	claims := &webClaims{}
+	jwtToken, err := jwtgo.ParseWithClaims(tokenString, claims, keyFuncCallback)
 	if err != nil {
-		errorIf(err, "Unable to parse JWT token string")
-		return false
+		return nil, err
 	}
 	if err = claims.Valid(); err != nil {
-		errorIf(err, "Invalid claims in JWT token string")
+		return nil, err
+	}
+	if !jwtToken.Valid {
+		return nil, errAuthentication
+	}
+	if !claims.isOwner() && !isOpenIDSessionValid(claims) {
+		return nil, errInvalidAccessKeyID
+	}
+	return claims, nil
+}
+
+func isAuthTokenValid(tokenString string) bool {
+	claims, err := parseWebToken(tokenString)
+	if err != nil {
+		if err != errNoAuthToken {
+			errorIf(err, "Unable to parse JWT token string")
+		}
 		return false
 	}
-	return jwtToken.Valid && claims.Subject == globalServerConfig.GetCredential().AccessKey
+	return claims.isOwner()
 }
 
 func isHTTPRequestValid(req *http.Request) bool {
 	return webRequestAuthenticate(req) == nil
 }
 
-// Check if the request is authenticated.
+// isWebRequestAllowed - returns whether the request is authenticated by
+// a browser session allowed to perform action on the bucket or object.
+func isWebRequestAllowed(req *http.Request, action, bucket, object string) bool {
+	claims, err := webSessionAuthenticate(req)
+	return err == nil && claims.isAllowed(action, bucket, object)
+}
+
+// Check if the request is authenticated with the server credentials.
 // Returns nil if the request is authenticated. errNoAuthToken if token missing.
 // Returns errAuthentication for all other errors.
 func webRequestAuthenticate(req *http.Request) error {
-	var claims jwtgo.StandardClaims
-	jwtToken, err := jwtreq.ParseFromRequestWithClaims(req, jwtreq.AuthorizationHeaderExtractor, &claims, keyFuncCallback)
+	claims, err := webSessionAuthenticate(req)
 	if err != nil {
-		if err == jwtreq.ErrNoTokenInRequest {
-			return errNoAuthToken
-		}
-		return errAuthentication
+		return err
 	}
-	if err = claims.Valid(); err != nil {
+	if !claims.isOwner() {
 		return errAuthentication
 	}
-	if claims.Subject != globalServerConfig.GetCredential().AccessKey {
-		return errInvalidAccessKeyID
+	return nil
+}
+
+// webSessionAuthenticate - returns the claims of the browser session
+// the request is authenticated by, either of the server credentials or
+// of an OpenID Connect user. Returns errNoAuthToken if token missing,
+// errInvalidAccessKeyID for tokens of unknown users and errAuthentication
+// for all other errors.
+func webSessionAuthenticate(req *http.Request) (*webClaims, error) {
+	tokenString, err := jwtreq.AuthorizationHeaderExtractor.ExtractToken(req)
+	if err != nil {
+		if err == jwtreq.ErrNoTokenInRequest {
+			return nil, errNoAuthToken
+		}
+		return nil, errAuthentication
 	}
-	if !jwtToken.Valid {
-		return errAuthentication
+	claims, err := parseWebToken(tokenString)
+	if err != nil {
+		if err == errNoAuthToken || err == errInvalidAccessKeyID {
+			return nil, err
+		}
+		return nil, errAuthentication
 	}
-	return nil
+	return claims, nil
 }
@@ -0,0 +1,203 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/policy"
+	"github.com/minio/minio-go/pkg/set"
+	"github.com/minio/minio/pkg/ldap"
+)
+
+const (
+	// Default attribute of group entries holding the group name.
+	ldapDefaultGroupNameAttribute = "cn"
+
+	// Default filter of the group search, %s is replaced by the
+	// escaped DN of the user.
+	ldapDefaultGroupSearchFilter = "(member=%s)"
+)
+
+var (
+	errLDAPNotEnabled = errors.New("LDAP authentication is not enabled")
+	errLDAPNoPolicy   = errors.New("No policy is granted to the LDAP user")
+)
+
+// ldapConfig - LDAP or Active Directory server users authenticate with
+// to obtain temporary credentials. The groups a user is a member of
+// select the policies granted to the credentials.
+type ldapConfig struct {
+	Enable     bool   `json:"enable"`
+	ServerAddr string `json:"serverAddr"`
+	// TLS - connect with LDAPS, StartTLS - upgrade a plain connection.
+	TLS                bool `json:"tls"`
+	StartTLS           bool `json:"startTLS"`
+	InsecureSkipVerify bool `json:"insecureSkipVerify"`
+	// UserDNFormat - DN users bind as, %s is replaced by the username.
+	UserDNFormat       string `json:"userDNFormat"`
+	GroupSearchBaseDN  string `json:"groupSearchBaseDN"`
+	GroupSearchFilter  string `json:"groupSearchFilter"`
+	GroupNameAttribute string `json:"groupNameAttribute"`
+	// Policies by group name, in bucket policy syntax.
+	Policies map[string]policy.BucketAccessPolicy `json:"policies"`
+}
+
+// Validate - validates the LDAP configuration.
+func (l ldapConfig) Validate() error {
+	if !l.Enable {
+		return nil
+	}
+	if l.ServerAddr == "" || l.UserDNFormat == "" || l.GroupSearchBaseDN == "" {
+		return errors.New("ldap: serverAddr, userDNFormat and groupSearchBaseDN must be set")
+	}
+	if _, _, err := net.SplitHostPort(l.ServerAddr); err != nil {
+		return fmt.Errorf("ldap: invalid server address ‘%s’", l.ServerAddr)
+	}
+	if l.TLS && l.StartTLS {
+		return errors.New("ldap: tls and startTLS cannot both be enabled")
+	}
+	if strings.Count(l.UserDNFormat, "%s") != 1 {
+		return fmt.Errorf("ldap: userDNFormat ‘%s’ must contain exactly one %%s", l.UserDNFormat)
+	}
+	if strings.Count(l.groupSearchFilter(), "%s") != 1 {
+		return fmt.Errorf("ldap: groupSearchFilter ‘%s’ must contain exactly one %%s", l.GroupSearchFilter)
+	}
+	if _, err := ldap.CompileFilter(fmt.Sprintf(l.groupSearchFilter(), "dn")); err != nil {
+		return fmt.Errorf("ldap: invalid groupSearchFilter: %v", err)
+	}
+	for name, p := range l.Policies {
+		if len(p.Statements) == 0 {
+			return fmt.Errorf("ldap: policy ‘%s’ has no statements", name)
+		}
+		for _, statement := range p.Statements {
+			if err := isValidEffect(statement.Effect); err != nil {
+				return fmt.Errorf("ldap: policy ‘%s’: %v", name, err)
+			}
+			if err := isValidResources(statement.Resources); err != nil {
+				return fmt.Errorf("ldap: policy ‘%s’: %v", name, err)
+			}
+			if statement.Actions.IsEmpty() {
+				return fmt.Errorf("ldap: policy ‘%s’: Action list cannot be empty", name)
+			}
+			if unsupported := statement.Actions.Difference(supportedActionMap); !unsupported.IsEmpty() {
+				return fmt.Errorf("ldap: policy ‘%s’: unsupported actions %v", name, unsupported.ToSlice())
+			}
+		}
+	}
+	return nil
+}
+
+func (l ldapConfig) groupSearchFilter() string {
+	if l.GroupSearchFilter == "" {
+		return ldapDefaultGroupSearchFilter
+	}
+	return l.GroupSearchFilter
+}
+
+func (l ldapConfig) groupNameAttribute() string {
+	if l.GroupNameAttribute == "" {
+		return ldapDefaultGroupNameAttribute
+	}
+	return l.GroupNameAttribute
+}
+
+// getLDAPConfig - returns the LDAP configuration if enabled.
+func getLDAPConfig() (ldapConfig, error) {
+	globalServerConfigMu.RLock()
+	defer globalServerConfigMu.RUnlock()
+	if globalServerConfig == nil || !globalServerConfig.LDAP.Enable {
+		return ldapConfig{}, errLDAPNotEnabled
+	}
+	return globalServerConfig.LDAP, nil
+}
+
+// dial - connects to the LDAP server, over TLS if configured.
+func (l ldapConfig) dial() (*ldap.Conn, error) {
+	host, _, _ := net.SplitHostPort(l.ServerAddr)
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		RootCAs:            getGlobalRootCAs(),
+		InsecureSkipVerify: l.InsecureSkipVerify,
+	}
+	if l.TLS {
+		return ldap.DialTLS(l.ServerAddr, tlsConfig)
+	}
+	conn, err := ldap.Dial(l.ServerAddr)
+	if err != nil {
+		return nil, err
+	}
+	if l.StartTLS {
+		if err = conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// ldapAuthenticate - binds as the user and returns the names of the
+// configured policies matching the groups the user is a member of.
+// Returns errAuthentication for invalid usernames or passwords.
+func ldapAuthenticate(config ldapConfig, username, password string) ([]string, error) {
+	// Usernames are placed into a DN, refuse the characters which
+	// would change its structure instead of escaping them.
+	if username == "" || strings.ContainsAny(username, ",=+<>#;\\\"") {
+		return nil, errAuthentication
+	}
+
+	conn, err := config.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	userDN := fmt.Sprintf(config.UserDNFormat, username)
+	if err = conn.Bind(userDN, password); err != nil {
+		if ldap.IsInvalidCredentials(err) {
+			return nil, errAuthentication
+		}
+		return nil, err
+	}
+
+	entries, err := conn.Search(ldap.SearchRequest{
+		BaseDN:     config.GroupSearchBaseDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     fmt.Sprintf(config.groupSearchFilter(), ldap.EscapeFilter(userDN)),
+		Attributes: []string{config.groupNameAttribute()},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := set.NewStringSet()
+	for _, entry := range entries {
+		for _, group := range entry.GetAttributeValues(config.groupNameAttribute()) {
+			if _, ok := config.Policies[group]; ok {
+				names.Add(group)
+			}
+		}
+	}
+	policies := names.ToSlice()
+	sort.Strings(policies)
+	return policies, nil
+}
@@ -136,6 +136,8 @@ func (n *nsLockMap) statusBlockedToRunning(param nsParam, lockSource, opsID stri
 	if lockInfo.status != blockedStatus {
 		return errors.Trace(LockInfoStateNotBlocked{param.volume, param.path, opsID})
 	}
+	// Record the time spent blocked, since is the time the lock was requested.
+	globalLockMetrics.lockAcquired(UTCNow().Sub(lockInfo.since))
 	// Change lock status to running and update the time.
 	n.debugLockMap[param].lockInfo[opsID] = newDebugLockInfo(lockSource, runningStatus, readLock)
 
@@ -251,6 +253,9 @@ func (n *nsLockMap) deleteLockInfoEntryForOps(param nsParam, opsID string) error
 	granted := opsIDLock.status == runningStatus
 	n.counters.lockRemoved(granted)
 	infoMap.counters.lockRemoved(granted)
+	if granted {
+		globalLockMetrics.lockReleased(UTCNow().Sub(opsIDLock.since))
+	}
 	delete(infoMap.lockInfo, opsID)
 	return nil
 }
@@ -0,0 +1,120 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// Upper bounds of the buckets of lock wait and hold time histograms,
+// durations above the last bound are only counted in the total.
+var lockDurationBuckets = []time.Duration{
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+	time.Minute,
+	10 * time.Minute,
+}
+
+// durationHistogram - counts durations in lockDurationBuckets. 64 bit
+// counters come first to be aligned for atomic access on 32 bit
+// platforms.
+type durationHistogram struct {
+	count   atomic.Uint64
+	sum     atomic.Int64
+	buckets []atomic.Uint64
+}
+
+func newDurationHistogram() *durationHistogram {
+	return &durationHistogram{buckets: make([]atomic.Uint64, len(lockDurationBuckets))}
+}
+
+// observe - adds d to the histogram.
+func (h *durationHistogram) observe(d time.Duration) {
+	for i, bound := range lockDurationBuckets {
+		if d <= bound {
+			h.buckets[i].Inc()
+			break
+		}
+	}
+	h.count.Inc()
+	h.sum.Add(int64(d))
+}
+
+// toServerHistogram - returns the histogram with cumulative bucket
+// counts, as expected by monitoring systems.
+func (h *durationHistogram) toServerHistogram() ServerDurationHistogram {
+	histogram := ServerDurationHistogram{
+		Count: h.count.Load(),
+		Sum:   time.Duration(h.sum.Load()).String(),
+	}
+	var cumulative uint64
+	for i, bound := range lockDurationBuckets {
+		cumulative += h.buckets[i].Load()
+		histogram.Buckets = append(histogram.Buckets, ServerDurationBucket{
+			UpperBound: bound.String(),
+			Count:      cumulative,
+		})
+	}
+	return histogram
+}
+
+// LockMetrics - statistics of the namespace locks of this server.
+type LockMetrics struct {
+	// Distributed locks dropped by this server's lock server
+	// because their holder stopped refreshing them.
+	staleLocks atomic.Uint64
+	// Time operations waited for a lock.
+	wait *durationHistogram
+	// Time operations held a lock.
+	hold *durationHistogram
+}
+
+func newLockMetrics() *LockMetrics {
+	return &LockMetrics{
+		wait: newDurationHistogram(),
+		hold: newDurationHistogram(),
+	}
+}
+
+// lockAcquired - records the time spent waiting for a granted lock.
+func (m *LockMetrics) lockAcquired(waited time.Duration) {
+	m.wait.observe(waited)
+}
+
+// lockReleased - records the time a released lock was held.
+func (m *LockMetrics) lockReleased(held time.Duration) {
+	m.hold.observe(held)
+}
+
+// staleLockDropped - counts a lock dropped by lock maintenance.
+func (m *LockMetrics) staleLockDropped() {
+	m.staleLocks.Inc()
+}
+
+// Converts lock metrics into struct to be sent back to the client.
+func (m *LockMetrics) toServerLockStats() ServerLockStats {
+	return ServerLockStats{
+		Wait:       m.wait.toServerHistogram(),
+		Hold:       m.hold.toServerHistogram(),
+		StaleLocks: m.staleLocks.Load(),
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockMetrics(t *testing.T) {
+	metrics := newLockMetrics()
+	for _, d := range []time.Duration{time.Microsecond, 5 * time.Millisecond, 2 * time.Second, time.Hour} {
+		metrics.lockAcquired(d)
+	}
+	metrics.lockReleased(time.Millisecond)
+
+	stats := metrics.toServerLockStats()
+	if stats.Wait.Count != 4 || stats.Hold.Count != 1 {
+		t.Fatalf("Unexpected counts %d %d", stats.Wait.Count, stats.Hold.Count)
+	}
+	// Bucket counts are cumulative, the hour is only in the total.
+	expected := []uint64{1, 2, 2, 2, 3, 3, 3}
+	for i, bucket := range stats.Wait.Buckets {
+		if bucket.Count != expected[i] {
+			t.Errorf("Bucket %s: Expected %d, got %d", bucket.UpperBound, expected[i], bucket.Count)
+		}
+	}
+	if stats.Wait.Sum != (time.Hour + 2*time.Second + 5*time.Millisecond + time.Microsecond).String() {
+		t.Errorf("Unexpected sum %s", stats.Wait.Sum)
+	}
+	if stats.Hold.Buckets[0].Count != 1 {
+		t.Errorf("Expected hold time in first bucket, got %v", stats.Hold.Buckets)
+	}
+}
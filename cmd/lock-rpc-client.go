@@ -16,7 +16,7 @@
 
 package cmd
 
-import "github.com/minio/dsync"
+import "github.com/minio/minio/pkg/dsync"
 
 // LockRPCClient is authenticable lock RPC client compatible to dsync.NetLocker
 type LockRPCClient struct {
@@ -63,6 +63,13 @@ func (lockRPCClient *LockRPCClient) ForceUnlock(args dsync.LockArgs) (reply bool
 	return reply, err
 }
 
+// Refresh calls refresh RPC.
+func (lockRPCClient *LockRPCClient) Refresh(args dsync.LockArgs) (reply bool, err error) {
+	lockArgs := newLockArgs(args)
+	err = lockRPCClient.AuthRPCClient.Call("Dsync.Refresh", &lockArgs, &reply)
+	return reply, err
+}
+
 // Expired calls expired RPC.
 func (lockRPCClient *LockRPCClient) Expired(args dsync.LockArgs) (reply bool, err error) {
 	lockArgs := newLockArgs(args)
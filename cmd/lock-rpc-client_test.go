@@ -20,7 +20,7 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/minio/dsync"
+	"github.com/minio/minio/pkg/dsync"
 )
 
 // Tests lock rpc client.
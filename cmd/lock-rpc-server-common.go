@@ -57,16 +57,14 @@ func (l *localLocker) removeEntry(name, uid string, lri *[]lockRequesterInfo) bo
 	return false
 }
 
-// getLongLivedLocks returns locks that are older than a certain time and
-// have not been 'checked' for validity too soon enough
-func getLongLivedLocks(m map[string][]lockRequesterInfo, interval time.Duration) []nameLockRequesterInfoPair {
+// getStaleLocks returns locks that have not been refreshed for longer
+// than ttl.
+func getStaleLocks(m map[string][]lockRequesterInfo, ttl time.Duration) []nameLockRequesterInfoPair {
 	rslt := []nameLockRequesterInfoPair{}
 	for name, lriArray := range m {
 		for idx := range lriArray {
-			// Check whether enough time has gone by since last check
-			if time.Since(lriArray[idx].timeLastCheck) >= interval {
+			if time.Since(lriArray[idx].timeLastRefresh) >= ttl {
 				rslt = append(rslt, nameLockRequesterInfoPair{name: name, lri: lriArray[idx]})
-				lriArray[idx].timeLastCheck = UTCNow()
 			}
 		}
 	}
@@ -34,7 +34,7 @@ func TestLockRpcServerRemoveEntryIfExists(t *testing.T) {
 		serviceEndpoint: "rpc-path",
 		uid:             "0123-4567",
 		timestamp:       UTCNow(),
-		timeLastCheck:   UTCNow(),
+		timeLastRefresh: UTCNow(),
 	}
 	nlrip := nameLockRequesterInfoPair{name: "name", lri: lri}
 
@@ -71,7 +71,7 @@ func TestLockRpcServerRemoveEntry(t *testing.T) {
 		serviceEndpoint: "rpc-path",
 		uid:             "0123-4567",
 		timestamp:       UTCNow(),
-		timeLastCheck:   UTCNow(),
+		timeLastRefresh: UTCNow(),
 	}
 	lockRequesterInfo2 := lockRequesterInfo{
 		writer:          true,
@@ -79,7 +79,7 @@ func TestLockRpcServerRemoveEntry(t *testing.T) {
 		serviceEndpoint: "rpc-path",
 		uid:             "89ab-cdef",
 		timestamp:       UTCNow(),
-		timeLastCheck:   UTCNow(),
+		timeLastRefresh: UTCNow(),
 	}
 
 	locker.ll.lockMap["name"] = []lockRequesterInfo{
@@ -116,15 +116,15 @@ func TestLockRpcServerRemoveEntry(t *testing.T) {
 }
 
 // Tests function returning long lived locks.
-func TestLockRpcServerGetLongLivedLocks(t *testing.T) {
+func TestLockRpcServerGetStaleLocks(t *testing.T) {
 	ut := UTCNow()
-	// Collection of test cases for verifying returning valid long lived locks.
+	// Collection of test cases for verifying returning stale locks.
 	testCases := []struct {
 		lockMap      map[string][]lockRequesterInfo
 		lockInterval time.Duration
 		expectedNSLR []nameLockRequesterInfoPair
 	}{
-		// Testcase - 1 validates long lived locks, returns empty list.
+		// Testcase - 1 validates recently refreshed locks, returns empty list.
 		{
 			lockMap: map[string][]lockRequesterInfo{
 				"test": {{
@@ -133,13 +133,13 @@ func TestLockRpcServerGetLongLivedLocks(t *testing.T) {
 					serviceEndpoint: "/lock/mnt/disk1",
 					uid:             "10000112",
 					timestamp:       ut,
-					timeLastCheck:   ut,
+					timeLastRefresh: ut,
 				}},
 			},
 			lockInterval: 1 * time.Minute,
 			expectedNSLR: []nameLockRequesterInfoPair{},
 		},
-		// Testcase - 2 validates long lived locks, returns at least one list.
+		// Testcase - 2 validates stale locks, returns at least one list.
 		{
 			lockMap: map[string][]lockRequesterInfo{
 				"test": {{
@@ -148,7 +148,7 @@ func TestLockRpcServerGetLongLivedLocks(t *testing.T) {
 					serviceEndpoint: "/lock/mnt/disk1",
 					uid:             "10000112",
 					timestamp:       ut,
-					timeLastCheck:   ut.Add(-2 * time.Minute),
+					timeLastRefresh: ut.Add(-2 * time.Minute),
 				}},
 			},
 			lockInterval: 1 * time.Minute,
@@ -161,7 +161,7 @@ func TestLockRpcServerGetLongLivedLocks(t *testing.T) {
 						serviceEndpoint: "/lock/mnt/disk1",
 						uid:             "10000112",
 						timestamp:       ut,
-						timeLastCheck:   ut.Add(-2 * time.Minute),
+						timeLastRefresh: ut.Add(-2 * time.Minute),
 					},
 				},
 			},
@@ -169,7 +169,7 @@ func TestLockRpcServerGetLongLivedLocks(t *testing.T) {
 	}
 	// Validates all test cases here.
 	for i, testCase := range testCases {
-		nsLR := getLongLivedLocks(testCase.lockMap, testCase.lockInterval)
+		nsLR := getStaleLocks(testCase.lockMap, testCase.lockInterval)
 		if !reflect.DeepEqual(testCase.expectedNSLR, nsLR) {
 			t.Errorf("Test %d: Expected %#v, got %#v", i+1, testCase.expectedNSLR, nsLR)
 		}
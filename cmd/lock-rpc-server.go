@@ -19,11 +19,12 @@ package cmd
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"sync"
 	"time"
 
 	router "github.com/gorilla/mux"
-	"github.com/minio/dsync"
+	"github.com/minio/minio/pkg/dsync"
 	"github.com/minio/minio/pkg/errors"
 )
 
@@ -35,10 +36,11 @@ const (
 	lockServiceName = "Dsync"
 
 	// Lock maintenance interval.
-	lockMaintenanceInterval = 1 * time.Minute // 1 minute.
+	lockMaintenanceInterval = 10 * time.Second // 10 seconds.
 
-	// Lock validity check interval.
-	lockValidityCheckInterval = 2 * time.Minute // 2 minutes.
+	// Locks not refreshed for this long are dropped, holders refresh
+	// them every dsync.DRWMutexRefreshInterval.
+	lockLeaseTTL = 30 * time.Second // 30 seconds.
 )
 
 // lockRequesterInfo stores various info from the client for each lock that is requested.
@@ -48,7 +50,7 @@ type lockRequesterInfo struct {
 	serviceEndpoint string    // RPC path of client claiming lock.
 	uid             string    // UID to uniquely identify request of client.
 	timestamp       time.Time // Timestamp set at the time of initialization.
-	timeLastCheck   time.Time // Timestamp for last check of validity of lock.
+	timeLastRefresh time.Time // Timestamp of the last refresh by the client.
 }
 
 // isWriteLock returns whether the lock is a write or read lock.
@@ -66,20 +68,20 @@ type lockServer struct {
 func startLockMaintenance(lkSrv *lockServer) {
 	// Start loop for stale lock maintenance
 	go func(lk *lockServer) {
-		// Initialize a new ticker with a minute between each ticks.
+		// Initialize a new ticker with lockMaintenanceInterval between each ticks.
 		ticker := time.NewTicker(lockMaintenanceInterval)
 
 		// Start with random sleep time, so as to avoid "synchronous checks" between servers
 		time.Sleep(time.Duration(rand.Float64() * float64(lockMaintenanceInterval)))
 		for {
-			// Verifies every minute for locks held more than 2minutes.
+			// Drops locks which were not refreshed within lockLeaseTTL.
 			select {
 			case <-globalServiceDoneCh:
 				// Stop the timer upon service closure and cleanup the go-routine.
 				ticker.Stop()
 				return
 			case <-ticker.C:
-				lk.lockMaintenance(lockValidityCheckInterval)
+				lk.lockMaintenance(lockLeaseTTL)
 			}
 		}
 	}(lkSrv)
@@ -133,7 +135,7 @@ func (l *localLocker) Lock(args dsync.LockArgs) (reply bool, err error) {
 				serviceEndpoint: args.ServiceEndpoint,
 				uid:             args.UID,
 				timestamp:       UTCNow(),
-				timeLastCheck:   UTCNow(),
+				timeLastRefresh: UTCNow(),
 			},
 		}
 	}
@@ -169,7 +171,7 @@ func (l *localLocker) RLock(args dsync.LockArgs) (reply bool, err error) {
 		serviceEndpoint: args.ServiceEndpoint,
 		uid:             args.UID,
 		timestamp:       UTCNow(),
-		timeLastCheck:   UTCNow(),
+		timeLastRefresh: UTCNow(),
 	}
 	if lri, ok := l.lockMap[args.Resource]; ok {
 		if reply = !isWriteLock(lri); reply {
@@ -202,6 +204,24 @@ func (l *localLocker) RUnlock(args dsync.LockArgs) (reply bool, err error) {
 	return reply, nil
 }
 
+func (l *localLocker) Refresh(args dsync.LockArgs) (reply bool, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	lri, ok := l.lockMap[args.Resource]
+	if !ok {
+		// No lock is held on the given name
+		return false, nil
+	}
+	for idx := range lri {
+		if lri[idx].uid == args.UID {
+			lri[idx].timeLastRefresh = UTCNow()
+			return true, nil
+		}
+	}
+	// Lock was released or dropped as stale
+	return false, nil
+}
+
 func (l *localLocker) ForceUnlock(args dsync.LockArgs) (reply bool, err error) {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
@@ -216,6 +236,43 @@ func (l *localLocker) ForceUnlock(args dsync.LockArgs) (reply bool, err error) {
 	return true, nil
 }
 
+// leaseStates - returns the leases on resources of bucket matching
+// prefix, held for longer than duration.
+func (l *localLocker) leaseStates(bucket, prefix string, duration time.Duration) map[nsParam][]LockLeaseState {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	timeNow := UTCNow()
+	leases := make(map[nsParam][]LockLeaseState)
+	for name, lri := range l.lockMap {
+		// Resources are named <volume>/<path>, see nsLockMap.lock().
+		nameParts := strings.SplitN(name, slashSeparator, 2)
+		param := nsParam{volume: nameParts[0]}
+		if len(nameParts) == 2 {
+			param.path = nameParts[1]
+		}
+		if param.volume != bucket || !hasPrefix(param.path, prefix) {
+			continue
+		}
+		for _, entry := range lri {
+			if timeNow.Sub(entry.timestamp) < duration {
+				continue
+			}
+			lType := debugRLockStr
+			if entry.writer {
+				lType = debugWLockStr
+			}
+			leases[param] = append(leases[param], LockLeaseState{
+				Owner:    entry.node,
+				LockType: lType,
+				Since:    entry.timestamp,
+				LeaseAge: timeNow.Sub(entry.timeLastRefresh),
+			})
+		}
+	}
+	return leases
+}
+
 ///  Distributed lock handlers
 
 // Lock - rpc handler for (single) write lock operation.
@@ -263,6 +320,15 @@ func (l *lockServer) ForceUnlock(args *LockArgs, reply *bool) (err error) {
 	return err
 }
 
+// Refresh - rpc handler for refreshing the lease of a lock.
+func (l *lockServer) Refresh(args *LockArgs, reply *bool) (err error) {
+	if err = args.IsAuthenticated(); err != nil {
+		return err
+	}
+	*reply, err = l.ll.Refresh(args.LockArgs)
+	return err
+}
+
 // Expired - rpc handler for expired lock status.
 func (l *lockServer) Expired(args *LockArgs, reply *bool) error {
 	if err := args.IsAuthenticated(); err != nil {
@@ -292,49 +358,15 @@ type nameLockRequesterInfoPair struct {
 	lri  lockRequesterInfo
 }
 
-// lockMaintenance loops over locks that have been active for some time and checks back
-// with the original server whether it is still alive or not
-//
-// Following logic inside ignores the errors generated for Dsync.Active operation.
-// - server at client down
-// - some network error (and server is up normally)
-//
-// We will ignore the error, and we will retry later to get a resolve on this lock
-func (l *lockServer) lockMaintenance(interval time.Duration) {
+// lockMaintenance drops locks which were not refreshed by their
+// holders within ttl, i.e. the holder crashed or lost its connection
+// to this server.
+func (l *lockServer) lockMaintenance(ttl time.Duration) {
 	l.ll.mutex.Lock()
-	// Get list of long lived locks to check for staleness.
-	nlripLongLived := getLongLivedLocks(l.ll.lockMap, interval)
-	l.ll.mutex.Unlock()
-
-	serverCred := globalServerConfig.GetCredential()
-	// Validate if long lived locks are indeed clean.
-	for _, nlrip := range nlripLongLived {
-		// Initialize client based on the long live locks.
-		c := newLockRPCClient(authConfig{
-			accessKey:       serverCred.AccessKey,
-			secretKey:       serverCred.SecretKey,
-			serverAddr:      nlrip.lri.node,
-			secureConn:      globalIsSSL,
-			serviceEndpoint: nlrip.lri.serviceEndpoint,
-			serviceName:     lockServiceName,
-		})
-
-		// Call back to original server verify whether the lock is still active (based on name & uid)
-		expired, _ := c.Expired(dsync.LockArgs{
-			UID:      nlrip.lri.uid,
-			Resource: nlrip.name,
-		})
-
-		// Close the connection regardless of the call response.
-		c.rpcClient.Close()
-
-		// For successful response, verify if lock is indeed active or stale.
-		if expired {
-			// The lock is no longer active at server that originated the lock
-			// So remove the lock from the map.
-			l.ll.mutex.Lock()
-			l.ll.removeEntryIfExists(nlrip) // Purge the stale entry if it exists.
-			l.ll.mutex.Unlock()
-		}
+	defer l.ll.mutex.Unlock()
+
+	for _, nlrip := range getStaleLocks(l.ll.lockMap, ttl) {
+		l.ll.removeEntryIfExists(nlrip) // Purge the stale entry if it exists.
+		globalLockMetrics.staleLockDropped()
 	}
 }
@@ -21,8 +21,9 @@ import (
 	"runtime"
 	"sync"
 	"testing"
+	"time"
 
-	"github.com/minio/dsync"
+	"github.com/minio/minio/pkg/dsync"
 )
 
 // Helper function to test equality of locks (without taking timing info into account)
@@ -435,6 +436,98 @@ func TestLockRpcServerExpired(t *testing.T) {
 	}
 }
 
+// Test Refresh functionality and dropping of stale locks.
+func TestLockRpcServerRefresh(t *testing.T) {
+	testPath, locker, token := createLockTestServer(t)
+	defer os.RemoveAll(testPath)
+
+	la := newLockArgs(dsync.LockArgs{
+		UID:             "0123-4567",
+		Resource:        "name",
+		ServerAddr:      "node",
+		ServiceEndpoint: "rpc-path",
+	})
+	la.SetAuthToken(token)
+	la.SetRPCAPIVersion(globalRPCAPIVersion)
+
+	// Unknown lock can not be refreshed.
+	var refreshed bool
+	if err := locker.Refresh(&la, &refreshed); err != nil || refreshed {
+		t.Fatalf("Expected unknown lock not to be refreshed, got %v %v", refreshed, err)
+	}
+
+	var result bool
+	if err := locker.Lock(&la, &result); err != nil || !result {
+		t.Fatalf("Unable to lock, got %v %v", result, err)
+	}
+	locker.ll.lockMap["name"][0].timeLastRefresh = UTCNow().Add(-time.Minute)
+	if err := locker.Refresh(&la, &refreshed); err != nil || !refreshed {
+		t.Fatalf("Expected lock to be refreshed, got %v %v", refreshed, err)
+	}
+
+	// Refreshed lock is kept.
+	locker.lockMaintenance(lockLeaseTTL)
+	if _, ok := locker.ll.lockMap["name"]; !ok {
+		t.Fatal("Expected refreshed lock to be kept")
+	}
+
+	// Stale lock is dropped.
+	staleLocks := globalLockMetrics.staleLocks.Load()
+	locker.ll.lockMap["name"][0].timeLastRefresh = UTCNow().Add(-2 * lockLeaseTTL)
+	locker.lockMaintenance(lockLeaseTTL)
+	if _, ok := locker.ll.lockMap["name"]; ok {
+		t.Fatal("Expected stale lock to be dropped")
+	}
+	if globalLockMetrics.staleLocks.Load() != staleLocks+1 {
+		t.Error("Expected stale lock to be counted")
+	}
+	if err := locker.Refresh(&la, &refreshed); err != nil || refreshed {
+		t.Fatalf("Expected dropped lock not to be refreshed, got %v %v", refreshed, err)
+	}
+}
+
+// Tests that dsync refreshes the locks it holds until they are released.
+func TestLockRefreshByDsync(t *testing.T) {
+	prevInterval := dsync.DRWMutexRefreshInterval
+	dsync.DRWMutexRefreshInterval = 10 * time.Millisecond
+	defer func() { dsync.DRWMutexRefreshInterval = prevInterval }()
+
+	lockers := []*localLocker{
+		{serverAddr: "node1", lockMap: make(map[string][]lockRequesterInfo)},
+		{serverAddr: "node2", lockMap: make(map[string][]lockRequesterInfo)},
+	}
+	ds, err := dsync.New([]dsync.NetLocker{lockers[0], lockers[1]}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastRefresh := func(l *localLocker) (time.Time, bool) {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		lri, ok := l.lockMap["bucket/object"]
+		if !ok {
+			return time.Time{}, false
+		}
+		return lri[0].timeLastRefresh, true
+	}
+
+	mutex := dsync.NewDRWMutex("bucket/object", ds)
+	mutex.Lock()
+	granted, _ := lastRefresh(lockers[1])
+	time.Sleep(100 * time.Millisecond)
+	for i, l := range lockers {
+		if refreshed, ok := lastRefresh(l); !ok || !refreshed.After(granted) {
+			t.Errorf("Locker %d: Expected lock to be refreshed", i+1)
+		}
+	}
+
+	mutex.Unlock()
+	for i, l := range lockers {
+		if _, ok := lastRefresh(l); ok {
+			t.Errorf("Locker %d: Expected lock to be released", i+1)
+		}
+	}
+}
+
 // Test initialization of lock server.
 func TestLockServerInit(t *testing.T) {
 	if runtime.GOOS == globalWindowsOSName {
@@ -52,6 +52,9 @@ type VolumeLockInfo struct {
 	// State information containing state of the locks for all operations
 	// on given <volume,path> pair.
 	LockDetailsOnObject []OpsLockState `json:"lockOwners"`
+	// Leases of distributed locks on given <volume,path> pair granted
+	// by the lock server of this node, held by any node.
+	Leases []LockLeaseState `json:"leases,omitempty"`
 }
 
 // OpsLockState - structure to fill in state information of the lock.
@@ -64,6 +67,42 @@ type OpsLockState struct {
 	Since       time.Time  `json:"since"`  // Time when the lock was initially held.
 }
 
+// LockLeaseState - structure to fill in state information of a lease
+// granted by a lock server.
+type LockLeaseState struct {
+	Owner    string        `json:"owner"`    // Address of the node holding the lock.
+	LockType lockType      `json:"type"`     // Lock type (RLock, WLock)
+	Since    time.Time     `json:"since"`    // Time when the lock was granted.
+	LeaseAge time.Duration `json:"leaseAge"` // Time since the owner last refreshed the lock.
+}
+
+// addLockLeases - adds the leases granted by the lock server of this
+// node on bucket, matching prefix, held for longer than duration to
+// volumeLocks.
+func addLockLeases(volumeLocks []VolumeLockInfo, bucket, prefix string, duration time.Duration) []VolumeLockInfo {
+	if globalLockServer == nil {
+		return volumeLocks
+	}
+	for param, leases := range globalLockServer.ll.leaseStates(bucket, prefix, duration) {
+		found := false
+		for i := range volumeLocks {
+			if volumeLocks[i].Bucket == param.volume && volumeLocks[i].Object == param.path {
+				volumeLocks[i].Leases = leases
+				found = true
+			}
+		}
+		if !found {
+			// Lock held by another node only, possibly a stale one.
+			volumeLocks = append(volumeLocks, VolumeLockInfo{
+				Bucket: param.volume,
+				Object: param.path,
+				Leases: leases,
+			})
+		}
+	}
+	return volumeLocks
+}
+
 // listLocksInfo - Fetches locks held on bucket, matching prefix held for longer than duration.
 func listLocksInfo(bucket, prefix string, duration time.Duration) []VolumeLockInfo {
 	locksInfo, _ := newObjectLayerFn().ListLocks(bucket, prefix, duration)
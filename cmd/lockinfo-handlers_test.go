@@ -105,3 +105,43 @@ func TestListLocksInfo(t *testing.T) {
 		}
 	}
 }
+
+// Tests that leases of the local lock server are added to lock info.
+func TestAddLockLeases(t *testing.T) {
+	prevLockServer := globalLockServer
+	defer func() { globalLockServer = prevLockServer }()
+
+	now := UTCNow()
+	globalLockServer = &lockServer{
+		ll: localLocker{
+			lockMap: map[string][]lockRequesterInfo{
+				"bucket/held": {{writer: true, node: "node1", uid: "1", timestamp: now.Add(-time.Minute), timeLastRefresh: now}},
+				"bucket/stale": {
+					{node: "node2", uid: "2", timestamp: now.Add(-time.Hour), timeLastRefresh: now.Add(-time.Hour)},
+					{node: "node3", uid: "3", timestamp: now.Add(-time.Hour), timeLastRefresh: now},
+				},
+				"bucket/new":   {{node: "node1", uid: "4", timestamp: now, timeLastRefresh: now}},
+				"other/object": {{node: "node1", uid: "5", timestamp: now.Add(-time.Hour), timeLastRefresh: now}},
+			},
+		},
+	}
+
+	volumeLocks := addLockLeases([]VolumeLockInfo{{Bucket: "bucket", Object: "held"}}, "bucket", "", time.Second)
+	if len(volumeLocks) != 2 {
+		t.Fatalf("Expected 2 lock infos, got %#v", volumeLocks)
+	}
+	for _, volLock := range volumeLocks {
+		switch volLock.Object {
+		case "held":
+			if len(volLock.Leases) != 1 || volLock.Leases[0].Owner != "node1" || volLock.Leases[0].LockType != debugWLockStr {
+				t.Errorf("Unexpected leases %#v", volLock.Leases)
+			}
+		case "stale":
+			if len(volLock.Leases) != 2 || volLock.Leases[0].LeaseAge < time.Minute || volLock.Leases[1].LeaseAge > time.Minute {
+				t.Errorf("Unexpected leases %#v", volLock.Leases)
+			}
+		default:
+			t.Errorf("Unexpected lock info %#v", volLock)
+		}
+	}
+}
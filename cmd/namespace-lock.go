@@ -26,9 +26,9 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/minio/dsync"
 	"github.com/minio/lsync"
 	"github.com/minio/minio-go/pkg/set"
+	"github.com/minio/minio/pkg/dsync"
 )
 
 // Global name space lock.
@@ -46,6 +46,21 @@ type RWLocker interface {
 	Unlock()
 	GetRLock(timeout *dynamicTimeout) (timedOutErr error)
 	RUnlock()
+	Lost() <-chan struct{}
+}
+
+// errLockLost - the write lock was lost before the write was committed,
+// e.g. it could not be refreshed on a quorum of lock servers.
+var errLockLost = errors.New("Write lock was lost")
+
+// isLockLost - returns true if the lost channel of a lock is closed.
+func isLockLost(lostCh <-chan struct{}) bool {
+	select {
+	case <-lostCh:
+		return true
+	default:
+		return false
+	}
 }
 
 // RWLockerSync - internal locker interface.
@@ -139,7 +154,10 @@ type nsLockMap struct {
 	debugLockMap map[nsParam]*debugLockInfoPerVolumePath // Info for instrumentation on locks.
 
 	// Indicates if namespace is part of a distributed setup.
-	isDistXL     bool
+	isDistXL bool
+	// Directory of lock files if namespace is shared with other
+	// servers through a filesystem.
+	lockDir      string
 	lockMap      map[nsParam]*nsLock
 	lockMapMutex sync.Mutex
 }
@@ -157,6 +175,9 @@ func (n *nsLockMap) lock(volume, path string, lockSource, opsID string, readLock
 				if n.isDistXL {
 					return dsync.NewDRWMutex(pathJoin(volume, path), globalDsync)
 				}
+				if n.lockDir != "" {
+					return newFSRWMutex(n.lockDir, volume, path)
+				}
 				return &lsync.LRWMutex{}
 			}(),
 			ref: 0,
@@ -375,6 +396,19 @@ func (li *lockInstance) RUnlock() {
 	li.ns.unlock(li.volume, li.path, li.opsID, readLock)
 }
 
+// Lost - returns a channel which is closed when the write lock is lost,
+// nil if the lock can not be lost.
+func (li *lockInstance) Lost() <-chan struct{} {
+	li.ns.lockMapMutex.Lock()
+	defer li.ns.lockMapMutex.Unlock()
+	if nsLk, ok := li.ns.lockMap[nsParam{li.volume, li.path}]; ok {
+		if dm, ok := nsLk.RWLockerSync.(*dsync.DRWMutex); ok {
+			return dm.Lost()
+		}
+	}
+	return nil
+}
+
 func getSource() string {
 	var funcName string
 	pc, filename, lineNum, ok := runtime.Caller(2)
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"io"
 	"sync"
 
 	"github.com/minio/minio/pkg/disk"
@@ -136,6 +137,20 @@ func (d *naughtyDisk) AppendFile(volume, path string, buf []byte) error {
 	return d.disk.AppendFile(volume, path, buf)
 }
 
+func (d *naughtyDisk) CreateFile(volume, path string, size int64, reader io.Reader) error {
+	if err := d.calcError(); err != nil {
+		return err
+	}
+	return d.disk.CreateFile(volume, path, size, reader)
+}
+
+func (d *naughtyDisk) ReadFileStream(volume, path string, offset, length int64, verifier *BitrotVerifier) (io.ReadCloser, error) {
+	if err := d.calcError(); err != nil {
+		return nil, err
+	}
+	return d.disk.ReadFileStream(volume, path, offset, length, verifier)
+}
+
 func (d *naughtyDisk) RenameFile(srcVolume, srcPath, dstVolume, dstPath string) error {
 	if err := d.calcError(); err != nil {
 		return err
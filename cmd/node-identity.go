@@ -0,0 +1,140 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+
+	miniohttp "github.com/minio/minio/pkg/http"
+)
+
+// Token sent by nodes authenticated by their certificate instead of
+// a JWT, it is never accepted without a verified node certificate.
+const nodeAuthToken = "node"
+
+// getGlobalRootCAs - returns the current root CAs, nil for the system
+// certs pool.
+func getGlobalRootCAs() *x509.CertPool {
+	globalCAsMu.RLock()
+	defer globalCAsMu.RUnlock()
+	return globalRootCAs
+}
+
+// getGlobalNodeCAs - returns the current CAs of node certificates.
+func getGlobalNodeCAs() *x509.CertPool {
+	globalCAsMu.RLock()
+	defer globalCAsMu.RUnlock()
+	return globalNodeCAs
+}
+
+// getNodeClientCertificate - returns the certificate presented to the
+// TLS server of other nodes, the latest one if it was reloaded. Can be
+// used as tls.Config.GetClientCertificate.
+func getNodeClientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if globalNodeCerts == nil {
+		// No certificate is sent.
+		return &tls.Certificate{}, nil
+	}
+	return globalNodeCerts.GetClientCertificate(info)
+}
+
+// newNodeTLSConfig - returns the TLS configuration of a connection to
+// the node serverName, using the current root CAs and node certificate.
+func newNodeTLSConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		ServerName:           serverName,
+		RootCAs:              getGlobalRootCAs(),
+		GetClientCertificate: getNodeClientCertificate,
+	}
+}
+
+// getNodeServerTLSConfig - returns a tls.Config.GetConfigForClient of
+// the server, which verifies node certificates against the current
+// node CAs.
+func getNodeServerTLSConfig(config *tls.Config) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		clientConfig := config.Clone()
+		clientConfig.GetConfigForClient = nil
+		clientConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		clientConfig.ClientCAs = getGlobalNodeCAs()
+		return clientConfig, nil
+	}
+}
+
+// getNodeAuthToken - returns the token authenticating this node to
+// other nodes.
+func getNodeAuthToken(accessKey, secretKey string) (string, error) {
+	if globalNodeCerts != nil {
+		return nodeAuthToken, nil
+	}
+	return authenticateNode(accessKey, secretKey)
+}
+
+// isNodeCertificate - returns true if the leaf of chain was issued by
+// one of the node CAs and is valid for the host of one of the endpoints
+// of this setup, node identities are pinned to the endpoints.
+func isNodeCertificate(chain []*x509.Certificate) bool {
+	nodeCAs := getGlobalNodeCAs()
+	if nodeCAs == nil || len(chain) == 0 {
+		return false
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	cert := chain[0]
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         nodeCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return false
+	}
+
+	for _, endpoint := range globalEndpoints {
+		if endpoint.Host == "" {
+			continue
+		}
+		host, _, err := net.SplitHostPort(endpoint.Host)
+		if err != nil {
+			host = endpoint.Host
+		}
+		if cert.VerifyHostname(host) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isNodeRequest - returns true if the client of r presented a node
+// certificate, verified against the node CAs during the TLS handshake.
+func isNodeRequest(r *http.Request) bool {
+	if globalNodeCerts == nil {
+		return false
+	}
+
+	var chains [][]*x509.Certificate
+	if r.TLS != nil {
+		chains = r.TLS.VerifiedChains
+	} else if localAddr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		chains = miniohttp.VerifiedChains(localAddr.String(), r.RemoteAddr)
+	}
+	return len(chains) > 0 && isNodeCertificate(chains[0])
+}
@@ -0,0 +1,227 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	router "github.com/gorilla/mux"
+	"github.com/minio/minio/pkg/auth"
+	"github.com/minio/minio/pkg/certs"
+)
+
+// newTestNodeCertificate - returns a certificate for the given IP
+// address signed by parent, or a self signed CA if parent is nil.
+func newTestNodeCertificate(t *testing.T, ip string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: ip},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	signer, signerKey := template, interface{}(key)
+	if parent == nil {
+		template.IsCA = true
+	} else {
+		template.IPAddresses = []net.IP{net.ParseIP(ip)}
+		if signer, err = x509.ParseCertificate(parent.Certificate[0]); err != nil {
+			t.Fatal(err)
+		}
+		signerKey = parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// newTestNodeCerts - returns a certificate manager presenting cert,
+// its files are placed in dir.
+func newTestNodeCerts(t *testing.T, dir string, cert tls.Certificate) *certs.Manager {
+	certFile, keyFile := filepath.Join(dir, publicCertFile), filepath.Join(dir, privateKeyFile)
+	for _, file := range []string{certFile, keyFile} {
+		if err := ioutil.WriteFile(file, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nodeCerts := certs.NewManager(func(certFile, keyFile string) (tls.Certificate, error) {
+		return cert, nil
+	})
+	if err := nodeCerts.AddCertificate(certFile, keyFile); err != nil {
+		t.Fatal(err)
+	}
+	return nodeCerts
+}
+
+func TestIsNodeCertificate(t *testing.T) {
+	prevEndpoints, prevNodeCAs := globalEndpoints, globalNodeCAs
+	defer func() { globalEndpoints, globalNodeCAs = prevEndpoints, prevNodeCAs }()
+	globalEndpoints = EndpointList{
+		{URL: &url.URL{Scheme: "https", Host: "10.0.0.1:9000", Path: "/d1"}},
+		{URL: &url.URL{Scheme: "https", Host: "10.0.0.2:9000", Path: "/d1"}},
+	}
+
+	ca := newTestNodeCertificate(t, "", nil)
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalNodeCAs = x509.NewCertPool()
+	globalNodeCAs.AddCert(caCert)
+	// A CA trusted for other purposes, e.g. in the system pool.
+	otherCA := newTestNodeCertificate(t, "", nil)
+
+	testCases := []struct {
+		ip     string
+		ca     *tls.Certificate
+		isNode bool
+	}{
+		{"10.0.0.1", &ca, true},
+		{"10.0.0.2", &ca, true},
+		{"10.0.0.3", &ca, false},
+		{"10.0.0.1", &otherCA, false},
+	}
+	for i, testCase := range testCases {
+		cert, err := x509.ParseCertificate(newTestNodeCertificate(t, testCase.ip, testCase.ca).Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isNode := isNodeCertificate([]*x509.Certificate{cert}); isNode != testCase.isNode {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.isNode, isNode)
+		}
+	}
+}
+
+// Tests that nodes presenting a certificate pinned to an endpoint are
+// authenticated without the root credentials.
+func TestNodeAuthenticatedRPC(t *testing.T) {
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	disks, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(disks)
+
+	prevEndpoints, prevRootCAs, prevNodeCAs, prevNodeCert := globalEndpoints, globalRootCAs, globalNodeCAs, globalNodeCerts
+	defer func() {
+		globalEndpoints, globalRootCAs, globalNodeCAs, globalNodeCerts = prevEndpoints, prevRootCAs, prevNodeCAs, prevNodeCert
+	}()
+
+	ca := newTestNodeCertificate(t, "", nil)
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalRootCAs = x509.NewCertPool()
+	globalRootCAs.AddCert(caCert)
+	globalNodeCAs = globalRootCAs
+	nodeCert := newTestNodeCertificate(t, "127.0.0.1", &ca)
+	globalNodeCerts = newTestNodeCerts(t, root, nodeCert)
+
+	mux := router.NewRouter().SkipClean(true)
+	endpoints := mustGetNewEndpointList(disks...)
+	if err = registerStorageRPCRouters(mux, endpoints); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(mux)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{nodeCert},
+	}
+	server.TLS.GetConfigForClient = getNodeServerTLSConfig(server.TLS)
+	server.StartTLS()
+	defer server.Close()
+
+	prevIsSSL := globalIsSSL
+	globalIsSSL = true
+	defer func() { globalIsSSL = prevIsSSL }()
+
+	endpoint := endpoints[0]
+	endpoint.Scheme = "https"
+	endpoint.Host = server.Listener.Addr().String()
+	endpoint.IsLocal = false
+
+	testCases := []struct {
+		endpointIP string
+		success    bool
+	}{
+		// Node certificate pinned to an endpoint.
+		{"127.0.0.1", true},
+		// Node certificate not valid for any endpoint.
+		{"10.0.0.1", false},
+	}
+	for i, testCase := range testCases {
+		pinned := endpoint
+		pinned.URL = &url.URL{Scheme: "https", Host: testCase.endpointIP + ":9000", Path: endpoint.Path}
+		globalEndpoints = EndpointList{pinned}
+
+		storage := newStorageRPC(endpoint)
+		// Root credentials are not used between nodes, rotate them.
+		globalServerConfig.SetCredential(auth.MustGetNewCredentials())
+		_, err = storage.DiskInfo()
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: Expected success, got %v", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: Expected authentication failure", i+1)
+		}
+		storage.Close()
+	}
+
+	// Node CAs are looked up on every handshake, a node certificate
+	// issued by a CA no longer trusted is rejected.
+	globalEndpoints = EndpointList{{URL: &url.URL{Scheme: "https", Host: "127.0.0.1:9000", Path: endpoint.Path}}}
+	otherCA := newTestNodeCertificate(t, "", nil)
+	otherCACert, err := x509.ParseCertificate(otherCA.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	globalCAsMu.Lock()
+	globalNodeCAs = x509.NewCertPool()
+	globalNodeCAs.AddCert(otherCACert)
+	globalCAsMu.Unlock()
+	storage := newStorageRPC(endpoint)
+	if _, err = storage.DiskInfo(); err == nil {
+		t.Error("Expected authentication failure after the node CAs changed")
+	}
+	storage.Close()
+}
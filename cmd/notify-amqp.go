@@ -142,6 +142,13 @@ func (q *amqpConn) Channel() (*amqp.Channel, error) {
 }
 
 // Fire is called when an event should be sent to the message broker.
+// Close - closes the amqp connection.
+func (q *amqpConn) Close() {
+	q.Lock()
+	defer q.Unlock()
+	_ = q.conn.Close()
+}
+
 func (q *amqpConn) Fire(entry *logrus.Entry) error {
 	ch, err := q.Channel()
 	if err != nil {
@@ -124,6 +124,11 @@ func newElasticNotify(accountID string) (*logrus.Logger, error) {
 }
 
 // Fire is required to implement logrus hook
+// Close - stops the background processes of the elastic client.
+func (q elasticClient) Close() {
+	q.Stop()
+}
+
 func (q elasticClient) Fire(entry *logrus.Entry) (err error) {
 	// Reflect on eventType and Key on their native type.
 	entryStr, ok := entry.Data["EventType"].(string)
@@ -61,7 +61,7 @@ func dialMQTT(mqttL mqttNotify) (mc mqttConn, e error) {
 		Password:             mqttL.Password,
 		MaxReconnectInterval: 1 * time.Second,
 		KeepAlive:            30 * time.Second,
-		TLSConfig:            tls.Config{RootCAs: globalRootCAs},
+		TLSConfig:            tls.Config{RootCAs: getGlobalRootCAs()},
 	}
 	connOpts.AddBroker(mqttL.Broker)
 	client := MQTT.NewClient(connOpts)
@@ -96,6 +96,12 @@ func newMQTTNotify(accountID string) (*logrus.Logger, error) {
 }
 
 // Fire if called when an event should be sent to the message broker.
+// Close - disconnects from the mqtt broker, waiting at most 250ms for
+// pending work to complete.
+func (q mqttConn) Close() {
+	q.Client.Disconnect(250)
+}
+
 func (q mqttConn) Fire(entry *logrus.Entry) error {
 	body, err := entry.String()
 	if err != nil {
@@ -162,6 +162,11 @@ func newNATSNotify(accountID string) (*logrus.Logger, error) {
 }
 
 // Fire is called when an event should be sent to the message broker
+// Close - closes the nats connection.
+func (n natsIOConn) Close() {
+	closeNATS(n)
+}
+
 func (n natsIOConn) Fire(entry *logrus.Entry) error {
 	body, err := entry.Reader()
 	if err != nil {
@@ -143,7 +143,7 @@ func newWebhookNotify(accountID string) (*logrus.Logger, error) {
 		// Configure aggressive timeouts for client posts.
 		Client: &http.Client{
 			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{RootCAs: globalRootCAs},
+				TLSClientConfig: &tls.Config{RootCAs: getGlobalRootCAs()},
 				DialContext: (&net.Dialer{
 					Timeout:   5 * time.Second,
 					KeepAlive: 5 * time.Second,
@@ -137,7 +137,7 @@ func newStorageAPI(endpoint Endpoint) (storage StorageAPI, err error) {
 		return newPosix(endpoint.Path)
 	}
 
-	return newStorageRPC(endpoint), nil
+	return newStorageRESTClient(endpoint), nil
 }
 
 var initMetaVolIgnoredErrs = append(baseIgnoredErrs, errVolumeExists)
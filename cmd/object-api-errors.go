@@ -172,6 +172,22 @@ func (e ObjectNotFound) Error() string {
 	return "Object not found: " + e.Bucket + "#" + e.Object
 }
 
+// InvalidObjectState - operation is not valid for the current state
+// of the object, e.g. restoring an object which was not transitioned.
+type InvalidObjectState GenericError
+
+func (e InvalidObjectState) Error() string {
+	return "Operation is not valid for the current state of the object: " + e.Bucket + "#" + e.Object
+}
+
+// RestoreAlreadyInProgress - a transitioned object is already being
+// restored from its tier.
+type RestoreAlreadyInProgress GenericError
+
+func (e RestoreAlreadyInProgress) Error() string {
+	return "Object restore is already in progress: " + e.Bucket + "#" + e.Object
+}
+
 // ObjectExistsAsDirectory object already exists as a directory.
 type ObjectExistsAsDirectory GenericError
 
@@ -324,6 +340,14 @@ func (e InvalidPart) Error() string {
 	return "One or more of the specified parts could not be found. The part may not have been uploaded, or the specified entity tag may not match the part's entity tag."
 }
 
+// InvalidPartOrder - parts of a multipart upload are not listed in
+// ascending order.
+type InvalidPartOrder struct{}
+
+func (e InvalidPartOrder) Error() string {
+	return "The list of parts was not in ascending order. The parts list must be specified in order by part number."
+}
+
 // PartsSizeUnequal - All parts except the last part should be of the same size
 type PartsSizeUnequal struct{}
 
@@ -404,6 +428,16 @@ func isErrBucketPolicyNotFound(err error) bool {
 	return false
 }
 
+// isErrBucketNotFound - Check if error type is BucketNotFound.
+func isErrBucketNotFound(err error) bool {
+	err = errors.Cause(err)
+	switch err.(type) {
+	case BucketNotFound:
+		return true
+	}
+	return false
+}
+
 // isErrObjectNotFound - Check if error type is ObjectNotFound.
 func isErrObjectNotFound(err error) bool {
 	err = errors.Cause(err)
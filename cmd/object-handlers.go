@@ -26,7 +26,9 @@ import (
 	"net/url"
 	"sort"
 	"strconv"
+	"strings"
 
+	humanize "github.com/dustin/go-humanize"
 	mux "github.com/gorilla/mux"
 	"github.com/minio/minio/pkg/errors"
 	"github.com/minio/minio/pkg/hash"
@@ -107,6 +109,22 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if s3Error := checkPOSIXPermission(objectAPI, accessLogRequester(r), bucket, object, false); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	if !objectAPI.IsEncryptionSupported() && IsSSECustomerRequest(r.Header) {
+		// Gateway backends may decrypt the object themselves.
+		var s3Error APIErrorCode
+		if objectAPI, s3Error = newGatewaySSECustomerObjects(objectAPI, r); s3Error != ErrNone {
+			writeErrorResponse(w, s3Error, r.URL)
+			return
+		}
+		w.Header().Set(SSECustomerAlgorithm, r.Header.Get(SSECustomerAlgorithm))
+		w.Header().Set(SSECustomerKeyMD5, r.Header.Get(SSECustomerKeyMD5))
+	}
+
 	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
 	if err != nil {
 		apiErr := toAPIErrorCode(err)
@@ -231,6 +249,20 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if s3Error := checkPOSIXPermission(objectAPI, accessLogRequester(r), bucket, object, false); s3Error != ErrNone {
+		writeErrorResponseHeadersOnly(w, s3Error)
+		return
+	}
+
+	if !objectAPI.IsEncryptionSupported() && IsSSECustomerRequest(r.Header) {
+		// Gateway backends may decrypt the object themselves.
+		var s3Error APIErrorCode
+		if objectAPI, s3Error = newGatewaySSECustomerObjects(objectAPI, r); s3Error != ErrNone {
+			writeErrorResponseHeadersOnly(w, s3Error)
+			return
+		}
+	}
+
 	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
 	if err != nil {
 		apiErr := toAPIErrorCode(err)
@@ -342,6 +374,12 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Temporary credentials must be allowed to read the source.
+	if s3Error := enforceSessionPolicy(r, "s3:GetObject", pathJoin(srcBucket, srcObject)); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
 	// Check if metadata directive is valid.
 	if !isMetadataDirectiveValid(r.Header) {
 		writeErrorResponse(w, ErrInvalidMetadataDirective, r.URL)
@@ -355,6 +393,16 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	}
 	cpSrcDstSame := srcBucket == dstBucket && srcObject == dstObject
 
+	accessKey := accessLogRequester(r)
+	if s3Error := checkPOSIXPermission(objectAPI, accessKey, srcBucket, srcObject, false); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+	if s3Error := checkPOSIXPermission(objectAPI, accessKey, dstBucket, dstObject, true); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
 	objInfo, err := objectAPI.GetObjectInfo(srcBucket, srcObject)
 	if err != nil {
 		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
@@ -379,6 +427,13 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// The copy is private unless a canned ACL is requested.
+	if s3Error := setObjectACLFromHeader(objectAPI, r.Header, newMetadata); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+	setPOSIXOwner(objectAPI, accessKey, newMetadata)
+
 	// Check if x-amz-metadata-directive was not set to REPLACE and source,
 	// desination are same objects.
 	if !isMetadataReplace(r.Header) && cpSrcDstSame {
@@ -550,6 +605,32 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
+	// Verify the policies of temporary credentials.
+	if s3Err = enforceSessionPolicy(r, "s3:PutObject", pathJoin(bucket, object)); s3Err != ErrNone {
+		writeErrorResponse(w, s3Err, r.URL)
+		return
+	}
+
+	accessKey := accessLogRequester(r)
+	if s3Err = checkPOSIXPermission(objectAPI, accessKey, bucket, object, true); s3Err != ErrNone {
+		writeErrorResponse(w, s3Err, r.URL)
+		return
+	}
+
+	if s3Err = setObjectACLFromHeader(objectAPI, r.Header, metadata); s3Err != ErrNone {
+		writeErrorResponse(w, s3Err, r.URL)
+		return
+	}
+	setPOSIXOwner(objectAPI, accessKey, metadata)
+
+	if !objectAPI.IsEncryptionSupported() && IsSSECustomerRequest(r.Header) {
+		// Gateway backends may encrypt the object themselves.
+		if objectAPI, s3Err = newGatewaySSECustomerObjects(objectAPI, r); s3Err != ErrNone {
+			writeErrorResponse(w, s3Err, r.URL)
+			return
+		}
+	}
+
 	hashReader, err := hash.NewReader(reader, size, md5hex, sha256hex)
 	if err != nil {
 		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
@@ -577,11 +658,9 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 	w.Header().Set("ETag", "\""+objInfo.ETag+"\"")
-	if objectAPI.IsEncryptionSupported() {
-		if IsSSECustomerRequest(r.Header) {
-			w.Header().Set(SSECustomerAlgorithm, r.Header.Get(SSECustomerAlgorithm))
-			w.Header().Set(SSECustomerKeyMD5, r.Header.Get(SSECustomerKeyMD5))
-		}
+	if IsSSECustomerRequest(r.Header) {
+		w.Header().Set(SSECustomerAlgorithm, r.Header.Get(SSECustomerAlgorithm))
+		w.Header().Set(SSECustomerKeyMD5, r.Header.Get(SSECustomerKeyMD5))
 	}
 
 	writeSuccessResponseHeadersOnly(w)
@@ -638,6 +717,12 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		return
 	}
 
+	accessKey := accessLogRequester(r)
+	if s3Error := checkPOSIXPermission(objectAPI, accessKey, bucket, object, true); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
 	// Extract metadata that needs to be saved.
 	metadata, err := extractMetadataFromHeader(r.Header)
 	if err != nil {
@@ -646,6 +731,12 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		return
 	}
 
+	if s3Error := setObjectACLFromHeader(objectAPI, r.Header, metadata); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+	setPOSIXOwner(objectAPI, accessKey, metadata)
+
 	uploadID, err := objectAPI.NewMultipartUpload(bucket, object, metadata)
 	if err != nil {
 		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
@@ -696,6 +787,12 @@ func (api objectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
+	// Temporary credentials must be allowed to read the source.
+	if s3Error := enforceSessionPolicy(r, "s3:GetObject", pathJoin(srcBucket, srcObject)); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
 	uploadID := r.URL.Query().Get("uploadId")
 	partIDString := r.URL.Query().Get("partNumber")
 
@@ -881,6 +978,12 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 		}
 	}
 
+	// Verify the policies of temporary credentials.
+	if s3Error := enforceSessionPolicy(r, "s3:PutObject", pathJoin(bucket, object)); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
 	hashReader, err := hash.NewReader(reader, size, md5hex, sha256hex)
 	if err != nil {
 		// Verify if the underlying error is signature mismatch.
@@ -927,6 +1030,59 @@ func (api objectAPIHandlers) AbortMultipartUploadHandler(w http.ResponseWriter,
 	writeSuccessNoContent(w)
 }
 
+// Maximum size of a restore request in POST requests.
+const maxRestoreObjectRequestSize = 64 * humanize.KiByte
+
+// RestoreObjectHandler - restores a transitioned object from its tier
+// for a number of days. The object data is restored in the background,
+// HEAD requests report the progress in the x-amz-restore header.
+func (api objectAPIHandlers) RestoreObjectHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket := vars["bucket"]
+	object := vars["object"]
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(w, ErrServerNotInitialized, r.URL)
+		return
+	}
+
+	if s3Error := checkRequestAuthType(r, bucket, "s3:RestoreObject", globalServerConfig.GetRegion()); s3Error != ErrNone {
+		writeErrorResponse(w, s3Error, r.URL)
+		return
+	}
+
+	if r.ContentLength > maxRestoreObjectRequestSize {
+		writeErrorResponse(w, ErrEntityTooLarge, r.URL)
+		return
+	}
+	restoreRequest := &restoreObjectRequest{}
+	err := xmlDecoder(io.LimitReader(r.Body, maxRestoreObjectRequestSize), restoreRequest, r.ContentLength)
+	if err != nil || restoreRequest.Days <= 0 {
+		writeErrorResponse(w, ErrMalformedXML, r.URL)
+		return
+	}
+
+	objInfo, err := objectAPI.GetObjectInfo(bucket, object)
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+	if err = objectAPI.RestoreObject(bucket, object, restoreRequest.Days); err != nil {
+		errorIf(err, "RestoreObject failed")
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	// Extending the expiry of an already restored copy completes
+	// immediately, a restore from the tier is only started.
+	if strings.HasPrefix(objInfo.UserDefined[amzRestore], `ongoing-request="false"`) {
+		writeSuccessResponseHeadersOnly(w)
+		return
+	}
+	writeResponse(w, http.StatusAccepted, nil, mimeNone)
+}
+
 // ListObjectPartsHandler - List object parts
 func (api objectAPIHandlers) ListObjectPartsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -25,7 +25,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 
@@ -3564,6 +3566,125 @@ func testAPIListObjectPartsHandler(obj ObjectLayer, instanceType, bucketName str
 	ExecObjectLayerAPINilTest(t, nilBucket, nilObject, instanceType, apiRouter, nilReq)
 }
 
+// blockingTierLayer - tier whose reads wait until releaseCh is closed.
+type blockingTierLayer struct {
+	ObjectLayer
+	releaseCh chan struct{}
+}
+
+func (l *blockingTierLayer) GetObject(bucket, object string, startOffset, length int64, writer io.Writer, etag string) error {
+	<-l.releaseCh
+	return l.ObjectLayer.GetObject(bucket, object, startOffset, length, writer, etag)
+}
+
+// Tests that RestoreObjectHandler restores transitioned objects in the
+// background and HEAD reports the progress.
+func TestAPIRestoreObjectHandler(t *testing.T) {
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer os.RemoveAll(root)
+	credentials := globalServerConfig.GetCredential()
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	tierDir, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(tierDir)
+	fsTier, err := newFSObjectLayer(tierDir[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = fsTier.MakeBucketWithLocation("tier", ""); err != nil {
+		t.Fatal(err)
+	}
+	tierLayer := &blockingTierLayer{fsTier, make(chan struct{})}
+	rule := transitionRule{Bucket: "bucket", Days: 1, Tier: "cold"}
+	defer setTestTier(t, "blocktest", tierLayer, rule)()
+
+	if err = obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("transitioned object")
+	for _, object := range []string{"object", "local"} {
+		if _, err = obj.PutObject("bucket", object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transitionObject := func(object string) {
+		if err = obj.(*xlObjects).transitionObject("bucket", object, "cold", UTCNow()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transitionObject("object")
+	apiRouter := initTestAPIEndPoints(obj, []string{"RestoreObject", "HeadObject"})
+
+	restore := func(object string, body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req, rerr := newTestSignedRequestV4("POST", getRestoreObjectURL("", "bucket", object),
+			int64(len(body)), bytes.NewReader(body), credentials.AccessKey, credentials.SecretKey)
+		if rerr != nil {
+			t.Fatal(rerr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec
+	}
+	restoreHeader := func(object string) string {
+		rec := httptest.NewRecorder()
+		req, rerr := newTestSignedRequestV4("HEAD", getHeadObjectURL("", "bucket", object),
+			0, nil, credentials.AccessKey, credentials.SecretKey)
+		if rerr != nil {
+			t.Fatal(rerr)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected HEAD to succeed, got %d", rec.Code)
+		}
+		return rec.Header().Get(amzRestore)
+	}
+	restoreRequest := []byte("<RestoreRequest><Days>1</Days></RestoreRequest>")
+
+	testCases := []struct {
+		object       string
+		body         []byte
+		expectedCode int
+	}{
+		{"object", []byte("<RestoreRequest>"), http.StatusBadRequest},
+		{"object", []byte("<RestoreRequest><Days>0</Days></RestoreRequest>"), http.StatusBadRequest},
+		{"object", bytes.Repeat([]byte(" "), maxRestoreObjectRequestSize+1), http.StatusBadRequest},
+		{"local", restoreRequest, http.StatusForbidden},
+		{"missing", restoreRequest, http.StatusNotFound},
+		// The restore is started, another one is rejected until it finished.
+		{"object", restoreRequest, http.StatusAccepted},
+		{"object", restoreRequest, http.StatusConflict},
+	}
+	for i, testCase := range testCases {
+		if rec := restore(testCase.object, testCase.body); rec.Code != testCase.expectedCode {
+			t.Errorf("Test %d: Expected the response status to be `%d`, but instead found `%d`", i+1, testCase.expectedCode, rec.Code)
+		}
+	}
+	if header := restoreHeader("object"); header != `ongoing-request="true"` {
+		t.Errorf("Expected the restore to be in progress, got %s", header)
+	}
+
+	close(tierLayer.releaseCh)
+	waitForRestore(t, obj, "bucket", "object")
+	if header := restoreHeader("object"); !strings.HasPrefix(header, `ongoing-request="false", expiry-date=`) {
+		t.Errorf("Expected the object to be restored, got %s", header)
+	}
+	// Extending the expiry of the restored copy completes immediately.
+	if rec := restore("object", restoreRequest); rec.Code != http.StatusOK {
+		t.Errorf("Expected the response status to be `%d`, but instead found `%d`", http.StatusOK, rec.Code)
+	}
+}
+
 // TestGetSourceIPAddress - check the source ip of a request is parsed correctly.
 func TestGetSourceIPAddress(t *testing.T) {
 	testCases := []struct {
@@ -746,6 +746,145 @@ func (s *posix) PrepareFile(volume, path string, fileSize int64) (err error) {
 	return nil
 }
 
+// CreateFile - appends all data read from reader until EOF at path, if
+// file doesn't exist at path this call explicitly creates it. If size
+// is not negative exactly size bytes have to be read.
+func (s *posix) CreateFile(volume, path string, size int64, reader io.Reader) (err error) {
+	defer func() {
+		if err == syscall.EIO {
+			atomic.AddInt32(&s.ioErrCount, 1)
+		}
+	}()
+
+	if atomic.LoadInt32(&s.ioErrCount) > maxAllowedIOError {
+		return errFaultyDisk
+	}
+
+	if size > 0 {
+		// Validate if disk is indeed free.
+		if err = checkDiskFree(s.diskPath, size); err != nil {
+			return err
+		}
+	}
+
+	// Create file if not found
+	w, err := s.createFile(volume, path)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	bufp := s.pool.Get().(*[]byte)
+	defer s.pool.Put(bufp)
+
+	if size < 0 {
+		_, err = io.CopyBuffer(w, reader, *bufp)
+		return err
+	}
+	n, err := io.CopyBuffer(w, io.LimitReader(reader, size), *bufp)
+	if err != nil {
+		return err
+	}
+	if n < size {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// posixFileReader - reads a byte range of a file and closes the file
+// on Close.
+type posixFileReader struct {
+	io.Reader
+	file *os.File
+}
+
+func (r *posixFileReader) Close() error {
+	return r.file.Close()
+}
+
+// ReadFileStream - returns a stream of length bytes of the file at path
+// starting at offset, the stream ends at EOF if length is negative.
+// If verifier is not nil and not verified yet the whole file is
+// verified against bitrot while streaming, the stream then ends with
+// a hashMismatchError instead of io.EOF if the file is corrupted.
+func (s *posix) ReadFileStream(volume, path string, offset, length int64, verifier *BitrotVerifier) (rc io.ReadCloser, err error) {
+	defer func() {
+		if err == syscall.EIO {
+			atomic.AddInt32(&s.ioErrCount, 1)
+		}
+	}()
+
+	if atomic.LoadInt32(&s.ioErrCount) > maxAllowedIOError {
+		return nil, errFaultyDisk
+	}
+
+	if err = s.checkDiskFound(); err != nil {
+		return nil, err
+	}
+
+	volumeDir, err := s.getVolDir(volume)
+	if err != nil {
+		return nil, err
+	}
+	// Stat a volume entry.
+	if _, err = os.Stat(volumeDir); err != nil {
+		if os.IsNotExist(err) {
+			return nil, errVolumeNotFound
+		}
+		return nil, err
+	}
+
+	// Validate effective path length before reading.
+	filePath := pathJoin(volumeDir, path)
+	if err = checkPathLength(filePath); err != nil {
+		return nil, err
+	}
+
+	// Open the file for reading.
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errFileNotFound
+		} else if os.IsPermission(err) {
+			return nil, errFileAccessDenied
+		} else if isSysErrNotDir(err) {
+			return nil, errFileAccessDenied
+		}
+		return nil, err
+	}
+
+	st, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// Verify it is a regular file, otherwise subsequent Seek is
+	// undefined.
+	if !st.Mode().IsRegular() {
+		file.Close()
+		return nil, errIsNotRegular
+	}
+
+	if verifier != nil && !verifier.IsVerified() {
+		return &posixFileReader{
+			Reader: newBitrotVerifyingReader(file, offset, length, verifier),
+			file:   file,
+		}, nil
+	}
+
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r := &posixFileReader{Reader: file, file: file}
+	if length >= 0 {
+		r.Reader = io.LimitReader(file, length)
+	}
+	return r, nil
+}
+
 // AppendFile - append a byte array at path, if file doesn't exist at
 // path this call explicitly creates it.
 func (s *posix) AppendFile(volume, path string, buf []byte) (err error) {
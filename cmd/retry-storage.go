@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"io"
 	"time"
 
 	"github.com/minio/minio/pkg/disk"
@@ -189,6 +190,31 @@ func (f *retryStorage) AppendFile(volume, path string, buffer []byte) (err error
 	return retryToStorageErr(err)
 }
 
+// CreateFile - reconnects upon failure but is not retried, data may
+// have been consumed from reader already.
+func (f *retryStorage) CreateFile(volume, path string, size int64, reader io.Reader) (err error) {
+	if f.IsOffline() {
+		return errDiskNotFound
+	}
+	err = f.remoteStorage.CreateFile(volume, path, size, reader)
+	f.reInitUponDiskNotFound(err)
+	return retryToStorageErr(err)
+}
+
+// ReadFileStream - a retryable implementation of opening a stream of
+// a file.
+func (f *retryStorage) ReadFileStream(volume, path string, offset, length int64, verifier *BitrotVerifier) (rc io.ReadCloser, err error) {
+	if f.IsOffline() {
+		return nil, errDiskNotFound
+	}
+	rc, err = f.remoteStorage.ReadFileStream(volume, path, offset, length, verifier)
+	if f.reInitUponDiskNotFound(err) {
+		rc, err = f.remoteStorage.ReadFileStream(volume, path, offset, length, verifier)
+		return rc, retryToStorageErr(err)
+	}
+	return rc, retryToStorageErr(err)
+}
+
 // StatFile - a retryable implementation of stating a file.
 func (f *retryStorage) StatFile(volume, path string) (fileInfo FileInfo, err error) {
 	if f.IsOffline() {
@@ -37,6 +37,13 @@ func registerDistXLRouters(mux *router.Router, endpoints EndpointList) error {
 		return err
 	}
 
+	// Register storage REST handlers, the RPC storage API is kept for
+	// nodes not supporting this version of the REST storage API.
+	err = registerStorageRESTHandlers(mux, endpoints)
+	if err != nil {
+		return err
+	}
+
 	// Register distributed namespace lock.
 	err = registerDistNSLockRouter(mux, endpoints)
 	if err != nil {
@@ -80,17 +87,24 @@ func configureServerHandler(endpoints EndpointList) (http.Handler, error) {
 		}
 	}
 
+	// Add STS router, ahead of the API router.
+	registerSTSRouter(mux)
+
 	// Add API router.
 	registerAPIRouter(mux)
 
 	// List of some generic handlers which are applied for all incoming requests.
 	var handlerFns = []HandlerFunc{
-		// Ratelimit the incoming requests using a token bucket algorithm
-		setRateLimitHandler,
+		// Limits the requests served at once, queues others fairly.
+		setAdmissionControlHandler,
 		// Validate all the incoming paths.
 		setPathValidityHandler,
 		// Network statistics
 		setHTTPStatsHandler,
+		// Throttles uploads and downloads to the bandwidth limits.
+		setBandwidthThrottleHandler,
+		// Records access logs of buckets with logging enabled.
+		setBucketAccessLogHandler,
 		// Limits all requests size to a maximum fixed limit
 		setRequestSizeLimitHandler,
 		// Limits all header sizes to a maximum fixed limit
@@ -101,6 +115,8 @@ func configureServerHandler(endpoints EndpointList) (http.Handler, error) {
 		setBrowserRedirectHandler,
 		// Validates if incoming request is for restricted buckets.
 		setReservedBucketHandler,
+		// Proxies or redirects requests for buckets of other clusters.
+		setBucketFederationHandler,
 		// Adds cache control for all browser requests.
 		setBrowserCacheControlHandler,
 		// Validates all incoming requests to have a valid date header.
@@ -17,6 +17,8 @@
 package cmd
 
 import (
+	"bufio"
+	"encoding/gob"
 	"io"
 	"net/http"
 	"net/rpc"
@@ -47,6 +49,11 @@ func (server *rpcServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 	// Can connect to RPC service using HTTP CONNECT to rpcPath.
 	io.WriteString(conn, "HTTP/1.0 200 Connected to Go RPC\n\n")
+	if isNodeRequest(req) {
+		// Calls of other nodes are authenticated by certificate.
+		server.ServeCodec(newNodeServerCodec(conn))
+		return
+	}
 	server.ServeConn(conn)
 }
 
@@ -56,3 +63,70 @@ type rpcServer struct{ *rpc.Server }
 func newRPCServer() *rpcServer {
 	return &rpcServer{rpc.NewServer()}
 }
+
+// nodeAuthenticator is implemented by RPC arguments which can be
+// authenticated by the certificate of the calling node.
+type nodeAuthenticator interface {
+	setNodeAuthenticated()
+}
+
+// nodeServerCodec is the gob codec of net/rpc, it additionally marks
+// all arguments as authenticated for connections of other nodes.
+type nodeServerCodec struct {
+	rwc    io.ReadWriteCloser
+	dec    *gob.Decoder
+	enc    *gob.Encoder
+	encBuf *bufio.Writer
+	closed bool
+}
+
+func newNodeServerCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
+	buf := bufio.NewWriter(conn)
+	return &nodeServerCodec{
+		rwc:    conn,
+		dec:    gob.NewDecoder(conn),
+		enc:    gob.NewEncoder(buf),
+		encBuf: buf,
+	}
+}
+
+func (c *nodeServerCodec) ReadRequestHeader(r *rpc.Request) error {
+	return c.dec.Decode(r)
+}
+
+func (c *nodeServerCodec) ReadRequestBody(body interface{}) error {
+	if err := c.dec.Decode(body); err != nil {
+		return err
+	}
+	if args, ok := body.(nodeAuthenticator); ok {
+		args.setNodeAuthenticated()
+	}
+	return nil
+}
+
+func (c *nodeServerCodec) WriteResponse(r *rpc.Response, body interface{}) (err error) {
+	if err = c.enc.Encode(r); err != nil {
+		if c.encBuf.Flush() == nil {
+			// Gob couldn't encode the header, shut down the connection.
+			c.Close()
+		}
+		return err
+	}
+	if err = c.enc.Encode(body); err != nil {
+		if c.encBuf.Flush() == nil {
+			// Gob couldn't encode the body, shut down the connection.
+			c.Close()
+		}
+		return err
+	}
+	return c.encBuf.Flush()
+}
+
+func (c *nodeServerCodec) Close() error {
+	if c.closed {
+		// Only call c.rwc.Close once; otherwise the semantics are undefined.
+		return nil
+	}
+	c.closed = true
+	return c.rwc.Close()
+}
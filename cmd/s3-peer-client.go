@@ -180,3 +180,31 @@ func S3PeersUpdateBucketPolicy(bucket string) {
 		)
 	}
 }
+
+// S3PeersUpdateBucketLogging - Sends update bucket logging request to
+// all peers. Currently we log an error and continue.
+func S3PeersUpdateBucketLogging(bucket string) {
+	setBLPArgs := &SetBucketLoggingPeerArgs{Bucket: bucket}
+	errs := globalS3Peers.SendUpdate(nil, setBLPArgs)
+	for idx, err := range errs {
+		errorIf(
+			err,
+			"Error sending update bucket logging to %s - %v",
+			globalS3Peers[idx].addr, err,
+		)
+	}
+}
+
+// S3PeersUpdateBucketACL - Sends update bucket ACL request to all
+// peers. Currently we log an error and continue.
+func S3PeersUpdateBucketACL(bucket string) {
+	setBAPArgs := &SetBucketACLPeerArgs{Bucket: bucket}
+	errs := globalS3Peers.SendUpdate(nil, setBAPArgs)
+	for idx, err := range errs {
+		errorIf(
+			err,
+			"Error sending update bucket ACL to %s - %v",
+			globalS3Peers[idx].addr, err,
+		)
+	}
+}
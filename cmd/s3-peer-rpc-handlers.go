@@ -113,3 +113,51 @@ func (s3 *s3PeerAPIHandlers) SetBucketPolicyPeer(args *SetBucketPolicyPeerArgs,
 
 	return s3.bms.UpdateBucketPolicy(args)
 }
+
+// SetBucketLoggingPeerArgs - Arguments collection for SetBucketLoggingPeer RPC call
+type SetBucketLoggingPeerArgs struct {
+	// For Auth
+	AuthRPCArgs
+
+	Bucket string
+}
+
+// BucketUpdate - implements bucket logging updates,
+// the underlying operation is a network call updates all
+// the peers delivering access logs of the bucket.
+func (s *SetBucketLoggingPeerArgs) BucketUpdate(client BucketMetaState) error {
+	return client.UpdateBucketLogging(s)
+}
+
+// tell receiving server to reload the logging config of a bucket
+func (s3 *s3PeerAPIHandlers) SetBucketLoggingPeer(args *SetBucketLoggingPeerArgs, reply *AuthRPCReply) error {
+	if err := args.IsAuthenticated(); err != nil {
+		return err
+	}
+
+	return s3.bms.UpdateBucketLogging(args)
+}
+
+// SetBucketACLPeerArgs - Arguments collection for SetBucketACLPeer RPC call
+type SetBucketACLPeerArgs struct {
+	// For Auth
+	AuthRPCArgs
+
+	Bucket string
+}
+
+// BucketUpdate - implements bucket ACL updates,
+// the underlying operation is a network call updates all
+// the peers enforcing the canned ACL of the bucket.
+func (s *SetBucketACLPeerArgs) BucketUpdate(client BucketMetaState) error {
+	return client.UpdateBucketACL(s)
+}
+
+// tell receiving server to reload the canned ACL of a bucket
+func (s3 *s3PeerAPIHandlers) SetBucketACLPeer(args *SetBucketACLPeerArgs, reply *AuthRPCReply) error {
+	if err := args.IsAuthenticated(); err != nil {
+		return err
+	}
+
+	return s3.bms.UpdateBucketACL(args)
+}
@@ -17,14 +17,16 @@
 package cmd
 
 import (
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 
 	"github.com/minio/cli"
-	"github.com/minio/dsync"
+	"github.com/minio/minio/pkg/dsync"
 	"github.com/minio/minio/pkg/errors"
 	miniohttp "github.com/minio/minio/pkg/http"
 )
@@ -35,6 +37,14 @@ var serverFlags = []cli.Flag{
 		Value: ":" + globalMinioPort,
 		Usage: "Bind to a specific ADDRESS:PORT, ADDRESS can be an IP or hostname.",
 	},
+	cli.StringFlag{
+		Name:  "sftp",
+		Usage: "Serve SFTP on ADDRESS:PORT, users log in with the access key and secret key.",
+	},
+	cli.StringFlag{
+		Name:  "ftp",
+		Usage: "Serve FTP on ADDRESS:PORT, users log in with the access key and secret key.",
+	},
 }
 
 var serverCmd = cli.Command{
@@ -72,12 +82,15 @@ EXAMPLES:
   2. Start minio server bound to a specific ADDRESS:PORT.
       $ {{.HelpName}} --address 192.168.1.101:9000 /home/shared
 
-  3. Start erasure coded minio server on a 12 disks server.
+  3. Start minio server on "/home/shared" directory with SFTP on port 2022.
+      $ {{.HelpName}} --sftp :2022 /home/shared
+
+  4. Start erasure coded minio server on a 12 disks server.
       $ {{.HelpName}} /mnt/export1/ /mnt/export2/ /mnt/export3/ /mnt/export4/ \
           /mnt/export5/ /mnt/export6/ /mnt/export7/ /mnt/export8/ /mnt/export9/ \
           /mnt/export10/ /mnt/export11/ /mnt/export12/
 
-  4. Start erasure coded distributed minio server on a 4 node setup with 1 drive each. Run following commands on all the 4 nodes.
+  5. Start erasure coded distributed minio server on a 4 node setup with 1 drive each. Run following commands on all the 4 nodes.
       $ export MINIO_ACCESS_KEY=minio
       $ export MINIO_SECRET_KEY=miniostorage
       $ {{.HelpName}} http://192.168.1.11/mnt/export/ http://192.168.1.12/mnt/export/ \
@@ -124,6 +137,16 @@ func serverHandleEnvVars() {
 		globalServerRegion = serverRegion
 	}
 
+	if algorithm := os.Getenv("MINIO_ERASURE_ALGORITHM"); algorithm != "" {
+		if !isValidErasureAlgorithm(algorithm) {
+			fatalIf(errErasureAlgorithmUnknown, "Unknown value ‘%s’ in MINIO_ERASURE_ALGORITHM environment variable.", algorithm)
+		}
+		globalErasureAlgorithm = algorithm
+	}
+
+	globalErasureSIMD = !strings.EqualFold(os.Getenv("MINIO_ERASURE_SIMD"), "off")
+
+	fatalIf(initBucketFederation(), "Unable to initialize bucket federation.")
 }
 
 // serverMain handler called for 'minio server' command.
@@ -162,7 +185,7 @@ func serverMain(ctx *cli.Context) {
 
 	// Check and load SSL certificates.
 	var err error
-	globalPublicCerts, globalRootCAs, globalTLSCertificate, globalIsSSL, err = getSSLConfig()
+	globalPublicCerts, globalRootCAs, globalTLSCerts, globalIsSSL, err = getSSLConfig()
 	fatalIf(err, "Invalid SSL certificate file")
 
 	// Is distributed setup, error out if no certificates are found for HTTPS endpoints.
@@ -170,6 +193,17 @@ func serverMain(ctx *cli.Context) {
 		fatalIf(errInvalidArgument, "No certificates found for HTTPS endpoints (%s)", globalEndpoints)
 	}
 
+	// Nodes of a distributed setup with HTTPS endpoints may authenticate
+	// each other by certificate instead of the root credentials.
+	if globalIsDistXL && globalIsSSL {
+		globalNodeCerts, err = getNodeCertificate()
+		fatalIf(err, "Invalid node certificate file")
+		if globalNodeCerts != nil {
+			globalNodeCAs, err = getNodeCAs(getNodeCADir())
+			fatalIf(err, "Unable to load the node CA certificates")
+		}
+	}
+
 	if !quietFlag {
 		// Check for new updates from dl.minio.io.
 		mode := globalMinioModeFS
@@ -207,7 +241,11 @@ func serverMain(ctx *cli.Context) {
 	// Initialize Admin Peers inter-node communication only in distributed setup.
 	initGlobalAdminPeers(globalEndpoints)
 
-	globalHTTPServer = miniohttp.NewServer([]string{globalMinioAddr}, handler, globalTLSCertificate)
+	globalHTTPServer = miniohttp.NewServer([]string{globalMinioAddr}, handler, startCertificatesWatch())
+	if globalNodeCerts != nil {
+		// Verify node certificates, other clients present none.
+		globalHTTPServer.TLSConfig.GetConfigForClient = getNodeServerTLSConfig(globalHTTPServer.TLSConfig)
+	}
 	globalHTTPServer.ReadTimeout = globalConnReadTimeout
 	globalHTTPServer.WriteTimeout = globalConnWriteTimeout
 	globalHTTPServer.UpdateBytesReadFunc = globalConnStats.incInputBytes
@@ -227,10 +265,17 @@ func serverMain(ctx *cli.Context) {
 		os.Exit(1)
 	}
 
+	// Bucket names are unique across federated clusters, buckets
+	// created before joining the federation are registered now.
+	fatalIf(registerLocalBuckets(newObject), "Unable to register the buckets of this cluster.")
+
 	globalObjLayerMutex.Lock()
 	globalObjectAPI = newObject
 	globalObjLayerMutex.Unlock()
 
+	// Start SFTP and FTP listeners once object layer is initialized.
+	startFileTransferServers(ctx)
+
 	// Prints the formatted startup message once object layer is initialized.
 	apiEndpoints := getAPIEndpoints(globalMinioAddr)
 	printStartupMessage(apiEndpoints)
@@ -238,6 +283,17 @@ func serverMain(ctx *cli.Context) {
 	// Set uptime time after object layer has initialized.
 	globalBootTime = UTCNow()
 
+	// Heal freshly replaced local disks in the background.
+	if globalIsXL {
+		initLocalDisksAutoHeal()
+	}
+
+	// Transition cold objects to their tiers, the servers take turns
+	// scanning the namespace.
+	if xl, ok := newObject.(*xlObjects); ok {
+		go transitionColdObjects(tierScanInterval, *xl, globalServiceDoneCh)
+	}
+
 	handleSignals()
 }
 
@@ -280,3 +336,47 @@ func newObjectLayer(endpoints EndpointList) (newObject ObjectLayer, err error) {
 	// XL initialized, return.
 	return newObject, nil
 }
+
+// newLocalObjectLayer - initializes an object layer in the given local
+// directories, FS for one directory and XL otherwise. Unlike the object
+// layer of the server, it leaves the event notifier and the bucket
+// access logger of the server alone.
+func newLocalObjectLayer(dirs []string) (ObjectLayer, error) {
+	if len(dirs) == 1 {
+		return newFSObjects(dirs[0], false)
+	}
+	endpoints, err := NewEndpointList(dirs...)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.Type() != PathEndpointType {
+			return nil, fmt.Errorf("directory %s is not a local path", endpoint)
+		}
+	}
+
+	storageDisks, err := initStorageDisks(endpoints)
+	if err != nil {
+		return nil, err
+	}
+	formattedDisks, err := waitForFormatXLDisks(true, endpoints, storageDisks)
+	if err != nil {
+		return nil, err
+	}
+	if err = houseKeeping(storageDisks); err != nil {
+		return nil, err
+	}
+	objAPI, err := newXLObjects(formattedDisks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bucket policies and ACLs are kept by each object layer.
+	if err = initBucketPolicies(objAPI); err != nil {
+		return nil, fmt.Errorf("Unable to load all bucket policies. %s", err)
+	}
+	if err = initBucketACLs(objAPI); err != nil {
+		return nil, fmt.Errorf("Unable to load all bucket ACLs. %s", err)
+	}
+	return objAPI, nil
+}
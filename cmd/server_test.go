@@ -1793,14 +1793,15 @@ func (s *TestSuiteCommon) TestPutBucketErrors(c *check) {
 		http.StatusConflict)
 
 	// request for ACL.
-	// Since Minio server doesn't support ACL's the request is expected to fail with  "NotImplemented" error message.
+	// Requests setting the ACL without a canned ACL or an access control
+	// policy are expected to fail with "MissingRequestBodyError" error message.
 	request, err = newTestSignedRequest("PUT", s.endPoint+"/"+bucketName+"?acl",
 		0, nil, s.accessKey, s.secretKey, s.signer)
 	c.Assert(err, nil)
 
 	response, err = client.Do(request)
 	c.Assert(err, nil)
-	verifyError(c, response, "NotImplemented", "A header you provided implies functionality that is not implemented", http.StatusNotImplemented)
+	verifyError(c, response, "MissingRequestBodyError", "Request body is empty.", http.StatusLengthRequired)
 }
 
 func (s *TestSuiteCommon) TestGetObjectLarge10MiB(c *check) {
@@ -0,0 +1,540 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTP version 3 packet types and status codes, as described in
+// https://tools.ietf.org/html/draft-ietf-secsh-filexfer-02
+const (
+	sftpProtocolVersion = 3
+
+	sshFxpInit     = 1
+	sshFxpVersion  = 2
+	sshFxpOpen     = 3
+	sshFxpClose    = 4
+	sshFxpRead     = 5
+	sshFxpWrite    = 6
+	sshFxpLstat    = 7
+	sshFxpFstat    = 8
+	sshFxpSetstat  = 9
+	sshFxpFsetstat = 10
+	sshFxpOpendir  = 11
+	sshFxpReaddir  = 12
+	sshFxpRemove   = 13
+	sshFxpMkdir    = 14
+	sshFxpRmdir    = 15
+	sshFxpRealpath = 16
+	sshFxpStat     = 17
+	sshFxpRename   = 18
+	sshFxpStatus   = 101
+	sshFxpHandle   = 102
+	sshFxpData     = 103
+	sshFxpName     = 104
+	sshFxpAttrs    = 105
+
+	sshFxOk               = 0
+	sshFxEOF              = 1
+	sshFxNoSuchFile       = 2
+	sshFxPermissionDenied = 3
+	sshFxFailure          = 4
+	sshFxBadMessage       = 5
+	sshFxOpUnsupported    = 8
+
+	sshFxfWrite  = 0x02
+	sshFxfAppend = 0x04
+	sshFxfExcl   = 0x20
+
+	sshFileXferAttrSize        = 0x01
+	sshFileXferAttrPermissions = 0x04
+	sshFileXferAttrACModTime   = 0x08
+
+	// Packets are limited to the size of write requests of common
+	// clients plus headers.
+	sftpMaxPacketSize = 256 * 1024
+	// Data returned by a read request.
+	sftpMaxReadSize = 64 * 1024
+	// Entries returned by a read directory request.
+	sftpMaxNames = 128
+)
+
+// Extension of the SSH permissions of a login holding the session
+// token of temporary credentials.
+const sshSessionTokenExtension = "minio-session-token"
+
+// sftpServer - SSH server serving the SFTP subsystem of sessions
+// logged in with the server credentials or temporary credentials.
+type sftpServer struct {
+	config *ssh.ServerConfig
+}
+
+func newSFTPServer(hostKey ssh.Signer) *sftpServer {
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			sessionToken, err := authenticateFileTransfer(conn.User(), string(password))
+			if err != nil {
+				return nil, err
+			}
+			return &ssh.Permissions{
+				Extensions: map[string]string{sshSessionTokenExtension: sessionToken},
+			}, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+	return &sftpServer{config: config}
+}
+
+// Serve - accepts connections of l until it is closed.
+func (s *sftpServer) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *sftpServer) serveConn(conn net.Conn) {
+	// Failed handshakes and logins are not logged.
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		session := &fileTransferSession{
+			accessKey:    sshConn.User(),
+			sessionToken: sshConn.Permissions.Extensions[sshSessionTokenExtension],
+			remoteAddr:   sshConn.RemoteAddr().String(),
+			protocol:     "sftp",
+		}
+		go func() {
+			started := false
+			for req := range requests {
+				// The payload of subsystem requests is the name of the
+				// subsystem as an SSH string. A channel serves a single
+				// subsystem, later requests are rejected.
+				ok := !started && req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					started = true
+					go func() {
+						h := &sftpHandler{session: session, rw: channel, handles: make(map[string]*sftpHandle)}
+						h.serve()
+						channel.Close()
+					}()
+				}
+			}
+		}()
+	}
+}
+
+// sftpDecoder - decodes the fields of a packet, decoding errors are
+// kept until checked.
+type sftpDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *sftpDecoder) uint32() uint32 {
+	if len(d.data) < 4 {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	v := binary.BigEndian.Uint32(d.data)
+	d.data = d.data[4:]
+	return v
+}
+
+func (d *sftpDecoder) uint64() uint64 {
+	if len(d.data) < 8 {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.data)
+	d.data = d.data[8:]
+	return v
+}
+
+func (d *sftpDecoder) string() string {
+	n := d.uint32()
+	if d.err != nil || uint32(len(d.data)) < n {
+		d.err = io.ErrUnexpectedEOF
+		return ""
+	}
+	v := string(d.data[:n])
+	d.data = d.data[n:]
+	return v
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v>>32)), uint32(v))
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(appendUint32(b, uint32(len(s))), s...)
+}
+
+// appendAttrs - appends the size, permissions and times of a file.
+func appendAttrs(b []byte, fi os.FileInfo) []byte {
+	mode := uint32(fi.Mode().Perm()) | 0100000
+	if fi.IsDir() {
+		mode = uint32(fi.Mode().Perm()) | 0040000
+	}
+	b = appendUint32(b, sshFileXferAttrSize|sshFileXferAttrPermissions|sshFileXferAttrACModTime)
+	b = appendUint64(b, uint64(fi.Size()))
+	b = appendUint32(b, mode)
+	b = appendUint32(b, uint32(fi.ModTime().Unix()))
+	return appendUint32(b, uint32(fi.ModTime().Unix()))
+}
+
+// sftpHandle - open file or directory of an SFTP session.
+type sftpHandle struct {
+	path string
+	// Info of the file when opened for reading.
+	info   os.FileInfo
+	reader io.ReadCloser
+	writer *fileTransferWriter
+	// Offset of the next read or write.
+	offset int64
+	// Entries of the directory not read yet.
+	entries []os.FileInfo
+}
+
+// sftpHandler - serves the SFTP requests of a session one after the
+// other, which makes the writes of a file sequential.
+type sftpHandler struct {
+	session    *fileTransferSession
+	rw         io.ReadWriter
+	handles    map[string]*sftpHandle
+	nextHandle int
+}
+
+func (h *sftpHandler) readPacket() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(h.rw, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > sftpMaxPacketSize {
+		return 0, nil, fmt.Errorf("invalid SFTP packet length %d", length)
+	}
+	data := make([]byte, length-1)
+	if _, err := io.ReadFull(h.rw, data); err != nil {
+		return 0, nil, err
+	}
+	return header[4], data, nil
+}
+
+func (h *sftpHandler) writePacket(typ byte, data []byte) error {
+	packet := appendUint32(make([]byte, 0, len(data)+5), uint32(len(data)+1))
+	packet = append(packet, typ)
+	_, err := h.rw.Write(append(packet, data...))
+	return err
+}
+
+func (h *sftpHandler) serve() error {
+	defer h.closeHandles()
+	for {
+		typ, data, err := h.readPacket()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if typ == sshFxpInit {
+			if err = h.writePacket(sshFxpVersion, appendUint32(nil, sftpProtocolVersion)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		d := &sftpDecoder{data: data}
+		id := d.uint32()
+		respType, resp := h.handle(typ, d)
+		if d.err != nil {
+			respType, resp = sshFxpStatus, sftpStatus(sshFxBadMessage, d.err)
+		}
+		if err = h.writePacket(respType, append(appendUint32(nil, id), resp...)); err != nil {
+			return err
+		}
+	}
+}
+
+// closeHandles - closes the handles left open, incomplete uploads are
+// discarded.
+func (h *sftpHandler) closeHandles() {
+	for _, handle := range h.handles {
+		if handle.reader != nil {
+			handle.reader.Close()
+		}
+		if handle.writer != nil {
+			handle.writer.Abort()
+		}
+	}
+}
+
+// sftpStatus - returns the status response of err.
+func sftpStatus(code uint32, err error) []byte {
+	msg := "OK"
+	if err != nil {
+		msg = err.Error()
+	}
+	return appendString(appendString(appendUint32(nil, code), msg), "en")
+}
+
+// sftpErrorStatus - returns the status response of the error of a
+// request.
+func sftpErrorStatus(err error) (byte, []byte) {
+	var code uint32 = sshFxOk
+	switch {
+	case err == nil:
+	case err == io.EOF:
+		code = sshFxEOF
+	case os.IsNotExist(err):
+		code = sshFxNoSuchFile
+	case os.IsPermission(err):
+		code = sshFxPermissionDenied
+	case err == errFileTransferUnsupported:
+		code = sshFxOpUnsupported
+	default:
+		code = sshFxFailure
+	}
+	return sshFxpStatus, sftpStatus(code, err)
+}
+
+func (h *sftpHandler) newHandle(handle *sftpHandle) (byte, []byte) {
+	h.nextHandle++
+	name := strconv.Itoa(h.nextHandle)
+	h.handles[name] = handle
+	return sshFxpHandle, appendString(nil, name)
+}
+
+// handle - serves a request, returns the response type and data.
+func (h *sftpHandler) handle(typ byte, d *sftpDecoder) (byte, []byte) {
+	switch typ {
+	case sshFxpOpen:
+		p, pflags := d.string(), d.uint32()
+		if d.err != nil {
+			return 0, nil
+		}
+		return h.open(p, pflags)
+	case sshFxpClose:
+		return h.close(d.string())
+	case sshFxpRead:
+		name, offset, length := d.string(), d.uint64(), d.uint32()
+		return h.read(name, int64(offset), length)
+	case sshFxpWrite:
+		name, offset, data := d.string(), d.uint64(), d.string()
+		return h.write(name, int64(offset), data)
+	case sshFxpStat, sshFxpLstat:
+		fi, err := h.session.stat(d.string())
+		if err != nil {
+			return sftpErrorStatus(err)
+		}
+		return sshFxpAttrs, appendAttrs(nil, fi)
+	case sshFxpFstat:
+		handle, ok := h.handles[d.string()]
+		if !ok {
+			return sftpErrorStatus(os.ErrInvalid)
+		}
+		if handle.writer != nil {
+			return sshFxpAttrs, appendAttrs(nil, fileTransferInfo{name: path.Base(handle.path), size: handle.offset, modTime: UTCNow()})
+		}
+		fi, err := h.session.stat(handle.path)
+		if err != nil {
+			return sftpErrorStatus(err)
+		}
+		return sshFxpAttrs, appendAttrs(nil, fi)
+	case sshFxpSetstat, sshFxpFsetstat:
+		// Permissions and times of objects can't be set.
+		return sftpErrorStatus(nil)
+	case sshFxpOpendir:
+		p := d.string()
+		fi, err := h.session.stat(p)
+		if err != nil {
+			return sftpErrorStatus(err)
+		}
+		if !fi.IsDir() {
+			return sftpErrorStatus(errFileTransferNotDir)
+		}
+		entries, err := h.session.readDir(p)
+		if err != nil {
+			return sftpErrorStatus(err)
+		}
+		return h.newHandle(&sftpHandle{path: p, entries: entries})
+	case sshFxpReaddir:
+		return h.readDir(d.string())
+	case sshFxpRemove:
+		return sftpErrorStatus(h.session.remove(d.string()))
+	case sshFxpMkdir:
+		return sftpErrorStatus(h.session.mkdir(d.string()))
+	case sshFxpRmdir:
+		return sftpErrorStatus(h.session.rmdir(d.string()))
+	case sshFxpRename:
+		oldPath, newPath := d.string(), d.string()
+		return sftpErrorStatus(h.session.rename(oldPath, newPath))
+	case sshFxpRealpath:
+		p := path.Clean("/" + d.string())
+		resp := appendUint32(nil, 1)
+		resp = appendString(appendString(resp, p), p)
+		return sshFxpName, appendUint32(resp, 0)
+	}
+	return sshFxpStatus, sftpStatus(sshFxOpUnsupported, errFileTransferUnsupported)
+}
+
+func (h *sftpHandler) open(p string, pflags uint32) (byte, []byte) {
+	if pflags&sshFxfAppend != 0 {
+		return sftpErrorStatus(errFileTransferUnsupported)
+	}
+	if pflags&sshFxfWrite != 0 {
+		if _, err := h.session.stat(p); pflags&sshFxfExcl != 0 && err == nil {
+			return sftpErrorStatus(os.ErrExist)
+		}
+		writer, err := h.session.create(p)
+		if err != nil {
+			return sftpErrorStatus(err)
+		}
+		return h.newHandle(&sftpHandle{path: p, writer: writer})
+	}
+
+	fi, err := h.session.stat(p)
+	if err != nil {
+		return sftpErrorStatus(err)
+	}
+	if fi.IsDir() {
+		return sftpErrorStatus(errFileTransferIsDir)
+	}
+	reader, err := h.session.open(p, 0)
+	if err != nil {
+		return sftpErrorStatus(err)
+	}
+	return h.newHandle(&sftpHandle{path: p, info: fi, reader: reader})
+}
+
+func (h *sftpHandler) close(name string) (byte, []byte) {
+	handle, ok := h.handles[name]
+	if !ok {
+		return sftpErrorStatus(os.ErrInvalid)
+	}
+	delete(h.handles, name)
+	var err error
+	if handle.reader != nil {
+		handle.reader.Close()
+	}
+	if handle.writer != nil {
+		err = handle.writer.Close()
+	}
+	return sftpErrorStatus(err)
+}
+
+func (h *sftpHandler) read(name string, offset int64, length uint32) (byte, []byte) {
+	handle, ok := h.handles[name]
+	if !ok || handle.info == nil {
+		return sftpErrorStatus(os.ErrInvalid)
+	}
+	if offset >= handle.info.Size() {
+		return sftpErrorStatus(io.EOF)
+	}
+	// Files are reopened at the offset of non-sequential reads.
+	if offset != handle.offset {
+		handle.reader.Close()
+		reader, err := h.session.open(handle.path, offset)
+		if err != nil {
+			return sftpErrorStatus(err)
+		}
+		handle.reader, handle.offset = reader, offset
+	}
+
+	if length > sftpMaxReadSize {
+		length = sftpMaxReadSize
+	}
+	buf := make([]byte, length)
+	n, err := io.ReadFull(handle.reader, buf)
+	handle.offset += int64(n)
+	if n == 0 {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return sftpErrorStatus(err)
+	}
+	return sshFxpData, appendString(nil, string(buf[:n]))
+}
+
+func (h *sftpHandler) write(name string, offset int64, data string) (byte, []byte) {
+	handle, ok := h.handles[name]
+	if !ok || handle.writer == nil {
+		return sftpErrorStatus(os.ErrInvalid)
+	}
+	// Objects are uploaded as a stream.
+	if offset != handle.offset {
+		return sftpErrorStatus(errFileTransferUnsupported)
+	}
+	n, err := io.WriteString(handle.writer, data)
+	handle.offset += int64(n)
+	return sftpErrorStatus(err)
+}
+
+func (h *sftpHandler) readDir(name string) (byte, []byte) {
+	handle, ok := h.handles[name]
+	if !ok || handle.info != nil || handle.writer != nil {
+		return sftpErrorStatus(os.ErrInvalid)
+	}
+	if len(handle.entries) == 0 {
+		return sftpErrorStatus(io.EOF)
+	}
+
+	entries := handle.entries
+	if len(entries) > sftpMaxNames {
+		entries = entries[:sftpMaxNames]
+	}
+	handle.entries = handle.entries[len(entries):]
+	resp := appendUint32(nil, uint32(len(entries)))
+	for _, fi := range entries {
+		resp = appendString(resp, fi.Name())
+		resp = appendString(resp, fileTransferListLine(fi))
+		resp = appendAttrs(resp, fi)
+	}
+	return sshFxpName, resp
+}
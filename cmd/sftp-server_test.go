@@ -0,0 +1,207 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sftpTestClient - sends SFTP requests over an SSH session.
+type sftpTestClient struct {
+	t  *testing.T
+	h  *sftpHandler
+	id uint32
+}
+
+// request - sends a request, returns the response type and data
+// following the request id.
+func (c *sftpTestClient) request(typ byte, data []byte) (byte, *sftpDecoder) {
+	c.id++
+	if err := c.h.writePacket(typ, append(appendUint32(nil, c.id), data...)); err != nil {
+		c.t.Fatal(err)
+	}
+	respType, resp, err := c.h.readPacket()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	d := &sftpDecoder{data: resp}
+	if id := d.uint32(); id != c.id {
+		c.t.Fatalf("Expected response to request %d, got %d", c.id, id)
+	}
+	return respType, d
+}
+
+// status - sends a request, returns the status code of the response.
+func (c *sftpTestClient) status(typ byte, data []byte) uint32 {
+	respType, d := c.request(typ, data)
+	if respType != sshFxpStatus {
+		c.t.Fatalf("Expected status, got response type %d", respType)
+	}
+	return d.uint32()
+}
+
+// handle - sends a request, returns the handle of the response.
+func (c *sftpTestClient) handle(typ byte, data []byte) string {
+	respType, d := c.request(typ, data)
+	if respType != sshFxpHandle {
+		c.t.Fatalf("Expected handle, got response type %d, status %d", respType, d.uint32())
+	}
+	return d.string()
+}
+
+func dialSFTPTestServer(t *testing.T, addr, user, password string) (*ssh.Client, error) {
+	return ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.Password(password)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+}
+
+func TestSFTPServer(t *testing.T) {
+	_, _, cleanup := prepareGatewayTest(t, 0)
+	defer cleanup()
+
+	hostKey, err := loadSSHHostKey(filepath.Join(getConfigDir(), sshHostKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go newSFTPServer(hostKey).Serve(l)
+
+	cred := globalServerConfig.GetCredential()
+	if _, err = dialSFTPTestServer(t, l.Addr().String(), cred.AccessKey, "invalid-secret-key"); err == nil {
+		t.Fatal("Expected login with invalid secret key to fail")
+	}
+	client, err := dialSFTPTestServer(t, l.Addr().String(), cred.AccessKey, cred.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = session.RequestSubsystem("sftp"); err != nil {
+		t.Fatal(err)
+	}
+	// A channel serves a single subsystem.
+	if err = session.RequestSubsystem("sftp"); err == nil {
+		t.Fatal("Expected a second subsystem request to be rejected")
+	}
+
+	c := &sftpTestClient{t: t, h: &sftpHandler{rw: struct {
+		io.Reader
+		io.Writer
+	}{stdout, stdin}}}
+	if err = c.h.writePacket(sshFxpInit, appendUint32(nil, sftpProtocolVersion)); err != nil {
+		t.Fatal(err)
+	}
+	if typ, data, rerr := c.h.readPacket(); rerr != nil || typ != sshFxpVersion || (&sftpDecoder{data: data}).uint32() != sftpProtocolVersion {
+		t.Fatalf("Unexpected version response %d, %v", typ, rerr)
+	}
+
+	if code := c.status(sshFxpMkdir, appendUint32(appendString(nil, "/bucket"), 0)); code != sshFxOk {
+		t.Fatalf("Expected bucket to be created, got status %d", code)
+	}
+
+	// Upload a file in two writes.
+	handle := c.handle(sshFxpOpen, appendUint32(appendUint32(appendString(nil, "/bucket/dir/file"), sshFxfWrite|0x08|0x10), 0))
+	for i, data := range []string{"hello ", "world"} {
+		if code := c.status(sshFxpWrite, appendString(appendUint64(appendString(nil, handle), uint64(i*6)), data)); code != sshFxOk {
+			t.Fatalf("Expected write to succeed, got status %d", code)
+		}
+	}
+	if code := c.status(sshFxpWrite, appendString(appendUint64(appendString(nil, handle), 0), "x")); code != sshFxOpUnsupported {
+		t.Errorf("Expected non-sequential write to fail, got status %d", code)
+	}
+	if code := c.status(sshFxpClose, appendString(nil, handle)); code != sshFxOk {
+		t.Fatalf("Expected upload to succeed, got status %d", code)
+	}
+
+	respType, d := c.request(sshFxpStat, appendString(nil, "/bucket/dir/file"))
+	if respType != sshFxpAttrs || d.uint32()&sshFileXferAttrSize == 0 || d.uint64() != 11 {
+		t.Errorf("Unexpected attributes of the file")
+	}
+	if code := c.status(sshFxpStat, appendString(nil, "/bucket/missing")); code != sshFxNoSuchFile {
+		t.Errorf("Expected missing file, got status %d", code)
+	}
+
+	respType, d = c.request(sshFxpRealpath, appendString(nil, "bucket/dir/.."))
+	if respType != sshFxpName || d.uint32() != 1 || d.string() != "/bucket" {
+		t.Errorf("Unexpected real path")
+	}
+
+	// List the directory.
+	handle = c.handle(sshFxpOpendir, appendString(nil, "/bucket"))
+	respType, d = c.request(sshFxpReaddir, appendString(nil, handle))
+	if respType != sshFxpName || d.uint32() != 1 || d.string() != "dir" {
+		t.Errorf("Expected directory entry `dir`")
+	}
+	if code := c.status(sshFxpReaddir, appendString(nil, handle)); code != sshFxEOF {
+		t.Errorf("Expected end of directory, got status %d", code)
+	}
+	c.status(sshFxpClose, appendString(nil, handle))
+
+	// Read the file from an offset and from its start.
+	handle = c.handle(sshFxpOpen, appendUint32(appendUint32(appendString(nil, "/bucket/dir/file"), 0x01), 0))
+	for _, testCase := range []struct {
+		offset   uint64
+		expected string
+	}{{6, "world"}, {0, "hello world"}} {
+		respType, d = c.request(sshFxpRead, appendUint32(appendUint64(appendString(nil, handle), testCase.offset), 1024))
+		if data := d.string(); respType != sshFxpData || data != testCase.expected {
+			t.Errorf("Expected `%s`, got `%s`", testCase.expected, data)
+		}
+	}
+	if code := c.status(sshFxpRead, appendUint32(appendUint64(appendString(nil, handle), 11), 1024)); code != sshFxEOF {
+		t.Errorf("Expected end of file, got status %d", code)
+	}
+	c.status(sshFxpClose, appendString(nil, handle))
+
+	if code := c.status(sshFxpRename, appendString(appendString(nil, "/bucket/dir/file"), "/bucket/renamed")); code != sshFxOk {
+		t.Fatalf("Expected rename to succeed, got status %d", code)
+	}
+	if code := c.status(sshFxpRemove, appendString(nil, "/bucket/renamed")); code != sshFxOk {
+		t.Fatalf("Expected remove to succeed, got status %d", code)
+	}
+	if code := c.status(sshFxpRmdir, appendString(nil, "/bucket")); code != sshFxOk {
+		t.Fatalf("Expected bucket to be removed, got status %d", code)
+	}
+	// Symbolic links are not supported.
+	if code := c.status(20, appendString(appendString(nil, "/a"), "/b")); code != sshFxOpUnsupported {
+		t.Errorf("Expected symlinks not to be supported, got status %d", code)
+	}
+}
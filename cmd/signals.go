@@ -42,6 +42,10 @@ func handleSignals() {
 		errorIf(err, "Unable to shutdown http server")
 
 		if objAPI := newObjectLayerFn(); objAPI != nil {
+			// Deliver buffered access logs before shutting down.
+			if globalBucketAccessLogger != nil {
+				globalBucketAccessLogger.Flush(objAPI)
+			}
 			oerr = objAPI.Shutdown()
 			errorIf(oerr, "Unable to shutdown object layer")
 		}
@@ -202,9 +202,6 @@ func doesPolicySignatureV4Match(formValues http.Header) APIErrorCode {
 //     - http://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
 // returns ErrNone if the signature matches.
 func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, region string) APIErrorCode {
-	// Access credentials.
-	cred := globalServerConfig.GetCredential()
-
 	// Copy request
 	req := *r
 
@@ -214,9 +211,10 @@ func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, region s
 		return err
 	}
 
-	// Verify if the access key id matches.
-	if pSignValues.Credential.accessKey != cred.AccessKey {
-		return ErrInvalidAccessKeyID
+	// Access credentials, verifies if the access key id matches.
+	cred, err := getRequestCredential(r, pSignValues.Credential.accessKey)
+	if err != ErrNone {
+		return err
 	}
 
 	// Verify if region is valid.
@@ -262,6 +260,11 @@ func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, region s
 	query.Set("X-Amz-SignedHeaders", getSignedHeaders(extractedSignedHeaders))
 	query.Set("X-Amz-Credential", cred.AccessKey+"/"+getScope(t, sRegion))
 
+	// The session token of temporary credentials is signed as well.
+	if sessionToken := req.URL.Query().Get(amzSecurityToken); sessionToken != "" {
+		query.Set(amzSecurityToken, sessionToken)
+	}
+
 	// Save other headers available in the request parameters.
 	for k, v := range req.URL.Query() {
 		if strings.HasPrefix(strings.ToLower(k), "x-amz") {
@@ -321,9 +324,6 @@ func doesPresignedSignatureMatch(hashedPayload string, r *http.Request, region s
 //     - http://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
 // returns ErrNone if signature matches.
 func doesSignatureMatch(hashedPayload string, r *http.Request, region string) APIErrorCode {
-	// Access credentials.
-	cred := globalServerConfig.GetCredential()
-
 	// Copy request.
 	req := *r
 
@@ -342,9 +342,10 @@ func doesSignatureMatch(hashedPayload string, r *http.Request, region string) AP
 		return errCode
 	}
 
-	// Verify if the access key id matches.
-	if signV4Values.Credential.accessKey != cred.AccessKey {
-		return ErrInvalidAccessKeyID
+	// Access credentials, verifies if the access key id matches.
+	cred, errCode := getRequestCredential(r, signV4Values.Credential.accessKey)
+	if errCode != ErrNone {
+		return errCode
 	}
 
 	// Verify if region is valid.
@@ -204,14 +204,16 @@ func objectQuorumFromMeta(xl xlObjects, partsMetaData []xlMetaV1, errs []error)
 
 	// latestXLMeta is updated most recently.
 	// We implicitly assume that all the xlMeta(s) have same dataBlocks and parityBlocks.
-	// We now check that at least dataBlocks number of xlMeta is available. This means count
-	// should be greater than or equal to dataBlocks field of latestXLMeta. If not we throw read quorum error.
-	if count < latestXLMeta.Erasure.DataBlocks {
+	// We now check that at least read quorum number of xlMeta is available, the read quorum
+	// is dataBlocks for Reed-Solomon codes and includes the local parity blocks for LRC.
+	// If not we throw read quorum error.
+	readQuorum := latestXLMeta.Erasure.ReadQuorum()
+	if count < readQuorum {
 		// This is the case when we can't reliably deduce object quorum
 		return 0, 0, errXLReadQuorum
 	}
 
-	// Since all the valid erasure code meta updated at the same time are equivalent, pass dataBlocks
-	// from latestXLMeta to get the quorum
-	return latestXLMeta.Erasure.DataBlocks, latestXLMeta.Erasure.DataBlocks + 1, nil
+	// Since all the valid erasure code meta updated at the same time are equivalent, pass
+	// the erasure code parameters from latestXLMeta to get the quorum
+	return readQuorum, latestXLMeta.Erasure.WriteQuorum(), nil
 }
@@ -43,6 +43,8 @@ type StorageAPI interface {
 	ReadFile(volume string, path string, offset int64, buf []byte, verifier *BitrotVerifier) (n int64, err error)
 	PrepareFile(volume string, path string, len int64) (err error)
 	AppendFile(volume string, path string, buf []byte) (err error)
+	CreateFile(volume, path string, size int64, reader io.Reader) (err error)
+	ReadFileStream(volume, path string, offset, length int64, verifier *BitrotVerifier) (io.ReadCloser, error)
 	RenameFile(srcVolume, srcPath, dstVolume, dstPath string) error
 	StatFile(volume string, path string) (file FileInfo, err error)
 	DeleteFile(volume string, path string) (err error)
@@ -0,0 +1,551 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/gob"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/disk"
+)
+
+// storageRESTClient is a StorageAPI for a disk of a remote node using
+// the REST storage API. File data is streamed in request and response
+// bodies over keep-alive connections. The version of the API is
+// negotiated on first use, servers without support for this version
+// are accessed through the RPC storage API instead.
+type storageRESTClient struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+
+	// RPC storage client to fall back to.
+	rpc *networkStorage
+
+	mu         sync.Mutex
+	negotiated bool // Whether the version was negotiated
+	fallback   bool // Whether the remote node only supports RPC
+}
+
+// Time to wait for the response headers of a REST storage API call
+// after the request was sent, a hung disk must not block forever.
+const storageRESTResponseHeaderTimeout = 2 * time.Minute
+
+// newStorageRESTHTTPClient - returns a client keeping enough idle
+// connections to stream all shards of a node concurrently. Nodes talk
+// to each other directly, proxies of the environment are ignored.
+func newStorageRESTHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+			// New connections use the root CAs and node certificate
+			// in use at the time, they are reloaded on change.
+			DialTLS: func(network, addr string) (net.Conn, error) {
+				host, _, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, err
+				}
+				return tls.DialWithDialer(dialer, network, addr, newNodeTLSConfig(host))
+			},
+			MaxIdleConns:          1024,
+			MaxIdleConnsPerHost:   1024,
+			IdleConnTimeout:       90 * time.Second,
+			ResponseHeaderTimeout: storageRESTResponseHeaderTimeout,
+			ExpectContinueTimeout: 1 * time.Second,
+			DisableCompression:    true,
+		},
+	}
+}
+
+// Initialize new storage REST client.
+func newStorageRESTClient(endpoint Endpoint) StorageAPI {
+	scheme := "http"
+	if globalIsSSL {
+		scheme = "https"
+	}
+	return &storageRESTClient{
+		baseURL: &url.URL{
+			Scheme: scheme,
+			Host:   endpoint.Host,
+			Path:   path.Join(minioReservedBucketPath, storageRESTPath, endpoint.Path),
+		},
+		httpClient: newStorageRESTHTTPClient(),
+		rpc:        newStorageRPC(endpoint).(*networkStorage),
+	}
+}
+
+// storageRESTBody - body of a REST storage API response, reports an
+// error in the trailer at the end of the body.
+type storageRESTBody struct {
+	resp *http.Response
+}
+
+func (b *storageRESTBody) Read(p []byte) (int, error) {
+	n, err := b.resp.Body.Read(p)
+	switch err {
+	case nil:
+	case io.EOF:
+		if code := b.resp.Trailer.Get(storageRESTError); code != "" {
+			err = storageRESTErrorFromCode(code, "")
+		}
+	default:
+		err = errDiskNotFoundFromNetError
+	}
+	return n, err
+}
+
+func (b *storageRESTBody) Close() error {
+	return b.resp.Body.Close()
+}
+
+// call - sends a request for method and returns the response body,
+// which must be closed by the caller. Non 200 responses are converted
+// to storage errors.
+func (client *storageRESTClient) call(method string, values url.Values, body io.Reader, length int64) (io.ReadCloser, error) {
+	cred := globalServerConfig.GetCredential()
+	token, err := getNodeAuthToken(cred.AccessKey, cred.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	u := *client.baseURL
+	u.Path += method
+	u.RawQuery = values.Encode()
+	req, err := http.NewRequest(http.MethodPost, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if length >= 0 {
+		req.ContentLength = length
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		if _, ok := err.(*url.Error); ok {
+			return nil, errDiskNotFoundFromNetError
+		}
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		if err != nil {
+			return nil, errDiskNotFoundFromNetError
+		}
+		return nil, storageRESTErrorFromCode(resp.Header.Get(storageRESTError), string(b))
+	}
+	return &storageRESTBody{resp}, nil
+}
+
+// callDiscard - sends a request for method discarding the response.
+func (client *storageRESTClient) callDiscard(method string, values url.Values, body io.Reader, length int64) error {
+	respBody, err := client.call(method, values, body, length)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+	// Drain the body to reuse the connection.
+	if _, err = io.Copy(ioutil.Discard, respBody); err != nil {
+		return errDiskNotFoundFromNetError
+	}
+	return nil
+}
+
+// callGob - sends a request for method and gob decodes the response
+// into reply.
+func (client *storageRESTClient) callGob(method string, values url.Values, reply interface{}) error {
+	respBody, err := client.call(method, values, nil, -1)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+	if err = gob.NewDecoder(respBody).Decode(reply); err != nil {
+		return errDiskNotFoundFromNetError
+	}
+	return nil
+}
+
+// useRPC - negotiates the version of the REST storage API once and
+// returns true if the remote node has to be accessed through RPC.
+func (client *storageRESTClient) useRPC() (bool, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.negotiated {
+		return client.fallback, nil
+	}
+
+	respBody, err := client.call(storageRESTMethodVersion, nil, nil, -1)
+	switch err {
+	case nil:
+		defer respBody.Close()
+		var version []byte
+		if version, err = ioutil.ReadAll(respBody); err != nil {
+			return false, errDiskNotFoundFromNetError
+		}
+		client.fallback = string(version) != storageRESTVersion
+	case errDiskNotFoundFromNetError:
+		// Negotiate again once the node is reachable.
+		return false, err
+	case errAuthentication, errInvalidAccessKeyID, errDiskNotFound:
+		return false, err
+	default:
+		// Servers without this version of the REST storage API
+		// reject the request.
+		client.fallback = true
+	}
+	if client.fallback {
+		errorIf(errRPCAPIVersionUnsupported, "%s does not support REST storage API %s, falling back to RPC", client, storageRESTVersion)
+	}
+	client.negotiated = true
+	return client.fallback, nil
+}
+
+// Stringer provides a canonicalized representation of network device.
+func (client *storageRESTClient) String() string {
+	return client.rpc.String()
+}
+
+// Init - negotiates the version of the REST storage API.
+func (client *storageRESTClient) Init() error {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return err
+	}
+	if fallback {
+		return client.rpc.Init()
+	}
+	return nil
+}
+
+// Close - closes idle connections.
+func (client *storageRESTClient) Close() error {
+	client.mu.Lock()
+	fallback := client.fallback
+	client.mu.Unlock()
+	if fallback {
+		return client.rpc.Close()
+	}
+	if transport, ok := client.httpClient.Transport.(*http.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+	return nil
+}
+
+// DiskInfo - fetch disk information for a remote disk.
+func (client *storageRESTClient) DiskInfo() (info disk.Info, err error) {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return info, err
+	}
+	if fallback {
+		return client.rpc.DiskInfo()
+	}
+	err = client.callGob(storageRESTMethodDiskInfo, nil, &info)
+	return info, err
+}
+
+// MakeVol - create a volume on a remote disk.
+func (client *storageRESTClient) MakeVol(volume string) error {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return err
+	}
+	if fallback {
+		return client.rpc.MakeVol(volume)
+	}
+	values := url.Values{storageRESTVolume: {volume}}
+	return client.callDiscard(storageRESTMethodMakeVol, values, nil, -1)
+}
+
+// ListVols - list all volumes on a remote disk.
+func (client *storageRESTClient) ListVols() (vols []VolInfo, err error) {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return nil, err
+	}
+	if fallback {
+		return client.rpc.ListVols()
+	}
+	err = client.callGob(storageRESTMethodListVols, nil, &vols)
+	return vols, err
+}
+
+// StatVol - get volume info over the network.
+func (client *storageRESTClient) StatVol(volume string) (vol VolInfo, err error) {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return vol, err
+	}
+	if fallback {
+		return client.rpc.StatVol(volume)
+	}
+	values := url.Values{storageRESTVolume: {volume}}
+	err = client.callGob(storageRESTMethodStatVol, values, &vol)
+	return vol, err
+}
+
+// DeleteVol - deletes a volume over the network.
+func (client *storageRESTClient) DeleteVol(volume string) error {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return err
+	}
+	if fallback {
+		return client.rpc.DeleteVol(volume)
+	}
+	values := url.Values{storageRESTVolume: {volume}}
+	return client.callDiscard(storageRESTMethodDeleteVol, values, nil, -1)
+}
+
+// PrepareFile - to fallocate() disk space for a file.
+func (client *storageRESTClient) PrepareFile(volume, path string, length int64) error {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return err
+	}
+	if fallback {
+		return client.rpc.PrepareFile(volume, path, length)
+	}
+	values := url.Values{
+		storageRESTVolume:   {volume},
+		storageRESTFilePath: {path},
+		storageRESTLength:   {strconv.FormatInt(length, 10)},
+	}
+	return client.callDiscard(storageRESTMethodPrepareFile, values, nil, -1)
+}
+
+// AppendFile - append file writes buffer to a remote network path.
+func (client *storageRESTClient) AppendFile(volume, path string, buffer []byte) error {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return err
+	}
+	if fallback {
+		return client.rpc.AppendFile(volume, path, buffer)
+	}
+	values := url.Values{
+		storageRESTVolume:   {volume},
+		storageRESTFilePath: {path},
+	}
+	return client.callDiscard(storageRESTMethodAppendFile, values, bytes.NewReader(buffer), int64(len(buffer)))
+}
+
+// CreateFile - streams all data read from reader to a remote file in
+// a single request.
+func (client *storageRESTClient) CreateFile(volume, path string, size int64, reader io.Reader) error {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return err
+	}
+	if fallback {
+		return client.rpc.CreateFile(volume, path, size, reader)
+	}
+	values := url.Values{
+		storageRESTVolume:   {volume},
+		storageRESTFilePath: {path},
+	}
+	if size >= 0 {
+		values.Set(storageRESTLength, strconv.FormatInt(size, 10))
+	}
+	// Hide a possible io.Closer of reader from the HTTP client, the
+	// caller owns the reader.
+	return client.callDiscard(storageRESTMethodCreateFile, values, ioutil.NopCloser(reader), size)
+}
+
+// storageRESTVerifiedStream - marks verifier as verified once the
+// remote node reached the end of a stream without a bitrot mismatch.
+type storageRESTVerifiedStream struct {
+	io.ReadCloser
+	verifier *BitrotVerifier
+}
+
+func (s *storageRESTVerifiedStream) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	switch err.(type) {
+	case nil:
+	case hashMismatchError:
+		err = hashMismatchError{expected: hex.EncodeToString(s.verifier.sum)}
+	default:
+		if err == io.EOF {
+			s.verifier.verified = true
+		}
+	}
+	return n, err
+}
+
+// ReadFileStream - returns a stream of a remote file. The file is
+// verified against bitrot by the remote node while streaming.
+func (client *storageRESTClient) ReadFileStream(volume, path string, offset, length int64, verifier *BitrotVerifier) (io.ReadCloser, error) {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return nil, err
+	}
+	if fallback {
+		return client.rpc.ReadFileStream(volume, path, offset, length, verifier)
+	}
+	values := url.Values{
+		storageRESTVolume:   {volume},
+		storageRESTFilePath: {path},
+		storageRESTOffset:   {strconv.FormatInt(offset, 10)},
+	}
+	if length >= 0 {
+		values.Set(storageRESTLength, strconv.FormatInt(length, 10))
+	}
+	verify := verifier != nil && !verifier.IsVerified()
+	if verify {
+		values.Set(storageRESTBitrotAlgo, verifier.algorithm.String())
+		values.Set(storageRESTBitrotHash, hex.EncodeToString(verifier.sum))
+	}
+	respBody, err := client.call(storageRESTMethodReadFileStream, values, nil, -1)
+	if err != nil || !verify {
+		return respBody, err
+	}
+	return &storageRESTVerifiedStream{respBody, verifier}, nil
+}
+
+// StatFile - get latest Stat information for a file at path.
+func (client *storageRESTClient) StatFile(volume, path string) (info FileInfo, err error) {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return info, err
+	}
+	if fallback {
+		return client.rpc.StatFile(volume, path)
+	}
+	values := url.Values{
+		storageRESTVolume:   {volume},
+		storageRESTFilePath: {path},
+	}
+	err = client.callGob(storageRESTMethodStatFile, values, &info)
+	return info, err
+}
+
+// ReadAll - reads entire contents of the file at path until EOF.
+func (client *storageRESTClient) ReadAll(volume, path string) ([]byte, error) {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return nil, err
+	}
+	if fallback {
+		return client.rpc.ReadAll(volume, path)
+	}
+	values := url.Values{
+		storageRESTVolume:   {volume},
+		storageRESTFilePath: {path},
+	}
+	respBody, err := client.call(storageRESTMethodReadAll, values, nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+	buf, err := ioutil.ReadAll(respBody)
+	if err != nil {
+		return nil, errDiskNotFoundFromNetError
+	}
+	return buf, nil
+}
+
+// ReadFile - reads a file at remote path and fills the buffer. If
+// verifier was not verified yet the remote node verifies the whole
+// file against bitrot while streaming the buffer.
+func (client *storageRESTClient) ReadFile(volume, path string, offset int64, buffer []byte, verifier *BitrotVerifier) (int64, error) {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return 0, err
+	}
+	if fallback {
+		return client.rpc.ReadFile(volume, path, offset, buffer, verifier)
+	}
+
+	respBody, err := client.ReadFileStream(volume, path, offset, int64(len(buffer)), verifier)
+	if err != nil {
+		return 0, err
+	}
+	defer respBody.Close()
+	n, err := io.ReadFull(respBody, buffer)
+	if err == nil && verifier != nil && !verifier.IsVerified() {
+		// The result of the verification comes at the end of the stream.
+		_, err = io.Copy(ioutil.Discard, respBody)
+	}
+	return int64(n), err
+}
+
+// ListDir - list all entries at prefix.
+func (client *storageRESTClient) ListDir(volume, path string) (entries []string, err error) {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return nil, err
+	}
+	if fallback {
+		return client.rpc.ListDir(volume, path)
+	}
+	values := url.Values{
+		storageRESTVolume:  {volume},
+		storageRESTDirPath: {path},
+	}
+	err = client.callGob(storageRESTMethodListDir, values, &entries)
+	return entries, err
+}
+
+// DeleteFile - Delete a file at path.
+func (client *storageRESTClient) DeleteFile(volume, path string) error {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return err
+	}
+	if fallback {
+		return client.rpc.DeleteFile(volume, path)
+	}
+	values := url.Values{
+		storageRESTVolume:   {volume},
+		storageRESTFilePath: {path},
+	}
+	return client.callDiscard(storageRESTMethodDeleteFile, values, nil, -1)
+}
+
+// RenameFile - rename a remote file from source to destination.
+func (client *storageRESTClient) RenameFile(srcVolume, srcPath, dstVolume, dstPath string) error {
+	fallback, err := client.useRPC()
+	if err != nil {
+		return err
+	}
+	if fallback {
+		return client.rpc.RenameFile(srcVolume, srcPath, dstVolume, dstPath)
+	}
+	values := url.Values{
+		storageRESTSrcVolume: {srcVolume},
+		storageRESTSrcPath:   {srcPath},
+		storageRESTDstVolume: {dstVolume},
+		storageRESTDstPath:   {dstPath},
+	}
+	return client.callDiscard(storageRESTMethodRenameFile, values, nil, -1)
+}
@@ -0,0 +1,130 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Version of the REST storage API, bump it on incompatible changes.
+// Clients fall back to the RPC storage API if a server does not
+// support this version.
+const storageRESTVersion = "v1"
+
+// Each disk exported by a server is served below
+// `/minio/storage/v1/<disk path>/<method>`.
+const storageRESTPath = "/storage/" + storageRESTVersion
+
+// Methods of the REST storage API.
+const (
+	storageRESTMethodVersion        = "/version"
+	storageRESTMethodDiskInfo       = "/diskinfo"
+	storageRESTMethodMakeVol        = "/makevol"
+	storageRESTMethodStatVol        = "/statvol"
+	storageRESTMethodDeleteVol      = "/deletevol"
+	storageRESTMethodListVols       = "/listvols"
+	storageRESTMethodPrepareFile    = "/preparefile"
+	storageRESTMethodAppendFile     = "/appendfile"
+	storageRESTMethodCreateFile     = "/createfile"
+	storageRESTMethodReadFileStream = "/readfilestream"
+	storageRESTMethodReadAll        = "/readall"
+	storageRESTMethodStatFile       = "/statfile"
+	storageRESTMethodListDir        = "/listdir"
+	storageRESTMethodDeleteFile     = "/deletefile"
+	storageRESTMethodRenameFile     = "/renamefile"
+)
+
+// Query parameters of the REST storage API.
+const (
+	storageRESTVolume     = "volume"
+	storageRESTDirPath    = "dir-path"
+	storageRESTFilePath   = "file-path"
+	storageRESTSrcVolume  = "source-volume"
+	storageRESTSrcPath    = "source-path"
+	storageRESTDstVolume  = "destination-volume"
+	storageRESTDstPath    = "destination-path"
+	storageRESTOffset     = "offset"
+	storageRESTLength     = "length"
+	storageRESTBitrotAlgo = "bitrot-algorithm"
+	storageRESTBitrotHash = "bitrot-hash"
+)
+
+// Errors are returned with a non 200 status, the code of the error in
+// this header and the error message in the body. A failure after the
+// first byte of a stream was sent is reported in the trailer with the
+// same name.
+const storageRESTError = "X-Minio-Storage-Error"
+
+// Error code of hashMismatchError, which is not a single value.
+const storageRESTErrorHashMismatch = "HashMismatch"
+
+// storageRESTErrors - errors of the REST storage API by code, with the
+// status they are returned with.
+var storageRESTErrors = map[string]struct {
+	err    error
+	status int
+}{
+	"EOF":                   {io.EOF, http.StatusRequestedRangeNotSatisfiable},
+	"UnexpectedEOF":         {io.ErrUnexpectedEOF, http.StatusRequestedRangeNotSatisfiable},
+	"Unexpected":            {errUnexpected, http.StatusInternalServerError},
+	"Authentication":        {errAuthentication, http.StatusUnauthorized},
+	"InvalidArgument":       {errInvalidArgument, http.StatusBadRequest},
+	"CorruptedFormat":       {errCorruptedFormat, http.StatusInternalServerError},
+	"UnformattedDisk":       {errUnformattedDisk, http.StatusInternalServerError},
+	"DiskFull":              {errDiskFull, http.StatusInsufficientStorage},
+	"DiskNotFound":          {errDiskNotFound, http.StatusServiceUnavailable},
+	"FaultyDisk":            {errFaultyDisk, http.StatusInternalServerError},
+	"DiskAccessDenied":      {errDiskAccessDenied, http.StatusForbidden},
+	"FileNotFound":          {errFileNotFound, http.StatusNotFound},
+	"FileNameTooLong":       {errFileNameTooLong, http.StatusBadRequest},
+	"FileAccessDenied":      {errFileAccessDenied, http.StatusForbidden},
+	"IsNotRegular":          {errIsNotRegular, http.StatusConflict},
+	"VolumeExists":          {errVolumeExists, http.StatusConflict},
+	"VolumeNotFound":        {errVolumeNotFound, http.StatusNotFound},
+	"VolumeNotEmpty":        {errVolumeNotEmpty, http.StatusConflict},
+	"VolumeAccessDenied":    {errVolumeAccessDenied, http.StatusForbidden},
+	"BitrotHashAlgoInvalid": {errBitrotHashAlgoInvalid, http.StatusBadRequest},
+	"CrossDeviceLink":       {errCrossDeviceLink, http.StatusInternalServerError},
+}
+
+// storageRESTErrorCode - returns the code and status of err, an empty
+// code if err has none.
+func storageRESTErrorCode(err error) (code string, status int) {
+	if _, ok := err.(hashMismatchError); ok {
+		return storageRESTErrorHashMismatch, http.StatusInternalServerError
+	}
+	for code, e := range storageRESTErrors {
+		if e.err == err {
+			return code, e.status
+		}
+	}
+	return "", http.StatusInternalServerError
+}
+
+// storageRESTErrorFromCode - returns the error of code, message is the
+// error of unknown codes.
+func storageRESTErrorFromCode(code, message string) error {
+	if code == storageRESTErrorHashMismatch {
+		return hashMismatchError{}
+	}
+	if e, ok := storageRESTErrors[code]; ok {
+		return e.err
+	}
+	return toStorageErr(errors.New(message))
+}
@@ -0,0 +1,344 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/gob"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+
+	router "github.com/gorilla/mux"
+)
+
+// storageRESTServer exports a local disk over HTTP. Bodies of
+// requests and responses carry the file data, all other arguments
+// are query parameters and structured replies are gob encoded.
+// Errors are returned with their code in the storageRESTError header.
+type storageRESTServer struct {
+	storage StorageAPI
+}
+
+func (s *storageRESTServer) writeErrorResponse(w http.ResponseWriter, err error) {
+	code, status := storageRESTErrorCode(err)
+	if code != "" {
+		w.Header().Set(storageRESTError, code)
+	}
+	w.WriteHeader(status)
+	w.Write([]byte(err.Error()))
+}
+
+// IsValid - authenticates the request, requests carry a JWT generated
+// with the server credentials unless the calling node is authenticated
+// by its certificate.
+func (s *storageRESTServer) IsValid(w http.ResponseWriter, r *http.Request) bool {
+	if !isNodeRequest(r) && webRequestAuthenticate(r) != nil {
+		s.writeErrorResponse(w, errAuthentication)
+		return false
+	}
+	if s.storage == nil {
+		s.writeErrorResponse(w, errDiskNotFound)
+		return false
+	}
+	return true
+}
+
+// writeGobResponse - gob encodes reply into the response body.
+func (s *storageRESTServer) writeGobResponse(w http.ResponseWriter, reply interface{}) {
+	gob.NewEncoder(w).Encode(reply)
+}
+
+// parseStorageRESTLength - parses a length query parameter, an empty
+// value is -1.
+func parseStorageRESTLength(value string) (int64, error) {
+	if value == "" {
+		return -1, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// VersionHandler - returns the version of the REST storage API.
+func (s *storageRESTServer) VersionHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	w.Write([]byte(storageRESTVersion))
+}
+
+// DiskInfoHandler - returns disk info.
+func (s *storageRESTServer) DiskInfoHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	info, err := s.storage.DiskInfo()
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+	s.writeGobResponse(w, info)
+}
+
+// MakeVolHandler - creates a volume.
+func (s *storageRESTServer) MakeVolHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	if err := s.storage.MakeVol(r.URL.Query().Get(storageRESTVolume)); err != nil {
+		s.writeErrorResponse(w, err)
+	}
+}
+
+// ListVolsHandler - lists all volumes.
+func (s *storageRESTServer) ListVolsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	vols, err := s.storage.ListVols()
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+	s.writeGobResponse(w, vols)
+}
+
+// StatVolHandler - stats a volume.
+func (s *storageRESTServer) StatVolHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	info, err := s.storage.StatVol(r.URL.Query().Get(storageRESTVolume))
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+	s.writeGobResponse(w, info)
+}
+
+// DeleteVolHandler - deletes a volume.
+func (s *storageRESTServer) DeleteVolHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	if err := s.storage.DeleteVol(r.URL.Query().Get(storageRESTVolume)); err != nil {
+		s.writeErrorResponse(w, err)
+	}
+}
+
+// PrepareFileHandler - fallocates space for a file.
+func (s *storageRESTServer) PrepareFileHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	length, err := strconv.ParseInt(query.Get(storageRESTLength), 10, 64)
+	if err != nil {
+		s.writeErrorResponse(w, errInvalidArgument)
+		return
+	}
+	if err = s.storage.PrepareFile(query.Get(storageRESTVolume), query.Get(storageRESTFilePath), length); err != nil {
+		s.writeErrorResponse(w, err)
+	}
+}
+
+// AppendFileHandler - appends the request body to a file.
+func (s *storageRESTServer) AppendFileHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	buf, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+	if err = s.storage.AppendFile(query.Get(storageRESTVolume), query.Get(storageRESTFilePath), buf); err != nil {
+		s.writeErrorResponse(w, err)
+	}
+}
+
+// CreateFileHandler - streams the request body into a file.
+func (s *storageRESTServer) CreateFileHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	size, err := parseStorageRESTLength(query.Get(storageRESTLength))
+	if err != nil {
+		s.writeErrorResponse(w, errInvalidArgument)
+		return
+	}
+	if err = s.storage.CreateFile(query.Get(storageRESTVolume), query.Get(storageRESTFilePath), size, r.Body); err != nil {
+		s.writeErrorResponse(w, err)
+	}
+}
+
+// ReadFileStreamHandler - streams a byte range of a file in the
+// response body. If a bitrot hash is given the whole file is verified
+// while streaming.
+func (s *storageRESTServer) ReadFileStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	offset, err := strconv.ParseInt(query.Get(storageRESTOffset), 10, 64)
+	if err != nil {
+		s.writeErrorResponse(w, errInvalidArgument)
+		return
+	}
+	length, err := parseStorageRESTLength(query.Get(storageRESTLength))
+	if err != nil {
+		s.writeErrorResponse(w, errInvalidArgument)
+		return
+	}
+	var verifier *BitrotVerifier
+	if algo := query.Get(storageRESTBitrotAlgo); algo != "" {
+		algorithm := BitrotAlgorithmFromString(algo)
+		if !algorithm.Available() {
+			s.writeErrorResponse(w, errBitrotHashAlgoInvalid)
+			return
+		}
+		sum, err := hex.DecodeString(query.Get(storageRESTBitrotHash))
+		if err != nil {
+			s.writeErrorResponse(w, errInvalidArgument)
+			return
+		}
+		verifier = NewBitrotVerifier(algorithm, sum)
+	}
+	rc, err := s.storage.ReadFileStream(query.Get(storageRESTVolume), query.Get(storageRESTFilePath), offset, length, verifier)
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+	defer rc.Close()
+
+	// Send the headers right away, hashing the bytes before the range
+	// may take a while.
+	w.Header().Set("Trailer", storageRESTError)
+	w.WriteHeader(http.StatusOK)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	if _, err = io.Copy(w, rc); err != nil {
+		code, _ := storageRESTErrorCode(err)
+		if code == "" {
+			code = "Unexpected"
+		}
+		w.Header().Set(storageRESTError, code)
+	}
+}
+
+// ReadAllHandler - returns the whole content of a file.
+func (s *storageRESTServer) ReadAllHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	buf, err := s.storage.ReadAll(query.Get(storageRESTVolume), query.Get(storageRESTFilePath))
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(buf)))
+	w.Write(buf)
+}
+
+// StatFileHandler - stats a file.
+func (s *storageRESTServer) StatFileHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	info, err := s.storage.StatFile(query.Get(storageRESTVolume), query.Get(storageRESTFilePath))
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+	s.writeGobResponse(w, info)
+}
+
+// ListDirHandler - lists the entries of a directory.
+func (s *storageRESTServer) ListDirHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	entries, err := s.storage.ListDir(query.Get(storageRESTVolume), query.Get(storageRESTDirPath))
+	if err != nil {
+		s.writeErrorResponse(w, err)
+		return
+	}
+	s.writeGobResponse(w, entries)
+}
+
+// DeleteFileHandler - deletes a file.
+func (s *storageRESTServer) DeleteFileHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	if err := s.storage.DeleteFile(query.Get(storageRESTVolume), query.Get(storageRESTFilePath)); err != nil {
+		s.writeErrorResponse(w, err)
+	}
+}
+
+// RenameFileHandler - renames a file.
+func (s *storageRESTServer) RenameFileHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.IsValid(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	if err := s.storage.RenameFile(query.Get(storageRESTSrcVolume), query.Get(storageRESTSrcPath),
+		query.Get(storageRESTDstVolume), query.Get(storageRESTDstPath)); err != nil {
+		s.writeErrorResponse(w, err)
+	}
+}
+
+// registerStorageRESTHandlers - register storage REST handlers for
+// every disk hosted on this node.
+func registerStorageRESTHandlers(mux *router.Router, endpoints EndpointList) error {
+	for _, endpoint := range endpoints {
+		if !endpoint.IsLocal {
+			continue
+		}
+		storage, err := newPosix(endpoint.Path)
+		if err != nil && err != errDiskNotFound {
+			return err
+		}
+		server := &storageRESTServer{storage: storage}
+
+		subrouter := mux.PathPrefix(path.Join(minioReservedBucketPath, storageRESTPath, endpoint.Path)).Subrouter()
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodVersion).HandlerFunc(server.VersionHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodDiskInfo).HandlerFunc(server.DiskInfoHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodMakeVol).HandlerFunc(server.MakeVolHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodStatVol).HandlerFunc(server.StatVolHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodDeleteVol).HandlerFunc(server.DeleteVolHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodListVols).HandlerFunc(server.ListVolsHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodPrepareFile).HandlerFunc(server.PrepareFileHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodAppendFile).HandlerFunc(server.AppendFileHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodCreateFile).HandlerFunc(server.CreateFileHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodReadFileStream).HandlerFunc(server.ReadFileStreamHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodReadAll).HandlerFunc(server.ReadAllHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodStatFile).HandlerFunc(server.StatFileHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodListDir).HandlerFunc(server.ListDirHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodDeleteFile).HandlerFunc(server.DeleteFileHandler)
+		subrouter.Methods(http.MethodPost).Path(storageRESTMethodRenameFile).HandlerFunc(server.RenameFileHandler)
+	}
+	return nil
+}
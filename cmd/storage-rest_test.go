@@ -0,0 +1,230 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	router "github.com/gorilla/mux"
+)
+
+// startTestStorageRESTServer - starts a server exporting a single disk
+// over the RPC storage API and, if withREST is set, the REST storage
+// API. Returns a REST storage client and the path of the disk.
+func startTestStorageRESTServer(t *testing.T, withREST bool) (*storageRESTClient, string, func()) {
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disks, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoints := mustGetNewEndpointList(disks...)
+
+	mux := router.NewRouter().SkipClean(true)
+	if err = registerStorageRPCRouters(mux, endpoints); err != nil {
+		t.Fatal(err)
+	}
+	if withREST {
+		if err = registerStorageRESTHandlers(mux, endpoints); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := httptest.NewServer(mux)
+
+	endpoint := endpoints[0]
+	endpoint.Scheme = "http"
+	endpoint.Host = server.Listener.Addr().String()
+	endpoint.IsLocal = false
+	client := newStorageRESTClient(endpoint).(*storageRESTClient)
+
+	return client, disks[0], func() {
+		server.Close()
+		removeRoots(append(disks, root))
+	}
+}
+
+func TestStorageRESTClient(t *testing.T) {
+	for _, withREST := range []bool{true, false} {
+		client, _, cleanup := startTestStorageRESTServer(t, withREST)
+
+		if err := client.Init(); err != nil {
+			t.Fatalf("REST %v: Unable to initialize client: %v", withREST, err)
+		}
+		if client.fallback == withREST {
+			t.Errorf("REST %v: Expected fallback to be %v", withREST, !withREST)
+		}
+		if err := client.MakeVol("vol"); err != nil {
+			t.Fatalf("REST %v: Unable to create volume: %v", withREST, err)
+		}
+
+		data := bytes.Repeat([]byte("abcdefgh"), 100000)
+		if err := client.CreateFile("vol", "dir/file", int64(len(data)), bytes.NewReader(data)); err != nil {
+			t.Fatalf("REST %v: Unable to create file: %v", withREST, err)
+		}
+		if err := client.CreateFile("vol", "short", int64(len(data)+1), bytes.NewReader(data)); err == nil {
+			t.Errorf("REST %v: Expected error for short content", withREST)
+		}
+		if err := client.CreateFile("vol", "empty", -1, bytes.NewReader(nil)); err != nil {
+			t.Fatalf("REST %v: Unable to create empty file: %v", withREST, err)
+		}
+		if fi, err := client.StatFile("vol", "empty"); err != nil || fi.Size != 0 {
+			t.Errorf("REST %v: Expected empty file, got %v %v", withREST, fi, err)
+		}
+
+		rc, err := client.ReadFileStream("vol", "dir/file", 100, 1000, nil)
+		if err != nil {
+			t.Fatalf("REST %v: Unable to read stream: %v", withREST, err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil || !bytes.Equal(b, data[100:1100]) {
+			t.Errorf("REST %v: Unexpected stream content, err %v", withREST, err)
+		}
+		if _, err = client.ReadFileStream("vol", "missing", 0, -1, nil); err != errFileNotFound {
+			t.Errorf("REST %v: Expected %v, got %v", withREST, errFileNotFound, err)
+		}
+
+		fi, err := client.StatFile("vol", "dir/file")
+		if err != nil || fi.Size != int64(len(data)) {
+			t.Errorf("REST %v: Unexpected file info %v %v", withREST, fi, err)
+		}
+		if entries, err := client.ListDir("vol", "dir"); err != nil || len(entries) != 1 || entries[0] != "file" {
+			t.Errorf("REST %v: Unexpected entries %v %v", withREST, entries, err)
+		}
+		if err = client.RenameFile("vol", "dir/file", "vol", "file"); err != nil {
+			t.Fatalf("REST %v: Unable to rename file: %v", withREST, err)
+		}
+		if b, err = client.ReadAll("vol", "file"); err != nil || !bytes.Equal(b, data) {
+			t.Errorf("REST %v: Unexpected file content, err %v", withREST, err)
+		}
+		if err = client.DeleteFile("vol", "file"); err != nil {
+			t.Errorf("REST %v: Unable to delete file: %v", withREST, err)
+		}
+		if _, err = client.StatFile("vol", "file"); err != errFileNotFound {
+			t.Errorf("REST %v: Expected %v, got %v", withREST, errFileNotFound, err)
+		}
+
+		cleanup()
+	}
+}
+
+// Tests bitrot verification of the REST storage client.
+func TestStorageRESTClientReadFileVerify(t *testing.T) {
+	client, diskPath, cleanup := startTestStorageRESTServer(t, true)
+	defer cleanup()
+
+	data := bytes.Repeat([]byte("a"), 1024)
+	if err := client.MakeVol("vol"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AppendFile("vol", "file", data); err != nil {
+		t.Fatal(err)
+	}
+	h := DefaultBitrotAlgorithm.New()
+	h.Write(data)
+	sum := h.Sum(nil)
+
+	buf := make([]byte, 10)
+	verifier := NewBitrotVerifier(DefaultBitrotAlgorithm, sum)
+	if _, err := client.ReadFile("vol", "file", 100, buf, verifier); err != nil {
+		t.Fatalf("Unable to read file: %v", err)
+	}
+	if !verifier.IsVerified() || !bytes.Equal(buf, data[100:110]) {
+		t.Error("Expected verified content")
+	}
+
+	// Corrupt the file on disk.
+	if err := ioutil.WriteFile(pathJoin(diskPath, "vol", "file"), bytes.Repeat([]byte("b"), 1024), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	verifier = NewBitrotVerifier(DefaultBitrotAlgorithm, sum)
+	if _, err := client.ReadFile("vol", "file", 100, buf, verifier); err == nil {
+		t.Error("Expected hash mismatch error")
+	} else if _, ok := err.(hashMismatchError); !ok {
+		t.Errorf("Expected hash mismatch error, got %v", err)
+	}
+
+	// A stream reports the mismatch at its end.
+	verifier = NewBitrotVerifier(DefaultBitrotAlgorithm, sum)
+	rc, err := client.ReadFileStream("vol", "file", 100, 10, verifier)
+	if err != nil {
+		t.Fatalf("Unable to read stream: %v", err)
+	}
+	b, err := ioutil.ReadAll(rc)
+	rc.Close()
+	if _, ok := err.(hashMismatchError); !ok {
+		t.Errorf("Expected hash mismatch error, got %v", err)
+	}
+	if !bytes.Equal(b, bytes.Repeat([]byte("b"), 10)) {
+		t.Errorf("Unexpected stream content %q", b)
+	}
+	if verifier.IsVerified() {
+		t.Error("Expected unverified verifier")
+	}
+
+	if _, err := client.ReadFile("vol", "file", 1020, buf, nil); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+// Tests the mapping of storage errors to codes and statuses.
+func TestStorageRESTErrorCode(t *testing.T) {
+	testCases := []struct {
+		err    error
+		status int
+	}{
+		{errFileNotFound, http.StatusNotFound},
+		{errVolumeNotFound, http.StatusNotFound},
+		{errVolumeExists, http.StatusConflict},
+		{errAuthentication, http.StatusUnauthorized},
+		{errInvalidArgument, http.StatusBadRequest},
+		{errDiskNotFound, http.StatusServiceUnavailable},
+		{hashMismatchError{"a", "b"}, http.StatusInternalServerError},
+	}
+	for i, testCase := range testCases {
+		code, status := storageRESTErrorCode(testCase.err)
+		if code == "" || status != testCase.status {
+			t.Errorf("Test %d: Unexpected code %q and status %d", i+1, code, status)
+		}
+		err := storageRESTErrorFromCode(code, testCase.err.Error())
+		if _, ok := testCase.err.(hashMismatchError); ok {
+			if _, ok = err.(hashMismatchError); !ok {
+				t.Errorf("Test %d: Expected hash mismatch error, got %v", i+1, err)
+			}
+		} else if err != testCase.err {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.err, err)
+		}
+	}
+
+	// Errors without a code keep their message.
+	code, status := storageRESTErrorCode(errors.New("some error"))
+	if code != "" || status != http.StatusInternalServerError {
+		t.Errorf("Unexpected code %q and status %d", code, status)
+	}
+	if err := storageRESTErrorFromCode(code, "some error"); err.Error() != "some error" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
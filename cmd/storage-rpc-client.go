@@ -19,6 +19,7 @@ package cmd
 import (
 	"bytes"
 	"io"
+	"io/ioutil"
 	"net"
 	"net/rpc"
 	"path"
@@ -212,6 +213,56 @@ func (n *networkStorage) AppendFile(volume, path string, buffer []byte) (err err
 	return nil
 }
 
+// CreateFile - appends all data read from reader to a remote file. The
+// RPC protocol cannot stream, data is sent in one call per buffer.
+func (n *networkStorage) CreateFile(volume, path string, size int64, reader io.Reader) (err error) {
+	if size >= 0 {
+		reader = io.LimitReader(reader, size)
+	}
+	buffer := make([]byte, readSizeV1)
+	var total int64
+	for {
+		m, rerr := io.ReadFull(reader, buffer)
+		if rerr != nil && rerr != io.EOF && rerr != io.ErrUnexpectedEOF {
+			return rerr
+		}
+		// Append at least once to create empty files.
+		if m > 0 || total == 0 {
+			if err = n.AppendFile(volume, path, buffer[:m]); err != nil {
+				return err
+			}
+		}
+		total += int64(m)
+		if rerr != nil {
+			break
+		}
+	}
+	if size >= 0 && total < size {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// ReadFileStream - returns a stream of a remote file, reading one
+// buffer per RPC call. The RPC storage API cannot verify a stream, the
+// file is verified by the remote node before streaming instead.
+func (n *networkStorage) ReadFileStream(volume, path string, offset, length int64, verifier *BitrotVerifier) (io.ReadCloser, error) {
+	if verifier != nil && !verifier.IsVerified() {
+		if _, err := n.ReadFile(volume, path, 0, nil, verifier); err != nil {
+			return nil, err
+		}
+		verifier.verified = true
+	} else if _, err := n.StatFile(volume, path); err != nil {
+		// Fail early like local disks if the file cannot be read.
+		return nil, err
+	}
+	reader := StorageReader(n, volume, path, offset)
+	if length >= 0 {
+		reader = io.LimitReader(reader, length)
+	}
+	return ioutil.NopCloser(reader), nil
+}
+
 // StatFile - get latest Stat information for a file at path.
 func (n *networkStorage) StatFile(volume, path string) (fileInfo FileInfo, err error) {
 	if err = n.rpcClient.Call("Storage.StatFileHandler", &StatFileArgs{
@@ -0,0 +1,165 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/minio/minio-go/pkg/policy"
+	"github.com/minio/minio/pkg/auth"
+)
+
+const (
+	// Header and query parameter carrying the session token of
+	// temporary credentials.
+	amzSecurityToken = "X-Amz-Security-Token"
+
+	// Issuer of the session tokens of LDAP users, keeps them apart
+	// from browser tokens signed with the same key.
+	stsLDAPIssuer = "minio-ldap"
+)
+
+// stsClaims - claims of the session token of temporary credentials.
+// The subject is the access key, the secret key is derived from the
+// token so that no state has to be kept on the server.
+type stsClaims struct {
+	jwtgo.StandardClaims
+	Username string   `json:"username"`
+	Policies []string `json:"policies"`
+}
+
+// stsSecretKey - returns the secret key of the temporary credentials
+// of a session token. Secret keys of all sessions change with the
+// server credentials.
+func stsSecretKey(sessionToken string) string {
+	mac := hmac.New(sha256.New, []byte(globalServerConfig.GetCredential().SecretKey))
+	mac.Write([]byte(sessionToken))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))[:40]
+}
+
+// newSTSCredentials - returns temporary credentials of an LDAP user
+// granting policies until expiry.
+func newSTSCredentials(username string, policies []string, expiry time.Time) (cred auth.Credentials, sessionToken string, err error) {
+	accessKey := auth.MustGetNewCredentials().AccessKey
+	jwt := jwtgo.NewWithClaims(jwtgo.SigningMethodHS512, stsClaims{
+		StandardClaims: jwtgo.StandardClaims{
+			ExpiresAt: expiry.Unix(),
+			Issuer:    stsLDAPIssuer,
+			Subject:   accessKey,
+		},
+		Username: username,
+		Policies: policies,
+	})
+	if sessionToken, err = jwt.SignedString([]byte(globalServerConfig.GetCredential().SecretKey)); err != nil {
+		return auth.Credentials{}, "", err
+	}
+	return auth.Credentials{AccessKey: accessKey, SecretKey: stsSecretKey(sessionToken)}, sessionToken, nil
+}
+
+// getSessionToken - returns the session token of a request, empty for
+// requests signed with the server credentials.
+func getSessionToken(r *http.Request) string {
+	if token := r.Header.Get(amzSecurityToken); token != "" {
+		return token
+	}
+	return r.URL.Query().Get(amzSecurityToken)
+}
+
+// parseSessionToken - returns the claims of a valid session token.
+// Sessions end once LDAP authentication is disabled.
+func parseSessionToken(sessionToken string) (*stsClaims, APIErrorCode) {
+	claims := &stsClaims{}
+	jwtToken, err := jwtgo.ParseWithClaims(sessionToken, claims, keyFuncCallback)
+	if err != nil {
+		if v, ok := err.(*jwtgo.ValidationError); ok && v.Errors&jwtgo.ValidationErrorExpired != 0 {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+	if !jwtToken.Valid || claims.Issuer != stsLDAPIssuer || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	if _, err = getLDAPConfig(); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, ErrNone
+}
+
+// getRequestCredential - returns the credentials a request signed with
+// accessKey is verified with: the server credentials, or the temporary
+// credentials of the session token of the request.
+func getRequestCredential(r *http.Request, accessKey string) (auth.Credentials, APIErrorCode) {
+	sessionToken := getSessionToken(r)
+	if sessionToken == "" {
+		cred := globalServerConfig.GetCredential()
+		if accessKey != cred.AccessKey {
+			return auth.Credentials{}, ErrInvalidAccessKeyID
+		}
+		return cred, ErrNone
+	}
+	claims, errCode := parseSessionToken(sessionToken)
+	if errCode != ErrNone {
+		return auth.Credentials{}, errCode
+	}
+	if claims.Subject != accessKey {
+		return auth.Credentials{}, ErrInvalidAccessKeyID
+	}
+	return auth.Credentials{AccessKey: accessKey, SecretKey: stsSecretKey(sessionToken)}, ErrNone
+}
+
+// enforceSessionPolicy - checks that the policies of the temporary
+// credentials of a request grant action on resource, a path of the form
+// /bucket/object. Requests of the server credentials are always allowed,
+// requests without action only with the server credentials.
+func enforceSessionPolicy(r *http.Request, action, resource string) APIErrorCode {
+	sessionToken := getSessionToken(r)
+	if sessionToken == "" {
+		return ErrNone
+	}
+	return enforceSessionTokenPolicy(sessionToken, action, resource)
+}
+
+// enforceSessionTokenPolicy - checks that the policies of a session
+// token grant action on resource, a path of the form /bucket/object.
+func enforceSessionTokenPolicy(sessionToken, action, resource string) APIErrorCode {
+	claims, errCode := parseSessionToken(sessionToken)
+	if errCode != ErrNone {
+		return errCode
+	}
+	if action == "" {
+		return ErrAccessDenied
+	}
+	config, err := getLDAPConfig()
+	if err != nil {
+		return ErrAccessDenied
+	}
+	var statements []policy.Statement
+	for _, name := range claims.Policies {
+		statements = append(statements, config.Policies[name].Statements...)
+	}
+	arn := bucketARNPrefix + strings.TrimSuffix(strings.TrimPrefix(resource, "/"), "/")
+	if !bucketPolicyEvalStatements(action, arn, nil, statements) {
+		return ErrAccessDenied
+	}
+	return ErrNone
+}
@@ -0,0 +1,143 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"time"
+
+	router "github.com/gorilla/mux"
+)
+
+const (
+	// STS API version implemented, as in AWS.
+	stsAPIVersion = "2011-06-15"
+
+	// Only action of the STS API.
+	stsActionAssumeRoleWithLDAPIdentity = "AssumeRoleWithLDAPIdentity"
+
+	// Limits and default of the validity of temporary credentials,
+	// the same as those of AWS.
+	stsMinDuration     = 15 * time.Minute
+	stsMaxDuration     = 12 * time.Hour
+	stsDefaultDuration = time.Hour
+)
+
+// stsAPIHandlers implements the STS API, which issues temporary
+// credentials to LDAP users.
+type stsAPIHandlers struct{}
+
+// registerSTSRouter - registers the STS API, form encoded POST
+// requests to the root path.
+func registerSTSRouter(mux *router.Router) {
+	sts := stsAPIHandlers{}
+	mux.Methods(http.MethodPost).Path("/").
+		HeadersRegexp("Content-Type", "application/x-www-form-urlencoded*").
+		HandlerFunc(sts.AssumeRoleWithLDAPIdentityHandler)
+}
+
+// stsCredentials - temporary credentials as returned by STS.
+type stsCredentials struct {
+	AccessKeyID     string    `xml:"AccessKeyId"`
+	SecretAccessKey string    `xml:"SecretAccessKey"`
+	SessionToken    string    `xml:"SessionToken"`
+	Expiration      time.Time `xml:"Expiration"`
+}
+
+// assumeRoleWithLDAPIdentityResponse - response of the
+// AssumeRoleWithLDAPIdentity action.
+type assumeRoleWithLDAPIdentityResponse struct {
+	XMLName xml.Name `xml:"https://sts.amazonaws.com/doc/2011-06-15/ AssumeRoleWithLDAPIdentityResponse"`
+	Result  struct {
+		Credentials stsCredentials `xml:"Credentials"`
+	} `xml:"AssumeRoleWithLDAPIdentityResult"`
+}
+
+// AssumeRoleWithLDAPIdentityHandler - authenticates an LDAP user by
+// username and password and returns temporary credentials granting the
+// policies of the user's groups.
+func (sts stsAPIHandlers) AssumeRoleWithLDAPIdentityHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeErrorResponse(w, ErrInvalidRequest, r.URL)
+		return
+	}
+	if r.PostForm.Get("Version") != stsAPIVersion {
+		writeErrorResponse(w, ErrInvalidRequest, r.URL)
+		return
+	}
+	if r.PostForm.Get("Action") != stsActionAssumeRoleWithLDAPIdentity {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+	config, err := getLDAPConfig()
+	if err != nil {
+		writeErrorResponse(w, ErrNotImplemented, r.URL)
+		return
+	}
+
+	duration := stsDefaultDuration
+	if seconds := r.PostForm.Get("DurationSeconds"); seconds != "" {
+		n, err := strconv.Atoi(seconds)
+		if err != nil {
+			writeErrorResponse(w, ErrInvalidDuration, r.URL)
+			return
+		}
+		duration = time.Duration(n) * time.Second
+		if duration < stsMinDuration || duration > stsMaxDuration {
+			writeErrorResponse(w, ErrInvalidDuration, r.URL)
+			return
+		}
+	}
+
+	username := r.PostForm.Get("LDAPUsername")
+	policies, err := ldapAuthenticate(config, username, r.PostForm.Get("LDAPPassword"))
+	if err == errAuthentication {
+		errorIf(err, "Unable to authenticate LDAP user ‘%s’ from %s", username, r.RemoteAddr)
+		writeErrorResponse(w, ErrAccessDenied, r.URL)
+		return
+	}
+	if err != nil {
+		errorIf(err, "Unable to query LDAP server %s", config.ServerAddr)
+		writeErrorResponse(w, ErrInternalError, r.URL)
+		return
+	}
+	// Make sure to log users without permissions, for security
+	// and auditing reasons.
+	if len(policies) == 0 {
+		errorIf(errLDAPNoPolicy, "Unable to issue credentials to LDAP user ‘%s’ from %s", username, r.RemoteAddr)
+		writeErrorResponse(w, ErrAccessDenied, r.URL)
+		return
+	}
+
+	expiry := UTCNow().Add(duration).Truncate(time.Second)
+	cred, sessionToken, err := newSTSCredentials(username, policies, expiry)
+	if err != nil {
+		writeErrorResponse(w, toAPIErrorCode(err), r.URL)
+		return
+	}
+
+	response := assumeRoleWithLDAPIdentityResponse{}
+	response.Result.Credentials = stsCredentials{
+		AccessKeyID:     cred.AccessKey,
+		SecretAccessKey: cred.SecretKey,
+		SessionToken:    sessionToken,
+		Expiration:      expiry,
+	}
+	writeSuccessResponseXML(w, encodeResponse(response))
+}
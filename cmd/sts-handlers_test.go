@@ -0,0 +1,358 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	router "github.com/gorilla/mux"
+	"github.com/minio/minio-go/pkg/policy"
+	"github.com/minio/minio-go/pkg/set"
+	"github.com/minio/minio/pkg/ldap/ldaptest"
+)
+
+// newTestLDAPServer - starts an LDAP server with the user alice, member
+// of the group developers, and the user bob, member of no group.
+func newTestLDAPServer() *ldaptest.Server {
+	return ldaptest.NewServer(nil,
+		ldaptest.Entry{
+			DN:         "uid=alice,ou=people,dc=example,dc=com",
+			Password:   "alice-secret",
+			Attributes: map[string][]string{"uid": {"alice"}},
+		},
+		ldaptest.Entry{
+			DN:         "uid=bob,ou=people,dc=example,dc=com",
+			Password:   "bob-secret",
+			Attributes: map[string][]string{"uid": {"bob"}},
+		},
+		ldaptest.Entry{
+			DN: "cn=developers,ou=groups,dc=example,dc=com",
+			Attributes: map[string][]string{
+				"objectClass": {"groupOfNames"},
+				"cn":          {"developers"},
+				"member":      {"uid=alice,ou=people,dc=example,dc=com"},
+			},
+		})
+}
+
+// newTestLDAPConfig - returns a configuration granting the group
+// developers read and write access to the objects of bucket.
+func newTestLDAPConfig(addr, bucket string) ldapConfig {
+	return ldapConfig{
+		Enable:            true,
+		ServerAddr:        addr,
+		UserDNFormat:      "uid=%s,ou=people,dc=example,dc=com",
+		GroupSearchBaseDN: "ou=groups,dc=example,dc=com",
+		GroupSearchFilter: "(&(objectClass=groupOfNames)(member=%s))",
+		Policies: map[string]policy.BucketAccessPolicy{
+			"developers": {
+				Version: "2012-10-17",
+				Statements: []policy.Statement{{
+					Effect:    "Allow",
+					Actions:   set.CreateStringSet("s3:GetObject", "s3:PutObject"),
+					Resources: set.CreateStringSet(bucketARNPrefix + bucket + "/*"),
+				}},
+			},
+		},
+	}
+}
+
+func TestLDAPConfigValidate(t *testing.T) {
+	valid := newTestLDAPConfig("localhost:389", "bucket")
+	testCases := []struct {
+		modify    func(*ldapConfig)
+		shouldErr bool
+	}{
+		{func(l *ldapConfig) {}, false},
+		{func(l *ldapConfig) { *l = ldapConfig{} }, false},
+		{func(l *ldapConfig) { l.ServerAddr = "localhost" }, true},
+		{func(l *ldapConfig) { l.UserDNFormat = "" }, true},
+		{func(l *ldapConfig) { l.UserDNFormat = "uid=user" }, true},
+		{func(l *ldapConfig) { l.GroupSearchFilter = "(member=%s" }, true},
+		{func(l *ldapConfig) { l.GroupSearchFilter = "(member=alice)" }, true},
+		{func(l *ldapConfig) { l.TLS, l.StartTLS = true, true }, true},
+		{func(l *ldapConfig) {
+			l.Policies = map[string]policy.BucketAccessPolicy{"empty": {}}
+		}, true},
+		{func(l *ldapConfig) {
+			l.Policies = map[string]policy.BucketAccessPolicy{"bad": {Statements: []policy.Statement{{
+				Effect:    "Allow",
+				Actions:   set.CreateStringSet("s3:Unknown"),
+				Resources: set.CreateStringSet(bucketARNPrefix + "bucket"),
+			}}}}
+		}, true},
+	}
+	for i, testCase := range testCases {
+		config := valid
+		testCase.modify(&config)
+		if err := config.Validate(); (err != nil) != testCase.shouldErr {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, testCase.shouldErr, err)
+		}
+	}
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	ldapServer := newTestLDAPServer()
+	defer ldapServer.Close()
+	config := newTestLDAPConfig(ldapServer.Addr, "bucket")
+
+	policies, err := ldapAuthenticate(config, "alice", "alice-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 1 || policies[0] != "developers" {
+		t.Errorf("Unexpected policies %v", policies)
+	}
+	if policies, err = ldapAuthenticate(config, "bob", "bob-secret"); err != nil || len(policies) != 0 {
+		t.Errorf("Expected no policies for bob, got %v, %v", policies, err)
+	}
+	for _, username := range []string{"alice", "", "alice,ou=people", "carol"} {
+		if _, err = ldapAuthenticate(config, username, "wrong"); err != errAuthentication {
+			t.Errorf("Expected authentication of %q to fail, got %v", username, err)
+		}
+	}
+	if _, err = ldapAuthenticate(config, "alice", ""); err != errAuthentication {
+		t.Errorf("Expected an empty password to be rejected, got %v", err)
+	}
+}
+
+// assumeRoleWithLDAPIdentity - requests temporary credentials.
+func assumeRoleWithLDAPIdentity(endpoint, username, password string) (*http.Response, error) {
+	form := url.Values{}
+	form.Set("Action", stsActionAssumeRoleWithLDAPIdentity)
+	form.Set("Version", stsAPIVersion)
+	form.Set("LDAPUsername", username)
+	form.Set("LDAPPassword", password)
+	return http.Post(endpoint+"/", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+}
+
+func TestSTSAssumeRoleWithLDAPIdentity(t *testing.T) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootPath)
+
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fsDir)
+	globalObjLayerMutex.Lock()
+	globalObjectAPI = obj
+	globalObjLayerMutex.Unlock()
+
+	mux := router.NewRouter().SkipClean(true)
+	registerSTSRouter(mux)
+	registerAdminRouter(mux)
+	registerAPIRouter(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ldapServer := newTestLDAPServer()
+	defer ldapServer.Close()
+
+	bucket, otherBucket := getRandomBucketName(), getRandomBucketName()
+	for _, b := range []string{bucket, otherBucket} {
+		if err := obj.MakeBucketWithLocation(b, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Temporary credentials are not issued while LDAP is disabled.
+	resp, err := assumeRoleWithLDAPIdentity(server.URL, "alice", "alice-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Fatalf("Expected %d, got %d", http.StatusNotImplemented, resp.StatusCode)
+	}
+
+	globalServerConfigMu.Lock()
+	globalServerConfig.LDAP = newTestLDAPConfig(ldapServer.Addr, bucket)
+	globalServerConfigMu.Unlock()
+
+	for _, user := range [][2]string{{"alice", "wrong"}, {"bob", "bob-secret"}} {
+		resp, err = assumeRoleWithLDAPIdentity(server.URL, user[0], user[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s: expected %d, got %d", user[0], http.StatusForbidden, resp.StatusCode)
+		}
+	}
+
+	resp, err = assumeRoleWithLDAPIdentity(server.URL, "alice", "alice-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	stsResp := assumeRoleWithLDAPIdentityResponse{}
+	if err = xml.NewDecoder(resp.Body).Decode(&stsResp); err != nil {
+		t.Fatal(err)
+	}
+	cred := stsResp.Result.Credentials
+	if cred.AccessKeyID == "" || cred.SecretAccessKey == "" || cred.SessionToken == "" {
+		t.Fatalf("Incomplete credentials %#v", cred)
+	}
+	if d := cred.Expiration.Sub(UTCNow()); d <= 0 || d > stsDefaultDuration {
+		t.Errorf("Unexpected expiration %v", cred.Expiration)
+	}
+
+	// signedRequest - returns a request signed with the temporary
+	// credentials and the session token token.
+	signedRequest := func(method, urlStr, token string, body []byte) *http.Request {
+		req, err := newTestRequest(method, urlStr, int64(len(body)), bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(amzSecurityToken, token)
+		if err = signRequestV4(req, cred.AccessKeyID, cred.SecretAccessKey); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	testCases := []struct {
+		req            *http.Request
+		expectedStatus int
+	}{
+		// Writing and reading objects of bucket is allowed.
+		{signedRequest(http.MethodPut, getPutObjectURL(server.URL, bucket, "object"), cred.SessionToken, []byte("hello")), http.StatusOK},
+		{signedRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "object"), cred.SessionToken, nil), http.StatusOK},
+		// Other buckets and bucket operations are not.
+		{signedRequest(http.MethodPut, getPutObjectURL(server.URL, otherBucket, "object"), cred.SessionToken, []byte("hello")), http.StatusForbidden},
+		{signedRequest(http.MethodGet, getListObjectsV1URL(server.URL, bucket, ""), cred.SessionToken, nil), http.StatusForbidden},
+		{signedRequest(http.MethodGet, server.URL+"/", cred.SessionToken, nil), http.StatusForbidden},
+		// The session token is required and must be intact.
+		{signedRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "object"), "", nil), http.StatusForbidden},
+		{signedRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "object"), cred.SessionToken+"x", nil), http.StatusBadRequest},
+	}
+	for i, testCase := range testCases {
+		resp, err := http.DefaultClient.Do(testCase.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != testCase.expectedStatus {
+			t.Errorf("Test %d: %s %s: expected %d, got %d", i+1, testCase.req.Method,
+				testCase.req.URL.Path, testCase.expectedStatus, resp.StatusCode)
+		}
+	}
+
+	// Presigned requests carry the session token in the query.
+	req, err := newTestRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "object"), 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.URL.RawQuery = url.Values{amzSecurityToken: {cred.SessionToken}}.Encode()
+	if err = preSignV4(req, cred.AccessKeyID, cred.SecretAccessKey, 60); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Presigned GET: expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Streaming uploads are verified with the temporary credentials.
+	data := bytes.Repeat([]byte("a"), 64*1024)
+	req, err = newTestStreamingRequest(http.MethodPut, getPutObjectURL(server.URL, bucket, "streamed"), int64(len(data)), 64*1024, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(amzSecurityToken, cred.SessionToken)
+	currTime := UTCNow()
+	signature, err := signStreamingRequest(req, cred.AccessKeyID, cred.SecretAccessKey, currTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req, err = assembleStreamingChunks(req, bytes.NewReader(data), 64*1024, cred.SecretAccessKey, signature, currTime); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Streaming PUT: expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Temporary credentials are never granted admin operations.
+	req = signedRequest(http.MethodGet, server.URL+adminAPIPathPrefix+"/v1/service", cred.SessionToken, nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Admin request: expected %d, got %d", http.StatusForbidden, resp.StatusCode)
+	}
+
+	// Sessions end once LDAP is disabled.
+	globalServerConfigMu.Lock()
+	globalServerConfig.LDAP.Enable = false
+	globalServerConfigMu.Unlock()
+	req = signedRequest(http.MethodGet, getGetObjectURL(server.URL, bucket, "object"), cred.SessionToken, nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Disabled LDAP: expected %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestParseSessionTokenExpired(t *testing.T) {
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	globalServerConfig.LDAP = newTestLDAPConfig("localhost:389", "bucket")
+
+	_, token, err := newSTSCredentials("alice", []string{"developers"}, UTCNow().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, errCode := parseSessionToken(token); errCode != ErrExpiredToken {
+		t.Errorf("Expected %v, got %v", ErrExpiredToken, errCode)
+	}
+
+	// Browser tokens are not session tokens.
+	token, err = authenticateWeb(globalServerConfig.GetCredential().AccessKey, globalServerConfig.GetCredential().SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, errCode := parseSessionToken(token); errCode != ErrInvalidToken {
+		t.Errorf("Expected %v, got %v", ErrInvalidToken, errCode)
+	}
+}
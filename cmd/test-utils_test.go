@@ -161,6 +161,7 @@ func prepareFS() (ObjectLayer, string, error) {
 	}
 	obj, err := newFSObjectLayer(fsDirs[0])
 	if err != nil {
+		removeRoots(fsDirs)
 		return nil, "", err
 	}
 	return obj, fsDirs[0], nil
@@ -183,6 +184,39 @@ func prepareXL16() (ObjectLayer, []string, error) {
 	return prepareXL(16)
 }
 
+// prepareGatewayTest - initializes a test config and an FS backend,
+// registered as the object layer of the server, along with n empty
+// directories. The returned function shuts the backend down and
+// removes all of them.
+func prepareGatewayTest(t *testing.T, n int) (ObjectLayer, []string, func()) {
+	rootPath, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, fsDir, err := prepareFS()
+	if err != nil {
+		os.RemoveAll(rootPath)
+		t.Fatal(err)
+	}
+	dirs, err := getRandomDisks(n)
+	if err != nil {
+		obj.Shutdown()
+		removeRoots([]string{fsDir, rootPath})
+		t.Fatal(err)
+	}
+	globalObjLayerMutex.Lock()
+	globalObjectAPI = obj
+	globalObjLayerMutex.Unlock()
+
+	return obj, dirs, func() {
+		globalObjLayerMutex.Lock()
+		globalObjectAPI = nil
+		globalObjLayerMutex.Unlock()
+		obj.Shutdown()
+		removeRoots(append(dirs, fsDir, rootPath))
+	}
+}
+
 // Initialize FS objects.
 func initFSObjects(disk string, t *testing.T) (obj ObjectLayer) {
 	newTestConfig(globalMinioDefaultRegion)
@@ -1444,6 +1478,13 @@ func getMultiDeleteObjectURL(endPoint, bucketName string) string {
 
 }
 
+// return URL for restoring a transitioned object.
+func getRestoreObjectURL(endPoint, bucketName, objectName string) string {
+	queryValue := url.Values{}
+	queryValue.Set("restore", "")
+	return makeTestTargetURL(endPoint, bucketName, objectName, queryValue)
+}
+
 // return URL for HEAD on the object.
 func getHeadObjectURL(endPoint, bucketName, objectName string) string {
 	return makeTestTargetURL(endPoint, bucketName, objectName, url.Values{})
@@ -2156,6 +2197,9 @@ func registerBucketLevelFunc(bucket *router.Router, api objectAPIHandlers, apiFu
 		case "ListenBucketNotification":
 			// Register ListenBucketNotification Handler.
 			bucket.Methods("GET").HandlerFunc(api.ListenBucketNotificationHandler).Queries("events", "{events:.*}")
+		case "RestoreObject":
+			// Register RestoreObject Handler.
+			bucket.Methods("POST").Path("/{object:.+}").HandlerFunc(api.RestoreObjectHandler).Queries("restore", "")
 		}
 	}
 }
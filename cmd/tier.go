@@ -0,0 +1,247 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/auth"
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Interval between two scans for objects to transition to their tier.
+const tierScanInterval = 24 * time.Hour
+
+const (
+	// Lock serializing the scans of all servers of a cluster.
+	tierScanLockPath = "tier/scan.lock"
+
+	// File recording the time of the last scan of the cluster.
+	tierScanJSONPath = "tier/scan.json"
+)
+
+// tierScanInfo - saved at tierScanJSONPath after every scan.
+type tierScanInfo struct {
+	Version string    `json:"version"`
+	Time    time.Time `json:"time"`
+}
+
+// tierConfig - remote tier cold object data is transitioned to.
+type tierConfig struct {
+	// Backend of the tier, name of a gateway, e.g. "s3".
+	Type      string `json:"type"`
+	Endpoint  string `json:"endpoint"`
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+	Bucket    string `json:"bucket"`
+	Prefix    string `json:"prefix"`
+}
+
+// transitionRule - transitions objects of bucket below prefix to tier
+// once they were not modified for days.
+type transitionRule struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix"`
+	Days   int    `json:"days"`
+	Tier   string `json:"tier"`
+}
+
+// tieringConfig - tiers by name and the rules using them.
+type tieringConfig struct {
+	Tiers map[string]tierConfig `json:"tiers"`
+	Rules []transitionRule      `json:"rules"`
+}
+
+// Validate - validates the tiering configuration.
+func (t tieringConfig) Validate() error {
+	for name, tier := range t.Tiers {
+		if tier.Type == "" {
+			return fmt.Errorf("tier ‘%s’ has no type", name)
+		}
+		if tier.Bucket == "" {
+			return fmt.Errorf("tier ‘%s’ has no bucket", name)
+		}
+	}
+	for i, rule := range t.Rules {
+		if rule.Bucket == "" {
+			return fmt.Errorf("transition rule %d has no bucket", i+1)
+		}
+		if rule.Days <= 0 {
+			return fmt.Errorf("transition rule %d has an invalid number of days %d", i+1, rule.Days)
+		}
+		if _, ok := t.Tiers[rule.Tier]; !ok {
+			return fmt.Errorf("transition rule %d refers to unknown tier ‘%s’", i+1, rule.Tier)
+		}
+	}
+	return nil
+}
+
+var (
+	// ObjectLayers of the configured tiers by name, created on
+	// first use.
+	tierLayersMu sync.Mutex
+	tierLayers   = make(map[string]ObjectLayer)
+)
+
+// getTier - returns the configuration and ObjectLayer of tier name.
+func getTier(name string) (tierConfig, ObjectLayer, error) {
+	globalServerConfigMu.RLock()
+	tier, ok := globalServerConfig.Tiering.Tiers[name]
+	globalServerConfigMu.RUnlock()
+	if !ok {
+		return tier, nil, fmt.Errorf("unknown tier ‘%s’", name)
+	}
+
+	tierLayersMu.Lock()
+	defer tierLayersMu.Unlock()
+	if objLayer, ok := tierLayers[name]; ok {
+		return tier, objLayer, nil
+	}
+
+	objLayer, err := newGatewayBackend(tier.Type, tier.Endpoint, auth.Credentials{
+		AccessKey: tier.AccessKey,
+		SecretKey: tier.SecretKey,
+	})
+	if err != nil {
+		return tier, nil, fmt.Errorf("Unable to initialize tier ‘%s’. %v", name, err)
+	}
+	tierLayers[name] = objLayer
+	return tier, objLayer, nil
+}
+
+// tierObjectName - returns the name of the copy of object in tier.
+func tierObjectName(tier tierConfig, bucket, object string) string {
+	return path.Join(tier.Prefix, bucket, object)
+}
+
+// transitionColdObjects - transitions objects matching the rules of the
+// tiering configuration and removes expired restored copies of
+// transitioned objects every interval.
+func transitionColdObjects(interval time.Duration, xl xlObjects, doneCh chan struct{}) {
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-doneCh:
+			// Stop the timer.
+			ticker.Stop()
+			return
+		case <-ticker.C:
+			scanColdObjects(xl, interval, UTCNow())
+		}
+	}
+}
+
+// scanColdObjects - transitions the objects matching the rules of the
+// tiering configuration, unless another server of the cluster scanned
+// within the last interval. Every server runs the scan, the servers
+// take turns by a cluster wide lock.
+func scanColdObjects(xl xlObjects, interval time.Duration, now time.Time) {
+	scanLock := xl.nsMutex.NewNSLock(minioMetaBucket, tierScanLockPath)
+	if err := scanLock.GetLock(globalOperationTimeout); err != nil {
+		// Another server is still scanning.
+		return
+	}
+	defer scanLock.Unlock()
+
+	// The tickers of the servers are not aligned, allow for some
+	// drift such that one of them scans every interval.
+	if last, err := readTierScanTime(xl); err == nil && now.Sub(last) < interval/2 {
+		return
+	}
+
+	globalServerConfigMu.RLock()
+	rules := globalServerConfig.Tiering.Rules
+	globalServerConfigMu.RUnlock()
+	for _, rule := range rules {
+		transitionRuleObjects(xl, rule, now)
+	}
+
+	errorIf(saveTierScanTime(xl, now), "Unable to save the time of the tier scan")
+}
+
+// readTierScanTime - returns the time of the last scan of the cluster,
+// the zero time if there was none.
+func readTierScanTime(xl xlObjects) (time.Time, error) {
+	var buffer bytes.Buffer
+	if err := xl.GetObject(minioMetaBucket, tierScanJSONPath, 0, -1, &buffer, ""); err != nil {
+		if isErrObjectNotFound(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	var info tierScanInfo
+	if err := json.Unmarshal(buffer.Bytes(), &info); err != nil {
+		return time.Time{}, errors.Trace(err)
+	}
+	return info.Time, nil
+}
+
+// saveTierScanTime - records the time of the last scan of the cluster.
+func saveTierScanTime(xl xlObjects, now time.Time) error {
+	buf, err := json.Marshal(tierScanInfo{Version: "1", Time: now})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	hashReader, err := hash.NewReader(bytes.NewReader(buf), int64(len(buf)), "", getSHA256Hash(buf))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = xl.PutObject(minioMetaBucket, tierScanJSONPath, hashReader, nil)
+	return err
+}
+
+// transitionRuleObjects - transitions all objects of rule last
+// modified more than rule.Days before now.
+func transitionRuleObjects(xl xlObjects, rule transitionRule, now time.Time) {
+	expiry := now.Add(-time.Duration(rule.Days) * 24 * time.Hour)
+	marker := ""
+	for {
+		result, err := xl.ListObjects(rule.Bucket, rule.Prefix, marker, "", 1000)
+		if err != nil {
+			errorIf(err, "Unable to list objects of bucket %s to transition", rule.Bucket)
+			return
+		}
+		for _, objInfo := range result.Objects {
+			if objInfo.IsDir || objInfo.ModTime.After(expiry) {
+				continue
+			}
+			if err = xl.transitionObject(rule.Bucket, objInfo.Name, rule.Tier, now); err != nil {
+				errorIf(err, "Unable to transition %s/%s to tier %s", rule.Bucket, objInfo.Name, rule.Tier)
+			}
+		}
+		if !result.IsTruncated {
+			return
+		}
+		marker = result.NextMarker
+	}
+}
+
+// deleteTierObject - deletes the copy of a transitioned object from
+// its tier.
+func deleteTierObject(remote *xlMetaTier) error {
+	_, objLayer, err := getTier(remote.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return objLayer.DeleteObject(remote.Bucket, remote.Object)
+}
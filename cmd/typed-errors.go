@@ -58,3 +58,10 @@ var errInvalidRange = errors.New("Invalid range")
 // errInvalidRangeSource - returned when given range value exceeds
 // the source object size.
 var errInvalidRangeSource = errors.New("Range specified exceeds source object size")
+
+// errErasureAlgorithmUnknown - the erasure coding algorithm of an
+// object is not supported.
+var errErasureAlgorithmUnknown = errors.New("Unknown erasure coding algorithm")
+
+// errSingularMatrix - an erasure coding matrix cannot be inverted.
+var errSingularMatrix = errors.New("Erasure coding matrix is singular")
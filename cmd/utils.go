@@ -259,7 +259,7 @@ func NewCustomHTTPTransport() http.RoundTripper {
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
-		TLSClientConfig:       &tls.Config{RootCAs: globalRootCAs},
+		TLSClientConfig:       &tls.Config{RootCAs: getGlobalRootCAs()},
 		DisableCompression:    true,
 	}
 }
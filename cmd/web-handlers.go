@@ -121,7 +121,7 @@ func (web *webAPIHandlers) MakeBucket(r *http.Request, args *MakeBucketArgs, rep
 	if objectAPI == nil {
 		return toJSONError(errServerNotInitialized)
 	}
-	if !isHTTPRequestValid(r) {
+	if !isWebRequestAllowed(r, "s3:CreateBucket", args.BucketName, "") {
 		return toJSONError(errAuthentication)
 	}
 
@@ -130,7 +130,7 @@ func (web *webAPIHandlers) MakeBucket(r *http.Request, args *MakeBucketArgs, rep
 		return toJSONError(errInvalidBucketName)
 	}
 
-	if err := objectAPI.MakeBucketWithLocation(args.BucketName, globalServerConfig.GetRegion()); err != nil {
+	if err := makeFederatedBucket(objectAPI, args.BucketName, globalServerConfig.GetRegion()); err != nil {
 		return toJSONError(err, args.BucketName)
 	}
 
@@ -149,11 +149,11 @@ func (web *webAPIHandlers) DeleteBucket(r *http.Request, args *RemoveBucketArgs,
 	if objectAPI == nil {
 		return toJSONError(errServerNotInitialized)
 	}
-	if !isHTTPRequestValid(r) {
+	if !isWebRequestAllowed(r, "s3:DeleteBucket", args.BucketName, "") {
 		return toJSONError(errAuthentication)
 	}
 
-	err := objectAPI.DeleteBucket(args.BucketName)
+	err := deleteFederatedBucket(objectAPI, args.BucketName)
 	if err != nil {
 		return toJSONError(err, args.BucketName)
 	}
@@ -182,7 +182,7 @@ func (web *webAPIHandlers) ListBuckets(r *http.Request, args *WebGenericArgs, re
 	if objectAPI == nil {
 		return toJSONError(errServerNotInitialized)
 	}
-	authErr := webRequestAuthenticate(r)
+	claims, authErr := webSessionAuthenticate(r)
 	if authErr != nil {
 		return toJSONError(authErr)
 	}
@@ -191,6 +191,10 @@ func (web *webAPIHandlers) ListBuckets(r *http.Request, args *WebGenericArgs, re
 		return toJSONError(err)
 	}
 	for _, bucket := range buckets {
+		// Only list the buckets the session may browse.
+		if !claims.isAllowed("s3:ListBucket", bucket.Name, "") {
+			continue
+		}
 		reply.Buckets = append(reply.Buckets, WebBucketInfo{
 			Name:         bucket.Name,
 			CreationDate: bucket.Created,
@@ -238,11 +242,14 @@ func (web *webAPIHandlers) ListObjects(r *http.Request, args *ListObjectsArgs, r
 	prefix := args.Prefix + "test" // To test if GetObject/PutObject with the specified prefix is allowed.
 	readable := isBucketActionAllowed("s3:GetObject", args.BucketName, prefix, objectAPI)
 	writable := isBucketActionAllowed("s3:PutObject", args.BucketName, prefix, objectAPI)
-	authErr := webRequestAuthenticate(r)
+	claims, authErr := webSessionAuthenticate(r)
 	switch {
 	case authErr == errAuthentication:
 		return toJSONError(authErr)
-	case authErr == nil:
+	case claims.isOwner():
+		break
+	case claims.isAllowed("s3:ListBucket", args.BucketName, args.Prefix):
+		reply.Writable = writable || claims.isAllowed("s3:PutObject", args.BucketName, prefix)
 		break
 	case readable && writable:
 		reply.Writable = true
@@ -299,13 +306,19 @@ func (web *webAPIHandlers) RemoveObject(r *http.Request, args *RemoveObjectArgs,
 	if objectAPI == nil {
 		return toJSONError(errServerNotInitialized)
 	}
-	if !isHTTPRequestValid(r) {
+	claims, authErr := webSessionAuthenticate(r)
+	if authErr != nil {
 		return toJSONError(errAuthentication)
 	}
 
 	if args.BucketName == "" || len(args.Objects) == 0 {
 		return toJSONError(errInvalidArgument)
 	}
+	for _, objectName := range args.Objects {
+		if !claims.isAllowed("s3:DeleteObject", args.BucketName, objectName) {
+			return toJSONError(errAuthentication)
+		}
+	}
 
 	var err error
 next:
@@ -503,13 +516,18 @@ type URLTokenReply struct {
 
 // CreateURLToken creates a URL token (short-lived) for GET requests.
 func (web *webAPIHandlers) CreateURLToken(r *http.Request, args *WebGenericArgs, reply *URLTokenReply) error {
-	if !isHTTPRequestValid(r) {
+	claims, authErr := webSessionAuthenticate(r)
+	if authErr != nil {
 		return toJSONError(errAuthentication)
 	}
 
-	creds := globalServerConfig.GetCredential()
-
-	token, err := authenticateURL(creds.AccessKey, creds.SecretKey)
+	// URL tokens of OpenID users carry the policies of their session.
+	urlClaims := *claims
+	urlClaims.ExpiresAt = UTCNow().Add(defaultURLJWTExpiry).Unix()
+	if claims.ExpiresAt < urlClaims.ExpiresAt {
+		urlClaims.ExpiresAt = claims.ExpiresAt
+	}
+	token, err := newWebToken(urlClaims)
 	if err != nil {
 		return toJSONError(err)
 	}
@@ -531,12 +549,12 @@ func (web *webAPIHandlers) Upload(w http.ResponseWriter, r *http.Request) {
 	bucket := vars["bucket"]
 	object := vars["object"]
 
-	authErr := webRequestAuthenticate(r)
+	claims, authErr := webSessionAuthenticate(r)
 	if authErr == errAuthentication {
 		writeWebErrorResponse(w, errAuthentication)
 		return
 	}
-	if authErr != nil && !isBucketActionAllowed("s3:PutObject", bucket, object, objectAPI) {
+	if !claims.isAllowed("s3:PutObject", bucket, object) && !isBucketActionAllowed("s3:PutObject", bucket, object, objectAPI) {
 		writeWebErrorResponse(w, errAuthentication)
 		return
 	}
@@ -589,7 +607,8 @@ func (web *webAPIHandlers) Download(w http.ResponseWriter, r *http.Request) {
 	object := vars["object"]
 	token := r.URL.Query().Get("token")
 
-	if !isAuthTokenValid(token) && !isBucketActionAllowed("s3:GetObject", bucket, object, objectAPI) {
+	claims, _ := parseWebToken(token)
+	if !claims.isAllowed("s3:GetObject", bucket, object) && !isBucketActionAllowed("s3:GetObject", bucket, object, objectAPI) {
 		writeWebErrorResponse(w, errAuthentication)
 		return
 	}
@@ -630,13 +649,13 @@ func (web *webAPIHandlers) DownloadZip(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token := r.URL.Query().Get("token")
-	if !isAuthTokenValid(token) {
-		for _, object := range args.Objects {
-			if !isBucketActionAllowed("s3:GetObject", args.BucketName, pathJoin(args.Prefix, object), objectAPI) {
-				writeWebErrorResponse(w, errAuthentication)
-				return
-			}
+	claims, _ := parseWebToken(r.URL.Query().Get("token"))
+	for _, object := range args.Objects {
+		objectName := pathJoin(args.Prefix, object)
+		if !claims.isAllowed("s3:GetObject", args.BucketName, objectName) &&
+			!isBucketActionAllowed("s3:GetObject", args.BucketName, objectName, objectAPI) {
+			writeWebErrorResponse(w, errAuthentication)
+			return
 		}
 	}
 
@@ -714,7 +733,7 @@ func (web *webAPIHandlers) GetBucketPolicy(r *http.Request, args *GetBucketPolic
 		return toJSONError(errServerNotInitialized)
 	}
 
-	if !isHTTPRequestValid(r) {
+	if !isWebRequestAllowed(r, "s3:GetBucketPolicy", args.BucketName, "") {
 		return toJSONError(errAuthentication)
 	}
 
@@ -756,7 +775,7 @@ func (web *webAPIHandlers) ListAllBucketPolicies(r *http.Request, args *ListAllB
 		return toJSONError(errServerNotInitialized)
 	}
 
-	if !isHTTPRequestValid(r) {
+	if !isWebRequestAllowed(r, "s3:GetBucketPolicy", args.BucketName, "") {
 		return toJSONError(errAuthentication)
 	}
 	var policyInfo, err = objectAPI.GetBucketPolicy(args.BucketName)
@@ -792,7 +811,7 @@ func (web *webAPIHandlers) SetBucketPolicy(r *http.Request, args *SetBucketPolic
 		return toJSONError(errServerNotInitialized)
 	}
 
-	if !isHTTPRequestValid(r) {
+	if !isWebRequestAllowed(r, "s3:PutBucketPolicy", args.BucketName, "") {
 		return toJSONError(errAuthentication)
 	}
 
@@ -990,6 +1009,12 @@ func toWebAPIError(err error) APIError {
 			HTTPStatusCode: http.StatusMethodNotAllowed,
 			Description:    err.Error(),
 		}
+	} else if err == errOpenIDNotEnabled {
+		return APIError{
+			Code:           "NotImplemented",
+			HTTPStatusCode: http.StatusNotImplemented,
+			Description:    err.Error(),
+		}
 	} else if err == errInvalidBucketName {
 		return APIError{
 			Code:           "InvalidBucketName",
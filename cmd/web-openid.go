@@ -0,0 +1,355 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/minio/minio-go/pkg/policy"
+	"github.com/minio/minio-go/pkg/set"
+	"github.com/minio/minio/pkg/openid"
+)
+
+const (
+	// Time a user has to log in at the OpenID provider.
+	openIDStateExpiry = 10 * time.Minute
+
+	// Audience of the state tokens passed through the provider, keeps
+	// them apart from session tokens signed with the same key.
+	openIDStateAudience = "minio-openid-state"
+
+	// Default claim of ID tokens mapped to policies.
+	openIDDefaultClaimName = "groups"
+)
+
+var (
+	errOpenIDNotEnabled = errors.New("OpenID login is not enabled")
+	errOpenIDNoPolicy   = errors.New("No policy is granted to the OpenID user")
+)
+
+// openIDActionMap - actions which can be granted to OpenID users, the
+// bucket policy actions and the bucket management actions of the browser.
+var openIDActionMap = supportedActionMap.Union(set.CreateStringSet(
+	"s3:CreateBucket", "s3:DeleteBucket", "s3:GetBucketPolicy", "s3:PutBucketPolicy"))
+
+// openIDConfig - OpenID Connect provider users log in to the browser
+// with. The values of the claim ClaimName of a user's ID token select
+// the policies granted to the user's session.
+type openIDConfig struct {
+	Enable       bool     `json:"enable"`
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"clientID"`
+	ClientSecret string   `json:"clientSecret"`
+	RedirectURI  string   `json:"redirectURI"`
+	Scopes       []string `json:"scopes"`
+	ClaimName    string   `json:"claimName"`
+	// Policies by claim value, in bucket policy syntax.
+	Policies map[string]policy.BucketAccessPolicy `json:"policies"`
+}
+
+// Validate - validates the OpenID configuration.
+func (o openIDConfig) Validate() error {
+	if !o.Enable {
+		return nil
+	}
+	if o.Issuer == "" || o.ClientID == "" || o.RedirectURI == "" {
+		return errors.New("openid: issuer, clientID and redirectURI must be set")
+	}
+	for _, u := range []string{o.Issuer, o.RedirectURI} {
+		if parsed, err := url.Parse(u); err != nil || parsed.Host == "" {
+			return fmt.Errorf("openid: invalid URL ‘%s’", u)
+		}
+	}
+	for name, p := range o.Policies {
+		if len(p.Statements) == 0 {
+			return fmt.Errorf("openid: policy ‘%s’ has no statements", name)
+		}
+		for _, statement := range p.Statements {
+			if err := isValidEffect(statement.Effect); err != nil {
+				return fmt.Errorf("openid: policy ‘%s’: %v", name, err)
+			}
+			if err := isValidResources(statement.Resources); err != nil {
+				return fmt.Errorf("openid: policy ‘%s’: %v", name, err)
+			}
+			if statement.Actions.IsEmpty() {
+				return fmt.Errorf("openid: policy ‘%s’: Action list cannot be empty", name)
+			}
+			if unsupported := statement.Actions.Difference(openIDActionMap); !unsupported.IsEmpty() {
+				return fmt.Errorf("openid: policy ‘%s’: unsupported actions %v", name, unsupported.ToSlice())
+			}
+		}
+	}
+	return nil
+}
+
+func (o openIDConfig) providerConfig() openid.Config {
+	return openid.Config{
+		Issuer:       o.Issuer,
+		ClientID:     o.ClientID,
+		ClientSecret: o.ClientSecret,
+		RedirectURI:  o.RedirectURI,
+		Scopes:       o.Scopes,
+	}
+}
+
+func (o openIDConfig) claimName() string {
+	if o.ClaimName == "" {
+		return openIDDefaultClaimName
+	}
+	return o.ClaimName
+}
+
+// openIDProviderCache - the provider of the current configuration,
+// discovered on first use and again once the configuration changes.
+type openIDProviderCache struct {
+	mu       sync.Mutex
+	config   openid.Config
+	provider *openid.Provider
+}
+
+var globalOpenIDProvider = &openIDProviderCache{}
+
+func (c *openIDProviderCache) get(config openIDConfig) (*openid.Provider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	providerConfig := config.providerConfig()
+	if c.provider != nil && reflect.DeepEqual(c.config, providerConfig) {
+		return c.provider, nil
+	}
+	provider, err := openid.NewProvider(providerConfig)
+	if err != nil {
+		return nil, err
+	}
+	c.config, c.provider = providerConfig, provider
+	return provider, nil
+}
+
+// getOpenIDConfig - returns the OpenID configuration if enabled.
+func getOpenIDConfig() (openIDConfig, error) {
+	globalServerConfigMu.RLock()
+	defer globalServerConfigMu.RUnlock()
+	if globalServerConfig == nil || !globalServerConfig.OpenID.Enable {
+		return openIDConfig{}, errOpenIDNotEnabled
+	}
+	return globalServerConfig.OpenID, nil
+}
+
+// isOpenIDSessionValid - returns whether claims belong to a session of
+// the configured provider. Sessions end once OpenID login is disabled
+// or another provider is configured.
+func isOpenIDSessionValid(claims *webClaims) bool {
+	config, err := getOpenIDConfig()
+	if err != nil {
+		return false
+	}
+	return claims.Issuer != "" && claims.Issuer == config.Issuer && len(claims.Policies) > 0
+}
+
+// isAllowed - returns whether the session may perform action on the
+// bucket or object. The server credentials may perform all actions,
+// OpenID users the ones granted by their policies.
+func (c *webClaims) isAllowed(action, bucket, object string) bool {
+	if c == nil {
+		return false
+	}
+	if c.isOwner() {
+		return true
+	}
+	config, err := getOpenIDConfig()
+	if err != nil {
+		return false
+	}
+	var statements []policy.Statement
+	for _, name := range c.Policies {
+		statements = append(statements, config.Policies[name].Statements...)
+	}
+	resource := bucketARNPrefix + path.Join(bucket, object)
+	return bucketPolicyEvalStatements(action, resource, nil, statements)
+}
+
+// openIDPolicies - returns the names of the configured policies matching
+// the values of the policy claim, which is either a string or a list.
+func openIDPolicies(config openIDConfig, claims jwtgo.MapClaims) []string {
+	var values []string
+	switch v := claims[config.claimName()].(type) {
+	case string:
+		values = append(values, v)
+	case []interface{}:
+		for _, value := range v {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	names := set.NewStringSet()
+	for _, value := range values {
+		if _, ok := config.Policies[value]; ok {
+			names.Add(value)
+		}
+	}
+	policies := names.ToSlice()
+	sort.Strings(policies)
+	return policies
+}
+
+// newOpenIDState - returns a state token binding a nonce to the login
+// request, verified when the provider redirects back.
+func newOpenIDState() (state, nonce string, err error) {
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+	nonce = base64.RawURLEncoding.EncodeToString(b)
+	state, err = newWebToken(webClaims{
+		StandardClaims: jwtgo.StandardClaims{
+			Audience:  openIDStateAudience,
+			ExpiresAt: UTCNow().Add(openIDStateExpiry).Unix(),
+			Id:        nonce,
+		},
+	})
+	return state, nonce, err
+}
+
+// parseOpenIDState - returns the nonce of a valid state token.
+func parseOpenIDState(state string) (string, error) {
+	claims := &jwtgo.StandardClaims{}
+	jwtToken, err := jwtgo.ParseWithClaims(state, claims, keyFuncCallback)
+	if err != nil {
+		return "", err
+	}
+	if !jwtToken.Valid || !claims.VerifyAudience(openIDStateAudience, true) || claims.Id == "" {
+		return "", errAuthentication
+	}
+	return claims.Id, nil
+}
+
+// OpenIDLogin - redirects the browser to the OpenID provider.
+func (web *webAPIHandlers) OpenIDLogin(w http.ResponseWriter, r *http.Request) {
+	config, err := getOpenIDConfig()
+	if err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+	provider, err := globalOpenIDProvider.get(config)
+	if err != nil {
+		errorIf(err, "Unable to discover OpenID provider %s", config.Issuer)
+		writeWebErrorResponse(w, err)
+		return
+	}
+	state, nonce, err := newOpenIDState()
+	if err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+	http.Redirect(w, r, provider.AuthCodeURL(state, nonce), http.StatusFound)
+}
+
+// openIDLoginPage - stores the session token where the browser UI
+// expects it and opens the UI.
+var openIDLoginPage = template.Must(template.New("openid").Parse(`<!DOCTYPE html>
+<html><head><script>
+window.localStorage.setItem("token", {{.Token}});
+window.location.replace({{.Location}});
+</script></head><body></body></html>
+`))
+
+// OpenIDCallback - validates the ID token of the user the provider
+// redirects back and starts a browser session granting the policies
+// selected by the user's claims.
+func (web *webAPIHandlers) OpenIDCallback(w http.ResponseWriter, r *http.Request) {
+	config, err := getOpenIDConfig()
+	if err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+	query := r.URL.Query()
+	if e := query.Get("error"); e != "" {
+		errorIf(fmt.Errorf("%s %s", e, query.Get("error_description")), "OpenID login from %s failed", r.RemoteAddr)
+		writeWebErrorResponse(w, errAuthentication)
+		return
+	}
+	nonce, err := parseOpenIDState(query.Get("state"))
+	if err != nil {
+		errorIf(err, "Invalid OpenID state from %s", r.RemoteAddr)
+		writeWebErrorResponse(w, errAuthentication)
+		return
+	}
+	provider, err := globalOpenIDProvider.get(config)
+	if err != nil {
+		errorIf(err, "Unable to discover OpenID provider %s", config.Issuer)
+		writeWebErrorResponse(w, err)
+		return
+	}
+	idToken, err := provider.Exchange(query.Get("code"))
+	if err != nil {
+		errorIf(err, "Unable to redeem OpenID authorization code from %s", r.RemoteAddr)
+		writeWebErrorResponse(w, errAuthentication)
+		return
+	}
+	idClaims, err := provider.Verify(idToken, nonce)
+	if err != nil {
+		errorIf(err, "Invalid OpenID ID token from %s", r.RemoteAddr)
+		writeWebErrorResponse(w, errAuthentication)
+		return
+	}
+
+	// Make sure to log logins of users without permissions,
+	// for security and auditing reasons.
+	subject, _ := idClaims["sub"].(string)
+	policies := openIDPolicies(config, idClaims)
+	if subject == "" || len(policies) == 0 {
+		errorIf(errOpenIDNoPolicy, "Unable to login OpenID user ‘%s’ from %s", subject, r.RemoteAddr)
+		writeWebErrorResponse(w, errAuthentication)
+		return
+	}
+
+	// Sessions do not outlive the ID token.
+	expiresAt := UTCNow().Add(defaultJWTExpiry).Unix()
+	if exp, ok := idClaims["exp"].(float64); ok && int64(exp) < expiresAt {
+		expiresAt = int64(exp)
+	}
+	token, err := newWebToken(webClaims{
+		StandardClaims: jwtgo.StandardClaims{
+			ExpiresAt: expiresAt,
+			Issuer:    config.Issuer,
+			Subject:   subject,
+		},
+		Policies: policies,
+	})
+	if err != nil {
+		writeWebErrorResponse(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	openIDLoginPage.Execute(w, struct{ Token, Location string }{token, minioReservedBucketPath + "/"})
+}
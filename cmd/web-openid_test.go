@@ -0,0 +1,350 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/minio/minio-go/pkg/policy"
+	"github.com/minio/minio-go/pkg/set"
+)
+
+// newTestOpenIDProvider - starts a mock OpenID Connect provider which
+// authenticates every user as "user" with the given groups claim.
+func newTestOpenIDProvider(t TestErrHandler, groups []string) *httptest.Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var server *httptest.Server
+	nonces := make(map[string]string)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"jwks_uri":               server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		code := mustGetUUID()
+		nonces[code] = r.URL.Query().Get("nonce")
+		redirect := r.URL.Query().Get("redirect_uri") + "?" + url.Values{
+			"code":  {code},
+			"state": {r.URL.Query().Get("state")},
+		}.Encode()
+		http.Redirect(w, r, redirect, http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		nonce, ok := nonces[r.PostFormValue("code")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwtgo.NewWithClaims(jwtgo.SigningMethodRS256, jwtgo.MapClaims{
+			"iss":    server.URL,
+			"sub":    "user",
+			"aud":    "minio",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"nonce":  nonce,
+			"groups": groups,
+		})
+		token.Header["kid"] = "test"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func newTestOpenIDPolicy(effect string, actions []string, resources ...string) policy.BucketAccessPolicy {
+	return policy.BucketAccessPolicy{
+		Version: "2012-10-17",
+		Statements: []policy.Statement{{
+			Effect:    effect,
+			Actions:   set.CreateStringSet(actions...),
+			Resources: set.CreateStringSet(resources...),
+		}},
+	}
+}
+
+func TestOpenIDConfigValidate(t *testing.T) {
+	valid := openIDConfig{
+		Enable:      true,
+		Issuer:      "https://accounts.example.com",
+		ClientID:    "minio",
+		RedirectURI: "https://minio.example.com/minio/openid/callback",
+		Policies: map[string]policy.BucketAccessPolicy{
+			"readers": newTestOpenIDPolicy("Allow", []string{"s3:ListBucket", "s3:GetObject"}, "arn:aws:s3:::photos*"),
+		},
+	}
+	noIssuer := valid
+	noIssuer.Issuer = ""
+	badRedirect := valid
+	badRedirect.RedirectURI = "callback"
+	badAction := valid
+	badAction.Policies = map[string]policy.BucketAccessPolicy{
+		"admins": newTestOpenIDPolicy("Allow", []string{"s3:PutBucketNotification"}, "arn:aws:s3:::*"),
+	}
+	badResource := valid
+	badResource.Policies = map[string]policy.BucketAccessPolicy{
+		"admins": newTestOpenIDPolicy("Allow", []string{"s3:*"}, "photos"),
+	}
+	badEffect := valid
+	badEffect.Policies = map[string]policy.BucketAccessPolicy{
+		"admins": newTestOpenIDPolicy("Permit", []string{"s3:*"}, "arn:aws:s3:::*"),
+	}
+
+	testCases := []struct {
+		config  openIDConfig
+		success bool
+	}{
+		{openIDConfig{}, true},
+		{valid, true},
+		{noIssuer, false},
+		{badRedirect, false},
+		{badAction, false},
+		{badResource, false},
+		{badEffect, false},
+	}
+	for i, testCase := range testCases {
+		err := testCase.config.Validate()
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: Expected an error", i+1)
+		}
+	}
+}
+
+func TestOpenIDPolicies(t *testing.T) {
+	config := openIDConfig{
+		Policies: map[string]policy.BucketAccessPolicy{
+			"readers": {},
+			"writers": {},
+		},
+	}
+	testCases := []struct {
+		claims   jwtgo.MapClaims
+		expected []string
+	}{
+		{jwtgo.MapClaims{}, []string{}},
+		{jwtgo.MapClaims{"groups": "readers"}, []string{"readers"}},
+		{jwtgo.MapClaims{"groups": []interface{}{"writers", "others", "readers", 1}}, []string{"readers", "writers"}},
+		{jwtgo.MapClaims{"roles": "writers"}, []string{}},
+	}
+	for i, testCase := range testCases {
+		if policies := openIDPolicies(config, testCase.claims); !reflect.DeepEqual(policies, testCase.expected) {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, policies)
+		}
+	}
+}
+
+// Wrapper for calling OpenID login tests for both XL multiple disks and single node setup.
+func TestWebHandlerOpenIDLogin(t *testing.T) {
+	ExecObjectLayerTest(t, testWebHandlerOpenIDLogin)
+}
+
+// loginWithOpenID - runs the authorization code flow against the mock
+// provider and returns the session token.
+func loginWithOpenID(t TestErrHandler, apiRouter http.Handler) string {
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/minio/openid/login", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("Expected redirect to the provider, got %d", rec.Code)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec = httptest.NewRecorder()
+	req, err = http.NewRequest("GET", callback.RequestURI(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected successful login, got %d: %s", rec.Code, rec.Body.String())
+	}
+	match := regexp.MustCompile(`setItem\("token", "([^"]+)"\)`).FindStringSubmatch(rec.Body.String())
+	if match == nil {
+		t.Fatalf("No session token found in %s", rec.Body.String())
+	}
+	return match[1]
+}
+
+func testWebHandlerOpenIDLogin(obj ObjectLayer, instanceType string, t TestErrHandler) {
+	apiRouter := initTestWebRPCEndPoint(obj)
+
+	provider := newTestOpenIDProvider(t, []string{"photographers", "unknown"})
+	defer provider.Close()
+
+	// Login is refused while OpenID is disabled.
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/minio/openid/login", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotImplemented {
+		t.Fatalf("Expected %d while disabled, got %d", http.StatusNotImplemented, rec.Code)
+	}
+
+	globalServerConfig.OpenID = openIDConfig{
+		Enable:      true,
+		Issuer:      provider.URL,
+		ClientID:    "minio",
+		RedirectURI: "http://localhost:9000/minio/openid/callback",
+		Policies: map[string]policy.BucketAccessPolicy{
+			"photographers": newTestOpenIDPolicy("Allow",
+				[]string{"s3:ListBucket", "s3:GetObject", "s3:PutObject"}, "arn:aws:s3:::photos*"),
+		},
+	}
+	defer func() { globalServerConfig.OpenID = openIDConfig{} }()
+
+	for _, bucket := range []string{"photos", "private"} {
+		if err = obj.MakeBucketWithLocation(bucket, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	token := loginWithOpenID(t, apiRouter)
+
+	// Only the buckets granted by the policy are listed.
+	rec = httptest.NewRecorder()
+	req, err = newTestWebRPCRequest("Web.ListBuckets", token, WebGenericArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	listBucketsReply := &ListBucketsRep{}
+	if err = getTestWebRPCResponse(rec, &listBucketsReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(listBucketsReply.Buckets) != 1 || listBucketsReply.Buckets[0].Name != "photos" {
+		t.Fatalf("Expected only bucket photos to be listed, got %v", listBucketsReply.Buckets)
+	}
+
+	upload := func(bucket string) int {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("PUT", "/minio/upload/"+bucket+"/object", bytes.NewReader([]byte("content")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.ContentLength = int64(len("content"))
+		req.Header.Set("Authorization", "Bearer "+token)
+		apiRouter.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := upload("photos"); code != http.StatusOK {
+		t.Fatalf("Expected upload to photos to succeed, got %d", code)
+	}
+	if code := upload("private"); code != http.StatusForbidden {
+		t.Fatalf("Expected upload to private to be denied, got %d", code)
+	}
+
+	// URL tokens carry the policies of the session.
+	rec = httptest.NewRecorder()
+	req, err = newTestWebRPCRequest("Web.CreateURLToken", token, WebGenericArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	urlTokenReply := &URLTokenReply{}
+	if err = getTestWebRPCResponse(rec, &urlTokenReply); err != nil {
+		t.Fatal(err)
+	}
+	download := func(bucket string) int {
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/minio/download/"+bucket+"/object?token="+urlTokenReply.Token, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		apiRouter.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := download("photos"); code != http.StatusOK {
+		t.Fatalf("Expected download from photos to succeed, got %d", code)
+	}
+	if code := download("private"); code != http.StatusForbidden {
+		t.Fatalf("Expected download from private to be denied, got %d", code)
+	}
+
+	// Bucket policies may not be changed without s3:PutBucketPolicy.
+	rec = httptest.NewRecorder()
+	req, err = newTestWebRPCRequest("Web.SetBucketPolicy", token, SetBucketPolicyArgs{
+		BucketName: "photos",
+		Policy:     string(policy.BucketPolicyReadOnly),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiRouter.ServeHTTP(rec, req)
+	if err = getTestWebRPCResponse(rec, &WebGenericRep{}); err == nil || !strings.Contains(err.Error(), errAuthentication.Error()) {
+		t.Fatalf("Expected SetBucketPolicy to be denied, got %v", err)
+	}
+
+	// Sessions end once OpenID login is disabled.
+	globalServerConfig.OpenID.Enable = false
+	if code := upload("photos"); code != http.StatusForbidden {
+		t.Fatalf("Expected session to be rejected, got %d", code)
+	}
+}
@@ -90,6 +90,10 @@ func registerWebRouter(mux *router.Router) error {
 	webBrowserRouter.Methods("GET").Path("/download/{bucket}/{object:.+}").Queries("token", "{token:.*}").HandlerFunc(web.Download)
 	webBrowserRouter.Methods("POST").Path("/zip").Queries("token", "{token:.*}").HandlerFunc(web.DownloadZip)
 
+	// OpenID Connect login, redirects to the provider and back.
+	webBrowserRouter.Methods("GET").Path("/openid/login").HandlerFunc(web.OpenIDLogin)
+	webBrowserRouter.Methods("GET").Path("/openid/callback").HandlerFunc(web.OpenIDCallback)
+
 	// Add compression for assets.
 	h := http.FileServer(assetFS())
 	compressedAssets := handlers.CompressHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -287,6 +287,18 @@ func (xl xlObjects) DeleteBucket(bucket string) error {
 	// Notify all peers (including self) to update in-memory state
 	S3PeersUpdateBucketPolicy(bucket)
 
+	// Delete bucket ACL, if present - ignore any errors.
+	_ = removeBucketACL(bucket, xl)
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketACL(bucket)
+
+	// Delete bucket logging config, if present - ignore any errors.
+	_ = removeBucketLoggingConfig(bucket, xl)
+
+	// Notify all peers (including self) to update in-memory state
+	S3PeersUpdateBucketLogging(bucket)
+
 	// Delete notification config, if present - ignore any errors.
 	_ = removeNotificationConfig(bucket, xl)
 
@@ -343,3 +355,13 @@ func (xl xlObjects) IsNotificationSupported() bool {
 func (xl xlObjects) IsEncryptionSupported() bool {
 	return true
 }
+
+// IsACLSupported returns whether canned ACLs are applicable for this layer.
+func (xl xlObjects) IsACLSupported() bool {
+	return true
+}
+
+// IsLoggingSupported returns whether bucket access logging is applicable for this layer.
+func (xl xlObjects) IsLoggingSupported() bool {
+	return true
+}
@@ -513,7 +513,7 @@ func healObject(storageDisks []StorageAPI, bucket string, object string,
 	// Heal each part. erasureHealFile() will write the healed
 	// part to .minio/tmp/uuid/ which needs to be renamed later to
 	// the final location.
-	storage, err := NewErasureStorage(latestDisks, latestMeta.Erasure.DataBlocks,
+	storage, err := NewErasureStorage(latestDisks, latestMeta.Erasure.Algorithm, latestMeta.Erasure.DataBlocks,
 		latestMeta.Erasure.ParityBlocks, latestMeta.Erasure.BlockSize)
 	if err != nil {
 		return result, toObjectErr(err, bucket, object)
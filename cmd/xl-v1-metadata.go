@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash"
+	"net/http"
 	"path"
 	"sort"
 	"sync"
@@ -33,8 +34,6 @@ import (
 	"golang.org/x/crypto/blake2b"
 )
 
-const erasureAlgorithmKlauspost = "klauspost/reedsolomon/vandermonde"
-
 // DefaultBitrotAlgorithm is the default algorithm used for bitrot protection.
 var DefaultBitrotAlgorithm = HighwayHash256
 
@@ -208,6 +207,18 @@ func (e *ErasureInfo) AddChecksumInfo(ckSumInfo ChecksumInfo) {
 	e.Checksums = append(e.Checksums, ckSumInfo)
 }
 
+// ReadQuorum - returns the number of disks the object has to be read
+// from for its erasure code.
+func (e ErasureInfo) ReadQuorum() int {
+	return erasureReadQuorum(e.Algorithm, e.DataBlocks, e.ParityBlocks)
+}
+
+// WriteQuorum - returns the number of disks the object has to be
+// written to for its erasure code.
+func (e ErasureInfo) WriteQuorum() int {
+	return erasureWriteQuorum(e.Algorithm, e.DataBlocks, e.ParityBlocks)
+}
+
 // GetChecksumInfo - get checksum of a part.
 func (e ErasureInfo) GetChecksumInfo(partName string) (ckSum ChecksumInfo) {
 	// Return the checksum.
@@ -240,6 +251,8 @@ type xlMetaV1 struct {
 	Meta map[string]string `json:"meta,omitempty"`
 	// Captures all the individual object `xl.json`.
 	Parts []objectPartInfo `json:"parts,omitempty"`
+	// Remote copy of the object data once transitioned to a tier.
+	Tier *xlMetaTier `json:"tier,omitempty"`
 }
 
 // XL metadata constants.
@@ -263,7 +276,7 @@ func newXLMetaV1(object string, dataBlocks, parityBlocks int) (xlMeta xlMetaV1)
 	xlMeta.Format = xlMetaFormat
 	xlMeta.Minio.Release = ReleaseTag
 	xlMeta.Erasure = ErasureInfo{
-		Algorithm:    erasureAlgorithmKlauspost,
+		Algorithm:    erasureAlgorithmFor(dataBlocks, parityBlocks),
 		DataBlocks:   dataBlocks,
 		ParityBlocks: parityBlocks,
 		BlockSize:    blockSizeV1,
@@ -312,6 +325,15 @@ func (m xlMetaV1) ToObjectInfo(bucket, object string) ObjectInfo {
 	// response headers. e.g, X-Minio-* or X-Amz-*.
 	objInfo.UserDefined = cleanMetadata(m.Meta)
 
+	// Report the expiry of a restored copy of a transitioned object,
+	// or that it is being restored.
+	if m.isRestoring() {
+		objInfo.UserDefined[amzRestore] = `ongoing-request="true"`
+	} else if m.Tier != nil && !m.isRemote() {
+		objInfo.UserDefined[amzRestore] = fmt.Sprintf(`ongoing-request="false", expiry-date="%s"`,
+			m.Tier.RestoreExpiry.UTC().Format(http.TimeFormat))
+	}
+
 	// Success.
 	return objInfo
 }
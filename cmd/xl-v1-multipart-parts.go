@@ -0,0 +1,282 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/errors"
+)
+
+// Suffix of the metadata file saved next to each uploaded part.
+const xlPartMetaJSONSuffix = ".json"
+
+// xlMetaPartV1 - metadata of an uploaded part of a multipart upload,
+// saved as `part.N.json` next to the part file on each disk. Part
+// uploads never update the `xl.json` of the upload, such that parallel
+// uploads of parts do not contend. CompleteMultipartUpload merges the
+// metadata of all parts into `xl.json`.
+type xlMetaPartV1 struct {
+	Version string         `json:"version"`
+	Part    objectPartInfo `json:"part"`
+	ModTime time.Time      `json:"modTime"`
+	// Bitrot checksum of the part on this disk.
+	Checksum ChecksumInfo `json:"checksum"`
+}
+
+// partMetaJSONFile - returns the name of the metadata file of a part.
+func partMetaJSONFile(partName string) string {
+	return partName + xlPartMetaJSONSuffix
+}
+
+// isPartMetaJSONFile - returns true if name is the metadata file of a
+// part, i.e. `part.N.json`.
+func isPartMetaJSONFile(name string) bool {
+	if !strings.HasPrefix(name, "part.") || !strings.HasSuffix(name, xlPartMetaJSONSuffix) {
+		return false
+	}
+	number := strings.TrimSuffix(strings.TrimPrefix(name, "part."), xlPartMetaJSONSuffix)
+	_, err := strconv.Atoi(number)
+	return err == nil
+}
+
+// writePartMetadata - writes `part.N.json` to prefix on the disk.
+func writePartMetadata(disk StorageAPI, bucket, prefix string, partMeta xlMetaPartV1) error {
+	jsonFile := path.Join(prefix, partMetaJSONFile(partMeta.Part.Name))
+
+	metadataBytes, err := json.Marshal(&partMeta)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(disk.AppendFile(bucket, jsonFile, metadataBytes))
+}
+
+// readPartMetadata - reads `part.N.json` of partName from the disk.
+func readPartMetadata(disk StorageAPI, bucket, prefix, partName string) (partMeta xlMetaPartV1, err error) {
+	metadataBytes, err := disk.ReadAll(bucket, path.Join(prefix, partMetaJSONFile(partName)))
+	if err != nil {
+		return partMeta, errors.Trace(err)
+	}
+	if err = json.Unmarshal(metadataBytes, &partMeta); err != nil {
+		return partMeta, errors.Trace(err)
+	}
+	return partMeta, nil
+}
+
+// writeUniquePartMetadata - writes unique `part.N.json` content for
+// each disk in order.
+func writeUniquePartMetadata(disks []StorageAPI, bucket, prefix string, partMetas []xlMetaPartV1, quorum int) ([]StorageAPI, error) {
+	var wg = &sync.WaitGroup{}
+	var mErrs = make([]error, len(disks))
+
+	// Start writing `part.N.json` to all disks in parallel.
+	for index, disk := range disks {
+		if disk == nil {
+			mErrs[index] = errors.Trace(errDiskNotFound)
+			continue
+		}
+		wg.Add(1)
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			mErrs[index] = writePartMetadata(disk, bucket, prefix, partMetas[index])
+		}(index, disk)
+	}
+
+	// Wait for all the routines.
+	wg.Wait()
+
+	err := reduceWriteQuorumErrs(mErrs, objectOpIgnoredErrs, quorum)
+	return evalDisks(disks, mErrs), err
+}
+
+// readAllPartMetadata - reads `part.N.json` of partName from all disks
+// in parallel, returns the metadata and errors in the order of disks.
+func readAllPartMetadata(disks []StorageAPI, bucket, prefix, partName string) ([]xlMetaPartV1, []error) {
+	var wg = &sync.WaitGroup{}
+	partMetas := make([]xlMetaPartV1, len(disks))
+	errs := make([]error, len(disks))
+
+	for index, disk := range disks {
+		if disk == nil {
+			errs[index] = errors.Trace(errDiskNotFound)
+			continue
+		}
+		wg.Add(1)
+		go func(index int, disk StorageAPI) {
+			defer wg.Done()
+			partMetas[index], errs[index] = readPartMetadata(disk, bucket, prefix, partName)
+		}(index, disk)
+	}
+	wg.Wait()
+
+	return partMetas, errs
+}
+
+// partMetadataConcurrency - number of parts whose `part.N.json` is
+// read or removed concurrently, uploads may have up to 10000 parts.
+const partMetadataConcurrency = 32
+
+// forEachPart - calls fn for each index in [0, n), at most
+// partMetadataConcurrency calls run concurrently.
+func forEachPart(n int, fn func(index int)) {
+	var wg = &sync.WaitGroup{}
+	sem := make(chan struct{}, partMetadataConcurrency)
+	for index := 0; index < n; index++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(index int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(index)
+		}(index)
+	}
+	wg.Wait()
+}
+
+// readAllPartsMetadata - reads `part.N.json` of all partNames from all
+// disks, returns the metadata and errors indexed by part and then by
+// disk.
+func readAllPartsMetadata(disks []StorageAPI, bucket, prefix string, partNames []string) ([][]xlMetaPartV1, [][]error) {
+	partMetas := make([][]xlMetaPartV1, len(partNames))
+	errs := make([][]error, len(partNames))
+	forEachPart(len(partNames), func(index int) {
+		partMetas[index], errs[index] = readAllPartMetadata(disks, bucket, prefix, partNames[index])
+	})
+	return partMetas, errs
+}
+
+// pickLatestPartMeta - returns the most recently written `part.N.json`
+// read without error, false if there is none.
+func pickLatestPartMeta(partMetas []xlMetaPartV1, errs []error) (latest xlMetaPartV1, ok bool) {
+	for index, partMeta := range partMetas {
+		if errs[index] != nil {
+			continue
+		}
+		if !ok || partMeta.ModTime.After(latest.ModTime) {
+			latest, ok = partMeta, true
+		}
+	}
+	return latest, ok
+}
+
+// removePartMetadata - removes `part.N.json` of partName from all
+// disks, errors are ignored as `xl.json` is the authoritative source
+// of the parts of a completed upload.
+func removePartMetadata(disks []StorageAPI, bucket, prefix, partName string) {
+	var wg = &sync.WaitGroup{}
+	jsonFile := path.Join(prefix, partMetaJSONFile(partName))
+	for _, disk := range disks {
+		if disk == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(disk StorageAPI) {
+			defer wg.Done()
+			_ = disk.DeleteFile(bucket, jsonFile)
+		}(disk)
+	}
+	wg.Wait()
+}
+
+// removeAllPartsMetadata - removes `part.N.json` of all partNames from
+// all disks, errors are ignored as in removePartMetadata.
+func removeAllPartsMetadata(disks []StorageAPI, bucket, prefix string, partNames []string) {
+	forEachPart(len(partNames), func(index int) {
+		removePartMetadata(disks, bucket, prefix, partNames[index])
+	})
+}
+
+// readUploadParts - returns all parts uploaded so far on the disk,
+// sorted by part number. Parts recorded in `xl.json` of the upload by
+// earlier releases are merged with the `part.N.json` of each part.
+func readUploadParts(disk StorageAPI, uploadIDPath string) ([]objectPartInfo, error) {
+	parts, err := readXLMetaParts(disk, minioMetaMultipartBucket, uploadIDPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := disk.ListDir(minioMetaMultipartBucket, uploadIDPath)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var partNames []string
+	for _, entry := range entries {
+		if isPartMetaJSONFile(entry) {
+			partNames = append(partNames, strings.TrimSuffix(entry, xlPartMetaJSONSuffix))
+		}
+	}
+
+	partMetas := make([]xlMetaPartV1, len(partNames))
+	errs := make([]error, len(partNames))
+	forEachPart(len(partNames), func(index int) {
+		partMetas[index], errs[index] = readPartMetadata(disk, minioMetaMultipartBucket, uploadIDPath, partNames[index])
+	})
+	for i, partMeta := range partMetas {
+		if err = errs[i]; err != nil {
+			// Part was removed or replaced concurrently.
+			if errors.Cause(err) == errFileNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if index := objectPartIndex(parts, partMeta.Part.Number); index != -1 {
+			parts[index] = partMeta.Part
+			continue
+		}
+		parts = append(parts, partMeta.Part)
+	}
+	sort.Sort(byObjectPartNumber(parts))
+	return parts, nil
+}
+
+// listUploadParts - returns all parts uploaded so far for uploadID
+// from one of the disks picked at random.
+func (xl xlObjects) listUploadParts(bucket, object, uploadID string) (parts []objectPartInfo, err error) {
+	uploadIDPath := path.Join(bucket, object, uploadID)
+
+	var ignoredErrs []error
+	for _, disk := range xl.getLoadBalancedDisks() {
+		if disk == nil {
+			ignoredErrs = append(ignoredErrs, errDiskNotFound)
+			continue
+		}
+		parts, err = readUploadParts(disk, uploadIDPath)
+		if err == nil {
+			return parts, nil
+		}
+		// For any reason disk or bucket is not available continue
+		// and read from other disks.
+		if errors.IsErrIgnored(err, objMetadataOpIgnoredErrs...) {
+			ignoredErrs = append(ignoredErrs, err)
+			continue
+		}
+		// Error is not ignored, return right here.
+		return nil, err
+	}
+	// If all errors were ignored, reduce to maximal occurrence
+	// based on the read quorum.
+	readQuorum := len(xl.storageDisks) / 2
+	return nil, reduceReadQuorumErrs(ignoredErrs, nil, readQuorum)
+}
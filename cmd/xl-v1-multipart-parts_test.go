@@ -0,0 +1,160 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestIsPartMetaJSONFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{"part.1.json", true},
+		{"part.10000.json", true},
+		{"part.1", false},
+		{"xl.json", false},
+		{"part.x.json", false},
+		{"part..json", false},
+	}
+	for i, testCase := range testCases {
+		if got := isPartMetaJSONFile(testCase.name); got != testCase.expected {
+			t.Errorf("Test %d: expected %v for %s, got %v", i+1, testCase.expected, testCase.name, got)
+		}
+	}
+}
+
+// Tests that forEachPart visits every part and bounds the number of
+// concurrent calls.
+func TestForEachPart(t *testing.T) {
+	const n = 1000
+	var mu sync.Mutex
+	var running, maxRunning int
+	visited := make([]bool, n)
+	forEachPart(n, func(index int) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		visited[index] = true
+		mu.Unlock()
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+	for index, ok := range visited {
+		if !ok {
+			t.Fatalf("Part %d was not visited", index)
+		}
+	}
+	if maxRunning > partMetadataConcurrency {
+		t.Fatalf("Expected at most %d concurrent calls, got %d", partMetadataConcurrency, maxRunning)
+	}
+}
+
+// Tests that parts uploaded in parallel are saved in `part.N.json`
+// and merged into `xl.json` of the object on completion.
+func TestXLParallelPutObjectPart(t *testing.T) {
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer os.RemoveAll(root)
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+
+	bucketName := "bucket"
+	objectName := "object"
+	if err = obj.MakeBucketWithLocation(bucketName, ""); err != nil {
+		t.Fatal(err)
+	}
+	uploadID, err := obj.NewMultipartUpload(bucketName, objectName, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const numParts = 4
+	data := make([]byte, numParts*globalMinPartSize)
+	if _, err = io.ReadFull(rand.Reader, data); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	parts := make([]CompletePart, numParts)
+	errs := make([]error, numParts)
+	for i := range parts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			partData := data[i*globalMinPartSize : (i+1)*globalMinPartSize]
+			pi, pErr := obj.PutObjectPart(bucketName, objectName, uploadID, i+1, mustGetHashReader(t, bytes.NewReader(partData), int64(len(partData)), "", ""))
+			parts[i], errs[i] = CompletePart{PartNumber: pi.PartNumber, ETag: pi.ETag}, pErr
+		}(i)
+	}
+	wg.Wait()
+	for i, pErr := range errs {
+		if pErr != nil {
+			t.Fatalf("Part %d: unexpected error: %v", i+1, pErr)
+		}
+	}
+
+	uploadIDDir := filepath.Join(fsDirs[0], minioMetaMultipartBucket, bucketName, objectName, uploadID)
+	if _, err = os.Stat(filepath.Join(uploadIDDir, "part.1.json")); err != nil {
+		t.Fatalf("Expected part.1.json to be saved: %v", err)
+	}
+
+	lpi, err := obj.ListObjectParts(bucketName, objectName, uploadID, 0, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lpi.Parts) != numParts {
+		t.Fatalf("Expected %d parts, got %d", numParts, len(lpi.Parts))
+	}
+	for i, part := range lpi.Parts {
+		if part.PartNumber != i+1 || part.ETag != parts[i].ETag || part.Size != globalMinPartSize {
+			t.Errorf("Part %d: unexpected part info %#v", i+1, part)
+		}
+	}
+
+	if _, err = obj.CompleteMultipartUpload(bucketName, objectName, uploadID, parts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(fsDirs[0], bucketName, objectName, "part.1.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected part.1.json to be removed on completion, got %v", err)
+	}
+
+	content := new(bytes.Buffer)
+	if err = obj.GetObject(bucketName, objectName, 0, int64(len(data)), content, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content.Bytes(), data) {
+		t.Errorf("Object content does not match the uploaded parts")
+	}
+}
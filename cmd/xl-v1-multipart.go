@@ -199,6 +199,7 @@ func (xl xlObjects) removeObjectPart(bucket, object, uploadID, partName string)
 			// requests. xl.json is the authoritative source of truth on which parts constitute
 			// the object. The presence of parts that don't belong in the object doesn't affect correctness.
 			_ = disk.DeleteFile(minioMetaMultipartBucket, curpartPath)
+			_ = disk.DeleteFile(minioMetaMultipartBucket, partMetaJSONFile(curpartPath))
 		}(i, disk)
 	}
 	wg.Wait()
@@ -512,7 +513,7 @@ func (xl xlObjects) newMultipartUpload(bucket string, object string, meta map[st
 
 	// we now know the number of blocks this object needs for data and parity.
 	// establish the writeQuorum using this data
-	writeQuorum := dataBlocks + 1
+	writeQuorum := xlMeta.Erasure.WriteQuorum()
 
 	// If not set default to "application/octet-stream"
 	if meta["content-type"] == "" {
@@ -655,11 +656,12 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, d
 
 	// Hold the lock so that two parallel complete-multipart-uploads
 	// do not leave a stale uploads.json behind.
+	// Parts are uploaded in parallel, so only a read lock is needed.
 	objectMPartPathLock := xl.nsMutex.NewNSLock(minioMetaMultipartBucket, pathJoin(bucket, object))
-	if err := objectMPartPathLock.GetLock(globalOperationTimeout); err != nil {
+	if err := objectMPartPathLock.GetRLock(globalOperationTimeout); err != nil {
 		return pi, err
 	}
-	defer objectMPartPathLock.Unlock()
+	defer objectMPartPathLock.RUnlock()
 
 	var partsMetadata []xlMetaV1
 	var errs []error
@@ -721,7 +723,7 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, d
 		}
 	}
 
-	storage, err := NewErasureStorage(onlineDisks, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, xlMeta.Erasure.BlockSize)
+	storage, err := NewErasureStorage(onlineDisks, xlMeta.Erasure.Algorithm, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, xlMeta.Erasure.BlockSize)
 	if err != nil {
 		return pi, toObjectErr(err, bucket, object)
 	}
@@ -737,68 +739,62 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, d
 		return pi, errors.Trace(IncompleteBody{})
 	}
 
-	// post-upload check (write) lock
+	// post-upload check lock, held as a read lock such that parts
+	// of the same upload are committed in parallel. Uploads of the
+	// same part are serialized by the part lock.
 	postUploadIDLock := xl.nsMutex.NewNSLock(minioMetaMultipartBucket, uploadIDPath)
-	if err = postUploadIDLock.GetLock(globalOperationTimeout); err != nil {
+	if err = postUploadIDLock.GetRLock(globalOperationTimeout); err != nil {
 		return pi, err
 	}
-	defer postUploadIDLock.Unlock()
-
-	// Validate again if upload ID still exists.
-	if !xl.isUploadIDExists(bucket, object, uploadID) {
-		return pi, errors.Trace(InvalidUploadID{UploadID: uploadID})
-	}
+	defer postUploadIDLock.RUnlock()
 
-	// Rename temporary part file to its final location.
 	partPath := path.Join(uploadIDPath, partSuffix)
-	onlineDisks, err = renamePart(onlineDisks, minioMetaTmpBucket, tmpPartPath, minioMetaMultipartBucket, partPath, writeQuorum)
-	if err != nil {
-		return pi, toObjectErr(err, minioMetaMultipartBucket, partPath)
-	}
-
-	// Read metadata again because it might be updated with parallel upload of another part.
-	partsMetadata, errs = readAllXLMetadata(onlineDisks, minioMetaMultipartBucket, uploadIDPath)
-	reducedErr = reduceWriteQuorumErrs(errs, objectOpIgnoredErrs, writeQuorum)
-	if errors.Cause(reducedErr) == errXLWriteQuorum {
-		return pi, toObjectErr(reducedErr, bucket, object)
-	}
-
-	// Get current highest version based on re-read partsMetadata.
-	onlineDisks, modTime = listOnlineDisks(onlineDisks, partsMetadata, errs)
-
-	// Pick one from the first valid metadata.
-	xlMeta, err = pickValidXLMeta(partsMetadata, modTime)
-	if err != nil {
+	partLock := xl.nsMutex.NewNSLock(minioMetaMultipartBucket, partPath)
+	if err = partLock.GetLock(globalOperationTimeout); err != nil {
 		return pi, err
 	}
+	defer partLock.Unlock()
 
-	// Once part is successfully committed, proceed with updating XL metadata.
-	xlMeta.Stat.ModTime = UTCNow()
+	// Validate again if upload ID still exists.
+	if !xl.isUploadIDExists(bucket, object, uploadID) {
+		return pi, errors.Trace(InvalidUploadID{UploadID: uploadID})
+	}
 
 	md5hex := hex.EncodeToString(data.MD5Current())
 
-	// Add the current part.
-	xlMeta.AddObjectPart(partID, partSuffix, md5hex, file.Size)
-
-	for i, disk := range onlineDisks {
-		if disk == OfflineDisk {
-			continue
+	// Write a unique `part.N.json` for each disk carrying the checksum
+	// of the part on that disk, the `xl.json` of the upload is only
+	// updated by CompleteMultipartUpload.
+	partMetas := make([]xlMetaPartV1, len(onlineDisks))
+	for i := range partMetas {
+		partMetas[i] = xlMetaPartV1{
+			Version: xlMetaVersion,
+			Part: objectPartInfo{
+				Number: partID,
+				Name:   partSuffix,
+				ETag:   md5hex,
+				Size:   file.Size,
+			},
+			ModTime:  UTCNow(),
+			Checksum: ChecksumInfo{partSuffix, file.Algorithm, file.Checksums[i]},
 		}
-		partsMetadata[i].Parts = xlMeta.Parts
-		partsMetadata[i].Erasure.AddChecksumInfo(ChecksumInfo{partSuffix, file.Algorithm, file.Checksums[i]})
+	}
+	if onlineDisks, err = writeUniquePartMetadata(onlineDisks, minioMetaTmpBucket, tmpPart, partMetas, writeQuorum); err != nil {
+		return pi, toObjectErr(err, minioMetaTmpBucket, tmpPart)
 	}
 
-	// Write all the checksum metadata.
-	newUUID := mustGetUUID()
-	tempXLMetaPath := newUUID
-
-	// Writes a unique `xl.json` each disk carrying new checksum related information.
-	if onlineDisks, err = writeUniqueXLMetadata(onlineDisks, minioMetaTmpBucket, tempXLMetaPath, partsMetadata, writeQuorum); err != nil {
-		return pi, toObjectErr(err, minioMetaTmpBucket, tempXLMetaPath)
+	// Rename temporary part file to its final location.
+	onlineDisks, err = renamePart(onlineDisks, minioMetaTmpBucket, tmpPartPath, minioMetaMultipartBucket, partPath, writeQuorum)
+	if err != nil {
+		return pi, toObjectErr(err, minioMetaMultipartBucket, partPath)
 	}
 
-	if _, err = commitXLMetadata(onlineDisks, minioMetaTmpBucket, tempXLMetaPath, minioMetaMultipartBucket, uploadIDPath, writeQuorum); err != nil {
-		return pi, toObjectErr(err, minioMetaMultipartBucket, uploadIDPath)
+	// Rename `part.N.json` last, listing parts only sees the part once
+	// it is fully committed.
+	tmpPartMetaPath := path.Join(tmpPart, partMetaJSONFile(partSuffix))
+	partMetaPath := path.Join(uploadIDPath, partMetaJSONFile(partSuffix))
+	if _, err = renamePart(onlineDisks, minioMetaTmpBucket, tmpPartMetaPath, minioMetaMultipartBucket, partMetaPath, writeQuorum); err != nil {
+		return pi, toObjectErr(err, minioMetaMultipartBucket, partMetaPath)
 	}
 
 	fi, err := xl.statPart(bucket, object, uploadID, partSuffix)
@@ -815,14 +811,14 @@ func (xl xlObjects) PutObjectPart(bucket, object, uploadID string, partID int, d
 	}, nil
 }
 
-// listObjectParts - wrapper reading `xl.json` for a given object and
-// uploadID. Lists all the parts captured inside `xl.json` content.
+// listObjectParts - wrapper reading `xl.json` and `part.N.json` for a
+// given object and uploadID. Lists all the parts uploaded so far.
 func (xl xlObjects) listObjectParts(bucket, object, uploadID string, partNumberMarker, maxParts int) (lpi ListPartsInfo, e error) {
 	result := ListPartsInfo{}
 
 	uploadIDPath := path.Join(bucket, object, uploadID)
 
-	xlParts, err := xl.readXLMetaParts(minioMetaMultipartBucket, uploadIDPath)
+	xlParts, err := xl.listUploadParts(bucket, object, uploadID)
 	if err != nil {
 		return lpi, toObjectErr(err, minioMetaMultipartBucket, uploadIDPath)
 	}
@@ -991,53 +987,97 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 	// Order parts metadata in accordance with distribution order.
 	partsMetadata = shufflePartsMetadata(partsMetadata, xlMeta.Erasure.Distribution)
 
-	// Save current xl meta for validation.
-	var currentXLMeta = xlMeta
+	// Merge the parts recorded in `xl.json` by earlier releases with
+	// the `part.N.json` written for each part.
+	uploadedParts, err := xl.listUploadParts(bucket, object, uploadID)
+	if err != nil {
+		return oi, toObjectErr(err, minioMetaMultipartBucket, uploadIDPath)
+	}
 
 	// Allocate parts similar to incoming slice.
 	xlMeta.Parts = make([]objectPartInfo, len(parts))
 
+	// Read the `part.N.json` of all parts up front, reading them one
+	// part after the other is too slow for uploads with many parts.
+	partNames := make([]string, len(parts))
+	for i, part := range parts {
+		partNames[i] = fmt.Sprintf("part.%d", part.PartNumber)
+	}
+	allPartMetas, allPartErrs := readAllPartsMetadata(onlineDisks, minioMetaMultipartBucket, uploadIDPath, partNames)
+
 	// Validate each part and then commit to disk.
 	for i, part := range parts {
-		partIdx := objectPartIndex(currentXLMeta.Parts, part.PartNumber)
+		// Pick the `part.N.json` of the latest upload of the part
+		// and add the checksum of each disk. Disks with a missing or
+		// stale part are left out of the commit and healed later.
+		partMetas, pErrs := allPartMetas[i], allPartErrs[i]
+		partMeta, ok := pickLatestPartMeta(partMetas, pErrs)
+		partIdx := objectPartIndex(uploadedParts, part.PartNumber)
+		if ok && partIdx == -1 {
+			uploadedParts = append(uploadedParts, partMeta.Part)
+			partIdx = len(uploadedParts) - 1
+		} else if ok {
+			uploadedParts[partIdx] = partMeta.Part
+		}
 		// All parts should have same part number.
 		if partIdx == -1 {
 			return oi, errors.Trace(InvalidPart{})
 		}
 
 		// All parts should have same ETag as previously generated.
-		if currentXLMeta.Parts[partIdx].ETag != part.ETag {
+		if uploadedParts[partIdx].ETag != part.ETag {
 			return oi, errors.Trace(InvalidPart{})
 		}
 
 		// All parts except the last part has to be atleast 5MB.
-		if (i < len(parts)-1) && !isMinAllowedPartSize(currentXLMeta.Parts[partIdx].Size) {
+		if (i < len(parts)-1) && !isMinAllowedPartSize(uploadedParts[partIdx].Size) {
 			return oi, errors.Trace(PartTooSmall{
 				PartNumber: part.PartNumber,
-				PartSize:   currentXLMeta.Parts[partIdx].Size,
+				PartSize:   uploadedParts[partIdx].Size,
 				PartETag:   part.ETag,
 			})
 		}
 
+		if ok {
+			for index := range onlineDisks {
+				if pErrs[index] != nil || partMetas[index].Part.ETag != partMeta.Part.ETag {
+					onlineDisks[index] = nil
+					continue
+				}
+				partsMetadata[index].Erasure.AddChecksumInfo(partMetas[index].Checksum)
+			}
+		}
+
 		// Last part could have been uploaded as 0bytes, do not need
 		// to save it in final `xl.json`.
-		if (i == len(parts)-1) && currentXLMeta.Parts[partIdx].Size == 0 {
+		if (i == len(parts)-1) && uploadedParts[partIdx].Size == 0 {
 			xlMeta.Parts = xlMeta.Parts[:i] // Skip the part.
 			continue
 		}
 
 		// Save for total object size.
-		objectSize += currentXLMeta.Parts[partIdx].Size
+		objectSize += uploadedParts[partIdx].Size
 
 		// Add incoming parts.
 		xlMeta.Parts[i] = objectPartInfo{
 			Number: part.PartNumber,
 			ETag:   part.ETag,
-			Size:   currentXLMeta.Parts[partIdx].Size,
+			Size:   uploadedParts[partIdx].Size,
 			Name:   fmt.Sprintf("part.%d", part.PartNumber),
 		}
 	}
 
+	// Disks which lost a part in the meantime may not carry the object.
+	diskErrs := make([]error, len(onlineDisks))
+	for index, disk := range onlineDisks {
+		if disk == nil {
+			diskErrs[index] = errDiskNotFound
+		}
+	}
+	if err = reduceWriteQuorumErrs(diskErrs, objectOpIgnoredErrs, writeQuorum); err != nil {
+		return oi, toObjectErr(err, bucket, object)
+	}
+
 	// Save the final object size and modtime.
 	xlMeta.Stat.Size = objectSize
 	xlMeta.Stat.ModTime = UTCNow()
@@ -1067,6 +1107,15 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		return oi, toObjectErr(rErr, minioMetaMultipartBucket, uploadIDPath)
 	}
 
+	// Do not replace the object if the write lock was lost meanwhile,
+	// another writer may hold it by now.
+	if isLockLost(destLock.Lost()) {
+		return oi, toObjectErr(errors.Trace(errLockLost), bucket, object)
+	}
+
+	// Remember the remote copy of the replaced object.
+	remote := xl.getObjectTier(bucket, object)
+
 	if xl.isObject(bucket, object) {
 		// Rename if an object already exists to temporary location.
 		newUniqueID := mustGetUUID()
@@ -1083,8 +1132,16 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		}
 	}
 
+	// `xl.json` is now the authoritative source of the parts, remove
+	// `part.N.json` of the committed parts from all disks.
+	committedParts := make([]string, len(xlMeta.Parts))
+	for i, part := range xlMeta.Parts {
+		committedParts[i] = part.Name
+	}
+	removeAllPartsMetadata(xl.storageDisks, minioMetaMultipartBucket, uploadIDPath, committedParts)
+
 	// Remove parts that weren't present in CompleteMultipartUpload request.
-	for _, curpart := range currentXLMeta.Parts {
+	for _, curpart := range uploadedParts {
 		if objectPartIndex(xlMeta.Parts, curpart.Number) == -1 {
 			// Delete the missing part files. e.g,
 			// Request 1: NewMultipart
@@ -1101,6 +1158,9 @@ func (xl xlObjects) CompleteMultipartUpload(bucket string, object string, upload
 		return oi, toObjectErr(err, bucket, object)
 	}
 
+	// Delete the remote copy of the replaced object.
+	removeTierObject(bucket, object, remote)
+
 	// Hold the lock so that two parallel
 	// complete-multipart-uploads do not leave a stale
 	// uploads.json behind.
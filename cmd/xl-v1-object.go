@@ -182,11 +182,17 @@ func (xl xlObjects) CopyObject(srcBucket, srcObject, dstBucket, dstObject string
 		return oi, toObjectErr(errors.Trace(err), dstBucket, dstObject)
 	}
 
-	objInfo, err := xl.putObject(dstBucket, dstObject, hashReader, metadata)
+	// Remember the remote copy of the replaced object.
+	remote := xl.getObjectTier(dstBucket, dstObject)
+
+	objInfo, err := xl.putObject(dstBucket, dstObject, hashReader, metadata, objectDWLock.Lost())
 	if err != nil {
 		return oi, toObjectErr(err, dstBucket, dstObject)
 	}
 
+	// Delete the remote copy of the replaced object.
+	removeTierObject(dstBucket, dstObject, remote)
+
 	// Explicitly close the reader.
 	pipeReader.Close()
 
@@ -270,6 +276,11 @@ func (xl xlObjects) getObject(bucket, object string, startOffset int64, length i
 		return errors.Trace(InvalidRange{startOffset, length, xlMeta.Stat.Size})
 	}
 
+	// Stream the data of transitioned objects from their tier.
+	if xlMeta.isRemote() {
+		return xl.getRemoteObject(xlMeta.Tier, startOffset, length, writer)
+	}
+
 	// Get start part index and offset.
 	partIndex, partOffset, err := xlMeta.ObjectToPartOffset(startOffset)
 	if err != nil {
@@ -289,7 +300,7 @@ func (xl xlObjects) getObject(bucket, object string, startOffset int64, length i
 	}
 
 	var totalBytesRead int64
-	storage, err := NewErasureStorage(onlineDisks, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, xlMeta.Erasure.BlockSize)
+	storage, err := NewErasureStorage(onlineDisks, xlMeta.Erasure.Algorithm, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, xlMeta.Erasure.BlockSize)
 	if err != nil {
 		return toObjectErr(err, bucket, object)
 	}
@@ -427,26 +438,8 @@ func (xl xlObjects) getObjectInfo(bucket, object string) (objInfo ObjectInfo, er
 		return objInfo, err
 	}
 
-	objInfo = ObjectInfo{
-		IsDir:           false,
-		Bucket:          bucket,
-		Name:            object,
-		Size:            xlMeta.Stat.Size,
-		ModTime:         xlMeta.Stat.ModTime,
-		ContentType:     xlMeta.Meta["content-type"],
-		ContentEncoding: xlMeta.Meta["content-encoding"],
-	}
-
-	// Extract etag.
-	objInfo.ETag = extractETag(xlMeta.Meta)
-
-	// etag/md5Sum has already been extracted. We need to
-	// remove to avoid it from appearing as part of
-	// response headers. e.g, X-Minio-* or X-Amz-*.
-	objInfo.UserDefined = cleanMetadata(xlMeta.Meta)
-
 	// Success.
-	return objInfo, nil
+	return xlMeta.ToObjectInfo(bucket, object), nil
 }
 
 func undoRename(disks []StorageAPI, srcBucket, srcEntry, dstBucket, dstEntry string, isDir bool, errs []error) {
@@ -552,11 +545,23 @@ func (xl xlObjects) PutObject(bucket string, object string, data *hash.Reader, m
 		return objInfo, err
 	}
 	defer objectLock.Unlock()
-	return xl.putObject(bucket, object, data, metadata)
+
+	// Remember the remote copy of the replaced object.
+	remote := xl.getObjectTier(bucket, object)
+
+	objInfo, err = xl.putObject(bucket, object, data, metadata, objectLock.Lost())
+	if err != nil {
+		return objInfo, err
+	}
+
+	// Delete the remote copy of the replaced object.
+	removeTierObject(bucket, object, remote)
+	return objInfo, nil
 }
 
-// putObject wrapper for xl PutObject
-func (xl xlObjects) putObject(bucket string, object string, data *hash.Reader, metadata map[string]string) (objInfo ObjectInfo, err error) {
+// putObject wrapper for xl PutObject, lostCh is the lost channel of the
+// write lock held on the object.
+func (xl xlObjects) putObject(bucket string, object string, data *hash.Reader, metadata map[string]string, lostCh <-chan struct{}) (objInfo ObjectInfo, err error) {
 	uniqueID := mustGetUUID()
 	tempObj := uniqueID
 
@@ -568,9 +573,11 @@ func (xl xlObjects) putObject(bucket string, object string, data *hash.Reader, m
 	// Get parity and data drive count based on storage class metadata
 	dataDrives, parityDrives := getRedundancyCount(metadata[amzStorageClass], len(xl.storageDisks))
 
+	xlMeta := newXLMetaV1(object, dataDrives, parityDrives)
+
 	// we now know the number of blocks this object needs for data and parity.
-	// writeQuorum is dataBlocks + 1
-	writeQuorum := dataDrives + 1
+	// writeQuorum depends on the erasure code of the object.
+	writeQuorum := xlMeta.Erasure.WriteQuorum()
 
 	// Delete temporary object in the event of failure.
 	// If PutObject succeeded there would be no temporary
@@ -623,8 +630,6 @@ func (xl xlObjects) putObject(bucket string, object string, data *hash.Reader, m
 	// Initialize parts metadata
 	partsMetadata := make([]xlMetaV1, len(xl.storageDisks))
 
-	xlMeta := newXLMetaV1(object, dataDrives, parityDrives)
-
 	// Initialize xl meta.
 	for index := range partsMetadata {
 		partsMetadata[index] = xlMeta
@@ -636,7 +641,7 @@ func (xl xlObjects) putObject(bucket string, object string, data *hash.Reader, m
 	// Total size of the written object
 	var sizeWritten int64
 
-	storage, err := NewErasureStorage(onlineDisks, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, xlMeta.Erasure.BlockSize)
+	storage, err := NewErasureStorage(onlineDisks, xlMeta.Erasure.Algorithm, xlMeta.Erasure.DataBlocks, xlMeta.Erasure.ParityBlocks, xlMeta.Erasure.BlockSize)
 	if err != nil {
 		return ObjectInfo{}, toObjectErr(err, bucket, object)
 	}
@@ -713,6 +718,12 @@ func (xl xlObjects) putObject(bucket string, object string, data *hash.Reader, m
 		}
 	}
 
+	// Do not replace the object if the write lock was lost meanwhile,
+	// another writer may hold it by now.
+	if isLockLost(lostCh) {
+		return ObjectInfo{}, toObjectErr(errors.Trace(errLockLost), bucket, object)
+	}
+
 	if xl.isObject(bucket, object) {
 		// Rename if an object already exists to temporary location.
 		newUniqueID := mustGetUUID()
@@ -841,11 +852,17 @@ func (xl xlObjects) DeleteObject(bucket, object string) (err error) {
 		return errors.Trace(ObjectNotFound{bucket, object})
 	} // else proceed to delete the object.
 
+	// Remember the remote copy of a transitioned object.
+	remote := xl.getObjectTier(bucket, object)
+
 	// Delete the object on all disks.
 	if err = xl.deleteObject(bucket, object); err != nil {
 		return toObjectErr(err, bucket, object)
 	}
 
+	// Delete the remote copy of the deleted object.
+	removeTierObject(bucket, object, remote)
+
 	// Success.
 	return nil
 }
@@ -0,0 +1,421 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/errors"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Header reporting the expiry of a restored transitioned object.
+const amzRestore = "x-amz-restore"
+
+// Time after which a restore which did not finish, e.g. because the
+// server restarted meanwhile, no longer blocks a new restore.
+const restoreObjectTimeout = time.Hour
+
+// xlMetaTier - stub of a transitioned object in `xl.json`, pointing to
+// the copy of the object data in a tier. Only the stub is kept on the
+// local disks unless the object was restored, in which case the local
+// copy of the object data is removed again after RestoreExpiry.
+// RestoreStarted is set while the object data is being restored.
+type xlMetaTier struct {
+	Name           string    `json:"name"`
+	Bucket         string    `json:"bucket"`
+	Object         string    `json:"object"`
+	RestoreExpiry  time.Time `json:"restoreExpiry"`
+	RestoreStarted time.Time `json:"restoreStarted"`
+}
+
+// isRemote - returns true if the object data is only in its tier.
+func (m xlMetaV1) isRemote() bool {
+	return m.Tier != nil && m.Tier.RestoreExpiry.IsZero()
+}
+
+// isRestoring - returns true if the object data is being restored
+// from its tier.
+func (m xlMetaV1) isRestoring() bool {
+	return m.isRemote() && !m.Tier.RestoreStarted.IsZero() &&
+		UTCNow().Sub(m.Tier.RestoreStarted) < restoreObjectTimeout
+}
+
+// isTieringConfigured - returns true if any tier is configured.
+func isTieringConfigured() bool {
+	globalServerConfigMu.RLock()
+	defer globalServerConfigMu.RUnlock()
+	return globalServerConfig != nil && len(globalServerConfig.Tiering.Tiers) > 0
+}
+
+// getObjectTier - returns the tier copy of a transitioned object, nil
+// if tiering is not configured or the object is not transitioned.
+func (xl xlObjects) getObjectTier(bucket, object string) *xlMetaTier {
+	if !isTieringConfigured() {
+		return nil
+	}
+	xlMeta, err := xl.getObjectXLMeta(bucket, object)
+	if err != nil {
+		return nil
+	}
+	return xlMeta.Tier
+}
+
+// removeTierObject - deletes the tier copy of an object which was
+// deleted or replaced, the object is gone even if this fails.
+func removeTierObject(bucket, object string, remote *xlMetaTier) {
+	if remote == nil {
+		return
+	}
+	errorIf(deleteTierObject(remote), "Unable to delete %s/%s from tier %s", bucket, object, remote.Name)
+}
+
+// getObjectXLMeta - returns the latest valid `xl.json` of an object.
+func (xl xlObjects) getObjectXLMeta(bucket, object string) (xlMeta xlMetaV1, err error) {
+	metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+
+	readQuorum, _, err := objectQuorumFromMeta(xl, metaArr, errs)
+	if err != nil {
+		return xlMeta, err
+	}
+	if reducedErr := reduceReadQuorumErrs(errs, objectOpIgnoredErrs, readQuorum); reducedErr != nil {
+		return xlMeta, reducedErr
+	}
+
+	_, modTime := listOnlineDisks(xl.storageDisks, metaArr, errs)
+	return pickValidXLMeta(metaArr, modTime)
+}
+
+// updateObjectXLMeta - applies update to the `xl.json` of an object on
+// all disks with the latest `xl.json`, keeping the fields which differ
+// between disks like checksums. Returns the `xl.json` before update.
+func (xl xlObjects) updateObjectXLMeta(bucket, object string, update func(*xlMetaV1)) (xlMeta xlMetaV1, err error) {
+	metaArr, errs := readAllXLMetadata(xl.storageDisks, bucket, object)
+
+	readQuorum, writeQuorum, err := objectQuorumFromMeta(xl, metaArr, errs)
+	if err != nil {
+		return xlMeta, err
+	}
+	if reducedErr := reduceReadQuorumErrs(errs, objectOpIgnoredErrs, readQuorum); reducedErr != nil {
+		return xlMeta, reducedErr
+	}
+
+	onlineDisks, modTime := listOnlineDisks(xl.storageDisks, metaArr, errs)
+	if xlMeta, err = pickValidXLMeta(metaArr, modTime); err != nil {
+		return xlMeta, err
+	}
+
+	for index := range metaArr {
+		if onlineDisks[index] != nil {
+			update(&metaArr[index])
+		}
+	}
+
+	tempObj := mustGetUUID()
+
+	// Write unique `xl.json` for each disk.
+	if onlineDisks, err = writeUniqueXLMetadata(onlineDisks, minioMetaTmpBucket, tempObj, metaArr, writeQuorum); err != nil {
+		return xlMeta, err
+	}
+	// Rename atomically `xl.json` from tmp location to destination for each disk.
+	if _, err = renameXLMetadata(onlineDisks, minioMetaTmpBucket, tempObj, bucket, object, writeQuorum); err != nil {
+		return xlMeta, err
+	}
+	return xlMeta, nil
+}
+
+// removeObjectData - removes the part files of an object from all
+// disks. Errors are ignored, `xl.json` no longer refers to the parts.
+func (xl xlObjects) removeObjectData(bucket, object string, parts []objectPartInfo) {
+	var wg sync.WaitGroup
+	for _, disk := range xl.storageDisks {
+		if disk == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(disk StorageAPI) {
+			defer wg.Done()
+			for _, part := range parts {
+				_ = disk.DeleteFile(bucket, pathJoin(object, part.Name))
+			}
+		}(disk)
+	}
+	wg.Wait()
+}
+
+// keepRemoteOnly - replaces the object data by the stub pointing to
+// remote, unless the object was modified after modTime.
+func (xl xlObjects) keepRemoteOnly(bucket, object string, modTime time.Time, remote xlMetaTier) error {
+	objectLock := xl.nsMutex.NewNSLock(bucket, object)
+	if err := objectLock.GetLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	defer objectLock.Unlock()
+
+	xlMeta, err := xl.getObjectXLMeta(bucket, object)
+	if err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	if !xlMeta.Stat.ModTime.Equal(modTime) || xlMeta.isRemote() {
+		// Object was replaced or transitioned meanwhile.
+		return nil
+	}
+
+	remote.RestoreExpiry = time.Time{}
+	xlMeta, err = xl.updateObjectXLMeta(bucket, object, func(m *xlMetaV1) {
+		m.Tier = &remote
+		m.Parts = nil
+		m.Erasure.Checksums = nil
+	})
+	if err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	xl.removeObjectData(bucket, object, xlMeta.Parts)
+	return nil
+}
+
+// transitionObject - copies the data of an object to tierName and keeps
+// only a stub on the local disks. A restored copy of an already
+// transitioned object is removed once it expired.
+func (xl xlObjects) transitionObject(bucket, object, tierName string, now time.Time) error {
+	objectLock := xl.nsMutex.NewNSLock(bucket, object)
+	if err := objectLock.GetRLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	xlMeta, err := xl.getObjectXLMeta(bucket, object)
+	if err != nil {
+		objectLock.RUnlock()
+		return toObjectErr(err, bucket, object)
+	}
+
+	if xlMeta.Tier != nil {
+		objectLock.RUnlock()
+		if xlMeta.isRemote() || now.Before(xlMeta.Tier.RestoreExpiry) {
+			return nil
+		}
+		return xl.keepRemoteOnly(bucket, object, xlMeta.Stat.ModTime, *xlMeta.Tier)
+	}
+	// Nothing to keep in the tier for empty objects.
+	if xlMeta.Stat.Size == 0 {
+		objectLock.RUnlock()
+		return nil
+	}
+
+	tier, objLayer, err := getTier(tierName)
+	if err != nil {
+		objectLock.RUnlock()
+		return err
+	}
+	remote := xlMetaTier{
+		Name:   tierName,
+		Bucket: tier.Bucket,
+		Object: tierObjectName(tier, bucket, object),
+	}
+
+	// Copy the object data to the tier while holding the read lock.
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		gerr := xl.getObject(bucket, object, 0, xlMeta.Stat.Size, pipeWriter, "")
+		pipeWriter.CloseWithError(gerr)
+	}()
+	hashReader, err := hash.NewReader(pipeReader, xlMeta.Stat.Size, "", "")
+	if err != nil {
+		pipeReader.Close()
+		objectLock.RUnlock()
+		return errors.Trace(err)
+	}
+	metadata := map[string]string{"content-type": xlMeta.Meta["content-type"]}
+	_, err = objLayer.PutObject(remote.Bucket, remote.Object, hashReader, metadata)
+	pipeReader.Close()
+	objectLock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return xl.keepRemoteOnly(bucket, object, xlMeta.Stat.ModTime, remote)
+}
+
+// getRemoteObject - streams the data of a transitioned object from its
+// tier.
+func (xl xlObjects) getRemoteObject(remote *xlMetaTier, startOffset, length int64, writer io.Writer) error {
+	if length == 0 {
+		return nil
+	}
+	_, objLayer, err := getTier(remote.Name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return objLayer.GetObject(remote.Bucket, remote.Object, startOffset, length, writer, "")
+}
+
+// RestoreObject - starts restoring the data of a transitioned object
+// from its tier to the local disks for the given number of days, the
+// object reports `ongoing-request="true"` in x-amz-restore until the
+// restore finished. Restoring an already restored object extends the
+// expiry of the restored copy.
+func (xl xlObjects) RestoreObject(bucket, object string, days int) error {
+	if err := checkGetObjArgs(bucket, object); err != nil {
+		return err
+	}
+
+	objectLock := xl.nsMutex.NewNSLock(bucket, object)
+	if err := objectLock.GetLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	defer objectLock.Unlock()
+
+	xlMeta, err := xl.getObjectXLMeta(bucket, object)
+	if err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+	if xlMeta.Tier == nil {
+		return errors.Trace(InvalidObjectState{Bucket: bucket, Object: object})
+	}
+	if xlMeta.isRestoring() {
+		return errors.Trace(RestoreAlreadyInProgress{Bucket: bucket, Object: object})
+	}
+
+	remote := *xlMeta.Tier
+	if !xlMeta.isRemote() {
+		remote.RestoreExpiry = UTCNow().Add(time.Duration(days) * 24 * time.Hour)
+		_, err = xl.updateObjectXLMeta(bucket, object, func(m *xlMetaV1) {
+			m.Tier = &remote
+		})
+		return toObjectErr(err, bucket, object)
+	}
+
+	if _, _, err = getTier(remote.Name); err != nil {
+		return errors.Trace(err)
+	}
+	remote.RestoreStarted = UTCNow()
+	if _, err = xl.updateObjectXLMeta(bucket, object, func(m *xlMetaV1) {
+		m.Tier = &remote
+	}); err != nil {
+		return toObjectErr(err, bucket, object)
+	}
+
+	go func() {
+		rerr := xl.restoreObject(bucket, object, days, xlMeta.Stat.Size, remote)
+		errorIf(rerr, "Unable to restore %s/%s from tier %s", bucket, object, remote.Name)
+	}()
+	return nil
+}
+
+// restoreObject - copies the data of a transitioned object from its
+// tier to the local disks, unless the object was replaced or restored
+// meanwhile. The data is staged on a single disk first, to not hold
+// the object lock while reading from the tier. The restore started at
+// remote.RestoreStarted is marked as finished even on failure, so that
+// it can be retried.
+func (xl xlObjects) restoreObject(bucket, object string, days int, size int64, remote xlMetaTier) error {
+	disk, staged, err := xl.stageTierObject(&remote, size)
+	if staged != "" {
+		defer disk.DeleteFile(minioMetaTmpBucket, staged)
+	}
+
+	objectLock := xl.nsMutex.NewNSLock(bucket, object)
+	if lerr := objectLock.GetLock(globalObjectTimeout); lerr != nil {
+		return lerr
+	}
+	defer objectLock.Unlock()
+
+	xlMeta, merr := xl.getObjectXLMeta(bucket, object)
+	if merr != nil {
+		return toObjectErr(merr, bucket, object)
+	}
+	if !xlMeta.isRemote() || xlMeta.Tier.Name != remote.Name || xlMeta.Tier.Object != remote.Object ||
+		!xlMeta.Tier.RestoreStarted.Equal(remote.RestoreStarted) {
+		// Object was replaced or restored meanwhile.
+		return nil
+	}
+
+	if err == nil {
+		err = xl.putStagedObject(bucket, object, xlMeta, disk, staged, objectLock.Lost())
+	}
+	remote.RestoreStarted = time.Time{}
+	if err != nil {
+		_, uerr := xl.updateObjectXLMeta(bucket, object, func(m *xlMetaV1) {
+			m.Tier = &remote
+		})
+		errorIf(uerr, "Unable to reset restore of %s/%s", bucket, object)
+		return err
+	}
+
+	remote.RestoreExpiry = UTCNow().Add(time.Duration(days) * 24 * time.Hour)
+	_, err = xl.updateObjectXLMeta(bucket, object, func(m *xlMetaV1) {
+		m.Stat.ModTime = xlMeta.Stat.ModTime
+		m.Meta = xlMeta.Meta
+		m.Tier = &remote
+	})
+	return toObjectErr(err, bucket, object)
+}
+
+// stageTierObject - copies the data of a transitioned object from its
+// tier to a temporary file on the first available disk. Returns the
+// disk and the name of the file, which is set if it was created even
+// on failure.
+func (xl xlObjects) stageTierObject(remote *xlMetaTier, size int64) (StorageAPI, string, error) {
+	_, objLayer, err := getTier(remote.Name)
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	var disk StorageAPI
+	for _, disk = range xl.storageDisks {
+		if disk != nil {
+			break
+		}
+	}
+	if disk == nil {
+		return nil, "", errors.Trace(errDiskNotFound)
+	}
+
+	staged := mustGetUUID()
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		gerr := objLayer.GetObject(remote.Bucket, remote.Object, 0, size, pipeWriter, "")
+		pipeWriter.CloseWithError(gerr)
+	}()
+	err = disk.CreateFile(minioMetaTmpBucket, staged, size, pipeReader)
+	pipeReader.Close()
+	return disk, staged, err
+}
+
+// putStagedObject - writes the data of a transitioned object staged by
+// stageTierObject to the local disks, with the object lock held by the
+// caller.
+func (xl xlObjects) putStagedObject(bucket, object string, xlMeta xlMetaV1, disk StorageAPI, staged string, lostCh <-chan struct{}) error {
+	reader, err := disk.ReadFileStream(minioMetaTmpBucket, staged, 0, xlMeta.Stat.Size, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer reader.Close()
+	hashReader, err := hash.NewReader(reader, xlMeta.Stat.Size, "", "")
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// putObject modifies the metadata, the original metadata and
+	// modification time are put back once the data is copied.
+	metadata := make(map[string]string, len(xlMeta.Meta))
+	for k, v := range xlMeta.Meta {
+		metadata[k] = v
+	}
+	_, err = xl.putObject(bucket, object, hashReader, metadata, lostCh)
+	return toObjectErr(err, bucket, object)
+}
@@ -0,0 +1,323 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/minio/pkg/errors"
+)
+
+// registerTestGateway - registers a gateway command name serving
+// objLayer, the returned function unregisters it.
+func registerTestGateway(t *testing.T, name string, objLayer ObjectLayer) func() {
+	err := RegisterGatewayCommand(cli.Command{Name: name}, func(arg string) Gateway {
+		return &testGateway{name, objLayer}
+	})
+	if err != nil {
+		t.Fatalf("RegisterGatewayCommand got unexpected error: %s", err)
+	}
+	return func() {
+		gatewaysMu.Lock()
+		delete(gateways, name)
+		gatewaysMu.Unlock()
+	}
+}
+
+// setTestTier - configures a tier named cold served by tierLayer,
+// registered as gateway name, with rule transitioning objects to it.
+// The returned function removes the tier again.
+func setTestTier(t *testing.T, name string, tierLayer ObjectLayer, rule transitionRule) func() {
+	unregister := registerTestGateway(t, name, tierLayer)
+	globalServerConfig.Tiering = tieringConfig{
+		Tiers: map[string]tierConfig{"cold": {Type: name, Bucket: "tier", Prefix: "minio"}},
+		Rules: []transitionRule{rule},
+	}
+	return func() {
+		globalServerConfig.Tiering = tieringConfig{}
+		tierLayersMu.Lock()
+		delete(tierLayers, "cold")
+		tierLayersMu.Unlock()
+		unregister()
+	}
+}
+
+// waitForRestore - waits until the restore of a transitioned object
+// finished.
+func waitForRestore(t *testing.T, obj ObjectLayer, bucket, object string) {
+	for i := 0; i < 500; i++ {
+		objInfo, err := obj.GetObjectInfo(bucket, object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(objInfo.UserDefined[amzRestore], `ongoing-request="false"`) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %s/%s to be restored", bucket, object)
+}
+
+func TestTieringConfigValidate(t *testing.T) {
+	tiers := map[string]tierConfig{"cold": {Type: "s3", Bucket: "cold"}}
+	testCases := []struct {
+		config     tieringConfig
+		shouldFail bool
+	}{
+		{tieringConfig{}, false},
+		{tieringConfig{Tiers: tiers, Rules: []transitionRule{{Bucket: "logs", Days: 30, Tier: "cold"}}}, false},
+		{tieringConfig{Tiers: map[string]tierConfig{"cold": {Bucket: "cold"}}}, true},
+		{tieringConfig{Tiers: map[string]tierConfig{"cold": {Type: "s3"}}}, true},
+		{tieringConfig{Tiers: tiers, Rules: []transitionRule{{Days: 30, Tier: "cold"}}}, true},
+		{tieringConfig{Tiers: tiers, Rules: []transitionRule{{Bucket: "logs", Tier: "cold"}}}, true},
+		{tieringConfig{Tiers: tiers, Rules: []transitionRule{{Bucket: "logs", Days: 30, Tier: "warm"}}}, true},
+	}
+	for i, testCase := range testCases {
+		err := testCase.config.Validate()
+		if err != nil && !testCase.shouldFail {
+			t.Errorf("Test %d: should pass but it failed with: %v", i+1, err)
+		}
+		if err == nil && testCase.shouldFail {
+			t.Errorf("Test %d: should fail but it passed", i+1)
+		}
+	}
+}
+
+// Tests transitioning an object to a tier backed by FS, reading it
+// from the tier, restoring it and expiring the restored copy.
+func TestXLTransitionObject(t *testing.T) {
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer os.RemoveAll(root)
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	xl := *obj.(*xlObjects)
+
+	tierDir, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(tierDir)
+	tierLayer, err := newFSObjectLayer(tierDir[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tierLayer.MakeBucketWithLocation("tier", ""); err != nil {
+		t.Fatal(err)
+	}
+	rule := transitionRule{Bucket: "bucket", Prefix: "cold/", Days: 1, Tier: "cold"}
+	defer setTestTier(t, "fstest", tierLayer, rule)()
+
+	if err = obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 2*blockSizeV1+100)
+	if _, err = io.ReadFull(rand.Reader, data); err != nil {
+		t.Fatal(err)
+	}
+	for _, object := range []string{"cold/object", "hot/object"} {
+		if _, err = obj.PutObject("bucket", object, mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	objInfo, err := obj.GetObjectInfo("bucket", "cold/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partFile := filepath.Join(fsDirs[0], "bucket", "cold/object", "part.1")
+
+	// Objects are not transitioned before the rule applies.
+	transitionRuleObjects(xl, rule, UTCNow())
+	if _, err = os.Stat(partFile); err != nil {
+		t.Fatalf("Expected object data to be kept: %v", err)
+	}
+
+	transitionRuleObjects(xl, rule, UTCNow().Add(48*time.Hour))
+	if _, err = os.Stat(partFile); !os.IsNotExist(err) {
+		t.Fatalf("Expected object data to be removed, got %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(fsDirs[0], "bucket", "hot/object", "part.1")); err != nil {
+		t.Fatalf("Expected object outside of the rule to be kept: %v", err)
+	}
+	if _, err = tierLayer.GetObjectInfo("tier", "minio/bucket/cold/object"); err != nil {
+		t.Fatalf("Expected object data in the tier: %v", err)
+	}
+
+	content := new(bytes.Buffer)
+	if err = obj.GetObject("bucket", "cold/object", 100, 1000, content, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content.Bytes(), data[100:1100]) {
+		t.Errorf("Transitioned object content does not match")
+	}
+
+	if err = obj.RestoreObject("bucket", "cold/object", 1); err != nil {
+		t.Fatal(err)
+	}
+	waitForRestore(t, obj, "bucket", "cold/object")
+	if _, err = os.Stat(partFile); err != nil {
+		t.Fatalf("Expected object data to be restored: %v", err)
+	}
+	restoredInfo, err := obj.GetObjectInfo("bucket", "cold/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restoredInfo.ETag != objInfo.ETag || !restoredInfo.ModTime.Equal(objInfo.ModTime) {
+		t.Errorf("Expected restored object to keep ETag %s and ModTime %v, got %s and %v", objInfo.ETag, objInfo.ModTime, restoredInfo.ETag, restoredInfo.ModTime)
+	}
+	if restoredInfo.UserDefined[amzRestore] == "" {
+		t.Errorf("Expected %s header on restored object", amzRestore)
+	}
+	content.Reset()
+	if err = obj.GetObject("bucket", "cold/object", 0, int64(len(data)), content, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content.Bytes(), data) {
+		t.Errorf("Restored object content does not match")
+	}
+
+	// Restored copy is removed once it expired.
+	transitionRuleObjects(xl, rule, UTCNow().Add(48*time.Hour))
+	if _, err = os.Stat(partFile); !os.IsNotExist(err) {
+		t.Fatalf("Expected restored object data to be removed, got %v", err)
+	}
+
+	err = obj.RestoreObject("bucket", "hot/object", 1)
+	if _, ok := errors.Cause(err).(InvalidObjectState); !ok {
+		t.Errorf("Expected InvalidObjectState restoring an object which was not transitioned, got %v", err)
+	}
+
+	// Overwriting a transitioned object deletes its copy in the tier.
+	if _, err = obj.PutObject("bucket", "cold/object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tierLayer.GetObjectInfo("tier", "minio/bucket/cold/object"); err == nil {
+		t.Errorf("Expected replaced object to be deleted from the tier")
+	}
+
+	// A scan transitions the object again, another scan within the
+	// scan interval is skipped.
+	now := UTCNow().Add(48 * time.Hour)
+	scanColdObjects(xl, tierScanInterval, now)
+	if _, err = tierLayer.GetObjectInfo("tier", "minio/bucket/cold/object"); err != nil {
+		t.Fatalf("Expected object data in the tier: %v", err)
+	}
+
+	if err = obj.DeleteObject("bucket", "cold/object"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tierLayer.GetObjectInfo("tier", "minio/bucket/cold/object"); err == nil {
+		t.Errorf("Expected object to be deleted from the tier")
+	}
+
+	if _, err = obj.PutObject("bucket", "cold/object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+		t.Fatal(err)
+	}
+	scanColdObjects(xl, tierScanInterval, now.Add(time.Hour))
+	if _, err = os.Stat(partFile); err != nil {
+		t.Fatalf("Expected object data to be kept by a skipped scan: %v", err)
+	}
+}
+
+// Tests that a restore which did not finish blocks other restores of
+// the object only until it times out.
+func TestXLRestoreObjectInProgress(t *testing.T) {
+	root, err := newTestConfig(globalMinioDefaultRegion)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	defer os.RemoveAll(root)
+
+	obj, fsDirs, err := prepareXL16()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(fsDirs)
+	xl := *obj.(*xlObjects)
+
+	tierDir, err := getRandomDisks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer removeRoots(tierDir)
+	tierLayer, err := newFSObjectLayer(tierDir[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tierLayer.MakeBucketWithLocation("tier", ""); err != nil {
+		t.Fatal(err)
+	}
+	rule := transitionRule{Bucket: "bucket", Days: 1, Tier: "cold"}
+	defer setTestTier(t, "fsrestoretest", tierLayer, rule)()
+
+	if err = obj.MakeBucketWithLocation("bucket", ""); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("transitioned object")
+	if _, err = obj.PutObject("bucket", "object", mustGetHashReader(t, bytes.NewReader(data), int64(len(data)), "", ""), nil); err != nil {
+		t.Fatal(err)
+	}
+	transitionRuleObjects(xl, rule, UTCNow().Add(48*time.Hour))
+
+	// Mark the object as being restored by a server which went away.
+	setRestoreStarted := func(started time.Time) {
+		if _, err = xl.updateObjectXLMeta("bucket", "object", func(m *xlMetaV1) {
+			m.Tier.RestoreStarted = started
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setRestoreStarted(UTCNow())
+	objInfo, err := obj.GetObjectInfo("bucket", "object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restore := objInfo.UserDefined[amzRestore]; restore != `ongoing-request="true"` {
+		t.Errorf("Expected restore in progress, got %s", restore)
+	}
+	err = obj.RestoreObject("bucket", "object", 1)
+	if _, ok := errors.Cause(err).(RestoreAlreadyInProgress); !ok {
+		t.Fatalf("Expected RestoreAlreadyInProgress, got %v", err)
+	}
+
+	setRestoreStarted(UTCNow().Add(-restoreObjectTimeout))
+	if err = obj.RestoreObject("bucket", "object", 1); err != nil {
+		t.Fatalf("Expected a timed out restore to be restarted, got %v", err)
+	}
+	waitForRestore(t, obj, "bucket", "object")
+	content := new(bytes.Buffer)
+	if err = obj.GetObject("bucket", "object", 0, int64(len(data)), content, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content.Bytes(), data) {
+		t.Errorf("Restored object content does not match")
+	}
+}
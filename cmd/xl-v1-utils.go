@@ -213,6 +213,31 @@ func parseXLMetaMap(xlMetaBuf []byte) map[string]string {
 	return metaMap
 }
 
+func parseXLTier(xlMetaBuf []byte) (*xlMetaTier, error) {
+	// Get xlMetaV1.Tier, only present for transitioned objects.
+	tierResult := gjson.GetBytes(xlMetaBuf, "tier")
+	if !tierResult.Exists() || tierResult.Type == gjson.Null {
+		return nil, nil
+	}
+	restoreExpiry, err := time.Parse(time.RFC3339, tierResult.Get("restoreExpiry").String())
+	if err != nil {
+		return nil, err
+	}
+	var restoreStarted time.Time
+	if started := tierResult.Get("restoreStarted"); started.Exists() {
+		if restoreStarted, err = time.Parse(time.RFC3339, started.String()); err != nil {
+			return nil, err
+		}
+	}
+	return &xlMetaTier{
+		Name:           tierResult.Get("name").String(),
+		Bucket:         tierResult.Get("bucket").String(),
+		Object:         tierResult.Get("object").String(),
+		RestoreExpiry:  restoreExpiry,
+		RestoreStarted: restoreStarted,
+	}, nil
+}
+
 // Constructs XLMetaV1 using `gjson` lib to retrieve each field.
 func xlMetaV1UnmarshalJSON(xlMetaBuf []byte) (xlMeta xlMetaV1, e error) {
 	// obtain version.
@@ -238,6 +263,11 @@ func xlMetaV1UnmarshalJSON(xlMetaBuf []byte) (xlMeta xlMetaV1, e error) {
 	xlMeta.Minio.Release = parseXLRelease(xlMetaBuf)
 	// parse xlMetaV1.
 	xlMeta.Meta = parseXLMetaMap(xlMetaBuf)
+	// Parse xlMetaV1.Tier.
+	xlMeta.Tier, err = parseXLTier(xlMetaBuf)
+	if err != nil {
+		return xlMeta, err
+	}
 
 	return xlMeta, nil
 }
@@ -54,6 +54,9 @@ type xlObjects struct {
 
 	// Variable represents bucket policies in memory.
 	bucketPolicies *bucketPolicies
+
+	// Variable represents bucket ACLs in memory.
+	bucketACLs *bucketACLs
 }
 
 // list of all errors that can be ignored in tree walk operation in XL
@@ -63,15 +66,29 @@ var xlTreeWalkIgnoredErrs = append(baseIgnoredErrs, errDiskAccessDenied, errVolu
 func newXLObjectLayer(storageDisks []StorageAPI) (ObjectLayer, error) {
 	// Initialize XL object layer.
 	objAPI, err := newXLObjects(storageDisks)
-	fatalIf(err, "Unable to initialize XL object layer.")
+	if err != nil {
+		return nil, fmt.Errorf("Unable to initialize XL object layer. %s", err)
+	}
 
 	// Initialize and load bucket policies.
-	err = initBucketPolicies(objAPI)
-	fatalIf(err, "Unable to load all bucket policies.")
+	if err = initBucketPolicies(objAPI); err != nil {
+		return nil, fmt.Errorf("Unable to load all bucket policies. %s", err)
+	}
+
+	// Initialize and load bucket ACLs.
+	if err = initBucketACLs(objAPI); err != nil {
+		return nil, fmt.Errorf("Unable to load all bucket ACLs. %s", err)
+	}
 
 	// Initialize a new event notifier.
-	err = initEventNotifier(objAPI)
-	fatalIf(err, "Unable to initialize event notification.")
+	if err = initEventNotifier(objAPI); err != nil {
+		return nil, fmt.Errorf("Unable to initialize event notification. %s", err)
+	}
+
+	// Initialize and load bucket access logging.
+	if err = initBucketLogging(objAPI); err != nil {
+		return nil, fmt.Errorf("Unable to initialize bucket logging. %s", err)
+	}
 
 	// Success.
 	return objAPI, nil
@@ -189,7 +206,7 @@ func (xl xlObjects) ListLocks(bucket, prefix string, duration time.Duration) ([]
 			volumeLocks = append(volumeLocks, volLockInfo)
 		}
 	}
-	return volumeLocks, nil
+	return addLockLeases(volumeLocks, bucket, prefix, duration), nil
 }
 
 // Clear namespace locks held in object layer
@@ -0,0 +1,243 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package certs keeps the TLS certificates of a server and reloads
+// them when their files change, without restarting the server.
+package certs
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// LoadX509KeyPairFunc - loads a certificate and its private key from
+// the given files.
+type LoadX509KeyPairFunc func(certFile, keyFile string) (tls.Certificate, error)
+
+// GetCertificateFunc - returns the certificate to present in a TLS
+// handshake, see tls.Config.GetCertificate.
+type GetCertificateFunc func(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+
+// errNoCertificate - no certificate was added to the manager.
+var errNoCertificate = errors.New("no TLS certificate configured")
+
+// keyPair - a certificate loaded from certFile and keyFile.
+type keyPair struct {
+	certFile, keyFile string
+	// modified - modification time and size of both files when the
+	// certificate was last loaded or failed to load.
+	modified [2]fileStamp
+	cert     *tls.Certificate
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statKeyPair(certFile, keyFile string) (stamps [2]fileStamp, err error) {
+	for i, file := range []string{certFile, keyFile} {
+		fi, err := os.Stat(file)
+		if err != nil {
+			return stamps, err
+		}
+		stamps[i] = fileStamp{fi.ModTime(), fi.Size()}
+	}
+	return stamps, nil
+}
+
+// Manager - keeps a set of TLS certificates. The first certificate
+// added is the default one, the others are chosen by the server name
+// a client requests (SNI). Certificates are validated before they
+// replace the ones in use, on failure the previous certificate is kept.
+type Manager struct {
+	mu       sync.RWMutex
+	keyPairs []*keyPair
+	load     LoadX509KeyPairFunc
+}
+
+// NewManager - returns a certificate manager loading key pairs with
+// load.
+func NewManager(load LoadX509KeyPairFunc) *Manager {
+	if load == nil {
+		load = tls.LoadX509KeyPair
+	}
+	return &Manager{load: load}
+}
+
+// AddCertificate - loads, validates and adds the certificate stored in
+// certFile and keyFile. Adding the same files again is a no-op.
+func (m *Manager) AddCertificate(certFile, keyFile string) error {
+	m.mu.RLock()
+	for _, pair := range m.keyPairs {
+		if pair.certFile == certFile && pair.keyFile == keyFile {
+			m.mu.RUnlock()
+			return nil
+		}
+	}
+	m.mu.RUnlock()
+
+	stamps, err := statKeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	cert, err := m.loadCertificate(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.keyPairs = append(m.keyPairs, &keyPair{
+		certFile: certFile,
+		keyFile:  keyFile,
+		modified: stamps,
+		cert:     cert,
+	})
+	m.mu.Unlock()
+	return nil
+}
+
+// loadCertificate - loads a key pair and validates its certificate.
+func (m *Manager) loadCertificate(certFile, keyFile string) (*tls.Certificate, error) {
+	cert, err := m.load(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.verify(&cert); err != nil {
+		return nil, fmt.Errorf("TLS: invalid certificate %s: %v", certFile, err)
+	}
+	return &cert, nil
+}
+
+// verify - checks the validity period of a certificate, that each
+// certificate of the chain is signed by the next one and that the
+// private key matches the certificate. The root is not checked, the
+// CAs trusted by clients are not known to the server. Sets the parsed
+// leaf of cert.
+func (m *Manager) verify(cert *tls.Certificate) error {
+	if len(cert.Certificate) == 0 {
+		return errors.New("no certificate found")
+	}
+	chain := make([]*x509.Certificate, len(cert.Certificate))
+	for i, der := range cert.Certificate {
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			return err
+		}
+		chain[i] = c
+	}
+	leaf := chain[0]
+	now := time.Now()
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return fmt.Errorf("certificate is only valid from %s until %s", leaf.NotBefore, leaf.NotAfter)
+	}
+	for i := 0; i+1 < len(chain); i++ {
+		if err := chain[i].CheckSignatureFrom(chain[i+1]); err != nil {
+			return fmt.Errorf("certificate %s is not signed by %s: %v",
+				chain[i].Subject.CommonName, chain[i+1].Subject.CommonName, err)
+		}
+	}
+
+	signer, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return errors.New("unsupported private key")
+	}
+	certKey, err := x509.MarshalPKIXPublicKey(leaf.PublicKey)
+	if err != nil {
+		return err
+	}
+	privateKey, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(certKey, privateKey) {
+		return errors.New("private key does not match the certificate")
+	}
+	cert.Leaf = leaf
+	return nil
+}
+
+// Reload - reloads the certificates whose files changed since they
+// were last loaded, all of them if force is set. Returns one error per
+// certificate which failed to reload, the previous certificate stays
+// in use for those.
+func (m *Manager) Reload(force bool) (errs []error) {
+	m.mu.RLock()
+	keyPairs := append([]*keyPair(nil), m.keyPairs...)
+	m.mu.RUnlock()
+
+	for _, pair := range keyPairs {
+		stamps, err := statKeyPair(pair.certFile, pair.keyFile)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.mu.RLock()
+		unchanged := stamps == pair.modified
+		m.mu.RUnlock()
+		if unchanged && !force {
+			continue
+		}
+
+		cert, err := m.loadCertificate(pair.certFile, pair.keyFile)
+		m.mu.Lock()
+		// Remember failed attempts too, to not retry and report an
+		// invalid certificate until its files change again.
+		pair.modified = stamps
+		if err == nil {
+			pair.cert = cert
+		}
+		m.mu.Unlock()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// GetCertificate - returns the certificate matching the server name
+// requested by the client, the default certificate otherwise. Can be
+// used as tls.Config.GetCertificate.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.keyPairs) == 0 {
+		return nil, errNoCertificate
+	}
+	if hello != nil && hello.ServerName != "" {
+		for _, pair := range m.keyPairs {
+			if pair.cert.Leaf.VerifyHostname(hello.ServerName) == nil {
+				return pair.cert, nil
+			}
+		}
+	}
+	return m.keyPairs[0].cert, nil
+}
+
+// GetClientCertificate - returns the default certificate, presented by
+// a client authenticating itself. Can be used as
+// tls.Config.GetClientCertificate.
+func (m *Manager) GetClientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return m.GetCertificate(nil)
+}
@@ -0,0 +1,309 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate - writes a self signed certificate for the
+// given DNS names, valid until notAfter, to certFile and keyFile.
+func writeTestCertificate(t *testing.T, certFile, keyFile string, notAfter time.Time, names ...string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: names[0]},
+		DNSNames:              names,
+		NotBefore:             notAfter.Add(-24 * time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getCertificateName(t *testing.T, m *Manager, serverName string) string {
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.Leaf.Subject.CommonName
+}
+
+func TestManagerGetCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewManager(nil)
+	if _, err = m.GetCertificate(&tls.ClientHelloInfo{}); err != errNoCertificate {
+		t.Fatalf("Expected %v, got %v", errNoCertificate, err)
+	}
+
+	notAfter := time.Now().Add(time.Hour)
+	writeTestCertificate(t, filepath.Join(dir, "default.crt"), filepath.Join(dir, "default.key"), notAfter, "minio.local")
+	writeTestCertificate(t, filepath.Join(dir, "a.crt"), filepath.Join(dir, "a.key"), notAfter, "a.example.com", "*.a.example.com")
+	writeTestCertificate(t, filepath.Join(dir, "expired.crt"), filepath.Join(dir, "expired.key"), time.Now().Add(-time.Hour), "expired.example.com")
+
+	for _, name := range []string{"default", "a", "a"} {
+		if err = m.AddCertificate(filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")); err != nil {
+			t.Fatalf("Unable to add certificate %s: %v", name, err)
+		}
+	}
+	if err = m.AddCertificate(filepath.Join(dir, "expired.crt"), filepath.Join(dir, "expired.key")); err == nil {
+		t.Fatal("Expected expired certificate to be rejected")
+	}
+	if len(m.keyPairs) != 2 {
+		t.Fatalf("Expected 2 certificates, got %d", len(m.keyPairs))
+	}
+
+	testCases := []struct {
+		serverName string
+		expected   string
+	}{
+		{"", "minio.local"},
+		{"minio.local", "minio.local"},
+		{"a.example.com", "a.example.com"},
+		{"b.a.example.com", "a.example.com"},
+		{"expired.example.com", "minio.local"},
+		{"unknown.example.com", "minio.local"},
+	}
+	for i, testCase := range testCases {
+		if name := getCertificateName(t, m, testCase.serverName); name != testCase.expected {
+			t.Errorf("Test %d: Expected %s, got %s", i+1, testCase.expected, name)
+		}
+	}
+}
+
+func TestManagerReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "public.crt"), filepath.Join(dir, "private.key")
+	writeTestCertificate(t, certFile, keyFile, time.Now().Add(time.Hour), "first")
+
+	m := NewManager(nil)
+	if err = m.AddCertificate(certFile, keyFile); err != nil {
+		t.Fatal(err)
+	}
+	if errs := m.Reload(false); len(errs) != 0 {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+
+	// Rotate the certificate, modification times are moved forward
+	// as the file system may not have a fine enough resolution.
+	writeTestCertificate(t, certFile, keyFile, time.Now().Add(time.Hour), "second")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	if errs := m.Reload(false); len(errs) != 0 {
+		t.Fatalf("Unexpected errors %v", errs)
+	}
+	if name := getCertificateName(t, m, ""); name != "second" {
+		t.Fatalf("Expected reloaded certificate, got %s", name)
+	}
+
+	// An invalid certificate is reported once and not swapped in.
+	writeTestCertificate(t, certFile, keyFile, time.Now().Add(-time.Hour), "expired")
+	future = future.Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	if errs := m.Reload(false); len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %v", errs)
+	}
+	if errs := m.Reload(false); len(errs) != 0 {
+		t.Fatalf("Expected no errors for unchanged files, got %v", errs)
+	}
+	if errs := m.Reload(true); len(errs) != 1 {
+		t.Fatalf("Expected 1 error on forced reload, got %v", errs)
+	}
+	if name := getCertificateName(t, m, ""); name != "second" {
+		t.Fatalf("Expected previous certificate to be kept, got %s", name)
+	}
+}
+
+// newTestCA - returns a CA certificate and its key.
+func newTestCA(t *testing.T, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ca, key
+}
+
+// newTestLeaf - returns a node certificate issued by ca and its key.
+func newTestLeaf(t *testing.T, name string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) ([]byte, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der, key
+}
+
+// A certificate whose chain is broken or which does not match its
+// private key is not swapped in.
+func TestManagerReloadBrokenChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "public.crt"), filepath.Join(dir, "private.key")
+	ca, caKey := newTestCA(t, "CA")
+	otherCA, otherCAKey := newTestCA(t, "other CA")
+	writeChain := func(name string, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey, next *x509.Certificate) {
+		der, key := newTestLeaf(t, name, issuer, issuerKey)
+		keyDER, kerr := x509.MarshalECPrivateKey(key)
+		if kerr != nil {
+			t.Fatal(kerr)
+		}
+		chain := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: next.Raw})...)
+		if kerr = ioutil.WriteFile(certFile, chain, 0600); kerr != nil {
+			t.Fatal(kerr)
+		}
+		if kerr = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); kerr != nil {
+			t.Fatal(kerr)
+		}
+	}
+
+	writeChain("first", ca, caKey, ca)
+	m := NewManager(nil)
+	if err = m.AddCertificate(certFile, keyFile); err != nil {
+		t.Fatal(err)
+	}
+
+	writeChain("broken", otherCA, otherCAKey, ca)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	if errs := m.Reload(false); len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %v", errs)
+	}
+	if name := getCertificateName(t, m, ""); name != "first" {
+		t.Fatalf("Expected previous certificate to be kept, got %s", name)
+	}
+
+	// tls.LoadX509KeyPair already rejects mismatching keys, a custom
+	// load function is used to return one.
+	der, _ := newTestLeaf(t, "mismatch", ca, caKey)
+	_, otherKey := newTestLeaf(t, "other", ca, caKey)
+	m.load = func(certFile, keyFile string) (tls.Certificate, error) {
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: otherKey}, nil
+	}
+	if errs := m.Reload(true); len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %v", errs)
+	}
+	if name := getCertificateName(t, m, ""); name != "first" {
+		t.Fatalf("Expected previous certificate to be kept, got %s", name)
+	}
+}
+
+// Certificates issued by a CA unknown to the server are accepted,
+// clients may trust CAs the server does not know about.
+func TestManagerUnknownIssuer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, caKey := newTestCA(t, "unknown CA")
+	der, key := newTestLeaf(t, "node", ca, caKey)
+
+	m := NewManager(func(certFile, keyFile string) (tls.Certificate, error) {
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+	})
+	certFile, keyFile := filepath.Join(dir, "public.crt"), filepath.Join(dir, "private.key")
+	for _, file := range []string{certFile, keyFile} {
+		if err = ioutil.WriteFile(file, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = m.AddCertificate(certFile, keyFile); err != nil {
+		t.Fatalf("Unable to add certificate: %v", err)
+	}
+	cert, err := m.GetClientCertificate(&tls.CertificateRequestInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Leaf.Subject.CommonName != "node" {
+		t.Errorf("Unexpected client certificate %s", cert.Leaf.Subject.CommonName)
+	}
+}
@@ -43,13 +43,23 @@ func log(msg ...interface{}) {
 const DRWMutexAcquireTimeout = 1 * time.Second // 1 second.
 const drwMutexInfinite = time.Duration(1<<63 - 1)
 
+// DRWMutexRefreshInterval - interval at which held locks are refreshed
+// on the nodes that granted them. Lock servers drop locks which are not
+// refreshed for a while, so locks of crashed nodes do not linger. A
+// write lock which can not be refreshed on a quorum of nodes is lost,
+// see Lost().
+var DRWMutexRefreshInterval = 10 * time.Second
+
 // A DRWMutex is a distributed mutual exclusion lock.
 type DRWMutex struct {
-	Name         string
-	writeLocks   []string   // Array of nodes that granted a write lock
-	readersLocks [][]string // Array of array of nodes that granted reader locks
-	m            sync.Mutex // Mutex to prevent multiple simultaneous locks from this node
-	clnt         *Dsync
+	Name            string
+	writeLocks      []string        // Array of nodes that granted a write lock
+	writeRefreshCh  chan struct{}   // Closed to stop refreshing the write lock
+	writeLostCh     chan struct{}   // Closed when the write lock is lost
+	readersLocks    [][]string      // Array of array of nodes that granted reader locks
+	readersRefreshs []chan struct{} // Closed to stop refreshing the matching reader lock
+	m               sync.Mutex      // Mutex to prevent multiple simultaneous locks from this node
+	clnt            *Dsync
 }
 
 // Granted - represents a structure of a granted lock.
@@ -138,14 +148,21 @@ func (dm *DRWMutex) lockBlocking(timeout time.Duration, isReadLock bool) (locked
 			dm.m.Lock()
 			defer dm.m.Unlock()
 
+			// Keep the granted locks alive until they are released.
+			refreshCh, lostCh := make(chan struct{}), make(chan struct{})
+			go refresh(dm.clnt, append([]string(nil), locks...), dm.Name, isReadLock, refreshCh, lostCh)
+
 			// If success, copy array to object
 			if isReadLock {
 				// Append new array of strings at the end
 				dm.readersLocks = append(dm.readersLocks, make([]string, dm.clnt.dNodeCount))
 				// and copy stack array into last spot
 				copy(dm.readersLocks[len(dm.readersLocks)-1], locks[:])
+				dm.readersRefreshs = append(dm.readersRefreshs, refreshCh)
 			} else {
 				copy(dm.writeLocks, locks[:])
+				dm.writeRefreshCh = refreshCh
+				dm.writeLostCh = lostCh
 			}
 
 			return true
@@ -166,6 +183,12 @@ func lock(ds *Dsync, locks *[]string, lockName string, isReadLock bool) bool {
 	ch := make(chan Granted, ds.dNodeCount)
 	defer close(ch)
 
+	// All nodes grant the lock under the same UID, so that lock servers
+	// can verify with the holder whether a lock is still held.
+	bytesUID := [16]byte{}
+	cryptorand.Read(bytesUID[:])
+	uid := fmt.Sprintf("%X", bytesUID[:])
+
 	var wg sync.WaitGroup
 	for index, c := range ds.rpcClnts {
 
@@ -176,10 +199,6 @@ func lock(ds *Dsync, locks *[]string, lockName string, isReadLock bool) bool {
 
 			// All client methods issuing RPCs are thread-safe and goroutine-safe,
 			// i.e. it is safe to call them from multiple concurrently running go routines.
-			bytesUID := [16]byte{}
-			cryptorand.Read(bytesUID[:])
-			uid := fmt.Sprintf("%X", bytesUID[:])
-
 			args := LockArgs{
 				UID:             uid,
 				Resource:        lockName,
@@ -287,6 +306,63 @@ func lock(ds *Dsync, locks *[]string, lockName string, isReadLock bool) bool {
 	return quorum
 }
 
+// refresh refreshes the locks granted by the nodes every
+// DRWMutexRefreshInterval until doneCh is closed. Once the locks can
+// not be refreshed on a quorum of nodes, the lock is lost: lostCh is
+// closed and refreshing stops.
+func refresh(ds *Dsync, locks []string, name string, isReadLock bool, doneCh <-chan struct{}, lostCh chan<- struct{}) {
+	ticker := time.NewTicker(DRWMutexRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-doneCh:
+			return
+		case <-ticker.C:
+		}
+
+		// Create buffered channel of size equal to total number of nodes.
+		ch := make(chan bool, ds.dNodeCount)
+		for index, c := range ds.rpcClnts {
+			if !isLocked(locks[index]) {
+				ch <- false
+				continue
+			}
+			go func(c NetLocker, uid string) {
+				args := LockArgs{
+					UID:             uid,
+					Resource:        name,
+					ServerAddr:      ds.rpcClnts[ds.ownNode].ServerAddr(),
+					ServiceEndpoint: ds.rpcClnts[ds.ownNode].ServiceEndpoint(),
+				}
+				refreshed, err := c.Refresh(args)
+				if err != nil {
+					log("Unable to call Refresh", err)
+				} else if !refreshed {
+					log("Lock no longer held", name, c.ServerAddr())
+				}
+				ch <- err == nil && refreshed
+			}(c, locks[index])
+		}
+
+		quorum := ds.dquorum
+		if isReadLock {
+			quorum = ds.dquorumReads
+		}
+		refreshed := 0
+		for range ds.rpcClnts {
+			if <-ch {
+				refreshed++
+			}
+		}
+		if refreshed < quorum {
+			log("Lock lost, unable to refresh on quorum of nodes", name)
+			close(lostCh)
+			return
+		}
+	}
+}
+
 // quorumMet determines whether we have acquired the required quorum of underlying locks or not
 func quorumMet(locks *[]string, isReadLock bool, quorum, quorumReads int) bool {
 
@@ -345,12 +421,27 @@ func (dm *DRWMutex) Unlock() {
 		copy(locks, dm.writeLocks[:])
 		// Clear write locks array
 		dm.writeLocks = make([]string, dm.clnt.dNodeCount)
+		// Stop refreshing the write lock
+		close(dm.writeRefreshCh)
+		dm.writeRefreshCh = nil
+		dm.writeLostCh = nil
 	}
 
 	isReadLock := false
 	unlock(dm.clnt, locks, dm.Name, isReadLock)
 }
 
+// Lost returns a channel which is closed when the write lock held on dm
+// is lost, i.e. it could not be refreshed on a quorum of nodes and may
+// have been granted to another node since. Writes done under the lock
+// must not be committed once it is lost. Returns nil if no write lock
+// is held.
+func (dm *DRWMutex) Lost() <-chan struct{} {
+	dm.m.Lock()
+	defer dm.m.Unlock()
+	return dm.writeLostCh
+}
+
 // RUnlock releases a read lock held on dm.
 //
 // It is a run-time error if dm is not locked on entry to RUnlock.
@@ -369,6 +460,9 @@ func (dm *DRWMutex) RUnlock() {
 		copy(locks, dm.readersLocks[0][:])
 		// Drop first element from array
 		dm.readersLocks = dm.readersLocks[1:]
+		// Stop refreshing the released reader lock
+		close(dm.readersRefreshs[0])
+		dm.readersRefreshs = dm.readersRefreshs[1:]
 	}
 
 	isReadLock := true
@@ -399,6 +493,16 @@ func (dm *DRWMutex) ForceUnlock() {
 		dm.writeLocks = make([]string, dm.clnt.dNodeCount)
 		// Clear read locks array
 		dm.readersLocks = nil
+		// Stop refreshing all locks
+		if dm.writeRefreshCh != nil {
+			close(dm.writeRefreshCh)
+			dm.writeRefreshCh = nil
+			dm.writeLostCh = nil
+		}
+		for _, refreshCh := range dm.readersRefreshs {
+			close(refreshCh)
+		}
+		dm.readersRefreshs = nil
 	}
 
 	for _, c := range dm.clnt.rpcClnts {
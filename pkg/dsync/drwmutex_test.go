@@ -0,0 +1,242 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dsync
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLocker - in memory lock server counting the refreshes of the
+// locks it granted.
+type testLocker struct {
+	mu        sync.Mutex
+	addr      string
+	writers   map[string]string         // resource -> uid
+	readers   map[string]map[string]int // resource -> uid -> count
+	refreshes int
+	expired   bool // the server dropped all locks, refreshes fail
+}
+
+func newTestLocker(addr string) *testLocker {
+	return &testLocker{
+		addr:    addr,
+		writers: make(map[string]string),
+		readers: make(map[string]map[string]int),
+	}
+}
+
+func (l *testLocker) RLock(args LockArgs) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.writers[args.Resource]; ok {
+		return false, nil
+	}
+	if l.readers[args.Resource] == nil {
+		l.readers[args.Resource] = make(map[string]int)
+	}
+	l.readers[args.Resource][args.UID]++
+	return true, nil
+}
+
+func (l *testLocker) Lock(args LockArgs) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.writers[args.Resource]; ok || len(l.readers[args.Resource]) > 0 {
+		return false, nil
+	}
+	l.writers[args.Resource] = args.UID
+	return true, nil
+}
+
+func (l *testLocker) RUnlock(args LockArgs) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.readers[args.Resource], args.UID)
+	return true, nil
+}
+
+func (l *testLocker) Unlock(args LockArgs) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.writers, args.Resource)
+	return true, nil
+}
+
+func (l *testLocker) Refresh(args LockArgs) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.expired {
+		return false, nil
+	}
+	l.refreshes++
+	if l.writers[args.Resource] == args.UID {
+		return true, nil
+	}
+	return l.readers[args.Resource][args.UID] > 0, nil
+}
+
+func (l *testLocker) ForceUnlock(args LockArgs) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.writers, args.Resource)
+	delete(l.readers, args.Resource)
+	return true, nil
+}
+
+func (l *testLocker) ServerAddr() string      { return l.addr }
+func (l *testLocker) ServiceEndpoint() string { return "/dsync" }
+
+func (l *testLocker) getRefreshes() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.refreshes
+}
+
+func (l *testLocker) expire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expired = true
+	l.writers = make(map[string]string)
+	l.readers = make(map[string]map[string]int)
+}
+
+// newTestDsync - returns a Dsync of n in memory lock servers and sets
+// a short refresh interval until the returned func is called.
+func newTestDsync(t *testing.T, n int) (*Dsync, []*testLocker, func()) {
+	lockers := make([]*testLocker, n)
+	clnts := make([]NetLocker, n)
+	for i := range lockers {
+		lockers[i] = newTestLocker(fmt.Sprintf("node%d:9000", i))
+		clnts[i] = lockers[i]
+	}
+	ds, err := New(clnts, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevInterval := DRWMutexRefreshInterval
+	DRWMutexRefreshInterval = 10 * time.Millisecond
+	return ds, lockers, func() { DRWMutexRefreshInterval = prevInterval }
+}
+
+func totalRefreshes(lockers []*testLocker) (total int) {
+	for _, l := range lockers {
+		total += l.getRefreshes()
+	}
+	return total
+}
+
+// Tests that held locks are refreshed on the nodes granting them until
+// they are released.
+func TestDRWMutexRefresh(t *testing.T) {
+	for _, isReadLock := range []bool{false, true} {
+		ds, lockers, restore := newTestDsync(t, 4)
+
+		dm := NewDRWMutex("resource", ds)
+		if isReadLock {
+			dm.RLock()
+		} else {
+			dm.Lock()
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for totalRefreshes(lockers) < 2*len(lockers) {
+			if time.Now().After(deadline) {
+				t.Fatalf("read lock %v: Lock was not refreshed", isReadLock)
+			}
+			time.Sleep(DRWMutexRefreshInterval)
+		}
+		select {
+		case <-dm.Lost():
+			t.Fatalf("read lock %v: Lock lost although it was refreshed", isReadLock)
+		default:
+		}
+
+		if isReadLock {
+			dm.RUnlock()
+		} else {
+			dm.Unlock()
+		}
+		// Allow an in-flight refresh to finish.
+		time.Sleep(5 * DRWMutexRefreshInterval)
+		refreshes := totalRefreshes(lockers)
+		time.Sleep(5 * DRWMutexRefreshInterval)
+		if n := totalRefreshes(lockers); n != refreshes {
+			t.Errorf("read lock %v: Lock refreshed %d times after it was released", isReadLock, n-refreshes)
+		}
+
+		restore()
+	}
+}
+
+// Tests that a write lock which cannot be refreshed on a quorum of
+// nodes is reported lost, and is not lost if a quorum refreshes it.
+func TestDRWMutexLost(t *testing.T) {
+	ds, lockers, restore := newTestDsync(t, 4)
+	defer restore()
+
+	dm := NewDRWMutex("resource", ds)
+	if lostCh := dm.Lost(); lostCh != nil {
+		t.Fatal("Expected no lost channel without a write lock")
+	}
+	dm.Lock()
+	lostCh := dm.Lost()
+	if lostCh == nil {
+		t.Fatal("Expected a lost channel while holding a write lock")
+	}
+
+	// One node dropping the lock leaves a quorum of 3 out of 4.
+	lockers[3].expire()
+	select {
+	case <-lostCh:
+		t.Fatal("Lock lost although a quorum still holds it")
+	case <-time.After(10 * DRWMutexRefreshInterval):
+	}
+
+	// Without a quorum the lock is lost, writers abort.
+	lockers[2].expire()
+	select {
+	case <-lostCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock not reported lost")
+	}
+
+	dm.Unlock()
+	if dm.Lost() != nil {
+		t.Fatal("Expected no lost channel after Unlock")
+	}
+}
+
+// Tests that read locks are never reported lost, they have no lost
+// channel.
+func TestDRWMutexReadLockNotLost(t *testing.T) {
+	ds, lockers, restore := newTestDsync(t, 4)
+	defer restore()
+
+	dm := NewDRWMutex("resource", ds)
+	dm.RLock()
+	if dm.Lost() != nil {
+		t.Fatal("Expected no lost channel for a read lock")
+	}
+	for _, l := range lockers {
+		l.expire()
+	}
+	time.Sleep(5 * DRWMutexRefreshInterval)
+	dm.RUnlock()
+}
@@ -53,6 +53,11 @@ type NetLocker interface {
 	// * an error on failure of unlock request operation.
 	Unlock(args LockArgs) (bool, error)
 
+	// Refresh the lease of a lock granted for given LockArgs. It should return
+	// * a boolean to indicate whether the lock is still held
+	// * an error on failure of refresh request operation.
+	Refresh(args LockArgs) (bool, error)
+
 	// Unlock (read/write) forcefully for given LockArgs. It should return
 	// * a boolean to indicate success/failure of the operation
 	// * an error on failure of unlock request operation.
@@ -30,6 +30,7 @@ type BufConn struct {
 	writeTimeout           time.Duration // sets the write timeout in the connection.
 	updateBytesReadFunc    func(int)     // function to be called to update bytes read.
 	updateBytesWrittenFunc func(int)     // function to be called to update bytes written.
+	peerKey                string        // key of verified client certificates, if any.
 }
 
 // Sets read timeout
@@ -99,3 +100,9 @@ func newBufConn(c net.Conn, readTimeout, writeTimeout time.Duration,
 		updateBytesWrittenFunc: updateBytesWrittenFunc,
 	}
 }
+
+// Close - closes the connection and forgets verified client certificates.
+func (c *BufConn) Close() error {
+	unregisterVerifiedChains(c)
+	return c.QuirkConn.Close()
+}
@@ -185,6 +185,9 @@ func (listener *httpListener) start() {
 			// Check whether the connection contains HTTP request or not.
 			bufconn = newBufConn(tlsConn, listener.readTimeout, listener.writeTimeout,
 				listener.updateBytesReadFunc, listener.updateBytesWrittenFunc)
+			if chains := tlsConn.ConnectionState().VerifiedChains; len(chains) > 0 {
+				registerVerifiedChains(bufconn, chains)
+			}
 
 			// Peek bytes of maximum length of all HTTP methods.
 			data, err := bufconn.Peek(methodMaxLen)
@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -477,6 +478,54 @@ func TestHTTPListenerAccept(t *testing.T) {
 	}
 }
 
+func TestHTTPListenerVerifiedChains(t *testing.T) {
+	tlsConfig := getTLSConfig(t)
+	tlsCert := tlsConfig.Certificates[0]
+	x509Cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsConfig.ClientCAs = x509.NewCertPool()
+	tlsConfig.ClientCAs.AddCert(x509Cert)
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+
+	listener, err := newHTTPListener([]string{"127.0.0.1:0"}, tlsConfig,
+		time.Duration(0), time.Duration(0), time.Duration(0), nil, nil, nil)
+	if err != nil {
+		t.Fatalf("error: expected = <nil>, got = %v", err)
+	}
+	defer listener.Close()
+
+	for i, certs := range [][]tls.Certificate{nil, {tlsCert}} {
+		conn, err := tls.Dial("tcp", listener.Addrs()[0].String(), &tls.Config{
+			InsecureSkipVerify: true,
+			Certificates:       certs,
+		})
+		if err != nil {
+			t.Fatalf("Test %d: error: expected = <nil>, got = %v", i+1, err)
+		}
+		if _, err = io.WriteString(conn, "GET / HTTP/1.0\n"); err != nil {
+			t.Fatalf("Test %d: request send: expected = <nil>, got = %v", i+1, err)
+		}
+		serverConn, err := listener.Accept()
+		if err != nil {
+			t.Fatalf("Test %d: accept: expected = <nil>, got = %v", i+1, err)
+		}
+
+		localAddr, remoteAddr := serverConn.LocalAddr().String(), serverConn.RemoteAddr().String()
+		chains := VerifiedChains(localAddr, remoteAddr)
+		if len(certs) != len(chains) {
+			t.Fatalf("Test %d: verified chains: expected = %d, got = %d", i+1, len(certs), len(chains))
+		}
+
+		serverConn.Close()
+		conn.Close()
+		if chains = VerifiedChains(localAddr, remoteAddr); chains != nil {
+			t.Fatalf("Test %d: verified chains of closed connection: expected = <nil>, got = %v", i+1, chains)
+		}
+	}
+}
+
 func TestHTTPListenerAcceptPeekError(t *testing.T) {
 	tlsConfig := getTLSConfig(t)
 	nonLoopBackIP := getNonLoopBackIP(t)
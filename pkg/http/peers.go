@@ -0,0 +1,63 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http
+
+import (
+	"crypto/x509"
+	"sync"
+)
+
+// Connections are passed to http.Server wrapped in a BufConn, hence
+// http.Request.TLS is not set. Verified client certificate chains of
+// TLS connections are kept here, keyed by the local and the remote
+// address of a connection, for as long as the connection is open.
+var verifiedPeers = struct {
+	sync.RWMutex
+	chains map[string][][]*x509.Certificate
+}{chains: make(map[string][][]*x509.Certificate)}
+
+func peerKey(localAddr, remoteAddr string) string {
+	return localAddr + "|" + remoteAddr
+}
+
+// registerVerifiedChains - saves the verified client certificate
+// chains of c, they are removed when c is closed.
+func registerVerifiedChains(c *BufConn, chains [][]*x509.Certificate) {
+	c.peerKey = peerKey(c.LocalAddr().String(), c.RemoteAddr().String())
+	verifiedPeers.Lock()
+	verifiedPeers.chains[c.peerKey] = chains
+	verifiedPeers.Unlock()
+}
+
+func unregisterVerifiedChains(c *BufConn) {
+	if c.peerKey == "" {
+		return
+	}
+	verifiedPeers.Lock()
+	delete(verifiedPeers.chains, c.peerKey)
+	verifiedPeers.Unlock()
+}
+
+// VerifiedChains - returns the verified certificate chains presented by
+// the client of the TLS connection between localAddr and remoteAddr,
+// e.g. for a request the value of http.LocalAddrContextKey and
+// http.Request.RemoteAddr.
+func VerifiedChains(localAddr, remoteAddr string) [][]*x509.Certificate {
+	verifiedPeers.RLock()
+	defer verifiedPeers.RUnlock()
+	return verifiedPeers.chains[peerKey(localAddr, remoteAddr)]
+}
@@ -23,6 +23,8 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/minio/minio/pkg/certs"
 )
 
 func TestNewServer(t *testing.T) {
@@ -35,17 +37,21 @@ func TestNewServer(t *testing.T) {
 		fmt.Fprintf(w, "Hello, world")
 	})
 
+	getCert := func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return &certificate, nil
+	}
+
 	testCases := []struct {
 		addrs       []string
 		handler     http.Handler
-		certificate *tls.Certificate
+		certificate certs.GetCertificateFunc
 	}{
 		{[]string{"127.0.0.1:9000"}, handler, nil},
 		{[]string{nonLoopBackIP + ":9000"}, handler, nil},
 		{[]string{"127.0.0.1:9000", nonLoopBackIP + ":9000"}, handler, nil},
-		{[]string{"127.0.0.1:9000"}, handler, &certificate},
-		{[]string{nonLoopBackIP + ":9000"}, handler, &certificate},
-		{[]string{"127.0.0.1:9000", nonLoopBackIP + ":9000"}, handler, &certificate},
+		{[]string{"127.0.0.1:9000"}, handler, getCert},
+		{[]string{nonLoopBackIP + ":9000"}, handler, getCert},
+		{[]string{"127.0.0.1:9000", nonLoopBackIP + ":9000"}, handler, getCert},
 	}
 
 	for i, testCase := range testCases {
@@ -146,7 +152,9 @@ func TestServerTLSCiphers(t *testing.T) {
 				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					fmt.Fprintf(w, "Hello, world")
 				}),
-				&certificate)
+				func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+					return &certificate, nil
+				})
 			if testCase.resetServerCiphers {
 				// Use Go default ciphers.
 				server.TLSConfig.CipherSuites = nil
@@ -0,0 +1,281 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ber encodes and decodes the subset of the ASN.1 basic
+// encoding rules used by LDAP: single octet identifiers and definite
+// lengths.
+package ber
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Classes of an identifier octet.
+const (
+	ClassUniversal   byte = 0x00
+	ClassApplication byte = 0x40
+	ClassContext     byte = 0x80
+)
+
+// Constructed - flag of an identifier octet of a constructed value.
+const Constructed byte = 0x20
+
+// Universal tags.
+const (
+	TagBoolean     byte = 0x01
+	TagInteger     byte = 0x02
+	TagOctetString byte = 0x04
+	TagNull        byte = 0x05
+	TagEnumerated  byte = 0x0a
+	TagSequence    byte = 0x10 | Constructed
+	TagSet         byte = 0x11 | Constructed
+)
+
+// Maximum length of a packet accepted when reading, protects against
+// malicious peers announcing huge packets.
+const maxPacketLength = 16 << 20
+
+var (
+	errInvalidLength = errors.New("ber: invalid length")
+	errTruncated     = errors.New("ber: truncated packet")
+	errLongTag       = errors.New("ber: multi octet tags are not supported")
+)
+
+// Packet - a BER encoded value. Constructed values have children,
+// primitive values only a value.
+type Packet struct {
+	// Tag - the identifier octet, class | constructed flag | number.
+	Tag      byte
+	Value    []byte
+	Children []*Packet
+}
+
+// IsConstructed - returns true for constructed packets.
+func (p *Packet) IsConstructed() bool {
+	return p.Tag&Constructed != 0
+}
+
+// NewPrimitive - returns a primitive packet.
+func NewPrimitive(tag byte, value []byte) *Packet {
+	return &Packet{Tag: tag, Value: value}
+}
+
+// NewConstructed - returns a constructed packet, tag should have the
+// Constructed flag set.
+func NewConstructed(tag byte, children ...*Packet) *Packet {
+	return &Packet{Tag: tag | Constructed, Children: children}
+}
+
+// NewSequence - returns a universal SEQUENCE.
+func NewSequence(children ...*Packet) *Packet {
+	return NewConstructed(TagSequence, children...)
+}
+
+// NewOctetString - returns a universal OCTET STRING.
+func NewOctetString(s string) *Packet {
+	return NewPrimitive(TagOctetString, []byte(s))
+}
+
+// NewInteger - returns a universal INTEGER.
+func NewInteger(i int64) *Packet {
+	return NewPrimitive(TagInteger, encodeInt(i))
+}
+
+// NewEnumerated - returns a universal ENUMERATED.
+func NewEnumerated(i int64) *Packet {
+	return NewPrimitive(TagEnumerated, encodeInt(i))
+}
+
+// NewBoolean - returns a universal BOOLEAN.
+func NewBoolean(b bool) *Packet {
+	if b {
+		return NewPrimitive(TagBoolean, []byte{0xff})
+	}
+	return NewPrimitive(TagBoolean, []byte{0x00})
+}
+
+// Append - adds children to a constructed packet.
+func (p *Packet) Append(children ...*Packet) *Packet {
+	p.Children = append(p.Children, children...)
+	return p
+}
+
+// String - returns the value of a primitive packet as string.
+func (p *Packet) String() string {
+	return string(p.Value)
+}
+
+// Int - decodes the value of an INTEGER or ENUMERATED packet.
+func (p *Packet) Int() (int64, error) {
+	if len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, errInvalidLength
+	}
+	i := int64(int8(p.Value[0]))
+	for _, b := range p.Value[1:] {
+		i = i<<8 | int64(b)
+	}
+	return i, nil
+}
+
+// Bool - decodes the value of a BOOLEAN packet.
+func (p *Packet) Bool() bool {
+	return len(p.Value) > 0 && p.Value[0] != 0
+}
+
+func encodeInt(i int64) []byte {
+	n := 1
+	for v := i; v > 127 || v < -128; v >>= 8 {
+		n++
+	}
+	b := make([]byte, n)
+	for j := n - 1; j >= 0; j-- {
+		b[j] = byte(i)
+		i >>= 8
+	}
+	return b
+}
+
+// Bytes - returns the encoding of the packet.
+func (p *Packet) Bytes() []byte {
+	value := p.Value
+	if p.IsConstructed() {
+		value = nil
+		for _, child := range p.Children {
+			value = append(value, child.Bytes()...)
+		}
+	}
+	b := append([]byte{p.Tag}, encodeLength(len(value))...)
+	return append(b, value...)
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// ReadPacket - reads one packet from r.
+func ReadPacket(r *bufio.Reader) (*Packet, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if tag&0x1f == 0x1f {
+		return nil, errLongTag
+	}
+	length, err := readLength(r)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxPacketLength {
+		return nil, errInvalidLength
+	}
+	value := make([]byte, length)
+	if _, err = io.ReadFull(r, value); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return newPacket(tag, value)
+}
+
+func readLength(r *bufio.Reader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b < 0x80 {
+		return int(b), nil
+	}
+	// Indefinite lengths are not used by LDAP.
+	n := int(b & 0x7f)
+	if n == 0 || n > 4 {
+		return 0, errInvalidLength
+	}
+	length := 0
+	for i := 0; i < n; i++ {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	return length, nil
+}
+
+// Parse - decodes a packet encoded in b, which must not contain any
+// trailing data.
+func Parse(b []byte) (*Packet, error) {
+	p, rest, err := parse(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errInvalidLength
+	}
+	return p, nil
+}
+
+func parse(b []byte) (*Packet, []byte, error) {
+	if len(b) < 2 {
+		return nil, nil, errTruncated
+	}
+	tag := b[0]
+	if tag&0x1f == 0x1f {
+		return nil, nil, errLongTag
+	}
+	length, n := int(b[1]), 2
+	if length >= 0x80 {
+		octets := length & 0x7f
+		if octets == 0 || octets > 4 || len(b) < 2+octets {
+			return nil, nil, errInvalidLength
+		}
+		length = 0
+		for _, o := range b[2 : 2+octets] {
+			length = length<<8 | int(o)
+		}
+		n += octets
+	}
+	if length < 0 || len(b)-n < length {
+		return nil, nil, errTruncated
+	}
+	p, err := newPacket(tag, b[n:n+length])
+	return p, b[n+length:], err
+}
+
+func newPacket(tag byte, value []byte) (*Packet, error) {
+	p := &Packet{Tag: tag}
+	if tag&Constructed == 0 {
+		p.Value = value
+		return p, nil
+	}
+	for len(value) > 0 {
+		child, rest, err := parse(value)
+		if err != nil {
+			return nil, err
+		}
+		p.Children = append(p.Children, child)
+		value = rest
+	}
+	return p, nil
+}
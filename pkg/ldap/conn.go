@@ -0,0 +1,341 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ldap implements the client operations of the LDAP v3
+// protocol (RFC 4511) needed to authenticate users and look up their
+// groups: simple bind, search and StartTLS.
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/ldap/ber"
+)
+
+// Protocol operations, application tags of RFC 4511 section 4.2 to 4.12.
+const (
+	ApplicationBindRequest           byte = 0
+	ApplicationBindResponse          byte = 1
+	ApplicationUnbindRequest         byte = 2
+	ApplicationSearchRequest         byte = 3
+	ApplicationSearchResultEntry     byte = 4
+	ApplicationSearchResultDone      byte = 5
+	ApplicationSearchResultReference byte = 19
+	ApplicationExtendedRequest       byte = 23
+	ApplicationExtendedResponse      byte = 24
+)
+
+// Search scopes.
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+// Result codes used by this package.
+const (
+	ResultSuccess            = 0
+	ResultInvalidCredentials = 49
+)
+
+// OIDStartTLS - name of the StartTLS extended operation.
+const OIDStartTLS = "1.3.6.1.4.1.1466.20037"
+
+// DefaultTimeout - time an operation may take before the connection
+// is considered broken.
+const DefaultTimeout = 30 * time.Second
+
+// Error - a result other than success returned by the server.
+type Error struct {
+	ResultCode int64
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", e.ResultCode)
+	}
+	return fmt.Sprintf("ldap: result code %d: %s", e.ResultCode, e.Message)
+}
+
+// IsInvalidCredentials - returns true if err reports a failed bind.
+func IsInvalidCredentials(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.ResultCode == ResultInvalidCredentials
+}
+
+var errUnexpectedResponse = errors.New("ldap: unexpected response")
+
+// Entry - a search result entry.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// GetAttributeValues - returns the values of an attribute, attribute
+// names are case insensitive.
+func (e *Entry) GetAttributeValues(name string) []string {
+	for attr, values := range e.Attributes {
+		if strings.EqualFold(attr, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// Conn - a connection to an LDAP server. Operations are sent one at a
+// time, a Conn is safe for concurrent use.
+type Conn struct {
+	mu        sync.Mutex
+	conn      net.Conn
+	reader    *bufio.Reader
+	messageID int64
+	// Timeout - deadline of each operation, DefaultTimeout if zero.
+	Timeout time.Duration
+}
+
+// Dial - connects to an LDAP server without TLS.
+func Dial(addr string) (*Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(conn), nil
+}
+
+// DialTLS - connects to an LDAPS server.
+func DialTLS(addr string, config *tls.Config) (*Conn, error) {
+	dialer := &net.Dialer{Timeout: DefaultTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(conn), nil
+}
+
+// NewConn - returns an LDAP connection over an established connection.
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// Close - unbinds and closes the connection.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messageID++
+	msg := ber.NewSequence(ber.NewInteger(c.messageID), ber.NewPrimitive(ber.ClassApplication|ApplicationUnbindRequest, nil))
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout()))
+	c.conn.Write(msg.Bytes())
+	return c.conn.Close()
+}
+
+func (c *Conn) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return c.Timeout
+}
+
+// StartTLS - upgrades the connection to TLS.
+func (c *Conn) StartTLS(config *tls.Config) error {
+	req := ber.NewConstructed(ber.ClassApplication|ApplicationExtendedRequest,
+		ber.NewPrimitive(ber.ClassContext|0, []byte(OIDStartTLS)))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	responses, err := c.do(req, ApplicationExtendedResponse)
+	if err != nil {
+		return err
+	}
+	if err = resultError(responses[len(responses)-1]); err != nil {
+		return err
+	}
+
+	tlsConn := tls.Client(c.conn, config)
+	tlsConn.SetDeadline(time.Now().Add(c.timeout()))
+	if err = tlsConn.Handshake(); err != nil {
+		return err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// Bind - authenticates the connection as dn with password. Binds with
+// an empty password are anonymous binds and rejected to not mistake
+// them for a successful authentication.
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		return &Error{ResultCode: ResultInvalidCredentials, Message: "empty password"}
+	}
+	req := ber.NewConstructed(ber.ClassApplication|ApplicationBindRequest,
+		ber.NewInteger(3),
+		ber.NewOctetString(dn),
+		ber.NewPrimitive(ber.ClassContext|0, []byte(password)))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	responses, err := c.do(req, ApplicationBindResponse)
+	if err != nil {
+		return err
+	}
+	return resultError(responses[len(responses)-1])
+}
+
+// SearchRequest - parameters of a search.
+type SearchRequest struct {
+	BaseDN     string
+	Scope      int
+	Filter     string
+	Attributes []string
+	SizeLimit  int
+}
+
+// Search - returns the entries matching the request.
+func (c *Conn) Search(req SearchRequest) ([]*Entry, error) {
+	filter, err := CompileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	attributes := ber.NewSequence()
+	for _, attr := range req.Attributes {
+		attributes.Append(ber.NewOctetString(attr))
+	}
+	op := ber.NewConstructed(ber.ClassApplication|ApplicationSearchRequest,
+		ber.NewOctetString(req.BaseDN),
+		ber.NewEnumerated(int64(req.Scope)),
+		ber.NewEnumerated(0), // Never dereference aliases.
+		ber.NewInteger(int64(req.SizeLimit)),
+		ber.NewInteger(int64(c.timeout()/time.Second)),
+		ber.NewBoolean(false),
+		filter,
+		attributes)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	responses, err := c.do(op, ApplicationSearchResultDone)
+	if err != nil {
+		return nil, err
+	}
+	if err = resultError(responses[len(responses)-1]); err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for _, resp := range responses[:len(responses)-1] {
+		if resp.Tag != ber.ClassApplication|ber.Constructed|ApplicationSearchResultEntry {
+			// Search result references are not followed.
+			continue
+		}
+		entry, err := parseEntry(resp)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// do - sends op and reads responses up to the final one, tagged with
+// the application tag done. Must be called with c.mu held.
+func (c *Conn) do(op *ber.Packet, done byte) ([]*ber.Packet, error) {
+	c.messageID++
+	id := c.messageID
+	c.conn.SetDeadline(time.Now().Add(c.timeout()))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if _, err := c.conn.Write(ber.NewSequence(ber.NewInteger(id), op).Bytes()); err != nil {
+		return nil, err
+	}
+
+	var responses []*ber.Packet
+	for {
+		msg, err := ber.ReadPacket(c.reader)
+		if err != nil {
+			return nil, err
+		}
+		if msg.Tag != ber.TagSequence || len(msg.Children) < 2 {
+			return nil, errUnexpectedResponse
+		}
+		msgID, err := msg.Children[0].Int()
+		if err != nil {
+			return nil, err
+		}
+		if msgID == 0 {
+			// Unsolicited notification, the server is
+			// about to close the connection.
+			if err = resultError(msg.Children[1]); err == nil {
+				err = errUnexpectedResponse
+			}
+			return nil, err
+		}
+		if msgID != id {
+			return nil, errUnexpectedResponse
+		}
+		resp := msg.Children[1]
+		responses = append(responses, resp)
+		if resp.Tag&^ber.Constructed == ber.ClassApplication|done {
+			return responses, nil
+		}
+	}
+}
+
+// resultError - returns the error reported by an LDAPResult.
+func resultError(resp *ber.Packet) error {
+	if len(resp.Children) < 3 {
+		return errUnexpectedResponse
+	}
+	code, err := resp.Children[0].Int()
+	if err != nil {
+		return err
+	}
+	if code == ResultSuccess {
+		return nil
+	}
+	return &Error{ResultCode: code, Message: resp.Children[2].String()}
+}
+
+func parseEntry(resp *ber.Packet) (*Entry, error) {
+	if len(resp.Children) != 2 {
+		return nil, errUnexpectedResponse
+	}
+	entry := &Entry{
+		DN:         resp.Children[0].String(),
+		Attributes: make(map[string][]string),
+	}
+	for _, attr := range resp.Children[1].Children {
+		if len(attr.Children) != 2 {
+			return nil, errUnexpectedResponse
+		}
+		name := attr.Children[0].String()
+		for _, value := range attr.Children[1].Children {
+			entry.Attributes[name] = append(entry.Attributes[name], value.String())
+		}
+	}
+	return entry, nil
+}
@@ -0,0 +1,209 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/minio/minio/pkg/ldap/ber"
+)
+
+// Filter choices, context specific tags of RFC 4511 section 4.5.1.
+const (
+	FilterAnd            byte = 0
+	FilterOr             byte = 1
+	FilterNot            byte = 2
+	FilterEqualityMatch  byte = 3
+	FilterSubstrings     byte = 4
+	FilterGreaterOrEqual byte = 5
+	FilterLessOrEqual    byte = 6
+	FilterPresent        byte = 7
+	FilterApproxMatch    byte = 8
+)
+
+var errFilterTruncated = errors.New("ldap: truncated filter")
+
+// Substring choices of a substrings filter.
+const (
+	SubstringInitial byte = 0
+	SubstringAny     byte = 1
+	SubstringFinal   byte = 2
+)
+
+// EscapeFilter - escapes the special characters of a value to be used
+// in a filter string, see RFC 4515 section 3.
+func EscapeFilter(value string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// CompileFilter - compiles a filter string as defined by RFC 4515 into
+// its BER encoding. Extensible matches are not supported.
+func CompileFilter(filter string) (*ber.Packet, error) {
+	p, rest, err := compileFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("ldap: trailing data in filter %q", filter)
+	}
+	return p, nil
+}
+
+func compileFilter(s string) (*ber.Packet, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return nil, "", fmt.Errorf("ldap: filter %q does not start with (", s)
+	}
+	s = s[1:]
+	if s == "" {
+		return nil, "", errFilterTruncated
+	}
+
+	var p *ber.Packet
+	switch s[0] {
+	case '&', '|':
+		tag := FilterAnd
+		if s[0] == '|' {
+			tag = FilterOr
+		}
+		p = ber.NewConstructed(ber.ClassContext | tag)
+		s = s[1:]
+		for strings.HasPrefix(s, "(") {
+			child, rest, err := compileFilter(s)
+			if err != nil {
+				return nil, "", err
+			}
+			p.Append(child)
+			s = rest
+		}
+	case '!':
+		child, rest, err := compileFilter(s[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		p = ber.NewConstructed(ber.ClassContext|FilterNot, child)
+		s = rest
+	default:
+		end := strings.IndexByte(s, ')')
+		if end < 0 {
+			return nil, "", errFilterTruncated
+		}
+		var err error
+		if p, err = compileItem(s[:end]); err != nil {
+			return nil, "", err
+		}
+		s = s[end:]
+	}
+
+	if !strings.HasPrefix(s, ")") {
+		return nil, "", errFilterTruncated
+	}
+	return p, s[1:], nil
+}
+
+// compileItem - compiles a simple filter item such as cn=admin*.
+func compileItem(item string) (*ber.Packet, error) {
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, fmt.Errorf("ldap: invalid filter item %q", item)
+	}
+	attr, value := item[:eq], item[eq+1:]
+
+	tag := FilterEqualityMatch
+	switch attr[len(attr)-1] {
+	case '~':
+		tag = FilterApproxMatch
+	case '>':
+		tag = FilterGreaterOrEqual
+	case '<':
+		tag = FilterLessOrEqual
+	}
+	if tag != FilterEqualityMatch {
+		attr = attr[:len(attr)-1]
+	}
+	if attr == "" || strings.ContainsAny(attr, "() ") {
+		return nil, fmt.Errorf("ldap: invalid attribute in filter item %q", item)
+	}
+
+	if tag == FilterEqualityMatch && value == "*" {
+		return ber.NewPrimitive(ber.ClassContext|FilterPresent, []byte(attr)), nil
+	}
+	if tag == FilterEqualityMatch && strings.Contains(value, "*") {
+		parts := strings.Split(value, "*")
+		substrings := ber.NewSequence()
+		for i, part := range parts {
+			if part == "" {
+				continue
+			}
+			unescaped, err := unescapeFilter(part)
+			if err != nil {
+				return nil, err
+			}
+			choice := SubstringAny
+			switch i {
+			case 0:
+				choice = SubstringInitial
+			case len(parts) - 1:
+				choice = SubstringFinal
+			}
+			substrings.Append(ber.NewPrimitive(ber.ClassContext|choice, []byte(unescaped)))
+		}
+		return ber.NewConstructed(ber.ClassContext|FilterSubstrings, ber.NewOctetString(attr), substrings), nil
+	}
+
+	unescaped, err := unescapeFilter(value)
+	if err != nil {
+		return nil, err
+	}
+	return ber.NewConstructed(ber.ClassContext|tag, ber.NewOctetString(attr), ber.NewOctetString(unescaped)), nil
+}
+
+// unescapeFilter - replaces the \XX escapes of a filter value.
+func unescapeFilter(value string) (string, error) {
+	if !strings.Contains(value, "\\") {
+		return value, nil
+	}
+	var b []byte
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			b = append(b, value[i])
+			continue
+		}
+		if i+2 >= len(value) {
+			return "", fmt.Errorf("ldap: invalid escape in filter value %q", value)
+		}
+		decoded, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", fmt.Errorf("ldap: invalid escape in filter value %q", value)
+		}
+		b = append(b, decoded...)
+		i += 2
+	}
+	return string(b), nil
+}
@@ -0,0 +1,290 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/ldap"
+	"github.com/minio/minio/pkg/ldap/ber"
+	"github.com/minio/minio/pkg/ldap/ldaptest"
+)
+
+var testEntries = []ldaptest.Entry{
+	{
+		DN:       "uid=alice,ou=people,dc=example,dc=com",
+		Password: "alice-secret",
+		Attributes: map[string][]string{
+			"uid": {"alice"},
+			"cn":  {"Alice Liddell"},
+		},
+	},
+	{
+		DN: "cn=developers,ou=groups,dc=example,dc=com",
+		Attributes: map[string][]string{
+			"objectClass": {"groupOfNames"},
+			"cn":          {"developers"},
+			"member":      {"uid=alice,ou=people,dc=example,dc=com"},
+		},
+	},
+	{
+		DN: "cn=admins,ou=groups,dc=example,dc=com",
+		Attributes: map[string][]string{
+			"objectClass": {"groupOfNames"},
+			"cn":          {"admins"},
+			"member":      {"uid=bob,ou=people,dc=example,dc=com"},
+		},
+	},
+}
+
+// testTLSConfigs - returns a server config with a self signed
+// certificate for 127.0.0.1 and a client config trusting it.
+func testTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client = &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+	return server, client
+}
+
+func TestCompileFilter(t *testing.T) {
+	testCases := []struct {
+		filter    string
+		expected  *ber.Packet
+		shouldErr bool
+	}{
+		{
+			filter: "(cn=admin)",
+			expected: ber.NewConstructed(ber.ClassContext|ldap.FilterEqualityMatch,
+				ber.NewOctetString("cn"), ber.NewOctetString("admin")),
+		},
+		{
+			filter:   "(objectClass=*)",
+			expected: ber.NewPrimitive(ber.ClassContext|ldap.FilterPresent, []byte("objectClass")),
+		},
+		{
+			filter: "(cn=a*b*c)",
+			expected: ber.NewConstructed(ber.ClassContext|ldap.FilterSubstrings,
+				ber.NewOctetString("cn"), ber.NewSequence(
+					ber.NewPrimitive(ber.ClassContext|ldap.SubstringInitial, []byte("a")),
+					ber.NewPrimitive(ber.ClassContext|ldap.SubstringAny, []byte("b")),
+					ber.NewPrimitive(ber.ClassContext|ldap.SubstringFinal, []byte("c")))),
+		},
+		{
+			filter: "(&(objectClass=groupOfNames)(!(cn>=m))(|(cn~=x)(cn<=b)))",
+			expected: ber.NewConstructed(ber.ClassContext|ldap.FilterAnd,
+				ber.NewConstructed(ber.ClassContext|ldap.FilterEqualityMatch,
+					ber.NewOctetString("objectClass"), ber.NewOctetString("groupOfNames")),
+				ber.NewConstructed(ber.ClassContext|ldap.FilterNot,
+					ber.NewConstructed(ber.ClassContext|ldap.FilterGreaterOrEqual,
+						ber.NewOctetString("cn"), ber.NewOctetString("m"))),
+				ber.NewConstructed(ber.ClassContext|ldap.FilterOr,
+					ber.NewConstructed(ber.ClassContext|ldap.FilterApproxMatch,
+						ber.NewOctetString("cn"), ber.NewOctetString("x")),
+					ber.NewConstructed(ber.ClassContext|ldap.FilterLessOrEqual,
+						ber.NewOctetString("cn"), ber.NewOctetString("b")))),
+		},
+		{
+			filter: "(cn=" + ldap.EscapeFilter("a*(b)\\") + ")",
+			expected: ber.NewConstructed(ber.ClassContext|ldap.FilterEqualityMatch,
+				ber.NewOctetString("cn"), ber.NewOctetString("a*(b)\\")),
+		},
+		{filter: "cn=admin", shouldErr: true},
+		{filter: "(cn=admin", shouldErr: true},
+		{filter: "(cn=admin))", shouldErr: true},
+		{filter: "(=admin)", shouldErr: true},
+		{filter: "(cn=\\4)", shouldErr: true},
+		{filter: "(cn=\\zz)", shouldErr: true},
+		{filter: "(&(cn=a)", shouldErr: true},
+	}
+
+	for i, testCase := range testCases {
+		p, err := ldap.CompileFilter(testCase.filter)
+		if testCase.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected an error for %q", i+1, testCase.filter)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: unexpected error for %q: %v", i+1, testCase.filter, err)
+			continue
+		}
+		// Compare encodings, decoded packets differ in nil vs empty slices.
+		if !reflect.DeepEqual(p.Bytes(), testCase.expected.Bytes()) {
+			t.Errorf("Test %d: unexpected encoding of %q", i+1, testCase.filter)
+		}
+	}
+}
+
+func TestBERRoundTrip(t *testing.T) {
+	for _, i := range []int64{0, 1, -1, 127, 128, -128, -129, 255, 256, 1 << 31, -1 << 40} {
+		p, err := ber.Parse(ber.NewInteger(i).Bytes())
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if v, err := p.Int(); err != nil || v != i {
+			t.Errorf("Expected %d, got %d (%v)", i, v, err)
+		}
+	}
+
+	long := make([]byte, 70000)
+	p, err := ber.Parse(ber.NewSequence(ber.NewPrimitive(ber.TagOctetString, long), ber.NewBoolean(true)).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Children) != 2 || len(p.Children[0].Value) != len(long) || !p.Children[1].Bool() {
+		t.Error("Unexpected decoding of a long sequence")
+	}
+
+	if _, err = ber.Parse([]byte{0x30, 0x05, 0x04, 0x01}); err == nil {
+		t.Error("Expected an error for a truncated packet")
+	}
+}
+
+func testBindAndSearch(t *testing.T, conn *ldap.Conn) {
+	defer conn.Close()
+
+	if err := conn.Bind("uid=alice,ou=people,dc=example,dc=com", "wrong"); !ldap.IsInvalidCredentials(err) {
+		t.Fatalf("Expected invalid credentials, got %v", err)
+	}
+	if err := conn.Bind("uid=alice,ou=people,dc=example,dc=com", ""); !ldap.IsInvalidCredentials(err) {
+		t.Fatalf("Expected an empty password to be rejected, got %v", err)
+	}
+	if err := conn.Bind("uid=alice,ou=people,dc=example,dc=com", "alice-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := conn.Search(ldap.SearchRequest{
+		BaseDN:     "ou=groups,dc=example,dc=com",
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     "(&(objectClass=groupOfNames)(member=" + ldap.EscapeFilter("uid=alice,ou=people,dc=example,dc=com") + "))",
+		Attributes: []string{"cn"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var groups []string
+	for _, entry := range entries {
+		groups = append(groups, entry.GetAttributeValues("CN")...)
+	}
+	sort.Strings(groups)
+	if !reflect.DeepEqual(groups, []string{"developers"}) {
+		t.Errorf("Unexpected groups %v", groups)
+	}
+
+	entries, err = conn.Search(ldap.SearchRequest{
+		BaseDN: "dc=example,dc=com",
+		Scope:  ldap.ScopeSingleLevel,
+		Filter: "(cn=*)",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no entries one level below the base, got %d", len(entries))
+	}
+}
+
+func TestConnBindAndSearch(t *testing.T) {
+	server := ldaptest.NewServer(nil, testEntries...)
+	defer server.Close()
+
+	conn, err := ldap.Dial(server.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBindAndSearch(t, conn)
+}
+
+func TestConnStartTLS(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfigs(t)
+
+	// StartTLS is refused by servers not supporting it.
+	plain := ldaptest.NewServer(nil, testEntries...)
+	defer plain.Close()
+	conn, err := ldap.Dial(plain.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.StartTLS(clientTLS); err == nil {
+		t.Error("Expected StartTLS to fail")
+	}
+	conn.Close()
+
+	server := ldaptest.NewServer(serverTLS, testEntries...)
+	defer server.Close()
+	if conn, err = ldap.Dial(server.Addr); err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.StartTLS(clientTLS); err != nil {
+		t.Fatal(err)
+	}
+	testBindAndSearch(t, conn)
+}
+
+func TestDialTLS(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfigs(t)
+	server := ldaptest.NewTLSServer(serverTLS, testEntries...)
+	defer server.Close()
+
+	// The certificate is not trusted by default.
+	if conn, err := ldap.DialTLS(server.Addr, &tls.Config{}); err == nil {
+		conn.Close()
+		t.Fatal("Expected certificate verification to fail")
+	}
+
+	conn, err := ldap.DialTLS(server.Addr, clientTLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBindAndSearch(t, conn)
+}
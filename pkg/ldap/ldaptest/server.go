@@ -0,0 +1,291 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ldaptest provides an in-process LDAP server for tests,
+// serving a fixed directory of entries.
+package ldaptest
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio/pkg/ldap"
+	"github.com/minio/minio/pkg/ldap/ber"
+)
+
+// Entry - a directory entry, users have a password to bind with.
+type Entry struct {
+	DN         string
+	Password   string
+	Attributes map[string][]string
+}
+
+// Server - an LDAP server listening on a local port.
+type Server struct {
+	// Addr - host:port the server listens on.
+	Addr string
+
+	listener  net.Listener
+	tlsConfig *tls.Config
+	entries   []Entry
+	wg        sync.WaitGroup
+}
+
+// NewServer - starts a server without TLS, StartTLS is supported if
+// tlsConfig is not nil.
+func NewServer(tlsConfig *tls.Config, entries ...Entry) *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("ldaptest: failed to listen: " + err.Error())
+	}
+	return start(listener, tlsConfig, entries)
+}
+
+// NewTLSServer - starts an LDAPS server.
+func NewTLSServer(tlsConfig *tls.Config, entries ...Entry) *Server {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	if err != nil {
+		panic("ldaptest: failed to listen: " + err.Error())
+	}
+	return start(listener, nil, entries)
+}
+
+func start(listener net.Listener, tlsConfig *tls.Config, entries []Entry) *Server {
+	s := &Server{
+		Addr:      listener.Addr().String(),
+		listener:  listener,
+		tlsConfig: tlsConfig,
+		entries:   entries,
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+	return s
+}
+
+// Close - stops the server and waits for its connections to be closed
+// by the clients.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		msg, err := ber.ReadPacket(reader)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		id, op := msg.Children[0], msg.Children[1]
+		reply := func(resp *ber.Packet) error {
+			_, err := conn.Write(ber.NewSequence(id, resp).Bytes())
+			return err
+		}
+
+		switch op.Tag &^ (ber.ClassApplication | ber.Constructed) {
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationBindRequest:
+			err = reply(result(ldap.ApplicationBindResponse, s.bind(op)))
+		case ldap.ApplicationSearchRequest:
+			for _, entry := range s.search(op) {
+				if err = reply(entry); err != nil {
+					return
+				}
+			}
+			err = reply(result(ldap.ApplicationSearchResultDone, ldap.ResultSuccess))
+		case ldap.ApplicationExtendedRequest:
+			if s.tlsConfig == nil || len(op.Children) == 0 || op.Children[0].String() != ldap.OIDStartTLS {
+				// Protocol error.
+				err = reply(result(ldap.ApplicationExtendedResponse, 2))
+				break
+			}
+			if err = reply(result(ldap.ApplicationExtendedResponse, ldap.ResultSuccess)); err != nil {
+				return
+			}
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err = tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, reader = tlsConn, bufio.NewReader(tlsConn)
+		default:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func result(application byte, code int64) *ber.Packet {
+	return ber.NewConstructed(ber.ClassApplication|application,
+		ber.NewEnumerated(code), ber.NewOctetString(""), ber.NewOctetString(""))
+}
+
+func (s *Server) bind(op *ber.Packet) int64 {
+	if len(op.Children) != 3 {
+		return 2
+	}
+	dn, password := op.Children[1].String(), op.Children[2].String()
+	if dn == "" && password == "" {
+		return ldap.ResultSuccess
+	}
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.DN, dn) && entry.Password != "" && entry.Password == password {
+			return ldap.ResultSuccess
+		}
+	}
+	return ldap.ResultInvalidCredentials
+}
+
+func (s *Server) search(op *ber.Packet) (entries []*ber.Packet) {
+	if len(op.Children) != 8 {
+		return nil
+	}
+	baseDN := strings.ToLower(op.Children[0].String())
+	scope, _ := op.Children[1].Int()
+	filter := op.Children[6]
+
+	for _, entry := range s.entries {
+		dn := strings.ToLower(entry.DN)
+		switch scope {
+		case ldap.ScopeBaseObject:
+			if dn != baseDN {
+				continue
+			}
+		case ldap.ScopeSingleLevel:
+			if i := strings.IndexByte(dn, ','); i < 0 || dn[i+1:] != baseDN {
+				continue
+			}
+		default:
+			if dn != baseDN && !strings.HasSuffix(dn, ","+baseDN) {
+				continue
+			}
+		}
+		if !match(filter, entry) {
+			continue
+		}
+
+		attributes := ber.NewSequence()
+		for name, values := range entry.Attributes {
+			set := ber.NewConstructed(ber.TagSet)
+			for _, value := range values {
+				set.Append(ber.NewOctetString(value))
+			}
+			attributes.Append(ber.NewSequence(ber.NewOctetString(name), set))
+		}
+		entries = append(entries, ber.NewConstructed(ber.ClassApplication|ldap.ApplicationSearchResultEntry,
+			ber.NewOctetString(entry.DN), attributes))
+	}
+	return entries
+}
+
+// match - evaluates a filter against an entry, values are compared
+// case insensitively.
+func match(filter *ber.Packet, entry Entry) bool {
+	values := func(name string) []string {
+		for attr, v := range entry.Attributes {
+			if strings.EqualFold(attr, name) {
+				return v
+			}
+		}
+		return nil
+	}
+
+	switch filter.Tag &^ (ber.ClassContext | ber.Constructed) {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !match(child, entry) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if match(child, entry) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return len(filter.Children) == 1 && !match(filter.Children[0], entry)
+	case ldap.FilterPresent:
+		return len(values(filter.String())) > 0
+	case ldap.FilterEqualityMatch, ldap.FilterApproxMatch:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		for _, v := range values(filter.Children[0].String()) {
+			if strings.EqualFold(v, filter.Children[1].String()) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterSubstrings:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		for _, v := range values(filter.Children[0].String()) {
+			if matchSubstrings(strings.ToLower(v), filter.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func matchSubstrings(value string, substrings []*ber.Packet) bool {
+	for _, sub := range substrings {
+		s := strings.ToLower(sub.String())
+		switch sub.Tag &^ ber.ClassContext {
+		case ldap.SubstringInitial:
+			if !strings.HasPrefix(value, s) {
+				return false
+			}
+			value = value[len(s):]
+		case ldap.SubstringAny:
+			i := strings.Index(value, s)
+			if i < 0 {
+				return false
+			}
+			value = value[i+len(s):]
+		case ldap.SubstringFinal:
+			if !strings.HasSuffix(value, s) {
+				return false
+			}
+			value = ""
+		}
+	}
+	return true
+}
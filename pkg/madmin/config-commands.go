@@ -39,6 +39,9 @@ type NodeSummary struct {
 type SetConfigResult struct {
 	NodeResults []NodeSummary `json:"nodeResults"`
 	Status      bool          `json:"status"`
+	// Difference with the previous configuration, only set by
+	// SetConfigSubsys.
+	Diff string `json:"diff,omitempty"`
 }
 
 // GetConfig - returns the config.json of a minio setup.
@@ -104,3 +107,70 @@ func (adm *AdminClient) SetConfig(config io.Reader) (r SetConfigResult, err erro
 	err = json.Unmarshal(jsonBytes, &r)
 	return r, err
 }
+
+// GetConfigSubsys - returns the configuration of a subsystem of a minio
+// setup, e.g. "region", "storageclass" or "notify/webhook/1".
+func (adm *AdminClient) GetConfigSubsys(subsys string) ([]byte, error) {
+	// No TLS?
+	if !adm.secure {
+		return nil, fmt.Errorf("credentials/configuration cannot be retrieved over an insecure connection")
+	}
+
+	// Execute GET on /minio/admin/v1/config/{subsys} to get the
+	// configuration of the subsystem.
+	resp, err := adm.executeMethod("GET",
+		requestData{relPath: "/v1/config/" + subsys})
+	defer closeResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpRespToErrorResponse(resp)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// SetConfigSubsys - set the configuration of a subsystem of a minio
+// setup. The change is applied without a restart unless the subsystem
+// requires one.
+func (adm *AdminClient) SetConfigSubsys(subsys string, config io.Reader) (r SetConfigResult, err error) {
+	// No TLS?
+	if !adm.secure {
+		return r, fmt.Errorf("credentials/configuration cannot be updated over an insecure connection")
+	}
+
+	configBytes, err := ioutil.ReadAll(config)
+	if err != nil {
+		return r, err
+	}
+
+	reqData := requestData{
+		relPath:            "/v1/config/" + subsys,
+		contentBody:        bytes.NewReader(configBytes),
+		contentMD5Bytes:    sumMD5(configBytes),
+		contentSHA256Bytes: sum256(configBytes),
+	}
+
+	// Execute PUT on /minio/admin/v1/config/{subsys} to set the
+	// configuration of the subsystem.
+	resp, err := adm.executeMethod("PUT", reqData)
+
+	defer closeResponse(resp)
+	if err != nil {
+		return r, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return r, httpRespToErrorResponse(resp)
+	}
+
+	jsonBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return r, err
+	}
+
+	err = json.Unmarshal(jsonBytes, &r)
+	return r, err
+}
@@ -95,13 +95,49 @@ type ServerHTTPStats struct {
 	SuccessDELETEStats ServerHTTPMethodStats `json:"successDELETEs"`
 }
 
+// ServerDurationBucket holds the number of observations less than or
+// equal to the upper bound
+type ServerDurationBucket struct {
+	UpperBound string `json:"le"`
+	Count      uint64 `json:"count"`
+}
+
+// ServerDurationHistogram holds a histogram of durations with
+// cumulative bucket counts
+type ServerDurationHistogram struct {
+	Buckets []ServerDurationBucket `json:"buckets"`
+	Count   uint64                 `json:"count"`
+	Sum     string                 `json:"sum"`
+}
+
+// ServerLockStats holds lock wait and hold time histograms and the
+// number of stale distributed locks dropped
+type ServerLockStats struct {
+	Wait       ServerDurationHistogram `json:"wait"`
+	Hold       ServerDurationHistogram `json:"hold"`
+	StaleLocks uint64                  `json:"staleLocks"`
+}
+
+// ServerAdmissionStats holds the limit of requests served at once,
+// the requests in flight and queued, and the number of requests
+// admitted and rejected
+type ServerAdmissionStats struct {
+	MaxRequests int    `json:"maxRequests"`
+	InFlight    int    `json:"inFlight"`
+	Queued      int    `json:"queued"`
+	Admitted    uint64 `json:"admitted"`
+	Rejected    uint64 `json:"rejected"`
+}
+
 // ServerInfoData holds storage, connections and other
 // information of a given server
 type ServerInfoData struct {
-	StorageInfo StorageInfo      `json:"storage"`
-	ConnStats   ServerConnStats  `json:"network"`
-	HTTPStats   ServerHTTPStats  `json:"http"`
-	Properties  ServerProperties `json:"server"`
+	StorageInfo StorageInfo          `json:"storage"`
+	ConnStats   ServerConnStats      `json:"network"`
+	HTTPStats   ServerHTTPStats      `json:"http"`
+	LockStats   ServerLockStats      `json:"locks"`
+	Admission   ServerAdmissionStats `json:"admission"`
+	Properties  ServerProperties     `json:"server"`
 }
 
 // ServerInfo holds server information result of one node
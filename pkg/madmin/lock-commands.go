@@ -61,6 +61,17 @@ type VolumeLockInfo struct {
 	// State information containing state of the locks for all operations
 	// on given <volume,path> pair.
 	LockDetailsOnObject []OpsLockState `json:"lockOwners"`
+	// Leases of distributed locks on given <volume,path> pair
+	// granted by the lock server of the responding node.
+	Leases []LockLeaseState `json:"leases,omitempty"`
+}
+
+// LockLeaseState - represents a lease granted by a lock server.
+type LockLeaseState struct {
+	Owner    string        `json:"owner"`    // Address of the node holding the lock.
+	LockType lockType      `json:"type"`     // Lock type (RLock, WLock)
+	Since    time.Time     `json:"since"`    // Time when the lock was granted.
+	LeaseAge time.Duration `json:"leaseAge"` // Time since the owner last refreshed the lock.
 }
 
 // getLockInfos - unmarshal []VolumeLockInfo from a reader.
@@ -0,0 +1,87 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package madmin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// WriteBackObject - an object staged locally by a gateway in
+// write-back mode, waiting to be uploaded to the gateway backend.
+type WriteBackObject struct {
+	Bucket    string    `json:"bucket"`
+	Object    string    `json:"object"`
+	Size      int64     `json:"size"`
+	Queued    time.Time `json:"queued"`
+	Uploading bool      `json:"uploading"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// WriteBackStatus - uploads pending on a gateway in write-back mode.
+type WriteBackStatus struct {
+	// Number and total size of objects not yet uploaded.
+	Pending     int   `json:"pending"`
+	PendingSize int64 `json:"pendingSize"`
+
+	// Number of objects being uploaded and number of objects
+	// whose last upload attempt failed.
+	Uploading int `json:"uploading"`
+	Failed    int `json:"failed"`
+
+	// Number of objects uploaded since the gateway started.
+	Uploaded int64 `json:"uploaded"`
+
+	// Oldest pending objects matching the requested bucket and
+	// prefix, at most 1000.
+	Objects []WriteBackObject `json:"objects"`
+}
+
+// WriteBackStatus - returns the uploads pending on a gateway in
+// write-back mode, objects are listed for the given bucket and prefix.
+func (adm *AdminClient) WriteBackStatus(bucket, prefix string) (status WriteBackStatus, err error) {
+	queryValues := url.Values{}
+	queryValues.Set("bucket", bucket)
+	queryValues.Set("prefix", prefix)
+
+	// Execute GET on /minio/admin/v1/writeback.
+	resp, err := adm.executeMethod("GET", requestData{
+		relPath:     "/v1/writeback",
+		queryValues: queryValues,
+	})
+	defer closeResponse(resp)
+	if err != nil {
+		return status, err
+	}
+
+	// Check response http status code
+	if resp.StatusCode != http.StatusOK {
+		return status, httpRespToErrorResponse(resp)
+	}
+
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return status, err
+	}
+
+	err = json.Unmarshal(respBytes, &status)
+	return status, err
+}
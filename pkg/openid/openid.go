@@ -0,0 +1,399 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openid implements the relying party side of the OpenID
+// Connect authorization code flow: provider discovery, exchanging an
+// authorization code for an ID token and validating ID tokens against
+// the signing keys published by the provider.
+package openid
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+)
+
+// Config - relying party configuration.
+type Config struct {
+	// Issuer URL of the provider, the discovery document is fetched
+	// from <issuer>/.well-known/openid-configuration.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURI the provider sends the authorization code to.
+	RedirectURI string
+	// Scopes requested in addition to "openid".
+	Scopes []string
+	// Client used for all requests to the provider,
+	// http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// Errors returned while validating ID tokens.
+var (
+	ErrInvalidIssuer   = errors.New("openid: ID token issued by another provider")
+	ErrInvalidAudience = errors.New("openid: ID token issued for another client")
+	ErrInvalidNonce    = errors.New("openid: ID token nonce does not match")
+	ErrUnknownKey      = errors.New("openid: ID token signed by an unknown key")
+)
+
+// Signing algorithms accepted for ID tokens, symmetric algorithms are
+// not accepted as the client secret is not meant to sign tokens.
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Minimum time between two fetches of the provider keys, limits the
+// requests triggered by tokens signed with unknown keys.
+const keysRefreshInterval = 10 * time.Second
+
+// Provider - an OpenID Connect provider discovered from its issuer.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	authEndpoint  string
+	tokenEndpoint string
+	jwksURI       string
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// discoveryDoc - the fields of the provider metadata used here.
+type discoveryDoc struct {
+	Issuer        string `json:"issuer"`
+	AuthEndpoint  string `json:"authorization_endpoint"`
+	TokenEndpoint string `json:"token_endpoint"`
+	JWKSURI       string `json:"jwks_uri"`
+}
+
+// NewProvider - fetches the discovery document and the signing keys of
+// the provider.
+func NewProvider(config Config) (*Provider, error) {
+	client := config.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	issuer := strings.TrimSuffix(config.Issuer, "/")
+
+	var doc discoveryDoc
+	if err := getJSON(client, issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+	// The issuer in the metadata must be identical to the one used to
+	// retrieve it, all tokens are checked against it.
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("openid: discovery document of %s is for issuer %s", issuer, doc.Issuer)
+	}
+	if doc.AuthEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("openid: discovery document of %s is incomplete", issuer)
+	}
+
+	p := &Provider{
+		config:        config,
+		client:        client,
+		authEndpoint:  doc.AuthEndpoint,
+		tokenEndpoint: doc.TokenEndpoint,
+		jwksURI:       doc.JWKSURI,
+	}
+	p.config.Issuer = doc.Issuer
+	if err := p.refreshKeys(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Issuer - returns the issuer identifier of the provider.
+func (p *Provider) Issuer() string {
+	return p.config.Issuer
+}
+
+// AuthCodeURL - returns the URL of the provider to redirect the user
+// agent to for authentication. state is passed back to the redirect
+// URI unchanged, nonce is embedded in the issued ID token.
+func (p *Provider) AuthCodeURL(state, nonce string) string {
+	values := url.Values{}
+	values.Set("response_type", "code")
+	values.Set("client_id", p.config.ClientID)
+	values.Set("redirect_uri", p.config.RedirectURI)
+	values.Set("scope", strings.Join(append([]string{"openid"}, p.config.Scopes...), " "))
+	values.Set("state", state)
+	values.Set("nonce", nonce)
+
+	sep := "?"
+	if strings.Contains(p.authEndpoint, "?") {
+		sep = "&"
+	}
+	return p.authEndpoint + sep + values.Encode()
+}
+
+// Exchange - redeems an authorization code at the token endpoint and
+// returns the raw ID token. The token still has to be validated.
+func (p *Provider) Exchange(code string) (string, error) {
+	values := url.Values{}
+	values.Set("grant_type", "authorization_code")
+	values.Set("code", code)
+	values.Set("redirect_uri", p.config.RedirectURI)
+
+	req, err := http.NewRequest("POST", p.tokenEndpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = doJSON(p.client, req, &token); err != nil {
+		return "", err
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("openid: token request failed: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", errors.New("openid: token response has no ID token")
+	}
+	return token.IDToken, nil
+}
+
+// Verify - validates the signature, issuer, audience, expiry and nonce
+// of an ID token and returns its claims.
+func (p *Provider) Verify(rawIDToken, nonce string) (jwtgo.MapClaims, error) {
+	claims := jwtgo.MapClaims{}
+	parser := &jwtgo.Parser{ValidMethods: validMethods}
+	if _, err := parser.ParseWithClaims(rawIDToken, claims, p.keyFunc); err != nil {
+		if verr, ok := err.(*jwtgo.ValidationError); ok && verr.Inner != nil {
+			return nil, verr.Inner
+		}
+		return nil, err
+	}
+
+	// Providers differ in whether they add a trailing slash, tokens
+	// may carry the issuer with or without it.
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(p.config.Issuer, "/") {
+		return nil, ErrInvalidIssuer
+	}
+	if !hasAudience(claims["aud"], p.config.ClientID) {
+		return nil, ErrInvalidAudience
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("openid: ID token has no expiry")
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, ErrInvalidNonce
+	}
+	return claims, nil
+}
+
+// hasAudience - the aud claim is either a single string or an array.
+func hasAudience(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyFunc - returns the provider key a token is signed with. Keys are
+// fetched again once a token refers to an unknown key, providers
+// publish new keys before they use them.
+func (p *Provider) keyFunc(token *jwtgo.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+
+	p.mu.RLock()
+	recent := time.Since(p.keysFetched) < keysRefreshInterval
+	p.mu.RUnlock()
+	if !recent {
+		if err := p.refreshKeys(); err != nil {
+			return nil, err
+		}
+		if key := p.lookupKey(kid); key != nil {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+// lookupKey - returns the key with the given ID, or the only key of the
+// provider for tokens without a key ID.
+func (p *Provider) lookupKey(kid string) crypto.PublicKey {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+// refreshKeys - fetches the JSON web key set of the provider.
+func (p *Provider) refreshKeys() error {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(p.client, p.jwksURI, &jwks); err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Skip key types not supported, the
+			// provider may sign with other keys.
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("openid: no usable signing keys found at %s", p.jwksURI)
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetched = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+// jsonWebKey - RSA and EC public keys as defined by RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA modulus and exponent.
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC curve and point.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("openid: invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("openid: unsupported curve %s", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("openid: invalid EC key")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("openid: unsupported key type %s", jwk.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("openid: empty key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func getJSON(client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	return doJSON(client, req, v)
+}
+
+// doJSON - sends req and decodes the JSON response into v. Error
+// responses of the token endpoint are JSON too and decoded as well.
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body := io.LimitReader(resp.Body, 1<<20)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		io.Copy(ioutil.Discard, body)
+		return fmt.Errorf("openid: %s %s: %s", req.Method, req.URL, resp.Status)
+	}
+	if err = json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("openid: invalid response from %s: %v", req.URL, err)
+	}
+	return nil
+}
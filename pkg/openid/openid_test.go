@@ -0,0 +1,221 @@
+/*
+ * Minio Cloud Storage, (C) 2018 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openid
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+)
+
+// mockProvider - a minimal OpenID Connect provider issuing ID tokens
+// for a fixed subject. The nonce of the last authorization request is
+// embedded in the tokens it issues.
+type mockProvider struct {
+	*httptest.Server
+	key   *rsa.PrivateKey
+	kid   string
+	nonce string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockProvider{key: key, kid: "1"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": m.kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		m.nonce = r.URL.Query().Get("nonce")
+		redirect := r.URL.Query().Get("redirect_uri") + "?code=code&state=" + url.QueryEscape(r.URL.Query().Get("state"))
+		http.Redirect(w, r, redirect, http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "client" || secret != "secret" || r.PostFormValue("code") != "code" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"id_token": m.sign(t, jwtgo.MapClaims{"nonce": m.nonce}),
+		})
+	})
+	m.Server = httptest.NewServer(mux)
+	return m
+}
+
+// sign - signs an ID token with default claims overridden by claims.
+func (m *mockProvider) sign(t *testing.T, claims jwtgo.MapClaims) string {
+	idClaims := jwtgo.MapClaims{
+		"iss": m.URL,
+		"sub": "user",
+		"aud": "client",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		idClaims[k] = v
+	}
+	token := jwtgo.NewWithClaims(jwtgo.SigningMethodRS256, idClaims)
+	token.Header["kid"] = m.kid
+	s, err := token.SignedString(m.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestProviderAuthorizationCodeFlow(t *testing.T) {
+	m := newMockProvider(t)
+	defer m.Close()
+
+	p, err := NewProvider(Config{
+		Issuer:       m.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURI:  "http://localhost/callback",
+		Scopes:       []string{"groups"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Follow the authorization request up to the redirect.
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(p.AuthCodeURL("state", "nonce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	redirect, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := redirect.Query().Get("state"); state != "state" {
+		t.Fatalf("Expected state to be passed back, got %s", state)
+	}
+
+	idToken, err := p.Exchange(redirect.Query().Get("code"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := p.Verify(idToken, "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["sub"] != "user" {
+		t.Fatalf("Expected subject user, got %v", claims["sub"])
+	}
+
+	if _, err = p.Exchange("invalid"); err == nil {
+		t.Fatal("Expected invalid code to be rejected")
+	}
+	if _, err = p.Verify(idToken, "other"); err != ErrInvalidNonce {
+		t.Fatalf("Expected %v, got %v", ErrInvalidNonce, err)
+	}
+}
+
+func TestProviderVerify(t *testing.T) {
+	m := newMockProvider(t)
+	defer m.Close()
+
+	p, err := NewProvider(Config{Issuer: m.URL, ClientID: "client"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwtgo.NewWithClaims(jwtgo.SigningMethodRS256, jwtgo.MapClaims{
+		"iss": m.URL, "sub": "user", "aud": "client", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = m.kid
+	forgedToken, err := forged.SignedString(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacToken, err := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, jwtgo.MapClaims{
+		"iss": m.URL, "sub": "user", "aud": "client", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		token   string
+		success bool
+	}{
+		{m.sign(t, nil), true},
+		{m.sign(t, jwtgo.MapClaims{"aud": []string{"other", "client"}}), true},
+		{m.sign(t, jwtgo.MapClaims{"aud": "other"}), false},
+		{m.sign(t, jwtgo.MapClaims{"iss": m.URL + "/"}), true},
+		{m.sign(t, jwtgo.MapClaims{"iss": "https://evil.example.com"}), false},
+		{m.sign(t, jwtgo.MapClaims{"iss": m.URL + "//"}), false},
+		{m.sign(t, jwtgo.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}), false},
+		{m.sign(t, jwtgo.MapClaims{"nonce": "nonce"}), false},
+		{forgedToken, false},
+		{hmacToken, false},
+		{"invalid", false},
+	}
+	for i, testCase := range testCases {
+		_, err := p.Verify(testCase.token, "")
+		if testCase.success && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.success && err == nil {
+			t.Errorf("Test %d: Expected token to be rejected", i+1)
+		}
+	}
+
+	// Keys rotated by the provider are fetched on first use.
+	m.key, m.kid = otherKey, "2"
+	p.keysFetched = time.Time{}
+	if _, err = p.Verify(m.sign(t, nil), ""); err != nil {
+		t.Fatalf("Expected token signed with the rotated key to be accepted, got %v", err)
+	}
+}